@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/py"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/flare"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
@@ -48,6 +50,8 @@ func SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
+	r.HandleFunc("/dogstatsd-capture", startDogstatsdCapture).Methods("POST")
+	r.HandleFunc("/config/api_key", setAPIKey).Methods("POST")
 }
 
 func stopAgent(w http.ResponseWriter, r *http.Request) {
@@ -218,6 +222,7 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
+	response.ResolutionTraces = common.AC.GetResolutionTraces()
 
 	jsonConfig, err := json.Marshal(response)
 	if err != nil {
@@ -242,3 +247,49 @@ func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Write(jsonTags)
 }
+
+func startDogstatsdCapture(w http.ResponseWriter, r *http.Request) {
+	if common.DSD == nil {
+		http.Error(w, "dogstatsd is not running", 503)
+		return
+	}
+
+	duration, err := time.ParseDuration(r.URL.Query().Get("duration"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid capture duration: %s", err), 400)
+		return
+	}
+
+	path, err := common.DSD.StartCapture(duration)
+	if err != nil {
+		log.Errorf("The dogstatsd capture could not be started: %s", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Write([]byte(path))
+}
+
+// setAPIKey rotates an API key of the forwarder, the main one by default
+func setAPIKey(w http.ResponseWriter, r *http.Request) {
+	var keys struct {
+		APIKey         string `json:"api_key"`
+		PreviousAPIKey string `json:"previous_api_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&keys); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), 400)
+		return
+	}
+
+	fwd, ok := common.Forwarder.(*forwarder.DefaultForwarder)
+	if !ok {
+		http.Error(w, "the forwarder is not running", 503)
+		return
+	}
+
+	if err := fwd.UpdateAPIKey(keys.PreviousAPIKey, keys.APIKey); err != nil {
+		log.Errorf("The API key could not be rotated: %s", err)
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	w.Write([]byte(""))
+}
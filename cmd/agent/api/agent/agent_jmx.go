@@ -28,6 +28,8 @@ import (
 )
 
 func getJMXConfigs(w http.ResponseWriter, r *http.Request) {
+	jmx.ReportHeartbeat()
+
 	var ts int
 	queries := r.URL.Query()
 	if timestamps, ok := queries["timestamp"]; ok {
@@ -85,6 +87,8 @@ func getJMXConfigs(w http.ResponseWriter, r *http.Request) {
 }
 
 func setJMXStatus(w http.ResponseWriter, r *http.Request) {
+	jmx.ReportHeartbeat()
+
 	decoder := json.NewDecoder(r.Body)
 
 	var jmxStatus status.JMXStatus
@@ -8,6 +8,7 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/fatih/color"
@@ -19,20 +20,54 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/util"
 )
 
 var (
-	checkRate  bool
-	checkName  string
-	checkDelay int
-	logLevel   string
+	checkRate    bool
+	checkName    string
+	checkDelay   int
+	logLevel     string
+	formatJSON   bool
+	profileCheck bool
 )
 
-// Make the check cmd aggregator never flush by setting a very high interval
-const checkCmdFlushInterval = time.Hour
+const (
+	// Make the check cmd aggregator never flush by setting a very high interval
+	checkCmdFlushInterval = time.Hour
+	// Time between the two runs of --check-rate, rates need the samples to be
+	// at least a second apart
+	checkRateInterval = time.Second
+)
+
+// instanceOutput holds what a check instance emitted, for the JSON output
+type instanceOutput struct {
+	CheckID    check.ID         `json:"check_id"`
+	Aggregator aggregatorOutput `json:"aggregator"`
+	Runner     *check.Stats     `json:"runner"`
+	Profile    []runProfile     `json:"profile,omitempty"`
+}
+
+// aggregatorOutput holds the metrics, events and service checks of a check.
+// Plain slices are used, the metrics types being marshalled to the intake formats.
+type aggregatorOutput struct {
+	Series        []*metrics.Serie        `json:"series,omitempty"`
+	Sketches      []metrics.SketchSeries  `json:"sketches,omitempty"`
+	ServiceChecks []*metrics.ServiceCheck `json:"service_checks,omitempty"`
+	Events        []*metrics.Event        `json:"events,omitempty"`
+}
+
+// runProfile holds the execution time and the memory allocated by the Go
+// runtime during a check run
+type runProfile struct {
+	ExecutionTimeMs int64  `json:"execution_time_ms"`
+	AllocatedBytes  uint64 `json:"allocated_bytes"`
+	Allocations     uint64 `json:"allocations"`
+	HeapDeltaBytes  int64  `json:"heap_delta_bytes"`
+}
 
 func init() {
 	AgentCmd.AddCommand(checkCmd)
@@ -40,6 +75,8 @@ func init() {
 	checkCmd.Flags().BoolVarP(&checkRate, "check-rate", "r", false, "check rates by running the check twice")
 	checkCmd.Flags().StringVarP(&logLevel, "log-level", "l", "", "set the log level (default 'off')")
 	checkCmd.Flags().IntVarP(&checkDelay, "delay", "d", 100, "delay between running the check and grabbing the metrics in miliseconds")
+	checkCmd.Flags().BoolVarP(&formatJSON, "json", "", false, "format the aggregator and check runner output as json")
+	checkCmd.Flags().BoolVarP(&profileCheck, "profile", "", false, "profile the execution time and memory allocations of the check runs")
 	checkCmd.SetArgs([]string{"checkName"})
 }
 
@@ -60,7 +97,8 @@ var checkCmd = &cobra.Command{
 		}
 
 		if logLevel == "" {
-			if confFilePath != "" {
+			// don't mix the logs with the json output
+			if confFilePath != "" && !formatJSON {
 				logLevel = config.Datadog.GetString("log_level")
 			} else {
 				logLevel = "off"
@@ -116,22 +154,46 @@ var checkCmd = &cobra.Command{
 			return fmt.Errorf("no valid check found")
 		}
 
-		if len(cs) > 1 {
+		if len(cs) > 1 && !formatJSON {
 			fmt.Println("Multiple check instances found, running each of them")
 		}
 
+		var instances []instanceOutput
 		for _, c := range cs {
-			s := runCheck(c, agg)
+			s, profile := runCheck(c, agg)
 
 			// Sleep for a while to allow the aggregator to finish ingesting all the metrics/events/sc
 			time.Sleep(time.Duration(checkDelay) * time.Millisecond)
 
-			printMetrics(agg)
+			output := getAggregatorOutput(agg)
+			if formatJSON {
+				instances = append(instances, instanceOutput{
+					CheckID:    c.ID(),
+					Aggregator: output,
+					Runner:     s,
+					Profile:    profile,
+				})
+				continue
+			}
+
+			printMetrics(output)
+			if profileCheck {
+				printProfile(profile)
+			}
 
 			checkStatus, _ := status.GetCheckStatus(c, s)
 			fmt.Println(string(checkStatus))
 		}
 
+		if formatJSON {
+			j, err := json.MarshalIndent(instances, "", "  ")
+			if err != nil {
+				return fmt.Errorf("could not format the check output as json: %s", err)
+			}
+			fmt.Println(string(j))
+			return nil
+		}
+
 		if checkRate == false {
 			color.Yellow("Check has run only once, if some metrics are missing you can try again with --check-rate to see any other metric if available.")
 		}
@@ -140,51 +202,89 @@ var checkCmd = &cobra.Command{
 	},
 }
 
-func runCheck(c check.Check, agg *aggregator.BufferedAggregator) *check.Stats {
+func runCheck(c check.Check, agg *aggregator.BufferedAggregator) (*check.Stats, []runProfile) {
 	s := check.NewStats(c)
+	var profile []runProfile
 	i := 0
 	times := 1
 	if checkRate {
 		times = 2
 	}
 	for i < times {
+		if i > 0 {
+			time.Sleep(checkRateInterval)
+		}
+
+		var before runtime.MemStats
+		if profileCheck {
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+		}
+
 		t0 := time.Now()
 		err := c.Run()
+		execTime := time.Since(t0)
+
+		if profileCheck {
+			var after runtime.MemStats
+			runtime.ReadMemStats(&after)
+			profile = append(profile, runProfile{
+				ExecutionTimeMs: int64(execTime / time.Millisecond),
+				AllocatedBytes:  after.TotalAlloc - before.TotalAlloc,
+				Allocations:     after.Mallocs - before.Mallocs,
+				HeapDeltaBytes:  int64(after.HeapAlloc) - int64(before.HeapAlloc),
+			})
+		}
+
 		warnings := c.GetWarnings()
 		mStats, _ := c.GetMetricStats()
-		s.Add(time.Since(t0), err, warnings, mStats)
+		s.Add(execTime, err, warnings, mStats)
 		i++
 	}
 
-	return s
+	return s, profile
 }
 
-func printMetrics(agg *aggregator.BufferedAggregator) {
-	series := agg.GetSeries()
-	if len(series) != 0 {
+func getAggregatorOutput(agg *aggregator.BufferedAggregator) aggregatorOutput {
+	return aggregatorOutput{
+		Series:        agg.GetSeries(),
+		Sketches:      agg.GetSketches(),
+		ServiceChecks: agg.GetServiceChecks(),
+		Events:        agg.GetEvents(),
+	}
+}
+
+func printMetrics(output aggregatorOutput) {
+	if len(output.Series) != 0 {
 		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Series")))
-		j, _ := json.MarshalIndent(series, "", "  ")
+		j, _ := json.MarshalIndent(metrics.Series(output.Series), "", "  ")
 		fmt.Println(string(j))
 	}
 
-	sketches := agg.GetSketches()
-	if len(sketches) != 0 {
+	if len(output.Sketches) != 0 {
 		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Sketches")))
-		j, _ := json.MarshalIndent(sketches, "", "  ")
+		j, _ := json.MarshalIndent(metrics.SketchSeriesList(output.Sketches), "", "  ")
 		fmt.Println(string(j))
 	}
 
-	serviceChecks := agg.GetServiceChecks()
-	if len(serviceChecks) != 0 {
+	if len(output.ServiceChecks) != 0 {
 		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Service Checks")))
-		j, _ := json.MarshalIndent(serviceChecks, "", "  ")
+		j, _ := json.MarshalIndent(metrics.ServiceChecks(output.ServiceChecks), "", "  ")
 		fmt.Println(string(j))
 	}
 
-	events := agg.GetEvents()
-	if len(events) != 0 {
+	if len(output.Events) != 0 {
 		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Events")))
-		j, _ := json.MarshalIndent(events, "", "  ")
+		j, _ := json.MarshalIndent(metrics.Events(output.Events), "", "  ")
 		fmt.Println(string(j))
 	}
 }
+
+func printProfile(profile []runProfile) {
+	fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Profile")))
+	for i, run := range profile {
+		fmt.Printf("Run %d: %dms, %d bytes allocated in %d allocations, heap delta %d bytes\n",
+			i+1, run.ExecutionTimeMs, run.AllocatedBytes, run.Allocations, run.HeapDeltaBytes)
+	}
+	fmt.Println("Only the memory allocated by the Go runtime is accounted for.")
+}
@@ -20,7 +20,7 @@ var withDebug bool
 func init() {
 	AgentCmd.AddCommand(configCheckCommand)
 
-	configCheckCommand.Flags().BoolVarP(&withDebug, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().BoolVarP(&withDebug, "verbose", "v", false, "print how the configurations were resolved and additional debug info")
 }
 
 var configCheckCommand = &cobra.Command{
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var captureDuration time.Duration
+
+func init() {
+	AgentCmd.AddCommand(dogstatsdCaptureCmd)
+	dogstatsdCaptureCmd.Flags().DurationVarP(&captureDuration, "duration", "d", time.Minute, "Duration of the capture")
+}
+
+var dogstatsdCaptureCmd = &cobra.Command{
+	Use:   "dogstatsd-capture",
+	Short: "Record the dogstatsd traffic of a running agent to a file",
+	Long:  `The file can be fed back to a running agent with the dogstatsd-replay command`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		if flagNoColor {
+			color.NoColor = true
+		}
+		c := util.GetClient(false) // FIX: get certificates right then make this true
+
+		// Set session token
+		err = util.SetAuthToken()
+		if err != nil {
+			return err
+		}
+
+		urlstr := fmt.Sprintf("https://localhost:%v/agent/dogstatsd-capture?duration=%s", config.Datadog.GetInt("cmd_port"), url.QueryEscape(captureDuration.String()))
+		r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer([]byte{}))
+		if err != nil {
+			if r != nil && string(r) != "" {
+				fmt.Fprintln(color.Output, fmt.Sprintf("The agent ran into an error while starting the capture: %s", string(r)))
+			} else {
+				fmt.Fprintln(color.Output, fmt.Sprintf("Failed to query the agent (running?): %s", err))
+			}
+			return err
+		}
+
+		fmt.Fprintln(color.Output, fmt.Sprintf("Capturing the dogstatsd traffic for %s to %s", captureDuration, color.GreenString(string(r))))
+		return nil
+	},
+}
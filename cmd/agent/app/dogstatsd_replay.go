@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/replay"
+)
+
+var (
+	replayFile      string
+	replayFast      bool
+	replayUseSocket bool
+)
+
+func init() {
+	AgentCmd.AddCommand(dogstatsdReplayCmd)
+	dogstatsdReplayCmd.Flags().StringVarP(&replayFile, "file", "f", "", "Capture file to replay")
+	dogstatsdReplayCmd.Flags().BoolVarP(&replayFast, "fast", "", false, "Send the packets as fast as possible instead of keeping their timing")
+	dogstatsdReplayCmd.Flags().BoolVarP(&replayUseSocket, "socket", "s", false, "Send the packets to the dogstatsd_socket instead of the UDP port")
+}
+
+var dogstatsdReplayCmd = &cobra.Command{
+	Use:   "dogstatsd-replay",
+	Short: "Send the dogstatsd traffic recorded by dogstatsd-capture to a running agent",
+	Long:  `The origin of the packets is not replayed, as it is detected from the sender`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		if flagNoColor {
+			color.NoColor = true
+		}
+		if replayFile == "" {
+			return fmt.Errorf("a capture file is required, see --file")
+		}
+
+		file, err := os.Open(replayFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader, err := replay.NewReader(file)
+		if err != nil {
+			return err
+		}
+
+		var conn net.Conn
+		if replayUseSocket {
+			conn, err = net.Dial("unixgram", config.Datadog.GetString("dogstatsd_socket"))
+		} else {
+			conn, err = net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port")))
+		}
+		if err != nil {
+			return fmt.Errorf("could not connect to dogstatsd: %s", err)
+		}
+		defer conn.Close()
+
+		sent, err := replay.Replay(reader, func(contents []byte) error {
+			_, err := conn.Write(contents)
+			return err
+		}, !replayFast)
+		fmt.Fprintln(color.Output, fmt.Sprintf("Replayed %s packets", color.GreenString(fmt.Sprintf("%d", sent))))
+		return err
+	},
+}
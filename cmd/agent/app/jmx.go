@@ -183,6 +183,11 @@ func loadConfigs() {
 
 	for _, c := range configs {
 		if check.IsJMXConfig(c.Name, c.InitConfig) && (includeEverything || configIncluded(c)) {
+			c, err := integration.DecryptConfig(c)
+			if err != nil {
+				fmt.Printf("Config %s was not loaded: %s\n", c.Name, err)
+				continue
+			}
 			fmt.Println("Config ", c.Name, " was loaded.")
 			jmx.AddScheduledConfig(c)
 		}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build cpython
+
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	AgentCmd.AddCommand(python3Cmd)
+}
+
+var python3Cmd = &cobra.Command{
+	Use:   "python3 [check]",
+	Short: "Report the custom checks, or the given one, that are not compatible with Python 3",
+	Long: `Runs the Python 3 porting checker of pylint against the custom checks
+of the checks.d directories, pylint must be installed in the embedded Python.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		checks := getCustomChecks()
+		if len(args) > 0 {
+			path, found := checks[args[0]]
+			if !found {
+				return fmt.Errorf("no custom check named %s found", args[0])
+			}
+			checks = map[string]string{args[0]: path}
+		}
+		if len(checks) == 0 {
+			fmt.Println("No custom check found")
+			return nil
+		}
+		return validatePython3(checks)
+	},
+}
+
+// getCustomChecks returns the paths of the custom checks, by name
+func getCustomChecks() map[string]string {
+	checks := make(map[string]string)
+	dirs := []string{
+		filepath.Join(common.GetDistPath(), "checks.d"),
+		config.Datadog.GetString("additional_checksd"),
+	}
+	// the first directories take precedence, as in the Python path
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if f.IsDir() || filepath.Ext(f.Name()) != ".py" {
+				continue
+			}
+			name := strings.TrimSuffix(f.Name(), ".py")
+			if _, found := checks[name]; !found {
+				checks[name] = filepath.Join(dir, f.Name())
+			}
+		}
+	}
+	return checks
+}
+
+// validatePython3 runs the porting checker against the checks and prints its findings
+func validatePython3(checks map[string]string) error {
+	pyPath, err := getCommandPython()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	notReady := 0
+	for _, name := range names {
+		warnings, err := runPy3kLinter(pyPath, checks[name])
+		if err != nil {
+			return err
+		}
+		if len(warnings) == 0 {
+			fmt.Printf("%s: %s\n", color.GreenString(name), "Python 3 ready")
+			continue
+		}
+		notReady++
+		fmt.Printf("%s: %s (%s)\n", color.YellowString(name), "not Python 3 ready", checks[name])
+		for _, warning := range warnings {
+			fmt.Printf("  * %s\n", warning)
+		}
+	}
+	fmt.Printf("\n%d of %d custom checks not ready for Python 3\n", notReady, len(checks))
+	return nil
+}
+
+// runPy3kLinter returns the Python 3 incompatibilities pylint found in the file
+func runPy3kLinter(pyPath, path string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(pyPath, "-m", "pylint", "--py3k", "--reports=n", "--score=n",
+		"--msg-template={line}: {msg} ({symbol})", path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// pylint exits with a non-zero status when it found issues
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok || stdout.Len() == 0 {
+			return nil, fmt.Errorf("could not run pylint, is it installed in %s? %s %s", pyPath, err, stderr.String())
+		}
+	}
+
+	var warnings []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// skip the module headers and the empty lines
+		if line == "" || strings.HasPrefix(line, "*") {
+			continue
+		}
+		warnings = append(warnings, line)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var previousAPIKey string
+
+func init() {
+	AgentCmd.AddCommand(rotateAPIKeyCmd)
+	rotateAPIKeyCmd.Flags().StringVarP(&previousAPIKey, "previous", "p", "", "API key to replace, the main one by default")
+}
+
+var rotateAPIKeyCmd = &cobra.Command{
+	Use:   "rotate-api-key <api key>",
+	Short: "Replace an API key of a running agent",
+	Long: `The new API key is validated, then used by the forwarder without restarting the agent.
+Update the configuration file as well to keep it after a restart.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		if flagNoColor {
+			color.NoColor = true
+		}
+		c := util.GetClient(false) // FIX: get certificates right then make this true
+
+		// Set session token
+		err = util.SetAuthToken()
+		if err != nil {
+			return err
+		}
+
+		body, err := json.Marshal(map[string]string{
+			"api_key":          args[0],
+			"previous_api_key": previousAPIKey,
+		})
+		if err != nil {
+			return err
+		}
+
+		urlstr := fmt.Sprintf("https://localhost:%v/agent/config/api_key", config.Datadog.GetInt("cmd_port"))
+		r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			if r != nil && string(r) != "" {
+				fmt.Fprintln(color.Output, fmt.Sprintf("The agent ran into an error while rotating the API key: %s", string(r)))
+			} else {
+				fmt.Fprintln(color.Output, fmt.Sprintf("Failed to query the agent (running?): %s", err))
+			}
+			return err
+		}
+
+		fmt.Fprintln(color.Output, color.GreenString("The API key was rotated"))
+		return nil
+	},
+}
@@ -41,7 +41,11 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/containers"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/embed"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/network"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/openmetrics"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/pdh"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/systemd"
 
 	// register metadata providers
 	_ "github.com/DataDog/datadog-agent/pkg/collector/metadata"
@@ -195,6 +199,10 @@ func StartAgent() error {
 
 	// create and setup the Autoconfig instance
 	common.SetupAutoConfig(config.Datadog.GetString("confd_path"))
+	// collect the logs configurations of autodiscovery
+	if scheduler := logs.GetScheduler(); scheduler != nil {
+		common.MetaScheduler.Register("logs", scheduler)
+	}
 	// start the autoconfig, this will immediately run any configured check
 	common.StartAutoConfig()
 
@@ -216,6 +224,7 @@ func StartAgent() error {
 // setupMetadataCollection initializes the metadata scheduler and its collectors based on the config
 func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 	addDefaultResourcesCollector := true
+	addDefaultInventoriesCollector := true
 	common.MetadataScheduler = metadata.NewScheduler(s, hostname)
 	var C []config.MetadataProviders
 	err := config.Datadog.UnmarshalKey("metadata_providers", &C)
@@ -228,6 +237,9 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 			if c.Name == "resources" {
 				addDefaultResourcesCollector = false
 			}
+			if c.Name == "inventories" {
+				addDefaultInventoriesCollector = false
+			}
 			if c.Interval == 0 {
 				log.Infof("Interval of metadata provider '%v' set to 0, skipping provider", c.Name)
 				continue
@@ -258,6 +270,12 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 			log.Warn("Could not add resources metadata provider: ", err)
 		}
 	}
+	if addDefaultInventoriesCollector {
+		err = common.MetadataScheduler.AddCollector("inventories", defaultInventoriesMetadataCollectorInterval*time.Second)
+		if err != nil {
+			log.Warn("Could not add inventories metadata provider: ", err)
+		}
+	}
 
 	return nil
 }
@@ -11,15 +11,16 @@ import (
 )
 
 // AutoAddListeners checks if the listener auto is selected and
-// adds the docker listener if the host support it
-// no effect if the listeners already contain the docker one
+// adds the docker listener if the host support it, or the ecs one
+// in an ECS Fargate task, the docker socket being unavailable there
+// no effect if the listeners already contain the docker or ecs one
 // Note: auto listener isn't a real listener but a way to automatically starts other listeners
 // TODO: support more listeners (kubelet, ...)
 func AutoAddListeners(listeners []config.Listeners) []config.Listeners {
 	autoIdx := -1
 	for i, l := range listeners {
 		switch l.Name {
-		case "docker":
+		case "docker", "ecs":
 			return listeners
 		case "auto":
 			autoIdx = i
@@ -32,12 +33,12 @@ func AutoAddListeners(listeners []config.Listeners) []config.Listeners {
 	// Remove the auto listener element from the listeners slice
 	listeners = remove(listeners, autoIdx)
 
-	if isDockerRunning() == false {
-		return listeners
-	}
-
 	// Adding listeners
-	listeners = addListener(listeners, "docker")
+	if isDockerRunning() {
+		listeners = addListener(listeners, "docker")
+	} else if isFargateInstance() {
+		listeners = addListener(listeners, "ecs")
+	}
 	log.Debugf("returning %d listeners", len(listeners))
 	return listeners
 }
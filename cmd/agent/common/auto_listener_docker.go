@@ -9,6 +9,7 @@ package common
 
 import (
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-agent/pkg/util/ecs"
 )
 
 // isDockerRunning check if docker is running
@@ -16,3 +17,8 @@ func isDockerRunning() bool {
 	_, err := docker.GetDockerUtil()
 	return err == nil
 }
+
+// isFargateInstance check if the agent runs in an ECS Fargate task
+func isFargateInstance() bool {
+	return ecs.IsFargateInstance()
+}
@@ -11,3 +11,8 @@ package common
 func isDockerRunning() bool {
 	return false
 }
+
+// isFargateInstance check if the agent runs in an ECS Fargate task
+func isFargateInstance() bool {
+	return false
+}
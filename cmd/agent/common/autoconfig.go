@@ -33,13 +33,13 @@ func SetupAutoConfig(confdPath string) {
 	Coll = collector.NewCollector(GetPythonPaths()...)
 
 	// creating the meta scheduler
-	metaScheduler := scheduler.NewMetaScheduler()
+	MetaScheduler = scheduler.NewMetaScheduler()
 
 	// registering the check scheduler
-	metaScheduler.Register("check", collector.InitCheckScheduler(Coll))
+	MetaScheduler.Register("check", collector.InitCheckScheduler(Coll))
 
 	// create the Autoconfig instance
-	AC = autodiscovery.NewAutoConfig(metaScheduler)
+	AC = autodiscovery.NewAutoConfig(MetaScheduler)
 
 	// Add the configuration providers
 	// File Provider is hardocded and always enabled
@@ -47,7 +47,7 @@ func SetupAutoConfig(confdPath string) {
 		confdPath,
 		filepath.Join(GetDistPath(), "conf.d"),
 	}
-	AC.AddProvider(providers.NewFileConfigProvider(confSearchPaths), false)
+	AC.AddProvider(providers.NewFileConfigProvider(confSearchPaths), false, 0)
 
 	// Register additional configuration providers
 	var CP []config.ConfigurationProviders
@@ -58,7 +58,7 @@ func SetupAutoConfig(confdPath string) {
 			if found {
 				configProvider, err := factory(cp)
 				if err == nil {
-					AC.AddProvider(configProvider, cp.Polling)
+					AC.AddProvider(configProvider, cp.Polling, providers.GetPollInterval(cp))
 					log.Infof("Registering %s config provider", cp.Name)
 				} else {
 					log.Errorf("Error while adding config provider %v: %v", cp.Name, err)
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
@@ -26,6 +27,9 @@ var (
 	// Coll is the global collector instance
 	Coll *collector.Collector
 
+	// MetaScheduler dispatches the configurations collected by AC to the registered schedulers
+	MetaScheduler *scheduler.MetaScheduler
+
 	// DSD is the global dogstastd instance
 	DSD *dogstatsd.Server
 
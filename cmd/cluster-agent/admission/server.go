@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+/*
+Package admission implements the HTTPS server receiving the admission
+reviews of the mutating webhook registered in the apiserver.
+*/
+package admission
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	stdLog "log"
+	"net"
+	"net/http"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// InjectPath is the path of the webhook, to set in the MutatingWebhookConfiguration
+const InjectPath = "/injectconfig"
+
+var listener net.Listener
+
+// StartServer starts the HTTPS server of the admission webhook
+func StartServer() error {
+	certFile := config.Datadog.GetString("admission_controller.tls_cert_file")
+	keyFile := config.Datadog.GetString("admission_controller.tls_key_file")
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load the certificate of the admission controller: %s", err)
+	}
+	tlsConfig := tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	listener, err = net.Listen("tcp", fmt.Sprintf(":%d", config.Datadog.GetInt("admission_controller.port")))
+	if err != nil {
+		return fmt.Errorf("unable to create the admission controller server: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(InjectPath, serveMutate)
+	srv := &http.Server{
+		Handler:   mux,
+		ErrorLog:  stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		TLSConfig: &tlsConfig,
+	}
+
+	go srv.Serve(tls.NewListener(listener, &tlsConfig))
+	log.Infof("Started the admission controller server on %s", listener.Addr())
+	return nil
+}
+
+// StopServer closes the connection and the server
+// stops listening to new admission reviews.
+func StopServer() {
+	if listener != nil {
+		listener.Close()
+	}
+}
+
+func serveMutate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("invalid method %s, only POST is supported", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Errorf("Could not read the admission review: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1beta1.AdmissionReview{}
+	if err = json.Unmarshal(body, &review); err != nil {
+		log.Errorf("Could not decode the admission review: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "the admission review has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = admission.Mutate(review.Request)
+	review.Request = nil
+	response, err := json.Marshal(review)
+	if err != nil {
+		log.Errorf("Could not encode the admission review: %s", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type scope string
+
+const (
+	// metadataScope covers the read-only routes queried by the node agents,
+	// authorized with the cluster agent token or a client certificate.
+	metadataScope scope = "metadata"
+	// adminScope covers all the other routes, authorized with the internal token only.
+	adminScope scope = "admin"
+)
+
+// metadataRoutes are the routes of the metadataScope
+var metadataRoutes = []*regexp.Regexp{
+	regexp.MustCompile(`^/version$`),
+	regexp.MustCompile(`^/api/v1/metadata/[^/]+/[^/]+/[^/]+$`),
+	regexp.MustCompile(`^/api/v1/tags/node/[^/]+$`),
+	regexp.MustCompile(`^/api/v1/clusterchecks/configs/[^/]+$`),
+	regexp.MustCompile(`^/api/v1/clusterchecks/templates$`),
+}
+
+// routeScope returns the scope required to query a path.
+func routeScope(path string) scope {
+	for _, route := range metadataRoutes {
+		if route.MatchString(path) {
+			return metadataScope
+		}
+	}
+	return adminScope
+}
+
+// hasVerifiedClientCert returns whether the request was made with a client
+// certificate signed by the configured client CA.
+func hasVerifiedClientCert(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
+
+// We only want to maintain 1 API and expose external routes to the node agents.
+// As we have 2 different tokens for the validation, we need to validate according to the scope of the route.
+func validateToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch routeScope(r.URL.Path) {
+		case metadataScope:
+			if !hasVerifiedClientCert(r) {
+				if err := util.ValidateDCARequest(w, r); err != nil {
+					return
+				}
+			}
+		default:
+			if err := util.Validate(w, r); err != nil {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getTLSConfig returns the TLS configuration of the server.
+// The certificate is read from cluster_agent.tls.cert_file and cluster_agent.tls.key_file if set,
+// otherwise a self-signed one is generated. When cluster_agent.tls.client_ca_file is set,
+// the client certificates signed by it are verified. The files are reloaded when rotated.
+func getTLSConfig() (*tls.Config, error) {
+	certFile := config.Datadog.GetString("cluster_agent.tls.cert_file")
+	keyFile := config.Datadog.GetString("cluster_agent.tls.key_file")
+	clientCAFile := config.Datadog.GetString("cluster_agent.tls.client_ca_file")
+
+	tlsConfig := &tls.Config{}
+	if certFile != "" && keyFile != "" {
+		keyPair, err := security.NewKeyPairReloader(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetCertificate = keyPair.GetCertificate
+	} else {
+		cert, err := generateSelfSignedCert()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if clientCAFile == "" {
+		return tlsConfig, nil
+	}
+	clientCAs, err := security.NewCertPoolReloader(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Verifying the client certificates of the cluster agent API with %s", clientCAFile)
+	// The CLI commands authenticate with the internal token only, so the
+	// client certificate is not required.
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		clientConfig := tlsConfig.Clone()
+		clientConfig.GetConfigForClient = nil
+		clientConfig.ClientCAs = clientCAs.Pool()
+		return clientConfig, nil
+	}
+	return tlsConfig, nil
+}
+
+func generateSelfSignedCert() (tls.Certificate, error) {
+	hosts := []string{"127.0.0.1", "localhost"}
+	_, rootCertPEM, rootKey, err := security.GenerateRootCert(hosts, 2048)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to start TLS server")
+	}
+
+	// PEM encode the private key
+	rootKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rootKey),
+	})
+
+	// Create a TLS cert using the private key and certificate
+	rootTLSCert, err := tls.X509KeyPair(rootCertPEM, rootKeyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid key pair: %v", err)
+	}
+	return rootTLSCert, nil
+}
@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// forwardedHeader is set on the requests forwarded to the leader, which
+	// serves them even if it lost the leadership in the meantime, to avoid loops.
+	forwardedHeader = "X-DCA-Forwarded-By"
+
+	leaderIPCacheKey    = "leaderIP"
+	leaderIPCacheExpire = 30 * time.Second
+)
+
+// leaderRoutes are the routes of the node agents served by the state of the
+// leader: the cluster checks it dispatched, and the metadata for consistency.
+// The other routes, like the cluster checks state, describe the replica queried.
+var leaderRoutes = []*regexp.Regexp{
+	regexp.MustCompile(`^/api/v1/metadata/[^/]+/[^/]+/[^/]+$`),
+	regexp.MustCompile(`^/api/v1/tags/node/[^/]+$`),
+	regexp.MustCompile(`^/api/v1/clusterchecks/configs/[^/]+$`),
+}
+
+var leaderProxyTransport = &http.Transport{
+	// The replicas serve the same self-signed or cluster agent certificate
+	TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+}
+
+// forwardToLeader proxies the requests of the leaderRoutes to the leader when
+// the replica is a follower, so the node agents do not need to know the leader.
+func forwardToLeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !shouldForward(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		le, err := leaderelection.GetLeaderEngine()
+		if err != nil || le.IsLeader() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		leaderName := le.GetLeader()
+		if leaderName == "" {
+			http.Error(w, "no leader elected yet", http.StatusServiceUnavailable)
+			return
+		}
+		leaderIP, err := getLeaderIP(leaderName)
+		if err != nil {
+			log.Errorf("Could not forward the request %s to the leader %s: %s", r.URL.Path, leaderName, err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		target := &url.URL{
+			Scheme: "https",
+			Host:   net.JoinHostPort(leaderIP, strconv.Itoa(config.Datadog.GetInt("cluster_agent.cmd_port"))),
+		}
+		log.Tracef("Forwarding the request %s to the leader %s at %s", r.URL.Path, leaderName, target.Host)
+		proxy := httputil.NewSingleHostReverseProxy(target)
+		proxy.Transport = leaderProxyTransport
+		proxy.ErrorLog = nil
+
+		// The request is authorized by this replica, the client may have used a certificate
+		r.Header.Set(forwardedHeader, le.HolderIdentity)
+		r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", getDCAToken()))
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+func shouldForward(r *http.Request) bool {
+	if !config.Datadog.GetBool("leader_election") || !config.Datadog.GetBool("cluster_agent.forward_to_leader") {
+		return false
+	}
+	if r.Header.Get(forwardedHeader) != "" {
+		return false
+	}
+	for _, route := range leaderRoutes {
+		if route.MatchString(r.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// getLeaderIP returns the IP of the pod of the leader, its name being the identity of the leader.
+func getLeaderIP(leaderName string) (string, error) {
+	cacheKey := cache.BuildAgentKey(leaderIPCacheKey, leaderName)
+	if ip, found := cache.Cache.Get(cacheKey); found {
+		return ip.(string), nil
+	}
+	cl, err := apiserver.GetAPIClient()
+	if err != nil {
+		return "", err
+	}
+	pod, err := cl.Client().CoreV1().Pods(apiserver.GetResourcesNamespace()).Get(leaderName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not get the pod of the leader: %s", err)
+	}
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("the pod of the leader %s has no IP", leaderName)
+	}
+	cache.Cache.Set(cacheKey, pod.Status.PodIP, leaderIPCacheExpire)
+	return pod.Status.PodIP, nil
+}
+
+func getDCAToken() string {
+	if token := config.Datadog.GetString("cluster_agent.auth_token"); token != "" {
+		return token
+	}
+	return util.GetDCAAuthToken()
+}
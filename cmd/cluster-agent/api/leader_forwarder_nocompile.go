@@ -0,0 +1,17 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubeapiserver
+
+package api
+
+import (
+	"net/http"
+)
+
+// forwardToLeader is a no-op without leader election
+func forwardToLeader(next http.Handler) http.Handler {
+	return next
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestShouldForward(t *testing.T) {
+	config.Datadog.Set("leader_election", true)
+	defer config.Datadog.Set("leader_election", false)
+
+	tests := []struct {
+		path      string
+		forwarded bool
+		expected  bool
+	}{
+		{"/api/v1/metadata/node/namespace/pod", false, true},
+		{"/api/v1/tags/node/node", false, true},
+		{"/api/v1/clusterchecks/configs/node", false, true},
+		{"/api/v1/clusterchecks", false, false},
+		{"/api/v1/clusterchecks/templates", false, false},
+		{"/api/v1/clusterchecks/configs/node", true, false},
+		{"/version", false, false},
+		{"/status", false, false},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("#%d %s", i, tt.path), func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			if tt.forwarded {
+				req.Header.Set(forwardedHeader, "dca-follower")
+			}
+			assert.Equal(t, tt.expected, shouldForward(req))
+		})
+	}
+
+	config.Datadog.Set("cluster_agent.forward_to_leader", false)
+	defer config.Datadog.Set("cluster_agent.forward_to_leader", true)
+	req, err := http.NewRequest("GET", "/api/v1/clusterchecks/configs/node", nil)
+	require.NoError(t, err)
+	assert.False(t, shouldForward(req))
+}
@@ -12,16 +12,12 @@ package api
 
 import (
 	"crypto/tls"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
 	stdLog "log"
 	"net"
 	"net/http"
-	"strings"
 
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/api/agent"
-	"github.com/DataDog/datadog-agent/pkg/api/security"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/gorilla/mux"
@@ -42,6 +38,9 @@ func StartServer() error {
 	// Validate token for every request
 	r.Use(validateToken)
 
+	// Followers forward the requests relying on the state of the leader
+	r.Use(forwardToLeader)
+
 	// get the transport we're going to use under HTTP
 	var err error
 	listener, err = getListener()
@@ -56,35 +55,18 @@ func StartServer() error {
 	// DCA client token
 	util.SetDCAAuthToken()
 
-	// create cert
-	hosts := []string{"127.0.0.1", "localhost"}
-	_, rootCertPEM, rootKey, err := security.GenerateRootCert(hosts, 2048)
-	if err != nil {
-		return fmt.Errorf("unable to start TLS server")
-	}
-
-	// PEM encode the private key
-	rootKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rootKey),
-	})
-
-	// Create a TLS cert using the private key and certificate
-	rootTLSCert, err := tls.X509KeyPair(rootCertPEM, rootKeyPEM)
+	tlsConfig, err := getTLSConfig()
 	if err != nil {
-		return fmt.Errorf("invalid key pair: %v", err)
-	}
-
-	tlsConfig := tls.Config{
-		Certificates: []tls.Certificate{rootTLSCert},
+		return err
 	}
 
 	srv := &http.Server{
 		Handler:   r,
 		ErrorLog:  stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
-		TLSConfig: &tlsConfig,
+		TLSConfig: tlsConfig,
 	}
 
-	tlsListener := tls.NewListener(listener, &tlsConfig)
+	tlsListener := tls.NewListener(listener, tlsConfig)
 
 	go srv.Serve(tlsListener)
 	return nil
@@ -97,21 +79,3 @@ func StopServer() {
 		listener.Close()
 	}
 }
-
-// We only want to maintain 1 API and expose an external route to serve the cluster level metadata.
-// As we have 2 different tokens for the validation, we need to validate accordingly.
-func validateToken(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.String()
-		if strings.HasPrefix(path, "/api/v1/metadata/") && len(strings.Split(path, "/")) == 7 || path == "/version" {
-			if err := util.ValidateDCARequest(w, r); err != nil {
-				return
-			}
-		} else {
-			if err := util.Validate(w, r); err != nil {
-				return
-			}
-		}
-		next.ServeHTTP(w, r)
-	})
-}
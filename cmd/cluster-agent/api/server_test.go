@@ -6,11 +6,17 @@
 package api
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/DataDog/datadog-agent/pkg/api/security"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/stretchr/testify/assert"
@@ -50,6 +56,31 @@ func TestValidateTokenMiddleware(t *testing.T) {
 			"bandit!",
 			http.StatusForbidden,
 		},
+		{
+			"/api/v1/tags/node/node",
+			"abc123",
+			http.StatusOK,
+		},
+		{
+			"/api/v1/clusterchecks/configs/node",
+			"abc123",
+			http.StatusOK,
+		},
+		{
+			"/api/v1/clusterchecks/templates",
+			"abc123",
+			http.StatusOK,
+		},
+		{
+			"/api/v1/clusterchecks",
+			"abc123",
+			http.StatusForbidden,
+		},
+		{
+			"/status",
+			"abc123",
+			http.StatusForbidden,
+		},
 	}
 
 	for i, tt := range tests {
@@ -73,3 +104,52 @@ func TestValidateTokenMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTokenClientCert(t *testing.T) {
+	config.Datadog.Set("cluster_agent.auth_token", "abc123")
+	util.SetDCAAuthToken()
+
+	nopHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	handler := validateToken(http.HandlerFunc(nopHandler))
+	verifiedState := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+
+	// A verified client certificate grants the metadata scope
+	req, err := http.NewRequest("GET", "/api/v1/metadata/node/namespace/pod", nil)
+	require.NoError(t, err)
+	req.TLS = verifiedState
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// but not the admin scope
+	req, err = http.NewRequest("GET", "/status", nil)
+	require.NoError(t, err)
+	req.TLS = verifiedState
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestGetTLSConfigClientCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dca-client-ca-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	_, certPEM, _, err := security.GenerateRootCert([]string{"localhost"}, 1024)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(caFile, certPEM, 0600))
+
+	config.Datadog.Set("cluster_agent.tls.client_ca_file", caFile)
+	defer config.Datadog.Set("cluster_agent.tls.client_ca_file", "")
+	tlsConfig, err := getTLSConfig()
+	require.NoError(t, err)
+
+	// the pool of client CAs is resolved on every handshake, to follow the rotations of the file
+	clientConfig, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, clientConfig.ClientAuth)
+	assert.Len(t, clientConfig.ClientCAs.Subjects(), 1)
+	assert.NotEmpty(t, clientConfig.Certificates)
+}
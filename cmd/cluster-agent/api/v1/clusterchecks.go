@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// installClusterCheckEndpoints registers endpoints for cluster checks
+func installClusterCheckEndpoints(r *mux.Router) {
+	r.HandleFunc("/clusterchecks/configs/{nodeName}", getCheckConfigs).Methods("GET")
+	r.HandleFunc("/clusterchecks", getState).Methods("GET")
+	r.HandleFunc("/clusterchecks/templates", getTemplates).Methods("GET")
+}
+
+// getCheckConfigs is used by the node agents to get the cluster checks dispatched to them.
+func getCheckConfigs(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5005/api/v1/clusterchecks/configs/localhost
+		Outputs
+			Status: 200
+			Returns: clusterchecks.ConfigResponse
+			Example: {"last_change":1530543841,"configs":[{"check_name":"postgres","instances":["aG9zdDogZGIK"],...}]}
+
+			Status: 404
+			Returns: string
+			Example: cluster checks are not enabled
+	*/
+	dispatcher := clusterchecks.GetDispatcher()
+	if dispatcher == nil {
+		http.Error(w, "cluster checks are not enabled", http.StatusNotFound)
+		return
+	}
+	nodeName := mux.Vars(r)["nodeName"]
+	writeJSONResponse(w, dispatcher.GetNodeConfigs(nodeName))
+}
+
+// getState is used by the clusterchecks command to display the dispatching state.
+func getState(w http.ResponseWriter, r *http.Request) {
+	dispatcher := clusterchecks.GetDispatcher()
+	if dispatcher == nil {
+		http.Error(w, "cluster checks are not enabled", http.StatusNotFound)
+		return
+	}
+	writeJSONResponse(w, dispatcher.GetState())
+}
+
+// getTemplates is used by the node agents to get the check templates collected
+// by the cluster agent, e.g. from the ConfigMaps.
+func getTemplates(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5005/api/v1/clusterchecks/templates
+		Outputs
+			Status: 200
+			Returns: clusterchecks.ConfigResponse
+			Example: {"last_change":1530543841,"configs":[{"check_name":"redisdb","ad_identifiers":["redis"],...}]}
+	*/
+	writeJSONResponse(w, clusterchecks.GetTemplates())
+}
+
+func writeJSONResponse(w http.ResponseWriter, data interface{}) {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		log.Errorf("Could not serialize the response: %s", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(bytes)
+}
@@ -25,7 +25,10 @@ func Install(r *mux.Router) {
 	r.HandleFunc("/metadata/{nodeName}/{ns}/{podName}", getPodMetadata).Methods("GET")
 	r.HandleFunc("/metadata/{nodeName}", getNodeMetadata).Methods("GET")
 	r.HandleFunc("/metadata", getAllMetadata).Methods("GET")
+	r.HandleFunc("/tags/node/{nodeName}", getNodeLabels).Methods("GET")
 	r.HandleFunc("/events/{check}", getCheckLatestEvents).Methods("GET")
+	r.HandleFunc("/leader", getLeader).Methods("GET")
+	installClusterCheckEndpoints(r)
 }
 
 func getCheckLatestEvents(w http.ResponseWriter, r *http.Request) {
@@ -89,6 +92,39 @@ func getPodMetadata(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// getNodeLabels is used by the node agents to collect the labels of their node as host tags.
+func getNodeLabels(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5001/api/v1/tags/node/localhost
+		Outputs
+			Status: 200
+			Returns: map[string]string
+			Example: {"kubernetes.io/hostname":"localhost","topology.kubernetes.io/zone":"us-east-1a"}
+
+			Status: 404
+			Returns: string
+			Example: no labels were found for the node localhost
+	*/
+	vars := mux.Vars(r)
+	nodeName := vars["nodeName"]
+	nodeLabels, err := as.GetNodeLabels(nodeName)
+	if err != nil {
+		log.Debugf("Could not retrieve the labels of the node %s: %s", nodeName, err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	labelsBytes, err := json.Marshal(nodeLabels)
+	if err != nil {
+		log.Errorf("Could not process the labels of the node %s: %s", nodeName, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(labelsBytes)
+}
+
 // getNodeMetadata has the same signature as getAllMetadata, but is only scoped on one node.
 func getNodeMetadata(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type leaderResponse struct {
+	leaderelection.LeaderDetails
+	Identity string `json:"identity,omitempty"`
+	IsLeader bool   `json:"is_leader"`
+}
+
+// getLeader returns the identity of the replica holding the leadership.
+func getLeader(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5005/api/v1/leader
+		Outputs
+			Status: 200
+			Returns: leaderResponse
+			Example: {"leader":"dca-5d69-xk2","acquired_time":"2018-07-02T13:24:01Z","renewed_time":"2018-07-02T14:01:31Z","leader_transitions":2,"held_for_seconds":2252.1,"identity":"dca-5d69-xk2","is_leader":true}
+
+			Status: 404
+			Returns: string
+			Example: leader election is not enabled
+
+			Status: 500
+			Returns: string
+			Example: configmaps "datadog-leader-election" not found
+	*/
+	if !config.Datadog.GetBool("leader_election") {
+		http.Error(w, "leader election is not enabled", http.StatusNotFound)
+		return
+	}
+	details, err := leaderelection.GetLeaderDetails()
+	if err != nil {
+		log.Errorf("Could not retrieve the leader election record: %s", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := leaderResponse{LeaderDetails: details}
+	if le, err := leaderelection.GetLeaderEngine(); err == nil {
+		resp.Identity = le.HolderIdentity
+		resp.IsLeader = le.IsLeader()
+	}
+
+	respBytes, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBytes)
+}
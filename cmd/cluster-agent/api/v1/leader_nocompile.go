@@ -0,0 +1,18 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubeapiserver
+
+package v1
+
+import (
+	"net/http"
+
+	as "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+func getLeader(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, as.ErrNotCompiled.Error(), http.StatusNotFound)
+}
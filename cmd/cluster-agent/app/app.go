@@ -8,6 +8,7 @@
 package app
 
 import (
+	"crypto/tls"
 	"fmt"
 	"os"
 	"os/signal"
@@ -19,14 +20,18 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/cmd/cluster-agent/admission"
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/api"
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/custommetrics"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/orchestrator"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/version"
 	"github.com/fatih/color"
@@ -153,6 +158,28 @@ func start(cmd *cobra.Command, args []string) error {
 		log.Errorf("Could not start the Cluster Agent Process.")
 
 	}
+	// Share the cached state between the replicas
+	switch backend := config.Datadog.GetString("cluster_agent.shared_cache.backend"); backend {
+	case "":
+	case "redis":
+		var tlsConfig *tls.Config
+		if config.Datadog.GetBool("cluster_agent.shared_cache.redis_tls") {
+			tlsConfig = &tls.Config{
+				InsecureSkipVerify: config.Datadog.GetBool("cluster_agent.shared_cache.redis_tls_skip_verify"),
+			}
+		}
+		cache.SetSharedBackend(cache.NewRedisBackend(
+			config.Datadog.GetString("cluster_agent.shared_cache.redis_address"),
+			config.Datadog.GetString("cluster_agent.shared_cache.redis_password"),
+			config.Datadog.GetInt("cluster_agent.shared_cache.redis_db"),
+			config.Datadog.GetString("cluster_agent.shared_cache.key_prefix"),
+			tlsConfig,
+		))
+		log.Infof("Using redis at %s as shared cache", config.Datadog.GetString("cluster_agent.shared_cache.redis_address"))
+	default:
+		log.Errorf("Unknown shared cache backend %q, using the in-process cache", backend)
+	}
+
 	// Start the Service Mapper.
 	asc, err := apiserver.GetAPIClient()
 	if err != nil {
@@ -166,9 +193,33 @@ func start(cmd *cobra.Command, args []string) error {
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
 	// create and setup the Autoconfig instance
 	common.SetupAutoConfig(config.Datadog.GetString("confd_path"))
+	// dispatch the cluster checks to the node agents
+	if config.Datadog.GetBool("cluster_checks.enabled") {
+		dispatcher := clusterchecks.SetupDispatcher()
+		common.MetaScheduler.Register("clusterchecks", dispatcher)
+		go dispatcher.Run()
+	}
 	// start the autoconfig, this will immediately run any configured check
 	common.StartAutoConfig()
 
+	// send the manifests of the cluster resources
+	if config.Datadog.GetBool("orchestrator_explorer.enabled") {
+		collector, err := orchestrator.NewCollector(s)
+		if err != nil {
+			log.Errorf("Could not start the orchestrator explorer: %s", err)
+		} else {
+			go collector.Run()
+			defer collector.Stop()
+		}
+	}
+
+	// start the admission controller webhook
+	if config.Datadog.GetBool("admission_controller.enabled") {
+		if err = admission.StartServer(); err != nil {
+			log.Errorf("Could not start the admission controller: %s", err)
+		}
+	}
+
 	// HPA Process
 	if config.Datadog.GetBool("external_metrics_provider.enabled") {
 		err = custommetrics.ValidateArgs(args)
@@ -188,6 +239,9 @@ func start(cmd *cobra.Command, args []string) error {
 	if config.Datadog.GetBool("external_metrics_provider.enabled") {
 		custommetrics.StopServer()
 	}
+	if config.Datadog.GetBool("admission_controller.enabled") {
+		admission.StopServer()
+	}
 	clusterAgent.Stop()
 	log.Info("See ya!")
 	log.Flush()
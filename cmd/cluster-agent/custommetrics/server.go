@@ -78,7 +78,7 @@ func StartServer() error {
 		return err
 	}
 	datadogHPAConfigMap := custommetrics.GetHPAConfigmapName()
-	store, err := custommetrics.NewConfigMapStore(client.Cl, as.GetResourcesNamespace(), datadogHPAConfigMap)
+	store, err := custommetrics.NewConfigMapStore(client.Client, as.GetResourcesNamespace(), datadogHPAConfigMap)
 	if err != nil {
 		return err
 	}
@@ -89,7 +89,7 @@ func StartServer() error {
 	}
 
 	// HPA watcher
-	hpaClient, err := hpa.NewHPAWatcherClient(client.Cl, datadogCl, store)
+	hpaClient, err := hpa.NewHPAWatcherClient(client.Client, datadogCl, store)
 	if err != nil {
 		return err
 	}
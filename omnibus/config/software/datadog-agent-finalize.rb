@@ -74,6 +74,9 @@ build do
 
             # remove windows specific configs
             delete "/etc/datadog-agent/conf.d/winproc.d"
+            delete "/etc/datadog-agent/conf.d/windows_system.d"
+            delete "/etc/datadog-agent/conf.d/pdh_check_native.d"
+            delete "/etc/datadog-agent/conf.d/iis_native.d"
 
             # cleanup clutter
             delete "#{install_dir}/etc"
@@ -83,6 +86,9 @@ build do
 
             # remove windows specific configs
             delete "#{install_dir}/etc/conf.d/winproc.d"
+            delete "#{install_dir}/etc/conf.d/windows_system.d"
+            delete "#{install_dir}/etc/conf.d/pdh_check_native.d"
+            delete "#{install_dir}/etc/conf.d/iis_native.d"
 
             delete "#{install_dir}/etc/trace-agent.conf.example"
 
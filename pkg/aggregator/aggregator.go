@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -90,6 +91,8 @@ var (
 	aggregatorServiceCheck            = expvar.Int{}
 	aggregatorEvent                   = expvar.Int{}
 	aggregatorHostnameUpdate          = expvar.Int{}
+	aggregatorFlushesDelayed          = expvar.Int{}
+	aggregatorNoAggregationFlushed    = expvar.Int{}
 )
 
 func init() {
@@ -120,6 +123,8 @@ func init() {
 	aggregatorExpvars.Set("ServiceCheck", &aggregatorServiceCheck)
 	aggregatorExpvars.Set("Event", &aggregatorEvent)
 	aggregatorExpvars.Set("HostnameUpdate", &aggregatorHostnameUpdate)
+	aggregatorExpvars.Set("FlushesDelayed", &aggregatorFlushesDelayed)
+	aggregatorExpvars.Set("NoAggregationSeriesFlushed", &aggregatorNoAggregationFlushed)
 }
 
 // InitAggregator returns the Singleton instance
@@ -151,10 +156,13 @@ type BufferedAggregator struct {
 	serviceCheckIn     chan metrics.ServiceCheck
 	eventIn            chan metrics.Event
 	sampler            TimeSampler
+	timeSamplerWorkers []*timeSamplerWorker // used instead of the sampler when dogstatsd_pipeline_count > 1
 	checkSamplers      map[check.ID]*CheckSampler
 	distSampler        distSampler
 	serviceChecks      metrics.ServiceChecks
 	events             metrics.Events
+	noAggregationIn    chan *metrics.Serie // the points sent as is, see runNoAggregationStream
+	noAggregationBatch int                 // the number of series sent at once by the no-aggregation stream
 	flushInterval      time.Duration
 	mu                 sync.Mutex // to protect the checkSamplers field
 	serializer         *serializer.Serializer
@@ -163,6 +171,14 @@ type BufferedAggregator struct {
 	hostnameUpdateDone chan struct{}    // signals that the hostname update is finished
 	TickerChan         <-chan time.Time // For test/benchmark purposes: it allows the flush to be controlled from the outside
 	health             *health.Handle
+	expectedTags       []string         // the host tags added to the series until expectedTagsUntil
+	expectedTagsLock   sync.Mutex       // to protect the expectedTags field, fetched in the background
+	expectedTagsUntil  time.Time        // the backend resolves the host tags of the series after that
+	backpressure       bool             // whether the forwarder congestion is tracked on each flush
+	maxDelayedFlushes  int              // the flush is no longer delayed past this number of congested flushes
+	congestedFlushes   int              // the consecutive flushes with every domain of the forwarder congested
+	fwdTelemetry       bool             // whether the forwarder counters are sent as series
+	forwarderCounters  map[string]int64 // the forwarder counters at the previous flush
 }
 
 // NewBufferedAggregator instantiates a BufferedAggregator
@@ -181,11 +197,40 @@ func NewBufferedAggregator(s *serializer.Serializer, hostname string, flushInter
 		hostnameUpdate:     make(chan string),
 		hostnameUpdateDone: make(chan struct{}),
 		health:             health.Register("aggregator"),
+		backpressure:       config.Datadog.GetBool("aggregator_backpressure"),
+		maxDelayedFlushes:  config.Datadog.GetInt("aggregator_backpressure_max_delayed_flushes"),
+		fwdTelemetry:       config.Datadog.GetBool("forwarder_telemetry_enabled"),
+		noAggregationBatch: config.Datadog.GetInt("aggregator_no_aggregation_batch_size"),
+	}
+	if aggregator.noAggregationBatch <= 0 {
+		aggregator.noAggregationBatch = 1
+	}
+	aggregator.noAggregationIn = make(chan *metrics.Serie, aggregator.noAggregationBatch)
+
+	if pipelineCount := config.Datadog.GetInt("dogstatsd_pipeline_count"); pipelineCount > 1 {
+		for i := 0; i < pipelineCount; i++ {
+			aggregator.timeSamplerWorkers = append(aggregator.timeSamplerWorkers, newTimeSamplerWorker(bucketSize, hostname))
+		}
+	}
+
+	if duration := config.Datadog.GetDuration("expected_tags_duration"); duration > 0 {
+		aggregator.expectedTagsUntil = time.Now().Add(duration)
+		// the host tags can wait for the metadata APIs of the cloud providers
+		go aggregator.fetchExpectedTags()
 	}
 
 	return aggregator
 }
 
+// fetchExpectedTags gets the host tags added to the series after the start
+func (agg *BufferedAggregator) fetchExpectedTags() {
+	tags := host.GetHostTags()
+	agg.expectedTagsLock.Lock()
+	agg.expectedTags = tags
+	agg.expectedTagsLock.Unlock()
+	log.Debugf("Adding the host tags %v to the series until %s", tags, agg.expectedTagsUntil)
+}
+
 func deduplicateTags(tags []string) []string {
 	seen := make(map[string]bool, len(tags))
 	idx := 0
@@ -248,6 +293,10 @@ func (agg *BufferedAggregator) deregisterSender(id check.ID) {
 }
 
 func (agg *BufferedAggregator) handleSenderSample(ss senderMetricSample) {
+	if ss.noAggregation && agg.addTimestampedSample(ss.metricSample) {
+		return
+	}
+
 	agg.mu.Lock()
 	defer agg.mu.Unlock()
 
@@ -289,6 +338,60 @@ func (agg *BufferedAggregator) addEvent(e metrics.Event) {
 	agg.events = append(agg.events, &e)
 }
 
+// dispatchSample sends the dogstatsd sample to the time sampler worker of its
+// metric name, the distributions and the samples without workers are added
+// by the aggregator goroutine.
+func (agg *BufferedAggregator) dispatchSample(metricSample *metrics.MetricSample) {
+	if metricSample.Timestamp > 0 && agg.addTimestampedSample(metricSample) {
+		metrics.PutMetricSample(metricSample)
+		return
+	}
+	if len(agg.timeSamplerWorkers) == 0 || metricSample.Mtype == metrics.DistributionType {
+		agg.addSample(metricSample, timeNowNano())
+		metrics.PutMetricSample(metricSample)
+		return
+	}
+	agg.timeSamplerWorkers[shardOf(metricSample.Name, len(agg.timeSamplerWorkers))].samplesIn <- metricSample
+}
+
+// addTimestampedSample converts the gauge or count sample, sent with a
+// timestamp by the dogstatsd client, e.g. a batch job backfilling its points,
+// or already aggregated by a check, to a serie sent by the no-aggregation
+// stream. It returns false for the other types, which are aggregated
+// regardless of their timestamp.
+func (agg *BufferedAggregator) addTimestampedSample(metricSample *metrics.MetricSample) bool {
+	var mType metrics.APIMetricType
+	value := metricSample.Value
+	switch metricSample.Mtype {
+	case metrics.GaugeType:
+		mType = metrics.APIGaugeType
+	case metrics.CounterType, metrics.CountType:
+		mType = metrics.APICountType
+		if metricSample.SampleRate > 0 {
+			value = value / metricSample.SampleRate
+		}
+	default:
+		return false
+	}
+
+	serie := &metrics.Serie{
+		Name:     metricSample.Name,
+		Points:   []metrics.Point{{Ts: metricSample.Timestamp, Value: value}},
+		Tags:     deduplicateTags(metricSample.Tags),
+		Host:     metricSample.Host,
+		MType:    mType,
+		Interval: bucketSize,
+	}
+
+	if serie.Host == "" {
+		agg.mu.Lock()
+		serie.Host = agg.hostname
+		agg.mu.Unlock()
+	}
+	agg.noAggregationIn <- serie
+	return true
+}
+
 // addSample adds the metric sample to either the sampler or distSampler
 func (agg *BufferedAggregator) addSample(metricSample *metrics.MetricSample, timestamp float64) {
 	metricSample.Tags = deduplicateTags(metricSample.Tags)
@@ -303,7 +406,12 @@ func (agg *BufferedAggregator) addSample(metricSample *metrics.MetricSample, tim
 
 // GetSeries grabs all the series from the queue and clears the queue
 func (agg *BufferedAggregator) GetSeries() metrics.Series {
-	series := agg.sampler.flush(timeNowNano())
+	var series metrics.Series
+	if len(agg.timeSamplerWorkers) > 0 {
+		series = flushTimeSamplerWorkers(agg.timeSamplerWorkers, timeNowNano())
+	} else {
+		series = agg.sampler.flush(timeNowNano())
+	}
 	agg.mu.Lock()
 	for _, checkSampler := range agg.checkSamplers {
 		series = append(series, checkSampler.flush()...)
@@ -325,6 +433,11 @@ func (agg *BufferedAggregator) flushSeries() {
 		MType:          metrics.APIGaugeType,
 		SourceTypeName: "System",
 	})
+	if agg.fwdTelemetry {
+		series = append(series, agg.forwarderTelemetry(float64(start.Unix()))...)
+	}
+
+	agg.addExpectedTags(series, start)
 
 	addFlushCount("Series", int64(len(series)))
 
@@ -349,6 +462,28 @@ func (agg *BufferedAggregator) flushSeries() {
 	}()
 }
 
+// addExpectedTags adds the host tags to the series during the first minutes
+// after the agent start, the backend not having resolved the host tags yet.
+// Nothing is added until the host tags are fetched.
+func (agg *BufferedAggregator) addExpectedTags(series metrics.Series, now time.Time) {
+	if !now.Before(agg.expectedTagsUntil) {
+		return
+	}
+	agg.expectedTagsLock.Lock()
+	expectedTags := agg.expectedTags
+	agg.expectedTagsLock.Unlock()
+	if len(expectedTags) == 0 {
+		return
+	}
+	for _, serie := range series {
+		// the tags may be shared with the contexts, copy them
+		tags := make([]string, 0, len(serie.Tags)+len(expectedTags))
+		tags = append(tags, serie.Tags...)
+		tags = append(tags, expectedTags...)
+		serie.Tags = deduplicateTags(tags)
+	}
+}
+
 // GetServiceChecks grabs all the service checks from the queue and clears the queue
 func (agg *BufferedAggregator) GetServiceChecks() metrics.ServiceChecks {
 	agg.mu.Lock()
@@ -396,7 +531,11 @@ func (agg *BufferedAggregator) GetSketches() metrics.SketchSeriesList {
 	agg.mu.Lock()
 	defer agg.mu.Unlock()
 
-	return agg.distSampler.flush(timeNowNano())
+	sketches := agg.distSampler.flush(timeNowNano())
+	for _, checkSampler := range agg.checkSamplers {
+		sketches = append(sketches, checkSampler.flushSketches()...)
+	}
+	return mergeSketchSeries(sketches)
 }
 
 func (agg *BufferedAggregator) flushSketches() {
@@ -459,7 +598,31 @@ func (agg *BufferedAggregator) flushEvents() {
 	}()
 }
 
+// delayFlush returns whether the flush is delayed, the samplers keeping the
+// points until the next one, while the transactions to every domain of the
+// forwarder pile up, up to maxDelayedFlushes times. The forwarder updates
+// the congestion of each domain, shedding their low priority payloads past
+// that.
+func (agg *BufferedAggregator) delayFlush() bool {
+	if !agg.backpressure || agg.serializer == nil || !agg.serializer.UpdateForwarderCongestion() {
+		agg.congestedFlushes = 0
+		return false
+	}
+
+	agg.congestedFlushes++
+	if agg.congestedFlushes > agg.maxDelayedFlushes {
+		return false
+	}
+	log.Warnf("The forwarder is congested, delaying the flush (%d/%d)", agg.congestedFlushes, agg.maxDelayedFlushes)
+	aggregatorFlushesDelayed.Add(1)
+	return true
+}
+
 func (agg *BufferedAggregator) flush() {
+	if agg.delayFlush() {
+		return
+	}
+
 	agg.flushSeries()
 	agg.flushSketches()
 	agg.flushServiceChecks()
@@ -467,6 +630,10 @@ func (agg *BufferedAggregator) flush() {
 }
 
 func (agg *BufferedAggregator) run() {
+	for _, w := range agg.timeSamplerWorkers {
+		go w.run()
+	}
+	go agg.runNoAggregationStream()
 	if agg.TickerChan == nil {
 		flushPeriod := agg.flushInterval
 		agg.TickerChan = time.NewTicker(flushPeriod).C
@@ -481,7 +648,7 @@ func (agg *BufferedAggregator) run() {
 			aggregatorNumberOfFlush.Add(1)
 		case sample := <-agg.dogstatsdIn:
 			aggregatorDogstatsdMetricSample.Add(1)
-			agg.addSample(sample, timeNowNano())
+			agg.dispatchSample(sample)
 		case ss := <-agg.checkMetricIn:
 			aggregatorChecksMetricSample.Add(1)
 			agg.handleSenderSample(ss)
@@ -500,6 +667,9 @@ func (agg *BufferedAggregator) run() {
 			}
 			agg.sampler.defaultHostname = h
 			agg.mu.Unlock()
+			for _, w := range agg.timeSamplerWorkers {
+				w.hostnameIn <- h
+			}
 			agg.hostnameUpdateDone <- struct{}{}
 		}
 	}
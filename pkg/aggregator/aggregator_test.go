@@ -7,14 +7,18 @@ package aggregator
 
 import (
 	// stdlib
+	"expvar"
 	"testing"
+	"time"
 
 	// 3p
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
 )
 
 var checkID1 check.ID = "1"
@@ -134,4 +138,160 @@ func TestSetHostname(t *testing.T) {
 	assert.Equal(t, "hostname", agg.hostname)
 	agg.SetHostname("different-hostname")
 	assert.Equal(t, "different-hostname", agg.hostname)
+	assert.Equal(t, "different-hostname", agg.sampler.defaultHostname)
+}
+
+func TestAddTimestampedSample(t *testing.T) {
+	// the aggregator is not run, the series stay in the no-aggregation stream channel
+	agg := NewBufferedAggregator(nil, "resolved-hostname", DefaultFlushInterval)
+
+	gauge := metrics.GetMetricSample()
+	*gauge = metrics.MetricSample{Name: "my.gauge", Value: 2, Mtype: metrics.GaugeType, Tags: []string{"foo", "foo"}, SampleRate: 1, Timestamp: 1500000000}
+	agg.dispatchSample(gauge)
+
+	counter := metrics.GetMetricSample()
+	*counter = metrics.MetricSample{Name: "my.counter", Value: 3, Mtype: metrics.CounterType, Host: "my-hostname", SampleRate: 0.5, Timestamp: 1500000010}
+	agg.dispatchSample(counter)
+
+	// the other types are aggregated regardless of their timestamp
+	assert.False(t, agg.addTimestampedSample(&metrics.MetricSample{Name: "my.histogram", Value: 1, Mtype: metrics.HistogramType, SampleRate: 1, Timestamp: 1500000000}))
+
+	require.Len(t, agg.noAggregationIn, 2)
+	assert.Equal(t, &metrics.Serie{
+		Name:     "my.gauge",
+		Points:   []metrics.Point{{Ts: 1500000000, Value: 2}},
+		Tags:     []string{"foo"},
+		Host:     "resolved-hostname",
+		MType:    metrics.APIGaugeType,
+		Interval: bucketSize,
+	}, <-agg.noAggregationIn)
+	assert.Equal(t, &metrics.Serie{
+		Name:     "my.counter",
+		Points:   []metrics.Point{{Ts: 1500000010, Value: 6}},
+		Host:     "my-hostname",
+		MType:    metrics.APICountType,
+		Interval: bucketSize,
+	}, <-agg.noAggregationIn)
+
+	// they are not flushed with the aggregated series
+	assert.Len(t, agg.GetSeries(), 0)
+}
+
+func TestSenderSampleNoAggregation(t *testing.T) {
+	agg := NewBufferedAggregator(nil, "resolved-hostname", DefaultFlushInterval)
+	id := check.ID("check")
+	require.NoError(t, agg.registerSender(id))
+	sender := newCheckSender(id, agg.checkMetricIn, agg.serviceCheckIn, agg.eventIn)
+
+	sender.CountWithTimestamp("my.count", 42, "", []string{"foo"}, 1500000000)
+	agg.handleSenderSample(<-agg.checkMetricIn)
+
+	require.Len(t, agg.noAggregationIn, 1)
+	assert.Equal(t, &metrics.Serie{
+		Name:     "my.count",
+		Points:   []metrics.Point{{Ts: 1500000000, Value: 42}},
+		Tags:     []string{"foo"},
+		Host:     "resolved-hostname",
+		MType:    metrics.APICountType,
+		Interval: bucketSize,
+	}, <-agg.noAggregationIn)
+
+	// the check sampler didn't receive the point
+	sender.Commit()
+	agg.handleSenderSample(<-agg.checkMetricIn)
+	assert.Len(t, agg.GetSeries(), 0)
+}
+
+func TestAddExpectedTags(t *testing.T) {
+	resetAggregator()
+	agg := InitAggregator(nil, "")
+	now := time.Now()
+	agg.expectedTags = []string{"env:prod", "role:web"}
+	agg.expectedTagsUntil = now.Add(time.Minute)
+
+	series := metrics.Series{
+		{Name: "my.metric", Tags: []string{"env:prod", "foo:bar"}},
+		{Name: "my.other_metric"},
+	}
+	agg.addExpectedTags(series, now)
+	assert.Equal(t, []string{"env:prod", "foo:bar", "role:web"}, series[0].Tags)
+	assert.Equal(t, []string{"env:prod", "role:web"}, series[1].Tags)
+
+	// the backend resolves the host tags after expectedTagsUntil
+	series = metrics.Series{{Name: "my.metric", Tags: []string{"foo:bar"}}}
+	agg.addExpectedTags(series, now.Add(time.Minute))
+	assert.Equal(t, []string{"foo:bar"}, series[0].Tags)
+}
+
+type congestedForwarder struct {
+	forwarder.MockedForwarder
+	congested bool
+}
+
+func (f *congestedForwarder) UpdateCongestion() bool {
+	return f.congested
+}
+
+func TestDelayFlush(t *testing.T) {
+	resetAggregator()
+	f := &congestedForwarder{}
+	agg := InitAggregator(serializer.NewSerializer(f), "")
+	agg.backpressure = true
+	agg.maxDelayedFlushes = 2
+
+	assert.False(t, agg.delayFlush())
+
+	// the flushes are delayed, then the forwarder sheds the payloads
+	f.congested = true
+	assert.True(t, agg.delayFlush())
+	assert.True(t, agg.delayFlush())
+	assert.False(t, agg.delayFlush())
+	assert.False(t, agg.delayFlush())
+
+	f.congested = false
+	assert.False(t, agg.delayFlush())
+	f.congested = true
+	assert.True(t, agg.delayFlush())
+
+	agg.backpressure = false
+	assert.False(t, agg.delayFlush())
+}
+
+func TestForwarderTelemetry(t *testing.T) {
+	resetAggregator()
+	agg := InitAggregator(nil, "myhost")
+
+	perDomain := expvar.Get("forwarder").(*expvar.Map).Get("TransactionsPerDomain").(*expvar.Map)
+	stats := new(expvar.Map).Init()
+	perDomain.Set("https://telemetry.test", stats)
+	stats.Add("Success", 10)
+	stats.Add("RetryQueueSize", 3)
+	stats.Add("Unknown", 1)
+
+	findSerie := func(series metrics.Series, name string) *metrics.Serie {
+		for _, serie := range series {
+			if serie.Name == name && len(serie.Tags) == 1 && serie.Tags[0] == "domain:https://telemetry.test" {
+				return serie
+			}
+		}
+		return nil
+	}
+
+	series := agg.forwarderTelemetry(12345)
+	success := findSerie(series, "datadog.agent.forwarder.transactions.success")
+	require.NotNil(t, success)
+	assert.Equal(t, metrics.APICountType, success.MType)
+	assert.Equal(t, "myhost", success.Host)
+	assert.Equal(t, []metrics.Point{{Ts: 12345, Value: 10}}, success.Points)
+	retryQueueSize := findSerie(series, "datadog.agent.forwarder.retry_queue_size")
+	require.NotNil(t, retryQueueSize)
+	assert.Equal(t, metrics.APIGaugeType, retryQueueSize.MType)
+	assert.Equal(t, []metrics.Point{{Ts: 12345, Value: 3}}, retryQueueSize.Points)
+
+	// the counts are the differences since the previous flush
+	stats.Add("Success", 5)
+	series = agg.forwarderTelemetry(12360)
+	success = findSerie(series, "datadog.agent.forwarder.transactions.success")
+	require.NotNil(t, success)
+	assert.Equal(t, []metrics.Point{{Ts: 12360, Value: 5}}, success.Points)
 }
@@ -6,6 +6,9 @@
 package aggregator
 
 import (
+	"math"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -16,8 +19,10 @@ const checksSourceTypeName = "System"
 // CheckSampler aggregates metrics from one Check instance
 type CheckSampler struct {
 	series          []*metrics.Serie
+	sketches        metrics.SketchSeriesList
 	contextResolver *ContextResolver
 	metrics         metrics.ContextMetrics
+	sketchMap       sketchMap
 	defaultHostname string
 }
 
@@ -27,6 +32,7 @@ func newCheckSampler(hostname string) *CheckSampler {
 		series:          make([]*metrics.Serie, 0),
 		contextResolver: newContextResolver(),
 		metrics:         metrics.MakeContextMetrics(),
+		sketchMap:       make(sketchMap),
 		defaultHostname: hostname,
 	}
 }
@@ -34,6 +40,14 @@ func newCheckSampler(hostname string) *CheckSampler {
 func (cs *CheckSampler) addSample(metricSample *metrics.MetricSample) {
 	contextKey := cs.contextResolver.trackContext(metricSample, metricSample.Timestamp)
 
+	if metricSample.Mtype == metrics.DistributionType {
+		bucketStart := int64(metricSample.Timestamp) - int64(metricSample.Timestamp)%bucketSize
+		if !cs.sketchMap.insert(bucketStart, contextKey, metricSample.Value, metricSample.SampleRate) {
+			log.Debugf("Ignoring the distribution sample '%s' on host '%s' and tags '%s': invalid value %f", metricSample.Name, metricSample.Host, metricSample.Tags, metricSample.Value)
+		}
+		return
+	}
+
 	if err := cs.metrics.AddSample(contextKey, metricSample, metricSample.Timestamp, 1); err != nil {
 		log.Debug("Ignoring sample '%s' on host '%s' and tags '%s': %s", metricSample.Name, metricSample.Host, metricSample.Tags, err)
 	}
@@ -68,11 +82,51 @@ func (cs *CheckSampler) commit(timestamp float64) {
 		cs.series = append(cs.series, serie)
 	}
 
+	cs.commitSketches()
+
 	cs.contextResolver.expireContexts(timestamp - defaultExpiry)
 }
 
+// commitSketches moves the sketches of the run to the sketch series, the
+// check runs being less frequent than the flushes.
+func (cs *CheckSampler) commitSketches() {
+	pointsByCtx := make(map[ckey.ContextKey][]metrics.SketchPoint)
+	cs.sketchMap.flushBefore(math.MaxInt64, func(ck ckey.ContextKey, p metrics.SketchPoint) {
+		if p.Sketch == nil {
+			return
+		}
+		pointsByCtx[ck] = append(pointsByCtx[ck], p)
+	})
+
+	for ck, points := range pointsByCtx {
+		context, ok := cs.contextResolver.contextsByKey[ck]
+		if !ok {
+			log.Errorf("Ignoring all distributions on context key '%v': inconsistent context resolver state: the context is not tracked", ck)
+			continue
+		}
+		ss := metrics.SketchSeries{
+			Name:       context.Name,
+			Tags:       context.Tags,
+			Host:       context.Host,
+			Interval:   bucketSize,
+			Points:     points,
+			ContextKey: ck,
+		}
+		if ss.Host == "" {
+			ss.Host = cs.defaultHostname
+		}
+		cs.sketches = append(cs.sketches, ss)
+	}
+}
+
 func (cs *CheckSampler) flush() metrics.Series {
 	series := cs.series
 	cs.series = make([]*metrics.Serie, 0)
 	return series
 }
+
+func (cs *CheckSampler) flushSketches() metrics.SketchSeriesList {
+	sketches := cs.sketches
+	cs.sketches = nil
+	return sketches
+}
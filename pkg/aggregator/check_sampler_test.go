@@ -222,3 +222,33 @@ func TestCheckSamplerHostname(t *testing.T) {
 	assert.Contains(t, actualHostnames, "my.test.hostname")
 	assert.Contains(t, actualHostnames, "metric-hostname")
 }
+
+func TestCheckDistribution(t *testing.T) {
+	checkSampler := newCheckSampler("default-hostname")
+
+	for _, v := range []float64{1, 2, 3} {
+		checkSampler.addSample(&metrics.MetricSample{
+			Name:       "my.metric.name",
+			Value:      v,
+			Mtype:      metrics.DistributionType,
+			Tags:       []string{"foo", "bar"},
+			SampleRate: 1,
+			Timestamp:  12345.0,
+		})
+	}
+
+	checkSampler.commit(12349.0)
+	assert.Len(t, checkSampler.flush(), 0)
+
+	sketches := checkSampler.flushSketches()
+	require.Len(t, sketches, 1)
+	assert.Equal(t, "my.metric.name", sketches[0].Name)
+	assert.Equal(t, "default-hostname", sketches[0].Host)
+	assert.Equal(t, int64(bucketSize), sketches[0].Interval)
+	require.Len(t, sketches[0].Points, 1)
+	assert.Equal(t, int64(12340), sketches[0].Points[0].Ts)
+	assert.Equal(t, int64(3), sketches[0].Points[0].Sketch.Basic.Cnt)
+
+	// the sketches are flushed once
+	assert.Len(t, checkSampler.flushSketches(), 0)
+}
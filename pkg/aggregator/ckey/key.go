@@ -29,6 +29,10 @@ const byteSize = 16
 // have a collision mitigation mechanism.
 type ContextKey [byteSize]byte
 
+// TagsKey is a non-cryptographic hash of a set of tags, it allows
+// to share the tags of the contexts that have the same ones.
+type TagsKey [byteSize]byte
+
 // hashPool is a reusable pool of murmur hasher objects
 // to avoid allocating
 var hashPool = sync.Pool{
@@ -66,6 +70,29 @@ func Generate(name, hostname string, tags []string) ContextKey {
 	return hash
 }
 
+// GenerateTags returns the TagsKey hash for the given tags.
+// The tags array is sorted in place like in Generate.
+func GenerateTags(tags []string) TagsKey {
+	mmh := hashPool.Get().(*mmh3.HashWriter128)
+	mmh.Reset()
+	defer hashPool.Put(mmh)
+
+	if len(tags) < 20 {
+		selectionSort(tags)
+	} else {
+		sort.Strings(tags)
+	}
+
+	for _, t := range tags {
+		mmh.WriteString(t)
+		mmh.WriteString(",")
+	}
+
+	var hash [byteSize]byte
+	mmh.Sum(hash[0:0])
+	return hash
+}
+
 // Compare returns an integer comparing two strings lexicographically.
 // The result will be 0 if a==b, -1 if a < b, and +1 if a > b.
 func Compare(a, b ContextKey) int {
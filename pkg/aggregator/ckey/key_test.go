@@ -61,3 +61,10 @@ func BenchmarkGenerateNoAlloc(b *testing.B) {
 		Generate(name, host, tags)
 	}
 }
+
+func TestGenerateTags(t *testing.T) {
+	key := GenerateTags([]string{"foo", "bar"})
+	assert.Equal(t, key, GenerateTags([]string{"bar", "foo"}))
+	assert.NotEqual(t, key, GenerateTags([]string{"bar", "foo", "baz"}))
+	assert.NotEqual(t, key, GenerateTags([]string{"barfoo"}))
+}
@@ -21,12 +21,15 @@ type Context struct {
 	Name string
 	Tags []string
 	Host string
+	// tagsKey is the key of the tags in the tags store
+	tagsKey ckey.TagsKey
 }
 
 // ContextResolver allows tracking and expiring contexts
 type ContextResolver struct {
 	contextsByKey map[ckey.ContextKey]*Context
 	lastSeenByKey map[ckey.ContextKey]float64
+	tagsStore     *tagsStore
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -38,6 +41,7 @@ func newContextResolver() *ContextResolver {
 	return &ContextResolver{
 		contextsByKey: make(map[ckey.ContextKey]*Context),
 		lastSeenByKey: make(map[ckey.ContextKey]float64),
+		tagsStore:     sharedTagsStore,
 	}
 }
 
@@ -45,10 +49,13 @@ func newContextResolver() *ContextResolver {
 func (cr *ContextResolver) trackContext(metricSample *metrics.MetricSample, currentTimestamp float64) ckey.ContextKey {
 	contextKey := generateContextKey(metricSample)
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
+		// the tags were sorted by generateContextKey
+		tagsKey := ckey.GenerateTags(metricSample.Tags)
 		cr.contextsByKey[contextKey] = &Context{
-			Name: metricSample.Name,
-			Tags: metricSample.Tags,
-			Host: metricSample.Host,
+			Name:    metricSample.Name,
+			Tags:    cr.tagsStore.insert(tagsKey, metricSample.Tags),
+			Host:    metricSample.Host,
+			tagsKey: tagsKey,
 		}
 	}
 	cr.lastSeenByKey[contextKey] = currentTimestamp
@@ -82,6 +89,9 @@ func (cr *ContextResolver) expireContexts(expireTimestamp float64) []ckey.Contex
 
 	// Delete expired context keys
 	for _, expiredContextKey := range expiredContextKeys {
+		if context, found := cr.contextsByKey[expiredContextKey]; found {
+			cr.tagsStore.release(context.tagsKey)
+		}
 		delete(cr.contextsByKey, expiredContextKey)
 		delete(cr.lastSeenByKey, expiredContextKey)
 	}
@@ -54,17 +54,20 @@ func TestTrackContext(t *testing.T) {
 		SampleRate: 1,
 	}
 	expectedContext1 := Context{
-		Name: mSample1.Name,
-		Tags: mSample1.Tags,
+		Name:    mSample1.Name,
+		Tags:    mSample1.Tags,
+		tagsKey: ckey.GenerateTags(mSample1.Tags),
 	}
 	expectedContext2 := Context{
-		Name: mSample2.Name,
-		Tags: mSample2.Tags,
+		Name:    mSample2.Name,
+		Tags:    mSample2.Tags,
+		tagsKey: ckey.GenerateTags(mSample2.Tags),
 	}
 	expectedContext3 := Context{
-		Name: mSample3.Name,
-		Tags: mSample3.Tags,
-		Host: mSample3.Host,
+		Name:    mSample3.Name,
+		Tags:    mSample3.Tags,
+		Host:    mSample3.Host,
+		tagsKey: ckey.GenerateTags(mSample3.Tags),
 	}
 	contextResolver := newContextResolver()
 
@@ -3,8 +3,6 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2018 Datadog, Inc.
 
-// NOTE: This file contains a feature in development that is NOT supported.
-
 package aggregator
 
 import (
@@ -15,6 +13,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/quantile"
 )
 
+// sketchConfig is the config of the sketches built by quantile.Agent
+var sketchConfig = quantile.Default()
+
+// minSampleRate is the lowest sample rate a distribution value is weighted
+// with, so that a tiny rate doesn't insert an unbounded number of values
+const minSampleRate = 1e-6
+
 type distSampler struct {
 	interval        int64
 	defaultHostname string
@@ -42,7 +47,7 @@ func (d *distSampler) calculateBucketStart(ts float64) int64 {
 
 func (d *distSampler) addSample(ms *metrics.MetricSample, ts float64) {
 	ck := d.ctxResolver.trackContext(ms, ts)
-	d.m.insert(d.calculateBucketStart(ts), ck, ms.Value)
+	d.m.insert(d.calculateBucketStart(ts), ck, ms.Value, ms.SampleRate)
 }
 
 func (d *distSampler) flush(flushTs float64) metrics.SketchSeriesList {
@@ -82,6 +87,45 @@ func (d *distSampler) newSeries(ck ckey.ContextKey, points []metrics.SketchPoint
 	return ss
 }
 
+// mergeSketchSeries merges the sketch series of the same context, e.g. a
+// distribution sent by a check and by dogstatsd, the points of the same
+// timestamp being merged in a single sketch.
+func mergeSketchSeries(list metrics.SketchSeriesList) metrics.SketchSeriesList {
+	if len(list) < 2 {
+		return list
+	}
+
+	merged := make(metrics.SketchSeriesList, 0, len(list))
+	byCtx := make(map[ckey.ContextKey]int, len(list))
+	for _, ss := range list {
+		// the context keys of the samplers differ, e.g. on the default hostname
+		ck := ckey.Generate(ss.Name, ss.Host, ss.Tags)
+		i, found := byCtx[ck]
+		if !found {
+			ss.ContextKey = ck
+			byCtx[ck] = len(merged)
+			merged = append(merged, ss)
+			continue
+		}
+
+		target := &merged[i]
+		for _, p := range ss.Points {
+			mergedPoint := false
+			for j := range target.Points {
+				if target.Points[j].Ts == p.Ts {
+					target.Points[j].Sketch.Merge(sketchConfig, p.Sketch)
+					mergedPoint = true
+					break
+				}
+			}
+			if !mergedPoint {
+				target.Points = append(target.Points, p)
+			}
+		}
+	}
+	return merged
+}
+
 type sketchMap map[int64]map[ckey.ContextKey]*quantile.Agent
 
 // Len returns the number of sketches stored
@@ -93,17 +137,33 @@ func (m sketchMap) Len() int {
 	return l
 }
 
-// insert v into a sketch for the given (ts, contextKey)
+// insert v into a sketch for the given (ts, contextKey), weighted by 1/sampleRate
+// like the histograms, so that the sketch counts the values sent by the client
+// and not only the sampled ones.
 // NOTE: ts is truncated to bucketSize
-func (m sketchMap) insert(ts int64, ck ckey.ContextKey, v float64) bool {
+func (m sketchMap) insert(ts int64, ck ckey.ContextKey, v float64, sampleRate float64) bool {
 	if math.IsInf(v, 0) || math.IsNaN(v) {
 		return false
 	}
 
-	m.getOrCreate(ts, ck).Insert(v)
+	if sampleRate <= 0 || sampleRate >= 1 {
+		m.getOrCreate(ts, ck).Insert(v)
+		return true
+	}
+
+	m.getOrCreate(ts, ck).InsertN(v, sampleWeight(sampleRate))
 	return true
 }
 
+// sampleWeight returns the number of values a value sent with sampleRate
+// stands for, rounded and bounded by minSampleRate
+func sampleWeight(sampleRate float64) uint {
+	if sampleRate < minSampleRate {
+		sampleRate = minSampleRate
+	}
+	return uint(math.Round(1 / sampleRate))
+}
+
 func (m sketchMap) getOrCreate(ts int64, ck ckey.ContextKey) *quantile.Agent {
 	// level 1: ts -> ctx
 	byCtx, ok := m[ts]
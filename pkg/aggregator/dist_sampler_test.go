@@ -6,6 +6,7 @@
 package aggregator
 
 import (
+	"fmt"
 	"sort"
 	"testing"
 
@@ -176,3 +177,98 @@ func TestDistSamplerContextSampling(t *testing.T) {
 		ContextKey: generateContextKey(&mSample2),
 	}, flushed[1])
 }
+
+func TestDistSamplerSampleRate(t *testing.T) {
+	distSampler := newDistSampler(10, "")
+
+	mSample := metrics.MetricSample{
+		Name:       "test.metric.name",
+		Value:      3,
+		Mtype:      metrics.DistributionType,
+		Tags:       []string{"a", "b"},
+		SampleRate: 0.25,
+	}
+	distSampler.addSample(&mSample, 10011)
+
+	flushed := distSampler.flush(10020)
+	expSketch := &quantile.Sketch{}
+	expSketch.Insert(quantile.Default(), 3, 3, 3, 3)
+
+	require.Len(t, flushed, 1)
+	metrics.AssertSketchSeriesEqual(t, metrics.SketchSeries{
+		Name:     "test.metric.name",
+		Tags:     []string{"a", "b"},
+		Interval: 10,
+		Points: []metrics.SketchPoint{
+			{Ts: 10010, Sketch: expSketch},
+		},
+		ContextKey: generateContextKey(&mSample),
+	}, flushed[0])
+}
+
+func TestDistSamplerSampleRateWeight(t *testing.T) {
+	for _, tc := range []struct {
+		sampleRate float64
+		count      int64
+	}{
+		{0.3, 3},
+		{1e-9, 1000000},
+	} {
+		t.Run(fmt.Sprintf("%g", tc.sampleRate), func(t *testing.T) {
+			distSampler := newDistSampler(10, "")
+			mSample := metrics.MetricSample{
+				Name:       "test.metric.name",
+				Value:      3,
+				Mtype:      metrics.DistributionType,
+				SampleRate: tc.sampleRate,
+			}
+			distSampler.addSample(&mSample, 10011)
+
+			flushed := distSampler.flush(10020)
+			require.Len(t, flushed, 1)
+			require.Len(t, flushed[0].Points, 1)
+			sketch := flushed[0].Points[0].Sketch
+			assert.EqualValues(t, tc.count, sketch.Basic.Cnt)
+			assert.EqualValues(t, 3*tc.count, sketch.Basic.Sum)
+		})
+	}
+}
+
+func TestMergeSketchSeries(t *testing.T) {
+	newSketch := func(values ...float64) *quantile.Sketch {
+		s := &quantile.Sketch{}
+		s.Insert(quantile.Default(), values...)
+		return s
+	}
+
+	merged := mergeSketchSeries(metrics.SketchSeriesList{
+		{
+			Name:   "my.distribution",
+			Host:   "host",
+			Tags:   []string{"a", "b"},
+			Points: []metrics.SketchPoint{{Ts: 10, Sketch: newSketch(1, 2)}},
+		},
+		{
+			Name:   "other.distribution",
+			Host:   "host",
+			Points: []metrics.SketchPoint{{Ts: 10, Sketch: newSketch(1)}},
+		},
+		{
+			Name: "my.distribution",
+			Host: "host",
+			Tags: []string{"b", "a"},
+			Points: []metrics.SketchPoint{
+				{Ts: 10, Sketch: newSketch(3)},
+				{Ts: 20, Sketch: newSketch(4)},
+			},
+		},
+	})
+
+	require.Len(t, merged, 2)
+	assert.Equal(t, "my.distribution", merged[0].Name)
+	assert.Equal(t, ckey.Generate("my.distribution", "host", []string{"a", "b"}), merged[0].ContextKey)
+	require.Len(t, merged[0].Points, 2)
+	assert.True(t, newSketch(1, 2, 3).Equals(merged[0].Points[0].Sketch))
+	assert.True(t, newSketch(4).Equals(merged[0].Points[1].Sketch))
+	assert.Equal(t, "other.distribution", merged[1].Name)
+}
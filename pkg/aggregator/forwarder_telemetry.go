@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	"expvar"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// forwarderTelemetryMetrics are the names of the metrics of the forwarder
+// counters of each domain, the retry queue size being a gauge
+var forwarderTelemetryMetrics = map[string]string{
+	"Success":          "datadog.agent.forwarder.transactions.success",
+	"Errors":           "datadog.agent.forwarder.transactions.errors",
+	"ConnectionErrors": "datadog.agent.forwarder.transactions.connection_errors",
+	"ClientErrors":     "datadog.agent.forwarder.transactions.client_errors",
+	"ServerErrors":     "datadog.agent.forwarder.transactions.server_errors",
+	"Dropped":          "datadog.agent.forwarder.transactions.dropped",
+	"DroppedOnInput":   "datadog.agent.forwarder.transactions.dropped_on_input",
+	"Retried":          "datadog.agent.forwarder.transactions.retried",
+	"Requeued":         "datadog.agent.forwarder.transactions.requeued",
+	"RetryQueueSize":   "datadog.agent.forwarder.retry_queue_size",
+}
+
+// forwarderTelemetry returns the forwarder counters of each domain as counts
+// since the previous flush, tagged with the domain
+func (agg *BufferedAggregator) forwarderTelemetry(timestamp float64) metrics.Series {
+	forwarderStats, ok := expvar.Get("forwarder").(*expvar.Map)
+	if !ok {
+		return nil
+	}
+	perDomain, ok := forwarderStats.Get("TransactionsPerDomain").(*expvar.Map)
+	if !ok {
+		return nil
+	}
+
+	if agg.forwarderCounters == nil {
+		agg.forwarderCounters = make(map[string]int64)
+	}
+
+	var series metrics.Series
+	perDomain.Do(func(domain expvar.KeyValue) {
+		stats, ok := domain.Value.(*expvar.Map)
+		if !ok {
+			return
+		}
+		tags := []string{"domain:" + domain.Key}
+		stats.Do(func(kv expvar.KeyValue) {
+			counter, ok := kv.Value.(*expvar.Int)
+			name, known := forwarderTelemetryMetrics[kv.Key]
+			if !ok || !known {
+				return
+			}
+
+			serie := &metrics.Serie{
+				Name: name,
+				Host: agg.hostname,
+				Tags: tags,
+			}
+			value := counter.Value()
+			if kv.Key == "RetryQueueSize" {
+				serie.MType = metrics.APIGaugeType
+				serie.Points = []metrics.Point{{Ts: timestamp, Value: float64(value)}}
+			} else {
+				key := domain.Key + "|" + kv.Key
+				serie.MType = metrics.APICountType
+				serie.Points = []metrics.Point{{Ts: timestamp, Value: float64(value - agg.forwarderCounters[key])}}
+				agg.forwarderCounters[key] = value
+			}
+			series = append(series, serie)
+		})
+	})
+	return series
+}
@@ -39,11 +39,26 @@ func (m *MockSender) Historate(metric string, value float64, hostname string, ta
 	m.Called(metric, value, hostname, tags)
 }
 
+//Distribution adds a distribution type to the mock calls.
+func (m *MockSender) Distribution(metric string, value float64, hostname string, tags []string) {
+	m.Called(metric, value, hostname, tags)
+}
+
 //Gauge adds a gauge type to the mock calls.
 func (m *MockSender) Gauge(metric string, value float64, hostname string, tags []string) {
 	m.Called(metric, value, hostname, tags)
 }
 
+//GaugeWithTimestamp adds a timestamped gauge type to the mock calls.
+func (m *MockSender) GaugeWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64) {
+	m.Called(metric, value, hostname, tags, timestamp)
+}
+
+//CountWithTimestamp adds a timestamped count type to the mock calls.
+func (m *MockSender) CountWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64) {
+	m.Called(metric, value, hostname, tags, timestamp)
+}
+
 //ServiceCheck enables the service check mock call.
 func (m *MockSender) ServiceCheck(checkName string, status metrics.ServiceCheckStatus, hostname string, tags []string, message string) {
 	m.Called(checkName, status, hostname, tags, message)
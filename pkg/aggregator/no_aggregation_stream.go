@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// runNoAggregationStream sends the series of noAggregationIn straight to the
+// serializer, by batches of noAggregationBatch series or at every flush
+// interval. These points, timestamped by the dogstatsd clients or already
+// aggregated by the checks, don't go through the samplers nor wait for the
+// flush of the other series.
+func (agg *BufferedAggregator) runNoAggregationStream() {
+	ticker := time.NewTicker(agg.flushInterval)
+	defer ticker.Stop()
+
+	batch := make(metrics.Series, 0, agg.noAggregationBatch)
+	for {
+		select {
+		case serie := <-agg.noAggregationIn:
+			batch = append(batch, serie)
+			if len(batch) < agg.noAggregationBatch {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		agg.sendNoAggregationSeries(batch)
+		batch = make(metrics.Series, 0, agg.noAggregationBatch)
+	}
+}
+
+func (agg *BufferedAggregator) sendNoAggregationSeries(series metrics.Series) {
+	agg.addExpectedTags(series, time.Now())
+
+	log.Debugf("Flushing %d non aggregated series to the forwarder", len(series))
+	if err := agg.serializer.SendSeries(series); err != nil {
+		log.Warnf("Error flushing the non aggregated series: %v", err)
+		aggregatorSeriesFlushErrors.Add(1)
+		return
+	}
+	aggregatorNoAggregationFlushed.Add(int64(len(series)))
+}
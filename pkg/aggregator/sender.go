@@ -13,10 +13,16 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
+// metricsOverLimitName is the telemetry metric reporting the samples a check
+// instance dropped because it exceeded max_metrics_per_instance
+const metricsOverLimitName = "datadog.agent.check.metrics_over_limit"
+
 var senderInstance *checkSender
 var senderInit sync.Once
 var senderPool *checkSenderPool
@@ -31,16 +37,20 @@ type Sender interface {
 	Counter(metric string, value float64, hostname string, tags []string)
 	Histogram(metric string, value float64, hostname string, tags []string)
 	Historate(metric string, value float64, hostname string, tags []string)
+	Distribution(metric string, value float64, hostname string, tags []string)
+	GaugeWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64)
+	CountWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64)
 	ServiceCheck(checkName string, status metrics.ServiceCheckStatus, hostname string, tags []string, message string)
 	Event(e metrics.Event)
 	GetMetricStats() map[string]int64
 }
 
 type metricStats struct {
-	MetricSamples int64
-	Events        int64
-	ServiceChecks int64
-	Lock          sync.RWMutex
+	MetricSamples    int64
+	Events           int64
+	ServiceChecks    int64
+	MetricsOverLimit int64 // metric samples dropped because of the contexts limit
+	Lock             sync.RWMutex
 }
 
 // RawSender interface to submit samples to aggregator directly
@@ -58,12 +68,15 @@ type checkSender struct {
 	smsOut           chan<- senderMetricSample
 	serviceCheckOut  chan<- metrics.ServiceCheck
 	eventOut         chan<- metrics.Event
+	maxContexts      int                          // max number of metric contexts per run, 0 for no limit
+	contexts         map[ckey.ContextKey]struct{} // metric contexts of the current run, protected by metricStats.Lock
 }
 
 type senderMetricSample struct {
-	id           check.ID
-	metricSample *metrics.MetricSample
-	commit       bool
+	id            check.ID
+	metricSample  *metrics.MetricSample
+	commit        bool
+	noAggregation bool // the sample is sent as is, with its timestamp
 }
 
 type checkSenderPool struct {
@@ -133,10 +146,20 @@ func GetDefaultSender() (Sender, error) {
 	return senderInstance, nil
 }
 
+// setMaxContexts limits the number of metric contexts the check can send per
+// run, the samples of the contexts over the limit being dropped. 0 disables it.
+func (s *checkSender) setMaxContexts(maxContexts int) {
+	s.maxContexts = maxContexts
+	if maxContexts > 0 {
+		s.contexts = make(map[ckey.ContextKey]struct{})
+	}
+}
+
 // Commit commits the metric samples that were added during a check run
 // Should be called at the end of every check run
 func (s *checkSender) Commit() {
-	s.smsOut <- senderMetricSample{s.id, &metrics.MetricSample{}, true}
+	s.reportMetricsOverLimit()
+	s.smsOut <- senderMetricSample{s.id, &metrics.MetricSample{}, true, false}
 	s.cyclemetricStats()
 }
 
@@ -148,6 +171,7 @@ func (s *checkSender) GetMetricStats() map[string]int64 {
 	metricStats["MetricSamples"] = s.priormetricStats.MetricSamples
 	metricStats["Events"] = s.priormetricStats.Events
 	metricStats["ServiceChecks"] = s.priormetricStats.ServiceChecks
+	metricStats["MetricsOverLimit"] = s.priormetricStats.MetricsOverLimit
 
 	return metricStats
 }
@@ -158,9 +182,14 @@ func (s *checkSender) cyclemetricStats() {
 	s.priormetricStats.MetricSamples = s.metricStats.MetricSamples
 	s.priormetricStats.Events = s.metricStats.Events
 	s.priormetricStats.ServiceChecks = s.metricStats.ServiceChecks
+	s.priormetricStats.MetricsOverLimit = s.metricStats.MetricsOverLimit
 	s.metricStats.MetricSamples = 0
 	s.metricStats.Events = 0
 	s.metricStats.ServiceChecks = 0
+	s.metricStats.MetricsOverLimit = 0
+	if s.maxContexts > 0 {
+		s.contexts = make(map[ckey.ContextKey]struct{}, len(s.contexts))
+	}
 	s.metricStats.Lock.Unlock()
 	s.priormetricStats.Lock.Unlock()
 }
@@ -168,12 +197,20 @@ func (s *checkSender) cyclemetricStats() {
 // SendRawMetricSample sends the raw sample
 // Useful for testing - submitting precomputed samples.
 func (s *checkSender) SendRawMetricSample(sample *metrics.MetricSample) {
-	s.smsOut <- senderMetricSample{s.id, sample, false}
+	s.smsOut <- senderMetricSample{s.id, sample, false, false}
 }
 
 func (s *checkSender) sendMetricSample(metric string, value float64, hostname string, tags []string, mType metrics.MetricType) {
+	s.sendSample(metric, value, hostname, tags, mType, timeNowNano(), false)
+}
+
+func (s *checkSender) sendSample(metric string, value float64, hostname string, tags []string, mType metrics.MetricType, timestamp float64, noAggregation bool) {
 	log.Trace(mType.String(), " sample: ", metric, ": ", value, " for hostname: ", hostname, " tags: ", tags)
 
+	if !s.withinContextsLimit(metric, hostname, tags) {
+		return
+	}
+
 	metricSample := &metrics.MetricSample{
 		Name:       metric,
 		Value:      value,
@@ -181,16 +218,62 @@ func (s *checkSender) sendMetricSample(metric string, value float64, hostname st
 		Tags:       tags,
 		Host:       hostname,
 		SampleRate: 1,
-		Timestamp:  timeNowNano(),
+		Timestamp:  timestamp,
 	}
 
-	s.smsOut <- senderMetricSample{s.id, metricSample, false}
+	s.smsOut <- senderMetricSample{s.id, metricSample, false, noAggregation}
 
 	s.metricStats.Lock.Lock()
 	s.metricStats.MetricSamples++
 	s.metricStats.Lock.Unlock()
 }
 
+// withinContextsLimit returns whether a sample can be sent without the check
+// exceeding its number of metric contexts per run. The samples of new contexts
+// are dropped once the limit is reached, the ones of known contexts are kept.
+func (s *checkSender) withinContextsLimit(metric string, hostname string, tags []string) bool {
+	if s.maxContexts <= 0 {
+		return true
+	}
+	// Generate sorts the tags in place, they are copied to leave the ones
+	// of the sample untouched
+	key := ckey.Generate(metric, hostname, append([]string(nil), tags...))
+
+	s.metricStats.Lock.Lock()
+	defer s.metricStats.Lock.Unlock()
+
+	if _, found := s.contexts[key]; found {
+		return true
+	}
+	if len(s.contexts) >= s.maxContexts {
+		s.metricStats.MetricsOverLimit++
+		return false
+	}
+	s.contexts[key] = struct{}{}
+	return true
+}
+
+// reportMetricsOverLimit sends the number of samples dropped during the run
+// because of the contexts limit, if any
+func (s *checkSender) reportMetricsOverLimit() {
+	s.metricStats.Lock.RLock()
+	dropped := s.metricStats.MetricsOverLimit
+	s.metricStats.Lock.RUnlock()
+	if dropped == 0 {
+		return
+	}
+
+	log.Warnf("Check %s sent more than %d metric contexts, %d samples were dropped", s.id, s.maxContexts, dropped)
+	s.smsOut <- senderMetricSample{s.id, &metrics.MetricSample{
+		Name:       metricsOverLimitName,
+		Value:      float64(dropped),
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{fmt.Sprintf("check:%s", check.IDToCheckName(s.id))},
+		SampleRate: 1,
+		Timestamp:  timeNowNano(),
+	}, false, false}
+}
+
 // Gauge should be used to send a simple gauge value to the aggregator. Only the last value sampled is kept at commit time.
 func (s *checkSender) Gauge(metric string, value float64, hostname string, tags []string) {
 	s.sendMetricSample(metric, value, hostname, tags, metrics.GaugeType)
@@ -230,6 +313,26 @@ func (s *checkSender) Historate(metric string, value float64, hostname string, t
 	s.sendMetricSample(metric, value, hostname, tags, metrics.HistorateType)
 }
 
+// Distribution should be used to track the global distribution of a set of values,
+// the values are aggregated in a sketch merged with the other hosts ones by the backend
+func (s *checkSender) Distribution(metric string, value float64, hostname string, tags []string) {
+	s.sendMetricSample(metric, value, hostname, tags, metrics.DistributionType)
+}
+
+// GaugeWithTimestamp should be used to send a gauge value the check computed
+// itself, e.g. an average over its own period. It is sent as is with its
+// timestamp, without being aggregated nor waiting for the commit.
+func (s *checkSender) GaugeWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64) {
+	s.sendSample(metric, value, hostname, tags, metrics.GaugeType, timestamp, true)
+}
+
+// CountWithTimestamp should be used to send a count the check computed itself
+// over its own period. It is sent as is with its timestamp, without being
+// aggregated nor waiting for the commit.
+func (s *checkSender) CountWithTimestamp(metric string, value float64, hostname string, tags []string, timestamp float64) {
+	s.sendSample(metric, value, hostname, tags, metrics.CountType, timestamp, true)
+}
+
 // SendRawServiceCheck sends the raw service check
 // Useful for testing - submitting precomputed service check.
 func (s *checkSender) SendRawServiceCheck(sc *metrics.ServiceCheck) {
@@ -282,6 +385,7 @@ func (sp *checkSenderPool) mkSender(id check.ID) (Sender, error) {
 
 	err := aggregatorInstance.registerSender(id)
 	sender := newCheckSender(id, aggregatorInstance.checkMetricIn, aggregatorInstance.serviceCheckIn, aggregatorInstance.eventIn)
+	sender.setMaxContexts(config.Datadog.GetInt("max_metrics_per_instance"))
 	sp.senders[id] = sender
 	return sender, err
 }
@@ -188,3 +188,45 @@ func TestCheckSenderInterface(t *testing.T) {
 	event := <-eventChan
 	assert.Equal(t, submittedEvent, event)
 }
+
+func TestCheckSenderMaxContexts(t *testing.T) {
+	senderMetricSampleChan := make(chan senderMetricSample, 10)
+	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
+	eventChan := make(chan metrics.Event, 10)
+	checkSender := newCheckSender(checkID1, senderMetricSampleChan, serviceCheckChan, eventChan)
+	checkSender.setMaxContexts(2)
+
+	checkSender.Gauge("my.metric", 1.0, "my-hostname", []string{"foo", "bar"})
+	checkSender.Gauge("my.metric", 2.0, "my-hostname", []string{"bar", "foo"})
+	checkSender.Gauge("my.other_metric", 1.0, "my-hostname", nil)
+	checkSender.Gauge("my.metric", 1.0, "other-hostname", []string{"foo", "bar"})
+	checkSender.Rate("my.rate_metric", 1.0, "my-hostname", nil)
+	checkSender.Commit()
+
+	// Samples of known contexts are still sent once the limit is reached,
+	// with their tags in the submitted order
+	for i, value := range []float64{1, 2, 1} {
+		sample := <-senderMetricSampleChan
+		assert.Equal(t, value, sample.metricSample.Value)
+		assert.Equal(t, false, sample.commit)
+		if i == 0 {
+			assert.Equal(t, []string{"foo", "bar"}, sample.metricSample.Tags)
+		}
+	}
+	overLimitSample := <-senderMetricSampleChan
+	assert.Equal(t, metricsOverLimitName, overLimitSample.metricSample.Name)
+	assert.Equal(t, float64(2), overLimitSample.metricSample.Value)
+	assert.Equal(t, []string{"check:1"}, overLimitSample.metricSample.Tags)
+	commitSenderSample := <-senderMetricSampleChan
+	assert.Equal(t, true, commitSenderSample.commit)
+	assert.EqualValues(t, 2, checkSender.GetMetricStats()["MetricsOverLimit"])
+
+	// The contexts are counted per run
+	checkSender.Rate("my.rate_metric", 1.0, "my-hostname", nil)
+	checkSender.Commit()
+	rateSenderSample := <-senderMetricSampleChan
+	assert.Equal(t, "my.rate_metric", rateSenderSample.metricSample.Name)
+	commitSenderSample = <-senderMetricSampleChan
+	assert.Equal(t, true, commitSenderSample.commit)
+	assert.EqualValues(t, 0, checkSender.GetMetricStats()["MetricsOverLimit"])
+}
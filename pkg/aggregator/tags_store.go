@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+)
+
+var (
+	tagsStoreExpvars = expvar.Map{}
+	tagsStoreHits    = expvar.Int{}
+	tagsStoreMisses  = expvar.Int{}
+
+	// sharedTagsStore holds the tags of the contexts of every sampler, the
+	// contexts of different metrics usually have the same tags
+	sharedTagsStore = newTagsStore()
+)
+
+func init() {
+	tagsStoreExpvars.Init()
+	tagsStoreExpvars.Set("Hits", &tagsStoreHits)
+	tagsStoreExpvars.Set("Misses", &tagsStoreMisses)
+	tagsStoreExpvars.Set("TagSets", expvar.Func(func() interface{} {
+		return sharedTagsStore.len()
+	}))
+	tagsStoreExpvars.Set("References", expvar.Func(func() interface{} {
+		return sharedTagsStore.references()
+	}))
+	aggregatorExpvars.Set("TagsStore", &tagsStoreExpvars)
+}
+
+type tagsEntry struct {
+	tags []string
+	refs int
+}
+
+// tagsStore interns the tags of the contexts: the contexts with the same tags
+// share a single slice, referenced until the last of them expires.
+type tagsStore struct {
+	sync.Mutex
+	tagsByKey map[ckey.TagsKey]*tagsEntry
+	refs      int
+}
+
+func newTagsStore() *tagsStore {
+	return &tagsStore{
+		tagsByKey: make(map[ckey.TagsKey]*tagsEntry),
+	}
+}
+
+// insert returns the interned tags of the key, storing the given ones if the
+// key is unknown, and adds a reference to them
+func (ts *tagsStore) insert(key ckey.TagsKey, tags []string) []string {
+	ts.Lock()
+	defer ts.Unlock()
+
+	ts.refs++
+	if entry, found := ts.tagsByKey[key]; found {
+		entry.refs++
+		tagsStoreHits.Add(1)
+		return entry.tags
+	}
+	ts.tagsByKey[key] = &tagsEntry{tags: tags, refs: 1}
+	tagsStoreMisses.Add(1)
+	return tags
+}
+
+// release removes a reference to the tags of the key, they are removed from
+// the store with their last reference
+func (ts *tagsStore) release(key ckey.TagsKey) {
+	ts.Lock()
+	defer ts.Unlock()
+
+	entry, found := ts.tagsByKey[key]
+	if !found {
+		return
+	}
+	ts.refs--
+	entry.refs--
+	if entry.refs <= 0 {
+		delete(ts.tagsByKey, key)
+	}
+}
+
+// len returns the number of distinct tag sets stored
+func (ts *tagsStore) len() int {
+	ts.Lock()
+	defer ts.Unlock()
+	return len(ts.tagsByKey)
+}
+
+// references returns the number of contexts referencing the stored tags
+func (ts *tagsStore) references() int {
+	ts.Lock()
+	defer ts.Unlock()
+	return ts.refs
+}
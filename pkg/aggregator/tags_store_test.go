@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	// stdlib
+	"testing"
+
+	// 3p
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestTagsStore(t *testing.T) {
+	store := newTagsStore()
+	tags1 := []string{"foo", "bar"}
+	tags2 := []string{"bar", "foo"}
+	key := ckey.GenerateTags(tags1)
+	assert.Equal(t, key, ckey.GenerateTags(tags2))
+
+	stored := store.insert(key, tags1)
+	assert.Equal(t, []string{"bar", "foo"}, stored)
+	// the same tags are shared
+	stored2 := store.insert(key, tags2)
+	assert.True(t, &stored[0] == &stored2[0])
+	assert.Equal(t, 1, store.len())
+	assert.Equal(t, 2, store.references())
+
+	store.release(key)
+	assert.Equal(t, 1, store.len())
+	assert.Equal(t, 1, store.references())
+	store.release(key)
+	assert.Equal(t, 0, store.len())
+	assert.Equal(t, 0, store.references())
+
+	// releasing an unknown key is a no-op
+	store.release(key)
+	assert.Equal(t, 0, store.references())
+}
+
+func TestContextResolverSharesTags(t *testing.T) {
+	contextResolver := newContextResolver()
+	contextResolver.tagsStore = newTagsStore()
+
+	mSample1 := metrics.MetricSample{
+		Name:       "my.metric.name1",
+		Value:      1,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"foo", "bar"},
+		SampleRate: 1,
+	}
+	mSample2 := metrics.MetricSample{
+		Name:       "my.metric.name2",
+		Value:      1,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"bar", "foo"},
+		SampleRate: 1,
+	}
+
+	contextKey1 := contextResolver.trackContext(&mSample1, 4)
+	contextKey2 := contextResolver.trackContext(&mSample2, 6)
+	context1 := contextResolver.contextsByKey[contextKey1]
+	context2 := contextResolver.contextsByKey[contextKey2]
+	assert.Equal(t, []string{"bar", "foo"}, context2.Tags)
+	assert.True(t, &context1.Tags[0] == &context2.Tags[0])
+	assert.Equal(t, 1, contextResolver.tagsStore.len())
+	assert.Equal(t, 2, contextResolver.tagsStore.references())
+
+	contextResolver.expireContexts(5)
+	assert.Equal(t, 1, contextResolver.tagsStore.len())
+	assert.Equal(t, 1, contextResolver.tagsStore.references())
+
+	contextResolver.expireContexts(7)
+	assert.Equal(t, 0, contextResolver.tagsStore.len())
+}
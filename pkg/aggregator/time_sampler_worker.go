@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// timeSamplerWorker runs a TimeSampler in its own goroutine. The dogstatsd
+// samples are sharded between the workers by metric name, so that a context
+// is always aggregated by the same TimeSampler.
+type timeSamplerWorker struct {
+	sampler    *TimeSampler
+	samplesIn  chan *metrics.MetricSample
+	flushIn    chan timeSamplerFlush
+	hostnameIn chan string
+}
+
+// timeSamplerFlush is a flush request, the series are sent back on seriesOut
+type timeSamplerFlush struct {
+	timestamp float64
+	seriesOut chan<- metrics.Series
+}
+
+func newTimeSamplerWorker(interval int64, defaultHostname string) *timeSamplerWorker {
+	return &timeSamplerWorker{
+		sampler:    NewTimeSampler(interval, defaultHostname),
+		samplesIn:  make(chan *metrics.MetricSample, 100), // TODO make buffer size configurable
+		flushIn:    make(chan timeSamplerFlush),
+		hostnameIn: make(chan string),
+	}
+}
+
+func (w *timeSamplerWorker) run() {
+	for {
+		select {
+		case sample := <-w.samplesIn:
+			w.addSample(sample)
+		case flush := <-w.flushIn:
+			// the samples received before the flush belong to it
+			for len(w.samplesIn) > 0 {
+				w.addSample(<-w.samplesIn)
+			}
+			flush.seriesOut <- w.sampler.flush(flush.timestamp)
+		case hostname := <-w.hostnameIn:
+			w.sampler.defaultHostname = hostname
+		}
+	}
+}
+
+func (w *timeSamplerWorker) addSample(sample *metrics.MetricSample) {
+	sample.Tags = deduplicateTags(sample.Tags)
+	w.sampler.addSample(sample, timeNowNano())
+	metrics.PutMetricSample(sample)
+}
+
+// flushTimeSamplerWorkers returns the series of all the workers, flushed concurrently
+func flushTimeSamplerWorkers(workers []*timeSamplerWorker, timestamp float64) metrics.Series {
+	seriesOut := make(chan metrics.Series, len(workers))
+	for _, w := range workers {
+		w.flushIn <- timeSamplerFlush{timestamp: timestamp, seriesOut: seriesOut}
+	}
+	var series metrics.Series
+	for range workers {
+		series = append(series, <-seriesOut...)
+	}
+	return series
+}
+
+// shardOf returns the index of the worker aggregating the metric name, using
+// the FNV-1a hash of the name without allocating.
+func shardOf(name string, shards int) int {
+	hash := uint32(2166136261)
+	for i := 0; i < len(name); i++ {
+		hash ^= uint32(name[i])
+		hash *= 16777619
+	}
+	return int(hash % uint32(shards))
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestShardOf(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("my.metric.%d", i)
+		shard := shardOf(name, 3)
+		assert.True(t, shard >= 0 && shard < 3)
+		assert.Equal(t, shard, shardOf(name, 3))
+	}
+	assert.Equal(t, 0, shardOf("my.metric", 1))
+}
+
+func TestTimeSamplerWorkers(t *testing.T) {
+	agg := &BufferedAggregator{}
+	for i := 0; i < 3; i++ {
+		w := newTimeSamplerWorker(10, "default-hostname")
+		agg.timeSamplerWorkers = append(agg.timeSamplerWorkers, w)
+		go w.run()
+	}
+
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 2; j++ {
+			sample := metrics.GetMetricSample()
+			sample.Name = fmt.Sprintf("my.metric.%d", i)
+			sample.Value = 1
+			sample.Mtype = metrics.CounterType
+			sample.Tags = []string{"a", "a"}
+			sample.SampleRate = 1
+			agg.dispatchSample(sample)
+		}
+	}
+
+	series := flushTimeSamplerWorkers(agg.timeSamplerWorkers, timeNowNano()+20)
+	require.Len(t, series, 10)
+	sort.Slice(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+	for i, serie := range series {
+		assert.Equal(t, fmt.Sprintf("my.metric.%d", i), serie.Name)
+		assert.Equal(t, []string{"a"}, serie.Tags)
+		require.Len(t, serie.Points, 1)
+		// the samples of a context are all aggregated by the same worker
+		assert.InEpsilon(t, 0.2, serie.Points[0].Value, 0.001)
+	}
+}
+
+func TestTimeSamplerWorkerSetHostname(t *testing.T) {
+	w := newTimeSamplerWorker(10, "default-hostname")
+	go w.run()
+
+	w.hostnameIn <- "different-hostname"
+	// the flush is handled after the update by the worker goroutine
+	flushTimeSamplerWorkers([]*timeSamplerWorker{w}, timeNowNano())
+	assert.Equal(t, "different-hostname", w.sampler.defaultHostname)
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// reloadCheckInterval is the minimum interval between two checks of the modification time of the files
+const reloadCheckInterval = 10 * time.Second
+
+// fileReloader reloads files when their modification time changes, it is
+// checked at most every reloadCheckInterval.
+type fileReloader struct {
+	sync.Mutex
+	files     []string
+	modTime   time.Time
+	lastCheck time.Time
+	load      func() error
+}
+
+// reloadIfChanged calls load if the files were modified since the last load.
+// On failure the previous content is kept, so a rotation in progress does
+// not break the connections. The lock must be held.
+func (r *fileReloader) reloadIfChanged() {
+	if time.Since(r.lastCheck) < reloadCheckInterval {
+		return
+	}
+	r.lastCheck = time.Now()
+	modTime, err := filesModTime(r.files)
+	if err != nil {
+		log.Warnf("Could not check the modification time of %v: %s", r.files, err)
+		return
+	}
+	if !modTime.After(r.modTime) {
+		return
+	}
+	if err := r.load(); err != nil {
+		log.Errorf("Could not reload %v, keeping the previous version: %s", r.files, err)
+		return
+	}
+	log.Infof("Reloaded %v", r.files)
+	r.modTime = modTime
+}
+
+// filesModTime returns the latest modification time of the files.
+func filesModTime(files []string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// KeyPairReloader serves a certificate loaded from files, and reloads
+// it when the files are rotated.
+type KeyPairReloader struct {
+	fileReloader
+	cert *tls.Certificate
+}
+
+// NewKeyPairReloader loads the certificate and key files.
+func NewKeyPairReloader(certFile, keyFile string) (*KeyPairReloader, error) {
+	r := &KeyPairReloader{}
+	r.files = []string{certFile, keyFile}
+	r.load = func() error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		r.cert = &cert
+		return nil
+	}
+	modTime, err := filesModTime(r.files)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(); err != nil {
+		return nil, fmt.Errorf("could not load the key pair %s, %s: %s", certFile, keyFile, err)
+	}
+	r.modTime = modTime
+	r.lastCheck = time.Now()
+	return r, nil
+}
+
+// Certificate returns the current certificate.
+func (r *KeyPairReloader) Certificate() *tls.Certificate {
+	r.Lock()
+	defer r.Unlock()
+	r.reloadIfChanged()
+	return r.cert
+}
+
+// GetCertificate is to be used as tls.Config.GetCertificate by servers.
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// GetClientCertificate is to be used as tls.Config.GetClientCertificate by clients.
+func (r *KeyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// CertPoolReloader serves a pool of CA certificates loaded from a PEM
+// file, and reloads it when the file is rotated.
+type CertPoolReloader struct {
+	fileReloader
+	pool *x509.CertPool
+}
+
+// NewCertPoolReloader loads the PEM file of CA certificates.
+func NewCertPoolReloader(caFile string) (*CertPoolReloader, error) {
+	r := &CertPoolReloader{}
+	r.files = []string{caFile}
+	r.load = func() error {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", caFile)
+		}
+		r.pool = pool
+		return nil
+	}
+	modTime, err := filesModTime(r.files)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(); err != nil {
+		return nil, fmt.Errorf("could not load the CA file %s: %s", caFile, err)
+	}
+	r.modTime = modTime
+	r.lastCheck = time.Now()
+	return r, nil
+}
+
+// Pool returns the current pool of CA certificates.
+func (r *CertPoolReloader) Pool() *x509.CertPool {
+	r.Lock()
+	defer r.Unlock()
+	r.reloadIfChanged()
+	return r.pool
+}
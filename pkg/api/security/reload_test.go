@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package security
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair generates a certificate and writes it with its key, with the given modification time.
+func writeKeyPair(t *testing.T, certFile, keyFile string, modTime time.Time) *x509.Certificate {
+	cert, certPEM, key, err := GenerateRootCert([]string{"localhost"}, 1024)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return cert
+}
+
+func TestKeyPairReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	now := time.Now()
+	first := writeKeyPair(t, certFile, keyFile, now.Add(-time.Hour))
+	r, err := NewKeyPairReloader(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, first.Raw, r.Certificate().Certificate[0])
+
+	// Rotated files are not reloaded before the check interval
+	second := writeKeyPair(t, certFile, keyFile, now)
+	assert.Equal(t, first.Raw, r.Certificate().Certificate[0])
+
+	r.lastCheck = time.Time{}
+	assert.Equal(t, second.Raw, r.Certificate().Certificate[0])
+
+	// An invalid file keeps the previous certificate
+	require.NoError(t, ioutil.WriteFile(certFile, []byte("invalid"), 0600))
+	require.NoError(t, os.Chtimes(certFile, now.Add(time.Hour), now.Add(time.Hour)))
+	r.lastCheck = time.Time{}
+	assert.Equal(t, second.Raw, r.Certificate().Certificate[0])
+
+	_, err = NewKeyPairReloader(certFile, keyFile)
+	assert.Error(t, err)
+}
+
+func TestCertPoolReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "key.pem")
+
+	now := time.Now()
+	first := writeKeyPair(t, caFile, keyFile, now.Add(-time.Hour))
+	r, err := NewCertPoolReloader(caFile)
+	require.NoError(t, err)
+	require.Len(t, r.Pool().Subjects(), 1)
+
+	second := writeKeyPair(t, caFile, keyFile, now)
+	r.lastCheck = time.Time{}
+	_, err = second.Verify(x509.VerifyOptions{Roots: r.Pool()})
+	assert.NoError(t, err)
+	_, err = first.Verify(x509.VerifyOptions{Roots: r.Pool()})
+	assert.Error(t, err)
+}
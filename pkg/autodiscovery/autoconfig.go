@@ -19,7 +19,6 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
-	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -42,11 +41,13 @@ func init() {
 }
 
 // providerDescriptor keeps track of the configurations loaded by a certain
-// `providers.ConfigProvider` and whether it should be polled or not.
+// `providers.ConfigProvider`, whether it should be polled or not and how often.
 type providerDescriptor struct {
-	provider providers.ConfigProvider
-	configs  []integration.Config
-	poll     bool
+	provider     providers.ConfigProvider
+	configs      []integration.Config
+	poll         bool
+	pollInterval time.Duration
+	lastPoll     time.Time
 }
 
 // AutoConfig is responsible to collect integrations configurations from
@@ -66,8 +67,11 @@ type AutoConfig struct {
 	listeners         []listeners.ServiceListener
 	configResolver    *ConfigResolver
 	configsPollTicker *time.Ticker
+	pollTickInterval  time.Duration
+	providersChanged  chan struct{}
 	scheduler         scheduler.Scheduler
 	stop              chan bool
+	stopWatches       chan struct{}
 	pollerActive      bool
 	health            *health.Handle
 	store             *store
@@ -77,12 +81,14 @@ type AutoConfig struct {
 // NewAutoConfig creates an AutoConfig instance.
 func NewAutoConfig(scheduler scheduler.Scheduler) *AutoConfig {
 	ac := &AutoConfig{
-		providers:     make([]*providerDescriptor, 0, 5),
-		templateCache: NewTemplateCache(),
-		stop:          make(chan bool),
-		store:         newStore(),
-		health:        health.Register("ad-autoconfig"),
-		scheduler:     scheduler,
+		providers:        make([]*providerDescriptor, 0, 5),
+		templateCache:    NewTemplateCache(),
+		providersChanged: make(chan struct{}, 1),
+		stop:             make(chan bool),
+		stopWatches:      make(chan struct{}),
+		store:            newStore(),
+		health:           health.Register("ad-autoconfig"),
+		scheduler:        scheduler,
 	}
 	ac.configResolver = newConfigResolver(ac, ac.templateCache)
 	return ac
@@ -93,7 +99,23 @@ func (ac *AutoConfig) StartPolling() {
 	ac.m.Lock()
 	defer ac.m.Unlock()
 
-	ac.configsPollTicker = time.NewTicker(configsPollIntl)
+	// tick at the smallest poll interval, the providers are only polled
+	// once their own interval elapsed
+	interval := configsPollIntl
+	for _, pd := range ac.providers {
+		if pd.poll && pd.pollInterval > 0 && pd.pollInterval < interval {
+			interval = pd.pollInterval
+		}
+	}
+	ac.configsPollTicker = time.NewTicker(interval)
+	ac.pollTickInterval = interval
+	// the providers able to watch their configurations are collected as soon
+	// as they change, they are still polled when the watch fails
+	for _, pd := range ac.providers {
+		if w, ok := pd.provider.(providers.WatchingConfigProvider); ok && pd.poll {
+			go w.Watch(ac.providersChanged, ac.stopWatches)
+		}
+	}
 	ac.pollConfigs()
 	ac.pollerActive = true
 }
@@ -107,6 +129,7 @@ func (ac *AutoConfig) Stop() {
 
 	// stop the poller if running
 	if ac.pollerActive {
+		close(ac.stopWatches)
 		ac.stop <- true
 		ac.pollerActive = false
 	}
@@ -128,8 +151,8 @@ func (ac *AutoConfig) Stop() {
 // AddProvider adds a new configuration provider to AutoConfig.
 // Callers must pass a flag to indicate whether the configuration provider
 // expects to be polled or it's fine for it to be invoked only once in the
-// Agent lifetime.
-func (ac *AutoConfig) AddProvider(provider providers.ConfigProvider, shouldPoll bool) {
+// Agent lifetime, and the interval to poll it at, the default one when 0.
+func (ac *AutoConfig) AddProvider(provider providers.ConfigProvider, shouldPoll bool, pollInterval time.Duration) {
 	ac.m.Lock()
 	defer ac.m.Unlock()
 
@@ -145,10 +168,14 @@ func (ac *AutoConfig) AddProvider(provider providers.ConfigProvider, shouldPoll
 		}
 	}
 
+	if pollInterval <= 0 {
+		pollInterval = configsPollIntl
+	}
 	pd := &providerDescriptor{
-		provider: provider,
-		configs:  []integration.Config{},
-		poll:     shouldPoll,
+		provider:     provider,
+		configs:      []integration.Config{},
+		poll:         shouldPoll,
+		pollInterval: pollInterval,
 	}
 	ac.providers = append(ac.providers, pd)
 }
@@ -246,21 +273,9 @@ func (ac *AutoConfig) resolve(config integration.Config) []integration.Config {
 		}
 		errorStats.removeResolveWarnings(config.Name)
 
-		// each template can resolve to multiple configs
-		for _, config := range resolvedConfigs {
-			config, err := decryptConfig(config)
-			if err != nil {
-				log.Errorf("Dropping conf for %q: %s", config.Name, err.Error())
-				continue
-			}
-			configs = append(configs, config)
-		}
-		return configs
-	}
-	config, err := decryptConfig(config)
-	if err != nil {
-		log.Errorf("Dropping conf for '%s': %s", config.Name, err.Error())
-		return configs
+		// each template can resolve to multiple configs, their secrets
+		// are only decrypted when their checks are loaded
+		return append(configs, resolvedConfigs...)
 	}
 	configs = append(configs, config)
 
@@ -285,38 +300,6 @@ func (ac *AutoConfig) AddListener(listener listeners.ServiceListener) {
 	listener.Listen(ac.configResolver.newService, ac.configResolver.delService)
 }
 
-func decryptConfig(conf integration.Config) (integration.Config, error) {
-	var err error
-
-	// init_config
-	conf.InitConfig, err = secrets.Decrypt(conf.InitConfig)
-	if err != nil {
-		return conf, fmt.Errorf("error while decrypting secrets in 'init_config': %s", err)
-	}
-
-	// instances
-	for idx := range conf.Instances {
-		conf.Instances[idx], err = secrets.Decrypt(conf.Instances[idx])
-		if err != nil {
-			return conf, fmt.Errorf("error while decrypting secrets in an instance: %s", err)
-		}
-	}
-
-	// metrics
-	conf.MetricConfig, err = secrets.Decrypt(conf.MetricConfig)
-	if err != nil {
-		return conf, fmt.Errorf("error while decrypting secrets in 'metrics': %s", err)
-	}
-
-	// logs
-	conf.LogsConfig, err = secrets.Decrypt(conf.LogsConfig)
-	if err != nil {
-		return conf, fmt.Errorf("error while decrypting secrets 'logs': %s", err)
-	}
-
-	return conf, nil
-}
-
 // pollConfigs periodically calls Collect() on all the configuration
 // providers that have been requested to be polled
 func (ac *AutoConfig) pollConfigs() {
@@ -330,7 +313,7 @@ func (ac *AutoConfig) pollConfigs() {
 				ac.health.Deregister()
 				return
 			case <-ac.health.C:
-			case <-ac.configsPollTicker.C:
+			case tick := <-ac.configsPollTicker.C:
 				// check if services tags are up to date
 				var servicesToRefresh []listeners.Service
 				// locking the configresolver to loop on services
@@ -354,41 +337,56 @@ func (ac *AutoConfig) pollConfigs() {
 					ac.configResolver.processDelService(service)
 					ac.configResolver.processNewService(service)
 				}
-				// invoke Collect on the known providers
-				for _, pd := range ac.providers {
-					// skip providers that don't want to be polled
-					if !pd.poll {
-						continue
-					}
-
-					// Check if the CPupdate cache is up to date. Fill it and trigger a Collect() if outdated.
-					upToDate, err := pd.provider.IsUpToDate()
-					if err != nil {
-						log.Errorf("cache processing of %v failed: %v", pd.provider.String(), err)
-					}
-					if upToDate == true {
-						log.Debugf("No modifications in the templates stored in %q ", pd.provider.String())
-						continue
-					}
-
-					// retrieve the list of newly added configurations as well
-					// as removed configurations
-					newConfigs, removedConfigs := ac.collect(pd)
-					// Process removed configs first to handle the case where a
-					// container churn would result in the same configuration hash.
-					ac.processRemovedConfigs(removedConfigs)
-
-					for _, config := range newConfigs {
-						config.Provider = pd.provider.String()
-						resolvedConfigs := ac.resolve(config)
-						ac.schedule(resolvedConfigs)
-					}
-				}
+				ac.pollProviders(tick)
+			case <-ac.providersChanged:
+				ac.pollProviders(time.Time{})
 			}
 		}
 	}()
 }
 
+// pollProviders invokes Collect on the known providers whose configurations
+// changed, and schedules the new configurations. At a tick, only the providers
+// whose poll interval elapsed are queried, a zero tick queries all of them.
+func (ac *AutoConfig) pollProviders(tick time.Time) {
+	for _, pd := range ac.providers {
+		// skip providers that don't want to be polled
+		if !pd.poll {
+			continue
+		}
+		if !tick.IsZero() {
+			// half a tick of slack for the ticks arriving slightly early
+			if tick.Sub(pd.lastPoll)+ac.pollTickInterval/2 < pd.pollInterval {
+				continue
+			}
+			pd.lastPoll = tick
+		}
+
+		// Check if the CPupdate cache is up to date. Fill it and trigger a Collect() if outdated.
+		upToDate, err := pd.provider.IsUpToDate()
+		if err != nil {
+			log.Errorf("cache processing of %v failed: %v", pd.provider.String(), err)
+		}
+		if upToDate == true {
+			log.Debugf("No modifications in the templates stored in %q ", pd.provider.String())
+			continue
+		}
+
+		// retrieve the list of newly added configurations as well
+		// as removed configurations
+		newConfigs, removedConfigs := ac.collect(pd)
+		// Process removed configs first to handle the case where a
+		// container churn would result in the same configuration hash.
+		ac.processRemovedConfigs(removedConfigs)
+
+		for _, config := range newConfigs {
+			config.Provider = pd.provider.String()
+			resolvedConfigs := ac.resolve(config)
+			ac.schedule(resolvedConfigs)
+		}
+	}
+}
+
 func (ac *AutoConfig) processRemovedConfigs(configs []integration.Config) {
 	ac.scheduler.Unschedule(configs)
 	for _, c := range configs {
@@ -435,6 +433,12 @@ func (ac *AutoConfig) GetLoadedConfigs() map[string]integration.Config {
 	return ac.store.getLoadedConfigs()
 }
 
+// GetResolutionTraces returns how the loaded configs were resolved from
+// their templates, by config digest
+func (ac *AutoConfig) GetResolutionTraces() map[string]integration.ResolutionTrace {
+	return ac.store.getResolutionTraces()
+}
+
 // GetUnresolvedTemplates returns templates in cache yet to be resolved
 func (ac *AutoConfig) GetUnresolvedTemplates() map[string]integration.Config {
 	return ac.templateCache.GetUnresolvedTemplates()
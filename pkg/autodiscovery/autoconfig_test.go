@@ -7,6 +7,7 @@ package autodiscovery
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
@@ -16,7 +17,8 @@ import (
 )
 
 type MockProvider struct {
-	collectCounter int
+	collectCounter  int
+	upToDateCounter int
 }
 
 func (p *MockProvider) Collect() ([]integration.Config, error) {
@@ -29,6 +31,7 @@ func (p *MockProvider) String() string {
 }
 
 func (p *MockProvider) IsUpToDate() (bool, error) {
+	p.upToDateCounter++
 	return true, nil
 }
 
@@ -49,14 +52,38 @@ func TestAddProvider(t *testing.T) {
 	ac.StartPolling()
 	assert.Len(t, ac.providers, 0)
 	mp := &MockProvider{}
-	ac.AddProvider(mp, false)
-	ac.AddProvider(mp, false) // this should be a noop
-	ac.AddProvider(&MockProvider2{}, true)
+	ac.AddProvider(mp, false, 0)
+	ac.AddProvider(mp, false, 0) // this should be a noop
+	ac.AddProvider(&MockProvider2{}, true, 0)
 	ac.LoadAndRun()
 	require.Len(t, ac.providers, 2)
 	assert.Equal(t, 1, mp.collectCounter)
 	assert.False(t, ac.providers[0].poll)
 	assert.True(t, ac.providers[1].poll)
+	assert.Equal(t, configsPollIntl, ac.providers[1].pollInterval)
+}
+
+func TestPollProvidersInterval(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	fast := &MockProvider{}
+	slow := &MockProvider2{}
+	ac.AddProvider(fast, true, 10*time.Second)
+	ac.AddProvider(slow, true, 30*time.Second)
+	ac.AddProvider(&MockProvider{}, false, 0)
+	ac.pollTickInterval = 10 * time.Second
+
+	start := time.Now()
+	for i := 1; i <= 6; i++ {
+		// the ticks may arrive slightly early
+		ac.pollProviders(start.Add(time.Duration(i)*10*time.Second - time.Millisecond))
+	}
+	assert.Equal(t, 6, fast.upToDateCounter)
+	assert.Equal(t, 2, slow.upToDateCounter)
+
+	// the providers are all queried when a watched store changed
+	ac.pollProviders(time.Time{})
+	assert.Equal(t, 7, fast.upToDateCounter)
+	assert.Equal(t, 3, slow.upToDateCounter)
 }
 
 func TestAddListener(t *testing.T) {
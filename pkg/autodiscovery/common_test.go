@@ -14,6 +14,8 @@ type dummyService struct {
 	Ports         []listeners.ContainerPort
 	Pid           int
 	Hostname      string
+	KubeNamespace string
+	KubePodName   string
 }
 
 // GetID returns the service ID
@@ -50,3 +52,23 @@ func (s *dummyService) GetPid() (int, error) {
 func (s *dummyService) GetHostname() (string, error) {
 	return s.Hostname, nil
 }
+
+// GetKubeNamespace return a dummy namespace
+func (s *dummyService) GetKubeNamespace() (string, error) {
+	return s.KubeNamespace, nil
+}
+
+// GetKubePodName return a dummy pod name
+func (s *dummyService) GetKubePodName() (string, error) {
+	return s.KubePodName, nil
+}
+
+type dummyNodeService struct {
+	dummyService
+	NodeName string
+}
+
+// GetNodeName return a dummy node name
+func (s *dummyNodeService) GetNodeName() string {
+	return s.NodeName
+}
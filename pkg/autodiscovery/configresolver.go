@@ -33,6 +33,8 @@ var (
 		"port":     getPort,
 		"env":      getEnvvar,
 		"hostname": getHostname,
+		"kube":     getKubeMetadata,
+		"extra":    getExtraConfig,
 	}
 )
 
@@ -115,7 +117,7 @@ func (cr *ConfigResolver) ResolveTemplate(tpl integration.Config) []integration.
 	// go through the AD identifiers provided by the template
 	for _, id := range tpl.ADIdentifiers {
 		// check out whether any service we know has this identifier
-		serviceIds, found := cr.adIDToServices[id]
+		serviceIds, found := cr.getServicesForADID(id)
 		if !found {
 			s := fmt.Sprintf("No service found with this AD identifier: %s", id)
 			errorStats.setResolveWarning(tpl.Name, s)
@@ -144,6 +146,30 @@ func (cr *ConfigResolver) ResolveTemplate(tpl integration.Config) []integration.
 	return resolved
 }
 
+// getServicesForADID returns the services having the AD identifier of a
+// template, or an image name matching it when it is a glob or a regular expression
+func (cr *ConfigResolver) getServicesForADID(adID string) (map[listeners.ID]bool, bool) {
+	if !isADPattern(adID) {
+		serviceIds, found := cr.adIDToServices[adID]
+		return serviceIds, found
+	}
+	p, err := newADPattern(adID)
+	if err != nil {
+		log.Warnf("Ignoring the AD identifier: %s", err)
+		return nil, false
+	}
+	serviceIds := make(map[listeners.ID]bool)
+	for id, services := range cr.adIDToServices {
+		if id != adID && !p.match(id) {
+			continue
+		}
+		for serviceID := range services {
+			serviceIds[serviceID] = true
+		}
+	}
+	return serviceIds, len(serviceIds) > 0
+}
+
 // resolve takes a template and a service and generates a config with
 // valid connection info and relevant tags.
 func (cr *ConfigResolver) resolve(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
@@ -153,12 +179,33 @@ func (cr *ConfigResolver) resolve(tpl integration.Config, svc listeners.Service)
 		Instances:     make([]integration.Data, len(tpl.Instances)),
 		InitConfig:    make(integration.Data, len(tpl.InitConfig)),
 		MetricConfig:  tpl.MetricConfig,
+		LogsConfig:    tpl.LogsConfig,
 		ADIdentifiers: tpl.ADIdentifiers,
 		Provider:      tpl.Provider,
+		ClusterCheck:  tpl.ClusterCheck,
+		NodeName:      tpl.NodeName,
 	}
 	copy(resolvedConfig.InitConfig, tpl.InitConfig)
 	copy(resolvedConfig.Instances, tpl.Instances)
 
+	// TODO: harmonize service & entities ID
+	entityName := string(svc.GetID())
+	if !strings.Contains(entityName, "://") {
+		entityName = docker.ContainerIDToEntityName(entityName)
+	}
+	resolvedConfig.Entity = entityName
+
+	// the cluster checks of the endpoints are dispatched to their node
+	if nodeSvc, ok := svc.(listeners.NodeService); ok && tpl.ClusterCheck {
+		resolvedConfig.NodeName = nodeSvc.GetNodeName()
+	}
+
+	trace := integration.ResolutionTrace{
+		TemplateDigest: tpl.Digest(),
+		ServiceID:      string(svc.GetID()),
+		Variables:      make(map[string]string),
+	}
+
 	tags, err := svc.GetTags()
 	if err != nil {
 		return resolvedConfig, err
@@ -173,6 +220,12 @@ func (cr *ConfigResolver) resolve(tpl integration.Config, svc listeners.Service)
 				if err != nil {
 					return integration.Config{}, err
 				}
+				if string(name) == "env" {
+					// the environment variables usually hold secrets
+					trace.Variables[string(v)] = "********"
+				} else {
+					trace.Variables[string(v)] = string(resolvedVar)
+				}
 				// init config vars are replaced by the first found
 				resolvedConfig.InitConfig = bytes.Replace(resolvedConfig.InitConfig, v, resolvedVar, -1)
 				resolvedConfig.Instances[i] = bytes.Replace(resolvedConfig.Instances[i], v, resolvedVar, -1)
@@ -186,13 +239,9 @@ func (cr *ConfigResolver) resolve(tpl integration.Config, svc listeners.Service)
 
 	// store resolved configs in the AC
 	cr.ac.store.setLoadedConfig(resolvedConfig)
+	cr.ac.store.setResolutionTrace(resolvedConfig, trace)
 	cr.ac.store.addConfigForService(svc.GetID(), resolvedConfig)
 	cr.configToService[resolvedConfig.Digest()] = svc.GetID()
-	// TODO: harmonize service & entities ID
-	entityName := string(svc.GetID())
-	if !strings.Contains(entityName, "://") {
-		entityName = docker.ContainerIDToEntityName(entityName)
-	}
 	cr.ac.store.setTagsHashForService(
 		svc.GetID(),
 		tagger.GetEntityHash(entityName),
@@ -210,8 +259,10 @@ func (cr *ConfigResolver) processNewService(svc listeners.Service) {
 	// in any case, register the service and store its tag hash
 	cr.services[svc.GetID()] = svc
 
-	// get all the templates matching service identifiers
+	// get all the templates matching service identifiers, a template matching
+	// several identifiers, as through a pattern, is resolved once
 	var templates []integration.Config
+	seen := make(map[string]bool)
 	ADIdentifiers, err := svc.GetADIdentifiers()
 	if err != nil {
 		log.Errorf("Failed to get AD identifiers for service %s, it will not be monitored - %s", svc.GetID(), err)
@@ -227,7 +278,14 @@ func (cr *ConfigResolver) processNewService(svc listeners.Service) {
 		if err != nil {
 			log.Debugf("Unable to fetch templates from the cache: %v", err)
 		}
-		templates = append(templates, tpls...)
+		for _, tpl := range tpls {
+			digest := tpl.Digest()
+			if seen[digest] {
+				continue
+			}
+			seen[digest] = true
+			templates = append(templates, tpl)
+		}
 	}
 
 	for _, template := range templates {
@@ -362,6 +420,41 @@ func getEnvvar(tplVar []byte, svc listeners.Service) ([]byte, error) {
 	return []byte(value), nil
 }
 
+// getKubeMetadata returns the namespace or the name of the service's pod
+func getKubeMetadata(tplVar []byte, svc listeners.Service) ([]byte, error) {
+	var value string
+	var err error
+	switch string(tplVar) {
+	case "namespace":
+		value, err = svc.GetKubeNamespace()
+	case "pod_name":
+		value, err = svc.GetKubePodName()
+	default:
+		return nil, fmt.Errorf("invalid kube template variable %q, skipping service %s", tplVar, svc.GetID())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kube_%s for service %s, skipping config - %s", tplVar, svc.GetID(), err)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("empty kube_%s for service %s, skipping config", tplVar, svc.GetID())
+	}
+	return []byte(value), nil
+}
+
+// getExtraConfig returns a parameter of the services exposing some, e.g.
+// the SNMP credentials of the devices found by the snmp listener
+func getExtraConfig(tplVar []byte, svc listeners.Service) ([]byte, error) {
+	extraSvc, ok := svc.(listeners.ExtraConfigService)
+	if !ok {
+		return nil, fmt.Errorf("no extra config for service %s, skipping config", svc.GetID())
+	}
+	value, err := extraSvc.GetExtraConfig(string(tplVar))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extra_%s for service %s, skipping config - %s", tplVar, svc.GetID(), err)
+	}
+	return []byte(value), nil
+}
+
 // parseTemplateVar extracts the name of the var
 // and the key (or index if it can be cast to an int)
 func parseTemplateVar(v []byte) (name, key []byte) {
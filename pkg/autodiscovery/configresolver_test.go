@@ -52,6 +52,95 @@ func TestResolveTemplate(t *testing.T) {
 	assert.Len(t, res, 1)
 }
 
+func TestResolveTemplatePattern(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	tc := NewTemplateCache()
+	cr := newConfigResolver(ac, tc)
+
+	cr.processNewService(&dummyService{
+		ID:            "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"docker://a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9", "myregistry/envoy-sidecar", "envoy-sidecar"},
+		Hosts:         map[string]string{"bridge": "172.17.0.2"},
+	})
+	cr.processNewService(&dummyService{
+		ID:            "3b8efe0c50e8a5d4b0c1a1b1fd1e16f6fd9b4fbf0ec2e59c3b4ac56bc2b6dc5d",
+		ADIdentifiers: []string{"docker://3b8efe0c50e8a5d4b0c1a1b1fd1e16f6fd9b4fbf0ec2e59c3b4ac56bc2b6dc5d", "myregistry/redis", "redis"},
+		Hosts:         map[string]string{"bridge": "172.17.0.3"},
+	})
+
+	for _, id := range []string{"myregistry/.*-sidecar", "myregistry/*-sidecar"} {
+		tpl := integration.Config{
+			Name:          "cpu",
+			ADIdentifiers: []string{id},
+			Instances:     []integration.Data{integration.Data("host: %%host%%")},
+		}
+		res := cr.ResolveTemplate(tpl)
+		require.Len(t, res, 1, id)
+		assert.Equal(t, integration.Data("host: 172.17.0.2"), res[0].Instances[0])
+	}
+
+	res := cr.ResolveTemplate(integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{"myregistry/.*"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%")},
+	})
+	assert.Len(t, res, 2)
+}
+
+func TestResolveTemplatePatternOnce(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	tc := NewTemplateCache()
+	cr := newConfigResolver(ac, tc)
+	tc.Set(integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{".*envoy-sidecar"},
+	})
+
+	// the pattern matches two identifiers of the service
+	service := dummyService{
+		ID:            "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"myregistry/envoy-sidecar", "envoy-sidecar"},
+	}
+	cr.processNewService(&service)
+
+	assert.Len(t, ac.store.getConfigsForService(service.GetID()), 1)
+}
+
+func TestResolveTrace(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	cr := newConfigResolver(ac, NewTemplateCache())
+	os.Setenv("REDIS_PASSWORD", "s3cr3t")
+	defer os.Unsetenv("REDIS_PASSWORD")
+
+	svc := &dummyService{
+		ID:            "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"redis"},
+		Hosts:         map[string]string{"bridge": "172.17.0.2"},
+		Ports:         newFakeContainerPorts(),
+	}
+	tpl := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%\nport: %%port_bar%%\npassword: %%env_REDIS_PASSWORD%%")},
+	}
+	cfg, err := cr.resolve(tpl, svc)
+	require.NoError(t, err)
+
+	traces := ac.GetResolutionTraces()
+	require.Len(t, traces, 1)
+	trace := traces[cfg.Digest()]
+	assert.Equal(t, tpl.Digest(), trace.TemplateDigest)
+	assert.Equal(t, string(svc.ID), trace.ServiceID)
+	assert.Equal(t, map[string]string{
+		"%%host%%":               "172.17.0.2",
+		"%%port_bar%%":           "2",
+		"%%env_REDIS_PASSWORD%%": "********",
+	}, trace.Variables)
+
+	ac.store.removeLoadedConfig(cfg)
+	assert.Len(t, ac.GetResolutionTraces(), 0)
+}
+
 func TestParseTemplateVar(t *testing.T) {
 	name, key := parseTemplateVar([]byte("%%host%%"))
 	assert.Equal(t, "host", string(name))
@@ -72,6 +161,10 @@ func TestParseTemplateVar(t *testing.T) {
 	name, key = parseTemplateVar([]byte("%%host_network_name%%"))
 	assert.Equal(t, "host", string(name))
 	assert.Equal(t, "network_name", string(key))
+
+	name, key = parseTemplateVar([]byte("%%kube_pod_name%%"))
+	assert.Equal(t, "kube", string(name))
+	assert.Equal(t, "pod_name", string(key))
 }
 
 func TestGetFallbackHost(t *testing.T) {
@@ -373,6 +466,52 @@ func TestResolve(t *testing.T) {
 				Instances:     []integration.Data{integration.Data("test: imhere")},
 			},
 		},
+		//// kube metadata
+		{
+			testName: "simple %%kube_namespace%% and %%kube_pod_name%%",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				KubeNamespace: "default",
+				KubePodName:   "redis-0",
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("test: %%kube_namespace%%/%%kube_pod_name%%")},
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("test: default/redis-0")},
+			},
+		},
+		{
+			testName: "missing %%kube_pod_name%%",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("test: %%kube_pod_name%%")},
+			},
+			errorString: "empty kube_pod_name for service a5901276aed1, skipping config",
+		},
+		{
+			testName: "invalid %%kube%%",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("test: %%kube_container%%")},
+			},
+			errorString: "invalid kube template variable \"container\", skipping service a5901276aed1",
+		},
 		//// other tags testing
 		{
 			testName: "simple %%pid%%",
@@ -392,6 +531,31 @@ func TestResolve(t *testing.T) {
 				Instances:     []integration.Data{integration.Data("pid: 1337\ntags:\n- foo\n")},
 			},
 		},
+		//// cluster checks
+		{
+			testName: "endpoint cluster check",
+			svc: &dummyNodeService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"kube_endpoint://default/redis"},
+					Hosts:         map[string]string{"endpoint": "10.0.0.1"},
+				},
+				NodeName: "node1",
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"kube_endpoint://default/redis"},
+				Instances:     []integration.Data{integration.Data("host: %%host%%")},
+				ClusterCheck:  true,
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"kube_endpoint://default/redis"},
+				Instances:     []integration.Data{integration.Data("host: 10.0.0.1")},
+				ClusterCheck:  true,
+				NodeName:      "node1",
+			},
+		},
 		//// unknown tag
 		{
 			testName: "invalid %%FOO%% tag",
@@ -35,6 +35,16 @@ type Config struct {
 	LogsConfig    Data     `json:"logs"`           // the logs config in Yaml (logs-agent only)
 	ADIdentifiers []string `json:"ad_identifiers"` // the list of AutoDiscovery identifiers (optional)
 	Provider      string   `json:"provider"`       // the provider that issued the config
+	ClusterCheck  bool     `json:"cluster_check"`  // cluster-check configuration flag, dispatched by the cluster agent
+	NodeName      string   `json:"node_name"`      // node running the endpoint, endpoint checks are dispatched to it (optional)
+	Entity        string   `json:"entity"`         // entity of the service the template was resolved against, e.g. docker://<id> (optional)
+}
+
+// ResolutionTrace records how a configuration was resolved from its template
+type ResolutionTrace struct {
+	TemplateDigest string            `json:"template_digest"` // digest of the template the configuration was resolved from
+	ServiceID      string            `json:"service_id"`      // the service the template was matched against
+	Variables      map[string]string `json:"variables"`       // the template variables and their substituted values
 }
 
 // Equal determines whether the passed config is the same
@@ -178,9 +188,12 @@ func (c *Config) Digest() string {
 		h.Write([]byte(i))
 	}
 	h.Write([]byte(c.InitConfig))
+	h.Write([]byte(c.LogsConfig))
 	for _, i := range c.ADIdentifiers {
 		h.Write([]byte(i))
 	}
+	h.Write([]byte(c.Entity))
+	h.Write([]byte(c.NodeName))
 
 	return strconv.FormatUint(h.Sum64(), 16)
 }
@@ -92,6 +92,9 @@ func TestMergeAdditionalTags(t *testing.T) {
 func TestDigest(t *testing.T) {
 	config := &Config{}
 	assert.Equal(t, 16, len(config.Digest()))
+
+	pinned := &Config{NodeName: "node1"}
+	assert.NotEqual(t, config.Digest(), pinned.Digest())
 }
 
 // this is here to prevent compiler optimization on the benchmarking code
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package integration
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+)
+
+// DecryptConfig returns a copy of the config with the secret handles of its
+// init_config, instances, metrics and logs replaced by the secrets. The
+// configs keep their handles until they are used, so that the secrets are
+// not shown by configcheck nor sent to the node agents.
+func DecryptConfig(conf Config) (Config, error) {
+	var err error
+
+	// init_config
+	conf.InitConfig, err = secrets.Decrypt(conf.InitConfig)
+	if err != nil {
+		return conf, fmt.Errorf("error while decrypting secrets in 'init_config': %s", err)
+	}
+
+	// instances, copied not to decrypt the ones of the stored configs
+	instances := make([]Data, len(conf.Instances))
+	for idx := range conf.Instances {
+		instances[idx], err = secrets.Decrypt(conf.Instances[idx])
+		if err != nil {
+			return conf, fmt.Errorf("error while decrypting secrets in an instance: %s", err)
+		}
+	}
+	conf.Instances = instances
+
+	// metrics
+	conf.MetricConfig, err = secrets.Decrypt(conf.MetricConfig)
+	if err != nil {
+		return conf, fmt.Errorf("error while decrypting secrets in 'metrics': %s", err)
+	}
+
+	// logs
+	conf.LogsConfig, err = secrets.Decrypt(conf.LogsConfig)
+	if err != nil {
+		return conf, fmt.Errorf("error while decrypting secrets in 'logs': %s", err)
+	}
+
+	return conf, nil
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 )
 
@@ -30,6 +31,7 @@ import (
 // match templates against.
 type DockerListener struct {
 	dockerUtil *docker.DockerUtil
+	filter     *containers.Filter
 	services   map[ID]Service
 	newService chan<- Service
 	delService chan<- Service
@@ -60,8 +62,13 @@ func NewDockerListener() (ServiceListener, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Docker, auto discovery will not work: %s", err)
 	}
+	filter, err := containers.NewFilterFromConfig(containers.ADFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container filters for auto discovery: %s", err)
+	}
 	return &DockerListener{
 		dockerUtil: d,
+		filter:     filter,
 		services:   make(map[ID]Service),
 		stop:       make(chan bool),
 		health:     health.Register("ad-dockerlistener"),
@@ -123,6 +130,9 @@ func (l *DockerListener) init() {
 	}
 
 	for _, co := range containers {
+		if l.isExcluded(co) {
+			continue
+		}
 		id := ID(co.ID)
 		var svc Service
 
@@ -147,6 +157,21 @@ func (l *DockerListener) init() {
 	}
 }
 
+// isExcluded returns whether the container is excluded from auto discovery
+// by the container_include_ad and container_exclude_ad options
+func (l *DockerListener) isExcluded(co types.Container) bool {
+	var name string
+	if len(co.Names) > 0 {
+		name = strings.TrimPrefix(co.Names[0], "/")
+	}
+	image, err := l.dockerUtil.ResolveImageName(co.Image)
+	if err != nil {
+		log.Debugf("Could not resolve the image %s of container %s: %s", co.Image, co.ID[:12], err)
+		image = co.Image
+	}
+	return l.filter.IsExcluded(name, image)
+}
+
 // GetServices returns a copy of the current services
 func (l *DockerListener) GetServices() map[ID]Service {
 	l.m.RLock()
@@ -180,7 +205,7 @@ func (l *DockerListener) processEvent(e *docker.ContainerEvent) {
 	} else {
 		// we might receive a `die` event for an unrelated container we don't
 		// care about, let's ignore it.
-		if e.Action == "start" {
+		if e.Action == "start" && !l.filter.IsExcluded(e.ContainerName, e.ImageName) {
 			l.createService(cID)
 		}
 	}
@@ -501,6 +526,18 @@ func (s *DockerService) GetHostname() (string, error) {
 	return s.Hostname, nil
 }
 
+// GetKubeNamespace returns nil and an error, the container not being
+// managed by kubernetes
+func (s *DockerService) GetKubeNamespace() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetKubePodName returns nil and an error, the container not being
+// managed by kubernetes
+func (s *DockerService) GetKubePodName() (string, error) {
+	return "", ErrNotSupported
+}
+
 // findKubernetesInLabels traverses a map of container labels and
 // returns true if a kubernetes label is detected
 func findKubernetesInLabels(labels map[string]string) bool {
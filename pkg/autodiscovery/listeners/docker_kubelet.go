@@ -97,3 +97,21 @@ func (s *DockerKubeletService) GetPorts() ([]ContainerPort, error) {
 	s.Ports = ports
 	return ports, nil
 }
+
+// GetKubeNamespace returns the namespace of the container's pod
+func (s *DockerKubeletService) GetKubeNamespace() (string, error) {
+	pod, err := s.getPod()
+	if err != nil {
+		return "", err
+	}
+	return pod.Metadata.Namespace, nil
+}
+
+// GetKubePodName returns the name of the container's pod
+func (s *DockerKubeletService) GetKubePodName() (string, error) {
+	pod, err := s.getPod()
+	if err != nil {
+		return "", err
+	}
+	return pod.Metadata.Name, nil
+}
@@ -8,6 +8,8 @@
 package listeners
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/ecs"
 )
@@ -24,6 +27,7 @@ import (
 // new containers to monitor, and old containers to stop monitoring
 type ECSListener struct {
 	task       ecs.TaskMetadata
+	filter     *containers.Filter
 	services   map[string]Service // maps container IDs to services
 	newService chan<- Service
 	delService chan<- Service
@@ -38,7 +42,7 @@ type ECSService struct {
 	ID            ID
 	ADIdentifiers []string
 	Hosts         map[string]string
-	Ports         []int
+	Ports         []ContainerPort
 	Pid           int
 	Tags          []string
 	clusterName   string
@@ -52,7 +56,12 @@ func init() {
 
 // NewECSListener creates an ECSListener
 func NewECSListener() (ServiceListener, error) {
+	filter, err := containers.NewFilterFromConfig(containers.ADFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container filters for auto discovery: %s", err)
+	}
 	return &ECSListener{
+		filter:   filter,
 		services: make(map[string]Service),
 		stop:     make(chan bool),
 		t:        time.NewTicker(2 * time.Second),
@@ -115,6 +124,10 @@ func (l *ECSListener) refreshServices() {
 			log.Debugf("container %s is in status %s - skipping", c.DockerID, c.KnownStatus)
 			continue
 		}
+		if l.isExcluded(c) {
+			log.Debugf("container %s is excluded from auto discovery - skipping", c.DockerID)
+			continue
+		}
 		s, err := l.createService(c)
 		if err != nil {
 			log.Errorf("couldn't create a service out of container %s - Auto Discovery will ignore it", c.DockerID)
@@ -137,6 +150,12 @@ func (l *ECSListener) refreshServices() {
 	}
 }
 
+// isExcluded returns whether the container is excluded from auto discovery
+// by the container_include_ad and container_exclude_ad options
+func (l *ECSListener) isExcluded(c ecs.Container) bool {
+	return l.filter.IsExcluded(c.DockerName, c.Image)
+}
+
 func (l *ECSListener) createService(c ecs.Container) (ECSService, error) {
 	cID := ID(c.DockerID)
 	svc := ECSService{
@@ -169,8 +188,17 @@ func (l *ECSListener) createService(c ecs.Container) (ECSService, error) {
 	}
 	svc.Tags = tags
 
-	// Ports and Pid
-	svc.Ports = nil
+	// Ports, only the ones declared in the task definition are exposed
+	ports := make([]ContainerPort, 0, len(c.Ports))
+	for _, port := range c.Ports {
+		ports = append(ports, ContainerPort{Port: port.ContainerPort})
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	svc.Ports = ports
+
+	// Pid
 	svc.Pid = -1
 
 	return svc, err
@@ -202,9 +230,9 @@ func (s *ECSService) GetHosts() (map[string]string, error) {
 	return s.Hosts, nil
 }
 
-// GetPorts returns nil and an error because port is not supported in Fargate-based ECS
+// GetPorts returns the container's ports declared in the task definition
 func (s *ECSService) GetPorts() ([]ContainerPort, error) {
-	return nil, ErrNotSupported
+	return s.Ports, nil
 }
 
 // GetTags retrieves a container's tags
@@ -222,3 +250,13 @@ func (s *ECSService) GetPid() (int, error) {
 func (s *ECSService) GetHostname() (string, error) {
 	return "", ErrNotSupported
 }
+
+// GetKubeNamespace returns nil and an error because kubernetes is not supported in ECS
+func (s *ECSService) GetKubeNamespace() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetKubePodName returns nil and an error because kubernetes is not supported in ECS
+func (s *ECSService) GetKubePodName() (string, error) {
+	return "", ErrNotSupported
+}
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/ecs"
+)
+
+func TestECSListenerIsExcluded(t *testing.T) {
+	filter, err := containers.NewFilter(nil, []string{"image:amazon/amazon-ecs-pause.*", "name:ecs-sidecar-.*"})
+	require.NoError(t, err)
+	listener := ECSListener{filter: filter}
+
+	assert.True(t, listener.isExcluded(ecs.Container{DockerName: "ecs-task-1-internalecspause", Image: "amazon/amazon-ecs-pause:0.1.0"}))
+	assert.True(t, listener.isExcluded(ecs.Container{DockerName: "ecs-sidecar-1", Image: "envoy:latest"}))
+	assert.False(t, listener.isExcluded(ecs.Container{DockerName: "ecs-task-1-nginx", Image: "nginx:latest"}))
+
+	// no filter excludes nothing
+	listener = ECSListener{}
+	assert.False(t, listener.isExcluded(ecs.Container{DockerName: "ecs-sidecar-1", Image: "envoy:latest"}))
+}
+
+func TestECSListenerCreateServicePorts(t *testing.T) {
+	listener := ECSListener{task: ecs.TaskMetadata{ClusterName: "default", Family: "redis", Version: "1"}}
+	container := ecs.Container{
+		DockerID:    "3ee35aa9a9e6e6bd4d1d41cf4ad1c26b9b3f9ce2e2c8aa5be78ad1a7c1f7e5b0",
+		Image:       "redis:latest",
+		KnownStatus: "RUNNING",
+		Networks:    []ecs.Network{{NetworkMode: "awsvpc", IPv4Addresses: []string{"10.0.2.106"}}},
+		Ports: []ecs.Port{
+			{ContainerPort: 26379, Protocol: "tcp"},
+			{ContainerPort: 6379, Protocol: "tcp", HostPort: 6379},
+		},
+	}
+
+	svc, err := listener.createService(container)
+	require.NoError(t, err)
+	ports, err := svc.GetPorts()
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{Port: 6379}, {Port: 26379}}, ports)
+	hosts, err := svc.GetHosts()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"awsvpc": "10.0.2.106"}, hosts)
+
+	// a container without declared ports has none
+	container.Ports = nil
+	svc, err = listener.createService(container)
+	require.NoError(t, err)
+	ports, err = svc.GetPorts()
+	assert.NoError(t, err)
+	assert.Empty(t, ports)
+}
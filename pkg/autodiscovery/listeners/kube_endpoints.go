@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package listeners
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeEndpointsAnnotation = "ad.datadoghq.com/endpoints.check_names"
+	kubeEndpointIDFormat    = "kube_endpoint://%s/%s"
+)
+
+// KubeEndpointsListener lists the endpoints of the kubernetes services having
+// check templates in their ad.datadoghq.com/endpoints.* annotations, to run
+// endpoint checks against each of their addresses, on the node running it.
+// It only runs in the cluster agent.
+type KubeEndpointsListener struct {
+	apiClient  *apiserver.APIClient
+	services   map[ID]kubeService
+	newService chan<- Service
+	delService chan<- Service
+	ticker     *time.Ticker
+	stop       chan bool
+	health     *health.Handle
+	m          sync.RWMutex
+}
+
+// KubeEndpointService implements and store results from the Service interface for the kube endpoints listener
+type KubeEndpointService struct {
+	ID            ID
+	ADIdentifiers []string
+	Hosts         map[string]string
+	Ports         []ContainerPort
+	Tags          []string
+	Namespace     string
+	PodName       string
+	NodeName      string
+	version       string
+}
+
+func init() {
+	Register("kube_endpoints", NewKubeEndpointsListener)
+}
+
+// NewKubeEndpointsListener returns a new KubeEndpointsListener.
+// Connectivity is not checked at this stage to allow for retries, the refresh will do it.
+func NewKubeEndpointsListener() (ServiceListener, error) {
+	return &KubeEndpointsListener{
+		services: make(map[ID]kubeService),
+		stop:     make(chan bool),
+		health:   health.Register("ad-kubeendpointslistener"),
+	}, nil
+}
+
+// Listen lists the endpoints regularly from the cache of the shared informer
+// and reports the addresses of the annotated services as Services
+func (l *KubeEndpointsListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	l.newService = newSvc
+	l.delService = delSvc
+	l.ticker = time.NewTicker(kubeRefreshInterval)
+
+	go func() {
+		l.refreshServices()
+		for {
+			select {
+			case <-l.stop:
+				l.ticker.Stop()
+				l.health.Deregister()
+				return
+			case <-l.health.C:
+			case <-l.ticker.C:
+				l.refreshServices()
+			}
+		}
+	}()
+}
+
+// Stop queues a shutdown of KubeEndpointsListener
+func (l *KubeEndpointsListener) Stop() {
+	l.stop <- true
+}
+
+// refreshServices lists the kube services and their endpoints, reports the
+// new and updated addresses and removes the ones that disappeared. The
+// informer factory is resolved on every refresh to follow the replacements of
+// the client set.
+func (l *KubeEndpointsListener) refreshServices() {
+	var err error
+	if l.apiClient == nil {
+		l.apiClient, err = apiserver.GetAPIClient()
+		if err != nil {
+			log.Errorf("Can't connect to the apiserver, not refreshing the kube endpoints: %s", err)
+			return
+		}
+	}
+	factory, stop := l.apiClient.InformerFactory()
+	servicesInformer := factory.Core().V1().Services()
+	endpointsInformer := factory.Core().V1().Endpoints()
+	if !apiserver.SyncInformers(factory, stop, kubeSyncTimeout, servicesInformer.Informer().HasSynced, endpointsInformer.Informer().HasSynced) {
+		log.Errorf("The kube services and endpoints informers are not synced, not refreshing the kube endpoints")
+		return
+	}
+	services, err := servicesInformer.Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("Can't list the kube services: %s", err)
+		return
+	}
+	endpoints, err := endpointsInformer.Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("Can't list the kube endpoints: %s", err)
+		return
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	updateKubeServices(l.services, processKubeEndpoints(services, endpoints), l.newService, l.delService)
+}
+
+// processKubeEndpoints returns a Service per endpoint address of the annotated
+// kube services, the objects are shared with the informers and must not be
+// modified
+func processKubeEndpoints(kubeServices []*v1.Service, kubeEndpoints []*v1.Endpoints) map[ID]kubeService {
+	annotated := make(map[string]bool)
+	for _, ksvc := range kubeServices {
+		if _, found := ksvc.Annotations[kubeEndpointsAnnotation]; found {
+			annotated[ksvc.Namespace+"/"+ksvc.Name] = true
+		}
+	}
+
+	services := make(map[ID]kubeService)
+	for _, kep := range kubeEndpoints {
+		serviceID := kep.Namespace + "/" + kep.Name
+		if !annotated[serviceID] {
+			continue
+		}
+		for _, subset := range kep.Subsets {
+			var ports []ContainerPort
+			for _, port := range subset.Ports {
+				ports = append(ports, ContainerPort{int(port.Port), port.Name})
+			}
+			sort.Slice(ports, func(i, j int) bool {
+				return ports[i].Port < ports[j].Port
+			})
+
+			for _, address := range subset.Addresses {
+				if address.NodeName == nil {
+					log.Debugf("Skipping the endpoint %s of the service %s, its node is unknown", address.IP, serviceID)
+					continue
+				}
+				svc := &KubeEndpointService{
+					ID:            ID(fmt.Sprintf("kube_endpoint_uid://%s/%s", serviceID, address.IP)),
+					ADIdentifiers: []string{fmt.Sprintf(kubeEndpointIDFormat, kep.Namespace, kep.Name)},
+					Hosts:         map[string]string{"endpoint": address.IP},
+					Ports:         ports,
+					Tags: []string{
+						fmt.Sprintf("kube_service:%s", kep.Name),
+						fmt.Sprintf("kube_namespace:%s", kep.Namespace),
+						fmt.Sprintf("kube_endpoint_ip:%s", address.IP),
+					},
+					Namespace: kep.Namespace,
+					NodeName:  *address.NodeName,
+				}
+				if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+					svc.PodName = address.TargetRef.Name
+				}
+				// the resource version of the endpoints changes with any of
+				// its addresses, the address is updated when its own fields do
+				svc.version = fmt.Sprintf("%s/%s/%v", svc.NodeName, svc.PodName, svc.Ports)
+				services[svc.ID] = svc
+			}
+		}
+	}
+	return services
+}
+
+// GetID returns the service ID
+func (s *KubeEndpointService) GetID() ID {
+	return s.ID
+}
+
+// GetADIdentifiers returns the service AD identifiers
+func (s *KubeEndpointService) GetADIdentifiers() ([]string, error) {
+	return s.ADIdentifiers, nil
+}
+
+// GetHosts returns the endpoint IP
+func (s *KubeEndpointService) GetHosts() (map[string]string, error) {
+	return s.Hosts, nil
+}
+
+// GetPorts returns the endpoint ports
+func (s *KubeEndpointService) GetPorts() ([]ContainerPort, error) {
+	return s.Ports, nil
+}
+
+// GetTags returns the kube_service, kube_namespace and kube_endpoint_ip tags
+func (s *KubeEndpointService) GetTags() ([]string, error) {
+	return s.Tags, nil
+}
+
+// GetPid is not supported for KubeEndpointService
+func (s *KubeEndpointService) GetPid() (int, error) {
+	return -1, ErrNotSupported
+}
+
+// GetHostname is not supported for KubeEndpointService
+func (s *KubeEndpointService) GetHostname() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetKubeNamespace returns the namespace of the endpoint
+func (s *KubeEndpointService) GetKubeNamespace() (string, error) {
+	return s.Namespace, nil
+}
+
+// GetKubePodName returns the name of the pod behind the endpoint
+func (s *KubeEndpointService) GetKubePodName() (string, error) {
+	return s.PodName, nil
+}
+
+// GetNodeName returns the node running the endpoint
+func (s *KubeEndpointService) GetNodeName() string {
+	return s.NodeName
+}
+
+func (s *KubeEndpointService) getVersion() string {
+	return s.version
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProcessKubeEndpoints(t *testing.T) {
+	node1, node2 := "node1", "node2"
+	kubeServices := []*v1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"ad.datadoghq.com/endpoints.check_names": `["http_check"]`,
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-annotated", Namespace: "default"},
+		},
+	}
+	kubeEndpoints := []*v1.Endpoints{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{
+						{IP: "10.0.0.1", NodeName: &node1, TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "web-1"}},
+						{IP: "10.0.0.2", NodeName: &node2},
+						{IP: "10.0.0.3"},
+					},
+					Ports: []v1.EndpointPort{{Name: "http", Port: 8080}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-annotated", Namespace: "default"},
+			Subsets: []v1.EndpointSubset{
+				{Addresses: []v1.EndpointAddress{{IP: "10.0.0.4", NodeName: &node1}}},
+			},
+		},
+	}
+
+	services := processKubeEndpoints(kubeServices, kubeEndpoints)
+
+	// the address without node is skipped
+	require.Len(t, services, 2)
+	svc, found := services["kube_endpoint_uid://default/web/10.0.0.1"]
+	require.True(t, found)
+	endpoint, ok := svc.(*KubeEndpointService)
+	require.True(t, ok)
+
+	adIdentifiers, err := endpoint.GetADIdentifiers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kube_endpoint://default/web"}, adIdentifiers)
+	hosts, err := endpoint.GetHosts()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"endpoint": "10.0.0.1"}, hosts)
+	ports, err := endpoint.GetPorts()
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{8080, "http"}}, ports)
+	tags, err := endpoint.GetTags()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kube_service:web", "kube_namespace:default", "kube_endpoint_ip:10.0.0.1"}, tags)
+	podName, err := endpoint.GetKubePodName()
+	assert.NoError(t, err)
+	assert.Equal(t, "web-1", podName)
+	assert.Equal(t, "node1", endpoint.GetNodeName())
+
+	svc, found = services["kube_endpoint_uid://default/web/10.0.0.2"]
+	require.True(t, found)
+	assert.Equal(t, "node2", svc.(NodeService).GetNodeName())
+}
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package listeners
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeServiceAnnotation = "ad.datadoghq.com/service.check_names"
+	// the services scraped by the prometheus_services config provider
+	kubePrometheusAnnotation = "prometheus.io/scrape"
+	kubeServiceIDFormat      = "kube_service://%s/%s"
+	// the refreshes list the caches of the informers, they don't query the apiserver
+	kubeRefreshInterval = 15 * time.Second
+	// kubeSyncTimeout is how long a refresh waits for the caches of the informers to sync
+	kubeSyncTimeout = 10 * time.Second
+)
+
+// KubeServiceListener lists the kubernetes services having check templates
+// in their ad.datadoghq.com/service.* annotations, or the prometheus.io/scrape
+// one, to run cluster checks against their cluster IP. It only runs in the
+// cluster agent.
+type KubeServiceListener struct {
+	apiClient  *apiserver.APIClient
+	services   map[ID]kubeService
+	newService chan<- Service
+	delService chan<- Service
+	ticker     *time.Ticker
+	stop       chan bool
+	health     *health.Handle
+	m          sync.RWMutex
+}
+
+// KubeServiceService implements and store results from the Service interface for the kube service listener
+type KubeServiceService struct {
+	ID            ID
+	ADIdentifiers []string
+	Hosts         map[string]string
+	Ports         []ContainerPort
+	Tags          []string
+	Namespace     string
+	version       string
+}
+
+// kubeService is a Service built from a kubernetes object, reported again
+// when its version changes
+type kubeService interface {
+	Service
+	getVersion() string
+}
+
+func init() {
+	Register("kube_services", NewKubeServiceListener)
+}
+
+// NewKubeServiceListener returns a new KubeServiceListener.
+// Connectivity is not checked at this stage to allow for retries, the refresh will do it.
+func NewKubeServiceListener() (ServiceListener, error) {
+	return &KubeServiceListener{
+		services: make(map[ID]kubeService),
+		stop:     make(chan bool),
+		health:   health.Register("ad-kubeservicelistener"),
+	}, nil
+}
+
+// Listen lists the kube services regularly from the cache of the shared
+// informer and reports the annotated ones as Services
+func (l *KubeServiceListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	l.newService = newSvc
+	l.delService = delSvc
+	l.ticker = time.NewTicker(kubeRefreshInterval)
+
+	go func() {
+		l.refreshServices()
+		for {
+			select {
+			case <-l.stop:
+				l.ticker.Stop()
+				l.health.Deregister()
+				return
+			case <-l.health.C:
+			case <-l.ticker.C:
+				l.refreshServices()
+			}
+		}
+	}()
+}
+
+// Stop queues a shutdown of KubeServiceListener
+func (l *KubeServiceListener) Stop() {
+	l.stop <- true
+}
+
+// refreshServices lists the kube services, reports the new and updated ones
+// and removes the ones that were deleted or lost their annotation. The informer
+// factory is resolved on every refresh to follow the replacements of the
+// client set.
+func (l *KubeServiceListener) refreshServices() {
+	var err error
+	if l.apiClient == nil {
+		l.apiClient, err = apiserver.GetAPIClient()
+		if err != nil {
+			log.Errorf("Can't connect to the apiserver, not refreshing the kube services: %s", err)
+			return
+		}
+	}
+	factory, stop := l.apiClient.InformerFactory()
+	informer := factory.Core().V1().Services()
+	if !apiserver.SyncInformers(factory, stop, kubeSyncTimeout, informer.Informer().HasSynced) {
+		log.Errorf("The kube services informer is not synced, not refreshing the kube services")
+		return
+	}
+	list, err := informer.Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("Can't list the kube services: %s", err)
+		return
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	updateKubeServices(l.services, processKubeServices(list), l.newService, l.delService)
+}
+
+// processKubeServices returns the Services of the annotated kube services,
+// the kube services are shared with the informer and must not be modified
+func processKubeServices(kubeServices []*v1.Service) map[ID]kubeService {
+	services := make(map[ID]kubeService)
+	for _, ksvc := range kubeServices {
+		_, found := ksvc.Annotations[kubeServiceAnnotation]
+		if !found && ksvc.Annotations[kubePrometheusAnnotation] != "true" {
+			continue
+		}
+		svc := &KubeServiceService{
+			ID:            ID(fmt.Sprintf("kube_service_uid://%s", ksvc.UID)),
+			ADIdentifiers: []string{fmt.Sprintf(kubeServiceIDFormat, ksvc.Namespace, ksvc.Name)},
+			Hosts:         map[string]string{"cluster_ip": ksvc.Spec.ClusterIP},
+			Tags: []string{
+				fmt.Sprintf("kube_service:%s", ksvc.Name),
+				fmt.Sprintf("kube_namespace:%s", ksvc.Namespace),
+			},
+			Namespace: ksvc.Namespace,
+			version:   ksvc.ResourceVersion,
+		}
+		for _, port := range ksvc.Spec.Ports {
+			svc.Ports = append(svc.Ports, ContainerPort{int(port.Port), port.Name})
+		}
+		sort.Slice(svc.Ports, func(i, j int) bool {
+			return svc.Ports[i].Port < svc.Ports[j].Port
+		})
+		services[svc.ID] = svc
+	}
+	return services
+}
+
+// updateKubeServices replaces the known services by the current ones,
+// reporting the changes. An updated service is removed then added again.
+func updateKubeServices(known, current map[ID]kubeService, newSvc, delSvc chan<- Service) {
+	for id, svc := range known {
+		if cur, found := current[id]; found && cur.getVersion() == svc.getVersion() {
+			continue
+		}
+		delete(known, id)
+		delSvc <- svc
+	}
+	for id, svc := range current {
+		if _, found := known[id]; found {
+			continue
+		}
+		known[id] = svc
+		newSvc <- svc
+	}
+}
+
+// GetID returns the service ID
+func (s *KubeServiceService) GetID() ID {
+	return s.ID
+}
+
+// GetADIdentifiers returns the service AD identifiers
+func (s *KubeServiceService) GetADIdentifiers() ([]string, error) {
+	return s.ADIdentifiers, nil
+}
+
+// GetHosts returns the service cluster IP
+func (s *KubeServiceService) GetHosts() (map[string]string, error) {
+	return s.Hosts, nil
+}
+
+// GetPorts returns the service ports
+func (s *KubeServiceService) GetPorts() ([]ContainerPort, error) {
+	return s.Ports, nil
+}
+
+// GetTags returns the kube_service and kube_namespace tags
+func (s *KubeServiceService) GetTags() ([]string, error) {
+	return s.Tags, nil
+}
+
+// GetPid is not supported for KubeServiceService
+func (s *KubeServiceService) GetPid() (int, error) {
+	return -1, ErrNotSupported
+}
+
+// GetHostname is not supported for KubeServiceService
+func (s *KubeServiceService) GetHostname() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetKubeNamespace returns the namespace of the service
+func (s *KubeServiceService) GetKubeNamespace() (string, error) {
+	return s.Namespace, nil
+}
+
+// GetKubePodName is not supported for KubeServiceService
+func (s *KubeServiceService) GetKubePodName() (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *KubeServiceService) getVersion() string {
+	return s.version
+}
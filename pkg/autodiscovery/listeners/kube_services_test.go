@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProcessKubeServices(t *testing.T) {
+	kubeServices := []*v1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:             "a1b2",
+				Name:            "redis",
+				Namespace:       "default",
+				ResourceVersion: "42",
+				Annotations: map[string]string{
+					"ad.datadoghq.com/service.check_names": `["redisdb"]`,
+				},
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: "10.0.0.1",
+				Ports: []v1.ServicePort{
+					{Name: "sentinel", Port: 26379},
+					{Name: "redis", Port: 6379},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{UID: "c3d4", Name: "not-annotated", Namespace: "default"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:         "e5f6",
+				Name:        "exporter",
+				Namespace:   "default",
+				Annotations: map[string]string{"prometheus.io/scrape": "true"},
+			},
+		},
+	}
+
+	services := processKubeServices(kubeServices)
+	require.Len(t, services, 2)
+	assert.Contains(t, services, ID("kube_service_uid://e5f6"))
+	svc, found := services["kube_service_uid://a1b2"]
+	require.True(t, found)
+
+	adIdentifiers, err := svc.GetADIdentifiers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kube_service://default/redis"}, adIdentifiers)
+	hosts, err := svc.GetHosts()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"cluster_ip": "10.0.0.1"}, hosts)
+	ports, err := svc.GetPorts()
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{6379, "redis"}, {26379, "sentinel"}}, ports)
+	tags, err := svc.GetTags()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kube_service:redis", "kube_namespace:default"}, tags)
+	namespace, err := svc.GetKubeNamespace()
+	assert.NoError(t, err)
+	assert.Equal(t, "default", namespace)
+	_, err = svc.GetPid()
+	assert.Equal(t, ErrNotSupported, err)
+	assert.Equal(t, "42", svc.getVersion())
+}
+
+func TestUpdateKubeServices(t *testing.T) {
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	known := make(map[ID]kubeService)
+
+	kept := &KubeServiceService{ID: "kept", version: "1"}
+	updated := &KubeServiceService{ID: "updated", version: "1"}
+	removed := &KubeServiceService{ID: "removed", version: "1"}
+	updateKubeServices(known, map[ID]kubeService{"kept": kept, "updated": updated, "removed": removed}, newSvc, delSvc)
+	assert.Len(t, newSvc, 3)
+	assert.Len(t, delSvc, 0)
+	for len(newSvc) > 0 {
+		<-newSvc
+	}
+
+	updatedV2 := &KubeServiceService{ID: "updated", version: "2"}
+	updateKubeServices(known, map[ID]kubeService{"kept": kept, "updated": updatedV2}, newSvc, delSvc)
+
+	// the updated service is removed then added again
+	require.Len(t, newSvc, 1)
+	assert.Equal(t, updatedV2, <-newSvc)
+	require.Len(t, delSvc, 2)
+	deleted := []Service{<-delSvc, <-delSvc}
+	assert.Contains(t, deleted, updated)
+	assert.Contains(t, deleted, removed)
+	assert.Len(t, known, 2)
+	assert.Equal(t, updatedV2, known["updated"])
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 
@@ -30,6 +31,7 @@ const (
 // KubeletListener listen to kubelet pod creation
 type KubeletListener struct {
 	watcher    *kubelet.PodWatcher
+	filter     *containers.Filter
 	services   map[ID]Service
 	newService chan<- Service
 	delService chan<- Service
@@ -45,6 +47,8 @@ type PodContainerService struct {
 	ADIdentifiers []string
 	Hosts         map[string]string
 	Ports         []ContainerPort
+	Namespace     string
+	PodName       string
 }
 
 func init() {
@@ -56,8 +60,13 @@ func NewKubeletListener() (ServiceListener, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to kubelet, Kubernetes listener will not work: %s", err)
 	}
+	filter, err := containers.NewFilterFromConfig(containers.ADFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container filters for auto discovery: %s", err)
+	}
 	return &KubeletListener{
 		watcher:  watcher,
+		filter:   filter,
 		services: make(map[ID]Service),
 		ticker:   time.NewTicker(15 * time.Second),
 		stop:     make(chan bool),
@@ -111,13 +120,20 @@ func (l *KubeletListener) Stop() {
 
 func (l *KubeletListener) processNewPod(pod *kubelet.Pod) {
 	for _, container := range pod.Status.Containers {
+		// the containers excluded by container_include_ad and container_exclude_ad are ignored
+		if l.filter.IsExcluded(container.Name, container.Image) {
+			log.Debugf("Container %s of pod %s is excluded from auto discovery", container.Name, pod.Metadata.Name)
+			continue
+		}
 		l.createService(ID(container.ID), pod)
 	}
 }
 
 func (l *KubeletListener) createService(id ID, pod *kubelet.Pod) {
 	svc := PodContainerService{
-		ID: id,
+		ID:        id,
+		Namespace: pod.Metadata.Namespace,
+		PodName:   pod.Metadata.Name,
 	}
 	podName := pod.Metadata.Name
 
@@ -249,3 +265,13 @@ func (s *PodContainerService) GetTags() ([]string, error) {
 func (s *PodContainerService) GetHostname() (string, error) {
 	return "", ErrNotSupported
 }
+
+// GetKubeNamespace returns the namespace of the pod
+func (s *PodContainerService) GetKubeNamespace() (string, error) {
+	return s.Namespace, nil
+}
+
+// GetKubePodName returns the name of the pod
+func (s *PodContainerService) GetKubePodName() (string, error) {
+	return s.PodName, nil
+}
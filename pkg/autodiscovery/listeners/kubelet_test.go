@@ -11,7 +11,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
 
@@ -93,7 +95,8 @@ func getMockedPod() *kubelet.Pod {
 		Spec:   kubeletSpec,
 		Status: kubeletStatus,
 		Metadata: kubelet.PodMetadata{
-			Name: "mock-pod",
+			Name:      "mock-pod",
+			Namespace: "default",
 			Annotations: map[string]string{
 				"ad.datadoghq.com/baz.instances": "[]",
 			},
@@ -123,6 +126,12 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, []ContainerPort{{1337, "footcpport"}, {1339, "fooudpport"}}, ports)
 		_, err = service.GetPid()
 		assert.Equal(t, ErrNotSupported, err)
+		namespace, err := service.GetKubeNamespace()
+		assert.Nil(t, err)
+		assert.Equal(t, "default", namespace)
+		podName, err := service.GetKubePodName()
+		assert.Nil(t, err)
+		assert.Equal(t, "mock-pod", podName)
 	default:
 		t.FailNow()
 	}
@@ -163,3 +172,20 @@ func TestProcessNewPod(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestProcessNewPodExcluded(t *testing.T) {
+	filter, err := containers.NewFilter(nil, []string{"image:datadoghq.com/foo.*", "name:baz"})
+	require.NoError(t, err)
+	services := make(chan Service, 3)
+	listener := KubeletListener{
+		newService: services,
+		services:   make(map[ID]Service),
+		filter:     filter,
+	}
+	listener.processNewPod(getMockedPod())
+
+	require.Len(t, services, 1)
+	service := <-services
+	assert.Equal(t, "rkt://bar-random-hash", string(service.GetID()))
+	assert.Len(t, listener.services, 1)
+}
@@ -0,0 +1,394 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/k-sone/snmpgo"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	snmpDefaultWorkers           = 2
+	snmpDefaultDiscoveryInterval = 3600
+	snmpDefaultAllowedFailures   = 3
+	snmpDefaultPort              = 161
+	snmpDefaultVersion           = 2
+	snmpDefaultTimeout           = 5
+	snmpDefaultRetries           = 3
+	snmpDefaultADIdentifier      = "snmp"
+	// snmpMinPrefixLength bounds the size of the subnets, a /16 having 65534 addresses
+	snmpMinPrefixLength = 16
+	// sysObjectID, answered by any SNMP device
+	snmpProbeOID = "1.3.6.1.2.1.1.2.0"
+)
+
+// SNMPListener sweeps the subnets of the snmp_listener option with SNMP GET
+// requests, and reports the devices answering them as Services. A device is
+// removed once it didn't answer allowed_failures sweeps in a row.
+type SNMPListener struct {
+	config     config.SNMPListenerConfig
+	services   map[ID]*SNMPService
+	failures   map[ID]int
+	newService chan<- Service
+	delService chan<- Service
+	stop       chan struct{}
+	probe      func(cfg config.SNMPConfig, ip string) bool
+	m          sync.Mutex
+}
+
+// SNMPService implements and store results from the Service interface for the SNMP listener
+type SNMPService struct {
+	ID            ID
+	ADIdentifiers []string
+	Hosts         map[string]string
+	Ports         []ContainerPort
+	Tags          []string
+	config        config.SNMPConfig
+}
+
+type snmpJob struct {
+	config config.SNMPConfig
+	ip     string
+}
+
+func init() {
+	Register("snmp", NewSNMPListener)
+}
+
+// NewSNMPListener creates a SNMPListener from the snmp_listener option
+func NewSNMPListener() (ServiceListener, error) {
+	var cfg config.SNMPListenerConfig
+	if err := config.Datadog.UnmarshalKey("snmp_listener", &cfg); err != nil {
+		return nil, fmt.Errorf("invalid snmp_listener configuration: %s", err)
+	}
+	if err := setSNMPDefaults(&cfg); err != nil {
+		return nil, err
+	}
+	return &SNMPListener{
+		config:   cfg,
+		services: make(map[ID]*SNMPService),
+		failures: make(map[ID]int),
+		stop:     make(chan struct{}),
+		probe:    probeSNMPDevice,
+	}, nil
+}
+
+// setSNMPDefaults validates the subnets and fills the unset options
+func setSNMPDefaults(cfg *config.SNMPListenerConfig) error {
+	if len(cfg.Configs) == 0 {
+		return errors.New("no subnet configured in snmp_listener")
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = snmpDefaultWorkers
+	}
+	if cfg.DiscoveryInterval <= 0 {
+		cfg.DiscoveryInterval = snmpDefaultDiscoveryInterval
+	}
+	if cfg.AllowedFailures <= 0 {
+		cfg.AllowedFailures = snmpDefaultAllowedFailures
+	}
+	for i := range cfg.Configs {
+		subnet := &cfg.Configs[i]
+		_, ipNet, err := net.ParseCIDR(subnet.Network)
+		if err != nil {
+			return fmt.Errorf("invalid snmp_listener network %q: %s", subnet.Network, err)
+		}
+		ones, bits := ipNet.Mask.Size()
+		if bits != 32 {
+			return fmt.Errorf("invalid snmp_listener network %q: only the IPv4 networks are supported", subnet.Network)
+		}
+		if ones < snmpMinPrefixLength {
+			return fmt.Errorf("invalid snmp_listener network %q: the networks larger than a /%d are not supported", subnet.Network, snmpMinPrefixLength)
+		}
+		if subnet.Port == 0 {
+			subnet.Port = snmpDefaultPort
+		}
+		if subnet.Version == 0 {
+			subnet.Version = snmpDefaultVersion
+		}
+		if subnet.Timeout <= 0 {
+			subnet.Timeout = snmpDefaultTimeout
+		}
+		if subnet.Retries <= 0 {
+			subnet.Retries = snmpDefaultRetries
+		}
+		if subnet.ADIdentifier == "" {
+			subnet.ADIdentifier = snmpDefaultADIdentifier
+		}
+	}
+	return nil
+}
+
+// Listen sweeps the subnets right away, then every discovery_interval
+func (l *SNMPListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	l.newService = newSvc
+	l.delService = delSvc
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(l.config.DiscoveryInterval) * time.Second)
+		defer ticker.Stop()
+
+		l.sweep()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				l.sweep()
+			}
+		}
+	}()
+}
+
+// Stop stops the SNMPListener, interrupting the current sweep
+func (l *SNMPListener) Stop() {
+	close(l.stop)
+}
+
+// sweep probes every address of the subnets with the workers
+func (l *SNMPListener) sweep() {
+	jobs := make(chan snmpJob)
+	var wg sync.WaitGroup
+	for i := 0; i < l.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				l.checkDevice(job)
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	for _, cfg := range l.config.Configs {
+		ip, subnet, _ := net.ParseCIDR(cfg.Network)
+		ip = ip.To4()
+		ones, _ := subnet.Mask.Size()
+		for ip = ip.Mask(subnet.Mask); subnet.Contains(ip); ip = nextIP(ip) {
+			// skip the network and broadcast addresses
+			if ones < 31 && (ip.Equal(subnet.IP) || !subnet.Contains(nextIP(ip))) {
+				continue
+			}
+			select {
+			case jobs <- snmpJob{config: cfg, ip: ip.String()}:
+			case <-l.stop:
+				return
+			}
+		}
+	}
+}
+
+// checkDevice probes a device, reporting it if it is new and removing it
+// once it failed to answer allowed_failures times in a row
+func (l *SNMPListener) checkDevice(job snmpJob) {
+	id := ID("snmp://" + net.JoinHostPort(job.ip, strconv.Itoa(int(job.config.Port))))
+	up := l.probe(job.config, job.ip)
+
+	// the services are sent once the lock is released, not to block the
+	// other workers while autodiscovery processes them
+	if svc, isNew := l.updateDevice(id, job, up); svc != nil {
+		if isNew {
+			log.Debugf("Found SNMP device %s", job.ip)
+			l.newService <- svc
+		} else {
+			log.Debugf("SNMP device %s stopped answering, removing it", job.ip)
+			l.delService <- svc
+		}
+	}
+}
+
+// updateDevice records the result of a probe, it returns the service of the
+// device if it is new, or if it has to be removed
+func (l *SNMPListener) updateDevice(id ID, job snmpJob, up bool) (*SNMPService, bool) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	svc, known := l.services[id]
+	if up {
+		delete(l.failures, id)
+		if known {
+			return nil, false
+		}
+		svc = newSNMPService(id, job.config, job.ip)
+		l.services[id] = svc
+		return svc, true
+	}
+	if !known {
+		return nil, false
+	}
+	l.failures[id]++
+	if l.failures[id] < l.config.AllowedFailures {
+		return nil, false
+	}
+	delete(l.services, id)
+	delete(l.failures, id)
+	return svc, false
+}
+
+func newSNMPService(id ID, cfg config.SNMPConfig, ip string) *SNMPService {
+	return &SNMPService{
+		ID:            id,
+		ADIdentifiers: []string{cfg.ADIdentifier},
+		Hosts:         map[string]string{"snmp": ip},
+		Ports:         []ContainerPort{{int(cfg.Port), "snmp"}},
+		Tags:          []string{fmt.Sprintf("snmp_device:%s", ip)},
+		config:        cfg,
+	}
+}
+
+// probeSNMPDevice returns whether a SNMP agent answers on the address
+// with the credentials of the subnet
+func probeSNMPDevice(cfg config.SNMPConfig, ip string) bool {
+	version := snmpgo.V2c
+	switch cfg.Version {
+	case 1:
+		version = snmpgo.V1
+	case 3:
+		version = snmpgo.V3
+	}
+	seclevel := snmpgo.NoAuthNoPriv
+	if version == snmpgo.V3 && cfg.AuthKey != "" {
+		if cfg.PrivKey != "" {
+			seclevel = snmpgo.AuthPriv
+		} else {
+			seclevel = snmpgo.AuthNoPriv
+		}
+	}
+
+	snmp, err := snmpgo.NewSNMP(snmpgo.SNMPArguments{
+		Version:         version,
+		Address:         net.JoinHostPort(ip, strconv.Itoa(int(cfg.Port))),
+		Retries:         uint(cfg.Retries),
+		Timeout:         time.Duration(cfg.Timeout) * time.Second,
+		UserName:        cfg.User,
+		Community:       cfg.Community,
+		AuthPassword:    cfg.AuthKey,
+		AuthProtocol:    snmpgo.AuthProtocol(cfg.AuthProtocol),
+		PrivPassword:    cfg.PrivKey,
+		PrivProtocol:    snmpgo.PrivProtocol(cfg.PrivProtocol),
+		ContextEngineId: cfg.ContextEngineID,
+		ContextName:     cfg.ContextName,
+		SecurityLevel:   seclevel,
+	})
+	if err != nil {
+		log.Warnf("Invalid SNMP settings for the subnet %s: %s", cfg.Network, err)
+		return false
+	}
+	if err = snmp.Open(); err != nil {
+		log.Debugf("Could not open a SNMP connection to %s: %s", ip, err)
+		return false
+	}
+	defer snmp.Close()
+
+	oids, err := snmpgo.NewOids([]string{snmpProbeOID})
+	if err != nil {
+		return false
+	}
+	// any answer, even an error, comes from a SNMP agent
+	_, err = snmp.GetRequest(oids)
+	return err == nil
+}
+
+// nextIP returns the address following ip
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// GetID returns the service ID
+func (s *SNMPService) GetID() ID {
+	return s.ID
+}
+
+// GetADIdentifiers returns the ad_identifier of the subnet of the device
+func (s *SNMPService) GetADIdentifiers() ([]string, error) {
+	return s.ADIdentifiers, nil
+}
+
+// GetHosts returns the device IP
+func (s *SNMPService) GetHosts() (map[string]string, error) {
+	return s.Hosts, nil
+}
+
+// GetPorts returns the SNMP port of the device
+func (s *SNMPService) GetPorts() ([]ContainerPort, error) {
+	return s.Ports, nil
+}
+
+// GetTags returns the snmp_device tag
+func (s *SNMPService) GetTags() ([]string, error) {
+	return s.Tags, nil
+}
+
+// GetPid is not supported for SNMPService
+func (s *SNMPService) GetPid() (int, error) {
+	return -1, ErrNotSupported
+}
+
+// GetHostname is not supported for SNMPService
+func (s *SNMPService) GetHostname() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetKubeNamespace is not supported for SNMPService
+func (s *SNMPService) GetKubeNamespace() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetKubePodName is not supported for SNMPService
+func (s *SNMPService) GetKubePodName() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetExtraConfig returns the SNMP settings of the subnet of the device
+func (s *SNMPService) GetExtraConfig(key string) (string, error) {
+	switch key {
+	case "network":
+		return s.config.Network, nil
+	case "snmp_version":
+		return strconv.Itoa(s.config.Version), nil
+	case "timeout":
+		return strconv.Itoa(s.config.Timeout), nil
+	case "retries":
+		return strconv.Itoa(s.config.Retries), nil
+	case "community":
+		return s.config.Community, nil
+	case "user":
+		return s.config.User, nil
+	case "authentication_key":
+		return s.config.AuthKey, nil
+	case "authentication_protocol":
+		return s.config.AuthProtocol, nil
+	case "privacy_key":
+		return s.config.PrivKey, nil
+	case "privacy_protocol":
+		return s.config.PrivProtocol, nil
+	case "context_engine_id":
+		return s.config.ContextEngineID, nil
+	case "context_name":
+		return s.config.ContextName, nil
+	}
+	return "", ErrNotSupported
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestSetSNMPDefaults(t *testing.T) {
+	cfg := config.SNMPListenerConfig{
+		Configs: []config.SNMPConfig{
+			{Network: "192.168.0.0/24", Community: "public"},
+			{Network: "10.0.0.0/30", Port: 1161, Version: 3, ADIdentifier: "switch"},
+		},
+	}
+	require.Nil(t, setSNMPDefaults(&cfg))
+	assert.Equal(t, snmpDefaultWorkers, cfg.Workers)
+	assert.Equal(t, snmpDefaultDiscoveryInterval, cfg.DiscoveryInterval)
+	assert.Equal(t, snmpDefaultAllowedFailures, cfg.AllowedFailures)
+	assert.Equal(t, uint16(161), cfg.Configs[0].Port)
+	assert.Equal(t, 2, cfg.Configs[0].Version)
+	assert.Equal(t, "snmp", cfg.Configs[0].ADIdentifier)
+	assert.Equal(t, uint16(1161), cfg.Configs[1].Port)
+	assert.Equal(t, 3, cfg.Configs[1].Version)
+	assert.Equal(t, "switch", cfg.Configs[1].ADIdentifier)
+
+	assert.NotNil(t, setSNMPDefaults(&config.SNMPListenerConfig{}))
+	assert.NotNil(t, setSNMPDefaults(&config.SNMPListenerConfig{
+		Configs: []config.SNMPConfig{{Network: "192.168.0.1"}},
+	}))
+	assert.NotNil(t, setSNMPDefaults(&config.SNMPListenerConfig{
+		Configs: []config.SNMPConfig{{Network: "10.0.0.0/8"}},
+	}))
+	assert.NotNil(t, setSNMPDefaults(&config.SNMPListenerConfig{
+		Configs: []config.SNMPConfig{{Network: "fd00::/120"}},
+	}))
+	assert.Nil(t, setSNMPDefaults(&config.SNMPListenerConfig{
+		Configs: []config.SNMPConfig{{Network: "10.0.0.0/16"}},
+	}))
+}
+
+func TestSNMPSweep(t *testing.T) {
+	cfg := config.SNMPListenerConfig{
+		AllowedFailures: 2,
+		Configs: []config.SNMPConfig{
+			{Network: "192.168.0.0/29", Community: "public"},
+		},
+	}
+	require.Nil(t, setSNMPDefaults(&cfg))
+
+	up := map[string]bool{"192.168.0.1": true, "192.168.0.5": true}
+	var probed []string
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	l := &SNMPListener{
+		config:   cfg,
+		services: make(map[ID]*SNMPService),
+		failures: make(map[ID]int),
+		stop:     make(chan struct{}),
+		probe: func(cfg config.SNMPConfig, ip string) bool {
+			probed = append(probed, ip)
+			return up[ip]
+		},
+		newService: newSvc,
+		delService: delSvc,
+	}
+	l.config.Workers = 1
+
+	l.sweep()
+	// the network and broadcast addresses are skipped
+	assert.Equal(t, []string{"192.168.0.1", "192.168.0.2", "192.168.0.3", "192.168.0.4", "192.168.0.5", "192.168.0.6"}, probed)
+	require.Len(t, newSvc, 2)
+	var ids []string
+	for i := 0; i < 2; i++ {
+		ids = append(ids, string((<-newSvc).GetID()))
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{"snmp://192.168.0.1:161", "snmp://192.168.0.5:161"}, ids)
+
+	svc := l.services["snmp://192.168.0.5:161"]
+	require.NotNil(t, svc)
+	hosts, _ := svc.GetHosts()
+	assert.Equal(t, map[string]string{"snmp": "192.168.0.5"}, hosts)
+	community, err := svc.GetExtraConfig("community")
+	require.Nil(t, err)
+	assert.Equal(t, "public", community)
+	_, err = svc.GetExtraConfig("unknown")
+	assert.Equal(t, ErrNotSupported, err)
+
+	// the device is removed after two sweeps without an answer
+	delete(up, "192.168.0.5")
+	l.sweep()
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 0)
+	l.sweep()
+	require.Len(t, delSvc, 1)
+	assert.Equal(t, ID("snmp://192.168.0.5:161"), (<-delSvc).GetID())
+	assert.Len(t, l.services, 1)
+
+	// a device answering again is reported again
+	up["192.168.0.5"] = true
+	l.sweep()
+	require.Len(t, newSvc, 1)
+	assert.Equal(t, ID("snmp://192.168.0.5:161"), (<-newSvc).GetID())
+}
@@ -31,6 +31,22 @@ type Service interface {
 	GetTags() ([]string, error)           // tags
 	GetPid() (int, error)                 // process identifier
 	GetHostname() (string, error)         // hostname.domainname for the entity
+	GetKubeNamespace() (string, error)    // namespace of the kubernetes pod
+	GetKubePodName() (string, error)      // name of the kubernetes pod
+}
+
+// NodeService is implemented by the services running on a known node, the
+// cluster checks resolved against them are dispatched to the agent of that node
+type NodeService interface {
+	Service
+	GetNodeName() string
+}
+
+// ExtraConfigService is implemented by the services exposing additional
+// parameters to their templates through the %%extra_<key>%% template variable
+type ExtraConfigService interface {
+	Service
+	GetExtraConfig(key string) (string, error)
 }
 
 // ServiceListener monitors running services and triggers check (un)scheduling
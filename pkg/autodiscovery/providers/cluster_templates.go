@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+)
+
+// ClusterTemplatesConfigProvider implements the ConfigProvider interface
+// for the check templates served by the cluster agent, e.g. the ones of the
+// ConfigMaps it watches. They are resolved against the services of the node.
+type ClusterTemplatesConfigProvider struct {
+	dcaClient  *clusteragent.DCAClient
+	lastChange int64
+}
+
+// NewClusterTemplatesConfigProvider returns a new ConfigProvider collecting
+// the check templates from the cluster-agent.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewClusterTemplatesConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	return &ClusterTemplatesConfigProvider{}, nil
+}
+
+// String returns a string representation of the ClusterTemplatesConfigProvider
+func (c *ClusterTemplatesConfigProvider) String() string {
+	return "cluster-templates"
+}
+
+// IsUpToDate queries the cluster-agent for the last change of its templates.
+func (c *ClusterTemplatesConfigProvider) IsUpToDate() (bool, error) {
+	reply, err := c.getTemplates()
+	if err != nil {
+		return false, err
+	}
+	return reply.LastChange == c.lastChange, nil
+}
+
+// Collect retrieves the check templates served by the cluster-agent.
+func (c *ClusterTemplatesConfigProvider) Collect() ([]integration.Config, error) {
+	reply, err := c.getTemplates()
+	if err != nil {
+		return nil, err
+	}
+	c.lastChange = reply.LastChange
+	return reply.Configs, nil
+}
+
+func (c *ClusterTemplatesConfigProvider) getTemplates() (clusterchecks.ConfigResponse, error) {
+	var err error
+	if c.dcaClient == nil {
+		c.dcaClient, err = clusteragent.GetClusterAgentClient()
+		if err != nil {
+			return clusterchecks.ConfigResponse{}, err
+		}
+	}
+	return c.dcaClient.GetClusterTemplates()
+}
+
+func init() {
+	RegisterProvider("cluster_templates", NewClusterTemplatesConfigProvider)
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ClusterChecksConfigProvider implements the ConfigProvider interface
+// for the cluster check feature: it polls the cluster agent for the
+// cluster checks dispatched to this node.
+type ClusterChecksConfigProvider struct {
+	dcaClient      *clusteragent.DCAClient
+	nodeName       string
+	lastSuccess    time.Time
+	expirationTime time.Duration
+}
+
+// NewClusterChecksConfigProvider returns a new ConfigProvider collecting
+// cluster check configurations from the cluster-agent.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewClusterChecksConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	return &ClusterChecksConfigProvider{
+		expirationTime: time.Duration(config.Datadog.GetInt64("cluster_checks.node_expiration_timeout")) * time.Second,
+	}, nil
+}
+
+// String returns a string representation of the ClusterChecksConfigProvider
+func (c *ClusterChecksConfigProvider) String() string {
+	return "cluster-checks"
+}
+
+// IsUpToDate queries the cluster-agent at every poll, as polling is also how
+// the node reports it is alive.
+func (c *ClusterChecksConfigProvider) IsUpToDate() (bool, error) {
+	return false, nil
+}
+
+// Collect retrieves the configurations the cluster-agent dispatched to this node.
+// If the cluster-agent cannot be reached for longer than the node expiration timeout,
+// the checks are unscheduled as they have been dispatched to other nodes.
+func (c *ClusterChecksConfigProvider) Collect() ([]integration.Config, error) {
+	configs, err := c.collect()
+	if err == nil {
+		c.lastSuccess = time.Now()
+		return configs, nil
+	}
+	if !c.lastSuccess.IsZero() && time.Since(c.lastSuccess) > c.expirationTime {
+		log.Warnf("Could not reach the cluster agent since %s, unscheduling the cluster checks: %s", c.lastSuccess, err)
+		c.lastSuccess = time.Time{}
+		return []integration.Config{}, nil
+	}
+	return nil, err
+}
+
+func (c *ClusterChecksConfigProvider) collect() ([]integration.Config, error) {
+	var err error
+	if c.dcaClient == nil {
+		c.dcaClient, err = clusteragent.GetClusterAgentClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.nodeName == "" {
+		c.nodeName, err = getNodeName()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.dcaClient.GetClusterCheckConfigs(c.nodeName)
+	if err != nil {
+		return nil, err
+	}
+	return reply.Configs, nil
+}
+
+func init() {
+	RegisterProvider("clusterchecks", NewClusterChecksConfigProvider)
+}
@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package providers
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+// getNodeName returns the Kubernetes node name, as endpoint checks are
+// pinned by the cluster-agent to the node name of the pods backing them.
+func getNodeName() (string, error) {
+	ku, err := kubelet.GetKubeUtil()
+	if err != nil {
+		return "", err
+	}
+	return ku.GetHostname()
+}
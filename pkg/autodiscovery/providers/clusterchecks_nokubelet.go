@@ -0,0 +1,17 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubelet
+
+package providers
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// getNodeName falls back to the agent hostname without the kubelet
+func getNodeName() (string, error) {
+	return util.GetHostname()
+}
@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	consul "github.com/hashicorp/consul/api"
@@ -40,12 +41,18 @@ func (c *consulWrapper) KV() consulKVBackend {
 	return c.client.KV()
 }
 
+// consulWaitTime is the maximum duration of the blocking queries watching
+// the templates, it bounds the time to stop the watch
+const consulWaitTime = time.Minute
+
 // ConsulConfigProvider implements the Config Provider interface
-// It should be called periodically and returns templates from consul for AutoConf.
+// It watches the templates in consul for AutoConf, it is polled while the
+// watch fails.
 type ConsulConfigProvider struct {
 	Client      consulBackend
 	TemplateDir string
 	cache       *ProviderCache
+	watch       watchState
 }
 
 // NewConsulConfigProvider creates a client connection to consul and create a new ConsulConfigProvider
@@ -104,6 +111,7 @@ func (p *ConsulConfigProvider) String() string {
 
 // Collect retrieves templates from consul, builds Config objects and returns them
 func (p *ConsulConfigProvider) Collect() ([]integration.Config, error) {
+	p.watch.collected()
 	configs := make([]integration.Config, 0)
 	identifiers := p.getIdentifiers(p.TemplateDir)
 	log.Debugf("identifiers found in backend: %v", identifiers)
@@ -116,6 +124,10 @@ func (p *ConsulConfigProvider) Collect() ([]integration.Config, error) {
 
 // IsUpToDate updates the list of AD templates versions in the Agent's cache and checks the list is up to date compared to Consul's data.
 func (p *ConsulConfigProvider) IsUpToDate() (bool, error) {
+	if upToDate, watching := p.watch.isUpToDate(); watching {
+		return upToDate, nil
+	}
+
 	kv := p.Client.KV()
 	adListUpdated := false
 	dateIdx := p.cache.LatestTemplateIdx
@@ -145,6 +157,50 @@ func (p *ConsulConfigProvider) IsUpToDate() (bool, error) {
 	return true, nil
 }
 
+// Watch watches the changes of the templates with consul blocking queries,
+// from the index of the last change seen so that no change is missed between
+// two queries. It returns when stop is closed.
+func (p *ConsulConfigProvider) Watch(changed chan<- struct{}, stop <-chan struct{}) {
+	defer p.watch.setWatching(false)
+
+	var waitIndex uint64
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		_, meta, err := p.Client.KV().List(p.TemplateDir, &consul.QueryOptions{WaitIndex: waitIndex, WaitTime: consulWaitTime})
+		if err != nil {
+			log.Warnf("Can't watch the templates in consul, polling them: %s", err)
+			p.watch.setWatching(false)
+			// the changes during the failure are unknown
+			waitIndex = 0
+			if !waitRetry(stop) {
+				return
+			}
+			continue
+		}
+
+		switch {
+		case waitIndex == 0:
+			// the changes since the last collection are unknown
+			p.watch.setWatching(true)
+			p.watch.notify(changed)
+		case meta.LastIndex < waitIndex:
+			// the index went backwards, e.g. the consul state was restored
+			log.Debugf("The consul index went back from %d to %d, watching from the current state", waitIndex, meta.LastIndex)
+			p.watch.notify(changed)
+		case meta.LastIndex > waitIndex:
+			log.Debugf("The templates in consul changed at index %d", meta.LastIndex)
+			p.watch.notify(changed)
+		}
+		// the query returned at the timeout if the index did not change
+		waitIndex = meta.LastIndex
+	}
+}
+
 // getIdentifiers gets folders at the root of the TemplateDir
 // verifies they have the right content to be a valid template
 // and return their names.
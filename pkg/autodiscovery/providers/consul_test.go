@@ -55,8 +55,9 @@ func (m *consulKVMock) Keys(prefix, separator string, q *consul.QueryOptions) ([
 func (m *consulKVMock) List(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
 	args := m.Called(prefix, q)
 	kvpairs, kvpairs_ok := args.Get(0).(consul.KVPairs)
+	meta, _ := args.Get(1).(*consul.QueryMeta)
 	if kvpairs_ok {
-		return kvpairs, nil, nil
+		return kvpairs, meta, nil
 	}
 	return nil, nil, args.Error(2)
 }
@@ -346,3 +347,41 @@ func TestIsUpToDate(t *testing.T) {
 	provider.AssertExpectations(t)
 	kv.AssertExpectations(t)
 }
+
+func TestConsulWatch(t *testing.T) {
+	kv := &consulKVMock{}
+	provider := &consulMock{kv: kv}
+	p := &ConsulConfigProvider{Client: provider, TemplateDir: "/datadog/tpl", cache: NewCPCache()}
+
+	changed := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	change := make(chan struct{})
+	kv.On("List", "/datadog/tpl", &consul.QueryOptions{WaitIndex: 0, WaitTime: consulWaitTime}).Return(consul.KVPairs{}, &consul.QueryMeta{LastIndex: 10}, nil).Once()
+	// blocks until the change is triggered
+	kv.On("List", "/datadog/tpl", &consul.QueryOptions{WaitIndex: 10, WaitTime: consulWaitTime}).Run(func(mock.Arguments) { <-change }).Return(consul.KVPairs{}, &consul.QueryMeta{LastIndex: 11}, nil).Once()
+	// blocks until the watch is stopped
+	kv.On("List", "/datadog/tpl", &consul.QueryOptions{WaitIndex: 11, WaitTime: consulWaitTime}).Run(func(mock.Arguments) { <-stop }).Return(consul.KVPairs{}, &consul.QueryMeta{LastIndex: 11}, nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		p.Watch(changed, stop)
+		close(done)
+	}()
+
+	// the initial state, then the change at index 11
+	<-changed
+	p.watch.collected()
+	close(change)
+	<-changed
+	upToDate, err := p.IsUpToDate()
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+	p.watch.collected()
+	upToDate, err = p.IsUpToDate()
+	assert.NoError(t, err)
+	assert.True(t, upToDate)
+
+	close(stop)
+	<-done
+	kv.AssertExpectations(t)
+}
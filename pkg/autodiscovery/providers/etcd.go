@@ -23,14 +23,17 @@ import (
 
 type etcdBackend interface {
 	Get(ctx context.Context, key string, opts *client.GetOptions) (*client.Response, error)
+	Watcher(key string, opts *client.WatcherOptions) client.Watcher
 }
 
 // EtcdConfigProvider implements the Config Provider interface
-// It should be called periodically and returns templates from etcd for AutoConf.
+// It watches the templates in etcd for AutoConf, it is polled while the
+// watch fails.
 type EtcdConfigProvider struct {
 	Client      etcdBackend
 	templateDir string
 	cache       *ProviderCache
+	watch       watchState
 }
 
 // NewEtcdConfigProvider creates a client connection to etcd and create a new EtcdConfigProvider
@@ -58,6 +61,7 @@ func NewEtcdConfigProvider(config config.ConfigurationProviders) (ConfigProvider
 // Collect retrieves templates from etcd, builds Config objects and returns them
 // TODO: cache templates and last-modified index to avoid future full crawl if no template changed.
 func (p *EtcdConfigProvider) Collect() ([]integration.Config, error) {
+	p.watch.collected()
 	configs := make([]integration.Config, 0)
 	identifiers := p.getIdentifiers(p.templateDir)
 	for _, id := range identifiers {
@@ -146,6 +150,9 @@ func (p *EtcdConfigProvider) getJSONValue(key string) ([]integration.Data, error
 
 // IsUpToDate updates the list of AD templates versions in the Agent's cache and checks the list is up to date compared to ETCD's data.
 func (p *EtcdConfigProvider) IsUpToDate() (bool, error) {
+	if upToDate, watching := p.watch.isUpToDate(); watching {
+		return upToDate, nil
+	}
 
 	adListUpdated := false
 	dateIdx := p.cache.LatestTemplateIdx
@@ -185,6 +192,66 @@ func (p *EtcdConfigProvider) IsUpToDate() (bool, error) {
 	return true, nil
 }
 
+// Watch watches the changes of the templates with the etcd watch API, from
+// the index of the last change seen so that no change is missed between two
+// watches. It returns when stop is closed.
+func (p *EtcdConfigProvider) Watch(changed chan<- struct{}, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	defer p.watch.setWatching(false)
+
+	var afterIndex uint64
+	for {
+		if afterIndex == 0 {
+			// start from the current state of the templates
+			resp, err := p.Client.Get(ctx, p.templateDir, &client.GetOptions{Recursive: true})
+			if err != nil {
+				log.Warnf("Can't watch the templates in etcd, polling them: %s", err)
+				p.watch.setWatching(false)
+				if !waitRetry(stop) {
+					return
+				}
+				continue
+			}
+			afterIndex = resp.Index
+			// the changes since the last collection are unknown
+			p.watch.notify(changed)
+		}
+
+		watcher := p.Client.Watcher(p.templateDir, &client.WatcherOptions{AfterIndex: afterIndex, Recursive: true})
+		p.watch.setWatching(true)
+		for {
+			resp, err := watcher.Next(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if etcdErr, ok := err.(client.Error); ok && etcdErr.Code == client.ErrorCodeEventIndexCleared {
+					// the index is too old, the history of etcd being limited
+					log.Debugf("The etcd index %d was cleared, watching from the current state", afterIndex)
+					afterIndex = 0
+					break
+				}
+				log.Warnf("Can't watch the templates in etcd, polling them: %s", err)
+				p.watch.setWatching(false)
+				if !waitRetry(stop) {
+					return
+				}
+				break
+			}
+			if resp.Node != nil {
+				afterIndex = resp.Node.ModifiedIndex
+			}
+			log.Debugf("The templates in etcd changed at index %d", afterIndex)
+			p.watch.notify(changed)
+		}
+	}
+}
+
 // String returns a string representation of the EtcdConfigProvider
 func (p *EtcdConfigProvider) String() string {
 	return "etcd Configuration Provider"
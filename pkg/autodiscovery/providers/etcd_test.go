@@ -28,6 +28,24 @@ func (m *etcdTest) Get(ctx context.Context, key string, opts *client.GetOptions)
 	return nil, args.Error(1)
 }
 
+func (m *etcdTest) Watcher(key string, opts *client.WatcherOptions) client.Watcher {
+	args := m.Called(key, opts)
+	return args.Get(0).(client.Watcher)
+}
+
+type etcdWatcherTest struct {
+	responses chan *client.Response
+}
+
+func (w *etcdWatcherTest) Next(ctx context.Context) (*client.Response, error) {
+	select {
+	case resp := <-w.responses:
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func createTestNode(key string) *client.Node {
 	return &client.Node{
 		Key:           key,
@@ -172,3 +190,40 @@ func TestETCDIsUpToDate(t *testing.T) {
 	assert.Equal(t, 2, etcd.cache.NumAdTemplates)
 	backend.AssertExpectations(t)
 }
+
+func TestEtcdWatch(t *testing.T) {
+	backend := &etcdTest{}
+	watcher := &etcdWatcherTest{responses: make(chan *client.Response)}
+	backend.On("Get", mock.Anything, "/datadog/tpl", &client.GetOptions{Recursive: true}).Return(&client.Response{Index: 42}, nil)
+	backend.On("Watcher", "/datadog/tpl", &client.WatcherOptions{AfterIndex: 42, Recursive: true}).Return(watcher)
+	p := &EtcdConfigProvider{Client: backend, templateDir: "/datadog/tpl", cache: NewCPCache()}
+
+	changed := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Watch(changed, stop)
+		close(done)
+	}()
+
+	// the initial state is to be collected
+	<-changed
+	p.watch.collected()
+
+	// a change is notified, the provider is outdated until the next collection
+	watcher.responses <- &client.Response{Node: &client.Node{Key: "/datadog/tpl/nginx/instances", ModifiedIndex: 43}}
+	<-changed
+	upToDate, err := p.IsUpToDate()
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+	p.watch.collected()
+	upToDate, err = p.IsUpToDate()
+	assert.NoError(t, err)
+	assert.True(t, upToDate)
+
+	close(stop)
+	<-done
+	_, watching := p.watch.isUpToDate()
+	assert.False(t, watching)
+	backend.AssertExpectations(t)
+}
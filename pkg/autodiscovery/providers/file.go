@@ -25,6 +25,7 @@ type configFormat struct {
 	LogsConfig    interface{} `yaml:"logs"`
 	Instances     []integration.RawMap
 	DockerImages  []string `yaml:"docker_images"` // Only imported for deprecation warning
+	ClusterCheck  bool     `yaml:"cluster_check"`
 }
 
 type configPkg struct {
@@ -242,18 +243,24 @@ func (c *FileConfigProvider) collectDir(parentPath string, folder os.FileInfo) c
 
 // GetIntegrationConfigFromFile returns an instance of integration.Config if `fpath` points to a valid config file
 func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error) {
-	cf := configFormat{}
-	config := integration.Config{Name: name}
-
 	// Read file contents
 	// FIXME: ReadFile reads the entire file, possible security implications
 	yamlFile, err := ioutil.ReadFile(fpath)
 	if err != nil {
-		return config, err
+		return integration.Config{Name: name}, err
 	}
 
+	return getIntegrationConfigFromYAML(name, yamlFile)
+}
+
+// getIntegrationConfigFromYAML returns an instance of integration.Config if
+// yamlFile is a valid configuration, in the format of the configuration files
+func getIntegrationConfigFromYAML(name string, yamlFile []byte) (integration.Config, error) {
+	cf := configFormat{}
+	config := integration.Config{Name: name}
+
 	// Parse configuration
-	err = yaml.Unmarshal(yamlFile, &cf)
+	err := yaml.Unmarshal(yamlFile, &cf)
 	if err != nil {
 		return config, err
 	}
@@ -292,6 +299,9 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Copy auto discovery identifiers
 	config.ADIdentifiers = cf.ADIdentifiers
 
+	// Copy cluster_check status
+	config.ClusterCheck = cf.ClusterCheck
+
 	// DockerImages entry was found: we ignore it if no ADIdentifiers has been found
 	if len(cf.DockerImages) > 0 && len(cf.ADIdentifiers) == 0 {
 		return config, errors.New("the 'docker_images' section is deprecated, please use 'ad_identifiers' instead")
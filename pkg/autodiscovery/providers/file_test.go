@@ -52,6 +52,13 @@ func TestGetIntegrationConfig(t *testing.T) {
 	config, err = GetIntegrationConfigFromFile("foo", "tests/ad.yaml")
 	require.Nil(t, err)
 	assert.Equal(t, config.ADIdentifiers, []string{"foo_id", "bar_id"})
+	assert.False(t, config.ClusterCheck)
+
+	// cluster check
+	config, err = GetIntegrationConfigFromFile("foo", "tests/cluster_check.yaml")
+	require.Nil(t, err)
+	assert.True(t, config.ClusterCheck)
+	assert.Equal(t, len(config.Instances), 1)
 
 	// autodiscovery: check if we correctly refuse to load if a 'docker_images' section is present
 	config, err = GetIntegrationConfigFromFile("foo", "tests/ad_deprecated.yaml")
@@ -90,6 +97,7 @@ func TestCollect(t *testing.T) {
 	// the regular configs
 	assert.Equal(t, 3, len(get("testcheck")))
 	assert.Equal(t, 1, len(get("ad")))
+	assert.Equal(t, 1, len(get("cluster_check")))
 
 	// default configs must be picked up
 	assert.Equal(t, 1, len(get("bar")))
@@ -120,7 +128,7 @@ func TestCollect(t *testing.T) {
 	assert.Equal(t, 1, len(get("logs-agent_only")))
 
 	// total number of configurations found
-	assert.Equal(t, 14, len(configs))
+	assert.Equal(t, 15, len(configs))
 
 	// incorrect configs get saved in the Errors map (invalid.yaml & notaconfig.yaml & ad_deprecated.yaml)
 	assert.Equal(t, 3, len(provider.Errors))
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const httpProviderTimeout = 10 * time.Second
+
+// HTTPConfigProvider implements the ConfigProvider interface for the check
+// configurations served by an HTTP(S) endpoint. The document at template_url
+// maps check names to configurations in the format of the configuration
+// files, e.g.:
+//
+//   redisdb:
+//     ad_identifiers:
+//       - redis
+//     init_config:
+//     instances:
+//       - host: "%%host%%"
+//
+// The document is only collected again when its ETag changes.
+type HTTPConfigProvider struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+	etag    string
+	configs []integration.Config
+}
+
+// NewHTTPConfigProvider returns a new HTTPConfigProvider, authenticating with
+// the username and password, or the token, and sending the configured headers
+func NewHTTPConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	if cfg.TemplateURL == "" {
+		return nil, fmt.Errorf("the http config provider needs a template_url")
+	}
+
+	transport := &http.Transport{}
+	if cfg.CAFile != "" || cfg.CertFile != "" {
+		tlsConfig := &tls.Config{}
+		if cfg.CAFile != "" {
+			ca, err := ioutil.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("could not read the CA file: %s", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("could not load the CA file %s", cfg.CAFile)
+			}
+		}
+		if cfg.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("could not load the client certificate: %s", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	headers := make(map[string]string, len(cfg.Headers)+1)
+	for name, value := range cfg.Headers {
+		headers[name] = value
+	}
+	if cfg.Token != "" {
+		headers["Authorization"] = "Bearer " + cfg.Token
+	} else if cfg.Username != "" {
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+		headers["Authorization"] = req.Header.Get("Authorization")
+	}
+
+	return &HTTPConfigProvider{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   httpProviderTimeout,
+		},
+		url:     cfg.TemplateURL,
+		headers: headers,
+	}, nil
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return "HTTP"
+}
+
+// IsUpToDate returns whether the document still has the ETag of the last collection
+func (p *HTTPConfigProvider) IsUpToDate() (bool, error) {
+	if p.etag == "" {
+		return false, nil
+	}
+	resp, err := p.get()
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusNotModified, nil
+}
+
+// Collect fetches the document and returns its configurations, the last
+// ones if it didn't change
+func (p *HTTPConfigProvider) Collect() ([]integration.Config, error) {
+	resp, err := p.get()
+	if err != nil {
+		return []integration.Config{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return p.configs, nil
+	case http.StatusOK:
+	default:
+		return []integration.Config{}, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, p.url)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []integration.Config{}, fmt.Errorf("could not read the templates: %s", err)
+	}
+	configs, err := parseHTTPTemplates(body)
+	if err != nil {
+		return []integration.Config{}, err
+	}
+	p.configs = configs
+	p.etag = resp.Header.Get("ETag")
+	return configs, nil
+}
+
+// get requests the document, conditionally to a change of its ETag
+func (p *HTTPConfigProvider) get() (*http.Response, error) {
+	req, err := http.NewRequest("GET", p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+	return p.client.Do(req)
+}
+
+// parseHTTPTemplates returns the configurations of the document, by check name
+func parseHTTPTemplates(body []byte) ([]integration.Config, error) {
+	document := make(map[string]interface{})
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("could not parse the templates: %s", err)
+	}
+
+	// sort the check names for the configurations to be stable between the collections
+	names := make([]string, 0, len(document))
+	for name := range document {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	configs := []integration.Config{}
+	for _, name := range names {
+		raw, err := yaml.Marshal(document[name])
+		if err != nil {
+			log.Errorf("Can't read the %s configuration: %s", name, err)
+			continue
+		}
+		cfg, err := getIntegrationConfigFromYAML(name, raw)
+		if err != nil {
+			log.Errorf("Can't parse the %s configuration: %s", name, err)
+			continue
+		}
+		configs = append(configs, cfg)
+	}
+	return configs, nil
+}
+
+func init() {
+	RegisterProvider("http", NewHTTPConfigProvider)
+}
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const httpTemplates = `
+redisdb:
+  ad_identifiers:
+    - redis
+  init_config:
+  instances:
+    - host: "%%host%%"
+      port: "6379"
+nginx:
+  ad_identifiers:
+    - nginx
+  init_config:
+  instances:
+    - nginx_status_url: "http://%%host%%/nginx_status/"
+`
+
+func TestHTTPCollect(t *testing.T) {
+	requests := 0
+	etag := `"v1"`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "Bearer s3cr3t", r.Header.Get("Authorization"))
+		assert.Equal(t, "monitoring", r.Header.Get("X-Team"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(httpTemplates))
+	}))
+	defer ts.Close()
+
+	p, err := NewHTTPConfigProvider(config.ConfigurationProviders{
+		TemplateURL: ts.URL,
+		Token:       "s3cr3t",
+		Headers:     map[string]string{"X-Team": "monitoring"},
+	})
+	require.Nil(t, err)
+
+	upToDate, err := p.IsUpToDate()
+	require.Nil(t, err)
+	assert.False(t, upToDate)
+	assert.Equal(t, 0, requests)
+
+	configs, err := p.Collect()
+	require.Nil(t, err)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "nginx", configs[0].Name)
+	assert.Equal(t, []string{"nginx"}, configs[0].ADIdentifiers)
+	assert.Equal(t, "redisdb", configs[1].Name)
+	assert.Equal(t, []string{"redis"}, configs[1].ADIdentifiers)
+	require.Len(t, configs[1].Instances, 1)
+	assert.Contains(t, string(configs[1].Instances[0]), "%%host%%")
+
+	// same ETag
+	upToDate, err = p.IsUpToDate()
+	require.Nil(t, err)
+	assert.True(t, upToDate)
+	configs, err = p.Collect()
+	require.Nil(t, err)
+	assert.Len(t, configs, 2)
+
+	// new ETag
+	etag = `"v2"`
+	upToDate, err = p.IsUpToDate()
+	require.Nil(t, err)
+	assert.False(t, upToDate)
+	assert.Equal(t, 4, requests)
+}
+
+func TestHTTPCollectError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "datadog" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("not: [valid"))
+	}))
+	defer ts.Close()
+
+	p, err := NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: ts.URL})
+	require.Nil(t, err)
+	configs, err := p.Collect()
+	assert.NotNil(t, err)
+	assert.Len(t, configs, 0)
+
+	p, err = NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: ts.URL, Username: "datadog", Password: "secret"})
+	require.Nil(t, err)
+	_, err = p.Collect()
+	assert.NotNil(t, err)
+
+	_, err = NewHTTPConfigProvider(config.ConfigurationProviders{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// configMapsSyncTimeout is how long a collection waits for the cache of the
+// ConfigMaps informer to sync
+const configMapsSyncTimeout = 10 * time.Second
+
+var errConfigMapsNotSynced = errors.New("the ConfigMaps informer is not synced")
+
+// KubeConfigMapsConfigProvider implements the ConfigProvider interface for the
+// check configurations of the ConfigMaps matching a label selector: each
+// <check_name>.yaml key holds a configuration in the format of the
+// configuration files. The configurations are run by the cluster agent, or
+// dispatched as cluster checks, and the templates are served to the node
+// agents, which resolve them against their own services.
+type KubeConfigMapsConfigProvider struct {
+	apiClient     *apiserver.APIClient
+	labelSelector labels.Selector
+	versions      map[string]string // the resource versions of the collected ConfigMaps, by namespace/name
+}
+
+// NewKubeConfigMapsConfigProvider returns a new ConfigProvider collecting the
+// check configurations of the ConfigMaps.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewKubeConfigMapsConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	labelSelector, err := labels.Parse(config.Datadog.GetString("kube_configmaps_provider.label_selector"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid kube_configmaps_provider.label_selector: %s", err)
+	}
+	return &KubeConfigMapsConfigProvider{
+		labelSelector: labelSelector,
+	}, nil
+}
+
+// String returns a string representation of the KubeConfigMapsConfigProvider
+func (k *KubeConfigMapsConfigProvider) String() string {
+	return "Kubernetes ConfigMaps"
+}
+
+// IsUpToDate returns whether the labelled ConfigMaps are the ones of the last
+// collection, at the same resource versions.
+func (k *KubeConfigMapsConfigProvider) IsUpToDate() (bool, error) {
+	configMaps, err := k.listConfigMaps()
+	if err != nil {
+		return false, err
+	}
+	versions := configMapVersions(configMaps)
+	if len(versions) != len(k.versions) {
+		return false, nil
+	}
+	for key, version := range versions {
+		if k.versions[key] != version {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Collect lists the labelled ConfigMaps, publishes their templates for the
+// node agents and returns their other configurations.
+func (k *KubeConfigMapsConfigProvider) Collect() ([]integration.Config, error) {
+	configMaps, err := k.listConfigMaps()
+	if err != nil {
+		return []integration.Config{}, err
+	}
+
+	configs, templates := parseConfigMaps(configMaps)
+	clusterchecks.SetTemplates(templates)
+	k.versions = configMapVersions(configMaps)
+	return configs, nil
+}
+
+// listConfigMaps lists the labelled ConfigMaps from the cache of the informer
+// shared by the consumers of the apiserver, resolved on every call to follow
+// the replacements of the client set. The ConfigMaps must not be modified.
+func (k *KubeConfigMapsConfigProvider) listConfigMaps() ([]*v1.ConfigMap, error) {
+	var err error
+	if k.apiClient == nil {
+		k.apiClient, err = apiserver.GetAPIClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	factory, stop := k.apiClient.InformerFactory()
+	configMaps := factory.Core().V1().ConfigMaps()
+	if !apiserver.SyncInformers(factory, stop, configMapsSyncTimeout, configMaps.Informer().HasSynced) {
+		return nil, errConfigMapsNotSynced
+	}
+	return configMaps.Lister().List(k.labelSelector)
+}
+
+// configMapVersions returns the resource versions of the ConfigMaps, by
+// namespace/name
+func configMapVersions(configMaps []*v1.ConfigMap) map[string]string {
+	versions := make(map[string]string, len(configMaps))
+	for _, cm := range configMaps {
+		versions[cm.Namespace+"/"+cm.Name] = cm.ResourceVersion
+	}
+	return versions
+}
+
+// parseConfigMaps returns the configurations and the templates of the ConfigMaps
+func parseConfigMaps(configMaps []*v1.ConfigMap) ([]integration.Config, []integration.Config) {
+	configs := []integration.Config{}
+	templates := []integration.Config{}
+
+	// the listers return the ConfigMaps in no particular order
+	sorted := make([]*v1.ConfigMap, len(configMaps))
+	copy(sorted, configMaps)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, cm := range sorted {
+		// sort the keys for the configurations to be stable between the collections
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			checkName := configMapCheckName(key)
+			if checkName == "" {
+				log.Debugf("Ignoring the key %s of the ConfigMap %s/%s, not a check configuration", key, cm.Namespace, cm.Name)
+				continue
+			}
+			cfg, err := getIntegrationConfigFromYAML(checkName, []byte(cm.Data[key]))
+			if err != nil {
+				log.Errorf("Can't parse the %s configuration of the ConfigMap %s/%s: %s", checkName, cm.Namespace, cm.Name, err)
+				continue
+			}
+			if cfg.IsTemplate() {
+				templates = append(templates, cfg)
+			} else {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+	return configs, templates
+}
+
+// configMapCheckName returns the check name of a <check_name>.yaml key, empty
+// if the key is not a check configuration
+func configMapCheckName(key string) string {
+	for _, ext := range []string{".yaml", ".yml"} {
+		if strings.HasSuffix(key, ext) {
+			return strings.TrimSuffix(key, ext)
+		}
+	}
+	return ""
+}
+
+func init() {
+	RegisterProvider("kube_configmaps", NewKubeConfigMapsConfigProvider)
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestParseConfigMaps(t *testing.T) {
+	configMaps := []*v1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "default"},
+			Data: map[string]string{
+				"redisdb.yaml":   "ad_identifiers:\n  - redis\ninit_config:\ninstances:\n  - host: '%%host%%'\n    port: '6379'\n",
+				"http_check.yml": "cluster_check: true\ninit_config:\ninstances:\n  - name: My service\n    url: http://my-service.default.svc\n",
+				"README.md":      "not a check",
+				"invalid.yaml":   "init_config:\n",
+			},
+		},
+	}
+
+	configs, templates := parseConfigMaps(configMaps)
+
+	require.Len(t, configs, 1)
+	assert.Equal(t, "http_check", configs[0].Name)
+	assert.True(t, configs[0].ClusterCheck)
+	assert.Equal(t, []integration.Data{integration.Data("name: My service\nurl: http://my-service.default.svc\n")}, configs[0].Instances)
+
+	require.Len(t, templates, 1)
+	assert.Equal(t, "redisdb", templates[0].Name)
+	assert.Equal(t, []string{"redis"}, templates[0].ADIdentifiers)
+	assert.Equal(t, []integration.Data{integration.Data("host: '%%host%%'\nport: \"6379\"\n")}, templates[0].Instances)
+}
+
+func TestConfigMapCheckName(t *testing.T) {
+	assert.Equal(t, "redisdb", configMapCheckName("redisdb.yaml"))
+	assert.Equal(t, "redisdb", configMapCheckName("redisdb.yml"))
+	assert.Equal(t, "", configMapCheckName("redisdb.json"))
+}
+
+func TestConfigMapVersions(t *testing.T) {
+	configMaps := []*v1.ConfigMap{
+		{ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "default", ResourceVersion: "12"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "kube-system", ResourceVersion: "34"}},
+	}
+	assert.Equal(t, map[string]string{"default/checks": "12", "kube-system/checks": "34"}, configMapVersions(configMaps))
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeEndpointAnnotationPrefix = "ad.datadoghq.com/endpoints."
+	// kubeEndpointIDFormat is the AD identifier of the endpoints of a service,
+	// reported by the kube_endpoints listener
+	kubeEndpointIDFormat = "kube_endpoint://%s/%s"
+)
+
+// KubeEndpointsConfigProvider implements the ConfigProvider interface for the
+// endpoint checks: the templates in the ad.datadoghq.com/endpoints.* annotations
+// of the kube services are resolved by the kube_endpoints listener against each
+// endpoint of the service, and dispatched by the cluster agent to the node
+// running the endpoint.
+type KubeEndpointsConfigProvider struct {
+	apiClient *apiserver.APIClient
+}
+
+// NewKubeEndpointsConfigProvider returns a new ConfigProvider collecting the endpoint checks.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewKubeEndpointsConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	return &KubeEndpointsConfigProvider{}, nil
+}
+
+// String returns a string representation of the KubeEndpointsConfigProvider
+func (k *KubeEndpointsConfigProvider) String() string {
+	return "Kubernetes endpoints"
+}
+
+// IsUpToDate always lists the services, as their annotations can change at any time.
+func (k *KubeEndpointsConfigProvider) IsUpToDate() (bool, error) {
+	return false, nil
+}
+
+// Collect lists the annotated services and returns their endpoint check templates.
+func (k *KubeEndpointsConfigProvider) Collect() ([]integration.Config, error) {
+	var err error
+	if k.apiClient == nil {
+		k.apiClient, err = apiserver.GetAPIClient()
+		if err != nil {
+			return []integration.Config{}, err
+		}
+	}
+
+	services, err := k.apiClient.Client().CoreV1().Services(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return []integration.Config{}, err
+	}
+	return parseServiceAnnotations(services.Items, kubeEndpointAnnotationPrefix, kubeEndpointIDFormat), nil
+}
+
+// parseServiceAnnotations returns the cluster check templates in the annotations
+// of the services starting with prefix, identified by the idFormat of the service.
+func parseServiceAnnotations(services []v1.Service, prefix, idFormat string) []integration.Config {
+	var configs []integration.Config
+	for _, svc := range services {
+		adIdentifier := fmt.Sprintf(idFormat, svc.Namespace, svc.Name)
+		templates, err := extractTemplatesFromMap(adIdentifier, svc.Annotations, prefix)
+		if err != nil {
+			log.Errorf("Can't parse the check templates of the service %s/%s: %s", svc.Namespace, svc.Name, err)
+			continue
+		}
+		for i := range templates {
+			templates[i].ClusterCheck = true
+		}
+		configs = append(configs, templates...)
+	}
+	return configs
+}
+
+func init() {
+	RegisterProvider("kube_endpoints", NewKubeEndpointsConfigProvider)
+}
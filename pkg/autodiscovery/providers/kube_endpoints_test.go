@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestParseServiceAnnotations(t *testing.T) {
+	services := []v1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"ad.datadoghq.com/endpoints.check_names":  `["http_check"]`,
+					"ad.datadoghq.com/endpoints.init_configs": `[{}]`,
+					"ad.datadoghq.com/endpoints.instances":    `[{"url": "http://%%host%%:%%port%%/health"}]`,
+					"ad.datadoghq.com/service.check_names":    `["tcp_check"]`,
+					"ad.datadoghq.com/service.init_configs":   `[{}]`,
+					"ad.datadoghq.com/service.instances":      `[{"host": "%%host%%", "port": "%%port%%"}]`,
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-annotated", Namespace: "default"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "invalid",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"ad.datadoghq.com/endpoints.check_names": `["http_check"]`,
+				},
+			},
+		},
+	}
+
+	configs := parseServiceAnnotations(services, kubeEndpointAnnotationPrefix, kubeEndpointIDFormat)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "http_check", configs[0].Name)
+	assert.True(t, configs[0].ClusterCheck)
+	assert.Equal(t, "", configs[0].NodeName)
+	assert.Equal(t, []string{"kube_endpoint://default/web"}, configs[0].ADIdentifiers)
+	assert.Equal(t, []integration.Data{integration.Data(`{"url":"http://%%host%%:%%port%%/health"}`)}, configs[0].Instances)
+
+	configs = parseServiceAnnotations(services, kubeServiceAnnotationPrefix, kubeServiceIDFormat)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "tcp_check", configs[0].Name)
+	assert.True(t, configs[0].ClusterCheck)
+	assert.Equal(t, []string{"kube_service://default/web"}, configs[0].ADIdentifiers)
+}
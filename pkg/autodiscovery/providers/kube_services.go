@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+const (
+	kubeServiceAnnotationPrefix = "ad.datadoghq.com/service."
+	// kubeServiceIDFormat is the AD identifier of a service, reported by the
+	// kube_services listener
+	kubeServiceIDFormat = "kube_service://%s/%s"
+)
+
+// KubeServiceConfigProvider implements the ConfigProvider interface for the
+// cluster checks of the kube services: the templates in their
+// ad.datadoghq.com/service.* annotations are resolved by the kube_services
+// listener against the cluster IP of the service.
+type KubeServiceConfigProvider struct {
+	apiClient *apiserver.APIClient
+}
+
+// NewKubeServiceConfigProvider returns a new ConfigProvider collecting the service checks.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewKubeServiceConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	return &KubeServiceConfigProvider{}, nil
+}
+
+// String returns a string representation of the KubeServiceConfigProvider
+func (k *KubeServiceConfigProvider) String() string {
+	return "Kubernetes services"
+}
+
+// IsUpToDate always lists the services, as their annotations can change at any time.
+func (k *KubeServiceConfigProvider) IsUpToDate() (bool, error) {
+	return false, nil
+}
+
+// Collect lists the annotated services and returns their check templates.
+func (k *KubeServiceConfigProvider) Collect() ([]integration.Config, error) {
+	var err error
+	if k.apiClient == nil {
+		k.apiClient, err = apiserver.GetAPIClient()
+		if err != nil {
+			return []integration.Config{}, err
+		}
+	}
+
+	services, err := k.apiClient.Client().CoreV1().Services(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return []integration.Config{}, err
+	}
+	return parseServiceAnnotations(services.Items, kubeServiceAnnotationPrefix, kubeServiceIDFormat), nil
+}
+
+func init() {
+	RegisterProvider("kube_services", NewKubeServiceConfigProvider)
+}
@@ -84,12 +84,23 @@ func parseKubeletPodlist(podlist []*kubelet.Pod) ([]integration.Config, error) {
 		if adExtractFormat == "" {
 			continue
 		}
+		adPrefix := newPodAnnotationPrefix
 		if adExtractFormat == legacyPodAnnotationFormat {
+			adPrefix = legacyPodAnnotationPrefix
 			log.Warnf("found legacy annotations %s for %s, please use the new prefix %s",
 				legacyPodAnnotationPrefix, pod.Metadata.Name, newPodAnnotationPrefix)
 		}
+		for _, name := range unknownTemplateContainers(pod, adPrefix) {
+			log.Warnf("The pod %s has templates for the container %s it doesn't have, check the annotations", pod.Metadata.Name, name)
+		}
 
 		for _, container := range pod.Status.Containers {
+			if container.ID == "" {
+				// the container is not created yet, its templates are
+				// collected once it is, with its ID
+				log.Debugf("Container %s of pod %s has no ID yet, skipping its templates", container.Name, pod.Metadata.Name)
+				continue
+			}
 			c, err := extractTemplatesFromMap(container.ID, pod.Metadata.Annotations,
 				fmt.Sprintf(adExtractFormat, container.Name))
 			switch {
@@ -107,6 +118,31 @@ func parseKubeletPodlist(podlist []*kubelet.Pod) ([]integration.Config, error) {
 	return configs, nil
 }
 
+// unknownTemplateContainers returns the container names of the AD annotations
+// with the prefix that match no container of the pod, e.g. a typo
+func unknownTemplateContainers(pod *kubelet.Pod, prefix string) []string {
+	containers := make(map[string]bool)
+	for _, container := range pod.Spec.Containers {
+		containers[container.Name] = true
+	}
+	for _, container := range pod.Status.Containers {
+		containers[container.Name] = true
+	}
+
+	var unknown []string
+	suffix := "." + checkNamePath
+	for name := range pod.Metadata.Annotations {
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		containerName := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+		if !containers[containerName] {
+			unknown = append(unknown, containerName)
+		}
+	}
+	return unknown
+}
+
 func init() {
 	RegisterProvider("kubelet", NewKubeletConfigProvider)
 }
@@ -145,6 +145,26 @@ func TestParseKubeletPodlist(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "Container not created yet",
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Annotations: map[string]string{
+						"ad.datadoghq.com/apache.check_names":  "[\"http_check\"]",
+						"ad.datadoghq.com/apache.init_configs": "[{}]",
+						"ad.datadoghq.com/apache.instances":    "[{\"name\": \"My service\", \"url\": \"http://%%host%%\", \"timeout\": 1}]",
+					},
+				},
+				Status: kubelet.Status{
+					Containers: []kubelet.ContainerStatus{
+						{
+							Name: "apache",
+						},
+					},
+				},
+			},
+			expectedCfg: nil,
+		},
 	} {
 		t.Run(fmt.Sprintf("case %d: %s", nb, tc.desc), func(t *testing.T) {
 			checks, err := parseKubeletPodlist([]*kubelet.Pod{tc.pod})
@@ -155,3 +175,38 @@ func TestParseKubeletPodlist(t *testing.T) {
 		})
 	}
 }
+
+func TestUnknownTemplateContainers(t *testing.T) {
+	pod := &kubelet.Pod{
+		Metadata: kubelet.PodMetadata{
+			Annotations: map[string]string{
+				"ad.datadoghq.com/apache.check_names":  "[\"http_check\"]",
+				"ad.datadoghq.com/apache.init_configs": "[{}]",
+				"ad.datadoghq.com/apache.instances":    "[{}]",
+				"ad.datadoghq.com/nginx.check_names":   "[\"http_check\"]",
+				"ad.datadoghq.com/nginx.init_configs":  "[{}]",
+				"ad.datadoghq.com/nginx.instances":     "[{}]",
+				"ad.datadoghq.com/ngnix.check_names":   "[\"http_check\"]",
+				"ad.datadoghq.com/ngnix.init_configs":  "[{}]",
+				"ad.datadoghq.com/ngnix.instances":     "[{}]",
+			},
+		},
+		Spec: kubelet.Spec{
+			Containers: []kubelet.ContainerSpec{
+				{Name: "apache"},
+				{Name: "nginx"},
+			},
+		},
+		Status: kubelet.Status{
+			Containers: []kubelet.ContainerStatus{
+				{
+					Name: "apache",
+					ID:   "docker://3b8efe0c50e8",
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"ngnix"}, unknownTemplateContainers(pod, newPodAnnotationPrefix))
+	assert.Len(t, unknownTemplateContainers(pod, legacyPodAnnotationPrefix), 0)
+}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	prometheusScrapeAnnotation = "prometheus.io/scrape"
+	prometheusPortAnnotation   = "prometheus.io/port"
+	prometheusPathAnnotation   = "prometheus.io/path"
+	prometheusSchemeAnnotation = "prometheus.io/scheme"
+	openmetricsCheckName       = "openmetrics"
+)
+
+// prometheusScrapeConfig selects the pods and services scraped through their
+// prometheus.io/* annotations, and the metrics collected from them
+type prometheusScrapeConfig struct {
+	namespaces map[string]bool
+	metrics    []string
+}
+
+type openmetricsInstance struct {
+	PrometheusURL string   `yaml:"prometheus_url"`
+	Namespace     string   `yaml:"namespace"`
+	Metrics       []string `yaml:"metrics"`
+}
+
+// newPrometheusScrapeConfig reads the prometheus_scrape options
+func newPrometheusScrapeConfig() prometheusScrapeConfig {
+	cfg := prometheusScrapeConfig{
+		metrics: config.Datadog.GetStringSlice("prometheus_scrape.metrics"),
+	}
+	if namespaces := config.Datadog.GetStringSlice("prometheus_scrape.namespaces"); len(namespaces) > 0 {
+		cfg.namespaces = make(map[string]bool, len(namespaces))
+		for _, ns := range namespaces {
+			cfg.namespaces[ns] = true
+		}
+	}
+	return cfg
+}
+
+// isScraped returns whether the annotations of an object of the kube
+// namespace ask for its scraping, and the namespace is allowed
+func (c prometheusScrapeConfig) isScraped(namespace string, annotations map[string]string) bool {
+	if annotations[prometheusScrapeAnnotation] != "true" {
+		return false
+	}
+	return c.namespaces == nil || c.namespaces[namespace]
+}
+
+// buildConfig returns the openmetrics template scraping the endpoint of the
+// annotations, on the last port of the service when there is no port annotation
+func (c prometheusScrapeConfig) buildConfig(adID string, annotations map[string]string) (integration.Config, error) {
+	scheme := annotations[prometheusSchemeAnnotation]
+	if scheme == "" {
+		scheme = "http"
+	}
+	port := annotations[prometheusPortAnnotation]
+	if port == "" {
+		port = "%%port%%"
+	}
+	path := annotations[prometheusPathAnnotation]
+	if path == "" {
+		path = "/metrics"
+	}
+
+	instance, err := yaml.Marshal(openmetricsInstance{
+		PrometheusURL: fmt.Sprintf("%s://%%%%host%%%%:%s%s", scheme, port, path),
+		Metrics:       c.metrics,
+	})
+	if err != nil {
+		return integration.Config{}, err
+	}
+	return integration.Config{
+		Name:          openmetricsCheckName,
+		InitConfig:    integration.Data("{}"),
+		Instances:     []integration.Data{instance},
+		ADIdentifiers: []string{adID},
+	}, nil
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package providers
+
+import (
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// PrometheusPodsConfigProvider implements the ConfigProvider interface for
+// the pods of the node having the prometheus.io/scrape annotation: an
+// openmetrics check scrapes the endpoint of their prometheus.io/* annotations.
+type PrometheusPodsConfigProvider struct {
+	kubelet *kubelet.KubeUtil
+	scrape  prometheusScrapeConfig
+}
+
+// NewPrometheusPodsConfigProvider returns a new ConfigProvider scraping the annotated pods.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewPrometheusPodsConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	return &PrometheusPodsConfigProvider{
+		scrape: newPrometheusScrapeConfig(),
+	}, nil
+}
+
+// String returns a string representation of the PrometheusPodsConfigProvider
+func (p *PrometheusPodsConfigProvider) String() string {
+	return "Prometheus pods"
+}
+
+// IsUpToDate always lists the pods, as their annotations can change at any time.
+func (p *PrometheusPodsConfigProvider) IsUpToDate() (bool, error) {
+	return false, nil
+}
+
+// Collect lists the pods of the node and returns the templates of the annotated ones
+func (p *PrometheusPodsConfigProvider) Collect() ([]integration.Config, error) {
+	var err error
+	if p.kubelet == nil {
+		p.kubelet, err = kubelet.GetKubeUtil()
+		if err != nil {
+			return []integration.Config{}, err
+		}
+	}
+
+	pods, err := p.kubelet.GetLocalPodList()
+	if err != nil {
+		return []integration.Config{}, err
+	}
+	return p.parsePods(pods), nil
+}
+
+// parsePods returns a template per annotated pod, resolved against the
+// container exposing the annotated port, or its first container
+func (p *PrometheusPodsConfigProvider) parsePods(pods []*kubelet.Pod) []integration.Config {
+	configs := []integration.Config{}
+	for _, pod := range pods {
+		if !p.scrape.isScraped(pod.Metadata.Namespace, pod.Metadata.Annotations) {
+			continue
+		}
+		containerID := scrapedContainerID(pod)
+		if containerID == "" {
+			log.Debugf("No container to scrape in the pod %s yet, skipping it", pod.Metadata.Name)
+			continue
+		}
+		cfg, err := p.scrape.buildConfig(containerID, pod.Metadata.Annotations)
+		if err != nil {
+			log.Errorf("Can't build the openmetrics template of the pod %s: %s", pod.Metadata.Name, err)
+			continue
+		}
+		configs = append(configs, cfg)
+	}
+	return configs
+}
+
+// scrapedContainerID returns the ID of the container exposing the port of the
+// prometheus.io/port annotation, or of the first container of the pod
+func scrapedContainerID(pod *kubelet.Pod) string {
+	name := ""
+	if port, err := strconv.Atoi(pod.Metadata.Annotations[prometheusPortAnnotation]); err == nil {
+		for _, container := range pod.Spec.Containers {
+			for _, p := range container.Ports {
+				if p.ContainerPort == port {
+					name = container.Name
+				}
+			}
+		}
+	}
+	for _, container := range pod.Status.Containers {
+		if name == "" || container.Name == name {
+			return container.ID
+		}
+	}
+	return ""
+}
+
+func init() {
+	RegisterProvider("prometheus_pods", NewPrometheusPodsConfigProvider)
+}
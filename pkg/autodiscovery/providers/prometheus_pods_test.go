@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+func TestPrometheusParsePods(t *testing.T) {
+	newPod := func(annotations map[string]string) *kubelet.Pod {
+		return &kubelet.Pod{
+			Metadata: kubelet.PodMetadata{Name: "app", Namespace: "default", Annotations: annotations},
+			Spec: kubelet.Spec{
+				Containers: []kubelet.ContainerSpec{
+					{Name: "app", Ports: []kubelet.ContainerPortSpec{{ContainerPort: 8080}}},
+					{Name: "exporter", Ports: []kubelet.ContainerPortSpec{{ContainerPort: 9102}}},
+				},
+			},
+			Status: kubelet.Status{
+				Containers: []kubelet.ContainerStatus{
+					{Name: "app", ID: "docker://app"},
+					{Name: "exporter", ID: "docker://exporter"},
+				},
+			},
+		}
+	}
+	p := &PrometheusPodsConfigProvider{scrape: newPrometheusScrapeConfig()}
+
+	configs := p.parsePods([]*kubelet.Pod{
+		newPod(nil),
+		newPod(map[string]string{"prometheus.io/scrape": "true"}),
+		newPod(map[string]string{"prometheus.io/scrape": "true", "prometheus.io/port": "9102"}),
+	})
+	require.Len(t, configs, 2)
+	assert.Equal(t, []string{"docker://app"}, configs[0].ADIdentifiers)
+	assert.Contains(t, string(configs[0].Instances[0]), "http://%%host%%:%%port%%/metrics")
+	assert.Equal(t, []string{"docker://exporter"}, configs[1].ADIdentifiers)
+	assert.Contains(t, string(configs[1].Instances[0]), "http://%%host%%:9102/metrics")
+
+	// containers not created yet
+	pod := newPod(map[string]string{"prometheus.io/scrape": "true"})
+	pod.Status.Containers = nil
+	assert.Len(t, p.parsePods([]*kubelet.Pod{pod}), 0)
+}
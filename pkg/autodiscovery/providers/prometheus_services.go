@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// PrometheusServicesConfigProvider implements the ConfigProvider interface
+// for the kube services having the prometheus.io/scrape annotation: an
+// openmetrics cluster check scrapes the endpoint of their prometheus.io/*
+// annotations on their cluster IP, reported by the kube_services listener.
+type PrometheusServicesConfigProvider struct {
+	apiClient *apiserver.APIClient
+	scrape    prometheusScrapeConfig
+}
+
+// NewPrometheusServicesConfigProvider returns a new ConfigProvider scraping the annotated services.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewPrometheusServicesConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	return &PrometheusServicesConfigProvider{
+		scrape: newPrometheusScrapeConfig(),
+	}, nil
+}
+
+// String returns a string representation of the PrometheusServicesConfigProvider
+func (p *PrometheusServicesConfigProvider) String() string {
+	return "Prometheus services"
+}
+
+// IsUpToDate always lists the services, as their annotations can change at any time.
+func (p *PrometheusServicesConfigProvider) IsUpToDate() (bool, error) {
+	return false, nil
+}
+
+// Collect lists the services and returns the cluster check templates of the annotated ones
+func (p *PrometheusServicesConfigProvider) Collect() ([]integration.Config, error) {
+	var err error
+	if p.apiClient == nil {
+		p.apiClient, err = apiserver.GetAPIClient()
+		if err != nil {
+			return []integration.Config{}, err
+		}
+	}
+
+	services, err := p.apiClient.Client().CoreV1().Services(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return []integration.Config{}, err
+	}
+	return p.parseServices(services.Items), nil
+}
+
+// parseServices returns a cluster check template per annotated service
+func (p *PrometheusServicesConfigProvider) parseServices(services []v1.Service) []integration.Config {
+	configs := []integration.Config{}
+	for _, svc := range services {
+		if !p.scrape.isScraped(svc.Namespace, svc.Annotations) {
+			continue
+		}
+		cfg, err := p.scrape.buildConfig(fmt.Sprintf(kubeServiceIDFormat, svc.Namespace, svc.Name), svc.Annotations)
+		if err != nil {
+			log.Errorf("Can't build the openmetrics template of the service %s/%s: %s", svc.Namespace, svc.Name, err)
+			continue
+		}
+		cfg.ClusterCheck = true
+		configs = append(configs, cfg)
+	}
+	return configs
+}
+
+func init() {
+	RegisterProvider("prometheus_services", NewPrometheusServicesConfigProvider)
+}
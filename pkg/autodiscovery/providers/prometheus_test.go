@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestPrometheusIsScraped(t *testing.T) {
+	scraped := map[string]string{"prometheus.io/scrape": "true"}
+
+	cfg := newPrometheusScrapeConfig()
+	assert.True(t, cfg.isScraped("default", scraped))
+	assert.False(t, cfg.isScraped("default", map[string]string{"prometheus.io/scrape": "false"}))
+	assert.False(t, cfg.isScraped("default", nil))
+
+	config.Datadog.Set("prometheus_scrape.namespaces", []string{"monitoring"})
+	defer config.Datadog.Set("prometheus_scrape.namespaces", []string{})
+	cfg = newPrometheusScrapeConfig()
+	assert.True(t, cfg.isScraped("monitoring", scraped))
+	assert.False(t, cfg.isScraped("default", scraped))
+}
+
+func TestPrometheusBuildConfig(t *testing.T) {
+	cfg := newPrometheusScrapeConfig()
+
+	tpl, err := cfg.buildConfig("docker://abcdef", map[string]string{"prometheus.io/scrape": "true"})
+	require.NoError(t, err)
+	assert.Equal(t, "openmetrics", tpl.Name)
+	assert.Equal(t, []string{"docker://abcdef"}, tpl.ADIdentifiers)
+	require.Len(t, tpl.Instances, 1)
+	assert.Equal(t, integration.Data("prometheus_url: http://%%host%%:%%port%%/metrics\nnamespace: \"\"\nmetrics:\n- '*'\n"), tpl.Instances[0])
+
+	config.Datadog.Set("prometheus_scrape.metrics", []string{"http_requests_total", "process_*"})
+	defer config.Datadog.Set("prometheus_scrape.metrics", []string{"*"})
+	cfg = newPrometheusScrapeConfig()
+	tpl, err = cfg.buildConfig("kube_service://default/exporter", map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/scheme": "https",
+		"prometheus.io/port":   "9102",
+		"prometheus.io/path":   "/prometheus",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, integration.Data("prometheus_url: https://%%host%%:9102/prometheus\nnamespace: \"\"\nmetrics:\n- http_requests_total\n- process_*\n"), tpl.Instances[0])
+}
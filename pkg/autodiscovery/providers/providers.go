@@ -6,6 +6,8 @@
 package providers
 
 import (
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
@@ -13,11 +15,30 @@ import (
 // ProviderCatalog keeps track of config providers by name
 var ProviderCatalog = make(map[string]ConfigProviderFactory)
 
+// providerPollIntervals keeps track of the poll intervals of the providers
+// slower to query than the ad_config_poll_interval, by name
+var providerPollIntervals = map[string]time.Duration{
+	"clusterchecks":     30 * time.Second,
+	"cluster_templates": 30 * time.Second,
+}
+
 // RegisterProvider adds a loader to the providers catalog
 func RegisterProvider(name string, factory ConfigProviderFactory) {
 	ProviderCatalog[name] = factory
 }
 
+// GetPollInterval returns the interval a provider should be polled at: its
+// poll_interval if set, else the interval of its kind, else ad_config_poll_interval
+func GetPollInterval(cfg config.ConfigurationProviders) time.Duration {
+	if cfg.PollInterval > 0 {
+		return time.Duration(cfg.PollInterval) * time.Second
+	}
+	if interval, found := providerPollIntervals[cfg.Name]; found {
+		return interval
+	}
+	return time.Duration(config.Datadog.GetInt("ad_config_poll_interval")) * time.Second
+}
+
 // ConfigProviderFactory is any function capable to create a ConfigProvider instance
 type ConfigProviderFactory func(cfg config.ConfigurationProviders) (ConfigProvider, error)
 
@@ -49,3 +70,15 @@ type ConfigProvider interface {
 	String() string
 	IsUpToDate() (bool, error)
 }
+
+// WatchingConfigProvider is a ConfigProvider watching its store for the
+// changes of the templates, instead of querying it at every poll.
+//
+// Watch blocks until stop is closed, sending on changed when the templates
+// changed, so that they are collected right away. IsUpToDate returns false
+// until the next Collect after a change, and queries the store again while
+// the watch is failing.
+type WatchingConfigProvider interface {
+	ConfigProvider
+	Watch(changed chan<- struct{}, stop <-chan struct{})
+}
@@ -20,6 +20,7 @@ const (
 	instancePath   string = "instances"
 	checkNamePath  string = "check_names"
 	initConfigPath string = "init_configs"
+	logsPath       string = "logs"
 )
 
 func init() {
@@ -99,10 +100,23 @@ func buildTemplates(key string, checkNames []string, initConfigs, instances []in
 
 // extractTemplatesFromMap looks for autodiscovery configurations in a given map
 // (either docker labels or kubernetes annotations) and returns them if found.
+// The logs configuration, if any, is returned as a template of its own.
 func extractTemplatesFromMap(key string, input map[string]string, prefix string) ([]integration.Config, error) {
+	templates := []integration.Config{}
+
+	if value, found := input[prefix+logsPath]; found {
+		if _, err := parseJSONValue(value); err != nil {
+			return []integration.Config{}, fmt.Errorf("in %s: %s", logsPath, err)
+		}
+		templates = append(templates, integration.Config{
+			LogsConfig:    integration.Data(value),
+			ADIdentifiers: []string{key},
+		})
+	}
+
 	value, found := input[prefix+checkNamePath]
 	if !found {
-		return []integration.Config{}, nil
+		return templates, nil
 	}
 	checkNames, err := parseCheckNames(value)
 	if err != nil {
@@ -127,5 +141,5 @@ func extractTemplatesFromMap(key string, input map[string]string, prefix string)
 		return []integration.Config{}, fmt.Errorf("in %s: %s", instancePath, err)
 	}
 
-	return buildTemplates(key, checkNames, initConfigs, instances), nil
+	return append(templates, buildTemplates(key, checkNames, initConfigs, instances)...), nil
 }
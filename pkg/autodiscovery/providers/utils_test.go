@@ -202,6 +202,53 @@ func TestExtractTemplatesFromMap(t *testing.T) {
 			output:       []integration.Config{},
 			err:          errors.New("in instances: Failed to unmarshal JSON"),
 		},
+		{
+			// Logs config along a check template
+			source: map[string]string{
+				"prefix.check_names":  "[\"apache\"]",
+				"prefix.init_configs": "[{}]",
+				"prefix.instances":    "[{\"apache_status_url\":\"http://%%host%%/server-status?auto\"}]",
+				"prefix.logs":         "[{\"source\":\"apache\",\"service\":\"webapp\"}]",
+			},
+			adIdentifier: "id",
+			prefix:       "prefix.",
+			output: []integration.Config{
+				{
+					LogsConfig:    integration.Data("[{\"source\":\"apache\",\"service\":\"webapp\"}]"),
+					ADIdentifiers: []string{"id"},
+				},
+				{
+					Name:          "apache",
+					Instances:     []integration.Data{integration.Data("{\"apache_status_url\":\"http://%%host%%/server-status?auto\"}")},
+					InitConfig:    integration.Data("{}"),
+					ADIdentifiers: []string{"id"},
+				},
+			},
+		},
+		{
+			// Logs config only
+			source: map[string]string{
+				"prefix.logs": "[{\"source\":\"apache\"}]",
+			},
+			adIdentifier: "id",
+			prefix:       "prefix.",
+			output: []integration.Config{
+				{
+					LogsConfig:    integration.Data("[{\"source\":\"apache\"}]"),
+					ADIdentifiers: []string{"id"},
+				},
+			},
+		},
+		{
+			// Invalid logs json
+			source: map[string]string{
+				"prefix.logs": "{\"source\":\"apache\"",
+			},
+			adIdentifier: "id",
+			prefix:       "prefix.",
+			output:       []integration.Config{},
+			err:          errors.New("in logs: Failed to unmarshal JSON"),
+		},
 	} {
 		t.Run(fmt.Sprintf("case %d: %s", nb, tc.source), func(t *testing.T) {
 			assert := assert.New(t)
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// watchRetryInterval is the delay before watching again after an error
+var watchRetryInterval = 10 * time.Second
+
+// watchState holds the state of the watch of a WatchingConfigProvider
+type watchState struct {
+	watching int32 // whether the watch is running, atomic
+	outdated int32 // whether a change was seen since the last collection, atomic
+}
+
+// setWatching sets whether the watch is running, the provider is polled
+// while it is not
+func (w *watchState) setWatching(watching bool) {
+	var v int32
+	if watching {
+		v = 1
+	}
+	atomic.StoreInt32(&w.watching, v)
+}
+
+// isUpToDate returns whether no change was seen since the last collection,
+// and whether the watch is running, the result being meaningless otherwise
+func (w *watchState) isUpToDate() (upToDate bool, watching bool) {
+	return atomic.LoadInt32(&w.outdated) == 0, atomic.LoadInt32(&w.watching) == 1
+}
+
+// notify records a change and signals it on changed without blocking, a
+// signal already pending covering it
+func (w *watchState) notify(changed chan<- struct{}) {
+	atomic.StoreInt32(&w.outdated, 1)
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// collected is to be called before a collection, the changes seen during it
+// being collected again
+func (w *watchState) collected() {
+	atomic.StoreInt32(&w.outdated, 0)
+}
+
+// waitRetry waits for watchRetryInterval, it returns false if stop was closed
+func waitRetry(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return false
+	case <-time.After(watchRetryInterval):
+		return true
+	}
+}
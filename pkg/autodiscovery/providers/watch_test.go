@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchState(t *testing.T) {
+	var w watchState
+	changed := make(chan struct{}, 1)
+
+	upToDate, watching := w.isUpToDate()
+	assert.True(t, upToDate)
+	assert.False(t, watching)
+
+	w.setWatching(true)
+	w.notify(changed)
+	// the second notification doesn't block, the first one is pending
+	w.notify(changed)
+	upToDate, watching = w.isUpToDate()
+	assert.False(t, upToDate)
+	assert.True(t, watching)
+	assert.Len(t, changed, 1)
+
+	w.collected()
+	upToDate, _ = w.isUpToDate()
+	assert.True(t, upToDate)
+
+	w.setWatching(false)
+	_, watching = w.isUpToDate()
+	assert.False(t, watching)
+}
+
+func TestWaitRetry(t *testing.T) {
+	defer func(interval time.Duration) { watchRetryInterval = interval }(watchRetryInterval)
+	watchRetryInterval = time.Millisecond
+	assert.True(t, waitRetry(make(chan struct{})))
+
+	watchRetryInterval = time.Hour
+	stop := make(chan struct{})
+	close(stop)
+	assert.False(t, waitRetry(stop))
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"math"
 	"path"
+	"reflect"
 	"strings"
 	"time"
 
@@ -26,14 +27,18 @@ const sessionTimeout = 1 * time.Second
 type zkBackend interface {
 	Get(key string) ([]byte, *zk.Stat, error)
 	Children(key string) ([]string, *zk.Stat, error)
+	GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+	ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error)
 }
 
-// ZookeeperConfigProvider implements the Config Provider interface It should
-// be called periodically and returns templates from Zookeeper for AutoConf.
+// ZookeeperConfigProvider implements the Config Provider interface It
+// watches the templates in Zookeeper for AutoConf, it is polled while the
+// watch fails.
 type ZookeeperConfigProvider struct {
 	client      zkBackend
 	templateDir string
 	cache       *ProviderCache
+	watch       watchState
 }
 
 // NewZookeeperConfigProvider returns a new Client connected to a Zookeeper backend.
@@ -60,6 +65,7 @@ func (z *ZookeeperConfigProvider) String() string {
 // Collect retrieves templates from Zookeeper, builds Config objects and returns them
 // TODO: cache templates and last-modified index to avoid future full crawl if no template changed.
 func (z *ZookeeperConfigProvider) Collect() ([]integration.Config, error) {
+	z.watch.collected()
 	configs := make([]integration.Config, 0)
 	identifiers, err := z.getIdentifiers(z.templateDir)
 	if err != nil {
@@ -74,6 +80,9 @@ func (z *ZookeeperConfigProvider) Collect() ([]integration.Config, error) {
 
 // IsUpToDate updates the list of AD templates versions in the Agent's cache and checks the list is up to date compared to Zookeeper's data.
 func (z *ZookeeperConfigProvider) IsUpToDate() (bool, error) {
+	if upToDate, watching := z.watch.isUpToDate(); watching {
+		return upToDate, nil
+	}
 
 	identifiers, err := z.getIdentifiers(z.templateDir)
 	if err != nil {
@@ -116,6 +125,106 @@ func (z *ZookeeperConfigProvider) IsUpToDate() (bool, error) {
 	return true, nil
 }
 
+// zkWatchKey identifies a watch, on the children or on the data of a node
+type zkWatchKey struct {
+	path     string
+	children bool
+}
+
+// Watch watches the changes of the templates with Zookeeper watches. These
+// only fire once: the watch that fired is set again, along with the watches of
+// the nodes created meanwhile, the other ones staying armed. It returns when
+// stop is closed.
+func (z *ZookeeperConfigProvider) Watch(changed chan<- struct{}, stop <-chan struct{}) {
+	defer z.watch.setWatching(false)
+
+	watches := make(map[zkWatchKey]<-chan zk.Event)
+	for {
+		if err := z.setWatches(watches); err != nil {
+			log.Warnf("Can't watch the templates in zookeeper, polling them: %s", err)
+			z.watch.setWatching(false)
+			if !waitRetry(stop) {
+				return
+			}
+			continue
+		}
+		// the changes since the last watch are unknown
+		z.watch.notify(changed)
+		z.watch.setWatching(true)
+
+		keys := make([]zkWatchKey, 0, len(watches))
+		cases := make([]reflect.SelectCase, 0, len(watches)+1)
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(stop)})
+		for key, ch := range watches {
+			keys = append(keys, key)
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+		}
+		chosen, value, _ := reflect.Select(cases)
+		if chosen == 0 {
+			return
+		}
+		// the watch fired, it has to be set again
+		delete(watches, keys[chosen-1])
+		if event, ok := value.Interface().(zk.Event); ok {
+			log.Debugf("The templates in zookeeper changed: %s on %s", event.Type, event.Path)
+		}
+	}
+}
+
+// setWatches watches the children of the template dir and of its folders,
+// and the template keys of the identifiers. Only the watches missing from
+// watches are set, the nodes already watched are listed without a watch.
+func (z *ZookeeperConfigProvider) setWatches(watches map[zkWatchKey]<-chan zk.Event) error {
+	children, err := z.watchChildren(watches, z.templateDir)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		nodePath := path.Join(z.templateDir, child)
+		nodes, err := z.watchChildren(watches, nodePath)
+		if err != nil {
+			return err
+		}
+
+		for _, node := range nodes {
+			if node != instancePath && node != checkNamePath && node != initConfigPath {
+				continue
+			}
+			keyPath := path.Join(nodePath, node)
+			key := zkWatchKey{path: keyPath}
+			if _, found := watches[key]; found {
+				continue
+			}
+			_, _, event, err := z.client.GetW(keyPath)
+			if err != nil {
+				return fmt.Errorf("couldn't watch '%s': %s", keyPath, err)
+			}
+			watches[key] = event
+		}
+	}
+	return nil
+}
+
+// watchChildren returns the children of a node, setting the watch on them if
+// it's missing from watches
+func (z *ZookeeperConfigProvider) watchChildren(watches map[zkWatchKey]<-chan zk.Event, nodePath string) ([]string, error) {
+	key := zkWatchKey{path: nodePath, children: true}
+	if _, found := watches[key]; found {
+		children, _, err := z.client.Children(nodePath)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list '%s': %s", nodePath, err)
+		}
+		return children, nil
+	}
+	children, _, event, err := z.client.ChildrenW(nodePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't watch '%s': %s", nodePath, err)
+	}
+	watches[key] = event
+	return children, nil
+}
+
 // getIdentifiers gets folders at the root of the template dir
 // verifies they have the right content to be a valid template
 // and return their names.
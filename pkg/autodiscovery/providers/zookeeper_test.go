@@ -51,6 +51,20 @@ func (m *zkTest) Children(key string) ([]string, *zk.Stat, error) {
 	return nil, nil, args.Error(2)
 }
 
+func (m *zkTest) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	args := m.Called(key)
+	array, _ := args.Get(0).([]byte)
+	events, _ := args.Get(1).(<-chan zk.Event)
+	return array, nil, events, args.Error(2)
+}
+
+func (m *zkTest) ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	args := m.Called(key)
+	array, _ := args.Get(0).([]string)
+	events, _ := args.Get(1).(<-chan zk.Event)
+	return array, nil, events, args.Error(2)
+}
+
 //
 // Tests
 //
@@ -221,3 +235,51 @@ func TestZKIsUpToDate(t *testing.T) {
 	assert.True(t, update)
 	backend.AssertExpectations(t)
 }
+
+func TestZKWatch(t *testing.T) {
+	backend := &zkTest{}
+	dirEvents := make(chan zk.Event)
+	folderEvents := make(chan zk.Event)
+	keyEvents := make(chan zk.Event)
+	instancesEvents := make(chan zk.Event)
+	backend.On("ChildrenW", "/datadog/check_configs").Return([]string{"config_folder_1"}, (<-chan zk.Event)(dirEvents), nil)
+	backend.On("ChildrenW", "/datadog/check_configs/config_folder_1").Return([]string{checkNamePath, initConfigPath, instancePath, "other"}, (<-chan zk.Event)(folderEvents), nil)
+	backend.On("GetW", "/datadog/check_configs/config_folder_1/check_names").Return([]byte("[\"first_name\"]"), (<-chan zk.Event)(keyEvents), nil)
+	backend.On("GetW", "/datadog/check_configs/config_folder_1/init_configs").Return([]byte("[{}]"), (<-chan zk.Event)(keyEvents), nil)
+	backend.On("GetW", "/datadog/check_configs/config_folder_1/instances").Return([]byte("[{}]"), (<-chan zk.Event)(instancesEvents), nil)
+	backend.On("Children", "/datadog/check_configs").Return([]string{"config_folder_1"}, nil, nil)
+	backend.On("Children", "/datadog/check_configs/config_folder_1").Return([]string{checkNamePath, initConfigPath, instancePath, "other"}, nil, nil)
+	zkr := &ZookeeperConfigProvider{client: backend, templateDir: "/datadog/check_configs", cache: NewCPCache()}
+
+	changed := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		zkr.Watch(changed, stop)
+		close(done)
+	}()
+
+	// the initial state is to be collected
+	<-changed
+	zkr.watch.collected()
+
+	// a change is notified, the provider is outdated until the next collection
+	instancesEvents <- zk.Event{Type: zk.EventNodeDataChanged, Path: "/datadog/check_configs/config_folder_1/instances"}
+	<-changed
+	// only the watch that fired is set again
+	backend.AssertNumberOfCalls(t, "GetW", 4)
+	backend.AssertNumberOfCalls(t, "ChildrenW", 2)
+	upToDate, err := zkr.IsUpToDate()
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+	zkr.watch.collected()
+	upToDate, err = zkr.IsUpToDate()
+	assert.NoError(t, err)
+	assert.True(t, upToDate)
+
+	close(stop)
+	<-done
+	_, watching := zkr.watch.isUpToDate()
+	assert.False(t, watching)
+	backend.AssertNotCalled(t, "GetW", "/datadog/check_configs/config_folder_1/other")
+}
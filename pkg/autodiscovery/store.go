@@ -17,6 +17,7 @@ type store struct {
 	serviceToConfigs  map[listeners.ID][]integration.Config
 	serviceToTagsHash map[listeners.ID]string
 	loadedConfigs     map[string]integration.Config
+	resolutionTraces  map[string]integration.ResolutionTrace
 	nameToJMXMetrics  map[string]integration.Data
 	m                 sync.RWMutex
 }
@@ -27,6 +28,7 @@ func newStore() *store {
 		serviceToConfigs:  make(map[listeners.ID][]integration.Config),
 		serviceToTagsHash: make(map[listeners.ID]string),
 		loadedConfigs:     make(map[string]integration.Config),
+		resolutionTraces:  make(map[string]integration.ResolutionTrace),
 		nameToJMXMetrics:  make(map[string]integration.Data),
 	}
 
@@ -92,6 +94,25 @@ func (s *store) removeLoadedConfig(config integration.Config) {
 	s.m.Lock()
 	defer s.m.Unlock()
 	delete(s.loadedConfigs, config.Digest())
+	delete(s.resolutionTraces, config.Digest())
+}
+
+// setResolutionTrace stores how a resolved config was resolved, by its digest
+func (s *store) setResolutionTrace(config integration.Config, trace integration.ResolutionTrace) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.resolutionTraces[config.Digest()] = trace
+}
+
+// getResolutionTraces returns a copy of the traces of the resolved configs, by digest
+func (s *store) getResolutionTraces() map[string]integration.ResolutionTrace {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	traces := make(map[string]integration.ResolutionTrace, len(s.resolutionTraces))
+	for digest, trace := range s.resolutionTraces {
+		traces[digest] = trace
+	}
+	return traces
 }
 
 // getLoadedConfigs returns all loaded and resolved configs
@@ -7,6 +7,8 @@ package autodiscovery
 
 import (
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -18,6 +20,7 @@ type TemplateCache struct {
 	adIDToDigests    map[string][]string           // map an AD identifier to all the configs that have it
 	digestToADId     map[string][]string           // map a config digest to the list of AD identifiers it has
 	digestToTemplate map[string]integration.Config // map a digest to the corresponding config object
+	patterns         map[string]*adPattern         // map the AD identifiers written as patterns to their matcher
 	m                sync.RWMutex
 }
 
@@ -27,9 +30,57 @@ func NewTemplateCache() *TemplateCache {
 		adIDToDigests:    map[string][]string{},
 		digestToADId:     map[string][]string{},
 		digestToTemplate: map[string]integration.Config{},
+		patterns:         map[string]*adPattern{},
 	}
 }
 
+// adPatternChars are the characters making an AD identifier a glob or
+// a regular expression rather than an image name
+const adPatternChars = "*?[]()|+^${}\\"
+
+// adPattern matches the image names of the services against an AD identifier
+// written as a glob, e.g. `myregistry/*-sidecar`, or as a regular expression,
+// e.g. `myregistry/.*-sidecar`. An image matching either form matches.
+type adPattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// isADPattern returns whether the AD identifier is a glob or a regular expression
+func isADPattern(adID string) bool {
+	return strings.ContainsAny(adID, adPatternChars)
+}
+
+// newADPattern returns the matcher of the AD identifier, an error if it is
+// neither a valid glob nor a valid regular expression
+func newADPattern(adID string) (*adPattern, error) {
+	p := &adPattern{}
+	if _, err := path.Match(adID, ""); err == nil {
+		p.glob = adID
+	}
+	if re, err := regexp.Compile("^(?:" + adID + ")$"); err == nil {
+		p.re = re
+	}
+	if p.glob == "" && p.re == nil {
+		return nil, fmt.Errorf("invalid AD identifier %q: not a valid glob nor regular expression", adID)
+	}
+	return p, nil
+}
+
+// match returns whether the AD identifier of a service matches the pattern,
+// only image names are matched, not entity identifiers such as docker://<id>
+func (p *adPattern) match(adID string) bool {
+	if strings.Contains(adID, "://") {
+		return false
+	}
+	if p.glob != "" {
+		if matched, _ := path.Match(p.glob, adID); matched {
+			return true
+		}
+	}
+	return p.re != nil && p.re.MatchString(adID)
+}
+
 // Set stores or updates a template in the cache
 func (cache *TemplateCache) Set(tpl integration.Config) error {
 	// return an error if configuration has no AD identifiers
@@ -48,6 +99,25 @@ func (cache *TemplateCache) Set(tpl integration.Config) error {
 		return nil
 	}
 
+	// compile the patterns first, not to store a template partially
+	patterns := map[string]*adPattern{}
+	for _, id := range tpl.ADIdentifiers {
+		if !isADPattern(id) {
+			continue
+		}
+		if _, found := cache.patterns[id]; found {
+			continue
+		}
+		p, err := newADPattern(id)
+		if err != nil {
+			return err
+		}
+		patterns[id] = p
+	}
+	for id, p := range patterns {
+		cache.patterns[id] = p
+	}
+
 	// store the template
 	cache.digestToTemplate[d] = tpl
 	cache.digestToADId[d] = tpl.ADIdentifiers
@@ -63,16 +133,30 @@ func (cache *TemplateCache) Get(adID string) ([]integration.Config, error) {
 	cache.m.RLock()
 	defer cache.m.RUnlock()
 
-	// do we know the identifier?
-	if digests, found := cache.adIDToDigests[adID]; found {
-		templates := []integration.Config{}
-		for _, digest := range digests {
-			templates = append(templates, cache.digestToTemplate[digest])
+	// do we know the identifier, or does it match any pattern?
+	_, found := cache.adIDToDigests[adID]
+	digests := append([]string{}, cache.adIDToDigests[adID]...)
+	for id, p := range cache.patterns {
+		if id != adID && p.match(adID) {
+			digests = append(digests, cache.adIDToDigests[id]...)
+			found = true
 		}
-		return templates, nil
+	}
+	if !found {
+		return nil, fmt.Errorf("AD id %s not found in cache", adID)
 	}
 
-	return nil, fmt.Errorf("AD id %s not found in cache", adID)
+	// a template can match through several of its identifiers
+	templates := []integration.Config{}
+	seen := make(map[string]bool, len(digests))
+	for _, digest := range digests {
+		if seen[digest] {
+			continue
+		}
+		seen[digest] = true
+		templates = append(templates, cache.digestToTemplate[digest])
+	}
+	return templates, nil
 }
 
 // GetUnresolvedTemplates returns templates yet to be resolved
@@ -111,6 +195,9 @@ func (cache *TemplateCache) Del(tpl integration.Config) error {
 				break
 			}
 		}
+		if len(cache.adIDToDigests[id]) == 0 {
+			delete(cache.patterns, id)
+		}
 	}
 
 	return nil
@@ -89,6 +89,44 @@ func TestGet(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestGetPattern(t *testing.T) {
+	cache := NewTemplateCache()
+	regexTpl := integration.Config{Name: "regex", ADIdentifiers: []string{"myregistry/.*-sidecar"}}
+	globTpl := integration.Config{Name: "glob", ADIdentifiers: []string{"myregistry/*-sidecar", "redis"}}
+	require.Nil(t, cache.Set(regexTpl))
+	require.Nil(t, cache.Set(globTpl))
+
+	ret, err := cache.Get("myregistry/envoy-sidecar")
+	require.Nil(t, err)
+	assert.Len(t, ret, 2)
+
+	ret, err = cache.Get("redis")
+	require.Nil(t, err)
+	require.Len(t, ret, 1)
+	assert.Equal(t, "glob", ret[0].Name)
+
+	// the glob doesn't match across the path separator, the regex does
+	ret, err = cache.Get("myregistry/team/envoy-sidecar")
+	require.Nil(t, err)
+	require.Len(t, ret, 1)
+	assert.Equal(t, "regex", ret[0].Name)
+
+	// entity identifiers are not matched
+	require.Nil(t, cache.Set(integration.Config{Name: "all", ADIdentifiers: []string{".*"}}))
+	_, err = cache.Get("docker://a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9")
+	assert.NotNil(t, err)
+
+	// the patterns are removed with their last template
+	require.Nil(t, cache.Del(regexTpl))
+	assert.NotContains(t, cache.patterns, "myregistry/.*-sidecar")
+	assert.Contains(t, cache.patterns, "myregistry/*-sidecar")
+
+	// neither a glob nor a regex
+	err = cache.Set(integration.Config{ADIdentifiers: []string{"myregistry/[envoy"}})
+	assert.NotNil(t, err)
+	assert.Len(t, cache.digestToTemplate, 2)
+}
+
 func TestGetUnresolvedTemplates(t *testing.T) {
 	cache := NewTemplateCache()
 	tpl := integration.Config{ADIdentifiers: []string{"foo", "bar"}}
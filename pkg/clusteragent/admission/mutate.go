@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// EnabledLabelKey is the pod label to opt in (or out, with "false") of the injection of the agent configuration
+	EnabledLabelKey = "admission.datadoghq.com/enabled"
+
+	envLabelKey     = "tags.datadoghq.com/env"
+	serviceLabelKey = "tags.datadoghq.com/service"
+	versionLabelKey = "tags.datadoghq.com/version"
+
+	agentHostEnvVar       = "DD_AGENT_HOST"
+	dogstatsdSocketEnvVar = "DD_DOGSTATSD_SOCKET"
+	socketVolumeName      = "datadog-dsdsocket"
+
+	hostIPMode = "hostip"
+	socketMode = "socket"
+)
+
+// tagLabelsToEnvVars maps the unified service tagging labels to the environment variables read by the tracers.
+var tagLabelsToEnvVars = []struct {
+	label  string
+	envVar string
+}{
+	{envLabelKey, "DD_ENV"},
+	{serviceLabelKey, "DD_SERVICE"},
+	{versionLabelKey, "DD_VERSION"},
+}
+
+// patchOperation is a JSON patch operation, see https://tools.ietf.org/html/rfc6902
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Mutate handles an admission review of a pod creation, and returns the
+// response carrying the JSON patch injecting the environment variables.
+// The pod is always allowed, mutation errors are only logged.
+func Mutate(request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	response := &admissionv1beta1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+
+	var pod corev1.Pod
+	if err := json.Unmarshal(request.Object.Raw, &pod); err != nil {
+		log.Errorf("Could not decode the pod in the admission request %s: %s", request.UID, err)
+		response.Result = &metav1.Status{Message: err.Error()}
+		return response
+	}
+
+	patches := podPatches(&pod)
+	if len(patches) == 0 {
+		return response
+	}
+	patch, err := json.Marshal(patches)
+	if err != nil {
+		log.Errorf("Could not encode the patch of the pod %s/%s: %s", request.Namespace, podName(&pod), err)
+		return response
+	}
+	log.Debugf("Mutating the pod %s/%s: %s", request.Namespace, podName(&pod), string(patch))
+
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	response.Patch = patch
+	response.PatchType = &patchType
+	return response
+}
+
+// podPatches returns the JSON patch operations to apply to the pod.
+func podPatches(pod *corev1.Pod) []patchOperation {
+	var envVars []corev1.EnvVar
+	for _, t := range tagLabelsToEnvVars {
+		if value, found := pod.Labels[t.label]; found {
+			envVars = append(envVars, corev1.EnvVar{Name: t.envVar, Value: value})
+		}
+	}
+
+	var patches []patchOperation
+	if shouldInjectConfig(pod) {
+		switch mode := config.Datadog.GetString("admission_controller.inject_config.mode"); mode {
+		case hostIPMode:
+			envVars = append(envVars, corev1.EnvVar{
+				Name: agentHostEnvVar,
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.hostIP"},
+				},
+			})
+		case socketMode:
+			socketPath := config.Datadog.GetString("admission_controller.inject_config.socket_path")
+			envVars = append(envVars, corev1.EnvVar{Name: dogstatsdSocketEnvVar, Value: socketPath})
+			patches = append(patches, socketVolumePatches(pod, filepath.Dir(socketPath))...)
+		default:
+			log.Warnf("Invalid admission_controller.inject_config.mode %q, expected %q or %q", mode, hostIPMode, socketMode)
+		}
+	}
+	if len(envVars) == 0 {
+		return patches
+	}
+
+	for i, container := range pod.Spec.Containers {
+		patches = append(patches, envVarPatches(i, container, envVars)...)
+	}
+	return patches
+}
+
+// shouldInjectConfig returns whether the agent host or socket are to be injected in the pod.
+// Pods opt in with the admission.datadoghq.com/enabled label, unless
+// admission_controller.mutate_unlabelled is set, in which case they can opt out.
+func shouldInjectConfig(pod *corev1.Pod) bool {
+	if !config.Datadog.GetBool("admission_controller.inject_config.enabled") {
+		return false
+	}
+	switch pod.Labels[EnabledLabelKey] {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return config.Datadog.GetBool("admission_controller.mutate_unlabelled")
+	}
+}
+
+// envVarPatches adds the environment variables not already defined in the container.
+func envVarPatches(index int, container corev1.Container, envVars []corev1.EnvVar) []patchOperation {
+	var patches []patchOperation
+	basePath := fmt.Sprintf("/spec/containers/%d/env", index)
+	hasEnv := len(container.Env) > 0
+	for _, envVar := range envVars {
+		if containsEnvVar(container.Env, envVar.Name) {
+			continue
+		}
+		if !hasEnv {
+			patches = append(patches, patchOperation{Op: "add", Path: basePath, Value: []corev1.EnvVar{envVar}})
+			hasEnv = true
+			continue
+		}
+		patches = append(patches, patchOperation{Op: "add", Path: basePath + "/-", Value: envVar})
+	}
+	return patches
+}
+
+// socketVolumePatches mounts the directory of the dogstatsd socket in the containers of the pod.
+func socketVolumePatches(pod *corev1.Pod, socketDir string) []patchOperation {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == socketVolumeName {
+			return nil
+		}
+	}
+
+	var patches []patchOperation
+	volume := corev1.Volume{
+		Name: socketVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{Path: socketDir},
+		},
+	}
+	if len(pod.Spec.Volumes) == 0 {
+		patches = append(patches, patchOperation{Op: "add", Path: "/spec/volumes", Value: []corev1.Volume{volume}})
+	} else {
+		patches = append(patches, patchOperation{Op: "add", Path: "/spec/volumes/-", Value: volume})
+	}
+
+	mount := corev1.VolumeMount{Name: socketVolumeName, MountPath: socketDir, ReadOnly: true}
+	for i, container := range pod.Spec.Containers {
+		basePath := fmt.Sprintf("/spec/containers/%d/volumeMounts", i)
+		if len(container.VolumeMounts) == 0 {
+			patches = append(patches, patchOperation{Op: "add", Path: basePath, Value: []corev1.VolumeMount{mount}})
+		} else {
+			patches = append(patches, patchOperation{Op: "add", Path: basePath + "/-", Value: mount})
+		}
+	}
+	return patches
+}
+
+func containsEnvVar(envVars []corev1.EnvVar, name string) bool {
+	for _, envVar := range envVars {
+		if envVar.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// podName returns the name of the pod, or its generate name as
+// the name is not set yet when it is created by a controller.
+func podName(pod *corev1.Pod) string {
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return pod.GenerateName
+}
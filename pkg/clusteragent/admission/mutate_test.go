@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func newPod(labels map[string]string, containers ...corev1.Container) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: labels},
+		Spec:       corev1.PodSpec{Containers: containers},
+	}
+}
+
+func TestPodPatchesTags(t *testing.T) {
+	pod := newPod(
+		map[string]string{envLabelKey: "prod", serviceLabelKey: "web"},
+		corev1.Container{Name: "noenv"},
+		corev1.Container{Name: "withenv", Env: []corev1.EnvVar{{Name: "DD_ENV", Value: "staging"}}},
+	)
+
+	patches := podPatches(pod)
+
+	assert.Equal(t, []patchOperation{
+		{Op: "add", Path: "/spec/containers/0/env", Value: []corev1.EnvVar{{Name: "DD_ENV", Value: "prod"}}},
+		{Op: "add", Path: "/spec/containers/0/env/-", Value: corev1.EnvVar{Name: "DD_SERVICE", Value: "web"}},
+		// DD_ENV is already defined in the second container
+		{Op: "add", Path: "/spec/containers/1/env/-", Value: corev1.EnvVar{Name: "DD_SERVICE", Value: "web"}},
+	}, patches)
+}
+
+func TestPodPatchesNoLabels(t *testing.T) {
+	assert.Len(t, podPatches(newPod(nil, corev1.Container{Name: "foo"})), 0)
+}
+
+func TestPodPatchesInjectConfig(t *testing.T) {
+	config.Datadog.Set("admission_controller.inject_config.enabled", true)
+	defer config.Datadog.Set("admission_controller.inject_config.enabled", true)
+	defer config.Datadog.Set("admission_controller.inject_config.mode", "hostip")
+
+	// Not labelled
+	assert.Len(t, podPatches(newPod(nil, corev1.Container{Name: "foo"})), 0)
+
+	// Host IP
+	config.Datadog.Set("admission_controller.inject_config.mode", "hostip")
+	patches := podPatches(newPod(map[string]string{EnabledLabelKey: "true"}, corev1.Container{Name: "foo"}))
+	require.Len(t, patches, 1)
+	envVars := patches[0].Value.([]corev1.EnvVar)
+	require.Len(t, envVars, 1)
+	assert.Equal(t, agentHostEnvVar, envVars[0].Name)
+	assert.Equal(t, "status.hostIP", envVars[0].ValueFrom.FieldRef.FieldPath)
+
+	// Socket
+	config.Datadog.Set("admission_controller.inject_config.mode", "socket")
+	config.Datadog.Set("admission_controller.inject_config.socket_path", "/var/run/datadog/dsd.socket")
+	patches = podPatches(newPod(map[string]string{EnabledLabelKey: "true"}, corev1.Container{Name: "foo"}))
+	require.Len(t, patches, 3)
+	assert.Equal(t, "/spec/volumes", patches[0].Path)
+	assert.Equal(t, "/var/run/datadog", patches[0].Value.([]corev1.Volume)[0].HostPath.Path)
+	assert.Equal(t, "/spec/containers/0/volumeMounts", patches[1].Path)
+	assert.Equal(t, []corev1.EnvVar{{Name: dogstatsdSocketEnvVar, Value: "/var/run/datadog/dsd.socket"}}, patches[2].Value)
+
+	// Opted out
+	config.Datadog.Set("admission_controller.mutate_unlabelled", true)
+	defer config.Datadog.Set("admission_controller.mutate_unlabelled", false)
+	assert.Len(t, podPatches(newPod(map[string]string{EnabledLabelKey: "false"}, corev1.Container{Name: "foo"})), 0)
+}
+
+func TestMutate(t *testing.T) {
+	raw, err := json.Marshal(newPod(map[string]string{versionLabelKey: "1.2"}, corev1.Container{Name: "foo"}))
+	require.NoError(t, err)
+
+	response := Mutate(&admissionv1beta1.AdmissionRequest{
+		UID:    "uid",
+		Object: runtime.RawExtension{Raw: raw},
+	})
+
+	assert.True(t, response.Allowed)
+	assert.EqualValues(t, "uid", response.UID)
+	require.NotNil(t, response.PatchType)
+	assert.Equal(t, admissionv1beta1.PatchTypeJSONPatch, *response.PatchType)
+	assert.JSONEq(t, `[{"op":"add","path":"/spec/containers/0/env","value":[{"name":"DD_VERSION","value":"1.2"}]}]`, string(response.Patch))
+}
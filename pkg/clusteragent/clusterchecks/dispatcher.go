@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clusterchecks
+
+import (
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const defaultNodeExpirationSeconds = 30
+
+// Dispatcher holds the management logic for cluster-checks.
+// It implements the autodiscovery scheduler interface to receive the
+// configurations flagged as cluster_check, and dispatches them to the
+// node agents polling the cluster agent API.
+type Dispatcher struct {
+	store                 *clusterStore
+	nodeExpirationSeconds int64
+	stop                  chan struct{}
+}
+
+var globalDispatcher *Dispatcher
+
+// NewDispatcher returns a Dispatcher, call Run to start the expiration of the nodes.
+func NewDispatcher() *Dispatcher {
+	expiration := config.Datadog.GetInt64("cluster_checks.node_expiration_timeout")
+	if expiration <= 0 {
+		log.Warnf("Invalid cluster_checks.node_expiration_timeout %d, using %d", expiration, defaultNodeExpirationSeconds)
+		expiration = defaultNodeExpirationSeconds
+	}
+	return &Dispatcher{
+		store:                 newClusterStore(),
+		nodeExpirationSeconds: expiration,
+		stop:                  make(chan struct{}),
+	}
+}
+
+// SetupDispatcher creates the global dispatcher, to be registered as an autodiscovery scheduler.
+func SetupDispatcher() *Dispatcher {
+	globalDispatcher = NewDispatcher()
+	return globalDispatcher
+}
+
+// GetDispatcher returns the global dispatcher, nil if the cluster checks are not enabled.
+func GetDispatcher() *Dispatcher {
+	return globalDispatcher
+}
+
+// Schedule implements the scheduler.Scheduler interface
+func (d *Dispatcher) Schedule(configs []integration.Config) {
+	d.store.Lock()
+	defer d.store.Unlock()
+
+	for _, c := range configs {
+		if !c.ClusterCheck {
+			continue // Ignore non cluster-check configs
+		}
+		digest := c.Digest()
+		if _, found := d.store.digestToConfig[digest]; found {
+			continue
+		}
+		// The node agents schedule the config as a regular check
+		patched := c
+		patched.ClusterCheck = false
+		d.store.digestToConfig[digest] = patched
+		d.store.danglingConfigs[digest] = patched
+	}
+	d.dispatchDangling()
+}
+
+// Unschedule implements the scheduler.Scheduler interface
+func (d *Dispatcher) Unschedule(configs []integration.Config) {
+	d.store.Lock()
+	defer d.store.Unlock()
+
+	for _, c := range configs {
+		if !c.ClusterCheck {
+			continue // Ignore non cluster-check configs
+		}
+		digest := c.Digest()
+		delete(d.store.digestToConfig, digest)
+		delete(d.store.danglingConfigs, digest)
+		if nodeName, found := d.store.digestToNode[digest]; found {
+			if node, found := d.store.nodes[nodeName]; found {
+				node.removeConfig(digest)
+			}
+			delete(d.store.digestToNode, digest)
+		}
+	}
+}
+
+// Stop implements the scheduler.Scheduler interface
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+}
+
+// Run expires the nodes that stopped polling and re-dispatches their configs, until Stop is called.
+func (d *Dispatcher) Run() {
+	ticker := time.NewTicker(time.Duration(d.nodeExpirationSeconds) * time.Second / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.expireNodes()
+		}
+	}
+}
+
+// GetNodeConfigs returns the configurations dispatched to a node, and records that the node is alive.
+// A node polling for the first time is added to the pool and the configurations are rebalanced.
+func (d *Dispatcher) GetNodeConfigs(nodeName string) ConfigResponse {
+	d.store.Lock()
+	defer d.store.Unlock()
+
+	node, created := d.store.getOrCreateNodeStore(nodeName)
+	node.lastPing = time.Now()
+	if created {
+		log.Infof("Node %s joined the cluster-check dispatching", nodeName)
+		d.dispatchDangling()
+		d.rebalance()
+	}
+	return ConfigResponse{
+		LastChange: node.lastConfigChange,
+		Configs:    node.configs(),
+	}
+}
+
+// GetState returns the state of the dispatching, for the status command.
+func (d *Dispatcher) GetState() StateResponse {
+	d.store.RLock()
+	defer d.store.RUnlock()
+
+	state := StateResponse{}
+	for _, node := range d.store.nodes {
+		state.Nodes = append(state.Nodes, StateNodeResponse{
+			Name:     node.name,
+			LastSeen: node.lastPing.Unix(),
+			Configs:  node.configs(),
+		})
+	}
+	sort.Slice(state.Nodes, func(i, j int) bool { return state.Nodes[i].Name < state.Nodes[j].Name })
+	for _, c := range d.store.danglingConfigs {
+		state.Dangling = append(state.Dangling, c)
+	}
+	return state
+}
+
+// GetStats returns the number of configs dispatched to each node, for the status page.
+func (d *Dispatcher) GetStats() StatsResponse {
+	d.store.RLock()
+	defer d.store.RUnlock()
+
+	stats := StatsResponse{
+		Nodes:       len(d.store.nodes),
+		Configs:     len(d.store.digestToConfig),
+		Dangling:    len(d.store.danglingConfigs),
+		NodeConfigs: make(map[string]int, len(d.store.nodes)),
+	}
+	for _, node := range d.store.nodes {
+		stats.NodeConfigs[node.name] = len(node.digestToConfig)
+	}
+	for _, c := range d.store.digestToConfig {
+		if c.NodeName != "" {
+			stats.EndpointChecks++
+		}
+	}
+	return stats
+}
+
+// expireNodes removes the nodes that did not poll within the expiration timeout,
+// their configurations are dispatched to the remaining nodes.
+func (d *Dispatcher) expireNodes() {
+	d.store.Lock()
+	defer d.store.Unlock()
+
+	cutoff := time.Now().Add(-time.Duration(d.nodeExpirationSeconds) * time.Second)
+	for name, node := range d.store.nodes {
+		if node.lastPing.After(cutoff) {
+			continue
+		}
+		log.Infof("Node %s did not poll since %s, re-dispatching its %d configs", name, node.lastPing, len(node.digestToConfig))
+		for digest, c := range node.digestToConfig {
+			delete(d.store.digestToNode, digest)
+			d.store.danglingConfigs[digest] = c
+		}
+		delete(d.store.nodes, name)
+	}
+	d.dispatchDangling()
+}
+
+// dispatchDangling assigns the dangling configs to the least busy nodes.
+// The endpoint checks are assigned to the node running the endpoint, and
+// stay dangling until it polls.
+// The store lock must be held.
+func (d *Dispatcher) dispatchDangling() {
+	if len(d.store.nodes) == 0 {
+		return
+	}
+	for digest, c := range d.store.danglingConfigs {
+		var node *nodeStore
+		if c.NodeName != "" {
+			node = d.store.nodes[c.NodeName]
+			if node == nil {
+				log.Tracef("Node %s running the endpoint of config %s did not poll yet", c.NodeName, c.Name)
+				continue
+			}
+		} else {
+			node = d.leastBusyNode()
+		}
+		node.addConfig(c)
+		d.store.digestToNode[digest] = node.name
+		delete(d.store.danglingConfigs, digest)
+		log.Debugf("Dispatched config %s to node %s", c.Name, node.name)
+	}
+}
+
+// rebalance moves configs from the busiest to the least busy nodes
+// until their number of movable configs differs by at most one.
+// The endpoint checks are not moved, nor counted.
+// The store lock must be held.
+func (d *Dispatcher) rebalance() {
+	for {
+		busiest, leastBusy := d.busiestNode(), d.leastBusyNode()
+		if busiest == nil || busiest.movableConfigs()-leastBusy.movableConfigs() <= 1 {
+			return
+		}
+		for digest, c := range busiest.digestToConfig {
+			if c.NodeName != "" {
+				continue
+			}
+			busiest.removeConfig(digest)
+			leastBusy.addConfig(c)
+			d.store.digestToNode[digest] = leastBusy.name
+			log.Debugf("Moved config %s from node %s to node %s", c.Name, busiest.name, leastBusy.name)
+			break
+		}
+	}
+}
+
+func (d *Dispatcher) leastBusyNode() *nodeStore {
+	var selected *nodeStore
+	for _, node := range d.sortedNodes() {
+		if selected == nil || node.movableConfigs() < selected.movableConfigs() {
+			selected = node
+		}
+	}
+	return selected
+}
+
+func (d *Dispatcher) busiestNode() *nodeStore {
+	var selected *nodeStore
+	for _, node := range d.sortedNodes() {
+		if selected == nil || node.movableConfigs() > selected.movableConfigs() {
+			selected = node
+		}
+	}
+	return selected
+}
+
+// sortedNodes returns the nodes sorted by name, for the dispatching to be deterministic.
+func (d *Dispatcher) sortedNodes() []*nodeStore {
+	nodes := make([]*nodeStore, 0, len(d.store.nodes))
+	for _, node := range d.store.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].name < nodes[j].name })
+	return nodes
+}
@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clusterchecks
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func generateConfig(name string, clusterCheck bool) integration.Config {
+	return integration.Config{
+		Name:         name,
+		Instances:    []integration.Data{integration.Data(fmt.Sprintf("host: %s", name))},
+		ClusterCheck: clusterCheck,
+	}
+}
+
+func configNames(configs []integration.Config) []string {
+	var names []string
+	for _, c := range configs {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestScheduleDanglingAndDispatch(t *testing.T) {
+	d := NewDispatcher()
+
+	d.Schedule([]integration.Config{
+		generateConfig("cluster-check", true),
+		generateConfig("node-check", false),
+	})
+
+	// No node yet, the config is dangling
+	state := d.GetState()
+	assert.Len(t, state.Nodes, 0)
+	assert.Equal(t, []string{"cluster-check"}, configNames(state.Dangling))
+
+	// The first node to poll gets the config, patched to be scheduled as a regular check
+	resp := d.GetNodeConfigs("node1")
+	require.Len(t, resp.Configs, 1)
+	assert.Equal(t, "cluster-check", resp.Configs[0].Name)
+	assert.False(t, resp.Configs[0].ClusterCheck)
+	assert.Len(t, d.GetState().Dangling, 0)
+
+	// Unscheduling removes it from the node
+	d.Unschedule([]integration.Config{generateConfig("cluster-check", true)})
+	assert.Len(t, d.GetNodeConfigs("node1").Configs, 0)
+}
+
+func TestRebalanceOnNodeJoin(t *testing.T) {
+	d := NewDispatcher()
+	d.GetNodeConfigs("node1")
+
+	var configs []integration.Config
+	for i := 0; i < 4; i++ {
+		configs = append(configs, generateConfig(fmt.Sprintf("check%d", i), true))
+	}
+	d.Schedule(configs)
+	assert.Len(t, d.GetNodeConfigs("node1").Configs, 4)
+
+	// A new node takes half of the configs
+	assert.Len(t, d.GetNodeConfigs("node2").Configs, 2)
+	assert.Len(t, d.GetNodeConfigs("node1").Configs, 2)
+
+	// The configs are dispatched once
+	all := append(d.GetNodeConfigs("node1").Configs, d.GetNodeConfigs("node2").Configs...)
+	assert.ElementsMatch(t, []string{"check0", "check1", "check2", "check3"}, configNames(all))
+}
+
+func TestExpireNodes(t *testing.T) {
+	d := NewDispatcher()
+	d.GetNodeConfigs("node1")
+	d.GetNodeConfigs("node2")
+	d.Schedule([]integration.Config{
+		generateConfig("check0", true),
+		generateConfig("check1", true),
+	})
+	assert.Len(t, d.GetNodeConfigs("node1").Configs, 1)
+
+	// node2 stopped polling
+	d.store.nodes["node2"].lastPing = time.Now().Add(-time.Hour)
+	d.expireNodes()
+
+	state := d.GetState()
+	require.Len(t, state.Nodes, 1)
+	assert.Equal(t, "node1", state.Nodes[0].Name)
+	assert.Len(t, state.Nodes[0].Configs, 2)
+	assert.Len(t, state.Dangling, 0)
+
+	// Last node leaving, the configs are dangling
+	d.store.nodes["node1"].lastPing = time.Now().Add(-time.Hour)
+	d.expireNodes()
+	assert.Len(t, d.GetState().Dangling, 2)
+}
+
+func TestDispatchEndpointChecks(t *testing.T) {
+	d := NewDispatcher()
+	d.GetNodeConfigs("node1")
+
+	endpointCheck := generateConfig("endpoint-check", true)
+	endpointCheck.NodeName = "node2"
+	d.Schedule([]integration.Config{
+		endpointCheck,
+		generateConfig("check0", true),
+		generateConfig("check1", true),
+	})
+
+	// The endpoint check waits for its node
+	assert.ElementsMatch(t, []string{"check0", "check1"}, configNames(d.GetNodeConfigs("node1").Configs))
+	assert.Equal(t, []string{"endpoint-check"}, configNames(d.GetState().Dangling))
+
+	// node2 gets the endpoint check and one of the other checks
+	node2Configs := configNames(d.GetNodeConfigs("node2").Configs)
+	assert.Len(t, node2Configs, 2)
+	assert.Contains(t, node2Configs, "endpoint-check")
+	assert.Len(t, d.GetNodeConfigs("node1").Configs, 1)
+	assert.Len(t, d.GetState().Dangling, 0)
+
+	// The endpoint check is not moved to another node when node2 expires
+	d.store.nodes["node2"].lastPing = time.Now().Add(-time.Hour)
+	d.expireNodes()
+	assert.ElementsMatch(t, []string{"check0", "check1"}, configNames(d.GetNodeConfigs("node1").Configs))
+	assert.Equal(t, []string{"endpoint-check"}, configNames(d.GetState().Dangling))
+}
+
+func TestGetStats(t *testing.T) {
+	d := NewDispatcher()
+	d.GetNodeConfigs("node1")
+
+	endpointCheck := generateConfig("endpoint-check", true)
+	endpointCheck.NodeName = "node2"
+	d.Schedule([]integration.Config{
+		endpointCheck,
+		generateConfig("check0", true),
+		generateConfig("check1", true),
+	})
+
+	stats := d.GetStats()
+	assert.Equal(t, 1, stats.Nodes)
+	assert.Equal(t, 3, stats.Configs)
+	assert.Equal(t, 1, stats.Dangling)
+	assert.Equal(t, 1, stats.EndpointChecks)
+	assert.Equal(t, map[string]int{"node1": 2}, stats.NodeConfigs)
+
+	d.GetNodeConfigs("node2")
+	stats = d.GetStats()
+	assert.Equal(t, 2, stats.Nodes)
+	assert.Equal(t, 0, stats.Dangling)
+	assert.Equal(t, 3, stats.NodeConfigs["node1"]+stats.NodeConfigs["node2"])
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clusterchecks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// clusterStore holds the state of cluster-check management.
+// Lock is to be held by the dispatcher so it can make atomic
+// operations involving several calls.
+type clusterStore struct {
+	sync.RWMutex
+	digestToConfig  map[string]integration.Config // All configurations to dispatch
+	digestToNode    map[string]string             // Node running a config
+	nodes           map[string]*nodeStore
+	danglingConfigs map[string]integration.Config // Configs we could not dispatch to any node
+}
+
+func newClusterStore() *clusterStore {
+	return &clusterStore{
+		digestToConfig:  make(map[string]integration.Config),
+		digestToNode:    make(map[string]string),
+		nodes:           make(map[string]*nodeStore),
+		danglingConfigs: make(map[string]integration.Config),
+	}
+}
+
+// getOrCreateNodeStore returns the nodeStore for a given node name, and whether it was created.
+// The clusterStore lock must be held.
+func (s *clusterStore) getOrCreateNodeStore(nodeName string) (*nodeStore, bool) {
+	node, found := s.nodes[nodeName]
+	if found {
+		return node, false
+	}
+	node = newNodeStore(nodeName)
+	s.nodes[nodeName] = node
+	return node, true
+}
+
+// nodeStore holds the state of cluster-check management for one node.
+type nodeStore struct {
+	name             string
+	lastPing         time.Time
+	lastConfigChange int64
+	digestToConfig   map[string]integration.Config
+}
+
+func newNodeStore(name string) *nodeStore {
+	return &nodeStore{
+		name:           name,
+		digestToConfig: make(map[string]integration.Config),
+	}
+}
+
+func (s *nodeStore) addConfig(config integration.Config) {
+	s.lastConfigChange = timestampNow()
+	s.digestToConfig[config.Digest()] = config
+}
+
+func (s *nodeStore) removeConfig(digest string) {
+	s.lastConfigChange = timestampNow()
+	delete(s.digestToConfig, digest)
+}
+
+// movableConfigs returns the number of configs that can be moved to another node,
+// the endpoint checks being pinned to their node.
+func (s *nodeStore) movableConfigs() int {
+	count := 0
+	for _, c := range s.digestToConfig {
+		if c.NodeName == "" {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *nodeStore) configs() []integration.Config {
+	var configs []integration.Config
+	for _, c := range s.digestToConfig {
+		configs = append(configs, c)
+	}
+	return configs
+}
+
+// timestampNow is a variable to be overridden in tests
+var timestampNow = func() int64 {
+	return time.Now().Unix()
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clusterchecks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// templateStore holds the check templates the cluster agent serves to the
+// node agents, e.g. the ones of the ConfigMaps, to be resolved against the
+// services of each node.
+type templateStore struct {
+	sync.RWMutex
+	digests  map[string]struct{}
+	response ConfigResponse
+}
+
+var globalTemplates = newTemplateStore()
+
+func newTemplateStore() *templateStore {
+	return &templateStore{
+		digests: make(map[string]struct{}),
+	}
+}
+
+// set replaces the templates, LastChange is only updated if they changed
+func (s *templateStore) set(templates []integration.Config) {
+	digests := make(map[string]struct{}, len(templates))
+	for _, tpl := range templates {
+		digests[tpl.Digest()] = struct{}{}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if sameDigests(s.digests, digests) {
+		return
+	}
+	s.digests = digests
+	s.response = ConfigResponse{
+		LastChange: time.Now().Unix(),
+		Configs:    templates,
+	}
+}
+
+func (s *templateStore) get() ConfigResponse {
+	s.RLock()
+	defer s.RUnlock()
+	return s.response
+}
+
+func sameDigests(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for digest := range a {
+		if _, found := b[digest]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SetTemplates replaces the check templates served to the node agents.
+func SetTemplates(templates []integration.Config) {
+	globalTemplates.set(templates)
+}
+
+// GetTemplates returns the check templates served to the node agents.
+func GetTemplates() ConfigResponse {
+	return globalTemplates.get()
+}
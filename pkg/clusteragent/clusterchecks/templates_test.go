@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clusterchecks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestTemplateStore(t *testing.T) {
+	store := newTemplateStore()
+	assert.Len(t, store.get().Configs, 0)
+	assert.Zero(t, store.get().LastChange)
+
+	redis := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: '%%host%%'")},
+	}
+	store.set([]integration.Config{redis})
+	response := store.get()
+	assert.Equal(t, []integration.Config{redis}, response.Configs)
+	assert.NotZero(t, response.LastChange)
+
+	// the same templates don't change LastChange
+	store.response.LastChange = 1
+	store.set([]integration.Config{redis})
+	assert.Equal(t, int64(1), store.get().LastChange)
+
+	store.set(nil)
+	assert.Len(t, store.get().Configs, 0)
+	assert.NotEqual(t, int64(1), store.get().LastChange)
+}
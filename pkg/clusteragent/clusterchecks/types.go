@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clusterchecks
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// ConfigResponse holds the DCA response for a config query
+type ConfigResponse struct {
+	LastChange int64                `json:"last_change"`
+	Configs    []integration.Config `json:"configs"`
+}
+
+// StateNodeResponse holds the state of a node agent for the status command
+type StateNodeResponse struct {
+	Name     string               `json:"name"`
+	LastSeen int64                `json:"last_seen"`
+	Configs  []integration.Config `json:"configs"`
+}
+
+// StatsResponse holds the distribution of the dispatched configs, for the status page
+type StatsResponse struct {
+	Nodes          int            `json:"nodes"`
+	Configs        int            `json:"configs"`
+	Dangling       int            `json:"dangling"`
+	EndpointChecks int            `json:"endpoint_checks"`
+	NodeConfigs    map[string]int `json:"node_configs"`
+}
+
+// StateResponse holds the DCA response for the dispatching state
+type StateResponse struct {
+	NotRunning string               `json:"not_running"` // Reason why not running, empty if leading
+	Nodes      []StateNodeResponse  `json:"nodes"`
+	Dangling   []integration.Config `json:"dangling"`
+}
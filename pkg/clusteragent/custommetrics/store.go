@@ -18,7 +18,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
 const (
@@ -34,11 +33,16 @@ type Store interface {
 	ListAllExternalMetricValues() ([]ExternalMetricValue, error)
 }
 
+// ClientGetter returns the client set to use. It's called on every request
+// rather than kept, to follow the replacements of the client set, e.g. by
+// apiserver.APIClient.Client.
+type ClientGetter func() kubernetes.Interface
+
 // configMapStore provides persistent storage of custom and external metrics using a configmap.
 type configMapStore struct {
 	namespace string
 	name      string
-	client    corev1.CoreV1Interface
+	getClient ClientGetter
 	cm        *v1.ConfigMap
 }
 
@@ -49,14 +53,15 @@ func GetHPAConfigmapName() string {
 
 // NewConfigMapStore returns a new store backed by a configmap. The configmap will be created
 // in the specified namespace if it does not exist.
-func NewConfigMapStore(client kubernetes.Interface, ns, name string) (Store, error) {
+func NewConfigMapStore(getClient ClientGetter, ns, name string) (Store, error) {
+	client := getClient()
 	cm, err := client.CoreV1().ConfigMaps(ns).Get(name, metav1.GetOptions{})
 	if err == nil {
 		log.Infof("Retrieved the configmap %s", name)
 		return &configMapStore{
 			namespace: ns,
 			name:      name,
-			client:    client.CoreV1(),
+			getClient: getClient,
 			cm:        cm,
 		}, nil
 	}
@@ -81,7 +86,7 @@ func NewConfigMapStore(client kubernetes.Interface, ns, name string) (Store, err
 	return &configMapStore{
 		namespace: ns,
 		name:      name,
-		client:    client.CoreV1(),
+		getClient: getClient,
 		cm:        cm,
 	}, nil
 }
@@ -160,7 +165,7 @@ func (c *configMapStore) ListAllExternalMetricValues() ([]ExternalMetricValue, e
 
 func (c *configMapStore) getConfigMap() error {
 	var err error
-	c.cm, err = c.client.ConfigMaps(c.namespace).Get(c.name, metav1.GetOptions{})
+	c.cm, err = c.getClient().CoreV1().ConfigMaps(c.namespace).Get(c.name, metav1.GetOptions{})
 	if err != nil {
 		log.Infof("Could not get the configmap %s: %s", c.name, err)
 		return err
@@ -173,7 +178,7 @@ func (c *configMapStore) updateConfigMap() error {
 		return fmt.Errorf("configmap not initialized")
 	}
 	var err error
-	c.cm, err = c.client.ConfigMaps(c.namespace).Update(c.cm)
+	c.cm, err = c.getClient().CoreV1().ConfigMaps(c.namespace).Update(c.cm)
 	if err != nil {
 		log.Infof("Could not update the configmap %s: %s", c.name, err)
 		return err
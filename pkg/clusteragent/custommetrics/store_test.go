@@ -16,6 +16,7 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -32,16 +33,38 @@ func TestNewConfigMapStore(t *testing.T) {
 	require.NoError(t, err)
 
 	// configmap already exists
-	store, err := NewConfigMapStore(client, "default", "foo")
+	store, err := NewConfigMapStore(func() kubernetes.Interface { return client }, "default", "foo")
 	require.NoError(t, err)
 	require.NotNil(t, store.(*configMapStore).cm)
 
 	// configmap doesn't exist
-	store, err = NewConfigMapStore(client, "default", "bar")
+	store, err = NewConfigMapStore(func() kubernetes.Interface { return client }, "default", "bar")
 	require.NoError(t, err)
 	require.NotNil(t, store.(*configMapStore).cm)
 }
 
+func TestConfigMapStoreClientReplaced(t *testing.T) {
+	newClient := func() kubernetes.Interface {
+		return fake.NewSimpleClientset(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}})
+	}
+	previous, current := newClient(), newClient()
+	client := previous
+	store, err := NewConfigMapStore(func() kubernetes.Interface { return client }, "default", "foo")
+	require.NoError(t, err)
+
+	// the store uses the client set in use on every request
+	client = current
+	err = store.SetExternalMetricValues([]ExternalMetricValue{{MetricName: "requests_per_s", HPA: ObjectReference{Name: "foo", Namespace: "default"}}})
+	require.NoError(t, err)
+
+	cm, err := current.CoreV1().ConfigMaps("default").Get("foo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, cm.Data, 1)
+	cm, err = previous.CoreV1().ConfigMaps("default").Get("foo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, cm.Data, 0)
+}
+
 func TestConfigMapStoreExternalMetrics(t *testing.T) {
 	client := fake.NewSimpleClientset()
 
@@ -130,7 +153,7 @@ func TestConfigMapStoreExternalMetrics(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(fmt.Sprintf("#%d %s", i, tt.desc), func(t *testing.T) {
-			store, err := NewConfigMapStore(client, "default", fmt.Sprintf("test-%d", i))
+			store, err := NewConfigMapStore(func() kubernetes.Interface { return client }, "default", fmt.Sprintf("test-%d", i))
 			require.NoError(t, err)
 			require.NotNil(t, store.(*configMapStore).cm)
 
@@ -0,0 +1,280 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	podType        = "pod"
+	deploymentType = "deployment"
+	replicaSetType = "replicaset"
+	nodeType       = "node"
+
+	defaultMaxPerMessage = 100
+
+	// informersSyncTimeout is how long a collection waits for the caches of
+	// the informers to sync
+	informersSyncTimeout = 10 * time.Second
+)
+
+var errNotSynced = errors.New("the caches of the pods, deployments, replicasets and nodes are not synced, check that the cluster agent can list and watch them")
+
+// ManifestSender sends the serialized payloads, implemented by the serializer
+type ManifestSender interface {
+	SendOrchestratorManifests(payloads [][]byte) error
+}
+
+// Collector periodically lists the pods, deployments, replicasets and nodes
+// of the cluster from the caches of the shared informers, and sends their
+// scrubbed manifests.
+type Collector struct {
+	sender      ManifestSender
+	apiClient   *apiserver.APIClient
+	clusterName string
+	interval    time.Duration
+	groupID     int32
+	stop        chan struct{}
+}
+
+// NewCollector returns a Collector, call Run to start the collection.
+func NewCollector(sender ManifestSender) (*Collector, error) {
+	apiClient, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return &Collector{
+		sender:      sender,
+		apiClient:   apiClient,
+		clusterName: config.Datadog.GetString("cluster_name"),
+		interval:    time.Duration(config.Datadog.GetInt64("orchestrator_explorer.collection_interval")) * time.Second,
+		stop:        make(chan struct{}),
+	}, nil
+}
+
+// Run collects and sends the manifests every orchestrator_explorer.collection_interval, until Stop is called.
+func (c *Collector) Run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := c.collect(); err != nil {
+				log.Errorf("Could not collect the orchestrator manifests: %s", err)
+			}
+		}
+	}
+}
+
+// Stop stops the collection.
+func (c *Collector) Stop() {
+	close(c.stop)
+}
+
+func (c *Collector) collect() error {
+	// Only the leader sends the manifests when several cluster agents run
+	if config.Datadog.GetBool("leader_election") {
+		le, err := leaderelection.GetLeaderEngine()
+		if err != nil {
+			return err
+		}
+		if !le.IsLeader() {
+			return nil
+		}
+	}
+
+	// the informers are started by the first collection
+	factory, stop := c.apiClient.InformerFactory()
+	pods := factory.Core().V1().Pods()
+	deployments := factory.Apps().V1().Deployments()
+	replicaSets := factory.Apps().V1().ReplicaSets()
+	nodes := factory.Core().V1().Nodes()
+	if !apiserver.SyncInformers(factory, stop, informersSyncTimeout,
+		pods.Informer().HasSynced,
+		deployments.Informer().HasSynced,
+		replicaSets.Informer().HasSynced,
+		nodes.Informer().HasSynced,
+	) {
+		return errNotSynced
+	}
+
+	var manifests []*Manifest
+	for _, list := range []func() ([]*Manifest, error){
+		func() ([]*Manifest, error) { return listPods(pods.Lister()) },
+		func() ([]*Manifest, error) { return listDeployments(deployments.Lister()) },
+		func() ([]*Manifest, error) { return listReplicaSets(replicaSets.Lister()) },
+		func() ([]*Manifest, error) { return listNodes(nodes.Lister()) },
+	} {
+		m, err := list()
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, m...)
+	}
+	if len(manifests) == 0 {
+		return nil
+	}
+
+	groupID := atomic.AddInt32(&c.groupID, 1)
+	payloads, err := chunkManifests(c.clusterName, groupID, manifests, maxPerMessage())
+	if err != nil {
+		return err
+	}
+	log.Debugf("Sending %d manifests in %d payloads", len(manifests), len(payloads))
+	return c.sender.SendOrchestratorManifests(payloads)
+}
+
+// chunkManifests serializes the manifests in payloads of at most maxPerMessage manifests.
+func chunkManifests(clusterName string, groupID int32, manifests []*Manifest, maxPerMessage int) ([][]byte, error) {
+	groupSize := (len(manifests) + maxPerMessage - 1) / maxPerMessage
+	timestamp := time.Now().Unix()
+	payloads := make([][]byte, 0, groupSize)
+	for start := 0; start < len(manifests); start += maxPerMessage {
+		end := start + maxPerMessage
+		if end > len(manifests) {
+			end = len(manifests)
+		}
+		payload, err := proto.Marshal(&ManifestPayload{
+			ClusterName: clusterName,
+			GroupID:     groupID,
+			GroupSize:   int32(groupSize),
+			Timestamp:   timestamp,
+			Manifests:   manifests[start:end],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not serialize the manifests: %s", err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+func maxPerMessage() int {
+	max := config.Datadog.GetInt("orchestrator_explorer.max_per_message")
+	if max <= 0 {
+		log.Warnf("Invalid orchestrator_explorer.max_per_message %d, using %d", max, defaultMaxPerMessage)
+		return defaultMaxPerMessage
+	}
+	return max
+}
+
+// newManifest serializes a scrubbed object, its metadata being given separately.
+func newManifest(manifestType string, meta metav1.ObjectMeta, object interface{}) (*Manifest, error) {
+	content, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize the %s %s/%s: %s", manifestType, meta.Namespace, meta.Name, err)
+	}
+	return &Manifest{
+		Type:            manifestType,
+		UID:             string(meta.UID),
+		ResourceVersion: meta.ResourceVersion,
+		Namespace:       meta.Namespace,
+		Name:            meta.Name,
+		Content:         content,
+	}, nil
+}
+
+// The listed objects are shared with the caches of the informers, they are
+// copied before being scrubbed.
+
+func listPods(lister corelisters.PodLister) ([]*Manifest, error) {
+	pods, err := lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("could not list the pods: %s", err)
+	}
+	manifests := make([]*Manifest, 0, len(pods))
+	for _, pod := range pods {
+		pod = pod.DeepCopy()
+		pod.Annotations = scrubAnnotations(pod.Annotations)
+		scrubPodSpec(&pod.Spec)
+		m, err := newManifest(podType, pod.ObjectMeta, pod)
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+func listDeployments(lister appslisters.DeploymentLister) ([]*Manifest, error) {
+	deployments, err := lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("could not list the deployments: %s", err)
+	}
+	manifests := make([]*Manifest, 0, len(deployments))
+	for _, deployment := range deployments {
+		deployment = deployment.DeepCopy()
+		deployment.Annotations = scrubAnnotations(deployment.Annotations)
+		scrubPodSpec(&deployment.Spec.Template.Spec)
+		m, err := newManifest(deploymentType, deployment.ObjectMeta, deployment)
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+func listReplicaSets(lister appslisters.ReplicaSetLister) ([]*Manifest, error) {
+	replicaSets, err := lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("could not list the replicasets: %s", err)
+	}
+	manifests := make([]*Manifest, 0, len(replicaSets))
+	for _, replicaSet := range replicaSets {
+		replicaSet = replicaSet.DeepCopy()
+		replicaSet.Annotations = scrubAnnotations(replicaSet.Annotations)
+		scrubPodSpec(&replicaSet.Spec.Template.Spec)
+		m, err := newManifest(replicaSetType, replicaSet.ObjectMeta, replicaSet)
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+func listNodes(lister corelisters.NodeLister) ([]*Manifest, error) {
+	nodes, err := lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("could not list the nodes: %s", err)
+	}
+	manifests := make([]*Manifest, 0, len(nodes))
+	for _, node := range nodes {
+		node = node.DeepCopy()
+		node.Annotations = scrubAnnotations(node.Annotations)
+		m, err := newManifest(nodeType, node.ObjectMeta, node)
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestChunkManifests(t *testing.T) {
+	var manifests []*Manifest
+	for i := 0; i < 5; i++ {
+		manifests = append(manifests, &Manifest{Type: podType, Name: fmt.Sprintf("pod%d", i), Content: []byte("{}")})
+	}
+
+	payloads, err := chunkManifests("cluster", 3, manifests, 2)
+	require.NoError(t, err)
+	require.Len(t, payloads, 3)
+
+	var names []string
+	for _, payload := range payloads {
+		decoded := &ManifestPayload{}
+		require.NoError(t, proto.Unmarshal(payload, decoded))
+		assert.Equal(t, "cluster", decoded.ClusterName)
+		assert.EqualValues(t, 3, decoded.GroupID)
+		assert.EqualValues(t, 3, decoded.GroupSize)
+		assert.True(t, len(decoded.Manifests) <= 2)
+		for _, m := range decoded.Manifests {
+			names = append(names, m.Name)
+		}
+	}
+	assert.Equal(t, []string{"pod0", "pod1", "pod2", "pod3", "pod4"}, names)
+}
+
+func TestListPodsDoesNotModifyTheCache(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "uid"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Env: []v1.EnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}}}},
+		},
+	}
+	require.NoError(t, indexer.Add(pod))
+
+	manifests, err := listPods(corelisters.NewPodLister(indexer))
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+	assert.Equal(t, "uid", manifests[0].UID)
+
+	scrubbed := &v1.Pod{}
+	require.NoError(t, json.Unmarshal(manifests[0].Content, scrubbed))
+	assert.Equal(t, redactedValue, scrubbed.Spec.Containers[0].Env[0].Value)
+	// the object of the cache keeps its value
+	assert.Equal(t, "hunter2", pod.Spec.Containers[0].Env[0].Value)
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package orchestrator
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// The messages below follow the protobuf encoding of:
+//
+//   message Manifest {
+//     string type = 1;
+//     string uid = 2;
+//     string resourceVersion = 3;
+//     string namespace = 4;
+//     string name = 5;
+//     bytes content = 6;
+//   }
+//
+//   message ManifestPayload {
+//     string clusterName = 1;
+//     int32 groupId = 2;
+//     int32 groupSize = 3;
+//     int64 timestamp = 4;
+//     repeated Manifest manifests = 5;
+//   }
+
+// Manifest is the scrubbed JSON specification of a Kubernetes resource
+type Manifest struct {
+	Type            string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	UID             string `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	ResourceVersion string `protobuf:"bytes,3,opt,name=resourceVersion,proto3" json:"resourceVersion,omitempty"`
+	Namespace       string `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name            string `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Content         []byte `protobuf:"bytes,6,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Manifest) Reset() { *m = Manifest{} }
+
+// String implements proto.Message
+func (m *Manifest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Manifest) ProtoMessage() {}
+
+// ManifestPayload is a chunk of the manifests collected in a run. The chunks of
+// a run share the same GroupID, GroupSize being the number of chunks.
+type ManifestPayload struct {
+	ClusterName string      `protobuf:"bytes,1,opt,name=clusterName,proto3" json:"clusterName,omitempty"`
+	GroupID     int32       `protobuf:"varint,2,opt,name=groupId,proto3" json:"groupId,omitempty"`
+	GroupSize   int32       `protobuf:"varint,3,opt,name=groupSize,proto3" json:"groupSize,omitempty"`
+	Timestamp   int64       `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Manifests   []*Manifest `protobuf:"bytes,5,rep,name=manifests" json:"manifests,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ManifestPayload) Reset() { *m = ManifestPayload{} }
+
+// String implements proto.Message
+func (m *ManifestPayload) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ManifestPayload) ProtoMessage() {}
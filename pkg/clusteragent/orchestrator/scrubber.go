@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/api/core/v1"
+)
+
+const (
+	redactedValue = "********"
+	// lastAppliedConfigAnnotation holds the whole manifest applied by kubectl, secrets included
+	lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+var (
+	sensitiveWords = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "credentials"}
+	// sensitiveArgRegex matches the --password=value form of command line arguments
+	sensitiveArgRegex = regexp.MustCompile(`(?i)^(-{1,2}[\w-]*(` + strings.Join(sensitiveWords, "|") + `)[\w-]*=).+$`)
+)
+
+// isSensitive returns whether a name looks like it holds a secret.
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range sensitiveWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubAnnotations removes the last applied configuration, which holds the unscrubbed manifest.
+func scrubAnnotations(annotations map[string]string) map[string]string {
+	if _, found := annotations[lastAppliedConfigAnnotation]; !found {
+		return annotations
+	}
+	scrubbed := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if k != lastAppliedConfigAnnotation {
+			scrubbed[k] = v
+		}
+	}
+	return scrubbed
+}
+
+// scrubPodSpec redacts the values of the sensitive environment variables and
+// command line arguments of the containers. The spec is modified in place,
+// it must be a copy of the cached object.
+func scrubPodSpec(spec *v1.PodSpec) {
+	for i := range spec.InitContainers {
+		scrubContainer(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		scrubContainer(&spec.Containers[i])
+	}
+}
+
+func scrubContainer(container *v1.Container) {
+	for i, env := range container.Env {
+		if env.Value != "" && isSensitive(env.Name) {
+			container.Env[i].Value = redactedValue
+		}
+	}
+	container.Command = scrubArgs(container.Command)
+	container.Args = scrubArgs(container.Args)
+}
+
+// scrubArgs redacts the values of the sensitive arguments, in the --password=value
+// and the --password value forms.
+func scrubArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	scrubbed := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		switch {
+		case redactNext:
+			scrubbed[i] = redactedValue
+			redactNext = false
+		case sensitiveArgRegex.MatchString(arg):
+			scrubbed[i] = sensitiveArgRegex.ReplaceAllString(arg, "${1}"+redactedValue)
+		default:
+			scrubbed[i] = arg
+			redactNext = strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") && isSensitive(arg)
+		}
+	}
+	return scrubbed
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+)
+
+func TestScrubPodSpec(t *testing.T) {
+	spec := v1.PodSpec{
+		InitContainers: []v1.Container{
+			{Env: []v1.EnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}}},
+		},
+		Containers: []v1.Container{
+			{
+				Command: []string{"app", "--api-key=abcdef", "--token", "secretvalue", "--port", "80"},
+				Args:    []string{"-v", "--password=hunter2"},
+				Env: []v1.EnvVar{
+					{Name: "DD_API_KEY", Value: "abcdef"},
+					{Name: "DD_ENV", Value: "prod"},
+					{Name: "SECRET_FROM_REF", ValueFrom: &v1.EnvVarSource{}},
+				},
+			},
+		},
+	}
+
+	scrubPodSpec(&spec)
+
+	assert.Equal(t, redactedValue, spec.InitContainers[0].Env[0].Value)
+	container := spec.Containers[0]
+	assert.Equal(t, []string{"app", "--api-key=********", "--token", "********", "--port", "80"}, container.Command)
+	assert.Equal(t, []string{"-v", "--password=********"}, container.Args)
+	assert.Equal(t, redactedValue, container.Env[0].Value)
+	assert.Equal(t, "prod", container.Env[1].Value)
+	// References to secrets are kept as they don't hold the value
+	assert.Equal(t, "", container.Env[2].Value)
+	assert.NotNil(t, container.Env[2].ValueFrom)
+}
+
+func TestScrubAnnotations(t *testing.T) {
+	annotations := map[string]string{
+		lastAppliedConfigAnnotation: `{"spec":{}}`,
+		"foo":                       "bar",
+	}
+	assert.Equal(t, map[string]string{"foo": "bar"}, scrubAnnotations(annotations))
+	assert.Nil(t, scrubAnnotations(nil))
+}
@@ -28,3 +28,22 @@ type Check interface {
 	GetMetricStats() (map[string]int64, error)           // get metric stats from the sender
 	Version() string                                     // return the version of the check if available
 }
+
+// DedicatedRunnerCheck is implemented by the checks whose instances can be
+// flagged as long-running or blocking with the `dedicated_runner` option
+type DedicatedRunnerCheck interface {
+	DedicatedRunner() bool // whether the instance asked for a dedicated runner
+}
+
+// ConfigDigestCheck is implemented by the checks exposing a digest of the
+// configuration of their instance, to tell when it changes
+type ConfigDigestCheck interface {
+	ConfigDigest() string // digest of the instance and init_config
+}
+
+// IsDedicatedRunner returns whether a check runs on its own runner, outside of
+// the shared pool. Long-running checks already get an extra runner.
+func IsDedicatedRunner(c Check) bool {
+	dedicated, ok := c.(DedicatedRunnerCheck)
+	return ok && dedicated.DedicatedRunner() && c.Interval() != 0
+}
@@ -74,3 +74,12 @@ func TestIDToCheckName(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigDigest(t *testing.T) {
+	instance := integration.Data("key1:value1\nkey2:value2")
+	initConfig := integration.Data("key:value")
+
+	digest := ConfigDigest(instance, initConfig)
+	assert.Equal(t, ID("TestCheck:"+digest), BuildID("TestCheck", instance, initConfig))
+	assert.NotEqual(t, digest, ConfigDigest(integration.Data("key1:value1"), initConfig))
+}
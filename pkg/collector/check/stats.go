@@ -14,6 +14,7 @@ import (
 type Stats struct {
 	CheckName            string
 	CheckVersion         string
+	CheckConfigDigest    string
 	CheckID              ID
 	TotalRuns            uint64
 	TotalErrors          uint64
@@ -24,6 +25,7 @@ type Stats struct {
 	TotalMetricSamples   int64
 	TotalEvents          int64
 	TotalServiceChecks   int64
+	MetricsOverLimit     int64     // metric samples dropped in the last run because of max_metrics_per_instance
 	ExecutionTimes       [32]int64 // circular buffer of recent run durations, most recent at [(TotalRuns+31) % 32]
 	AverageExecutionTime int64     // average run duration
 	LastExecutionTime    int64     // most recent run duration, provided for convenience
@@ -35,11 +37,15 @@ type Stats struct {
 
 // NewStats returns a new check stats instance
 func NewStats(c Check) *Stats {
-	return &Stats{
+	stats := &Stats{
 		CheckID:      c.ID(),
 		CheckName:    c.String(),
 		CheckVersion: c.Version(),
 	}
+	if digest, ok := c.(ConfigDigestCheck); ok {
+		stats.CheckConfigDigest = digest.ConfigDigest()
+	}
+	return stats
 }
 
 // Add tracks a new execution time
@@ -94,4 +100,7 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 			cs.TotalServiceChecks += sc
 		}
 	}
+	if over, ok := metricStats["MetricsOverLimit"]; ok {
+		cs.MetricsOverLimit = over
+	}
 }
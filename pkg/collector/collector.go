@@ -97,7 +97,7 @@ func (c *Collector) RunCheck(ch check.Check) (check.ID, error) {
 		return emptyID, fmt.Errorf("a check with ID %s is already running", ch.ID())
 	}
 
-	err := c.scheduler.Enter(ch)
+	err := c.schedule(ch)
 	if err != nil {
 		return emptyID, fmt.Errorf("unable to schedule the check: %s", err)
 	}
@@ -146,6 +146,9 @@ func (c *Collector) ReloadCheck(id check.ID, config, initConfig integration.Data
 		return fmt.Errorf("an error occurred while stopping the check: %s", err)
 	}
 
+	// the new configuration might not ask for a dedicated runner anymore
+	c.runner.RemoveDedicatedWorker(id)
+
 	// re-configure
 	check := c.checks[id]
 	err = check.Configure(config, initConfig)
@@ -154,7 +157,7 @@ func (c *Collector) ReloadCheck(id check.ID, config, initConfig integration.Data
 	}
 
 	// re-schedule
-	c.scheduler.Enter(check)
+	c.schedule(check)
 
 	return nil
 }
@@ -180,6 +183,7 @@ func (c *Collector) StopCheck(id check.ID) error {
 	if err != nil {
 		return fmt.Errorf("an error occurred while stopping the check: %s", err)
 	}
+	c.runner.RemoveDedicatedWorker(id)
 
 	// remove the check from the stats map
 	runner.RemoveCheckStats(id)
@@ -190,6 +194,21 @@ func (c *Collector) StopCheck(id check.ID) error {
 	return nil
 }
 
+// schedule enters a check in the scheduler, running it on a dedicated worker
+// if its instance was flagged with `dedicated_runner`
+func (c *Collector) schedule(ch check.Check) error {
+	if !check.IsDedicatedRunner(ch) {
+		return c.scheduler.Enter(ch)
+	}
+
+	log.Infof("Adding a dedicated runner for the '%s' check", ch)
+	err := c.scheduler.EnterDedicated(ch, c.runner.AddDedicatedWorker(ch.ID()))
+	if err != nil {
+		c.runner.RemoveDedicatedWorker(ch.ID())
+	}
+	return err
+}
+
 // check if the check is on the list
 func (c *Collector) find(id check.ID) bool {
 	c.m.RLock()
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"time"
 
+	yaml "gopkg.in/yaml.v2"
+
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
@@ -29,9 +31,11 @@ import (
 // that forward the warning to the logger and send the warning to
 // the collector for display in the status page and the web UI.
 type CheckBase struct {
-	checkName      string
-	checkID        check.ID
-	latestWarnings []error
+	checkName       string
+	checkID         check.ID
+	latestWarnings  []error
+	dedicatedRunner bool
+	configDigest    string
 }
 
 // NewCheckBase returns a check base struct with a given check name
@@ -48,6 +52,31 @@ func (c *CheckBase) BuildID(instance, initConfig integration.Data) {
 	c.checkID = check.BuildID(c.checkName, instance, initConfig)
 }
 
+// CommonConfigure parses the options shared by every check instance, it is
+// called by the loader once the check is configured.
+func (c *CheckBase) CommonConfigure(instance, initConfig integration.Data) error {
+	commonOptions := struct {
+		DedicatedRunner bool `yaml:"dedicated_runner"`
+	}{}
+	if err := yaml.Unmarshal(instance, &commonOptions); err != nil {
+		return err
+	}
+	c.dedicatedRunner = commonOptions.DedicatedRunner
+	c.configDigest = check.ConfigDigest(instance, initConfig)
+	return nil
+}
+
+// DedicatedRunner returns whether the instance was flagged to run on its own
+// runner, outside of the shared pool
+func (c *CheckBase) DedicatedRunner() bool {
+	return c.dedicatedRunner
+}
+
+// ConfigDigest returns a digest of the configuration of the instance
+func (c *CheckBase) ConfigDigest() string {
+	return c.configDigest
+}
+
 // Warn sends an integration warning to logs + agent status.
 func (c *CheckBase) Warn(v ...interface{}) error {
 	w := log.Warn(v)
@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeAPIServerMetricsCheckName = "kube_apiserver_metrics"
+	kubeAPIServerMetricsPrefix    = "kube_apiserver."
+	kubeAPIServerMetricsPath      = "/metrics"
+)
+
+// defaultAPIServerMetricFamilies maps the metric families collected by default to
+// the name of the metric submitted.
+var defaultAPIServerMetricFamilies = map[string]string{
+	"apiserver_request_count":             "request.count",
+	"apiserver_request_latencies":         "request.latencies",
+	"apiserver_request_latencies_summary": "request.latencies_summary",
+	"apiserver_current_inflight_requests": "current_inflight_requests",
+	"apiserver_dropped_requests":          "dropped_requests",
+	"etcd_object_counts":                  "etcd.object_counts",
+	"etcd_request_latencies_summary":      "etcd.request.latencies_summary",
+}
+
+// KubeAPIServerMetricsConfig is the config of the apiserver metrics check.
+type KubeAPIServerMetricsConfig struct {
+	Tags []string `yaml:"tags"`
+	// Allowlist lists additional metric families to collect, submitted with their own name
+	Allowlist []string `yaml:"allowlist"`
+	// Denylist lists the metric families not to collect, it has priority over the allowlist
+	Denylist []string `yaml:"denylist"`
+}
+
+// KubeAPIServerMetricsCheck scrapes the metrics of the kube-apiserver
+type KubeAPIServerMetricsCheck struct {
+	core.CheckBase
+	instance *KubeAPIServerMetricsConfig
+	families map[string]string
+	ac       *apiserver.APIClient
+}
+
+// KubeAPIServerMetricsFactory is exported for integration testing.
+func KubeAPIServerMetricsFactory() check.Check {
+	return &KubeAPIServerMetricsCheck{
+		CheckBase: core.NewCheckBase(kubeAPIServerMetricsCheckName),
+		instance:  &KubeAPIServerMetricsConfig{},
+	}
+}
+
+// Configure parses the check configuration and init the check.
+func (k *KubeAPIServerMetricsCheck) Configure(config, initConfig integration.Data) error {
+	err := yaml.Unmarshal(config, k.instance)
+	if err != nil {
+		log.Error("could not parse the config for the kube_apiserver_metrics check")
+		return err
+	}
+	k.families = buildMetricFamilies(k.instance.Allowlist, k.instance.Denylist)
+	return nil
+}
+
+// buildMetricFamilies returns the families to collect from the defaults and the allow and deny lists.
+func buildMetricFamilies(allowlist, denylist []string) map[string]string {
+	families := make(map[string]string)
+	for family, name := range defaultAPIServerMetricFamilies {
+		families[family] = name
+	}
+	for _, family := range allowlist {
+		if _, found := families[family]; !found {
+			families[family] = family
+		}
+	}
+	for _, family := range denylist {
+		delete(families, family)
+	}
+	return families
+}
+
+// Run executes the check.
+func (k *KubeAPIServerMetricsCheck) Run() error {
+	sender, err := aggregator.GetSender(k.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	if config.Datadog.GetBool("leader_election") {
+		err = runLeaderElection(&k.CheckBase)
+		if err == apiserver.ErrNotLeader {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if k.ac == nil {
+		k.ac, err = apiserver.GetAPIClient()
+		if err != nil {
+			k.Warnf("Could not connect to apiserver: %s", err)
+			return err
+		}
+	}
+
+	raw, err := k.ac.Client().CoreV1().RESTClient().Get().AbsPath(kubeAPIServerMetricsPath).DoRaw()
+	if err != nil {
+		k.Warnf("Could not scrape the apiserver metrics: %s", err)
+		return err
+	}
+
+	var parser expfmt.TextParser
+	metricFamilies, err := parser.TextToMetricFamilies(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("could not parse the apiserver metrics: %s", err)
+	}
+	k.submitMetricFamilies(sender, metricFamilies)
+	return nil
+}
+
+// submitMetricFamilies submits the collected metric families:
+// counters as monotonic counts, gauges as gauges, histograms and summaries
+// as monotonic counts of their sum and count, plus a gauge per quantile.
+func (k *KubeAPIServerMetricsCheck) submitMetricFamilies(sender aggregator.Sender, metricFamilies map[string]*dto.MetricFamily) {
+	for familyName, family := range metricFamilies {
+		name, found := k.families[familyName]
+		if !found {
+			continue
+		}
+		name = kubeAPIServerMetricsPrefix + name
+
+		for _, metric := range family.Metric {
+			tags := append([]string{}, k.instance.Tags...)
+			for _, label := range metric.Label {
+				tags = append(tags, fmt.Sprintf("%s:%s", label.GetName(), label.GetValue()))
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				sender.MonotonicCount(name, metric.GetCounter().GetValue(), "", tags)
+			case dto.MetricType_GAUGE:
+				sender.Gauge(name, metric.GetGauge().GetValue(), "", tags)
+			case dto.MetricType_UNTYPED:
+				sender.Gauge(name, metric.GetUntyped().GetValue(), "", tags)
+			case dto.MetricType_HISTOGRAM:
+				sender.MonotonicCount(name+".sum", metric.GetHistogram().GetSampleSum(), "", tags)
+				sender.MonotonicCount(name+".count", float64(metric.GetHistogram().GetSampleCount()), "", tags)
+			case dto.MetricType_SUMMARY:
+				sender.MonotonicCount(name+".sum", metric.GetSummary().GetSampleSum(), "", tags)
+				sender.MonotonicCount(name+".count", float64(metric.GetSummary().GetSampleCount()), "", tags)
+				for _, q := range metric.GetSummary().GetQuantile() {
+					if math.IsNaN(q.GetValue()) {
+						continue
+					}
+					quantileTags := append(append([]string{}, tags...), fmt.Sprintf("quantile:%s", strconv.FormatFloat(q.GetQuantile(), 'f', -1, 64)))
+					sender.Gauge(name+".quantile", q.GetValue(), "", quantileTags)
+				}
+			}
+		}
+	}
+}
+
+func init() {
+	core.RegisterCheck(kubeAPIServerMetricsCheckName, KubeAPIServerMetricsFactory)
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+const testAPIServerMetrics = `# HELP apiserver_current_inflight_requests Maximal number of currently used inflight request limit of this apiserver per request kind in last second.
+# TYPE apiserver_current_inflight_requests gauge
+apiserver_current_inflight_requests{requestKind="mutating"} 2
+apiserver_current_inflight_requests{requestKind="readOnly"} 5
+# HELP apiserver_request_count Counter of apiserver requests broken out for each verb, API resource, client, and HTTP response contentType and code.
+# TYPE apiserver_request_count counter
+apiserver_request_count{client="kubectl",code="200",contentType="application/json",resource="pods",scope="namespace",subresource="",verb="LIST"} 42
+# HELP apiserver_request_latencies Response latency distribution in microseconds for each verb, resource and subresource.
+# TYPE apiserver_request_latencies histogram
+apiserver_request_latencies_bucket{resource="pods",scope="namespace",subresource="",verb="LIST",le="125000"} 10
+apiserver_request_latencies_bucket{resource="pods",scope="namespace",subresource="",verb="LIST",le="+Inf"} 12
+apiserver_request_latencies_sum{resource="pods",scope="namespace",subresource="",verb="LIST"} 1.5e+06
+apiserver_request_latencies_count{resource="pods",scope="namespace",subresource="",verb="LIST"} 12
+# HELP etcd_object_counts Number of stored objects at the time of last check split by kind.
+# TYPE etcd_object_counts gauge
+etcd_object_counts{resource="pods"} 120
+# HELP apiserver_longrunning_gauge Gauge of all active long-running apiserver requests broken out by verb, API resource, and scope.
+# TYPE apiserver_longrunning_gauge gauge
+apiserver_longrunning_gauge{resource="pods",scope="cluster",subresource="",verb="WATCH"} 3
+`
+
+func TestKubeAPIServerMetrics(t *testing.T) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(testAPIServerMetrics))
+	require.NoError(t, err)
+
+	check := KubeAPIServerMetricsFactory().(*KubeAPIServerMetricsCheck)
+	err = check.Configure([]byte("tags: [customtag]\nallowlist: [apiserver_longrunning_gauge]\ndenylist: [etcd_object_counts]"), []byte(""))
+	require.NoError(t, err)
+
+	mocked := mocksender.NewMockSender(check.ID())
+	mocked.SetupAcceptAll()
+	check.submitMetricFamilies(mocked, families)
+
+	mocked.AssertMetric(t, "Gauge", "kube_apiserver.current_inflight_requests", 2, "", []string{"customtag", "requestKind:mutating"})
+	mocked.AssertMetric(t, "Gauge", "kube_apiserver.current_inflight_requests", 5, "", []string{"customtag", "requestKind:readOnly"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.count", 42, "", []string{"customtag", "verb:LIST", "resource:pods", "code:200"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.latencies.sum", 1.5e+06, "", []string{"customtag", "verb:LIST"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.latencies.count", 12, "", []string{"customtag", "verb:LIST"})
+	mocked.AssertMetric(t, "Gauge", "kube_apiserver.apiserver_longrunning_gauge", 3, "", []string{"customtag", "verb:WATCH"})
+	mocked.AssertMetricNotTaggedWith(t, "Gauge", "kube_apiserver.etcd.object_counts", []string{"resource:pods"})
+}
+
+func TestBuildMetricFamilies(t *testing.T) {
+	families := buildMetricFamilies([]string{"custom_family", "etcd_object_counts"}, []string{"apiserver_request_count"})
+	assert.Equal(t, "custom_family", families["custom_family"])
+	assert.Equal(t, "etcd.object_counts", families["etcd_object_counts"])
+	_, found := families["apiserver_request_count"]
+	assert.False(t, found)
+}
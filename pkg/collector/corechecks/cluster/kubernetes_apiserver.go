@@ -39,8 +39,17 @@ type KubeASConfig struct {
 	Tags                     []string `yaml:"tags"`
 	CollectEvent             bool     `yaml:"collect_events"`
 	CollectOShiftQuotas      bool     `yaml:"collect_openshift_clusterquotas"`
+	CollectResourceCounts    bool     `yaml:"collect_resource_counts"`
 	FilteredEventType        []string `yaml:"filtered_event_types"`
 	EventCollectionTimeoutMs int      `yaml:"kubernetes_event_read_timeout_ms"`
+	// Server-side filtering of the events, e.g. "type!=Normal"
+	EventFieldSelector string `yaml:"event_field_selector"`
+	// Client-side filtering of the events, exclusions take precedence over inclusions
+	IncludedEventReasons    []string `yaml:"included_event_reasons"`
+	IncludedEventKinds      []string `yaml:"included_event_kinds"`
+	ExcludedEventKinds      []string `yaml:"excluded_event_kinds"`
+	IncludedEventNamespaces []string `yaml:"included_event_namespaces"`
+	ExcludedEventNamespaces []string `yaml:"excluded_event_namespaces"`
 }
 
 // KubeASCheck grabs metrics and events from the API server.
@@ -103,6 +112,8 @@ func (k *KubeASCheck) Run() error {
 		return err
 	}
 
+	k.reportLeaderElection(sender)
+
 	// API Server client initialisation on first run
 	if k.ac == nil {
 		// We start the API Server Client.
@@ -139,6 +150,14 @@ func (k *KubeASCheck) Run() error {
 		}
 	}
 
+	// Running the object count collection if enabled
+	if k.instance.CollectResourceCounts {
+		err = k.collectResourceCounts(sender)
+		if err != nil {
+			k.Warnf("Could not collect the Kubernetes object counts: %s", err.Error())
+		}
+	}
+
 	// Running the event collection.
 	if !k.instance.CollectEvent {
 		return nil
@@ -154,17 +173,9 @@ func (k *KubeASCheck) Run() error {
 	}
 
 	// Process the events to have a Datadog format.
-	err = k.processEvents(sender, newEvents, false)
-	if err != nil {
-		k.Warnf("Could not submit new event %s", err.Error())
-	}
-	// We send the events in 2 steps to make sure the new events are initializing the aggregation keys and as modified events have a different payload.
-	if len(modifiedEvents) == 0 {
-		return nil
-	}
-	err = k.processEvents(sender, modifiedEvents, true)
+	err = k.processEvents(sender, newEvents, modifiedEvents)
 	if err != nil {
-		k.Warnf("Could not submit modified event %s", err.Error())
+		k.Warnf("Could not submit events %s", err.Error())
 	}
 	return nil
 }
@@ -178,16 +189,21 @@ func KubernetesASFactory() check.Check {
 }
 
 func (k *KubeASCheck) runLeaderElection() error {
+	return runLeaderElection(&k.CheckBase)
+}
 
+// runLeaderElection returns apiserver.ErrNotLeader if the current agent is not the leader,
+// for cluster level checks that must only run once per cluster.
+func runLeaderElection(c *core.CheckBase) error {
 	leaderEngine, err := leaderelection.GetLeaderEngine()
 	if err != nil {
-		k.Warn("Failed to instantiate the Leader Elector. Not running the Kubernetes API Server check or collecting Kubernetes Events.")
+		c.Warnf("Failed to instantiate the Leader Elector. Not running the %s check.", c.String())
 		return err
 	}
 
 	err = leaderEngine.EnsureLeaderElectionRuns()
 	if err != nil {
-		k.Warn("Leader Election process failed to start")
+		c.Warn("Leader Election process failed to start")
 		return err
 	}
 
@@ -198,6 +214,20 @@ func (k *KubeASCheck) runLeaderElection() error {
 	log.Tracef("Current leader: %q, running Kubernetes cluster related checks and collecting events", leaderEngine.GetLeader())
 	return nil
 }
+
+// reportLeaderElection reports for how long the current leader has held the leadership,
+// tagged with its identity, to allow alerting on leadership flapping.
+func (k *KubeASCheck) reportLeaderElection(sender aggregator.Sender) {
+	details, err := leaderelection.GetLeaderDetails()
+	if err != nil {
+		log.Debugf("Could not retrieve the leader election record: %s", err)
+		return
+	}
+	tags := append([]string{fmt.Sprintf("leader:%s", details.Leader)}, k.instance.Tags...)
+	sender.Gauge("kubernetes.leader_election.leadership_duration", details.HeldForSeconds, "", tags)
+	sender.Gauge("kubernetes.leader_election.transitions", float64(details.LeaderTransitions), "", tags)
+}
+
 func (k *KubeASCheck) eventCollectionInit() {
 	if k.latestEventToken == "" {
 		// Initialization: Checking if we previously stored the latestEventToken in a configMap
@@ -224,7 +254,7 @@ func (k *KubeASCheck) eventCollectionInit() {
 func (k *KubeASCheck) eventCollectionCheck() ([]*v1.Event, []*v1.Event, error) {
 	timeout := time.Duration(k.instance.EventCollectionTimeoutMs) * time.Millisecond
 
-	newEvents, modifiedEvents, versionToken, err := k.ac.LatestEvents(k.latestEventToken, timeout)
+	newEvents, modifiedEvents, versionToken, err := k.ac.LatestEvents(k.latestEventToken, timeout, k.instance.EventFieldSelector)
 	if err != nil {
 		k.Warnf("Could not collect events from the api server: %s", err.Error())
 		return nil, nil, err
@@ -232,7 +262,7 @@ func (k *KubeASCheck) eventCollectionCheck() ([]*v1.Event, []*v1.Event, error) {
 
 	if versionToken == "0" {
 		// API server cache expired or no recent events to process. Resetting the Resversion token.
-		_, _, versionToken, err = k.ac.LatestEvents("0", timeout)
+		_, _, versionToken, err = k.ac.LatestEvents("0", timeout, k.instance.EventFieldSelector)
 		if err != nil {
 			k.Warnf("Could not collect cached events from the api server: %s", err.Error())
 			return nil, nil, err
@@ -300,35 +330,37 @@ func (k *KubeASCheck) parseComponentStatus(sender aggregator.Sender, componentsS
 // - iterates over the Kubernetes Events
 // - extracts some attributes and builds a structure ready to be submitted as a Datadog event (bundle)
 // - formats the bundle and submit the Datadog event
-func (k *KubeASCheck) processEvents(sender aggregator.Sender, events []*v1.Event, modified bool) error {
+// New and modified events are bundled together, so that only one Datadog event is submitted per involved object and run.
+func (k *KubeASCheck) processEvents(sender aggregator.Sender, newEvents, modifiedEvents []*v1.Event) error {
 	eventsByObject := make(map[types.UID]*kubernetesEventBundle)
 	filteredByType := make(map[string]int)
 
-	// Only process the events which actions aren't part of the FilteredEventType list in the yaml config.
-ITER_EVENTS:
-	for _, event := range events {
-		for _, action := range k.instance.FilteredEventType {
-			if event.Reason == action {
-				filteredByType[action] = filteredByType[action] + 1
-				continue ITER_EVENTS
+	bundleEvents := func(events []*v1.Event, modified bool) {
+		// Only process the events which are not filtered out by the yaml config.
+		for _, event := range events {
+			if filteredBy, filtered := k.instance.filterEvent(event); filtered {
+				filteredByType[filteredBy] = filteredByType[filteredBy] + 1
+				continue
+			}
+			bundle, found := eventsByObject[event.InvolvedObject.UID]
+			if found == false {
+				bundle = newKubernetesEventBundler(event.InvolvedObject.UID, event.Source.Component)
+				eventsByObject[event.InvolvedObject.UID] = bundle
+			}
+			err := bundle.addEvent(event, modified)
+			if err != nil {
+				k.Warnf("Error while bundling events, %s.", err.Error())
 			}
 		}
-		bundle, found := eventsByObject[event.InvolvedObject.UID]
-		if found == false {
-			bundle = newKubernetesEventBundler(event.InvolvedObject.UID, event.Source.Component)
-			eventsByObject[event.InvolvedObject.UID] = bundle
-		}
-		err := bundle.addEvent(event)
-		if err != nil {
-			k.Warnf("Error while bundling events, %s.", err.Error())
-		}
+	}
+	bundleEvents(newEvents, false)
+	bundleEvents(modifiedEvents, true)
 
-		if len(filteredByType) > 0 {
-			log.Debugf("Filtered out the following events: %s", formatStringIntMap(filteredByType))
-		}
+	if len(filteredByType) > 0 {
+		log.Debugf("Filtered out the following events: %s", formatStringIntMap(filteredByType))
 	}
 	for _, bundle := range eventsByObject {
-		datadogEv, err := bundle.formatEvents(k.KubeAPIServerHostname, modified)
+		datadogEv, err := bundle.formatEvents(k.KubeAPIServerHostname)
 		if err != nil {
 			k.Warnf("Error while formatting bundled events, %s. Not submitting", err.Error())
 			continue
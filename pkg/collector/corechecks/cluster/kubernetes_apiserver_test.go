@@ -170,7 +170,7 @@ func TestProcessBundledEvents(t *testing.T) {
 	mocked := mocksender.NewMockSender(kubeASCheck.ID())
 	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
 
-	kubeASCheck.processEvents(mocked, newKubeEventsBundle, false)
+	kubeASCheck.processEvents(mocked, newKubeEventsBundle, nil)
 
 	// We are only expecting one bundle event.
 	// We need to check that the countByAction concatenated string contains the source events.
@@ -200,7 +200,7 @@ func TestProcessBundledEvents(t *testing.T) {
 	mocked = mocksender.NewMockSender(kubeASCheck.ID())
 	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
 
-	kubeASCheck.processEvents(mocked, modifiedKubeEventsBundle, true)
+	kubeASCheck.processEvents(mocked, nil, modifiedKubeEventsBundle)
 
 	mocked.AssertEvent(t, modifiedNewDatadogEvents, 0)
 	mocked.AssertExpectations(t)
@@ -236,14 +236,14 @@ func TestProcessEvent(t *testing.T) {
 		EventType:      "kubernetes_apiserver",
 	}
 	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
-	kubeASCheck.processEvents(mocked, newKubeEventBundle, false)
+	kubeASCheck.processEvents(mocked, newKubeEventBundle, nil)
 	mocked.AssertEvent(t, newDatadogEvent, 0)
 	mocked.AssertExpectations(t)
 
 	// No events
 	empty := []*v1.Event{}
 	mocked = mocksender.NewMockSender(kubeASCheck.ID())
-	kubeASCheck.processEvents(mocked, empty, false)
+	kubeASCheck.processEvents(mocked, empty, nil)
 	mocked.AssertNotCalled(t, "Event")
 	mocked.AssertExpectations(t)
 
@@ -253,7 +253,66 @@ func TestProcessEvent(t *testing.T) {
 		ev5,
 	}
 	mocked = mocksender.NewMockSender(kubeASCheck.ID())
-	kubeASCheck.processEvents(mocked, filteredKubeEventsBundle, false)
+	kubeASCheck.processEvents(mocked, filteredKubeEventsBundle, nil)
 	mocked.AssertNotCalled(t, "Event")
 	mocked.AssertExpectations(t)
 }
+
+func TestFilterEvent(t *testing.T) {
+	backOff := createEvent(12, "default", "dca-789976f5d7-2ljx6", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "kubelet", "BackOff", "Back-off restarting failed container", 709662600)
+	scheduled := createEvent(1, "kube-system", "dca-789976f5d7-2ljx6", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "default-scheduler", "Scheduled", "Successfully assigned dca-789976f5d7-2ljx6 to ip-10-0-0-54", 709662600)
+	nodeReady := createEvent(1, "", "localhost", "Node", "e63e74fa-f566-11e7-9749-0e4863e1cbf4", "kubelet", "NodeReady", "Node localhost status is now: NodeReady", 709662600)
+
+	for _, tc := range []struct {
+		name     string
+		config   KubeASConfig
+		filtered []*v1.Event
+		kept     []*v1.Event
+	}{
+		{
+			name: "no filter",
+			kept: []*v1.Event{backOff, scheduled, nodeReady},
+		},
+		{
+			name:     "excluded reason",
+			config:   KubeASConfig{FilteredEventType: []string{"BackOff"}},
+			filtered: []*v1.Event{backOff},
+			kept:     []*v1.Event{scheduled, nodeReady},
+		},
+		{
+			name:     "included reason",
+			config:   KubeASConfig{IncludedEventReasons: []string{"Scheduled"}},
+			filtered: []*v1.Event{backOff, nodeReady},
+			kept:     []*v1.Event{scheduled},
+		},
+		{
+			name:     "included kind",
+			config:   KubeASConfig{IncludedEventKinds: []string{"Node"}},
+			filtered: []*v1.Event{backOff, scheduled},
+			kept:     []*v1.Event{nodeReady},
+		},
+		{
+			name:     "excluded kind",
+			config:   KubeASConfig{ExcludedEventKinds: []string{"Node"}},
+			filtered: []*v1.Event{nodeReady},
+			kept:     []*v1.Event{backOff, scheduled},
+		},
+		{
+			name:     "namespaces",
+			config:   KubeASConfig{IncludedEventNamespaces: []string{"default", "kube-system"}, ExcludedEventNamespaces: []string{"kube-system"}},
+			filtered: []*v1.Event{scheduled, nodeReady},
+			kept:     []*v1.Event{backOff},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, event := range tc.filtered {
+				_, filtered := tc.config.filterEvent(event)
+				assert.True(t, filtered, "event %s should be filtered", event.Reason)
+			}
+			for _, event := range tc.kept {
+				_, filtered := tc.config.filterEvent(event)
+				assert.False(t, filtered, "event %s should be kept", event.Reason)
+			}
+		})
+	}
+}
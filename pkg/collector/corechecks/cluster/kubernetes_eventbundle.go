@@ -19,25 +19,30 @@ import (
 )
 
 type kubernetesEventBundle struct {
-	objUid        types.UID      // Unique object Identifier used as the Aggregation key
-	namespace     string         // namespace of the bundle
-	readableKey   string         // Formated key used in the Title in the events
-	component     string         // Used to identify the Kubernetes component which generated the event
-	events        []*v1.Event    // List of events in the bundle
-	timeStamp     float64        // Used for the new events in the bundle to specify when they first occurred
-	lastTimestamp float64        // Used for the modified events in the bundle to specify when they last occurred
-	countByAction map[string]int // Map of count per action to aggregate several events from the same ObjUid in one event
+	objUid        types.UID          // Unique object Identifier used as the Aggregation key
+	namespace     string             // namespace of the bundle
+	readableKey   string             // Formated key used in the Title in the events
+	component     string             // Used to identify the Kubernetes component which generated the event
+	events        []*v1.Event        // List of events in the bundle
+	timeStamp     float64            // Used for the new events in the bundle to specify when they first occurred
+	lastTimestamp float64            // Used for the modified events in the bundle to specify when they last occurred
+	modified      bool               // Whether the bundle contains modified events
+	countByReason map[string]int     // Map of count per reason to aggregate several events from the same ObjUid in one event
+	lastByReason  map[string]float64 // Timestamp of the last occurrence of each reason
+	lastMessage   map[string]string  // Last message seen for each reason
 }
 
 func newKubernetesEventBundler(objUid types.UID, compName string) *kubernetesEventBundle {
 	return &kubernetesEventBundle{
 		objUid:        objUid,
 		component:     compName,
-		countByAction: make(map[string]int),
+		countByReason: make(map[string]int),
+		lastByReason:  make(map[string]float64),
+		lastMessage:   make(map[string]string),
 	}
 }
 
-func (k *kubernetesEventBundle) addEvent(event *v1.Event) error {
+func (k *kubernetesEventBundle) addEvent(event *v1.Event, modified bool) error {
 	// As some fields are optional, we want to avoid evaluating empty values.
 	if event == nil || event.InvolvedObject.Kind == "" {
 		return errors.New("could not retrieve some parent attributes of the event")
@@ -50,16 +55,22 @@ func (k *kubernetesEventBundle) addEvent(event *v1.Event) error {
 	}
 
 	k.events = append(k.events, event)
+	k.modified = k.modified || modified
 	k.namespace = event.InvolvedObject.Namespace
 	k.timeStamp = math.Max(k.timeStamp, float64(event.FirstTimestamp.Unix()))
-	k.lastTimestamp = math.Max(k.timeStamp, float64(event.LastTimestamp.Unix()))
+	k.lastTimestamp = math.Max(k.lastTimestamp, float64(event.LastTimestamp.Unix()))
 
-	k.countByAction[fmt.Sprintf("**%s**: %s\n", event.Reason, event.Message)] += int(event.Count)
+	// Events of a crash looping object mostly differ by their message, only the last one is kept
+	k.countByReason[event.Reason] += int(event.Count)
+	if last := float64(event.LastTimestamp.Unix()); last >= k.lastByReason[event.Reason] {
+		k.lastByReason[event.Reason] = last
+		k.lastMessage[event.Reason] = event.Message
+	}
 	k.readableKey = fmt.Sprintf("%s %s", event.InvolvedObject.Name, event.InvolvedObject.Kind)
 	return nil
 }
 
-func (k *kubernetesEventBundle) formatEvents(hostname string, modified bool) (metrics.Event, error) {
+func (k *kubernetesEventBundle) formatEvents(hostname string) (metrics.Event, error) {
 	if len(k.events) == 0 {
 		return metrics.Event{}, errors.New("no event to export")
 	}
@@ -76,12 +87,16 @@ func (k *kubernetesEventBundle) formatEvents(hostname string, modified bool) (me
 	if k.namespace != "" {
 		output.Tags = append(output.Tags, fmt.Sprintf("namespace:%s", k.namespace))
 	}
-	if modified {
-		output.Text = "%%% \n" + fmt.Sprintf("%s \n _Events emitted by the %s seen at %s_ \n", formatStringIntMap(k.countByAction), k.component, time.Unix(int64(k.lastTimestamp), 0)) + "\n %%%"
+	countByAction := make(map[string]int, len(k.countByReason))
+	for reason, count := range k.countByReason {
+		countByAction[fmt.Sprintf("**%s**: %s\n", reason, k.lastMessage[reason])] = count
+	}
+	if k.modified {
+		output.Text = "%%% \n" + fmt.Sprintf("%s \n _Events emitted by the %s seen at %s_ \n", formatStringIntMap(countByAction), k.component, time.Unix(int64(k.lastTimestamp), 0)) + "\n %%%"
 		output.Ts = int64(k.lastTimestamp)
 		return output, nil
 	}
-	output.Text = "%%% \n" + fmt.Sprintf("%s \n _New events emitted by the %s seen at %s_ \n", formatStringIntMap(k.countByAction), k.component, time.Unix(int64(k.timeStamp), 0)) + "\n %%%"
+	output.Text = "%%% \n" + fmt.Sprintf("%s \n _New events emitted by the %s seen at %s_ \n", formatStringIntMap(countByAction), k.component, time.Unix(int64(k.timeStamp), 0)) + "\n %%%"
 	return output, nil
 }
 
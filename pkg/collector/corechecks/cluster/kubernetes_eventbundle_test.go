@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestEventBundleLastMessage(t *testing.T) {
+	uid := "e6417a7f-f566-11e7-9749-0e4863e1cbf4"
+	ev1 := createEvent(3, "default", "dca-789976f5d7-2ljx6", "Pod", uid, "kubelet", "BackOff", "Back-off restarting failed container (1)", 709662600)
+	ev2 := createEvent(5, "default", "dca-789976f5d7-2ljx6", "Pod", uid, "kubelet", "BackOff", "Back-off restarting failed container (2)", 709662700)
+	ev3 := createEvent(1, "default", "dca-789976f5d7-2ljx6", "Pod", uid, "kubelet", "Pulled", "Container image pulled", 709662650)
+
+	bundle := newKubernetesEventBundler(types.UID(uid), "kubelet")
+	require.NoError(t, bundle.addEvent(ev2, true))
+	require.NoError(t, bundle.addEvent(ev1, false))
+	require.NoError(t, bundle.addEvent(ev3, false))
+
+	assert.Equal(t, 8, bundle.countByReason["BackOff"])
+	assert.Equal(t, "Back-off restarting failed container (2)", bundle.lastMessage["BackOff"])
+
+	event, err := bundle.formatEvents("hostname")
+	require.NoError(t, err)
+	assert.Contains(t, event.Text, "8 **BackOff**: Back-off restarting failed container (2)")
+	assert.Contains(t, event.Text, "1 **Pulled**: Container image pulled")
+	assert.NotContains(t, event.Text, "(1)")
+	// Modified events are in the bundle, the latest timestamp is used
+	assert.Equal(t, int64(709662700), event.Ts)
+}
+
+func TestProcessEventsOneEventPerObject(t *testing.T) {
+	uid := "e6417a7f-f566-11e7-9749-0e4863e1cbf4"
+	newEv := createEvent(1, "default", "dca-789976f5d7-2ljx6", "Pod", uid, "kubelet", "Started", "Started container", 709662600)
+	modifiedEv := createEvent(12, "default", "dca-789976f5d7-2ljx6", "Pod", uid, "kubelet", "BackOff", "Back-off restarting failed container", 709662700)
+	otherEv := createEvent(1, "default", "localhost", "Node", "e63e74fa-f566-11e7-9749-0e4863e1cbf4", "kubelet", "NodeReady", "Node localhost status is now: NodeReady", 709662600)
+
+	kubeASCheck := &KubeASCheck{
+		instance:              &KubeASConfig{},
+		CheckBase:             core.NewCheckBase(kubernetesAPIServerCheckName),
+		KubeAPIServerHostname: "hostname",
+	}
+	mocked := mocksender.NewMockSender(kubeASCheck.ID())
+	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
+
+	kubeASCheck.processEvents(mocked, []*v1.Event{newEv, otherEv}, []*v1.Event{modifiedEv})
+
+	mocked.AssertNumberOfCalls(t, "Event", 2)
+	for _, call := range mocked.Calls {
+		event := call.Arguments.Get(0).(metrics.Event)
+		if event.AggregationKey != "kubernetes_apiserver:"+uid {
+			continue
+		}
+		assert.Contains(t, event.Text, "1 **Started**: Started container")
+		assert.Contains(t, event.Text, "12 **BackOff**: Back-off restarting failed container")
+	}
+}
@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+)
+
+// filterEvent returns true and the reason of the filtering if the event must not be submitted.
+// Exclusions take precedence over inclusions, empty inclusion lists match every event.
+func (c *KubeASConfig) filterEvent(event *v1.Event) (string, bool) {
+	if containsString(c.FilteredEventType, event.Reason) {
+		return fmt.Sprintf("reason:%s", event.Reason), true
+	}
+	if len(c.IncludedEventReasons) > 0 && !containsString(c.IncludedEventReasons, event.Reason) {
+		return fmt.Sprintf("reason:%s", event.Reason), true
+	}
+	kind := event.InvolvedObject.Kind
+	if containsString(c.ExcludedEventKinds, kind) || len(c.IncludedEventKinds) > 0 && !containsString(c.IncludedEventKinds, kind) {
+		return fmt.Sprintf("kind:%s", kind), true
+	}
+	namespace := event.InvolvedObject.Namespace
+	if containsString(c.ExcludedEventNamespaces, namespace) || len(c.IncludedEventNamespaces) > 0 && !containsString(c.IncludedEventNamespaces, namespace) {
+		return fmt.Sprintf("namespace:%s", namespace), true
+	}
+	return "", false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+// resourcesSyncTimeout is how long a run waits for the caches of the informers
+// to sync
+const resourcesSyncTimeout = 10 * time.Second
+
+var errResourcesNotSynced = errors.New("the caches of the Kubernetes objects are not synced, check that the agent can list and watch the pods, nodes, deployments and replicasets")
+
+// resourceListers list the pods, nodes, deployments and replicasets from the
+// caches of informers watching them, instead of listing them from the API
+// server on every run.
+type resourceListers struct {
+	synced      []cache.InformerSynced
+	pods        corelisters.PodLister
+	nodes       corelisters.NodeLister
+	deployments appslisters.DeploymentLister
+	replicaSets appslisters.ReplicaSetLister
+}
+
+// newResourceListers requests the informers of the resources from factory,
+// they are started by apiserver.SyncInformers
+func newResourceListers(factory informers.SharedInformerFactory) *resourceListers {
+	pods := factory.Core().V1().Pods()
+	nodes := factory.Core().V1().Nodes()
+	deployments := factory.Apps().V1().Deployments()
+	replicaSets := factory.Apps().V1().ReplicaSets()
+
+	return &resourceListers{
+		pods:        pods.Lister(),
+		nodes:       nodes.Lister(),
+		deployments: deployments.Lister(),
+		replicaSets: replicaSets.Lister(),
+		synced: []cache.InformerSynced{
+			pods.Informer().HasSynced,
+			nodes.Informer().HasSynced,
+			deployments.Informer().HasSynced,
+			replicaSets.Informer().HasSynced,
+		},
+	}
+}
+
+// collectResourceCounts reports the object counts of the pods, nodes, deployments and
+// replicasets of the cluster, as a lightweight alternative to kube-state-metrics. They
+// are counted from the caches of the informers shared by the consumers of the apiserver,
+// resolved on every run to follow the replacements of the client set.
+func (k *KubeASCheck) collectResourceCounts(sender aggregator.Sender) error {
+	factory, stop := k.ac.InformerFactory()
+	listers := newResourceListers(factory)
+	if !apiserver.SyncInformers(factory, stop, resourcesSyncTimeout, listers.synced...) {
+		return errResourcesNotSynced
+	}
+
+	var errs []string
+
+	pods, err := listers.pods.List(labels.Everything())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("pods: %s", err))
+	} else {
+		k.reportPodCounts(pods, sender)
+	}
+
+	nodes, err := listers.nodes.List(labels.Everything())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("nodes: %s", err))
+	} else {
+		k.reportNodeCounts(nodes, sender)
+	}
+
+	deployments, err := listers.deployments.List(labels.Everything())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("deployments: %s", err))
+	} else {
+		k.reportDeployments(deployments, sender)
+	}
+
+	replicaSets, err := listers.replicaSets.List(labels.Everything())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("replicasets: %s", err))
+	} else {
+		k.reportReplicaSets(replicaSets, sender)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("could not list %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// reportPodCounts reports the number of pods per namespace and phase
+func (k *KubeASCheck) reportPodCounts(pods []*v1.Pod, sender aggregator.Sender) {
+	type podKey struct {
+		namespace string
+		phase     v1.PodPhase
+	}
+	counts := make(map[podKey]int)
+	for _, pod := range pods {
+		counts[podKey{pod.Namespace, pod.Status.Phase}]++
+	}
+	for key, count := range counts {
+		tags := append(k.instance.Tags, fmt.Sprintf("kube_namespace:%s", key.namespace), fmt.Sprintf("pod_phase:%s", strings.ToLower(string(key.phase))))
+		sender.Gauge("kubernetes.pods.count", float64(count), "", tags)
+	}
+}
+
+// reportNodeCounts reports the number of nodes, and the number of nodes per condition and status
+func (k *KubeASCheck) reportNodeCounts(nodes []*v1.Node, sender aggregator.Sender) {
+	type conditionKey struct {
+		condition v1.NodeConditionType
+		status    v1.ConditionStatus
+	}
+	counts := make(map[conditionKey]int)
+	for _, node := range nodes {
+		for _, condition := range node.Status.Conditions {
+			counts[conditionKey{condition.Type, condition.Status}]++
+		}
+	}
+	sender.Gauge("kubernetes.nodes.count", float64(len(nodes)), "", k.instance.Tags)
+	for key, count := range counts {
+		tags := append(k.instance.Tags, fmt.Sprintf("condition:%s", strings.ToLower(string(key.condition))), fmt.Sprintf("status:%s", strings.ToLower(string(key.status))))
+		sender.Gauge("kubernetes.nodes.by_condition", float64(count), "", tags)
+	}
+}
+
+// reportDeployments reports the desired and available replicas of every deployment
+func (k *KubeASCheck) reportDeployments(deployments []*appsv1.Deployment, sender aggregator.Sender) {
+	for _, deploy := range deployments {
+		tags := append(k.instance.Tags, fmt.Sprintf("kube_namespace:%s", deploy.Namespace), fmt.Sprintf("kube_deployment:%s", deploy.Name))
+		desired := int32(1)
+		if deploy.Spec.Replicas != nil {
+			desired = *deploy.Spec.Replicas
+		}
+		sender.Gauge("kubernetes.deployment.replicas_desired", float64(desired), "", tags)
+		sender.Gauge("kubernetes.deployment.replicas_available", float64(deploy.Status.AvailableReplicas), "", tags)
+	}
+	sender.Gauge("kubernetes.deployments.count", float64(len(deployments)), "", k.instance.Tags)
+}
+
+// reportReplicaSets reports the desired and available replicas of every replicaset
+func (k *KubeASCheck) reportReplicaSets(replicaSets []*appsv1.ReplicaSet, sender aggregator.Sender) {
+	for _, rs := range replicaSets {
+		tags := append(k.instance.Tags, fmt.Sprintf("kube_namespace:%s", rs.Namespace), fmt.Sprintf("kube_replica_set:%s", rs.Name))
+		desired := int32(1)
+		if rs.Spec.Replicas != nil {
+			desired = *rs.Spec.Replicas
+		}
+		sender.Gauge("kubernetes.replicaset.replicas_desired", float64(desired), "", tags)
+		sender.Gauge("kubernetes.replicaset.replicas_available", float64(rs.Status.AvailableReplicas), "", tags)
+	}
+	sender.Gauge("kubernetes.replicasets.count", float64(len(replicaSets)), "", k.instance.Tags)
+}
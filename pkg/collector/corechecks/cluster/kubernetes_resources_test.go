@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+func TestReportResourceCounts(t *testing.T) {
+	kubeASCheck := KubernetesASFactory().(*KubeASCheck)
+	err := kubeASCheck.Configure([]byte("tags: [customtag]"), []byte(""))
+	require.NoError(t, err)
+
+	mocked := mocksender.NewMockSender(kubeASCheck.ID())
+	mocked.SetupAcceptAll()
+
+	newPod := func(ns string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+	}
+	kubeASCheck.reportPodCounts([]*v1.Pod{
+		newPod("default", v1.PodRunning),
+		newPod("default", v1.PodRunning),
+		newPod("default", v1.PodPending),
+		newPod("kube-system", v1.PodRunning),
+	}, mocked)
+	mocked.AssertMetric(t, "Gauge", "kubernetes.pods.count", 2, "", []string{"customtag", "kube_namespace:default", "pod_phase:running"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes.pods.count", 1, "", []string{"customtag", "kube_namespace:default", "pod_phase:pending"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes.pods.count", 1, "", []string{"customtag", "kube_namespace:kube-system", "pod_phase:running"})
+
+	newNode := func(ready v1.ConditionStatus) *v1.Node {
+		return &v1.Node{
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	kubeASCheck.reportNodeCounts([]*v1.Node{
+		newNode(v1.ConditionTrue),
+		newNode(v1.ConditionTrue),
+		newNode(v1.ConditionFalse),
+	}, mocked)
+	mocked.AssertMetric(t, "Gauge", "kubernetes.nodes.count", 3, "", []string{"customtag"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes.nodes.by_condition", 2, "", []string{"customtag", "condition:ready", "status:true"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes.nodes.by_condition", 1, "", []string{"customtag", "condition:ready", "status:false"})
+
+	replicas := int32(3)
+	kubeASCheck.reportDeployments([]*appsv1.Deployment{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+		},
+	}, mocked)
+	deployTags := []string{"customtag", "kube_namespace:default", "kube_deployment:nginx"}
+	mocked.AssertMetric(t, "Gauge", "kubernetes.deployment.replicas_desired", 3, "", deployTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes.deployment.replicas_available", 2, "", deployTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes.deployments.count", 1, "", []string{"customtag"})
+
+	kubeASCheck.reportReplicaSets([]*appsv1.ReplicaSet{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx-5d69"},
+			Status:     appsv1.ReplicaSetStatus{AvailableReplicas: 1},
+		},
+	}, mocked)
+	rsTags := []string{"customtag", "kube_namespace:default", "kube_replica_set:nginx-5d69"}
+	mocked.AssertMetric(t, "Gauge", "kubernetes.replicaset.replicas_desired", 1, "", rsTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes.replicaset.replicas_available", 1, "", rsTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes.replicasets.count", 1, "", []string{"customtag"})
+}
+
+func TestResourceListers(t *testing.T) {
+	cl := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"}},
+	)
+	factory := informers.NewSharedInformerFactory(cl, 0)
+	stop := make(chan struct{})
+	defer close(stop)
+	listers := newResourceListers(factory)
+
+	require.True(t, apiserver.SyncInformers(factory, stop, 5*time.Second, listers.synced...), "the caches didn't sync")
+
+	pods, err := listers.pods.List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, pods, 1)
+	nodes, err := listers.nodes.List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	deployments, err := listers.deployments.List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, deployments, 1)
+	replicaSets, err := listers.replicaSets.List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, replicaSets, 0)
+}
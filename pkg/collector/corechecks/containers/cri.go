@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build cri
+
+package containers
+
+import (
+	"fmt"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+	pb "k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/cri"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const criCheckName = "cri"
+
+// CRIConfig holds the config of the check
+type CRIConfig struct {
+	CollectDisk bool     `yaml:"collect_disk"`
+	Tags        []string `yaml:"tags"`
+}
+
+// CRICheck grabs the metrics of the containers of a CRI runtime, e.g.
+// containerd or CRI-O, when the kubelet doesn't use docker. The containers
+// are filtered and tagged like the ones of the docker check.
+type CRICheck struct {
+	core.CheckBase
+	instance *CRIConfig
+	filter   *containers.Filter
+}
+
+// Parse parses the CRICheck config and set default values
+func (c *CRIConfig) Parse(data []byte) error {
+	// default values
+	c.CollectDisk = true
+
+	return yaml.Unmarshal(data, c)
+}
+
+// Configure parses the check configuration and init the check
+func (c *CRICheck) Configure(config, initConfig integration.Data) error {
+	if err := c.instance.Parse(config); err != nil {
+		return err
+	}
+	filter, err := containers.NewFilterFromConfig(containers.MetricsFilter)
+	if err != nil {
+		return err
+	}
+	c.BuildID(config, initConfig)
+	c.filter = filter
+	return nil
+}
+
+// Run executes the check
+func (c *CRICheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	util, err := cri.GetUtil()
+	if err != nil {
+		c.Warnf("Error initialising check: %s", err)
+		return err
+	}
+	if util.Runtime == containers.RuntimeNameDocker {
+		return fmt.Errorf("the runtime of the CRI socket is docker, use the docker check")
+	}
+
+	runtimeContainers, err := util.ListRunningContainers()
+	if err != nil {
+		c.Warnf("Error collecting the containers: %s", err)
+		return err
+	}
+	stats, err := util.ListContainerStats()
+	if err != nil {
+		c.Warnf("Error collecting the container stats: %s", err)
+		return err
+	}
+
+	runtimeTags := append([]string{"runtime:" + util.Runtime}, c.instance.Tags...)
+	sender.Gauge("cri.containers.running.total", float64(len(runtimeContainers)), "", runtimeTags)
+
+	for _, ctr := range runtimeContainers {
+		if c.isExcluded(ctr) {
+			continue
+		}
+		tags, err := tagger.Tag(util.GetEntityID(ctr.Id), true)
+		if err != nil {
+			log.Errorf("Could not collect tags for container %s: %s", ctr.Id, err)
+		}
+		tags = append(tags, runtimeTags...)
+
+		sender.Gauge("cri.uptime", time.Since(time.Unix(0, ctr.CreatedAt)).Seconds(), "", tags)
+		if s, found := stats[ctr.Id]; found {
+			c.submitStats(sender, s, tags)
+		}
+	}
+	return nil
+}
+
+// isExcluded applies the container filters to the name and image of a container
+func (c *CRICheck) isExcluded(ctr *pb.Container) bool {
+	var name, image string
+	if ctr.Metadata != nil {
+		name = ctr.Metadata.Name
+	}
+	if ctr.Image != nil {
+		image = ctr.Image.Image
+	}
+	return c.filter.IsExcluded(name, image)
+}
+
+func (c *CRICheck) submitStats(sender aggregator.Sender, s *pb.ContainerStats, tags []string) {
+	if s.Cpu != nil && s.Cpu.UsageCoreNanoSeconds != nil {
+		sender.Rate("cri.cpu.usage", float64(s.Cpu.UsageCoreNanoSeconds.Value), "", tags)
+	}
+	if s.Memory != nil && s.Memory.WorkingSetBytes != nil {
+		sender.Gauge("cri.mem.working_set", float64(s.Memory.WorkingSetBytes.Value), "", tags)
+	}
+	if !c.instance.CollectDisk || s.WritableLayer == nil {
+		return
+	}
+	if s.WritableLayer.UsedBytes != nil {
+		sender.Gauge("cri.disk.used", float64(s.WritableLayer.UsedBytes.Value), "", tags)
+	}
+	if s.WritableLayer.InodesUsed != nil {
+		sender.Gauge("cri.disk.inodes_used", float64(s.WritableLayer.InodesUsed.Value), "", tags)
+	}
+}
+
+func criFactory() check.Check {
+	return &CRICheck{
+		CheckBase: core.NewCheckBase(criCheckName),
+		instance:  &CRIConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(criCheckName, criFactory)
+}
@@ -45,6 +45,7 @@ type DockerConfig struct {
 	CollectEvent             bool               `yaml:"collect_events"`
 	FilteredEventType        []string           `yaml:"filtered_event_types"`
 	CappedMetrics            map[string]float64 `yaml:"capped_metrics"`
+	BreakdownExclude         []string           `yaml:"breakdown_exclude"`
 }
 
 type containerPerImage struct {
@@ -72,6 +73,7 @@ type DockerCheck struct {
 	dockerHostname              string
 	cappedSender                *cappedSender
 	collectContainerSizeCounter uint64
+	breakdownFilters            []tagFilter
 }
 
 func updateContainerRunningCount(images map[string]*containerPerImage, c *docker.Container) {
@@ -213,19 +215,15 @@ func (d *DockerCheck) Run() error {
 			sender.Gauge("docker.mem.soft_limit", float64(c.SoftMemLimit), "", tags)
 		}
 
-		sender.Rate("docker.io.read_bytes", float64(c.IO.ReadBytes), "", tags)
-		sender.Rate("docker.io.write_bytes", float64(c.IO.WriteBytes), "", tags)
+		d.reportIO(sender, c.IO, tags)
+
+		sender.Gauge("docker.thread.count", float64(c.ThreadCount), "", tags)
+		if c.ThreadLimit > 0 {
+			sender.Gauge("docker.thread.limit", float64(c.ThreadLimit), "", tags)
+		}
 
 		if c.Network != nil {
-			for _, netStat := range c.Network {
-				if netStat.NetworkName == "" {
-					log.Debugf("Ignore network stat with empty name for container %s: %s", c.ID[:12], netStat)
-					continue
-				}
-				ifaceTags := append(tags, fmt.Sprintf("docker_network:%s", netStat.NetworkName))
-				sender.Rate("docker.net.bytes_sent", float64(netStat.BytesSent), "", ifaceTags)
-				sender.Rate("docker.net.bytes_rcvd", float64(netStat.BytesRcvd), "", ifaceTags)
-			}
+			d.reportNetwork(sender, c.Network, tags)
 		}
 
 		if collectingContainerSizeDuringThisRun {
@@ -335,6 +333,11 @@ func (d *DockerCheck) Configure(config, initConfig integration.Data) error {
 	}
 
 	var err error
+	d.breakdownFilters, err = parseTagFilters(d.instance.BreakdownExclude)
+	if err != nil {
+		return err
+	}
+
 	// Use the same hostname as the agent so that host tags (like `availability-zone:us-east-1b`)
 	// are attached to Docker events from this host. The hostname from the docker api may be
 	// different than the agent hostname depending on the environment (like EC2 or GCE).
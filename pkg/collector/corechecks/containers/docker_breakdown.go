@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package containers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+/*
+ * Network metrics are reported per interface and I/O metrics per block
+ * device. The resulting series can be filtered out by their tags with the
+ * `breakdown_exclude` option, e.g. to drop the loop devices.
+ */
+
+// tagFilter matches the tags named name with a value matching value
+type tagFilter struct {
+	name  string
+	value *regexp.Regexp
+}
+
+// parseTagFilters parses a list of `tag_name:regex` filters
+func parseTagFilters(filters []string) ([]tagFilter, error) {
+	var parsed []tagFilter
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid tag filter %q, expected tag_name:regex", filter)
+		}
+		r, err := regexp.Compile(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex in the tag filter %q: %s", filter, err)
+		}
+		parsed = append(parsed, tagFilter{name: parts[0], value: r})
+	}
+	return parsed, nil
+}
+
+// isBreakdownExcluded returns whether a tag of a series matches one of the
+// breakdown_exclude filters
+func (d *DockerCheck) isBreakdownExcluded(tags []string) bool {
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		for _, filter := range d.breakdownFilters {
+			if filter.name == parts[0] && filter.value.MatchString(parts[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reportNetwork submits the network metrics of a container per interface,
+// tagged with its docker network and interface name
+func (d *DockerCheck) reportNetwork(sender aggregator.Sender, network docker.ContainerNetStats, tags []string) {
+	for _, netStat := range network {
+		if netStat.NetworkName == "" {
+			log.Debugf("Ignore network stat with empty name: %v", netStat)
+			continue
+		}
+		ifaceTags := append(copyTags(tags), fmt.Sprintf("docker_network:%s", netStat.NetworkName))
+		if netStat.Interface != "" {
+			ifaceTags = append(ifaceTags, fmt.Sprintf("interface:%s", netStat.Interface))
+		}
+		if d.isBreakdownExcluded(ifaceTags) {
+			continue
+		}
+		sender.Rate("docker.net.bytes_sent", float64(netStat.BytesSent), "", ifaceTags)
+		sender.Rate("docker.net.bytes_rcvd", float64(netStat.BytesRcvd), "", ifaceTags)
+		sender.Rate("docker.net.packets_sent", float64(netStat.PacketsSent), "", ifaceTags)
+		sender.Rate("docker.net.packets_rcvd", float64(netStat.PacketsRcvd), "", ifaceTags)
+		sender.Rate("docker.net.errors_sent", float64(netStat.ErrorsSent), "", ifaceTags)
+		sender.Rate("docker.net.errors_rcvd", float64(netStat.ErrorsRcvd), "", ifaceTags)
+		sender.Rate("docker.net.dropped_sent", float64(netStat.DroppedSent), "", ifaceTags)
+		sender.Rate("docker.net.dropped_rcvd", float64(netStat.DroppedRcvd), "", ifaceTags)
+	}
+}
+
+// reportIO submits the I/O metrics of a container per block device, tagged
+// with the device name. The cgroup totals are sent if no device is listed.
+func (d *DockerCheck) reportIO(sender aggregator.Sender, io *docker.CgroupIOStat, tags []string) {
+	if len(io.Devices) == 0 {
+		sender.Rate("docker.io.read_bytes", float64(io.ReadBytes), "", tags)
+		sender.Rate("docker.io.write_bytes", float64(io.WriteBytes), "", tags)
+		return
+	}
+	for name, dev := range io.Devices {
+		devTags := append(copyTags(tags), fmt.Sprintf("device:%s", name))
+		if d.isBreakdownExcluded(devTags) {
+			continue
+		}
+		sender.Rate("docker.io.read_bytes", float64(dev.ReadBytes), "", devTags)
+		sender.Rate("docker.io.write_bytes", float64(dev.WriteBytes), "", devTags)
+		sender.Rate("docker.io.read_operations", float64(dev.ReadOps), "", devTags)
+		sender.Rate("docker.io.write_operations", float64(dev.WriteOps), "", devTags)
+	}
+}
+
+// copyTags returns a copy of tags with room for the breakdown tags, so that
+// appending to it never alters the tags of the container
+func copyTags(tags []string) []string {
+	return append(make([]string, 0, len(tags)+2), tags...)
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package containers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+func TestParseTagFilters(t *testing.T) {
+	filters, err := parseTagFilters([]string{"device:^loop", "interface:lo:0"})
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+	assert.Equal(t, "device", filters[0].name)
+	assert.Equal(t, "interface", filters[1].name)
+	assert.Equal(t, "lo:0", filters[1].value.String())
+
+	for _, invalid := range []string{"device", ":^loop", "device:["} {
+		_, err = parseTagFilters([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestReportNetworkBreakdown(t *testing.T) {
+	filters, err := parseTagFilters([]string{"interface:^lo$"})
+	require.NoError(t, err)
+	check := &DockerCheck{breakdownFilters: filters}
+	sender := mocksender.NewMockSender("breakdownTest")
+	sender.SetupAcceptAll()
+
+	// Spare capacity, so that appending to the container tags would make
+	// the interfaces share their tags
+	tags := make([]string, 1, 4)
+	tags[0] = "container_name:web"
+	check.reportNetwork(sender, docker.ContainerNetStats{
+		{NetworkName: "bridge", Interface: "eth0", BytesSent: 10, ErrorsRcvd: 2},
+		{NetworkName: "test", Interface: "eth1", BytesRcvd: 20, DroppedSent: 3},
+		{NetworkName: "host", Interface: "lo", BytesSent: 30},
+		{Interface: "eth2", BytesSent: 40},
+	}, tags)
+
+	eth0 := []string{"container_name:web", "docker_network:bridge", "interface:eth0"}
+	eth1 := []string{"container_name:web", "docker_network:test", "interface:eth1"}
+	sender.AssertMetric(t, "Rate", "docker.net.bytes_sent", 10, "", eth0)
+	sender.AssertMetric(t, "Rate", "docker.net.errors_rcvd", 2, "", eth0)
+	sender.AssertMetric(t, "Rate", "docker.net.bytes_rcvd", 20, "", eth1)
+	sender.AssertMetric(t, "Rate", "docker.net.dropped_sent", 3, "", eth1)
+	sender.AssertNumberOfCalls(t, "Rate", 16)
+}
+
+func TestReportIOBreakdown(t *testing.T) {
+	filters, err := parseTagFilters([]string{"device:^loop"})
+	require.NoError(t, err)
+	check := &DockerCheck{breakdownFilters: filters}
+	tags := []string{"container_name:web"}
+
+	sender := mocksender.NewMockSender("breakdownTest")
+	sender.SetupAcceptAll()
+	check.reportIO(sender, &docker.CgroupIOStat{
+		ReadBytes: 1050,
+		Devices: map[string]*docker.DeviceIOStat{
+			"sda":   {ReadBytes: 1000, WriteBytes: 100, ReadOps: 10, WriteOps: 1},
+			"loop0": {ReadBytes: 50},
+		},
+	}, tags)
+	sda := []string{"container_name:web", "device:sda"}
+	sender.AssertMetric(t, "Rate", "docker.io.read_bytes", 1000, "", sda)
+	sender.AssertMetric(t, "Rate", "docker.io.write_bytes", 100, "", sda)
+	sender.AssertMetric(t, "Rate", "docker.io.read_operations", 10, "", sda)
+	sender.AssertMetric(t, "Rate", "docker.io.write_operations", 1, "", sda)
+	sender.AssertNumberOfCalls(t, "Rate", 4)
+
+	// The totals are sent when the devices are unknown
+	sender = mocksender.NewMockSender("breakdownTest")
+	sender.SetupAcceptAll()
+	check.reportIO(sender, &docker.CgroupIOStat{ReadBytes: 1050, WriteBytes: 100}, tags)
+	sender.AssertMetric(t, "Rate", "docker.io.read_bytes", 1050, "", tags)
+	sender.AssertMetric(t, "Rate", "docker.io.write_bytes", 100, "", tags)
+	sender.AssertNumberOfCalls(t, "Rate", 2)
+}
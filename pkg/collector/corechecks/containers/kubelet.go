@@ -0,0 +1,386 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package containers
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeletCheckName = "kubelet_native"
+	// cadvisorPodContainer is the name of the infra container of the pods,
+	// holding their network namespace, in the cAdvisor metrics
+	cadvisorPodContainer = "POD"
+	cadvisorTimeout      = 5 * time.Second
+)
+
+// cadvisorMetric describes how a cAdvisor metric is submitted
+type cadvisorMetric struct {
+	name  string
+	scale float64
+	rate  bool
+	// pod metrics are only reported for the infra container of the pods
+	pod bool
+}
+
+var cadvisorMetrics = map[string]cadvisorMetric{
+	"container_cpu_usage_seconds_total":       {name: "kubernetes.cpu.usage.total", scale: 1e9, rate: true},
+	"container_memory_usage_bytes":            {name: "kubernetes.memory.usage", scale: 1},
+	"container_memory_working_set_bytes":      {name: "kubernetes.memory.working_set", scale: 1},
+	"container_memory_rss":                    {name: "kubernetes.memory.rss", scale: 1},
+	"container_fs_usage_bytes":                {name: "kubernetes.filesystem.usage", scale: 1},
+	"container_fs_limit_bytes":                {name: "kubernetes.filesystem.capacity", scale: 1},
+	"container_network_receive_bytes_total":   {name: "kubernetes.network.rx_bytes", scale: 1, rate: true, pod: true},
+	"container_network_transmit_bytes_total":  {name: "kubernetes.network.tx_bytes", scale: 1, rate: true, pod: true},
+	"container_network_receive_errors_total":  {name: "kubernetes.network.rx_errors", scale: 1, rate: true, pod: true},
+	"container_network_transmit_errors_total": {name: "kubernetes.network.tx_errors", scale: 1, rate: true, pod: true},
+}
+
+// KubeletConfig holds the config of the check
+type KubeletConfig struct {
+	CadvisorPort int      `yaml:"cadvisor_port"`
+	Tags         []string `yaml:"tags"`
+}
+
+// KubeletCheck grabs the node, pod and container stats of the kubelet. It
+// uses the /stats/summary endpoint, and falls back to the cAdvisor metrics
+// exposed by the kubelet, then to the cAdvisor port, on kubelets missing it.
+type KubeletCheck struct {
+	core.CheckBase
+	instance *KubeletConfig
+	filter   *containers.Filter
+}
+
+// podIndex indexes the pods of the kubelet pod list by UID and by namespace/name
+type podIndex struct {
+	byUID  map[string]*kubelet.Pod
+	byName map[string]*kubelet.Pod
+}
+
+// cadvisorSeries identifies the series summed for a container in the cAdvisor
+// metrics, e.g. the per-cpu or per-device ones
+type cadvisorSeries struct {
+	namespace string
+	pod       string
+	container string
+	iface     string
+}
+
+// Parse parses the KubeletCheck config and set default values
+func (c *KubeletConfig) Parse(data []byte) error {
+	// default values
+	c.CadvisorPort = 4194
+
+	return yaml.Unmarshal(data, c)
+}
+
+// Configure parses the check configuration and init the check
+func (c *KubeletCheck) Configure(config, initConfig integration.Data) error {
+	if err := c.instance.Parse(config); err != nil {
+		return err
+	}
+	filter, err := containers.NewFilterFromConfig(containers.MetricsFilter)
+	if err != nil {
+		return err
+	}
+	c.BuildID(config, initConfig)
+	c.filter = filter
+	return nil
+}
+
+// Run executes the check
+func (c *KubeletCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	ku, err := kubelet.GetKubeUtil()
+	if err != nil {
+		c.Warnf("Error initialising check: %s", err)
+		return err
+	}
+	pods, err := ku.GetLocalPodList()
+	if err != nil {
+		c.Warnf("Error collecting the pod list: %s", err)
+		return err
+	}
+	index := newPodIndex(pods)
+
+	summary, err := ku.GetStatsSummary()
+	if err == nil {
+		c.submitSummary(sender, summary, index)
+		return nil
+	}
+	log.Debugf("Cannot get the kubelet stats summary, falling back to cAdvisor: %s", err)
+
+	data, err := ku.GetRawCadvisorMetrics()
+	if err != nil && c.instance.CadvisorPort > 0 {
+		log.Debugf("Cannot get the cAdvisor metrics from the kubelet, falling back to the cAdvisor port: %s", err)
+		data, err = queryCadvisor(ku.GetKubeletHost(), c.instance.CadvisorPort)
+	}
+	if err != nil {
+		c.Warnf("Error collecting the container stats from the kubelet and cAdvisor: %s", err)
+		return err
+	}
+	return c.submitCadvisor(sender, data, index)
+}
+
+// submitSummary submits the node, pod and container stats of /stats/summary
+func (c *KubeletCheck) submitSummary(sender aggregator.Sender, summary *kubelet.Summary, index *podIndex) {
+	node := summary.Node
+	if node.CPU != nil {
+		submitGauge(sender, "kubernetes.node.cpu.usage", node.CPU.UsageNanoCores, c.instance.Tags)
+	}
+	if node.Memory != nil {
+		submitGauge(sender, "kubernetes.node.memory.usage", node.Memory.UsageBytes, c.instance.Tags)
+		submitGauge(sender, "kubernetes.node.memory.working_set", node.Memory.WorkingSetBytes, c.instance.Tags)
+		submitGauge(sender, "kubernetes.node.memory.rss", node.Memory.RSSBytes, c.instance.Tags)
+	}
+	submitNetwork(sender, "kubernetes.node.network", node.Network, c.instance.Tags)
+	submitFs(sender, "kubernetes.node.filesystem", node.Fs, c.instance.Tags)
+	if node.Runtime != nil {
+		submitFs(sender, "kubernetes.node.imagefs", node.Runtime.ImageFs, c.instance.Tags)
+	}
+
+	for _, podStats := range summary.Pods {
+		submitNetwork(sender, "kubernetes.network", podStats.Network, c.podTags(podStats.PodRef.UID))
+
+		pod := index.byUID[podStats.PodRef.UID]
+		if pod == nil {
+			continue
+		}
+		for _, ctr := range podStats.Containers {
+			tags, found := c.containerTags(pod, ctr.Name)
+			if !found {
+				continue
+			}
+			if ctr.CPU != nil {
+				submitRate(sender, "kubernetes.cpu.usage.total", ctr.CPU.UsageCoreNanoSeconds, tags)
+			}
+			if ctr.Memory != nil {
+				submitGauge(sender, "kubernetes.memory.usage", ctr.Memory.UsageBytes, tags)
+				submitGauge(sender, "kubernetes.memory.working_set", ctr.Memory.WorkingSetBytes, tags)
+				submitGauge(sender, "kubernetes.memory.rss", ctr.Memory.RSSBytes, tags)
+			}
+			submitFs(sender, "kubernetes.filesystem", ctr.Rootfs, tags)
+		}
+	}
+}
+
+// submitCadvisor submits the container and pod network stats of a cAdvisor
+// metrics payload, as exposed by the kubelet or by the cAdvisor port
+func (c *KubeletCheck) submitCadvisor(sender aggregator.Sender, data []byte, index *podIndex) error {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error parsing the cAdvisor metrics: %s", err)
+	}
+
+	for familyName, family := range families {
+		metric, found := cadvisorMetrics[familyName]
+		if !found {
+			continue
+		}
+		for series, value := range sumCadvisorSeries(family) {
+			pod := index.byName[series.namespace+"/"+series.pod]
+			if pod == nil || (series.container == cadvisorPodContainer) != metric.pod {
+				continue
+			}
+			var tags []string
+			if metric.pod {
+				tags = c.podTags(pod.Metadata.UID)
+				if series.iface != "" {
+					tags = append(tags, "interface:"+series.iface)
+				}
+			} else if tags, found = c.containerTags(pod, series.container); !found {
+				continue
+			}
+			if metric.rate {
+				sender.Rate(metric.name, value*metric.scale, "", tags)
+			} else {
+				sender.Gauge(metric.name, value*metric.scale, "", tags)
+			}
+		}
+	}
+	return nil
+}
+
+// containerTags returns the tags of a container of a pod, and false if the
+// container is unknown or excluded by the container filters
+func (c *KubeletCheck) containerTags(pod *kubelet.Pod, name string) ([]string, bool) {
+	for _, status := range pod.Status.Containers {
+		if status.Name != name {
+			continue
+		}
+		if status.ID == "" || c.filter.IsExcluded(status.Name, status.Image) {
+			return nil, false
+		}
+		tags, err := tagger.Tag(status.ID, true)
+		if err != nil {
+			log.Errorf("Could not collect tags for container %s: %s", status.ID, err)
+		}
+		return append(tags, c.instance.Tags...), true
+	}
+	return nil, false
+}
+
+// podTags returns the tags of a pod from its UID
+func (c *KubeletCheck) podTags(uid string) []string {
+	tags, err := tagger.Tag(kubelet.PodUIDToEntityName(uid), true)
+	if err != nil {
+		log.Errorf("Could not collect tags for pod %s: %s", uid, err)
+	}
+	return append(tags, c.instance.Tags...)
+}
+
+func newPodIndex(pods []*kubelet.Pod) *podIndex {
+	index := &podIndex{
+		byUID:  make(map[string]*kubelet.Pod, len(pods)),
+		byName: make(map[string]*kubelet.Pod, len(pods)),
+	}
+	for _, pod := range pods {
+		index.byUID[pod.Metadata.UID] = pod
+		index.byName[pod.Metadata.Namespace+"/"+pod.Metadata.Name] = pod
+	}
+	return index
+}
+
+// sumCadvisorSeries sums the samples of a cAdvisor metric family by
+// container and network interface. The cgroups that are not containers of a
+// pod, e.g. the pod or the node ones, have no container name and are skipped.
+func sumCadvisorSeries(family *dto.MetricFamily) map[cadvisorSeries]float64 {
+	sums := make(map[cadvisorSeries]float64)
+	for _, m := range family.GetMetric() {
+		labels := make(map[string]string, len(m.GetLabel()))
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		// Newer cAdvisor versions name these labels container and pod
+		series := cadvisorSeries{
+			namespace: labels["namespace"],
+			pod:       firstNonEmpty(labels["pod_name"], labels["pod"]),
+			container: firstNonEmpty(labels["container_name"], labels["container"]),
+			iface:     labels["interface"],
+		}
+		if series.container == "" || series.pod == "" {
+			continue
+		}
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			sums[series] += m.GetCounter().GetValue()
+		case dto.MetricType_GAUGE:
+			sums[series] += m.GetGauge().GetValue()
+		case dto.MetricType_UNTYPED:
+			sums[series] += m.GetUntyped().GetValue()
+		}
+	}
+	return sums
+}
+
+// queryCadvisor gets the metrics of the cAdvisor port of the kubelet,
+// removed from recent kubelets
+func queryCadvisor(host string, port int) ([]byte, error) {
+	client := http.Client{Timeout: cadvisorTimeout}
+	url := fmt.Sprintf("http://%s:%d/metrics", host, port)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d on %s", resp.StatusCode, url)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func submitGauge(sender aggregator.Sender, metric string, value *uint64, tags []string) {
+	if value != nil {
+		sender.Gauge(metric, float64(*value), "", tags)
+	}
+}
+
+func submitRate(sender aggregator.Sender, metric string, value *uint64, tags []string) {
+	if value != nil {
+		sender.Rate(metric, float64(*value), "", tags)
+	}
+}
+
+// submitNetwork submits the stats of every interface, or of the default one
+// for kubelets not listing them, tagged by interface
+func submitNetwork(sender aggregator.Sender, prefix string, stats *kubelet.NetworkStats, tags []string) {
+	if stats == nil {
+		return
+	}
+	interfaces := stats.Interfaces
+	if len(interfaces) == 0 {
+		interfaces = []kubelet.InterfaceStats{stats.InterfaceStats}
+	}
+	for _, iface := range interfaces {
+		ifaceTags := tags
+		if iface.Name != "" {
+			ifaceTags = append(append([]string{}, tags...), "interface:"+iface.Name)
+		}
+		submitRate(sender, prefix+".rx_bytes", iface.RxBytes, ifaceTags)
+		submitRate(sender, prefix+".tx_bytes", iface.TxBytes, ifaceTags)
+		submitRate(sender, prefix+".rx_errors", iface.RxErrors, ifaceTags)
+		submitRate(sender, prefix+".tx_errors", iface.TxErrors, ifaceTags)
+	}
+}
+
+// submitFs submits the usage of a filesystem, including its usage_pct
+func submitFs(sender aggregator.Sender, prefix string, stats *kubelet.FsStats, tags []string) {
+	if stats == nil {
+		return
+	}
+	submitGauge(sender, prefix+".usage", stats.UsedBytes, tags)
+	submitGauge(sender, prefix+".capacity", stats.CapacityBytes, tags)
+	submitGauge(sender, prefix+".available", stats.AvailableBytes, tags)
+	submitGauge(sender, prefix+".inodes_used", stats.InodesUsed, tags)
+	if stats.UsedBytes != nil && stats.CapacityBytes != nil && *stats.CapacityBytes > 0 {
+		sender.Gauge(prefix+".usage_pct", float64(*stats.UsedBytes)/float64(*stats.CapacityBytes), "", tags)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func kubeletFactory() check.Check {
+	return &KubeletCheck{
+		CheckBase: core.NewCheckBase(kubeletCheckName),
+		instance:  &KubeletConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(kubeletCheckName, kubeletFactory)
+}
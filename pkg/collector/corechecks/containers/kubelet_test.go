@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package containers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+const testSummary = `{
+  "node": {
+    "nodeName": "node-1",
+    "cpu": {"usageNanoCores": 250000000, "usageCoreNanoSeconds": 4000000000000},
+    "memory": {"usageBytes": 2000, "workingSetBytes": 1500, "rssBytes": 1000},
+    "network": {
+      "name": "eth0", "rxBytes": 10, "txBytes": 20,
+      "interfaces": [
+        {"name": "eth0", "rxBytes": 10, "txBytes": 20, "rxErrors": 0, "txErrors": 0},
+        {"name": "cbr0", "rxBytes": 30, "txBytes": 40}
+      ]
+    },
+    "fs": {"availableBytes": 750, "capacityBytes": 1000, "usedBytes": 250, "inodesUsed": 12},
+    "runtime": {"imageFs": {"capacityBytes": 1000, "usedBytes": 500}}
+  },
+  "pods": [
+    {
+      "podRef": {"name": "web-1", "namespace": "default", "uid": "uid-1"},
+      "network": {"name": "eth0", "rxBytes": 100, "txBytes": 200},
+      "containers": [
+        {
+          "name": "nginx",
+          "cpu": {"usageNanoCores": 1000, "usageCoreNanoSeconds": 5000},
+          "memory": {"usageBytes": 300, "workingSetBytes": 200, "rssBytes": 100},
+          "rootfs": {"capacityBytes": 1000, "usedBytes": 100}
+        },
+        {"name": "excluded", "memory": {"usageBytes": 300}}
+      ]
+    }
+  ]
+}`
+
+const testCadvisorMetrics = `# TYPE container_cpu_usage_seconds_total counter
+container_cpu_usage_seconds_total{container_name="nginx",cpu="cpu00",namespace="default",pod_name="web-1"} 1.5
+container_cpu_usage_seconds_total{container_name="nginx",cpu="cpu01",namespace="default",pod_name="web-1"} 0.5
+container_cpu_usage_seconds_total{container_name="",cpu="cpu00",namespace="default",pod_name="web-1"} 10
+container_cpu_usage_seconds_total{container_name="excluded",cpu="cpu00",namespace="default",pod_name="web-1"} 3
+container_cpu_usage_seconds_total{container_name="nginx",cpu="cpu00",namespace="default",pod_name="unknown"} 3
+# TYPE container_memory_rss gauge
+container_memory_rss{container="nginx",namespace="default",pod="web-1"} 100
+container_memory_rss{container="POD",namespace="default",pod="web-1"} 50
+# TYPE container_network_receive_bytes_total counter
+container_network_receive_bytes_total{container_name="POD",interface="eth0",namespace="default",pod_name="web-1"} 100
+container_network_receive_bytes_total{container_name="nginx",interface="eth0",namespace="default",pod_name="web-1"} 100
+`
+
+func newTestKubeletCheck(t *testing.T) (*KubeletCheck, *mocksender.MockSender, *podIndex) {
+	check := kubeletFactory().(*KubeletCheck)
+	require.NoError(t, check.instance.Parse([]byte("tags: [env:test]")))
+	filter, err := containers.NewFilter(nil, []string{"name:excluded"})
+	require.NoError(t, err)
+	check.filter = filter
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+
+	pod := &kubelet.Pod{}
+	pod.Metadata.Name = "web-1"
+	pod.Metadata.Namespace = "default"
+	pod.Metadata.UID = "uid-1"
+	pod.Status.Containers = []kubelet.ContainerStatus{
+		{Name: "nginx", Image: "nginx:latest", ID: "docker://abcdef"},
+		{Name: "excluded", Image: "busybox:latest", ID: "docker://012345"},
+	}
+	return check, sender, newPodIndex([]*kubelet.Pod{pod})
+}
+
+func TestKubeletCheckSummary(t *testing.T) {
+	check, sender, index := newTestKubeletCheck(t)
+	summary := &kubelet.Summary{}
+	require.NoError(t, json.Unmarshal([]byte(testSummary), summary))
+
+	check.submitSummary(sender, summary, index)
+
+	tags := []string{"env:test"}
+	sender.AssertMetric(t, "Gauge", "kubernetes.node.cpu.usage", 250000000, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.node.memory.working_set", 1500, "", tags)
+	sender.AssertMetric(t, "Rate", "kubernetes.node.network.rx_bytes", 10, "", []string{"env:test", "interface:eth0"})
+	sender.AssertMetric(t, "Rate", "kubernetes.node.network.tx_bytes", 40, "", []string{"env:test", "interface:cbr0"})
+	sender.AssertNotCalled(t, "Rate", "kubernetes.node.network.rx_errors", mock.Anything, "", []string{"env:test", "interface:cbr0"})
+	sender.AssertMetric(t, "Gauge", "kubernetes.node.filesystem.usage", 250, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.node.filesystem.available", 750, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.node.filesystem.usage_pct", 0.25, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.node.filesystem.inodes_used", 12, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.node.imagefs.usage_pct", 0.5, "", tags)
+
+	sender.AssertMetric(t, "Rate", "kubernetes.network.rx_bytes", 100, "", []string{"env:test", "interface:eth0"})
+	sender.AssertMetric(t, "Rate", "kubernetes.cpu.usage.total", 5000, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.memory.usage", 300, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.memory.rss", 100, "", tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.filesystem.usage_pct", 0.1, "", tags)
+	sender.AssertNumberOfCalls(t, "Gauge", 18)
+}
+
+func TestKubeletCheckCadvisor(t *testing.T) {
+	check, sender, index := newTestKubeletCheck(t)
+
+	require.NoError(t, check.submitCadvisor(sender, []byte(testCadvisorMetrics), index))
+
+	tags := []string{"env:test"}
+	sender.AssertMetric(t, "Rate", "kubernetes.cpu.usage.total", 2e9, "", tags)
+	sender.AssertNumberOfCalls(t, "Rate", 2)
+	sender.AssertMetric(t, "Gauge", "kubernetes.memory.rss", 100, "", tags)
+	sender.AssertNumberOfCalls(t, "Gauge", 1)
+	sender.AssertMetric(t, "Rate", "kubernetes.network.rx_bytes", 100, "", []string{"env:test", "interface:eth0"})
+
+	require.Error(t, check.submitCadvisor(sender, []byte("not { prometheus"), index))
+}
@@ -10,16 +10,35 @@ package jmx
 import (
 	"fmt"
 	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/jmxfetch"
+	"github.com/DataDog/datadog-agent/pkg/status"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"gopkg.in/yaml.v2"
 )
 
+const (
+	// Delays between the restarts of JMXFetch, doubling from one restart to
+	// the next. It is reset once JMXFetch ran for longer than the maximum.
+	minRestartBackoff = 5 * time.Second
+	maxRestartBackoff = 5 * time.Minute
+	// Period at which the supervisor checks that JMXFetch still calls the agent
+	heartbeatCheckInterval = 30 * time.Second
+)
+
 type runner struct {
-	jmxfetch *jmxfetch.JMXFetch
-	started  bool
+	jmxfetch      *jmxfetch.JMXFetch
+	started       bool
+	stop          chan struct{}           // closed when the runner is stopped
+	m             sync.Mutex              // protects the process from concurrent starts and kills
+	processStatus status.JMXProcessStatus // state of the process, protected by m
+	lastHeartbeat int64                   // unix time in ns of the last call of JMXFetch to the agent API
 }
 
 // checkInstanceCfg lists the config options on the instance against which we make some sanity checks
@@ -50,14 +69,157 @@ func (r *runner) initRunner() {
 }
 
 func (r *runner) startRunner() error {
-	err := r.jmxfetch.Start()
-	if err != nil {
+	r.stop = make(chan struct{})
+	if err := r.start(); err != nil {
 		return err
 	}
 	r.started = true
+	go r.supervise()
 	return nil
 }
 
+// start starts the JMXFetch process and reports it in the status
+func (r *runner) start() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.stopping() {
+		return fmt.Errorf("the jmxfetch runner is stopped")
+	}
+	if err := r.jmxfetch.Start(); err != nil {
+		r.processStatus.LastError = err.Error()
+		status.SetJMXProcessStatus(r.processStatus)
+		return err
+	}
+	r.heartbeat()
+	if r.processStatus.LastStart != 0 {
+		r.processStatus.Restarts++
+	}
+	r.processStatus.Running = true
+	r.processStatus.LastStart = time.Now().Unix()
+	status.SetJMXProcessStatus(r.processStatus)
+	return nil
+}
+
+// supervise watches the JMXFetch process and restarts it, with an exponential
+// backoff, when it exits or when it stopped calling the agent API for longer
+// than jmx_heartbeat_timeout, which means it hangs.
+func (r *runner) supervise() {
+	health := health.Register("jmxfetch")
+	defer health.Deregister()
+
+	heartbeatTimeout := time.Duration(config.Datadog.GetInt("jmx_heartbeat_timeout")) * time.Second
+	heartbeatTicker := time.NewTicker(heartbeatCheckInterval)
+	defer heartbeatTicker.Stop()
+
+	backoff := minRestartBackoff
+	exited := r.wait()
+	var restart <-chan time.Time
+	for {
+		select {
+		case <-health.C:
+		case <-r.stop:
+			return
+		case <-heartbeatTicker.C:
+			if exited == nil || heartbeatTimeout <= 0 {
+				continue
+			}
+			if silence := time.Duration(time.Now().UnixNano() - atomic.LoadInt64(&r.lastHeartbeat)); silence > heartbeatTimeout {
+				log.Warnf("jmxfetch did not call the agent for %s, killing it", silence)
+				if err := r.kill(); err != nil {
+					log.Errorf("failure to kill the jmxfetch process: %s", err)
+				}
+				// don't kill it again while it's exiting
+				r.heartbeat()
+			}
+		case err := <-exited:
+			exited = nil
+			uptime := r.processExited(err)
+			if r.stopping() {
+				return
+			}
+			if uptime > maxRestartBackoff {
+				backoff = minRestartBackoff
+			}
+			log.Warnf("jmxfetch exited: %v, restarting it in %s", err, backoff)
+			restart = time.After(backoff)
+			backoff = nextBackoff(backoff)
+		case <-restart:
+			restart = nil
+			if err := r.start(); err != nil {
+				if r.stopping() {
+					return
+				}
+				log.Errorf("failure to restart jmxfetch: %s, retrying in %s", err, backoff)
+				restart = time.After(backoff)
+				backoff = nextBackoff(backoff)
+				continue
+			}
+			exited = r.wait()
+		}
+	}
+}
+
+// wait returns a channel receiving the result of the JMXFetch process once it exits
+func (r *runner) wait() <-chan error {
+	exited := make(chan error, 1)
+	go func() {
+		exited <- r.jmxfetch.Wait()
+	}()
+	return exited
+}
+
+// processExited reports the end of the JMXFetch process in the status and
+// returns how long it ran
+func (r *runner) processExited(err error) time.Duration {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.processStatus.Running = false
+	if err != nil {
+		r.processStatus.LastError = err.Error()
+	} else {
+		r.processStatus.LastError = "exited with status 0"
+	}
+	status.SetJMXProcessStatus(r.processStatus)
+	return time.Since(time.Unix(r.processStatus.LastStart, 0))
+}
+
+// kill kills the JMXFetch process if it's running
+func (r *runner) kill() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if !r.processStatus.Running {
+		return nil
+	}
+	return r.jmxfetch.Kill()
+}
+
+// heartbeat records that JMXFetch is alive
+func (r *runner) heartbeat() {
+	atomic.StoreInt64(&r.lastHeartbeat, time.Now().UnixNano())
+}
+
+// stopping returns whether the runner is being stopped
+func (r *runner) stopping() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// nextBackoff doubles a restart backoff, up to maxRestartBackoff
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxRestartBackoff {
+		return maxRestartBackoff
+	}
+	return backoff
+}
+
 func (r *runner) configureRunner(instance, initConfig integration.Data) error {
 
 	var initConf checkInitCfg
@@ -110,8 +272,13 @@ func (r *runner) configureRunner(instance, initConfig integration.Data) error {
 }
 
 func (r *runner) stopRunner() error {
-	if r.jmxfetch != nil && r.started {
-		return r.jmxfetch.Kill()
+	if r.jmxfetch == nil || !r.started {
+		return nil
 	}
-	return nil
+	r.m.Lock()
+	if !r.stopping() {
+		close(r.stop)
+	}
+	r.m.Unlock()
+	return r.kill()
 }
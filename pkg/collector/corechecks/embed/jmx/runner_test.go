@@ -9,6 +9,7 @@ package jmx
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -87,3 +88,9 @@ func TestConfigureRunner(t *testing.T) {
 	assert.Contains(t, r.jmxfetch.JavaCustomJarPaths, "foo/")
 	assert.Contains(t, r.jmxfetch.JavaCustomJarPaths, "bar/")
 }
+
+func TestNextBackoff(t *testing.T) {
+	assert.Equal(t, 2*minRestartBackoff, nextBackoff(minRestartBackoff))
+	assert.Equal(t, maxRestartBackoff, nextBackoff(maxRestartBackoff-time.Second))
+	assert.Equal(t, maxRestartBackoff, nextBackoff(maxRestartBackoff))
+}
@@ -84,6 +84,12 @@ func GetScheduledConfigsModificationTimestamp() int64 {
 	return state.getScheduledConfigsModificationTimestamp()
 }
 
+// ReportHeartbeat records a call of JMXFetch to the agent API, telling the
+// supervisor of the process that it doesn't hang.
+func ReportHeartbeat() {
+	state.runner.heartbeat()
+}
+
 // StopJmxfetch stops the jmxfetch process if it is running
 func StopJmxfetch() {
 	err := state.runner.stopRunner()
@@ -45,6 +45,11 @@ func GetCheckFactory(name string) CheckFactory {
 	return f
 }
 
+// commonConfigurer is implemented by the checks embedding CheckBase
+type commonConfigurer interface {
+	CommonConfigure(instance, initConfig integration.Data) error
+}
+
 // GoCheckLoader is a specific loader for checks living in this package
 type GoCheckLoader struct{}
 
@@ -76,6 +81,13 @@ func (gl *GoCheckLoader) Load(config integration.Config) ([]check.Check, error)
 			log.Errorf("core.loader: could not configure check %s: %s", newCheck, err)
 			continue
 		}
+		if common, ok := newCheck.(commonConfigurer); ok {
+			if err := common.CommonConfigure(instance, config.InitConfig); err != nil {
+				errors = append(errors, fmt.Sprintf("Could not configure check %s: %s", newCheck, err))
+				log.Errorf("core.loader: could not configure check %s: %s", newCheck, err)
+				continue
+			}
+		}
 		checks = append(checks, newCheck)
 	}
 
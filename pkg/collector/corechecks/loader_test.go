@@ -111,3 +111,39 @@ func TestLoad(t *testing.T) {
 		t.Fatalf("Expected 0 checks, found: %d", len(lst))
 	}
 }
+
+// FIXTURE
+type TestBaseCheck struct {
+	CheckBase
+}
+
+func (c *TestBaseCheck) Run() error                                         { return nil }
+func (c *TestBaseCheck) Configure(integration.Data, integration.Data) error { return nil }
+
+func TestLoadDedicatedRunner(t *testing.T) {
+	RegisterCheck("base", func() check.Check {
+		return &TestBaseCheck{CheckBase: NewCheckBase("base")}
+	})
+
+	i := []integration.Data{
+		integration.Data("dedicated_runner: true"),
+		integration.Data("foo: bar"),
+	}
+	cc := integration.Config{Name: "base", Instances: i}
+	l, _ := NewGoCheckLoader()
+
+	lst, err := l.Load(cc)
+
+	if err != nil {
+		t.Fatalf("Expected nil error, found: %v", err)
+	}
+	if len(lst) != 2 {
+		t.Fatalf("Expected 2 checks, found: %d", len(lst))
+	}
+	if !check.IsDedicatedRunner(lst[0]) {
+		t.Fatal("Expected the first instance to run on a dedicated runner")
+	}
+	if check.IsDedicatedRunner(lst[1]) {
+		t.Fatal("Expected the second instance to run on the shared runners")
+	}
+}
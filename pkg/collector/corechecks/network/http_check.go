@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	httpCheckName              = "http_check_native"
+	httpDefaultTimeout         = 10
+	httpDefaultStatusCode      = `(1|2|3)\d\d`
+	httpDefaultDaysWarning     = 14
+	httpDefaultDaysCritical    = 7
+	httpMaxContentLength       = 1 << 20
+	httpCanConnectServiceCheck = "http.can_connect"
+	httpSSLCertServiceCheck    = "http.ssl_cert"
+	httpResponseTimeMetric     = "network.http.response_time"
+	httpCanConnectMetric       = "network.http.can_connect"
+	httpCantConnectMetric      = "network.http.cant_connect"
+	httpSSLDaysLeftMetric      = "http.ssl.days_left"
+)
+
+// HTTPCheck requests an URL and reports whether it answered with the expected
+// status code and content, and how long it took to answer. For the HTTPS
+// URLs, it also reports the days left before the certificate expires.
+type HTTPCheck struct {
+	core.CheckBase
+	config       httpInstanceConfig
+	client       *http.Client
+	statusCode   *regexp.Regexp
+	contentMatch *regexp.Regexp
+}
+
+type httpInstanceConfig struct {
+	Name                       string            `yaml:"name"`
+	URL                        string            `yaml:"url"`
+	Method                     string            `yaml:"method"`
+	Data                       string            `yaml:"data"`
+	Headers                    map[string]string `yaml:"headers"`
+	Timeout                    int               `yaml:"timeout"`
+	HTTPResponseStatusCode     string            `yaml:"http_response_status_code"`
+	ContentMatch               string            `yaml:"content_match"`
+	ReverseContentMatch        bool              `yaml:"reverse_content_match"`
+	TLSVerify                  *bool             `yaml:"tls_verify"`
+	TLSCACert                  string            `yaml:"tls_ca_cert"`
+	TLSServerName              string            `yaml:"tls_server_name"`
+	CheckCertificateExpiration *bool             `yaml:"check_certificate_expiration"`
+	DaysWarning                int               `yaml:"days_warning"`
+	DaysCritical               int               `yaml:"days_critical"`
+	Tags                       []string          `yaml:"tags"`
+}
+
+func (c *HTTPCheck) String() string {
+	return httpCheckName
+}
+
+// Configure parses the check configuration and builds the HTTP client
+func (c *HTTPCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var config httpInstanceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	if config.URL == "" {
+		return errors.New("the url of the instance is missing")
+	}
+	if config.Name == "" {
+		config.Name = config.URL
+	}
+	if config.Method == "" {
+		config.Method = http.MethodGet
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = httpDefaultTimeout
+	}
+	if config.HTTPResponseStatusCode == "" {
+		config.HTTPResponseStatusCode = httpDefaultStatusCode
+	}
+	if config.DaysWarning <= 0 {
+		config.DaysWarning = httpDefaultDaysWarning
+	}
+	if config.DaysCritical <= 0 {
+		config.DaysCritical = httpDefaultDaysCritical
+	}
+
+	statusCode, err := regexp.Compile("^(" + config.HTTPResponseStatusCode + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid http_response_status_code: %s", err)
+	}
+	var contentMatch *regexp.Regexp
+	if config.ContentMatch != "" {
+		if contentMatch, err = regexp.Compile(config.ContentMatch); err != nil {
+			return fmt.Errorf("invalid content_match: %s", err)
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.TLSVerify != nil && !*config.TLSVerify,
+		ServerName:         config.TLSServerName,
+	}
+	if config.TLSCACert != "" {
+		ca, err := ioutil.ReadFile(config.TLSCACert)
+		if err != nil {
+			return fmt.Errorf("could not read the tls_ca_cert file: %s", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("could not load the tls_ca_cert file %s", config.TLSCACert)
+		}
+	}
+
+	c.BuildID(data, initConfig)
+	c.config = config
+	c.statusCode = statusCode
+	c.contentMatch = contentMatch
+	c.client = &http.Client{
+		// every run measures the time to open a new connection
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			TLSClientConfig:   tlsConfig,
+			DisableKeepAlives: true,
+		},
+		Timeout: time.Duration(config.Timeout) * time.Second,
+	}
+	return nil
+}
+
+// Run requests the URL and reports its availability. The URL being down
+// isn't an error of the check, it's reported by the http.can_connect
+// service check.
+func (c *HTTPCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	tags := append([]string{}, c.config.Tags...)
+	tags = append(tags, "url:"+c.config.URL, "instance:"+c.config.Name)
+
+	var body io.Reader
+	if c.config.Data != "" {
+		body = strings.NewReader(c.config.Data)
+	}
+	req, err := http.NewRequest(c.config.Method, c.config.URL, body)
+	if err != nil {
+		return err
+	}
+	for name, value := range c.config.Headers {
+		req.Header.Set(name, value)
+	}
+	// net/http uses the Host header instead of the URL host
+	if host, found := c.config.Headers["Host"]; found {
+		req.Host = host
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.reportDown(sender, tags, err.Error())
+		return nil
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, httpMaxContentLength))
+	elapsed := time.Since(start)
+	if err != nil {
+		c.reportDown(sender, tags, fmt.Sprintf("could not read the response: %s", err))
+		return nil
+	}
+
+	if !c.statusCode.MatchString(fmt.Sprint(resp.StatusCode)) {
+		c.reportDown(sender, tags, fmt.Sprintf("Incorrect HTTP return code for url %s. Expected %s, got %d.",
+			c.config.URL, c.config.HTTPResponseStatusCode, resp.StatusCode))
+		return nil
+	}
+	if c.contentMatch != nil && c.contentMatch.Match(content) == c.config.ReverseContentMatch {
+		if c.config.ReverseContentMatch {
+			c.reportDown(sender, tags, fmt.Sprintf("Content %q found in the response", c.config.ContentMatch))
+		} else {
+			c.reportDown(sender, tags, fmt.Sprintf("Content %q not found in the response", c.config.ContentMatch))
+		}
+		return nil
+	}
+
+	sender.Gauge(httpResponseTimeMetric, elapsed.Seconds(), "", tags)
+	sender.Gauge(httpCanConnectMetric, 1, "", tags)
+	sender.Gauge(httpCantConnectMetric, 0, "", tags)
+	sender.ServiceCheck(httpCanConnectServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+
+	if resp.TLS != nil && (c.config.CheckCertificateExpiration == nil || *c.config.CheckCertificateExpiration) {
+		c.checkCertificate(sender, resp.TLS, tags)
+	}
+	return nil
+}
+
+func (c *HTTPCheck) reportDown(sender aggregator.Sender, tags []string, message string) {
+	sender.Gauge(httpCanConnectMetric, 0, "", tags)
+	sender.Gauge(httpCantConnectMetric, 1, "", tags)
+	sender.ServiceCheck(httpCanConnectServiceCheck, metrics.ServiceCheckCritical, "", tags, message)
+}
+
+// checkCertificate reports the days left before the server certificate expires
+func (c *HTTPCheck) checkCertificate(sender aggregator.Sender, state *tls.ConnectionState, tags []string) {
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+	cert := state.PeerCertificates[0]
+	daysLeft := time.Until(cert.NotAfter).Hours() / 24
+	sender.Gauge(httpSSLDaysLeftMetric, daysLeft, "", tags)
+
+	status, message := metrics.ServiceCheckOK, ""
+	switch {
+	case daysLeft < 0:
+		status, message = metrics.ServiceCheckCritical, fmt.Sprintf("The certificate expired on %s", cert.NotAfter)
+	case daysLeft < float64(c.config.DaysCritical):
+		status, message = metrics.ServiceCheckCritical, fmt.Sprintf("The certificate expires in %.0f days", daysLeft)
+	case daysLeft < float64(c.config.DaysWarning):
+		status, message = metrics.ServiceCheckWarning, fmt.Sprintf("The certificate expires in %.0f days", daysLeft)
+	}
+	sender.ServiceCheck(httpSSLCertServiceCheck, status, "", tags, message)
+}
+
+func httpFactory() check.Check {
+	return &HTTPCheck{
+		CheckBase: core.NewCheckBase(httpCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(httpCheckName, httpFactory)
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newHTTPTestHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Write([]byte(`{"status": "healthy"}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+}
+
+func runHTTPCheck(t *testing.T, instance string) *mocksender.MockSender {
+	check := httpFactory().(*HTTPCheck)
+	require.NoError(t, check.Configure([]byte(instance), nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	return sender
+}
+
+func TestHTTPCheck(t *testing.T) {
+	ts := httptest.NewServer(newHTTPTestHandler())
+	defer ts.Close()
+
+	url := ts.URL + "/health"
+	sender := runHTTPCheck(t, fmt.Sprintf("name: health\nurl: %s\ncontent_match: healthy\ntags: [env:test]", url))
+	tags := []string{"env:test", "url:" + url, "instance:health"}
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertMetric(t, "Gauge", "network.http.can_connect", 1, "", tags)
+	sender.AssertMetric(t, "Gauge", "network.http.cant_connect", 0, "", tags)
+	sender.AssertMetricTaggedWith(t, "Gauge", "network.http.response_time", tags)
+	sender.AssertNotCalled(t, "Gauge", "http.ssl.days_left", mock.Anything, "", mock.Anything)
+
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s\ncontent_match: healthy\nreverse_content_match: true", url))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckCritical, "", nil, `Content "healthy" found in the response`)
+
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s\ncontent_match: unhealthy", url))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckCritical, "", nil, `Content "unhealthy" not found in the response`)
+
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s/down", ts.URL))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckCritical, "", nil,
+		fmt.Sprintf(`Incorrect HTTP return code for url %s/down. Expected (1|2|3)\d\d, got 503.`, ts.URL))
+	sender.AssertMetric(t, "Gauge", "network.http.cant_connect", 1, "", nil)
+
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s/down\nhttp_response_status_code: 503", ts.URL))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckOK, "", nil, "")
+}
+
+func TestHTTPCheckTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(newHTTPTestHandler())
+	defer ts.Close()
+	url := ts.URL + "/health"
+
+	// the certificate of the test server isn't trusted by default
+	sender := runHTTPCheck(t, "url: "+url)
+	sender.AssertMetric(t, "Gauge", "network.http.can_connect", 0, "", nil)
+
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s\ntls_verify: false", url))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckOK, "", nil, "")
+
+	ca, err := ioutil.TempFile("", "http-check-ca")
+	require.NoError(t, err)
+	defer os.Remove(ca.Name())
+	require.NoError(t, pem.Encode(ca, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	ca.Close()
+
+	// the certificate of the test server is valid for example.com
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s\ntls_ca_cert: %s\ntls_server_name: example.com", url, ca.Name()))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckOK, "", nil, "")
+	sender.AssertMetricTaggedWith(t, "Gauge", "http.ssl.days_left", []string{"url:" + url})
+	sender.AssertServiceCheck(t, "http.ssl_cert", metrics.ServiceCheckOK, "", nil, "")
+
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s\ntls_ca_cert: %s\ntls_server_name: datadoghq.com", url, ca.Name()))
+	sender.AssertMetric(t, "Gauge", "network.http.can_connect", 0, "", nil)
+}
+
+func TestHTTPCheckInvalidConfig(t *testing.T) {
+	check := httpFactory().(*HTTPCheck)
+	assert.Error(t, check.Configure([]byte("name: missing"), nil))
+	assert.Error(t, check.Configure([]byte("url: http://localhost\ncontent_match: \"(\""), nil))
+	assert.Error(t, check.Configure([]byte("url: http://localhost\ntls_ca_cert: /does/not/exist"), nil))
+}
@@ -8,7 +8,9 @@ package network
 import (
 	"expvar"
 	"fmt"
-	"math/rand"
+	"math"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -22,10 +24,17 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
-const ntpCheckName = "ntp"
+const (
+	ntpCheckName = "ntp"
+	// offsets deviating from the median by more than outlierFactor times
+	// their median absolute deviation are ignored
+	outlierFactor = 3
+)
 
 var (
 	ntpExpVar = expvar.NewFloat("ntpOffset")
+	// servers queried when none is configured
+	defaultHosts = []string{"0.datadog.pool.ntp.org", "1.datadog.pool.ntp.org", "2.datadog.pool.ntp.org", "3.datadog.pool.ntp.org"}
 	// for testing purpose
 	ntpQuery = ntp.Query
 )
@@ -38,12 +47,13 @@ type NTPCheck struct {
 }
 
 type ntpInstanceConfig struct {
-	OffsetThreshold       int    `yaml:"offset_threshold"`
-	Host                  string `yaml:"host"`
-	Port                  string `yaml:"port"`
-	Timeout               int    `yaml:"timeout"`
-	Version               int    `yaml:"version"`
-	MinCollectionInterval int    `yaml:"min_collection_interval"`
+	OffsetThreshold       int      `yaml:"offset_threshold"`
+	Host                  string   `yaml:"host"`
+	Hosts                 []string `yaml:"hosts"`
+	Port                  string   `yaml:"port"`
+	Timeout               int      `yaml:"timeout"`
+	Version               int      `yaml:"version"`
+	MinCollectionInterval int      `yaml:"min_collection_interval"`
 }
 
 type ntpInitConfig struct{}
@@ -75,8 +85,12 @@ func (c *ntpConfig) parse(data []byte, initData []byte) error {
 	}
 
 	c.instance = instance
-	if c.instance.Host == "" {
-		c.instance.Host = fmt.Sprintf("%d.datadog.pool.ntp.org", rand.Intn(3))
+	if len(c.instance.Hosts) == 0 {
+		if c.instance.Host != "" {
+			c.instance.Hosts = []string{c.instance.Host}
+		} else {
+			c.instance.Hosts = defaultHosts
+		}
 	}
 	if c.instance.Port == "" {
 		c.instance.Port = defaultPort
@@ -130,12 +144,13 @@ func (c *NTPCheck) Run() error {
 	serviceCheckMessage := ""
 	offsetThreshold := c.cfg.instance.OffsetThreshold
 
-	response, err := ntpQuery(c.cfg.instance.Host, c.cfg.instance.Version)
-	if err != nil {
-		log.Infof("There was an error querying the ntp host: %s", err)
+	offsets := c.queryOffsets()
+	if len(offsets) == 0 {
 		serviceCheckStatus = metrics.ServiceCheckUnknown
+		serviceCheckMessage = "None of the ntp servers responded"
 	} else {
-		clockOffset = int(response.ClockOffset.Seconds())
+		offset := medianOffset(offsets)
+		clockOffset = int(offset)
 		if clockOffset > offsetThreshold {
 			serviceCheckStatus = metrics.ServiceCheckCritical
 			serviceCheckMessage = fmt.Sprintf("Offset %v secs higher than offset threshold (%v secs)", clockOffset, offsetThreshold)
@@ -143,11 +158,13 @@ func (c *NTPCheck) Run() error {
 			serviceCheckStatus = metrics.ServiceCheckOK
 		}
 
-		sender.Gauge("ntp.offset", response.ClockOffset.Seconds(), "", nil)
-		ntpExpVar.Set(response.ClockOffset.Seconds())
+		sender.Gauge("ntp.offset", offset, "", nil)
+		ntpExpVar.Set(offset)
 	}
 
 	sender.ServiceCheck("ntp.in_sync", serviceCheckStatus, "", nil, serviceCheckMessage)
+	sender.ServiceCheck("ntp.servers_responding", respondingStatus(len(offsets), len(c.cfg.instance.Hosts)), "", nil,
+		fmt.Sprintf("%d of %d ntp servers responded", len(offsets), len(c.cfg.instance.Hosts)))
 
 	c.lastCollection = time.Now()
 
@@ -156,6 +173,81 @@ func (c *NTPCheck) Run() error {
 	return nil
 }
 
+// queryOffsets concurrently queries the configured servers and returns the
+// clock offsets, in seconds, reported by the ones that responded
+func (c *NTPCheck) queryOffsets() []float64 {
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	offsets := make([]float64, 0, len(c.cfg.instance.Hosts))
+
+	for _, host := range c.cfg.instance.Hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			response, err := ntpQuery(host, c.cfg.instance.Version)
+			if err != nil {
+				log.Infof("There was an error querying the ntp host %s: %s", host, err)
+				return
+			}
+			m.Lock()
+			offsets = append(offsets, response.ClockOffset.Seconds())
+			m.Unlock()
+		}(host)
+	}
+	wg.Wait()
+
+	return offsets
+}
+
+// medianOffset returns the median of the offsets, ignoring the outliers
+// deviating from it by more than outlierFactor times the median absolute
+// deviation of the offsets
+func medianOffset(offsets []float64) float64 {
+	center := median(offsets)
+
+	deviations := make([]float64, len(offsets))
+	for i, offset := range offsets {
+		deviations[i] = math.Abs(offset - center)
+	}
+	maxDeviation := outlierFactor * median(deviations)
+
+	kept := make([]float64, 0, len(offsets))
+	for i, offset := range offsets {
+		if deviations[i] <= maxDeviation {
+			kept = append(kept, offset)
+		} else {
+			log.Debugf("Ignoring the outlier ntp offset %vs, the median being %vs", offset, center)
+		}
+	}
+	return median(kept)
+}
+
+// median returns the median of a non-empty list of values
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// respondingStatus returns the status of the ntp.servers_responding service
+// check: OK when most servers responded, WARNING when half or less of them
+// did and CRITICAL when none did.
+func respondingStatus(responding int, total int) metrics.ServiceCheckStatus {
+	switch {
+	case responding == 0:
+		return metrics.ServiceCheckCritical
+	case 2*responding <= total:
+		return metrics.ServiceCheckWarning
+	default:
+		return metrics.ServiceCheckOK
+	}
+}
+
 func ntpFactory() check.Check {
 	return &NTPCheck{
 		CheckBase: core.NewCheckBase(ntpCheckName),
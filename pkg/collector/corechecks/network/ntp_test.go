@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/beevik/ntp"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
@@ -57,13 +58,19 @@ func TestNTPOK(t *testing.T) {
 		"",
 		[]string(nil),
 		"").Return().Times(1)
+	mockSender.On("ServiceCheck",
+		"ntp.servers_responding",
+		metrics.ServiceCheckOK,
+		"",
+		[]string(nil),
+		"4 of 4 ntp servers responded").Return().Times(1)
 
 	mockSender.On("Commit").Return().Times(1)
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
 	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
-	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
+	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 2)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
 
@@ -87,13 +94,19 @@ func TestNTPCritical(t *testing.T) {
 		"",
 		[]string(nil),
 		"Offset 100 secs higher than offset threshold (60 secs)").Return().Times(1)
+	mockSender.On("ServiceCheck",
+		"ntp.servers_responding",
+		metrics.ServiceCheckOK,
+		"",
+		[]string(nil),
+		"4 of 4 ntp servers responded").Return().Times(1)
 
 	mockSender.On("Commit").Return().Times(1)
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
 	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
-	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
+	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 2)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
 
@@ -115,12 +128,80 @@ func TestNTPError(t *testing.T) {
 		"",
 		[]string(nil),
 		mock.AnythingOfType("string")).Return().Times(1)
+	mockSender.On("ServiceCheck",
+		"ntp.servers_responding",
+		metrics.ServiceCheckCritical,
+		"",
+		[]string(nil),
+		"0 of 4 ntp servers responded").Return().Times(1)
 
 	mockSender.On("Commit").Return().Times(1)
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
 	mockSender.AssertNumberOfCalls(t, "Gauge", 0)
-	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
+	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 2)
+	mockSender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestNTPMultipleServers(t *testing.T) {
+	var ntpCfg = []byte(`
+hosts: [a.ntp, b.ntp, c.ntp, d.ntp, e.ntp]
+`)
+	var ntpInitCfg = []byte("")
+
+	offsets := map[string]float64{"a.ntp": 1, "b.ntp": 2, "c.ntp": 3, "d.ntp": 3600}
+	ntpQuery = func(host string, version int) (*ntp.Response, error) {
+		offset, found := offsets[host]
+		if !found {
+			return nil, fmt.Errorf("test error from NTP")
+		}
+		return &ntp.Response{
+			ClockOffset: time.Duration(offset) * time.Second,
+		}, nil
+	}
+	defer func() { ntpQuery = ntp.Query }()
+
+	ntpCheck := new(NTPCheck)
+	ntpCheck.Configure(ntpCfg, ntpInitCfg)
+
+	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+
+	// d.ntp is an outlier, e.ntp does not respond
+	mockSender.On("Gauge", "ntp.offset", float64(2), "", []string(nil)).Return().Times(1)
+	mockSender.On("ServiceCheck",
+		"ntp.in_sync",
+		metrics.ServiceCheckOK,
+		"",
+		[]string(nil),
+		"").Return().Times(1)
+	mockSender.On("ServiceCheck",
+		"ntp.servers_responding",
+		metrics.ServiceCheckOK,
+		"",
+		[]string(nil),
+		"4 of 5 ntp servers responded").Return().Times(1)
+
+	mockSender.On("Commit").Return().Times(1)
+	ntpCheck.Run()
+
+	mockSender.AssertExpectations(t)
+	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 2)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
+
+func TestMedianOffset(t *testing.T) {
+	assert.Equal(t, float64(5), medianOffset([]float64{5}))
+	assert.Equal(t, float64(1.5), medianOffset([]float64{2, 1}))
+	assert.Equal(t, float64(1), medianOffset([]float64{1, 1, 1, 50}))
+	assert.Equal(t, float64(-2), medianOffset([]float64{-1, -2, -3, 100, -2}))
+}
+
+func TestRespondingStatus(t *testing.T) {
+	assert.Equal(t, metrics.ServiceCheckOK, respondingStatus(3, 4))
+	assert.Equal(t, metrics.ServiceCheckWarning, respondingStatus(2, 4))
+	assert.Equal(t, metrics.ServiceCheckWarning, respondingStatus(1, 3))
+	assert.Equal(t, metrics.ServiceCheckCritical, respondingStatus(0, 4))
+	assert.Equal(t, metrics.ServiceCheckOK, respondingStatus(1, 1))
+}
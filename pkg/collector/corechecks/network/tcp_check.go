@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	tcpCheckName              = "tcp_check_native"
+	tcpDefaultTimeout         = 10
+	tcpCanConnectServiceCheck = "tcp.can_connect"
+	tcpResponseTimeMetric     = "network.tcp.response_time"
+	tcpCanConnectMetric       = "network.tcp.can_connect"
+)
+
+// TCPCheck opens a TCP connection to a host and reports whether it succeeded,
+// and how long it took if collect_response_time is set
+type TCPCheck struct {
+	core.CheckBase
+	config tcpInstanceConfig
+}
+
+type tcpInstanceConfig struct {
+	Name                string   `yaml:"name"`
+	Host                string   `yaml:"host"`
+	Port                int      `yaml:"port"`
+	Timeout             int      `yaml:"timeout"`
+	CollectResponseTime bool     `yaml:"collect_response_time"`
+	Tags                []string `yaml:"tags"`
+}
+
+func (c *TCPCheck) String() string {
+	return tcpCheckName
+}
+
+// Configure parses the check configuration
+func (c *TCPCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var config tcpInstanceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	if config.Host == "" {
+		return errors.New("the host of the instance is missing")
+	}
+	if config.Port <= 0 || config.Port > 65535 {
+		return fmt.Errorf("invalid port %d", config.Port)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = tcpDefaultTimeout
+	}
+	if config.Name == "" {
+		config.Name = net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	}
+
+	c.BuildID(data, initConfig)
+	c.config = config
+	return nil
+}
+
+// Run connects to the host, the host being down isn't an error of the
+// check, it's reported by the tcp.can_connect service check
+func (c *TCPCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	tags := append([]string{}, c.config.Tags...)
+	tags = append(tags, "target_host:"+c.config.Host, "port:"+strconv.Itoa(c.config.Port), "instance:"+c.config.Name)
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port)), time.Duration(c.config.Timeout)*time.Second)
+	if err != nil {
+		sender.Gauge(tcpCanConnectMetric, 0, "", tags)
+		sender.ServiceCheck(tcpCanConnectServiceCheck, metrics.ServiceCheckCritical, "", tags, err.Error())
+		return nil
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+
+	if c.config.CollectResponseTime {
+		sender.Gauge(tcpResponseTimeMetric, elapsed.Seconds(), "", tags)
+	}
+	sender.Gauge(tcpCanConnectMetric, 1, "", tags)
+	sender.ServiceCheck(tcpCanConnectServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+	return nil
+}
+
+func tcpFactory() check.Check {
+	return &TCPCheck{
+		CheckBase: core.NewCheckBase(tcpCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(tcpCheckName, tcpFactory)
+}
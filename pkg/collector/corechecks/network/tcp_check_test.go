@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestTCPCheck(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+
+	check := tcpFactory().(*TCPCheck)
+	instance := fmt.Sprintf("name: local\nhost: 127.0.0.1\nport: %d\ncollect_response_time: true\ntags: [env:test]", port)
+	require.NoError(t, check.Configure([]byte(instance), nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+
+	require.NoError(t, check.Run())
+	tags := []string{"env:test", "target_host:127.0.0.1", fmt.Sprintf("port:%d", port), "instance:local"}
+	sender.AssertServiceCheck(t, "tcp.can_connect", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertMetric(t, "Gauge", "network.tcp.can_connect", 1, "", tags)
+	sender.AssertMetricTaggedWith(t, "Gauge", "network.tcp.response_time", tags)
+
+	// nothing listens on the port anymore
+	l.Close()
+	sender.ResetCalls()
+	require.NoError(t, check.Run())
+	sender.AssertMetric(t, "Gauge", "network.tcp.can_connect", 0, "", tags)
+	sender.AssertNotCalled(t, "ServiceCheck", "tcp.can_connect", metrics.ServiceCheckOK, "", mocksender.MatchTagsContains(tags), "")
+}
+
+func TestTCPCheckInvalidConfig(t *testing.T) {
+	check := tcpFactory().(*TCPCheck)
+	assert.Error(t, check.Configure([]byte("port: 80"), nil))
+	assert.Error(t, check.Configure([]byte("host: localhost"), nil))
+	assert.Error(t, check.Configure([]byte("host: localhost\nport: 70000"), nil))
+}
@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	tlsCheckName                  = "tls_native"
+	tlsDefaultPort                = 443
+	tlsDefaultTimeout             = 10
+	tlsDefaultDaysWarning         = 14
+	tlsDefaultDaysCritical        = 7
+	tlsCanConnectServiceCheck     = "tls.can_connect"
+	tlsCertValidationServiceCheck = "tls.cert_validation"
+	tlsCertExpirationServiceCheck = "tls.cert_expiration"
+	tlsDaysLeftMetric             = "tls.days_left"
+	tlsChainDaysLeftMetric        = "tls.chain.days_left"
+)
+
+// TLSCheck connects to a TLS endpoint, validates the certificate chain it
+// presents and reports the days left before the certificates expire
+type TLSCheck struct {
+	core.CheckBase
+	config tlsInstanceConfig
+	roots  *x509.CertPool // nil to use the system pool
+}
+
+type tlsInstanceConfig struct {
+	Name          string   `yaml:"name"`
+	Host          string   `yaml:"host"`
+	Port          int      `yaml:"port"`
+	Timeout       int      `yaml:"timeout"`
+	TLSVerify     *bool    `yaml:"tls_verify"`
+	TLSCACert     string   `yaml:"tls_ca_cert"`
+	TLSServerName string   `yaml:"tls_server_name"`
+	DaysWarning   int      `yaml:"days_warning"`
+	DaysCritical  int      `yaml:"days_critical"`
+	Tags          []string `yaml:"tags"`
+}
+
+func (c *TLSCheck) String() string {
+	return tlsCheckName
+}
+
+// Configure parses the check configuration and loads the custom CA
+func (c *TLSCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var config tlsInstanceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	if config.Host == "" {
+		return errors.New("the host of the instance is missing")
+	}
+	if config.Port == 0 {
+		config.Port = tlsDefaultPort
+	}
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("invalid port %d", config.Port)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = tlsDefaultTimeout
+	}
+	if config.TLSServerName == "" {
+		config.TLSServerName = config.Host
+	}
+	if config.DaysWarning <= 0 {
+		config.DaysWarning = tlsDefaultDaysWarning
+	}
+	if config.DaysCritical <= 0 {
+		config.DaysCritical = tlsDefaultDaysCritical
+	}
+	if config.Name == "" {
+		config.Name = net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	}
+
+	var roots *x509.CertPool
+	if config.TLSCACert != "" {
+		ca, err := ioutil.ReadFile(config.TLSCACert)
+		if err != nil {
+			return fmt.Errorf("could not read the tls_ca_cert file: %s", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("could not load the tls_ca_cert file %s", config.TLSCACert)
+		}
+	}
+
+	c.BuildID(data, initConfig)
+	c.config = config
+	c.roots = roots
+	return nil
+}
+
+// Run connects to the endpoint and checks its certificates. The endpoint
+// being down or presenting invalid certificates isn't an error of the check,
+// it's reported by the service checks.
+func (c *TLSCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	tags := append([]string{}, c.config.Tags...)
+	tags = append(tags, "target_host:"+c.config.Host, "port:"+strconv.Itoa(c.config.Port),
+		"server_name:"+c.config.TLSServerName, "instance:"+c.config.Name)
+
+	// the chain is verified once connected, to report the expiration of the
+	// certificates even when they're invalid
+	dialer := &net.Dialer{Timeout: time.Duration(c.config.Timeout) * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port)), &tls.Config{
+		ServerName:         c.config.TLSServerName,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		sender.ServiceCheck(tlsCanConnectServiceCheck, metrics.ServiceCheckCritical, "", tags, err.Error())
+		return nil
+	}
+	certs := conn.ConnectionState().PeerCertificates
+	conn.Close()
+	sender.ServiceCheck(tlsCanConnectServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+
+	if len(certs) == 0 {
+		sender.ServiceCheck(tlsCertValidationServiceCheck, metrics.ServiceCheckCritical, "", tags, "The server presented no certificate")
+		return nil
+	}
+	if c.config.TLSVerify == nil || *c.config.TLSVerify {
+		if err := c.verify(certs); err != nil {
+			sender.ServiceCheck(tlsCertValidationServiceCheck, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("The certificate chain is invalid: %s", err))
+		} else {
+			sender.ServiceCheck(tlsCertValidationServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+		}
+	}
+	c.checkExpiration(sender, certs, tags)
+	return nil
+}
+
+// verify validates the chain presented by the server for the server name
+func (c *TLSCheck) verify(certs []*x509.Certificate) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       c.config.TLSServerName,
+		Roots:         c.roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// checkExpiration reports the days left before the server certificate, and
+// the earliest expiring certificate of the chain it presented, expire
+func (c *TLSCheck) checkExpiration(sender aggregator.Sender, certs []*x509.Certificate, tags []string) {
+	daysLeft := time.Until(certs[0].NotAfter).Hours() / 24
+	sender.Gauge(tlsDaysLeftMetric, daysLeft, "", tags)
+
+	chainExpiration := certs[0].NotAfter
+	for _, cert := range certs[1:] {
+		if cert.NotAfter.Before(chainExpiration) {
+			chainExpiration = cert.NotAfter
+		}
+	}
+	sender.Gauge(tlsChainDaysLeftMetric, time.Until(chainExpiration).Hours()/24, "", tags)
+
+	status, message := metrics.ServiceCheckOK, ""
+	switch {
+	case daysLeft < 0:
+		status, message = metrics.ServiceCheckCritical, fmt.Sprintf("The certificate expired on %s", certs[0].NotAfter)
+	case daysLeft < float64(c.config.DaysCritical):
+		status, message = metrics.ServiceCheckCritical, fmt.Sprintf("The certificate expires in %.0f days", daysLeft)
+	case daysLeft < float64(c.config.DaysWarning):
+		status, message = metrics.ServiceCheckWarning, fmt.Sprintf("The certificate expires in %.0f days", daysLeft)
+	}
+	sender.ServiceCheck(tlsCertExpirationServiceCheck, status, "", tags, message)
+}
+
+func tlsFactory() check.Check {
+	return &TLSCheck{
+		CheckBase: core.NewCheckBase(tlsCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(tlsCheckName, tlsFactory)
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func runTLSCheck(t *testing.T, instance string) *mocksender.MockSender {
+	check := tlsFactory().(*TLSCheck)
+	require.NoError(t, check.Configure([]byte(instance), nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	return sender
+}
+
+func TestTLSCheck(t *testing.T) {
+	ts := httptest.NewTLSServer(newHTTPTestHandler())
+	defer ts.Close()
+	host, port, err := net.SplitHostPort(ts.Listener.Addr().String())
+	require.NoError(t, err)
+	endpoint := fmt.Sprintf("host: %s\nport: %s\ntags: [env:test]", host, port)
+
+	ca, err := ioutil.TempFile("", "tls-check-ca")
+	require.NoError(t, err)
+	defer os.Remove(ca.Name())
+	require.NoError(t, pem.Encode(ca, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	ca.Close()
+
+	// the certificate of the test server isn't trusted by default
+	tags := []string{"env:test", "target_host:" + host, "port:" + port, "server_name:" + host}
+	sender := runTLSCheck(t, endpoint)
+	sender.AssertServiceCheck(t, "tls.can_connect", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertServiceCheck(t, "tls.cert_validation", metrics.ServiceCheckCritical, "", tags, mock.Anything)
+	sender.AssertMetricTaggedWith(t, "Gauge", "tls.days_left", tags)
+	sender.AssertMetricTaggedWith(t, "Gauge", "tls.chain.days_left", tags)
+	sender.AssertServiceCheck(t, "tls.cert_expiration", metrics.ServiceCheckOK, "", tags, "")
+
+	// the certificate of the test server is valid for example.com
+	sender = runTLSCheck(t, fmt.Sprintf("%s\ntls_ca_cert: %s\ntls_server_name: example.com", endpoint, ca.Name()))
+	sender.AssertServiceCheck(t, "tls.cert_validation", metrics.ServiceCheckOK, "", []string{"server_name:example.com"}, "")
+
+	sender = runTLSCheck(t, fmt.Sprintf("%s\ntls_ca_cert: %s\ntls_server_name: datadoghq.com", endpoint, ca.Name()))
+	sender.AssertServiceCheck(t, "tls.cert_validation", metrics.ServiceCheckCritical, "", []string{"server_name:datadoghq.com"}, mock.Anything)
+
+	// the certificate expires in less than days_warning days
+	sender = runTLSCheck(t, fmt.Sprintf("%s\ntls_verify: false\ndays_warning: 1000000", endpoint))
+	sender.AssertNotCalled(t, "ServiceCheck", "tls.cert_validation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertServiceCheck(t, "tls.cert_expiration", metrics.ServiceCheckWarning, "", tags, mock.Anything)
+
+	// nothing listens on the port anymore
+	ts.Close()
+	sender = runTLSCheck(t, endpoint)
+	sender.AssertServiceCheck(t, "tls.can_connect", metrics.ServiceCheckCritical, "", tags, mock.Anything)
+	sender.AssertNotCalled(t, "Gauge", "tls.days_left", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTLSCheckInvalidConfig(t *testing.T) {
+	check := tlsFactory().(*TLSCheck)
+	assert.Error(t, check.Configure([]byte("port: 443"), nil))
+	assert.Error(t, check.Configure([]byte("host: localhost\nport: 70000"), nil))
+	assert.Error(t, check.Configure([]byte("host: localhost\ntls_ca_cert: /does/not/exist"), nil))
+}
@@ -0,0 +1,385 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package openmetrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+const (
+	openmetricsCheckName             = "openmetrics_native"
+	openmetricsDefaultMaxMetrics     = 2000
+	openmetricsDefaultTimeout        = 10
+	openmetricsAcceptHeader          = `text/plain;version=0.0.4;q=0.9,*/*;q=0.1`
+	openmetricsHealthServiceCheckFmt = "%s.prometheus.health"
+)
+
+// OpenMetricsCheck scrapes a Prometheus or OpenMetrics endpoint and submits
+// the metric families of its metrics option, renamed if asked to:
+//  - the counters as monotonic counts and the gauges as gauges,
+//  - the histograms as the monotonic counts of their sum, count and buckets,
+//  - the summaries as the monotonic counts of their sum and count, plus a
+//    gauge per quantile.
+type OpenMetricsCheck struct {
+	core.CheckBase
+	config   openmetricsInstanceConfig
+	names    map[string]string
+	patterns []metricPattern
+	client   *http.Client
+}
+
+type openmetricsInstanceConfig struct {
+	PrometheusURL string `yaml:"prometheus_url"`
+	Namespace     string `yaml:"namespace"`
+	// names or globs of the metric families to collect, or maps renaming them
+	Metrics               []interface{}        `yaml:"metrics"`
+	LabelsMapper          map[string]string    `yaml:"labels_mapper"`
+	ExcludeLabels         []string             `yaml:"exclude_labels"`
+	LabelJoins            map[string]labelJoin `yaml:"label_joins"`
+	SendHistogramsBuckets *bool                `yaml:"send_histograms_buckets"`
+	MaxReturnedMetrics    int                  `yaml:"max_returned_metrics"`
+	Timeout               int                  `yaml:"timeout"`
+	SSLVerify             *bool                `yaml:"ssl_verify"`
+	Headers               map[string]string    `yaml:"headers"`
+	Tags                  []string             `yaml:"tags"`
+}
+
+// labelJoin adds the labels_to_get of the series of a target metric to the
+// series of the other metrics having the same value for label_to_match
+type labelJoin struct {
+	LabelToMatch string   `yaml:"label_to_match"`
+	LabelsToGet  []string `yaml:"labels_to_get"`
+}
+
+type metricPattern struct {
+	glob string
+	name string
+}
+
+// joinedTags holds the tags to add per target metric, then per value of the matched label
+type joinedTags map[string]map[string][]string
+
+func (c *OpenMetricsCheck) String() string {
+	return openmetricsCheckName
+}
+
+// Configure parses the check configuration and builds the metric mappings
+func (c *OpenMetricsCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var config openmetricsInstanceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	if config.PrometheusURL == "" {
+		return errors.New("the prometheus_url of the instance is missing")
+	}
+	if config.Namespace == "" {
+		return errors.New("the namespace of the instance is missing")
+	}
+	if len(config.Metrics) == 0 {
+		return errors.New("the instance has no metrics to collect")
+	}
+	if config.MaxReturnedMetrics <= 0 {
+		config.MaxReturnedMetrics = openmetricsDefaultMaxMetrics
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = openmetricsDefaultTimeout
+	}
+
+	names, patterns, err := parseMetricMappings(config.Metrics)
+	if err != nil {
+		return err
+	}
+
+	// the proxies and TLS options of the agent apply to the scrapes
+	transport := util.CreateHTTPTransport()
+	if config.SSLVerify != nil && !*config.SSLVerify {
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	c.BuildID(data, initConfig)
+	c.config = config
+	c.names = names
+	c.patterns = patterns
+	c.client = &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+	}
+	return nil
+}
+
+// parseMetricMappings returns the submitted names of the metric families by
+// family name, and the globs to match the other families against
+func parseMetricMappings(entries []interface{}) (map[string]string, []metricPattern, error) {
+	names := make(map[string]string)
+	var patterns []metricPattern
+	add := func(family, name string) error {
+		if _, err := path.Match(family, ""); err != nil {
+			return fmt.Errorf("invalid metric %q: %s", family, err)
+		}
+		if isGlob(family) {
+			patterns = append(patterns, metricPattern{glob: family, name: name})
+		} else {
+			names[family] = name
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		switch e := entry.(type) {
+		case string:
+			if err := add(e, ""); err != nil {
+				return nil, nil, err
+			}
+		case map[interface{}]interface{}:
+			for family, name := range e {
+				if err := add(fmt.Sprint(family), fmt.Sprint(name)); err != nil {
+					return nil, nil, err
+				}
+			}
+		default:
+			return nil, nil, fmt.Errorf("invalid metric %v, expected a name or a mapping", entry)
+		}
+	}
+	return names, patterns, nil
+}
+
+func isGlob(name string) bool {
+	for _, r := range name {
+		switch r {
+		case '*', '?', '[':
+			return true
+		}
+	}
+	return false
+}
+
+// metricName returns the name to submit a metric family with, and whether it is collected
+func (c *OpenMetricsCheck) metricName(family string) (string, bool) {
+	name, found := c.names[family]
+	if !found {
+		for _, p := range c.patterns {
+			if matched, _ := path.Match(p.glob, family); matched {
+				name, found = p.name, true
+				break
+			}
+		}
+	}
+	if !found {
+		return "", false
+	}
+	if name == "" {
+		name = family
+	}
+	return c.config.Namespace + "." + name, true
+}
+
+// Run scrapes the endpoint and submits the collected metric families
+func (c *OpenMetricsCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	serviceCheck := fmt.Sprintf(openmetricsHealthServiceCheckFmt, c.config.Namespace)
+	families, err := c.scrape()
+	if err != nil {
+		sender.ServiceCheck(serviceCheck, metrics.ServiceCheckCritical, "", c.config.Tags, err.Error())
+		return err
+	}
+	sender.ServiceCheck(serviceCheck, metrics.ServiceCheckOK, "", c.config.Tags, "")
+
+	c.submitMetricFamilies(sender, families)
+	return nil
+}
+
+// scrape fetches and parses the metric families of the endpoint
+func (c *OpenMetricsCheck) scrape() (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequest("GET", c.config.PrometheusURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", openmetricsAcceptHeader)
+	for name, value := range c.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not scrape %s: %s", c.config.PrometheusURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, c.config.PrometheusURL)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the metrics of %s: %s", c.config.PrometheusURL, err)
+	}
+	return families, nil
+}
+
+// submitMetricFamilies submits the series of the collected families, up to
+// max_returned_metrics of them
+func (c *OpenMetricsCheck) submitMetricFamilies(sender aggregator.Sender, families map[string]*dto.MetricFamily) {
+	joins := c.getJoinedTags(families)
+	sendBuckets := c.config.SendHistogramsBuckets == nil || *c.config.SendHistogramsBuckets
+
+	// sort the families for the same series to be dropped at every run
+	familyNames := make([]string, 0, len(families))
+	for familyName := range families {
+		familyNames = append(familyNames, familyName)
+	}
+	sort.Strings(familyNames)
+
+	submitted := 0
+	for _, familyName := range familyNames {
+		name, collected := c.metricName(familyName)
+		if !collected {
+			continue
+		}
+		family := families[familyName]
+		for _, metric := range family.Metric {
+			if submitted >= c.config.MaxReturnedMetrics {
+				c.Warnf("Reached the limit of %d metrics, increase max_returned_metrics to collect the other ones", c.config.MaxReturnedMetrics)
+				return
+			}
+			submitted++
+
+			tags := c.getTags(metric, joins)
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				sender.MonotonicCount(name, metric.GetCounter().GetValue(), "", tags)
+			case dto.MetricType_GAUGE:
+				sender.Gauge(name, metric.GetGauge().GetValue(), "", tags)
+			case dto.MetricType_UNTYPED:
+				sender.Gauge(name, metric.GetUntyped().GetValue(), "", tags)
+			case dto.MetricType_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				sender.MonotonicCount(name+".sum", histogram.GetSampleSum(), "", tags)
+				sender.MonotonicCount(name+".count", float64(histogram.GetSampleCount()), "", tags)
+				if !sendBuckets {
+					continue
+				}
+				for _, bucket := range histogram.GetBucket() {
+					bucketTags := append(append([]string{}, tags...), "upper_bound:"+formatFloat(bucket.GetUpperBound()))
+					sender.MonotonicCount(name+".count", float64(bucket.GetCumulativeCount()), "", bucketTags)
+				}
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+				sender.MonotonicCount(name+".sum", summary.GetSampleSum(), "", tags)
+				sender.MonotonicCount(name+".count", float64(summary.GetSampleCount()), "", tags)
+				for _, q := range summary.GetQuantile() {
+					if math.IsNaN(q.GetValue()) {
+						continue
+					}
+					quantileTags := append(append([]string{}, tags...), "quantile:"+formatFloat(q.GetQuantile()))
+					sender.Gauge(name+".quantile", q.GetValue(), "", quantileTags)
+				}
+			}
+		}
+	}
+}
+
+// getJoinedTags returns the tags of the label_joins, from the series of their target metrics
+func (c *OpenMetricsCheck) getJoinedTags(families map[string]*dto.MetricFamily) joinedTags {
+	joins := make(joinedTags, len(c.config.LabelJoins))
+	for target, join := range c.config.LabelJoins {
+		family, found := families[target]
+		if !found {
+			continue
+		}
+		values := make(map[string][]string)
+		for _, metric := range family.Metric {
+			labels := make(map[string]string, len(metric.Label))
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			value, found := labels[join.LabelToMatch]
+			if !found {
+				continue
+			}
+			for _, name := range join.LabelsToGet {
+				if v, found := labels[name]; found {
+					values[value] = append(values[value], c.tagName(name)+":"+v)
+				}
+			}
+		}
+		joins[target] = values
+	}
+	return joins
+}
+
+// getTags returns the instance tags, the labels of the series except the
+// excluded ones and the tags joined from other metrics
+func (c *OpenMetricsCheck) getTags(metric *dto.Metric, joins joinedTags) []string {
+	tags := append([]string{}, c.config.Tags...)
+	for _, label := range metric.Label {
+		if c.isExcluded(label.GetName()) {
+			continue
+		}
+		tags = append(tags, c.tagName(label.GetName())+":"+label.GetValue())
+	}
+	for target, join := range c.config.LabelJoins {
+		for _, label := range metric.Label {
+			if label.GetName() == join.LabelToMatch {
+				tags = append(tags, joins[target][label.GetValue()]...)
+			}
+		}
+	}
+	return tags
+}
+
+// tagName returns the tag name of a label, renamed by the labels_mapper
+func (c *OpenMetricsCheck) tagName(label string) string {
+	if name, found := c.config.LabelsMapper[label]; found {
+		return name
+	}
+	return label
+}
+
+func (c *OpenMetricsCheck) isExcluded(label string) bool {
+	for _, excluded := range c.config.ExcludeLabels {
+		if label == excluded {
+			return true
+		}
+	}
+	return false
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func openmetricsFactory() check.Check {
+	return &OpenMetricsCheck{
+		CheckBase: core.NewCheckBase(openmetricsCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(openmetricsCheckName, openmetricsFactory)
+}
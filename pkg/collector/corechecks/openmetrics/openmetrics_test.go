@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const openmetricsPayload = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200",pod="web-1"} 1027
+http_requests_total{method="post",code="400",pod="web-1"} 3
+# HELP queue_size The size of the queue.
+# TYPE queue_size gauge
+queue_size{queue="jobs"} 12
+# TYPE kube_pod_info gauge
+kube_pod_info{pod="web-1",node="node-a"} 1
+# HELP request_duration_seconds The request latencies.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 24054
+request_duration_seconds_bucket{le="0.5"} 129389
+request_duration_seconds_bucket{le="+Inf"} 144320
+request_duration_seconds_sum 53423
+request_duration_seconds_count 144320
+# HELP rpc_duration_seconds The RPC latencies.
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773
+rpc_duration_seconds{quantile="0.99"} NaN
+rpc_duration_seconds_sum 1.7560473e+07
+rpc_duration_seconds_count 2693
+# TYPE ignored_metric gauge
+ignored_metric 1
+`
+
+const openmetricsInstance = `
+prometheus_url: %s
+namespace: app
+metrics:
+  - http_requests_total: requests
+  - queue_*
+  - request_duration_seconds
+  - rpc_duration_seconds
+labels_mapper:
+  code: status_code
+exclude_labels:
+  - method
+label_joins:
+  kube_pod_info:
+    label_to_match: pod
+    labels_to_get:
+      - node
+tags:
+  - env:test
+`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept"), "text/plain")
+		w.Write([]byte(openmetricsPayload))
+	}))
+}
+
+func TestOpenMetricsCheck(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	check := openmetricsFactory().(*OpenMetricsCheck)
+	require.NoError(t, check.Configure([]byte(fmt.Sprintf(openmetricsInstance, ts.URL)), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckOK, "", []string{"env:test"}, "")
+	sender.AssertMetric(t, "MonotonicCount", "app.requests", 1027, "", []string{"env:test", "status_code:200", "pod:web-1", "node:node-a"})
+	sender.AssertMetric(t, "MonotonicCount", "app.requests", 3, "", []string{"status_code:400"})
+	sender.AssertMetricNotTaggedWith(t, "MonotonicCount", "app.requests", []string{"method:post"})
+	sender.AssertMetric(t, "Gauge", "app.queue_size", 12, "", []string{"queue:jobs"})
+
+	sender.AssertMetric(t, "MonotonicCount", "app.request_duration_seconds.sum", 53423, "", []string{"env:test"})
+	sender.AssertMetric(t, "MonotonicCount", "app.request_duration_seconds.count", 144320, "", []string{"env:test"})
+	sender.AssertMetric(t, "MonotonicCount", "app.request_duration_seconds.count", 24054, "", []string{"upper_bound:0.1"})
+	sender.AssertMetric(t, "MonotonicCount", "app.request_duration_seconds.count", 144320, "", []string{"upper_bound:+Inf"})
+
+	sender.AssertMetric(t, "MonotonicCount", "app.rpc_duration_seconds.sum", 1.7560473e+07, "", nil)
+	sender.AssertMetric(t, "MonotonicCount", "app.rpc_duration_seconds.count", 2693, "", nil)
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.quantile", 4773, "", []string{"quantile:0.5"})
+	sender.AssertMetricNotTaggedWith(t, "Gauge", "app.rpc_duration_seconds.quantile", []string{"quantile:0.99"})
+
+	sender.AssertNotCalled(t, "Gauge", "app.ignored_metric", mock.Anything, "", mock.Anything)
+	sender.AssertNotCalled(t, "Gauge", "app.kube_pod_info", mock.Anything, "", mock.Anything)
+}
+
+func TestOpenMetricsCheckMaxReturnedMetrics(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	check := openmetricsFactory().(*OpenMetricsCheck)
+	instance := fmt.Sprintf(openmetricsInstance, ts.URL) + "max_returned_metrics: 2\nsend_histograms_buckets: false\n"
+	require.NoError(t, check.Configure([]byte(instance), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	// the families are sorted, the two http_requests_total series are the first ones
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 2)
+	sender.AssertNotCalled(t, "Gauge", "app.queue_size", mock.Anything, "", mock.Anything)
+	assert.Len(t, check.GetWarnings(), 1)
+}
+
+func TestOpenMetricsCheckScrapeError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	check := openmetricsFactory().(*OpenMetricsCheck)
+	require.NoError(t, check.Configure([]byte(fmt.Sprintf(openmetricsInstance, ts.URL)), nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+
+	assert.Error(t, check.Run())
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckCritical, "", []string{"env:test"}, fmt.Sprintf("unexpected status code 500 from %s", ts.URL))
+}
+
+func TestOpenMetricsCheckInvalidConfig(t *testing.T) {
+	check := openmetricsFactory().(*OpenMetricsCheck)
+	assert.Error(t, check.Configure([]byte("namespace: app\nmetrics: [up]"), nil))
+	assert.Error(t, check.Configure([]byte("prometheus_url: http://localhost:9090/metrics\nmetrics: [up]"), nil))
+	assert.Error(t, check.Configure([]byte("prometheus_url: http://localhost:9090/metrics\nnamespace: app"), nil))
+	assert.Error(t, check.Configure([]byte("prometheus_url: http://localhost:9090/metrics\nnamespace: app\nmetrics: [\"up[\"]"), nil))
+}
+
+func TestOpenMetricsCheckTransport(t *testing.T) {
+	check := openmetricsFactory().(*OpenMetricsCheck)
+	require.NoError(t, check.Configure([]byte("prometheus_url: https://localhost:9090/metrics\nnamespace: app\nmetrics: [up]"), nil))
+	transport := check.client.Transport.(*http.Transport)
+	require.NotNil(t, transport.TLSClientConfig)
+	assert.False(t, transport.TLSClientConfig.InsecureSkipVerify)
+
+	check = openmetricsFactory().(*OpenMetricsCheck)
+	require.NoError(t, check.Configure([]byte("prometheus_url: https://localhost:9090/metrics\nnamespace: app\nmetrics: [up]\nssl_verify: false"), nil))
+	transport = check.client.Transport.(*http.Transport)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+}
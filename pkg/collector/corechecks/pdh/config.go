@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package pdh
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+)
+
+const defaultTagBy = "instance"
+
+// CounterSetConfig declares counters of a counter set (performance object)
+// and the metrics to report them as. The counters of the sets having
+// instances are reported per instance, tagged with tag_by, for the instances
+// matching one of instances_include and none of instances_exclude.
+type CounterSetConfig struct {
+	CounterSetName   string              `yaml:"countersetname"`
+	Metrics          []CounterConfig     `yaml:"metrics"`
+	InstancesInclude []string            `yaml:"instances_include"`
+	InstancesExclude []string            `yaml:"instances_exclude"`
+	TagBy            string              `yaml:"tag_by"`
+	InstanceTags     map[string][]string `yaml:"instance_tags"`
+	Tags             []string            `yaml:"tags"`
+}
+
+// CounterConfig maps a counter to a metric, submitted as a gauge by default
+type CounterConfig struct {
+	Counter string `yaml:"counter"`
+	Name    string `yaml:"name"`
+	// gauge, rate or monotonic_count
+	Type string `yaml:"type"`
+}
+
+// counterSet is a validated CounterSetConfig
+type counterSet struct {
+	CounterSetConfig
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// parseInstance returns the counter set of an instance, nil for the
+// instances of the checks only collecting their builtin counter sets
+func parseInstance(data []byte) (*counterSet, error) {
+	var config CounterSetConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if config.CounterSetName == "" && len(config.Metrics) == 0 {
+		return nil, nil
+	}
+	return newCounterSet(config)
+}
+
+func newCounterSet(config CounterSetConfig) (*counterSet, error) {
+	if config.CounterSetName == "" {
+		return nil, errors.New("the countersetname is missing")
+	}
+	if len(config.Metrics) == 0 {
+		return nil, fmt.Errorf("no metrics declared for the counter set %s", config.CounterSetName)
+	}
+	for _, metric := range config.Metrics {
+		if metric.Counter == "" || metric.Name == "" {
+			return nil, fmt.Errorf("a metric of the counter set %s has no counter or name", config.CounterSetName)
+		}
+		switch metric.Type {
+		case "", "gauge", "rate", "monotonic_count":
+		default:
+			return nil, fmt.Errorf("unsupported type %q for the metric %s", metric.Type, metric.Name)
+		}
+	}
+	if config.TagBy == "" {
+		config.TagBy = defaultTagBy
+	}
+
+	set := &counterSet{CounterSetConfig: config}
+	var err error
+	if set.include, err = compileAll(config.InstancesInclude); err != nil {
+		return nil, fmt.Errorf("invalid instances_include: %s", err)
+	}
+	if set.exclude, err = compileAll(config.InstancesExclude); err != nil {
+		return nil, fmt.Errorf("invalid instances_exclude: %s", err)
+	}
+	return set, nil
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		regexps = append(regexps, re)
+	}
+	return regexps, nil
+}
+
+// keepInstance returns whether the counters of an instance are collected
+func (s *counterSet) keepInstance(instance string) bool {
+	for _, re := range s.exclude {
+		if re.MatchString(instance) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, re := range s.include {
+		if re.MatchString(instance) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTags returns the tags of the values of an instance, an empty instance
+// being the value of a counter set without instances
+func (s *counterSet) getTags(instance string) []string {
+	tags := append([]string{}, s.Tags...)
+	if instance == "" {
+		return tags
+	}
+	tags = append(tags, s.TagBy+":"+instance)
+	return append(tags, s.InstanceTags[instance]...)
+}
+
+// submit sends the value of a counter with the type of its metric
+func submit(sender aggregator.Sender, metric CounterConfig, value float64, tags []string) {
+	switch metric.Type {
+	case "rate":
+		sender.Rate(metric.Name, value, "", tags)
+	case "monotonic_count":
+		sender.MonotonicCount(metric.Name, value, "", tags)
+	default:
+		sender.Gauge(metric.Name, value, "", tags)
+	}
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package pdh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+const testInstance = `
+countersetname: LogicalDisk
+metrics:
+  - counter: Disk Reads/sec
+    name: disk.reads
+  - counter: Split IO/Sec
+    name: disk.split_io
+    type: rate
+instances_include:
+  - "^[A-Z]:$"
+instances_exclude:
+  - "^D:$"
+tag_by: drive
+instance_tags:
+  "C:":
+    - system_drive
+tags:
+  - env:test
+`
+
+func TestParseInstance(t *testing.T) {
+	set, err := parseInstance([]byte(testInstance))
+	require.NoError(t, err)
+	require.NotNil(t, set)
+	assert.Equal(t, "LogicalDisk", set.CounterSetName)
+	assert.Len(t, set.Metrics, 2)
+
+	assert.True(t, set.keepInstance("C:"))
+	assert.False(t, set.keepInstance("D:"))
+	assert.False(t, set.keepInstance("_Total"))
+	assert.False(t, set.keepInstance("HarddiskVolume1"))
+
+	assert.Equal(t, []string{"env:test", "drive:C:", "system_drive"}, set.getTags("C:"))
+	assert.Equal(t, []string{"env:test", "drive:E:"}, set.getTags("E:"))
+	assert.Equal(t, []string{"env:test"}, set.getTags(""))
+
+	// the instances of the checks with builtin counter sets can be empty
+	set, err = parseInstance([]byte("{}"))
+	assert.NoError(t, err)
+	assert.Nil(t, set)
+}
+
+func TestParseInstanceDefaults(t *testing.T) {
+	set, err := parseInstance([]byte("countersetname: Processor\nmetrics: [{counter: \"% Processor Time\", name: cpu.time}]"))
+	require.NoError(t, err)
+	assert.Equal(t, defaultTagBy, set.TagBy)
+	assert.True(t, set.keepInstance("_Total"))
+	assert.Equal(t, []string{"instance:0"}, set.getTags("0"))
+}
+
+func TestParseInstanceErrors(t *testing.T) {
+	for _, instance := range []string{
+		"metrics: [{counter: Threads, name: threads}]",
+		"countersetname: System",
+		"countersetname: System\nmetrics: [{counter: Threads}]",
+		"countersetname: System\nmetrics: [{counter: Threads, name: threads, type: histogram}]",
+		"countersetname: System\nmetrics: [{counter: Threads, name: threads}]\ninstances_include: [\"(\"]",
+	} {
+		_, err := parseInstance([]byte(instance))
+		assert.Error(t, err, instance)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	sender := mocksender.NewMockSender("pdh-test")
+	sender.SetupAcceptAll()
+
+	submit(sender, CounterConfig{Name: "disk.reads"}, 12, []string{"drive:C:"})
+	submit(sender, CounterConfig{Name: "disk.split_io", Type: "rate"}, 3, nil)
+	submit(sender, CounterConfig{Name: "disk.errors", Type: "monotonic_count"}, 1, nil)
+
+	sender.AssertMetric(t, "Gauge", "disk.reads", 12, "", []string{"drive:C:"})
+	sender.AssertMetric(t, "Rate", "disk.split_io", 3, "", nil)
+	sender.AssertMetric(t, "MonotonicCount", "disk.errors", 1, "", nil)
+}
@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package pdh
+
+import (
+	"fmt"
+	"regexp"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	iisCheckName           = "iis_native"
+	iisSiteUpServiceCheck  = "iis.site_up"
+	iisAppPoolServiceCheck = "iis.app_pool_up"
+	iisSiteUptimeMetric    = "iis.uptime"
+	iisAppPoolStateMetric  = "iis.app_pool.state"
+	iisAppPoolStateRunning = 3
+	iisTotalInstance       = "_Total"
+	iisSiteCounterSet      = "Web Service"
+	iisAppPoolCounterSet   = "APP_POOL_WAS"
+	iisSiteTag             = "site"
+	iisAppPoolTag          = "app_pool"
+)
+
+var iisSiteMetrics = []CounterConfig{
+	{Counter: "Service Uptime", Name: iisSiteUptimeMetric},
+	{Counter: "Bytes Sent/sec", Name: "iis.net.bytes_sent"},
+	{Counter: "Bytes Received/sec", Name: "iis.net.bytes_rcvd"},
+	{Counter: "Bytes Total/sec", Name: "iis.net.bytes_total"},
+	{Counter: "Current Connections", Name: "iis.net.num_connections"},
+	{Counter: "Files Sent/sec", Name: "iis.net.files_sent"},
+	{Counter: "Files Received/sec", Name: "iis.net.files_rcvd"},
+	{Counter: "Total Connection Attempts (all instances)", Name: "iis.net.connection_attempts", Type: "monotonic_count"},
+	{Counter: "Get Requests/sec", Name: "iis.httpd_request_method.get"},
+	{Counter: "Post Requests/sec", Name: "iis.httpd_request_method.post"},
+	{Counter: "Head Requests/sec", Name: "iis.httpd_request_method.head"},
+	{Counter: "Put Requests/sec", Name: "iis.httpd_request_method.put"},
+	{Counter: "Delete Requests/sec", Name: "iis.httpd_request_method.delete"},
+	{Counter: "Options Requests/sec", Name: "iis.httpd_request_method.options"},
+	{Counter: "Trace Requests/sec", Name: "iis.httpd_request_method.trace"},
+	{Counter: "Not Found Errors/sec", Name: "iis.errors.not_found"},
+	{Counter: "Locked Errors/sec", Name: "iis.errors.locked"},
+	{Counter: "Anonymous Users/sec", Name: "iis.users.anon"},
+	{Counter: "NonAnonymous Users/sec", Name: "iis.users.nonanon"},
+	{Counter: "CGI Requests/sec", Name: "iis.requests.cgi"},
+	{Counter: "ISAPI Extension Requests/sec", Name: "iis.requests.isapi"},
+}
+
+var iisAppPoolMetrics = []CounterConfig{
+	{Counter: "Current Application Pool State", Name: iisAppPoolStateMetric},
+	{Counter: "Current Application Pool Uptime", Name: "iis.app_pool.uptime"},
+	{Counter: "Current Worker Processes", Name: "iis.app_pool.worker_processes"},
+	{Counter: "Total Worker Process Failures", Name: "iis.app_pool.worker_process_failures", Type: "monotonic_count"},
+	{Counter: "Total Application Pool Recycles", Name: "iis.app_pool.recycles", Type: "monotonic_count"},
+}
+
+// IISCheck collects the counters of the IIS sites and application pools,
+// tagged by site and app_pool. The sites and pools created after the check
+// started are collected once the instances of the counter sets are refreshed.
+// A site is up while its uptime is positive, a pool while it's running, the
+// sites and pools of the sites and app_pools options missing being down.
+type IISCheck struct {
+	core.CheckBase
+	config    iisInstanceConfig
+	collector *counterCollector
+}
+
+type iisInstanceConfig struct {
+	Sites    []string `yaml:"sites"`
+	AppPools []string `yaml:"app_pools"`
+	Tags     []string `yaml:"tags"`
+}
+
+func (c *IISCheck) String() string {
+	return iisCheckName
+}
+
+// Configure opens the queries of the site and app pool counters
+func (c *IISCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var config iisInstanceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	sites, err := newCounterSet(CounterSetConfig{
+		CounterSetName:   iisSiteCounterSet,
+		Metrics:          iisSiteMetrics,
+		InstancesInclude: exactPatterns(config.Sites),
+		InstancesExclude: []string{"^" + iisTotalInstance + "$"},
+		TagBy:            iisSiteTag,
+		Tags:             config.Tags,
+	})
+	if err != nil {
+		return err
+	}
+	appPools, err := newCounterSet(CounterSetConfig{
+		CounterSetName:   iisAppPoolCounterSet,
+		Metrics:          iisAppPoolMetrics,
+		InstancesInclude: exactPatterns(config.AppPools),
+		InstancesExclude: []string{"^" + iisTotalInstance + "$"},
+		TagBy:            iisAppPoolTag,
+		Tags:             config.Tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	collector, err := newCounterCollector([]*counterSet{sites, appPools})
+	if err != nil {
+		return fmt.Errorf("could not find the IIS counters, is IIS installed? %s", err)
+	}
+	c.BuildID(data, initConfig)
+	c.config = config
+	c.collector = collector
+	return nil
+}
+
+// exactPatterns returns the regular expressions matching exactly the names
+func exactPatterns(names []string) []string {
+	patterns := make([]string, 0, len(names))
+	for _, name := range names {
+		patterns = append(patterns, "^"+regexp.QuoteMeta(name)+"$")
+	}
+	return patterns
+}
+
+// Run submits the counters of the sites and pools, and their service checks
+func (c *IISCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	sitesUp := make(map[string]bool)
+	appPoolsUp := make(map[string]bool)
+	c.collector.collect(func(counter pdhCounter, instance string, value float64) {
+		submit(sender, counter.metric, value, counter.set.getTags(instance))
+		switch counter.metric.Name {
+		case iisSiteUptimeMetric:
+			sitesUp[instance] = value > 0
+		case iisAppPoolStateMetric:
+			appPoolsUp[instance] = value == iisAppPoolStateRunning
+		}
+	})
+
+	c.submitServiceChecks(sender, iisSiteUpServiceCheck, iisSiteTag, c.config.Sites, sitesUp)
+	c.submitServiceChecks(sender, iisAppPoolServiceCheck, iisAppPoolTag, c.config.AppPools, appPoolsUp)
+	return nil
+}
+
+// submitServiceChecks reports the state of the collected instances, and
+// the expected instances that weren't collected as down
+func (c *IISCheck) submitServiceChecks(sender aggregator.Sender, name, tag string, expected []string, up map[string]bool) {
+	for _, instance := range expected {
+		if _, found := up[instance]; !found {
+			tags := append(append([]string{}, c.config.Tags...), tag+":"+instance)
+			sender.ServiceCheck(name, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("%s %s not found", tag, instance))
+		}
+	}
+	for instance, isUp := range up {
+		tags := append(append([]string{}, c.config.Tags...), tag+":"+instance)
+		if isUp {
+			sender.ServiceCheck(name, metrics.ServiceCheckOK, "", tags, "")
+		} else {
+			sender.ServiceCheck(name, metrics.ServiceCheckCritical, "", tags, "")
+		}
+	}
+}
+
+func iisFactory() check.Check {
+	return &IISCheck{
+		CheckBase: core.NewCheckBase(iisCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(iisCheckName, iisFactory)
+}
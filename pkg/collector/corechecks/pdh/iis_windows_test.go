@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package pdh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestIISSubmitServiceChecks(t *testing.T) {
+	check := iisFactory().(*IISCheck)
+	check.config = iisInstanceConfig{
+		Sites: []string{"Default Web Site", "Missing Site"},
+		Tags:  []string{"env:test"},
+	}
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+
+	up := map[string]bool{
+		"Default Web Site": true,
+		"Stopped Site":     false,
+	}
+	check.submitServiceChecks(sender, iisSiteUpServiceCheck, iisSiteTag, check.config.Sites, up)
+
+	sender.AssertServiceCheck(t, iisSiteUpServiceCheck, metrics.ServiceCheckOK, "", []string{"env:test", "site:Default Web Site"}, "")
+	sender.AssertServiceCheck(t, iisSiteUpServiceCheck, metrics.ServiceCheckCritical, "", []string{"env:test", "site:Stopped Site"}, "")
+	sender.AssertServiceCheck(t, iisSiteUpServiceCheck, metrics.ServiceCheckCritical, "", []string{"env:test", "site:Missing Site"}, "site Missing Site not found")
+	sender.AssertNumberOfCalls(t, "ServiceCheck", 3)
+
+	// the configured tags are not modified by the instance tags
+	assert.Equal(t, []string{"env:test"}, check.config.Tags)
+}
+
+func TestIISSubmitServiceChecksNoInstance(t *testing.T) {
+	check := iisFactory().(*IISCheck)
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+
+	// without expected pools nor collected ones, nothing is reported
+	check.submitServiceChecks(sender, iisAppPoolServiceCheck, iisAppPoolTag, nil, map[string]bool{})
+	sender.AssertNumberOfCalls(t, "ServiceCheck", 0)
+
+	check.submitServiceChecks(sender, iisAppPoolServiceCheck, iisAppPoolTag, nil, map[string]bool{"DefaultAppPool": true})
+	sender.AssertServiceCheck(t, iisAppPoolServiceCheck, metrics.ServiceCheckOK, "", []string{"app_pool:DefaultAppPool"}, "")
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package pdh
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/winutil/pdhutil"
+)
+
+const (
+	pdhCheckName = "pdh_check_native"
+	// interval at which the instances of the counter sets are enumerated again
+	instancesRefreshInterval = time.Minute
+)
+
+// PdhCheck collects the counters of the builtin counter sets of the check,
+// and of the counter set declared by its instance
+type PdhCheck struct {
+	core.CheckBase
+	builtin   []CounterSetConfig
+	collector *counterCollector
+}
+
+type pdhCounter struct {
+	set    *counterSet
+	metric CounterConfig
+	pdh    *pdhutil.PdhCounterSet
+}
+
+// counterCollector holds the queries of the counters of counter sets. As
+// the queries are bound to the instances existing when they're opened, they
+// are opened again every instancesRefreshInterval for the new instances,
+// e.g. new disks or processes, to be collected.
+type counterCollector struct {
+	sets        []*counterSet
+	counters    []pdhCounter
+	lastRefresh time.Time
+}
+
+// RegisterCheck registers a check collecting the builtin counter sets, plus
+// the counter set declared by each of its instances
+func RegisterCheck(name string, builtin []CounterSetConfig) {
+	core.RegisterCheck(name, func() check.Check {
+		return &PdhCheck{
+			CheckBase: core.NewCheckBase(name),
+			builtin:   builtin,
+		}
+	})
+}
+
+// Configure opens the queries of the counters
+func (c *PdhCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var sets []*counterSet
+	for _, config := range c.builtin {
+		set, err := newCounterSet(config)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+	}
+	set, err := parseInstance(data)
+	if err != nil {
+		return err
+	}
+	if set != nil {
+		sets = append(sets, set)
+	}
+	if len(sets) == 0 {
+		return fmt.Errorf("no counter set declared for the %s check", c.String())
+	}
+
+	collector, err := newCounterCollector(sets)
+	if err != nil {
+		return err
+	}
+	c.BuildID(data, initConfig)
+	c.collector = collector
+	return nil
+}
+
+// Run submits the values of the counters
+func (c *PdhCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	c.collector.collect(func(counter pdhCounter, instance string, value float64) {
+		submit(sender, counter.metric, value, counter.set.getTags(instance))
+	})
+	return nil
+}
+
+// newCounterCollector opens the queries of the counters, the counters
+// missing on the host are skipped with a warning
+func newCounterCollector(sets []*counterSet) (*counterCollector, error) {
+	c := &counterCollector{sets: sets}
+	c.open()
+	if len(c.counters) == 0 {
+		return nil, fmt.Errorf("none of the counters were found")
+	}
+	return c, nil
+}
+
+func (c *counterCollector) open() {
+	c.counters = nil
+	c.lastRefresh = time.Now()
+	for _, set := range c.sets {
+		for _, metric := range set.Metrics {
+			counterSet, err := pdhutil.GetCounterSet(set.CounterSetName, metric.Counter, "", set.keepInstance)
+			if err != nil {
+				log.Warnf("Could not find the counter %s\\%s, skipping it: %s", set.CounterSetName, metric.Counter, err)
+				continue
+			}
+			c.counters = append(c.counters, pdhCounter{set: set, metric: metric, pdh: counterSet})
+		}
+	}
+}
+
+// collect calls fn with the value of every counter per instance, the
+// instance being empty for the counter sets without instances
+func (c *counterCollector) collect(fn func(counter pdhCounter, instance string, value float64)) {
+	for _, counter := range c.counters {
+		if value, err := counter.pdh.GetSingleValue(); err == nil {
+			fn(counter, "", value)
+			continue
+		}
+		values, err := counter.pdh.GetAllValues()
+		if err != nil {
+			log.Debugf("Could not collect the counter %s\\%s: %s", counter.set.CounterSetName, counter.metric.Counter, err)
+			continue
+		}
+		for instance, value := range values {
+			fn(counter, instance, value)
+		}
+	}
+
+	// refresh after the collection, for the rate counters to be sampled
+	// over a whole check interval at the next run
+	if time.Since(c.lastRefresh) >= instancesRefreshInterval {
+		for _, counter := range c.counters {
+			counter.pdh.Close()
+		}
+		c.open()
+	}
+}
+
+func init() {
+	RegisterCheck(pdhCheckName, nil)
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package pdh
+
+const windowsSystemCheckName = "windows_system"
+
+// windowsSystemCounters are the system counters not reported by the other
+// system checks
+var windowsSystemCounters = []CounterSetConfig{
+	{
+		CounterSetName: "System",
+		Metrics: []CounterConfig{
+			{Counter: "Context Switches/sec", Name: "system.cpu.context_switches"},
+			{Counter: "System Calls/sec", Name: "system.cpu.syscalls"},
+			{Counter: "Threads", Name: "system.threads.count"},
+		},
+	},
+	{
+		CounterSetName: "Processor",
+		Metrics: []CounterConfig{
+			{Counter: "% Interrupt Time", Name: "system.cpu.interrupt"},
+			{Counter: "% DPC Time", Name: "system.cpu.dpc"},
+		},
+		InstancesExclude: []string{"^_Total$"},
+		TagBy:            "core",
+	},
+	{
+		CounterSetName: "Memory",
+		Metrics: []CounterConfig{
+			{Counter: "Pages/sec", Name: "system.mem.pages_per_sec"},
+			{Counter: "Page Faults/sec", Name: "system.mem.page_faults_per_sec"},
+		},
+	},
+	{
+		CounterSetName: "Paging File",
+		Metrics: []CounterConfig{
+			{Counter: "% Usage", Name: "system.paging.pct_usage"},
+		},
+		InstancesExclude: []string{"^_Total$"},
+		TagBy:            "paging_file",
+	},
+	{
+		CounterSetName: "TCPv4",
+		Metrics: []CounterConfig{
+			{Counter: "Connections Established", Name: "system.net.tcp.connections_established"},
+			{Counter: "Segments Retransmitted/sec", Name: "system.net.tcp.retrans_segs_per_sec"},
+		},
+	},
+}
+
+func init() {
+	RegisterCheck(windowsSystemCheckName, windowsSystemCounters)
+}
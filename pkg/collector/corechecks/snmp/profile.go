@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// defaultProfiles are the profiles shipped in the profiles folder of the check,
+// used when init_config has no profiles
+var defaultProfiles = map[string]profileConfig{
+	"generic-router": {DefinitionFile: "generic-router.yaml"},
+}
+
+// profileConfig is a profile of init_config
+type profileConfig struct {
+	DefinitionFile string `yaml:"definition_file"`
+}
+
+// profileDefinition is the content of a profile definition file
+type profileDefinition struct {
+	// glob of the sysObjectID of the devices the profile applies to
+	SysObjectID string             `yaml:"sysobjectid"`
+	Metrics     []metricDefinition `yaml:"metrics"`
+}
+
+// metricDefinition is a scalar symbol, or the symbols of a table tagged with
+// its columns or the parts of its index
+type metricDefinition struct {
+	Symbol     symbolDefinition   `yaml:"symbol"`
+	Table      symbolDefinition   `yaml:"table"`
+	Symbols    []symbolDefinition `yaml:"symbols"`
+	MetricTags []metricTag        `yaml:"metric_tags"`
+	// gauge, rate or monotonic_count, the counters are reported as rates by default
+	ForcedType string `yaml:"forced_type"`
+}
+
+type symbolDefinition struct {
+	OID  string `yaml:"OID"`
+	Name string `yaml:"name"`
+}
+
+// metricTag tags the rows of a table with the value of a column, or with the
+// index-th part of their index
+type metricTag struct {
+	Tag    string           `yaml:"tag"`
+	Column symbolDefinition `yaml:"column"`
+	Index  int              `yaml:"index"`
+}
+
+var (
+	// parsed definition files by path, shared by the instances
+	definitionCache      = make(map[string]*profileDefinition)
+	definitionCacheMutex sync.Mutex
+)
+
+// profilesFolder returns the folder of the relative definition files
+func profilesFolder() string {
+	return filepath.Join(config.Datadog.GetString("confd_path"), snmpCheckName+".d", "profiles")
+}
+
+// loadProfiles returns the definitions of the profiles by name
+func loadProfiles(profiles map[string]profileConfig) (map[string]*profileDefinition, error) {
+	if len(profiles) == 0 {
+		profiles = defaultProfiles
+	}
+	definitions := make(map[string]*profileDefinition, len(profiles))
+	for name, profile := range profiles {
+		file := profile.DefinitionFile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(profilesFolder(), file)
+		}
+		definition, err := loadDefinition(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the profile %s: %s", name, err)
+		}
+		definitions[name] = definition
+	}
+	return definitions, nil
+}
+
+func loadDefinition(file string) (*profileDefinition, error) {
+	definitionCacheMutex.Lock()
+	defer definitionCacheMutex.Unlock()
+
+	if definition, found := definitionCache[file]; found {
+		return definition, nil
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var definition profileDefinition
+	if err := yaml.Unmarshal(content, &definition); err != nil {
+		return nil, err
+	}
+	if err := validateMetrics(definition.Metrics); err != nil {
+		return nil, err
+	}
+	definitionCache[file] = &definition
+	return &definition, nil
+}
+
+// validateMetrics checks the metrics are either scalars or tables with symbols
+func validateMetrics(metrics []metricDefinition) error {
+	for _, metric := range metrics {
+		switch metric.ForcedType {
+		case "", "gauge", "rate", "monotonic_count":
+		default:
+			return fmt.Errorf("unsupported forced_type %q", metric.ForcedType)
+		}
+		if metric.Symbol.OID != "" {
+			if metric.Symbol.Name == "" {
+				return fmt.Errorf("the symbol %s has no name", metric.Symbol.OID)
+			}
+			continue
+		}
+		if metric.Table.OID == "" || len(metric.Symbols) == 0 {
+			return fmt.Errorf("a metric has neither a symbol nor a table with symbols")
+		}
+		for _, symbol := range metric.Symbols {
+			if symbol.OID == "" || symbol.Name == "" {
+				return fmt.Errorf("a symbol of the table %s has no OID or name", metric.Table.Name)
+			}
+		}
+		for _, tag := range metric.MetricTags {
+			if tag.Tag == "" || (tag.Column.OID == "" && tag.Index <= 0) {
+				return fmt.Errorf("a metric tag of the table %s has no name, column or index", metric.Table.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// matchProfile returns the name of the profile with the most specific
+// sysobjectid glob matching the sysObjectID of a device
+func matchProfile(definitions map[string]*profileDefinition, sysObjectID string) (string, bool) {
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	// sort the names for the choice between equally specific globs to be stable
+	sort.Strings(names)
+
+	match, matchLen := "", -1
+	for _, name := range names {
+		glob := normalizeOID(definitions[name].SysObjectID)
+		if glob == "" {
+			continue
+		}
+		if matched, _ := path.Match(glob, sysObjectID); matched && len(glob) > matchLen {
+			match, matchLen = name, len(glob)
+		}
+	}
+	return match, matchLen >= 0
+}
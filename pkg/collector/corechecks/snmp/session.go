@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k-sone/snmpgo"
+)
+
+// snmpValue is a variable returned by a device, its number is only set for
+// the numeric variables
+type snmpValue struct {
+	oid       string
+	str       string
+	number    float64
+	isNumber  bool
+	isCounter bool
+}
+
+// snmpSession is the connection to a device
+type snmpSession interface {
+	// Get returns the values of the scalar OIDs
+	Get(oids []string) ([]snmpValue, error)
+	// BulkWalk returns the values of the OIDs under oid
+	BulkWalk(oid string) ([]snmpValue, error)
+	Close()
+}
+
+var newSNMPSession = openSNMPSession
+
+type snmpgoSession struct {
+	snmp           *snmpgo.SNMP
+	maxRepetitions int
+}
+
+// openSNMPSession opens a session to the device of the instance
+func openSNMPSession(cfg snmpInstanceConfig) (snmpSession, error) {
+	version := snmpgo.V2c
+	if cfg.SNMPVersion == "3" {
+		version = snmpgo.V3
+	}
+	seclevel := snmpgo.NoAuthNoPriv
+	if version == snmpgo.V3 && cfg.AuthKey != "" {
+		if cfg.PrivKey != "" {
+			seclevel = snmpgo.AuthPriv
+		} else {
+			seclevel = snmpgo.AuthNoPriv
+		}
+	}
+
+	snmp, err := snmpgo.NewSNMP(snmpgo.SNMPArguments{
+		Version:         version,
+		Address:         net.JoinHostPort(cfg.IPAddress, strconv.Itoa(cfg.Port)),
+		Retries:         uint(cfg.Retries),
+		Timeout:         time.Duration(cfg.Timeout) * time.Second,
+		UserName:        cfg.User,
+		Community:       cfg.CommunityString,
+		AuthPassword:    cfg.AuthKey,
+		AuthProtocol:    snmpgo.AuthProtocol(cfg.AuthProtocol),
+		PrivPassword:    cfg.PrivKey,
+		PrivProtocol:    snmpgo.PrivProtocol(cfg.PrivProtocol),
+		ContextEngineId: cfg.ContextEngineID,
+		ContextName:     cfg.ContextName,
+		SecurityLevel:   seclevel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SNMP settings: %s", err)
+	}
+	if err = snmp.Open(); err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %s", cfg.IPAddress, err)
+	}
+	return &snmpgoSession{snmp: snmp, maxRepetitions: cfg.BulkMaxRepetitions}, nil
+}
+
+func (s *snmpgoSession) Get(oids []string) ([]snmpValue, error) {
+	snmpOids, err := snmpgo.NewOids(oids)
+	if err != nil {
+		return nil, err
+	}
+	pdu, err := s.snmp.GetRequest(snmpOids)
+	if err != nil {
+		return nil, err
+	}
+	if pdu.ErrorStatus() != snmpgo.NoError {
+		return nil, fmt.Errorf("the device answered %s", pdu.ErrorStatus())
+	}
+	return toSNMPValues(pdu.VarBinds()), nil
+}
+
+func (s *snmpgoSession) BulkWalk(oid string) ([]snmpValue, error) {
+	snmpOids, err := snmpgo.NewOids([]string{oid})
+	if err != nil {
+		return nil, err
+	}
+	pdu, err := s.snmp.GetBulkWalk(snmpOids, 0, s.maxRepetitions)
+	if err != nil {
+		return nil, err
+	}
+	if pdu.ErrorStatus() != snmpgo.NoError {
+		return nil, fmt.Errorf("the device answered %s", pdu.ErrorStatus())
+	}
+	return toSNMPValues(pdu.VarBinds().MatchBaseOids(snmpOids[0])), nil
+}
+
+func (s *snmpgoSession) Close() {
+	s.snmp.Close()
+}
+
+// toSNMPValues converts the variables, skipping the missing ones
+func toSNMPValues(varBinds snmpgo.VarBinds) []snmpValue {
+	values := make([]snmpValue, 0, len(varBinds))
+	for _, vb := range varBinds {
+		value := snmpValue{oid: vb.Oid.String()}
+		switch v := vb.Variable.(type) {
+		case *snmpgo.NoSucheObject, *snmpgo.NoSucheInstance, *snmpgo.EndOfMibView:
+			continue
+		case *snmpgo.OctetString, *snmpgo.Ipaddress, *snmpgo.Oid:
+			value.str = v.String()
+		default:
+			value.str = v.String()
+			if n, err := v.BigInt(); err == nil {
+				value.number, _ = new(big.Float).SetInt(n).Float64()
+				value.isNumber = true
+			}
+			switch v.(type) {
+			case *snmpgo.Counter32, *snmpgo.Counter64:
+				value.isCounter = true
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// normalizeOID returns the OID without its leading dot
+func normalizeOID(oid string) string {
+	return strings.TrimPrefix(oid, ".")
+}
@@ -0,0 +1,351 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	snmpCheckName             = "snmp_native"
+	snmpServiceCheck          = "snmp.can_check"
+	snmpDefaultPort           = 161
+	snmpDefaultTimeout        = 5
+	snmpDefaultRetries        = 3
+	snmpDefaultMaxRepetitions = 10
+	snmpDefaultOIDBatchSize   = 10
+	// sysObjectID, identifying the model of the device
+	sysObjectIDOID = "1.3.6.1.2.1.1.2.0"
+)
+
+// SNMPCheck polls a SNMP v2c or v3 device for the scalar and table OIDs of
+// its profile and of its metrics option. The tables are fetched with bulk
+// walks, their rows tagged with the values of other columns or with the
+// parts of their index. Without profile nor metrics, the profile is picked
+// by matching the sysObjectID of the device against the ones of the profiles.
+type SNMPCheck struct {
+	core.CheckBase
+	config   snmpInstanceConfig
+	profiles map[string]*profileDefinition
+	profile  string
+	metrics  []metricDefinition
+}
+
+type snmpInstanceConfig struct {
+	IPAddress          string             `yaml:"ip_address"`
+	Port               int                `yaml:"port"`
+	SNMPVersion        string             `yaml:"snmp_version"`
+	CommunityString    string             `yaml:"community_string"`
+	User               string             `yaml:"user"`
+	AuthKey            string             `yaml:"authKey"`
+	AuthProtocol       string             `yaml:"authProtocol"`
+	PrivKey            string             `yaml:"privKey"`
+	PrivProtocol       string             `yaml:"privProtocol"`
+	ContextEngineID    string             `yaml:"context_engine_id"`
+	ContextName        string             `yaml:"context_name"`
+	Timeout            int                `yaml:"timeout"`
+	Retries            int                `yaml:"retries"`
+	BulkMaxRepetitions int                `yaml:"bulk_max_repetitions"`
+	OIDBatchSize       int                `yaml:"oid_batch_size"`
+	Profile            string             `yaml:"profile"`
+	Metrics            []metricDefinition `yaml:"metrics"`
+	Tags               []string           `yaml:"tags"`
+}
+
+type snmpInitConfig struct {
+	Profiles map[string]profileConfig `yaml:"profiles"`
+}
+
+func (c *SNMPCheck) String() string {
+	return snmpCheckName
+}
+
+// Configure parses the check configuration and loads the profiles
+func (c *SNMPCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var config snmpInstanceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	var initCfg snmpInitConfig
+	if err := yaml.Unmarshal(initConfig, &initCfg); err != nil {
+		return err
+	}
+	if err := setSNMPDefaults(&config); err != nil {
+		return err
+	}
+	if err := validateMetrics(config.Metrics); err != nil {
+		return err
+	}
+
+	c.BuildID(data, initConfig)
+	c.config = config
+	c.metrics = config.Metrics
+	if config.Profile == "" && len(config.Metrics) > 0 {
+		return nil
+	}
+
+	profiles, err := loadProfiles(initCfg.Profiles)
+	if err != nil {
+		return err
+	}
+	c.profiles = profiles
+	if config.Profile != "" {
+		return c.setProfile(config.Profile)
+	}
+	// the profile is detected at the first run
+	c.metrics = nil
+	return nil
+}
+
+// setSNMPDefaults validates the connection settings and fills the unset options
+func setSNMPDefaults(cfg *snmpInstanceConfig) error {
+	if cfg.IPAddress == "" {
+		return errors.New("the ip_address of the instance is missing")
+	}
+	switch cfg.SNMPVersion {
+	case "", "2", "2c":
+		cfg.SNMPVersion = "2"
+		if cfg.CommunityString == "" {
+			return errors.New("the community_string of the instance is missing")
+		}
+	case "3":
+		if cfg.User == "" {
+			return errors.New("the user of the instance is missing")
+		}
+	default:
+		return fmt.Errorf("unsupported snmp_version %q, only 2c and 3 are supported", cfg.SNMPVersion)
+	}
+	if cfg.Port <= 0 {
+		cfg.Port = snmpDefaultPort
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = snmpDefaultTimeout
+	}
+	if cfg.Retries <= 0 {
+		cfg.Retries = snmpDefaultRetries
+	}
+	if cfg.BulkMaxRepetitions <= 0 {
+		cfg.BulkMaxRepetitions = snmpDefaultMaxRepetitions
+	}
+	if cfg.OIDBatchSize <= 0 {
+		cfg.OIDBatchSize = snmpDefaultOIDBatchSize
+	}
+	return nil
+}
+
+// setProfile adds the metrics of a profile to the ones of the instance
+func (c *SNMPCheck) setProfile(name string) error {
+	definition, found := c.profiles[name]
+	if !found {
+		return fmt.Errorf("unknown profile %s", name)
+	}
+	c.profile = name
+	c.metrics = append(append([]metricDefinition{}, c.config.Metrics...), definition.Metrics...)
+	return nil
+}
+
+// Run polls the device and submits the metrics
+func (c *SNMPCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	err = c.collect(sender)
+	// after the collection, the profile might have been detected
+	tags := c.getTags()
+	if err != nil {
+		sender.ServiceCheck(snmpServiceCheck, metrics.ServiceCheckCritical, "", tags, err.Error())
+		return err
+	}
+	sender.ServiceCheck(snmpServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+	return nil
+}
+
+func (c *SNMPCheck) getTags() []string {
+	tags := append([]string{}, c.config.Tags...)
+	tags = append(tags, "snmp_device:"+c.config.IPAddress)
+	if c.profile != "" {
+		tags = append(tags, "snmp_profile:"+c.profile)
+	}
+	return tags
+}
+
+func (c *SNMPCheck) collect(sender aggregator.Sender) error {
+	session, err := newSNMPSession(c.config)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if c.metrics == nil {
+		if err = c.detectProfile(session); err != nil {
+			return err
+		}
+	}
+
+	tags := c.getTags()
+	var scalars []metricDefinition
+	for _, metric := range c.metrics {
+		if metric.Symbol.OID != "" {
+			scalars = append(scalars, metric)
+			continue
+		}
+		if err = c.collectTable(sender, session, metric, tags); err != nil {
+			return err
+		}
+	}
+	return c.collectScalars(sender, session, scalars, tags)
+}
+
+// detectProfile picks the profile matching the sysObjectID of the device
+func (c *SNMPCheck) detectProfile(session snmpSession) error {
+	values, err := session.Get([]string{sysObjectIDOID})
+	if err != nil {
+		return fmt.Errorf("could not get the sysObjectID of the device: %s", err)
+	}
+	if len(values) == 0 {
+		return errors.New("the device has no sysObjectID")
+	}
+	sysObjectID := normalizeOID(values[0].str)
+	name, found := matchProfile(c.profiles, sysObjectID)
+	if !found {
+		return fmt.Errorf("no profile matches the sysObjectID %s of the device", sysObjectID)
+	}
+	log.Infof("Using the profile %s for the SNMP device %s", name, c.config.IPAddress)
+	return c.setProfile(name)
+}
+
+// collectScalars gets the scalar symbols by batches of oid_batch_size
+func (c *SNMPCheck) collectScalars(sender aggregator.Sender, session snmpSession, scalars []metricDefinition, tags []string) error {
+	for start := 0; start < len(scalars); start += c.config.OIDBatchSize {
+		end := start + c.config.OIDBatchSize
+		if end > len(scalars) {
+			end = len(scalars)
+		}
+		batch := scalars[start:end]
+		oids := make([]string, 0, len(batch))
+		for _, metric := range batch {
+			oids = append(oids, normalizeOID(metric.Symbol.OID))
+		}
+		values, err := session.Get(oids)
+		if err != nil {
+			return fmt.Errorf("could not get the scalar OIDs: %s", err)
+		}
+		byOID := make(map[string]snmpValue, len(values))
+		for _, value := range values {
+			byOID[normalizeOID(value.oid)] = value
+		}
+		for _, metric := range batch {
+			if value, found := byOID[normalizeOID(metric.Symbol.OID)]; found {
+				c.submit(sender, metric, metric.Symbol, value, tags)
+			}
+		}
+	}
+	return nil
+}
+
+// collectTable walks the symbol and tag columns of a table, and submits the
+// symbols of every row with the tags of the row
+func (c *SNMPCheck) collectTable(sender aggregator.Sender, session snmpSession, metric metricDefinition, tags []string) error {
+	// values of the tag columns by tag, then row index
+	columnTags := make(map[string]map[string]string)
+	for _, tag := range metric.MetricTags {
+		if tag.Column.OID == "" {
+			continue
+		}
+		values, err := c.walkColumn(session, tag.Column.OID)
+		if err != nil {
+			return err
+		}
+		columnTags[tag.Tag] = values
+	}
+
+	for _, symbol := range metric.Symbols {
+		column := normalizeOID(symbol.OID)
+		values, err := session.BulkWalk(column)
+		if err != nil {
+			return fmt.Errorf("could not walk the column %s of the table %s: %s", symbol.Name, metric.Table.Name, err)
+		}
+		for _, value := range values {
+			index := strings.TrimPrefix(normalizeOID(value.oid), column+".")
+			rowTags := append([]string{}, tags...)
+			for _, tag := range metric.MetricTags {
+				if tag.Column.OID != "" {
+					if v, found := columnTags[tag.Tag][index]; found {
+						rowTags = append(rowTags, tag.Tag+":"+v)
+					}
+					continue
+				}
+				if parts := strings.Split(index, "."); tag.Index <= len(parts) {
+					rowTags = append(rowTags, tag.Tag+":"+parts[tag.Index-1])
+				}
+			}
+			c.submit(sender, metric, symbol, value, rowTags)
+		}
+	}
+	return nil
+}
+
+// walkColumn returns the values of a column by row index
+func (c *SNMPCheck) walkColumn(session snmpSession, oid string) (map[string]string, error) {
+	column := normalizeOID(oid)
+	values, err := session.BulkWalk(column)
+	if err != nil {
+		return nil, fmt.Errorf("could not walk the column %s: %s", column, err)
+	}
+	byIndex := make(map[string]string, len(values))
+	for _, value := range values {
+		byIndex[strings.TrimPrefix(normalizeOID(value.oid), column+".")] = value.str
+	}
+	return byIndex, nil
+}
+
+// submit sends a numeric value with the forced type of its metric, the
+// counters as rates and the other values as gauges by default
+func (c *SNMPCheck) submit(sender aggregator.Sender, metric metricDefinition, symbol symbolDefinition, value snmpValue, tags []string) {
+	if !value.isNumber {
+		log.Debugf("Skipping the non numeric value of %s on %s", symbol.Name, c.config.IPAddress)
+		return
+	}
+	name := "snmp." + symbol.Name
+	switch metric.ForcedType {
+	case "gauge":
+		sender.Gauge(name, value.number, "", tags)
+	case "rate":
+		sender.Rate(name, value.number, "", tags)
+	case "monotonic_count":
+		sender.MonotonicCount(name, value.number, "", tags)
+	default:
+		if value.isCounter {
+			sender.Rate(name, value.number, "", tags)
+		} else {
+			sender.Gauge(name, value.number, "", tags)
+		}
+	}
+}
+
+func snmpFactory() check.Check {
+	return &SNMPCheck{
+		CheckBase: core.NewCheckBase(snmpCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(snmpCheckName, snmpFactory)
+}
@@ -0,0 +1,209 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const testProfile = `
+sysobjectid: 1.3.6.1.4.1.9.*
+metrics:
+  - symbol:
+      OID: .1.3.6.1.2.1.1.3.0
+      name: sysUpTimeInstance
+  - table:
+      OID: 1.3.6.1.2.1.2.2
+      name: ifTable
+    symbols:
+      - OID: 1.3.6.1.2.1.2.2.1.14
+        name: ifInErrors
+    metric_tags:
+      - tag: interface
+        column:
+          OID: 1.3.6.1.2.1.31.1.1.1.1
+          name: ifName
+  - table:
+      OID: 1.3.6.1.2.1.4.31.1
+      name: ipSystemStatsTable
+    symbols:
+      - OID: 1.3.6.1.2.1.4.31.1.1.4
+        name: ipSystemStatsHCInReceives
+    metric_tags:
+      - tag: ipversion
+        index: 1
+    forced_type: monotonic_count
+`
+
+// fakeSession answers with the values under the requested OIDs
+type fakeSession struct {
+	values []snmpValue
+	err    error
+	gets   [][]string
+}
+
+func (s *fakeSession) Get(oids []string) ([]snmpValue, error) {
+	s.gets = append(s.gets, oids)
+	var values []snmpValue
+	for _, oid := range oids {
+		for _, v := range s.values {
+			if v.oid == oid {
+				values = append(values, v)
+			}
+		}
+	}
+	return values, s.err
+}
+
+func (s *fakeSession) BulkWalk(oid string) ([]snmpValue, error) {
+	var values []snmpValue
+	for _, v := range s.values {
+		if strings.HasPrefix(v.oid, oid+".") {
+			values = append(values, v)
+		}
+	}
+	return values, s.err
+}
+
+func (s *fakeSession) Close() {}
+
+func newFakeDevice() *fakeSession {
+	return &fakeSession{values: []snmpValue{
+		{oid: "1.3.6.1.2.1.1.2.0", str: "1.3.6.1.4.1.9.1.1045"},
+		{oid: "1.3.6.1.2.1.1.3.0", number: 4200, isNumber: true},
+		{oid: "1.3.6.1.2.1.2.2.1.14.1", number: 3, isNumber: true, isCounter: true},
+		{oid: "1.3.6.1.2.1.2.2.1.14.2", number: 7, isNumber: true, isCounter: true},
+		{oid: "1.3.6.1.2.1.31.1.1.1.1.1", str: "eth0"},
+		{oid: "1.3.6.1.2.1.31.1.1.1.1.2", str: "eth1"},
+		{oid: "1.3.6.1.2.1.4.31.1.1.4.1", number: 1500, isNumber: true, isCounter: true},
+		{oid: "1.3.6.1.2.1.4.31.1.1.4.2", number: 800, isNumber: true, isCounter: true},
+	}}
+}
+
+func writeTestProfile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "snmp-profiles")
+	require.NoError(t, err)
+	file := filepath.Join(dir, "cisco.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(testProfile), 0644))
+	return fmt.Sprintf("profiles:\n  cisco:\n    definition_file: %s\n", file), func() { os.RemoveAll(dir) }
+}
+
+func TestSNMPCheckProfileDetection(t *testing.T) {
+	initConfig, cleanup := writeTestProfile(t)
+	defer cleanup()
+	device := newFakeDevice()
+	newSNMPSession = func(cfg snmpInstanceConfig) (snmpSession, error) { return device, nil }
+	defer func() { newSNMPSession = openSNMPSession }()
+
+	check := snmpFactory().(*SNMPCheck)
+	require.NoError(t, check.Configure([]byte("ip_address: 10.0.0.1\ncommunity_string: public\ntags: [site:paris]"), []byte(initConfig)))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	assert.Equal(t, "cisco", check.profile)
+	deviceTags := []string{"site:paris", "snmp_device:10.0.0.1", "snmp_profile:cisco"}
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckOK, "", deviceTags, "")
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", 4200, "", deviceTags)
+	sender.AssertMetric(t, "Rate", "snmp.ifInErrors", 3, "", append(deviceTags, "interface:eth0"))
+	sender.AssertMetric(t, "Rate", "snmp.ifInErrors", 7, "", append(deviceTags, "interface:eth1"))
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ipSystemStatsHCInReceives", 1500, "", []string{"ipversion:1"})
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ipSystemStatsHCInReceives", 800, "", []string{"ipversion:2"})
+}
+
+func TestSNMPCheckInlineMetrics(t *testing.T) {
+	device := newFakeDevice()
+	newSNMPSession = func(cfg snmpInstanceConfig) (snmpSession, error) { return device, nil }
+	defer func() { newSNMPSession = openSNMPSession }()
+
+	instance := `
+ip_address: 10.0.0.1
+snmp_version: 3
+user: datadog
+oid_batch_size: 1
+metrics:
+  - symbol:
+      OID: 1.3.6.1.2.1.1.3.0
+      name: sysUpTimeInstance
+  - symbol:
+      OID: 1.3.6.1.2.1.1.2.0
+      name: sysObjectID
+`
+	check := snmpFactory().(*SNMPCheck)
+	require.NoError(t, check.Configure([]byte(instance), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", 4200, "", []string{"snmp_device:10.0.0.1"})
+	// the sysObjectID isn't numeric
+	sender.AssertNotCalled(t, "Gauge", "snmp.sysObjectID", mock.Anything, "", mock.Anything)
+	assert.Equal(t, [][]string{{"1.3.6.1.2.1.1.3.0"}, {"1.3.6.1.2.1.1.2.0"}}, device.gets)
+}
+
+func TestSNMPCheckDeviceError(t *testing.T) {
+	initConfig, cleanup := writeTestProfile(t)
+	defer cleanup()
+	device := newFakeDevice()
+	device.err = fmt.Errorf("request timeout")
+	newSNMPSession = func(cfg snmpInstanceConfig) (snmpSession, error) { return device, nil }
+	defer func() { newSNMPSession = openSNMPSession }()
+
+	check := snmpFactory().(*SNMPCheck)
+	require.NoError(t, check.Configure([]byte("ip_address: 10.0.0.1\ncommunity_string: public\nprofile: cisco"), []byte(initConfig)))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+
+	assert.Error(t, check.Run())
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckCritical, "", []string{"snmp_device:10.0.0.1", "snmp_profile:cisco"}, "could not walk the column 1.3.6.1.2.1.31.1.1.1.1: request timeout")
+}
+
+func TestSNMPCheckInvalidConfig(t *testing.T) {
+	initConfig, cleanup := writeTestProfile(t)
+	defer cleanup()
+
+	for _, instance := range []string{
+		"community_string: public",
+		"ip_address: 10.0.0.1",
+		"ip_address: 10.0.0.1\nsnmp_version: 1\ncommunity_string: public",
+		"ip_address: 10.0.0.1\nsnmp_version: 3",
+		"ip_address: 10.0.0.1\ncommunity_string: public\nprofile: juniper",
+		"ip_address: 10.0.0.1\ncommunity_string: public\nmetrics: [{table: {OID: 1.3.6.1.2.1.2.2}}]",
+	} {
+		check := snmpFactory().(*SNMPCheck)
+		assert.Error(t, check.Configure([]byte(instance), []byte(initConfig)), instance)
+	}
+}
+
+func TestMatchProfile(t *testing.T) {
+	definitions := map[string]*profileDefinition{
+		"generic": {SysObjectID: "1.3.6.1.4.1.*"},
+		"cisco":   {SysObjectID: "1.3.6.1.4.1.9.*"},
+		"custom":  {},
+	}
+	name, found := matchProfile(definitions, "1.3.6.1.4.1.9.1.1045")
+	assert.True(t, found)
+	assert.Equal(t, "cisco", name)
+	name, found = matchProfile(definitions, "1.3.6.1.4.1.2636.1.1")
+	assert.True(t, found)
+	assert.Equal(t, "generic", name)
+	_, found = matchProfile(definitions, "1.3.6.1.2.1")
+	assert.False(t, found)
+}
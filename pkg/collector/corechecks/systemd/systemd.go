@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package systemd
+
+import (
+	"fmt"
+	"math"
+	"path"
+
+	"github.com/coreos/go-systemd/dbus"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	systemdCheckName = "systemd"
+	serviceSuffix    = ".service"
+)
+
+// systemdConn is the part of the dbus connection to systemd the check uses
+type systemdConn interface {
+	ListUnits() ([]dbus.UnitStatus, error)
+	GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error)
+	Close()
+}
+
+// for testing purpose
+var newSystemdConn = func() (systemdConn, error) {
+	return dbus.New()
+}
+
+// SystemdCheck reports the number of units by state and, for the units of
+// its allowlist, their state, and the restarts and the cgroup accounting of
+// the services
+type SystemdCheck struct {
+	core.CheckBase
+	config systemdInstanceConfig
+}
+
+type systemdInstanceConfig struct {
+	// names or globs of the units to report
+	Units []string `yaml:"units"`
+	// tags of the units, e.g. {"nginx.service": ["team:web"]}
+	UnitTags map[string][]string `yaml:"unit_tags"`
+}
+
+func (c *SystemdCheck) String() string {
+	return systemdCheckName
+}
+
+// Configure parses the check configuration and validates the unit globs
+func (c *SystemdCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	var config systemdInstanceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	for _, pattern := range config.Units {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid unit pattern %q: %s", pattern, err)
+		}
+	}
+
+	c.BuildID(data, initConfig)
+	c.config = config
+	return nil
+}
+
+// Run lists the units and reports the state of the monitored ones
+func (c *SystemdCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	conn, err := newSystemdConn()
+	if err != nil {
+		sender.ServiceCheck("systemd.can_connect", metrics.ServiceCheckCritical, "", nil, err.Error())
+		sender.Commit()
+		return fmt.Errorf("could not connect to systemd: %s", err)
+	}
+	defer conn.Close()
+
+	units, err := conn.ListUnits()
+	if err != nil {
+		sender.ServiceCheck("systemd.can_connect", metrics.ServiceCheckCritical, "", nil, err.Error())
+		sender.Commit()
+		return fmt.Errorf("could not list the systemd units: %s", err)
+	}
+	sender.ServiceCheck("systemd.can_connect", metrics.ServiceCheckOK, "", nil, "")
+
+	byState := make(map[string]int)
+	for _, unit := range units {
+		byState[unit.ActiveState]++
+		if !c.isMonitored(unit.Name) {
+			continue
+		}
+		c.submitUnit(sender, conn, unit)
+	}
+	for state, count := range byState {
+		sender.Gauge("systemd.units_by_state", float64(count), "", []string{"active_state:" + state})
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// isMonitored returns whether a unit matches the allowlist
+func (c *SystemdCheck) isMonitored(name string) bool {
+	for _, pattern := range c.config.Units {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// submitUnit reports the state of a unit, and the restarts and resource
+// usage of the services
+func (c *SystemdCheck) submitUnit(sender aggregator.Sender, conn systemdConn, unit dbus.UnitStatus) {
+	tags := append([]string{"unit:" + unit.Name}, c.config.UnitTags[unit.Name]...)
+
+	active := 0.0
+	status := metrics.ServiceCheckWarning
+	switch unit.ActiveState {
+	case "active":
+		active = 1
+		status = metrics.ServiceCheckOK
+	case "failed":
+		status = metrics.ServiceCheckCritical
+	}
+	sender.Gauge("systemd.unit.active", active, "", tags)
+	sender.ServiceCheck("systemd.unit.state", status, "", tags, fmt.Sprintf("Unit %s is %s (%s)", unit.Name, unit.ActiveState, unit.SubState))
+
+	if path.Ext(unit.Name) != serviceSuffix {
+		return
+	}
+	properties, err := conn.GetUnitTypeProperties(unit.Name, "Service")
+	if err != nil {
+		log.Debugf("Could not get the properties of the service %s: %s", unit.Name, err)
+		return
+	}
+	if restarts, ok := properties["NRestarts"].(uint32); ok {
+		sender.MonotonicCount("systemd.service.restart_count", float64(restarts), "", tags)
+	}
+	// the cgroup accounting values are the max uint64 when it is disabled
+	if cpu, ok := properties["CPUUsageNSec"].(uint64); ok && cpu != math.MaxUint64 {
+		sender.MonotonicCount("systemd.service.cpu_usage_nsec", float64(cpu), "", tags)
+	}
+	if memory, ok := properties["MemoryCurrent"].(uint64); ok && memory != math.MaxUint64 {
+		sender.Gauge("systemd.service.memory_usage", float64(memory), "", tags)
+	}
+	if tasks, ok := properties["TasksCurrent"].(uint64); ok && tasks != math.MaxUint64 {
+		sender.Gauge("systemd.service.tasks", float64(tasks), "", tags)
+	}
+}
+
+func systemdFactory() check.Check {
+	return &SystemdCheck{
+		CheckBase: core.NewCheckBase(systemdCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(systemdCheckName, systemdFactory)
+}
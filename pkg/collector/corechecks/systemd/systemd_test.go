@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package systemd
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+type fakeConn struct {
+	units      []dbus.UnitStatus
+	properties map[string]map[string]interface{}
+	err        error
+}
+
+func (c *fakeConn) ListUnits() ([]dbus.UnitStatus, error) {
+	return c.units, c.err
+}
+
+func (c *fakeConn) GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error) {
+	return c.properties[unit], nil
+}
+
+func (c *fakeConn) Close() {}
+
+func TestSystemdCheck(t *testing.T) {
+	conn := &fakeConn{
+		units: []dbus.UnitStatus{
+			{Name: "nginx.service", ActiveState: "active", SubState: "running"},
+			{Name: "cron.service", ActiveState: "failed", SubState: "failed"},
+			{Name: "docker.socket", ActiveState: "active", SubState: "listening"},
+			{Name: "ssh.service", ActiveState: "active", SubState: "running"},
+		},
+		properties: map[string]map[string]interface{}{
+			"nginx.service": {
+				"NRestarts":     uint32(2),
+				"CPUUsageNSec":  uint64(1500000000),
+				"MemoryCurrent": uint64(4096),
+				"TasksCurrent":  uint64(math.MaxUint64),
+			},
+		},
+	}
+	newSystemdConn = func() (systemdConn, error) { return conn, nil }
+	defer func() { newSystemdConn = func() (systemdConn, error) { return dbus.New() } }()
+
+	check := systemdFactory().(*SystemdCheck)
+	require.NoError(t, check.Configure([]byte("units: [nginx.service, cron.*, docker.socket]\nunit_tags:\n  nginx.service: [team:web]\n"), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	nginxTags := []string{"unit:nginx.service", "team:web"}
+	sender.AssertServiceCheck(t, "systemd.can_connect", metrics.ServiceCheckOK, "", nil, "")
+	sender.AssertMetric(t, "Gauge", "systemd.unit.active", 1, "", nginxTags)
+	sender.AssertMetric(t, "Gauge", "systemd.unit.active", 0, "", []string{"unit:cron.service"})
+	sender.AssertServiceCheck(t, "systemd.unit.state", metrics.ServiceCheckCritical, "", []string{"unit:cron.service"}, "Unit cron.service is failed (failed)")
+	sender.AssertMetric(t, "MonotonicCount", "systemd.service.restart_count", 2, "", nginxTags)
+	sender.AssertMetric(t, "MonotonicCount", "systemd.service.cpu_usage_nsec", 1500000000, "", nginxTags)
+	sender.AssertMetric(t, "Gauge", "systemd.service.memory_usage", 4096, "", nginxTags)
+	sender.AssertNotCalled(t, "Gauge", "systemd.service.tasks", mock.Anything, "", mock.Anything)
+	sender.AssertMetric(t, "Gauge", "systemd.units_by_state", 3, "", []string{"active_state:active"})
+	sender.AssertMetric(t, "Gauge", "systemd.units_by_state", 1, "", []string{"active_state:failed"})
+	// ssh isn't in the allowlist
+	sender.AssertNotCalled(t, "Gauge", "systemd.unit.active", mock.Anything, "", []string{"unit:ssh.service"})
+}
+
+func TestSystemdCheckConnectionError(t *testing.T) {
+	newSystemdConn = func() (systemdConn, error) { return nil, fmt.Errorf("no bus") }
+	defer func() { newSystemdConn = func() (systemdConn, error) { return dbus.New() } }()
+
+	check := systemdFactory().(*SystemdCheck)
+	require.NoError(t, check.Configure([]byte("units: [nginx.service]"), nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+
+	assert.Error(t, check.Run())
+	sender.AssertServiceCheck(t, "systemd.can_connect", metrics.ServiceCheckCritical, "", nil, "no bus")
+}
+
+func TestSystemdCheckInvalidPattern(t *testing.T) {
+	check := systemdFactory().(*SystemdCheck)
+	assert.Error(t, check.Configure([]byte("units: [\"nginx[.service\"]"), nil))
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/metadata/inventories"
+	md "github.com/DataDog/datadog-agent/pkg/metadata"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+)
+
+// InventoriesCollector fills and sends the inventories metadata payload,
+// describing the version, configuration and last runs of the check instances
+type InventoriesCollector struct{}
+
+// Send collects the data needed and submits the payload
+func (hp *InventoriesCollector) Send(s *serializer.Serializer) error {
+	payload := inventories.GetPayload()
+	if err := s.SendMetadata(payload); err != nil {
+		return fmt.Errorf("unable to submit inventories metadata payload, %s", err)
+	}
+	return nil
+}
+
+func init() {
+	md.RegisterCollector("inventories", new(InventoriesCollector))
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// GetPayload builds a payload of the metadata of every check instance
+func GetPayload() *Payload {
+	hostname, _ := util.GetHostname()
+	return getPayload(hostname, runner.GetCheckStats())
+}
+
+func getPayload(hostname string, checkStats map[check.ID]*check.Stats) *Payload {
+	checkMetadata := make(CheckMetadata)
+	for _, stats := range checkStats {
+		checkMetadata[stats.CheckName] = append(checkMetadata[stats.CheckName], &CheckInstanceMetadata{
+			ID:                   string(stats.CheckID),
+			Version:              stats.CheckVersion,
+			ConfigDigest:         stats.CheckConfigDigest,
+			TotalRuns:            stats.TotalRuns,
+			AverageExecutionTime: stats.AverageExecutionTime,
+			LastExecutionTime:    stats.LastExecutionTime,
+			MetricSamples:        stats.MetricSamples,
+			LastError:            stats.LastError,
+		})
+	}
+	// keep the payload stable from one run to the other
+	for _, instances := range checkMetadata {
+		sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	}
+
+	return &Payload{
+		Hostname:      hostname,
+		Timestamp:     time.Now().UnixNano(),
+		CheckMetadata: checkMetadata,
+	}
+}
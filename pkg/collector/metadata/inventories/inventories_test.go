@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+func TestGetPayload(t *testing.T) {
+	checkStats := map[check.ID]*check.Stats{
+		"redis:b": {
+			CheckName:            "redis",
+			CheckVersion:         "1.5.0",
+			CheckConfigDigest:    "b",
+			CheckID:              "redis:b",
+			TotalRuns:            3,
+			AverageExecutionTime: 20,
+			LastExecutionTime:    10,
+			MetricSamples:        42,
+			LastError:            "connection refused",
+		},
+		"redis:a": {CheckName: "redis", CheckID: "redis:a", CheckConfigDigest: "a"},
+		"cpu":     {CheckName: "cpu", CheckID: "cpu"},
+	}
+
+	payload := getPayload("my-host", checkStats)
+	assert.Equal(t, "my-host", payload.Hostname)
+	require.Len(t, payload.CheckMetadata, 2)
+	require.Len(t, payload.CheckMetadata["redis"], 2)
+	assert.Equal(t, "redis:a", payload.CheckMetadata["redis"][0].ID)
+	assert.Equal(t, &CheckInstanceMetadata{
+		ID:                   "redis:b",
+		Version:              "1.5.0",
+		ConfigDigest:         "b",
+		TotalRuns:            3,
+		AverageExecutionTime: 20,
+		LastExecutionTime:    10,
+		MetricSamples:        42,
+		LastError:            "connection refused",
+	}, payload.CheckMetadata["redis"][1])
+	assert.Len(t, payload.CheckMetadata["cpu"], 1)
+
+	_, err := json.Marshal(payload)
+	assert.NoError(t, err)
+}
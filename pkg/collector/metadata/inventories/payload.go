@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+)
+
+// CheckInstanceMetadata holds the metadata and the last run stats of a check instance
+type CheckInstanceMetadata struct {
+	ID                   string `json:"id"`
+	Version              string `json:"version"`
+	ConfigDigest         string `json:"config_digest"`
+	TotalRuns            uint64 `json:"total_runs"`
+	AverageExecutionTime int64  `json:"average_execution_time"`
+	LastExecutionTime    int64  `json:"last_execution_time"`
+	MetricSamples        int64  `json:"metric_samples"`
+	LastError            string `json:"last_error"`
+}
+
+// CheckMetadata maps check names to the metadata of their instances
+type CheckMetadata map[string][]*CheckInstanceMetadata
+
+// Payload handles the JSON unmarshalling of the inventories metadata payload
+type Payload struct {
+	Hostname      string        `json:"hostname"`
+	Timestamp     int64         `json:"timestamp"`
+	CheckMetadata CheckMetadata `json:"check_metadata"`
+}
+
+// MarshalJSON serialization a Payload to JSON
+func (p *Payload) MarshalJSON() ([]byte, error) {
+	// use an alias to avoid infinite recursion while serializing
+	type PayloadAlias Payload
+
+	return json.Marshal((*PayloadAlias)(p))
+}
+
+// Marshal not implemented
+func (p *Payload) Marshal() ([]byte, error) {
+	return nil, fmt.Errorf("Inventories Payload serialization is not implemented")
+}
+
+// SplitPayload breaks the payload into times number of pieces
+func (p *Payload) SplitPayload(times int) ([]marshaler.Marshaler, error) {
+	return nil, fmt.Errorf("Inventories Payload splitting is not implemented")
+}
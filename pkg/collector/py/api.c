@@ -19,7 +19,8 @@ char* MetricTypeNames[] = {
   "MONOTONIC_COUNT",
   "COUNTER",
   "HISTOGRAM",
-  "HISTORATE"
+  "HISTORATE",
+  "DISTRIBUTION"
 };
 
 static PyObject *submit_metric(PyObject *self, PyObject *args) {
@@ -63,6 +63,8 @@ func SubmitMetric(check *C.PyObject, checkID *C.char, mt C.MetricType, name *C.c
 		sender.Histogram(_name, _value, _hostname, _tags)
 	case C.HISTORATE:
 		sender.Historate(_name, _value, _hostname, _tags)
+	case C.DISTRIBUTION:
+		sender.Distribution(_name, _value, _hostname, _tags)
 	}
 
 	return C._none()
@@ -19,7 +19,8 @@ typedef enum {
   COUNTER,
   HISTOGRAM,
   HISTORATE,
-  MT_LAST = HISTORATE
+  DISTRIBUTION,
+  MT_LAST = DISTRIBUTION
 } MetricType;
 
 void initaggregator();
@@ -37,6 +37,8 @@ type PythonCheck struct {
 	config       *python.PyObject
 	interval     time.Duration
 	lastWarnings []error
+	dedicated    bool
+	configDigest string
 }
 
 // NewPythonCheck conveniently creates a PythonCheck instance
@@ -203,6 +205,7 @@ func (c *PythonCheck) getInstance(args, kwargs *python.PyObject) (*python.PyObje
 func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Data) error {
 	// Generate check ID
 	c.id = check.Identify(c, data, initConfig)
+	c.configDigest = check.ConfigDigest(data, initConfig)
 
 	// Unmarshal instances config to a RawConfigMap
 	rawInstances := integration.RawMap{}
@@ -230,6 +233,11 @@ func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Da
 		}
 	}
 
+	// See if the instance asked for a dedicated runner
+	if dedicated, ok := rawInstances["dedicated_runner"].(bool); ok {
+		c.dedicated = dedicated
+	}
+
 	// To be retrocompatible with the Python code, still use an `instance` dictionary
 	// to contain the (now) unique instance for the check
 	conf := make(integration.RawMap)
@@ -303,6 +311,16 @@ func (c *PythonCheck) Interval() time.Duration {
 	return c.interval
 }
 
+// DedicatedRunner returns whether the instance runs on its own runner
+func (c *PythonCheck) DedicatedRunner() bool {
+	return c.dedicated
+}
+
+// ConfigDigest returns a digest of the configuration of the instance
+func (c *PythonCheck) ConfigDigest() string {
+	return c.configDigest
+}
+
 // ID returns the ID of the check
 func (c *PythonCheck) ID() check.ID {
 	return c.id
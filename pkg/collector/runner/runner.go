@@ -6,6 +6,7 @@
 package runner
 
 import (
+	"context"
 	"expvar"
 	"fmt"
 
@@ -56,12 +57,14 @@ type runnerCheckStats struct {
 
 // Runner ...
 type Runner struct {
-	pending          chan check.Check         // The channel where checks come from
-	done             chan bool                // Guard for the main loop
-	runningChecks    map[check.ID]check.Check // The list of checks running
-	m                sync.Mutex               // To control races on runningChecks
-	running          uint32                   // Flag to see if the Runner is, well, running
-	staticNumWorkers bool                     // Flag indicating if numWorkers is dynamically updated
+	pending          chan check.Check              // The channel where checks come from
+	done             chan bool                     // Guard for the main loop
+	runningChecks    map[check.ID]check.Check      // The list of checks running
+	m                sync.Mutex                    // To control races on runningChecks and dedicatedWorkers
+	running          uint32                        // Flag to see if the Runner is, well, running
+	staticNumWorkers bool                          // Flag indicating if numWorkers is dynamically updated
+	checkTimeout     time.Duration                 // Time after which a check run is cancelled, 0 to disable
+	dedicatedWorkers map[check.ID]chan check.Check // The pipes of the workers dedicated to a check
 }
 
 // NewRunner takes the number of desired goroutines processing incoming checks.
@@ -76,8 +79,10 @@ func NewRunner() *Runner {
 		// initialize the channel
 		pending:          make(chan check.Check),
 		runningChecks:    make(map[check.ID]check.Check),
+		dedicatedWorkers: make(map[check.ID]chan check.Check),
 		running:          1,
 		staticNumWorkers: numWorkers != 0,
+		checkTimeout:     time.Duration(config.Datadog.GetInt("check_timeout")) * time.Second,
 	}
 
 	if !r.staticNumWorkers {
@@ -97,7 +102,46 @@ func NewRunner() *Runner {
 func (r *Runner) AddWorker() {
 	runnerStats.Add("Workers", 1)
 	TestWg.Add(1)
-	go r.work()
+	go func() {
+		defer TestWg.Done()
+		defer runnerStats.Add("Workers", -1)
+		r.work(r.pending)
+	}()
+}
+
+// AddDedicatedWorker starts a worker running a single check, outside of the
+// worker pool, and returns the pipe the check is to be sent to. If the check
+// already has a dedicated worker, its pipe is returned.
+func (r *Runner) AddDedicatedWorker(id check.ID) chan<- check.Check {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if pipe, found := r.dedicatedWorkers[id]; found {
+		return pipe
+	}
+
+	pipe := make(chan check.Check)
+	r.dedicatedWorkers[id] = pipe
+	runnerStats.Add("DedicatedWorkers", 1)
+	go func() {
+		defer runnerStats.Add("DedicatedWorkers", -1)
+		r.work(pipe)
+	}()
+	log.Infof("Added a dedicated worker for check %s", id)
+	return pipe
+}
+
+// RemoveDedicatedWorker stops the dedicated worker of a check, once it is done
+// running it. Nothing must be sending to its pipe anymore. If the check has no
+// dedicated worker, this is a noop.
+func (r *Runner) RemoveDedicatedWorker(id check.ID) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if pipe, found := r.dedicatedWorkers[id]; found {
+		close(pipe)
+		delete(r.dedicatedWorkers, id)
+	}
 }
 
 // UpdateNumWorkers checks if the current number of workers is reasonable, and adds more if needed
@@ -153,6 +197,10 @@ func (r *Runner) Stop() {
 
 	// stop checks that are still running
 	r.m.Lock()
+	for id, pipe := range r.dedicatedWorkers {
+		close(pipe)
+		delete(r.dedicatedWorkers, id)
+	}
 	globalDone := make(chan struct{})
 	wg := sync.WaitGroup{}
 	for _, c := range r.runningChecks {
@@ -222,12 +270,10 @@ func (r *Runner) StopCheck(id check.ID) error {
 }
 
 // work waits for checks and run them as long as they arrive on the channel
-func (r *Runner) work() {
+func (r *Runner) work(pending <-chan check.Check) {
 	log.Debug("Ready to process checks...")
-	defer TestWg.Done()
-	defer runnerStats.Add("Workers", -1)
 
-	for check := range r.pending {
+	for check := range pending {
 		// see if the check is already running
 		r.m.Lock()
 		if _, isRunning := r.runningChecks[check.ID()]; isRunning {
@@ -249,10 +295,9 @@ func (r *Runner) work() {
 		}
 
 		// run the check
-		var err error
 		t0 := time.Now()
 
-		err = check.Run()
+		returned, err := r.runCheck(check)
 		longRunning := check.Interval() == 0
 
 		warnings := check.GetWarnings()
@@ -284,13 +329,21 @@ func (r *Runner) work() {
 			sender.Commit()
 		}
 
-		// remove the check from the running list
-		r.m.Lock()
-		delete(r.runningChecks, check.ID())
-		r.m.Unlock()
+		// remove the check from the running list, once it has returned if it
+		// was cancelled, so that it's not run again in the meantime
+		select {
+		case <-returned:
+			r.removeRunningCheck(check)
+		default:
+			cancelled := check
+			go func() {
+				<-returned
+				r.removeRunningCheck(cancelled)
+				log.Infof("Cancelled check %s has returned", cancelled)
+			}()
+		}
 
 		// publish statistics about this run
-		runnerStats.Add("RunningChecks", -1)
 		runnerStats.Add("Runs", 1)
 
 		// HACK: If a long-running check execute successfully we don't want it to
@@ -320,6 +373,48 @@ func (r *Runner) work() {
 	log.Debug("Finished processing checks.")
 }
 
+// runCheck runs a check, cancelling it if it doesn't return within the
+// check timeout. A cancelled check is asked to stop and its run is reported
+// as failed, but its goroutine can't be interrupted: the returned channel is
+// closed once the check has actually returned.
+func (r *Runner) runCheck(c check.Check) (<-chan struct{}, error) {
+	returned := make(chan struct{})
+
+	// long-running checks are not supposed to return
+	if r.checkTimeout <= 0 || c.Interval() == 0 {
+		err := c.Run()
+		close(returned)
+		return returned, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.checkTimeout)
+	defer cancel()
+
+	var err error
+	go func() {
+		err = c.Run()
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+		return returned, err
+	case <-ctx.Done():
+		runnerStats.Add("Timeouts", 1)
+		log.Warnf("Check %s did not return after %v, stopping it", c, r.checkTimeout)
+		go c.Stop()
+		return returned, fmt.Errorf("timeout after %v running check %s", r.checkTimeout, c)
+	}
+}
+
+// removeRunningCheck removes a check from the running list
+func (r *Runner) removeRunningCheck(c check.Check) {
+	r.m.Lock()
+	delete(r.runningChecks, c.ID())
+	r.m.Unlock()
+	runnerStats.Add("RunningChecks", -1)
+}
+
 func shouldLog(id check.ID) (doLog bool, lastLog bool) {
 	checkStats.M.RLock()
 	defer checkStats.M.RUnlock()
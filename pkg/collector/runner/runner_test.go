@@ -140,3 +140,57 @@ func TestStopCheck(t *testing.T) {
 	err = r.StopCheck(c2.ID())
 	assert.Equal(t, "timeout during stop operation on check id TestCheck", err.Error())
 }
+
+type HangingCheck struct {
+	TestCheck
+	stop chan struct{}
+}
+
+func (hc *HangingCheck) Run() error {
+	<-hc.stop
+	return nil
+}
+func (hc *HangingCheck) Stop()          { close(hc.stop) }
+func (hc *HangingCheck) String() string { return "HangingCheck" }
+
+func TestRunCheckTimeout(t *testing.T) {
+	r := &Runner{checkTimeout: 10 * time.Millisecond}
+
+	c1 := &TestCheck{}
+	returned, err := r.runCheck(c1)
+	assert.Nil(t, err)
+	assert.True(t, c1.hasRun)
+	_, open := <-returned
+	assert.False(t, open)
+
+	c2 := &HangingCheck{stop: make(chan struct{})}
+	returned, err = r.runCheck(c2)
+	assert.EqualError(t, err, "timeout after 10ms running check HangingCheck")
+
+	// the cancelled check is asked to stop
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the cancelled check was not stopped")
+	}
+}
+
+func TestDedicatedWorker(t *testing.T) {
+	r := NewRunner()
+	defer r.Stop()
+
+	c := &TestCheck{}
+	pipe := r.AddDedicatedWorker(c.ID())
+	assert.Equal(t, pipe, r.AddDedicatedWorker(c.ID()))
+	assert.Len(t, r.dedicatedWorkers, 1)
+
+	pipe <- c
+	// the check was received, wait for it to be removed from the running list
+	pipe <- &TestCheck{doErr: true}
+	assert.True(t, c.hasRun)
+
+	r.RemoveDedicatedWorker(c.ID())
+	assert.Len(t, r.dedicatedWorkers, 0)
+	// removing it again is a noop
+	r.RemoveDedicatedWorker(c.ID())
+}
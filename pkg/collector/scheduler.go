@@ -178,7 +178,14 @@ func (s *CheckScheduler) GetChecksFromConfigs(configs []integration.Config, popu
 			// skip non check configs.
 			continue
 		}
+		// the checks are indexed by the digest of the config with its secret
+		// handles, the one AutoConfig unschedules it with
 		configDigest := config.Digest()
+		config, err := integration.DecryptConfig(config)
+		if err != nil {
+			log.Errorf("Dropping conf for %q: %s", config.Name, err)
+			continue
+		}
 		checks, err := s.getChecks(config)
 		if err != nil {
 			log.Errorf("Unable to load the check: %v", err)
@@ -196,9 +203,10 @@ func (s *CheckScheduler) GetChecksFromConfigs(configs []integration.Config, popu
 	return allChecks
 }
 
-// isCheckConfig returns true if the config is a check configuration,
+// isCheckConfig returns true if the config is a check configuration to be scheduled locally.
+// Cluster checks are dispatched to the node agents by the cluster agent.
 func isCheckConfig(config integration.Config) bool {
-	return len(config.Instances) > 0
+	return len(config.Instances) > 0 && !config.ClusterCheck
 }
 
 // GetLoaderErrors returns the check loader errors
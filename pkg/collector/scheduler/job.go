@@ -7,6 +7,7 @@ package scheduler
 
 import (
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -16,24 +17,52 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 )
 
+// jobBucket contains the jobs of a queue that are scheduled at the same
+// offset of the interval.
+type jobBucket struct {
+	jobs []check.Check
+}
+
 // jobQueue contains a list of checks (called jobs) that need to be
 // scheduled at a certain interval.
+// The interval is split in one-second buckets, the ticker firing the jobs of
+// one bucket at a time, so that the checks of a queue don't all run at once.
 type jobQueue struct {
-	interval time.Duration
-	stop     chan bool // to stop this queue
-	stopped  chan bool // signals that this queue has stopped
-	ticker   *time.Ticker
-	jobs     []check.Check
-	running  bool
-	health   *health.Handle
-	mu       sync.RWMutex // to protect critical sections in struct's fields
+	interval      time.Duration
+	stop          chan bool // to stop this queue
+	stopped       chan bool // signals that this queue has stopped
+	ticker        *time.Ticker
+	buckets       []*jobBucket
+	currentBucket int
+	pipes         map[check.ID]chan<- check.Check // the pipes of the jobs with a dedicated runner
+	jitter        bool                            // add the jobs to a random bucket
+	spread        bool                            // spread the instances of a check across the buckets
+	rand          *rand.Rand
+	running       bool
+	health        *health.Handle
+	mu            sync.RWMutex // to protect critical sections in struct's fields
 }
 
 // newJobQueue creates a new jobQueue instance
-func newJobQueue(interval time.Duration) *jobQueue {
+func newJobQueue(interval time.Duration, jitter, spread bool) *jobQueue {
+	// Intervals that are not a whole number of seconds get a single bucket
+	nbBuckets := 1
+	if interval%time.Second == 0 {
+		nbBuckets = int(interval / time.Second)
+	}
+	buckets := make([]*jobBucket, nbBuckets)
+	for i := range buckets {
+		buckets[i] = &jobBucket{}
+	}
+
 	return &jobQueue{
 		interval: interval,
-		ticker:   time.NewTicker(time.Duration(interval)),
+		ticker:   time.NewTicker(interval / time.Duration(nbBuckets)),
+		buckets:  buckets,
+		pipes:    make(map[check.ID]chan<- check.Check),
+		jitter:   jitter,
+		spread:   spread,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
 		stop:     make(chan bool),
 		stopped:  make(chan bool),
 		health:   health.Register("collector-queue"),
@@ -41,28 +70,84 @@ func newJobQueue(interval time.Duration) *jobQueue {
 
 }
 
-// addJob is a convenience method to add a check to a queue
-func (jq *jobQueue) addJob(c check.Check) {
+// addJob is a convenience method to add a check to a queue. A check with a
+// dedicated runner is sent to its pipe, nil otherwise.
+func (jq *jobQueue) addJob(c check.Check, pipe chan<- check.Check) {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
-	jq.jobs = append(jq.jobs, c)
+	if pipe != nil {
+		jq.pipes[c.ID()] = pipe
+	}
+
+	bucket := jq.buckets[jq.pickBucket(c)]
+	bucket.jobs = append(bucket.jobs, c)
 }
 
 func (jq *jobQueue) removeJob(id check.ID) error {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
-	for i, c := range jq.jobs {
-		if c.ID() == id {
-			jq.jobs = append(jq.jobs[:i], jq.jobs[i+1:]...)
-			return nil
+	for _, bucket := range jq.buckets {
+		for i, c := range bucket.jobs {
+			if c.ID() == id {
+				bucket.jobs = append(bucket.jobs[:i], bucket.jobs[i+1:]...)
+				delete(jq.pipes, id)
+				return nil
+			}
 		}
 	}
 
 	return fmt.Errorf("check with id %s is not in this Job Queue", id)
 }
 
+// pickBucket returns the index of the bucket a check is added to. When
+// spreading, it is one of the buckets holding the fewest instances of the
+// same check. Ties are broken randomly with jitter, or by taking the first
+// bucket without.
+func (jq *jobQueue) pickBucket(c check.Check) int {
+	candidates := make([]int, 0, len(jq.buckets))
+	if jq.spread {
+		min := -1
+		for i, bucket := range jq.buckets {
+			instances := 0
+			for _, job := range bucket.jobs {
+				if job.String() == c.String() {
+					instances++
+				}
+			}
+			if min == -1 || instances < min {
+				min = instances
+				candidates = candidates[:0]
+			}
+			if instances == min {
+				candidates = append(candidates, i)
+			}
+		}
+	} else {
+		for i := range jq.buckets {
+			candidates = append(candidates, i)
+		}
+	}
+
+	if !jq.jitter {
+		return candidates[0]
+	}
+	return candidates[jq.rand.Intn(len(candidates))]
+}
+
+// jobCount returns the number of jobs in the queue
+func (jq *jobQueue) jobCount() int {
+	jq.mu.RLock()
+	defer jq.mu.RUnlock()
+
+	count := 0
+	for _, bucket := range jq.buckets {
+		count += len(bucket.jobs)
+	}
+	return count
+}
+
 // run schedules the checks in the queue by posting them to the
 // execution pipeline.
 // Not blocking, runs in a new goroutine.
@@ -74,8 +159,8 @@ func (jq *jobQueue) run(out chan<- check.Check) {
 	}()
 }
 
-// waitForTicks enqueues the checks at a tick, and returns whether the queue
-// should listen to the following tick (or stop)
+// waitForTicks enqueues the checks of the current bucket at a tick, and
+// returns whether the queue should listen to the following tick (or stop)
 func (jq *jobQueue) waitForTick(out chan<- check.Check) bool {
 	select {
 	case <-jq.stop:
@@ -87,7 +172,22 @@ func (jq *jobQueue) waitForTick(out chan<- check.Check) bool {
 	case <-jq.ticker.C:
 		// normal case, (re)schedule the queue
 		jq.mu.RLock()
-		for _, check := range jq.jobs {
+		bucket := jq.buckets[jq.currentBucket]
+		jq.currentBucket = (jq.currentBucket + 1) % len(jq.buckets)
+		for _, check := range bucket.jobs {
+			// a dedicated runner only runs this check: if it's busy, the
+			// check is still running and this run is skipped. This never
+			// blocks the queue on a slow check.
+			if pipe, found := jq.pipes[check.ID()]; found {
+				select {
+				case pipe <- check:
+					log.Debugf("Enqueuing check %s on its dedicated runner for queue %d", check, jq.interval)
+				default:
+					log.Debugf("Check %s is still running on its dedicated runner, skip execution...", check)
+				}
+				continue
+			}
+
 			// sending to `out` is blocking, we need to constantly check that someone
 			// isn't asking to stop this queue
 			select {
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FIXTURE
+type TestInstance struct {
+	TestCheck
+	name string
+	id   string
+}
+
+func (c *TestInstance) String() string { return c.name }
+func (c *TestInstance) ID() check.ID   { return check.ID(c.id) }
+
+func bucketSizes(jq *jobQueue) []int {
+	sizes := make([]int, len(jq.buckets))
+	for i, bucket := range jq.buckets {
+		sizes[i] = len(bucket.jobs)
+	}
+	return sizes
+}
+
+func TestNewJobQueueBuckets(t *testing.T) {
+	jq := newJobQueue(15*time.Second, false, false)
+	assert.Len(t, jq.buckets, 15)
+
+	// Not a whole number of seconds
+	jq = newJobQueue(1500*time.Millisecond, false, false)
+	assert.Len(t, jq.buckets, 1)
+}
+
+func TestAddJobNoJitter(t *testing.T) {
+	jq := newJobQueue(5*time.Second, false, false)
+	for i := 0; i < 3; i++ {
+		jq.addJob(&TestInstance{name: "check", id: string(rune('a' + i))}, nil)
+	}
+	// Without jitter, all the checks run at the same time
+	assert.Equal(t, []int{3, 0, 0, 0, 0}, bucketSizes(jq))
+	assert.Equal(t, 3, jq.jobCount())
+
+	require.NoError(t, jq.removeJob("b"))
+	assert.Equal(t, 2, jq.jobCount())
+	assert.Error(t, jq.removeJob("b"))
+}
+
+func TestAddJobSpread(t *testing.T) {
+	jq := newJobQueue(4*time.Second, true, true)
+	for i := 0; i < 8; i++ {
+		jq.addJob(&TestInstance{name: "check", id: string(rune('a' + i))}, nil)
+	}
+	// The instances of a check are evenly spread
+	assert.Equal(t, []int{2, 2, 2, 2}, bucketSizes(jq))
+
+	// Another check is spread independently
+	jq.addJob(&TestInstance{name: "other", id: "other"}, nil)
+	assert.Equal(t, 9, jq.jobCount())
+
+	jq = newJobQueue(4*time.Second, false, true)
+	for i := 0; i < 3; i++ {
+		jq.addJob(&TestInstance{name: "check", id: string(rune('a' + i))}, nil)
+	}
+	jq.addJob(&TestInstance{name: "other", id: "other"}, nil)
+	assert.Equal(t, []int{2, 1, 1, 0}, bucketSizes(jq))
+}
+
+func TestWaitForTickBuckets(t *testing.T) {
+	jq := newJobQueue(2*time.Second, false, true)
+	jq.ticker.Stop()
+	tick := make(chan time.Time, 1)
+	jq.ticker.C = tick
+	// Drain the health pings the handle is created with
+	for len(jq.health.C) > 0 {
+		<-jq.health.C
+	}
+
+	first := &TestInstance{name: "check", id: "first"}
+	second := &TestInstance{name: "check", id: "second"}
+	jq.addJob(first, nil)
+	jq.addJob(second, nil)
+
+	out := make(chan check.Check, 2)
+	for _, expected := range []check.Check{first, second, first} {
+		tick <- time.Now()
+		assert.True(t, jq.waitForTick(out))
+		assert.Len(t, out, 1)
+		assert.Equal(t, expected, <-out)
+	}
+}
+
+func TestWaitForTickDedicated(t *testing.T) {
+	jq := newJobQueue(time.Second, false, false)
+	jq.ticker.Stop()
+	tick := make(chan time.Time, 1)
+	jq.ticker.C = tick
+	for len(jq.health.C) > 0 {
+		<-jq.health.C
+	}
+
+	shared := &TestInstance{name: "check", id: "shared"}
+	dedicated := &TestInstance{name: "slow", id: "dedicated"}
+	pipe := make(chan check.Check, 1)
+	jq.addJob(dedicated, pipe)
+	jq.addJob(shared, nil)
+
+	out := make(chan check.Check, 2)
+	tick <- time.Now()
+	assert.True(t, jq.waitForTick(out))
+	assert.Equal(t, dedicated, <-pipe)
+	assert.Equal(t, shared, <-out)
+
+	// the dedicated runner is busy, the run is skipped without blocking
+	pipe <- dedicated
+	tick <- time.Now()
+	assert.True(t, jq.waitForTick(out))
+	assert.Len(t, pipe, 1)
+	assert.Equal(t, shared, <-out)
+
+	require.NoError(t, jq.removeJob("dedicated"))
+	assert.Len(t, jq.pipes, 0)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 var (
@@ -43,6 +44,8 @@ type Scheduler struct {
 	running       uint32                      // Flag to see if the scheduler is running
 	cancelOneTime chan bool                   // Used to internally communicate a cancel signal to one-time schedule goroutines
 	wgOneTime     sync.WaitGroup              // WaitGroup to track the exit of one-time schedule goroutines
+	jitter        bool                        // Schedule the checks at a random offset of their interval
+	spread        bool                        // Spread the instances of a check across their interval
 }
 
 // NewScheduler create a Scheduler and returns a pointer to it.
@@ -57,12 +60,25 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 		running:       0,
 		cancelOneTime: make(chan bool),
 		wgOneTime:     sync.WaitGroup{},
+		jitter:        config.Datadog.GetBool("check_scheduling_jitter"),
+		spread:        config.Datadog.GetBool("check_scheduling_spread"),
 	}
 }
 
 // Enter schedules a `Check`s for execution accordingly to the `Check.Interval()` value.
 // If the interval is 0, the check is supposed to run only once.
 func (s *Scheduler) Enter(check check.Check) error {
+	return s.enter(check, nil)
+}
+
+// EnterDedicated schedules a `Check` like `Enter`, but sends it to the pipe of
+// its dedicated runner instead of the shared one. One-time checks are still
+// sent to the shared pipe.
+func (s *Scheduler) EnterDedicated(check check.Check, pipe chan<- check.Check) error {
+	return s.enter(check, pipe)
+}
+
+func (s *Scheduler) enter(check check.Check, pipe chan<- check.Check) error {
 	// enqueue immediately if this is a one-time schedule
 	if check.Interval() == 0 {
 		s.enqueueOnce(check)
@@ -80,11 +96,11 @@ func (s *Scheduler) Enter(check check.Check) error {
 	defer s.mu.Unlock()
 
 	if _, ok := s.jobQueues[check.Interval()]; !ok {
-		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
+		s.jobQueues[check.Interval()] = newJobQueue(check.Interval(), s.jitter, s.spread)
 		s.startQueue(s.jobQueues[check.Interval()])
 		schedulerQueuesCount.Add(1)
 	}
-	s.jobQueues[check.Interval()].addJob(check)
+	s.jobQueues[check.Interval()].addJob(check, pipe)
 	// map each check to the Job Queue it was assigned to
 	s.checkToQueue[check.ID()] = s.jobQueues[check.Interval()]
 
@@ -240,7 +256,8 @@ func expQueues(s *Scheduler) func() interface{} {
 		for interval, queue := range s.jobQueues {
 			queueStats := map[string]interface{}{
 				"Interval": interval / time.Second,
-				"Size":     len(queue.jobs),
+				"Buckets":  len(queue.buckets),
+				"Size":     queue.jobCount(),
 			}
 			queues = append(queues, queueStats)
 		}
@@ -78,21 +78,21 @@ func TestEnter(t *testing.T) {
 	c.intl = 1 * time.Second
 	s.Enter(c)
 	assert.Len(t, s.jobQueues, 1)
-	assert.Len(t, s.jobQueues[c.intl].jobs, 1)
+	assert.Equal(t, 1, s.jobQueues[c.intl].jobCount())
 
 	// schedule another, same interval
 	c = &TestCheck{intl: c.intl}
 	s.Enter(c)
 	assert.Len(t, s.jobQueues, 1)
-	assert.Len(t, s.jobQueues[c.intl].jobs, 2)
+	assert.Equal(t, 2, s.jobQueues[c.intl].jobCount())
 
 	// schedule again the previous plus another with different interval
 	s.Enter(c)
 	c = &TestCheck{intl: 20 * time.Second}
 	s.Enter(c)
 	assert.Len(t, s.jobQueues, 2)
-	assert.Len(t, s.jobQueues[1*time.Second].jobs, 3)
-	assert.Len(t, s.jobQueues[c.intl].jobs, 1)
+	assert.Equal(t, 3, s.jobQueues[1*time.Second].jobCount())
+	assert.Equal(t, 1, s.jobQueues[c.intl].jobCount())
 }
 
 func TestCancel(t *testing.T) {
@@ -103,7 +103,7 @@ func TestCancel(t *testing.T) {
 	s.Enter(c)
 	s.Run()
 	s.Cancel(c.ID())
-	assert.Len(t, s.jobQueues[c.intl].jobs, 0)
+	assert.Equal(t, 0, s.jobQueues[c.intl].jobCount())
 }
 
 func TestRun(t *testing.T) {
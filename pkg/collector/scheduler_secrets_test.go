@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+)
+
+type recordingLoader struct {
+	configs []integration.Config
+}
+
+func (l *recordingLoader) Load(config integration.Config) ([]check.Check, error) {
+	l.configs = append(l.configs, config)
+	return []check.Check{}, nil
+}
+
+func TestGetChecksFromConfigsDecryptsSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	backend := filepath.Join(dir, "backend.sh")
+	script := "#!/bin/sh\necho '{\"db_password\": {\"value\": \"s3cr3t\"}}'\n"
+	require.NoError(t, ioutil.WriteFile(backend, []byte(script), 0700))
+	secrets.Init(backend, nil, 5, 1024)
+	defer secrets.Init("", nil, 5, 1024)
+
+	loader := &recordingLoader{}
+	s := CheckScheduler{
+		configToChecks: make(map[string][]check.ID),
+		loaders:        []check.Loader{loader},
+	}
+	config := integration.Config{
+		Name:      "postgres",
+		Instances: []integration.Data{integration.Data("password: ENC[db_password]\n")},
+		Entity:    "docker://a1b2c3",
+	}
+	s.GetChecksFromConfigs([]integration.Config{config}, true)
+
+	require.Len(t, loader.configs, 1)
+	assert.Equal(t, "password: s3cr3t\n", string(loader.configs[0].Instances[0]))
+	// the scheduled config keeps its secret handle
+	assert.Equal(t, "password: ENC[db_password]\n", string(config.Instances[0]))
+}
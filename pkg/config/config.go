@@ -7,6 +7,7 @@ package config
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -41,17 +42,19 @@ type MetadataProviders struct {
 
 // ConfigurationProviders helps unmarshalling `config_providers` config param
 type ConfigurationProviders struct {
-	Name        string `mapstructure:"name"`
-	Polling     bool   `mapstructure:"polling"`
-	TemplateURL string `mapstructure:"template_url"`
-	TemplateDir string `mapstructure:"template_dir"`
-	Username    string `mapstructure:"username"`
-	Password    string `mapstructure:"password"`
-	CAFile      string `mapstructure:"ca_file"`
-	CAPath      string `mapstructure:"ca_path"`
-	CertFile    string `mapstructure:"cert_file"`
-	KeyFile     string `mapstructure:"key_file"`
-	Token       string `mapstructure:"token"`
+	Name         string            `mapstructure:"name"`
+	Polling      bool              `mapstructure:"polling"`
+	PollInterval int               `mapstructure:"poll_interval"`
+	TemplateURL  string            `mapstructure:"template_url"`
+	TemplateDir  string            `mapstructure:"template_dir"`
+	Username     string            `mapstructure:"username"`
+	Password     string            `mapstructure:"password"`
+	CAFile       string            `mapstructure:"ca_file"`
+	CAPath       string            `mapstructure:"ca_path"`
+	CertFile     string            `mapstructure:"cert_file"`
+	KeyFile      string            `mapstructure:"key_file"`
+	Token        string            `mapstructure:"token"`
+	Headers      map[string]string `mapstructure:"headers"`
 }
 
 // Listeners helps unmarshalling `listeners` config param
@@ -59,6 +62,54 @@ type Listeners struct {
 	Name string `mapstructure:"name"`
 }
 
+// SNMPListenerConfig helps unmarshalling the `snmp_listener` config param
+type SNMPListenerConfig struct {
+	Workers           int          `mapstructure:"workers"`
+	DiscoveryInterval int          `mapstructure:"discovery_interval"`
+	AllowedFailures   int          `mapstructure:"allowed_failures"`
+	Configs           []SNMPConfig `mapstructure:"configs"`
+}
+
+// SNMPConfig holds the SNMP settings of the devices of a subnet scanned by the snmp listener
+type SNMPConfig struct {
+	Network         string `mapstructure:"network"`
+	Port            uint16 `mapstructure:"port"`
+	Version         int    `mapstructure:"snmp_version"`
+	Timeout         int    `mapstructure:"timeout"`
+	Retries         int    `mapstructure:"retries"`
+	Community       string `mapstructure:"community"`
+	User            string `mapstructure:"user"`
+	AuthKey         string `mapstructure:"authentication_key"`
+	AuthProtocol    string `mapstructure:"authentication_protocol"`
+	PrivKey         string `mapstructure:"privacy_key"`
+	PrivProtocol    string `mapstructure:"privacy_protocol"`
+	ContextEngineID string `mapstructure:"context_engine_id"`
+	ContextName     string `mapstructure:"context_name"`
+	ADIdentifier    string `mapstructure:"ad_identifier"`
+}
+
+// MappingProfile helps unmarshalling the `dogstatsd_mapper_profiles` config param
+type MappingProfile struct {
+	Name     string          `mapstructure:"name"`
+	Prefix   string          `mapstructure:"prefix"`
+	Mappings []MetricMapping `mapstructure:"mappings"`
+}
+
+// MetricMapping is a rule of a MappingProfile
+type MetricMapping struct {
+	Match     string            `mapstructure:"match"`
+	MatchType string            `mapstructure:"match_type"`
+	Name      string            `mapstructure:"name"`
+	Tags      map[string]string `mapstructure:"tags"`
+}
+
+// HistogramOverride helps unmarshalling the `histogram_overrides` config param
+type HistogramOverride struct {
+	Prefix      string   `mapstructure:"prefix"`
+	Aggregates  []string `mapstructure:"aggregates"`
+	Percentiles []string `mapstructure:"percentiles"`
+}
+
 // Proxy represents the configuration for proxies in the agent
 type Proxy struct {
 	HTTP    string   `mapstructure:"http"`
@@ -85,6 +136,12 @@ func init() {
 	BindEnvAndSetDefault("skip_ssl_validation", false)
 	Datadog.SetDefault("hostname", "")
 	Datadog.SetDefault("tags", []string{})
+	BindEnvAndSetDefault("expected_tags_duration", 0)
+	// Aggregator: delay the flushes while every endpoint is congested, the forwarder shedding the sketches and events of a congested one
+	BindEnvAndSetDefault("aggregator_backpressure", true)
+	BindEnvAndSetDefault("aggregator_backpressure_max_delayed_flushes", 3)
+	// Aggregator: the timestamped points are sent by batches of this size, without waiting for the flush
+	BindEnvAndSetDefault("aggregator_no_aggregation_batch_size", 2048)
 	Datadog.SetDefault("conf_path", ".")
 	Datadog.SetDefault("confd_path", defaultConfdPath)
 	Datadog.SetDefault("additional_checksd", defaultAdditionalChecksPath)
@@ -106,6 +163,10 @@ func init() {
 	Datadog.SetDefault("enable_metadata_collection", true)
 	Datadog.SetDefault("enable_gohai", true)
 	Datadog.SetDefault("check_runners", int64(1))
+	BindEnvAndSetDefault("check_scheduling_jitter", true)
+	BindEnvAndSetDefault("check_scheduling_spread", false)
+	BindEnvAndSetDefault("check_timeout", 0)
+	BindEnvAndSetDefault("max_metrics_per_instance", 0)
 	Datadog.SetDefault("auth_token_file_path", "")
 	Datadog.SetDefault("bind_host", "localhost")
 	BindEnvAndSetDefault("hostname_fqdn", false)
@@ -152,9 +213,15 @@ func init() {
 	Datadog.SetDefault("histogram_aggregates", []string{"max", "median", "avg", "count"})
 	Datadog.SetDefault("histogram_percentiles", []string{"0.95"})
 	// Serializer
-	Datadog.SetDefault("use_v2_api.series", false)
+	BindEnvAndSetDefault("use_v2_api.series", false)
 	Datadog.SetDefault("use_v2_api.events", false)
-	Datadog.SetDefault("use_v2_api.service_checks", false)
+	BindEnvAndSetDefault("use_v2_api.service_checks", false)
+	// Serializer: compressor of the payloads, the default one of the build if empty
+	BindEnvAndSetDefault("serializer_compressor_kind", "")
+	// Serializer: write a copy of the payloads to the disk, for debugging and auditing
+	BindEnvAndSetDefault("serializer_audit_enabled", false)
+	BindEnvAndSetDefault("serializer_audit_path", filepath.Join(defaultRunPath, "payloads_audit"))
+	BindEnvAndSetDefault("serializer_audit_max_files", 1000)
 	// Serializer: allow user to blacklist any kind of payload to be sent
 	BindEnvAndSetDefault("enable_payloads.events", true)
 	BindEnvAndSetDefault("enable_payloads.series", true)
@@ -165,27 +232,58 @@ func init() {
 	// Forwarder
 	Datadog.SetDefault("forwarder_timeout", 20)
 	Datadog.SetDefault("forwarder_retry_queue_max_size", 30)
+	BindEnvAndSetDefault("forwarder_telemetry_enabled", true)
+	BindEnvAndSetDefault("forwarder_apikey_validation_interval", 60) // in minutes
+	BindEnvAndSetDefault("forwarder_storage_path", filepath.Join(defaultRunPath, "transactions_to_retry"))
+	BindEnvAndSetDefault("forwarder_storage_max_size_in_bytes", 0) // Notice: 0 means the transactions are not stored on disk
 	BindEnvAndSetDefault("forwarder_num_workers", 1)
 	// Dogstatsd
 	Datadog.SetDefault("use_dogstatsd", true)
 	Datadog.SetDefault("dogstatsd_port", 8125)          // Notice: 0 means UDP port closed
 	Datadog.SetDefault("dogstatsd_buffer_size", 1024*8) // 8KB buffer
 	Datadog.SetDefault("dogstatsd_non_local_traffic", false)
-	Datadog.SetDefault("dogstatsd_socket", "") // Notice: empty means feature disabled
+	Datadog.SetDefault("dogstatsd_socket", "")            // Notice: empty means feature disabled
+	Datadog.SetDefault("dogstatsd_windows_pipe_name", "") // Notice: empty means feature disabled, Windows only
+	// Only SYSTEM, the administrators and the agent user can write to the pipe by default
+	Datadog.SetDefault("dogstatsd_windows_pipe_security_descriptor", "D:P(A;;GA;;;SY)(A;;GA;;;BA)(A;;GA;;;OW)")
 	Datadog.SetDefault("dogstatsd_stats_port", 5000)
 	Datadog.SetDefault("dogstatsd_stats_enable", false)
 	Datadog.SetDefault("dogstatsd_stats_buffer", 10)
 	Datadog.SetDefault("dogstatsd_expiry_seconds", 300)
-	Datadog.SetDefault("dogstatsd_origin_detection", false) // Only supported for socket traffic
+	Datadog.SetDefault("dogstatsd_origin_detection", false) // Only supported for socket and named pipe traffic
+	Datadog.SetDefault("dogstatsd_origin_detection_client", false)
 	Datadog.SetDefault("dogstatsd_so_rcvbuf", 0)
+	Datadog.SetDefault("dogstatsd_udp_batch_size", 32) // Notice: only used on Linux, 1 disables the batched reads
+	Datadog.SetDefault("dogstatsd_mapper_cache_size", 1000)
+	BindEnvAndSetDefault("dogstatsd_metric_blocklist", []string{})
+	BindEnvAndSetDefault("dogstatsd_context_limit_per_metric", 0) // Notice: 0 means no limit
+	BindEnvAndSetDefault("dogstatsd_context_limit_strip_tags", false)
+	BindEnvAndSetDefault("dogstatsd_workers_count", 0)  // Notice: 0 means max(2, GOMAXPROCS-2)
+	BindEnvAndSetDefault("dogstatsd_pipeline_count", 1) // number of time samplers aggregating the dogstatsd metrics
+	BindEnvAndSetDefault("dogstatsd_capture_path", filepath.Join(defaultRunPath, "dsd_capture"))
+	BindEnvAndSetDefault("dogstatsd_telemetry_enabled", false)
 	Datadog.SetDefault("statsd_forward_host", "")
 	Datadog.SetDefault("statsd_forward_port", 0)
+	BindEnvAndSetDefault("statsd_forward_addresses", []string{})
 	BindEnvAndSetDefault("statsd_metric_namespace", "")
 	// Autoconfig
 	Datadog.SetDefault("autoconf_template_dir", "/datadog/check_configs")
+	Datadog.SetDefault("ad_config_poll_interval", int64(10)) // in seconds
 	Datadog.SetDefault("exclude_pause_container", true)
 	Datadog.SetDefault("ac_include", []string{})
 	Datadog.SetDefault("ac_exclude", []string{})
+	// Containers filtering, the scoped options add to the global ones
+	BindEnvAndSetDefault("container_include", []string{})
+	BindEnvAndSetDefault("container_exclude", []string{})
+	BindEnvAndSetDefault("container_include_metrics", []string{})
+	BindEnvAndSetDefault("container_exclude_metrics", []string{})
+	BindEnvAndSetDefault("container_include_logs", []string{})
+	BindEnvAndSetDefault("container_exclude_logs", []string{})
+	BindEnvAndSetDefault("container_include_ad", []string{})
+	BindEnvAndSetDefault("container_exclude_ad", []string{})
+	// Prometheus annotations scraping, empty namespaces means all of them
+	BindEnvAndSetDefault("prometheus_scrape.namespaces", []string{})
+	BindEnvAndSetDefault("prometheus_scrape.metrics", []string{"*"})
 
 	// Docker
 	BindEnvAndSetDefault("docker_query_timeout", int64(5))
@@ -194,6 +292,12 @@ func init() {
 	Datadog.SetDefault("kubernetes_pod_labels_as_tags", map[string]string{})
 	Datadog.SetDefault("kubernetes_pod_annotations_as_tags", map[string]string{})
 	Datadog.SetDefault("kubernetes_node_labels_as_tags", map[string]string{})
+	Datadog.SetDefault("kubernetes_namespace_labels_as_tags", map[string]string{})
+
+	// CRI
+	BindEnvAndSetDefault("cri_socket_path", "")              // empty is autodetect
+	BindEnvAndSetDefault("cri_connection_timeout", int64(1)) // in seconds
+	BindEnvAndSetDefault("cri_query_timeout", int64(5))      // in seconds
 
 	// Kubernetes
 	Datadog.SetDefault("kubernetes_http_kubelet_port", 10255)
@@ -209,8 +313,19 @@ func init() {
 	Datadog.SetDefault("kubernetes_collect_metadata_tags", true)
 	Datadog.SetDefault("kubernetes_metadata_tag_update_freq", 60) // Polling frequency of the Agent to the DCA in seconds (gets the local cache if the DCA is disabled)
 	BindEnvAndSetDefault("kubernetes_apiserver_client_timeout", 10)
+	BindEnvAndSetDefault("kubernetes_apiserver_request_timeout", 2) // Timeout in seconds of each HTTP request to the API Server
+	BindEnvAndSetDefault("kubernetes_apiserver_client_qps", 0)      // 0 uses the client-go default rate limiting
+	BindEnvAndSetDefault("kubernetes_apiserver_client_burst", 0)
+	BindEnvAndSetDefault("kubernetes_apiserver_use_protobuf", false)
+	// List of apiserver URLs or kubeconfig contexts to fail over between, empty means the default endpoint
+	BindEnvAndSetDefault("kubernetes_apiserver_endpoints", []string{})
+	BindEnvAndSetDefault("kubernetes_apiserver_health_check_interval", 15)
 	BindEnvAndSetDefault("kubernetes_apiserver_poll_freq", 30)   // Polling frequency of the DCA (or the agent if the DCA is disabled) to the API Server in seconds
 	BindEnvAndSetDefault("kubernetes_map_services_on_ip", false) // temporary opt-out of the new mapping logic
+	// Restricts the metadata collection to these namespaces, empty means cluster-wide
+	BindEnvAndSetDefault("kubernetes_metadata_namespaces", []string{})
+	// Time to live of the metadata mapper bundles in cache, in seconds
+	BindEnvAndSetDefault("kubernetes_metadata_mapper_ttl", 120)
 
 	// Kube ApiServer
 	Datadog.SetDefault("kubernetes_kubeconfig_path", "")
@@ -223,6 +338,35 @@ func init() {
 	Datadog.SetDefault("cluster_agent.auth_token", "")
 	Datadog.SetDefault("cluster_agent.url", "")
 	Datadog.SetDefault("cluster_agent.kubernetes_service_name", "datadog-cluster-agent")
+	BindEnvAndSetDefault("cluster_agent.forward_to_leader", true)
+	BindEnvAndSetDefault("cluster_agent.tls.cert_file", "")
+	BindEnvAndSetDefault("cluster_agent.tls.key_file", "")
+	BindEnvAndSetDefault("cluster_agent.tls.client_ca_file", "")
+	BindEnvAndSetDefault("cluster_agent.tls.ca_file", "")
+	BindEnvAndSetDefault("cluster_agent.tls.client_cert_file", "")
+	BindEnvAndSetDefault("cluster_agent.tls.client_key_file", "")
+	BindEnvAndSetDefault("cluster_agent.shared_cache.backend", "") // "" for in-process only, or "redis"
+	BindEnvAndSetDefault("cluster_agent.shared_cache.redis_address", "localhost:6379")
+	BindEnvAndSetDefault("cluster_agent.shared_cache.redis_password", "")
+	BindEnvAndSetDefault("cluster_agent.shared_cache.redis_db", 0)
+	BindEnvAndSetDefault("cluster_agent.shared_cache.redis_tls", false)
+	BindEnvAndSetDefault("cluster_agent.shared_cache.redis_tls_skip_verify", false)
+	BindEnvAndSetDefault("cluster_agent.shared_cache.key_prefix", "datadog-cluster-agent/")
+	BindEnvAndSetDefault("cluster_checks.enabled", false)
+	BindEnvAndSetDefault("cluster_checks.node_expiration_timeout", 30) // value in seconds
+	BindEnvAndSetDefault("kube_configmaps_provider.label_selector", "ad.datadoghq.com/checks=true")
+	BindEnvAndSetDefault("cluster_name", "")
+	BindEnvAndSetDefault("orchestrator_explorer.enabled", false)
+	BindEnvAndSetDefault("orchestrator_explorer.collection_interval", 10) // value in seconds
+	BindEnvAndSetDefault("orchestrator_explorer.max_per_message", 100)
+	BindEnvAndSetDefault("admission_controller.enabled", false)
+	BindEnvAndSetDefault("admission_controller.port", 8000)
+	BindEnvAndSetDefault("admission_controller.tls_cert_file", "")
+	BindEnvAndSetDefault("admission_controller.tls_key_file", "")
+	BindEnvAndSetDefault("admission_controller.mutate_unlabelled", false)
+	BindEnvAndSetDefault("admission_controller.inject_config.enabled", true)
+	BindEnvAndSetDefault("admission_controller.inject_config.mode", "hostip") // hostip or socket
+	BindEnvAndSetDefault("admission_controller.inject_config.socket_path", "/var/run/datadog/dsd.socket")
 	Datadog.BindEnv("external_metrics_provider.enabled")
 
 	// ECS
@@ -236,6 +380,7 @@ func init() {
 	// JMXFetch
 	BindEnvAndSetDefault("jmx_custom_jars", []string{})
 	BindEnvAndSetDefault("jmx_use_cgroup_memory_limit", false)
+	BindEnvAndSetDefault("jmx_heartbeat_timeout", 300)
 
 	// Go_expvar server port
 	Datadog.SetDefault("expvar_port", "5000")
@@ -254,8 +399,17 @@ func init() {
 	BindEnvAndSetDefault("logs_config.run_path", defaultRunPath)
 	BindEnvAndSetDefault("logs_config.open_files_limit", 100)
 	BindEnvAndSetDefault("logs_config.container_collect_all", false)
+	BindEnvAndSetDefault("logs_config.k8s_container_use_file", false)
 	BindEnvAndSetDefault("logs_config.frame_size", 9000)
 	BindEnvAndSetDefault("logs_config.tcp_forward_port", -1)
+	BindEnvAndSetDefault("logs_config.use_http", false)
+	BindEnvAndSetDefault("logs_config.http_url", "https://agent-http-intake.logs.datadoghq.com/v1/input")
+	BindEnvAndSetDefault("logs_config.use_compression", true)
+	BindEnvAndSetDefault("logs_config.batch_wait", 5)
+	BindEnvAndSetDefault("logs_config.socks5_proxy_address", "")
+	BindEnvAndSetDefault("logs_config.socks5_proxy_username", "")
+	BindEnvAndSetDefault("logs_config.socks5_proxy_password", "")
+	BindEnvAndSetDefault("logs_config.tag_cardinality", "high")
 
 	// Tagger full cardinality mode
 	// Undocumented opt-in feature for now
@@ -281,9 +435,12 @@ func init() {
 	Datadog.BindEnv("container_proc_root")
 	Datadog.BindEnv("container_cgroup_root")
 	Datadog.BindEnv("dogstatsd_socket")
+	Datadog.BindEnv("dogstatsd_windows_pipe_name")
+	Datadog.BindEnv("dogstatsd_windows_pipe_security_descriptor")
 	Datadog.BindEnv("dogstatsd_stats_port")
 	Datadog.BindEnv("dogstatsd_non_local_traffic")
 	Datadog.BindEnv("dogstatsd_origin_detection")
+	Datadog.BindEnv("dogstatsd_origin_detection_client")
 	Datadog.BindEnv("dogstatsd_so_rcvbuf")
 	Datadog.BindEnv("check_runners")
 	Datadog.BindEnv("expvar_port")
@@ -306,6 +463,7 @@ func init() {
 	Datadog.BindEnv("kubernetes_pod_labels_as_tags")
 	Datadog.BindEnv("kubernetes_pod_annotations_as_tags")
 	Datadog.BindEnv("kubernetes_node_labels_as_tags")
+	Datadog.BindEnv("kubernetes_namespace_labels_as_tags")
 	Datadog.BindEnv("ac_include")
 	Datadog.BindEnv("ac_exclude")
 
@@ -319,9 +477,13 @@ func init() {
 	BindEnvAndSetDefault("external_metrics_provider.polling_freq", 30)
 	BindEnvAndSetDefault("external_metrics_provider.max_age", 60)
 	BindEnvAndSetDefault("external_metrics_provider.bucket_size", 60*5)
+	BindEnvAndSetDefault("external_metrics_provider.queries_per_batch", 35)
+	BindEnvAndSetDefault("external_metrics_provider.cache_ttl", 10)
+	BindEnvAndSetDefault("external_metrics_provider.wpa_controller", false)
 
 	Datadog.BindEnv("forwarder_timeout")
 	Datadog.BindEnv("forwarder_retry_queue_max_size")
+	Datadog.BindEnv("additional_endpoints")
 	Datadog.BindEnv("cloud_foundry")
 	Datadog.BindEnv("bosh_id")
 	Datadog.BindEnv("histogram_aggregates")
@@ -492,6 +654,71 @@ func addAgentVersionToDomain(domain string, app string) (string, error) {
 	return u.String(), nil
 }
 
+// RemoveAgentVersionFromDomain removes the agent version prefix added to the
+// domain of the forwarder, to reach the unversioned endpoints like the API key
+// validation one
+func RemoveAgentVersionFromDomain(domain string, app string) (string, error) {
+	u, err := url.Parse(domain)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := getDomainPrefix(app) + "."
+	if !strings.HasPrefix(u.Host, prefix) {
+		return domain, nil
+	}
+	u.Host = app + "." + strings.TrimPrefix(u.Host, prefix)
+	return u.String(), nil
+}
+
+// GetEndpointProxies returns the proxy settings of the forwarder endpoints
+// overriding the global ones, per domain
+func GetEndpointProxies() (map[string]*Proxy, error) {
+	return getEndpointProxies(Datadog)
+}
+
+// getEndpointProxies implements the logic to extract the proxies per domain
+// from an agent config
+func getEndpointProxies(config *viper.Viper) (map[string]*Proxy, error) {
+	var endpointProxies map[string]Proxy
+	if err := config.UnmarshalKey("endpoint_proxies", &endpointProxies); err != nil {
+		return nil, err
+	}
+
+	proxiesPerDomain := make(map[string]*Proxy, len(endpointProxies))
+	for domain, p := range endpointProxies {
+		updatedDomain, err := addAgentVersionToDomain(domain, "app")
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse url from 'endpoint_proxies' %s: %s", domain, err)
+		}
+		proxy := p
+		proxiesPerDomain[updatedDomain] = &proxy
+	}
+	return proxiesPerDomain, nil
+}
+
+// GetEndpointCompressorKinds returns the compressor of the forwarder endpoints
+// overriding serializer_compressor_kind, per domain
+func GetEndpointCompressorKinds() (map[string]string, error) {
+	return getEndpointCompressorKinds(Datadog)
+}
+
+// getEndpointCompressorKinds implements the logic to extract the compressor
+// kinds per domain from an agent config
+func getEndpointCompressorKinds(config *viper.Viper) (map[string]string, error) {
+	endpointKinds := config.GetStringMapString("endpoint_compressor_kinds")
+
+	kindsPerDomain := make(map[string]string, len(endpointKinds))
+	for domain, kind := range endpointKinds {
+		updatedDomain, err := addAgentVersionToDomain(domain, "app")
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse url from 'endpoint_compressor_kinds' %s: %s", domain, err)
+		}
+		kindsPerDomain[updatedDomain] = kind
+	}
+	return kindsPerDomain, nil
+}
+
 // getMultipleEndpoints implements the logic to extract the api keys per domain from an agent config
 func getMultipleEndpoints(config *viper.Viper) (map[string][]string, error) {
 	ddURL := config.GetString("dd_url")
@@ -507,7 +734,12 @@ func getMultipleEndpoints(config *viper.Viper) (map[string][]string, error) {
 	}
 
 	var additionalEndpoints map[string][]string
-	err = config.UnmarshalKey("additional_endpoints", &additionalEndpoints)
+	if raw, ok := config.Get("additional_endpoints").(string); ok {
+		// set as JSON in the DD_ADDITIONAL_ENDPOINTS environment variable
+		err = json.Unmarshal([]byte(raw), &additionalEndpoints)
+	} else {
+		err = config.UnmarshalKey("additional_endpoints", &additionalEndpoints)
+	}
 	if err != nil {
 		return keysPerDomain, err
 	}
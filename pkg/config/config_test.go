@@ -58,6 +58,82 @@ additional_endpoints:
 	assert.EqualValues(t, expectedMultipleEndpoints, multipleEndpoints)
 }
 
+func TestGetEndpointProxies(t *testing.T) {
+	datadogYaml := `
+dd_url: "https://app.datadoghq.com"
+api_key: fakeapikey
+
+endpoint_proxies:
+  "https://app.datadoghq.com":
+    https: "http://proxy:3128"
+    no_proxy:
+      - host1
+  "https://foo.datadoghq.com": {}
+`
+
+	testConfig := setupViperConf(datadogYaml)
+	proxies, err := getEndpointProxies(testConfig)
+
+	expectedProxies := map[string]*Proxy{
+		"https://" + getDomainPrefix("app") + ".datadoghq.com": {
+			HTTPS:   "http://proxy:3128",
+			NoProxy: []string{"host1"},
+		},
+		"https://foo.datadoghq.com": {},
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedProxies, proxies)
+}
+
+func TestGetEndpointCompressorKinds(t *testing.T) {
+	datadogYaml := `
+dd_url: "https://app.datadoghq.com"
+api_key: fakeapikey
+
+endpoint_compressor_kinds:
+  "https://app.datadoghq.com": zstd
+  "https://foo.datadoghq.com": zlib
+`
+
+	testConfig := setupViperConf(datadogYaml)
+	kinds, err := getEndpointCompressorKinds(testConfig)
+
+	expectedKinds := map[string]string{
+		"https://" + getDomainPrefix("app") + ".datadoghq.com": "zstd",
+		"https://foo.datadoghq.com":                            "zlib",
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedKinds, kinds)
+}
+
+func TestGetMultipleEndpointsFromJSON(t *testing.T) {
+	datadogYaml := `
+dd_url: "https://app.datadoghq.com"
+api_key: fakeapikey
+`
+
+	testConfig := setupViperConf(datadogYaml)
+	// as set by the DD_ADDITIONAL_ENDPOINTS environment variable
+	testConfig.Set("additional_endpoints", `{"https://foo.datadoghq.com": ["someapikey", "otherapikey"]}`)
+
+	multipleEndpoints, err := getMultipleEndpoints(testConfig)
+
+	expectedMultipleEndpoints := map[string][]string{
+		"https://foo.datadoghq.com": {
+			"someapikey",
+			"otherapikey",
+		},
+		"https://" + getDomainPrefix("app") + ".datadoghq.com": {
+			"fakeapikey",
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedMultipleEndpoints, multipleEndpoints)
+}
+
 func TestGetMultipleEndpointsWithNoAdditionalEndpoints(t *testing.T) {
 	datadogYaml := `
 dd_url: "https://app.datadoghq.com"
@@ -158,6 +234,16 @@ func TestAddAgentVersionToDomain(t *testing.T) {
 	assert.Equal(t, "https://app.myproxy.com", newURL)
 }
 
+func TestRemoveAgentVersionFromDomain(t *testing.T) {
+	for _, domain := range []string{"https://app.datadoghq.com", "https://app.datadoghq.eu", "https://app.myproxy.com"} {
+		versioned, err := addAgentVersionToDomain(domain, "app")
+		require.Nil(t, err)
+		unversioned, err := RemoveAgentVersionFromDomain(versioned, "app")
+		require.Nil(t, err)
+		assert.Equal(t, domain, unversioned)
+	}
+}
+
 func TestEnvNestedConfig(t *testing.T) {
 	Datadog.BindEnv("foo.bar.nested")
 	os.Setenv("DD_FOO_BAR_NESTED", "baz")
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"bytes"
+	"strings"
+)
+
+// metricBlocklist holds the metric names dropped by the server. An entry
+// ending with '*' matches the names starting with it, the other entries
+// match the exact name.
+type metricBlocklist struct {
+	names    map[string]struct{}
+	prefixes []string
+}
+
+func newMetricBlocklist(entries []string) *metricBlocklist {
+	b := &metricBlocklist{names: make(map[string]struct{})}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "" || entry == "*":
+			continue
+		case strings.HasSuffix(entry, "*"):
+			b.prefixes = append(b.prefixes, strings.TrimSuffix(entry, "*"))
+		default:
+			b.names[entry] = struct{}{}
+		}
+	}
+	return b
+}
+
+// isEmpty returns whether no name is blocked
+func (b *metricBlocklist) isEmpty() bool {
+	return len(b.names) == 0 && len(b.prefixes) == 0
+}
+
+// isBlocked returns whether the metric message, as sent by the client, must be
+// dropped. Only the name is looked at, so that the message is not parsed.
+func (b *metricBlocklist) isBlocked(message []byte) bool {
+	name := message
+	if sep := bytes.IndexByte(message, ':'); sep != -1 {
+		name = message[:sep]
+	}
+	if _, found := b.names[string(name)]; found {
+		return true
+	}
+	for _, prefix := range b.prefixes {
+		if bytes.HasPrefix(name, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricBlocklist(t *testing.T) {
+	b := newMetricBlocklist([]string{"junk.metric", "noisy.lib.*", " ", "*"})
+	assert.False(t, b.isEmpty())
+
+	for message, blocked := range map[string]bool{
+		"junk.metric:1|c":                 true,
+		"junk.metric.other:1|c":           false,
+		"noisy.lib.requests:1|c|#tag:a":   true,
+		"noisy.lib.:1|g":                  true,
+		"noisy.library:1|g":               false,
+		"daemon:666|g|@0.5":               false,
+		"junk.metric":                     true,
+		"other.junk.metric:1|c":           false,
+		"noisy.lib.requests:1|c\n":        true,
+		"noisy.lib.requests.bytes:1|d":    true,
+		"not.noisy.lib.requests:1|c|#a:b": false,
+	} {
+		assert.Equal(t, blocked, b.isBlocked([]byte(message)), message)
+	}
+
+	assert.True(t, newMetricBlocklist(nil).isEmpty())
+	assert.True(t, newMetricBlocklist([]string{"", "*"}).isEmpty())
+}
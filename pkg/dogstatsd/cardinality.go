@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// cardinalityShards is the number of shards of the limiter, the metric
+	// names are spread between them by hash to limit the lock contention
+	cardinalityShards = 32
+
+	// the FNV-1a 64 bits parameters, see hash/fnv
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// cardinalityLimiter counts the distinct contexts, host and tag sets, of each
+// metric name. Past the limit, the samples of the new contexts are dropped, or
+// sent without their tags if stripTags is set. The counts are reset every
+// window, like the contexts expire in the aggregator.
+type cardinalityLimiter struct {
+	limit     int
+	stripTags bool
+	window    time.Duration
+	shards    [cardinalityShards]*cardinalityShard
+}
+
+// cardinalityShard holds the contexts of the metric names of a shard
+type cardinalityShard struct {
+	m        sync.Mutex
+	resetAt  time.Time
+	contexts map[string]map[uint64]struct{}
+	// notified holds the names already reported by an event, for the
+	// lifetime of the agent
+	notified map[string]struct{}
+}
+
+func newCardinalityLimiter(limit int, stripTags bool, window time.Duration) *cardinalityLimiter {
+	c := &cardinalityLimiter{
+		limit:     limit,
+		stripTags: stripTags,
+		window:    window,
+	}
+	resetAt := time.Now().Add(window)
+	for i := range c.shards {
+		c.shards[i] = &cardinalityShard{
+			resetAt:  resetAt,
+			contexts: make(map[string]map[uint64]struct{}),
+			notified: make(map[string]struct{}),
+		}
+	}
+	return c
+}
+
+// apply returns whether the sample must be sent, its tags being stripped if
+// needed, and the event to send the first time the metric is limited. The
+// limited samples are counted in the MetricCardinalityLimited expvar.
+func (c *cardinalityLimiter) apply(sample *metrics.MetricSample) (bool, *metrics.Event) {
+	key := contextHash(sample)
+	shard := c.shards[hashString(fnvOffset64, sample.Name)%cardinalityShards]
+
+	shard.m.Lock()
+	defer shard.m.Unlock()
+
+	if now := time.Now(); now.After(shard.resetAt) {
+		shard.contexts = make(map[string]map[uint64]struct{})
+		shard.resetAt = now.Add(c.window)
+	}
+
+	contexts, found := shard.contexts[sample.Name]
+	if !found {
+		contexts = make(map[uint64]struct{})
+		shard.contexts[sample.Name] = contexts
+	}
+	if _, found := contexts[key]; found || len(contexts) < c.limit {
+		contexts[key] = struct{}{}
+		return true, nil
+	}
+
+	dogstatsdMetricCardinalityLimit.Add(1)
+	var event *metrics.Event
+	if _, found := shard.notified[sample.Name]; !found {
+		shard.notified[sample.Name] = struct{}{}
+		log.Warnf("Dogstatsd: the metric %s has more than %d contexts, the new ones are limited", sample.Name, c.limit)
+		event = &metrics.Event{
+			Title:          fmt.Sprintf("Dogstatsd limited the cardinality of %s", sample.Name),
+			Text:           fmt.Sprintf("The metric %s has more than %d distinct tag sets, the samples of the new tag sets are %s.", sample.Name, c.limit, c.actionName()),
+			Ts:             time.Now().Unix(),
+			Priority:       metrics.EventPriorityNormal,
+			Host:           sample.Host,
+			AlertType:      metrics.EventAlertTypeWarning,
+			AggregationKey: "dogstatsd_cardinality_limit",
+			SourceTypeName: "dogstatsd",
+		}
+	}
+
+	if !c.stripTags {
+		return false, event
+	}
+	sample.Tags = nil
+	return true, event
+}
+
+func (c *cardinalityLimiter) actionName() string {
+	if c.stripTags {
+		return "sent without tags"
+	}
+	return "dropped"
+}
+
+// contextHash hashes the host and the tags of the sample, regardless of the
+// order of the tags, so that the tags are not sorted for every sample.
+func contextHash(sample *metrics.MetricSample) uint64 {
+	key := hashString(fnvOffset64, sample.Host)
+	for _, tag := range sample.Tags {
+		key += hashString(fnvOffset64, tag)
+	}
+	return key
+}
+
+// hashString returns the FNV-1a hash of s, computed in place to avoid the
+// allocations of hash/fnv
+func hashString(hash uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		hash ^= uint64(s[i])
+		hash *= fnvPrime64
+	}
+	return hash
+}
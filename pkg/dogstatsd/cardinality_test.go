@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func sampleWithTags(name string, tags ...string) *metrics.MetricSample {
+	return &metrics.MetricSample{Name: name, Host: "host", Tags: tags, Mtype: metrics.GaugeType}
+}
+
+func TestCardinalityLimiterDrop(t *testing.T) {
+	c := newCardinalityLimiter(2, false, time.Hour)
+
+	keep, event := c.apply(sampleWithTags("metric", "a:1", "b:1"))
+	assert.True(t, keep)
+	assert.Nil(t, event)
+	keep, _ = c.apply(sampleWithTags("metric", "a:2"))
+	assert.True(t, keep)
+	// the order of the tags does not matter
+	keep, _ = c.apply(sampleWithTags("metric", "b:1", "a:1"))
+	assert.True(t, keep)
+
+	keep, event = c.apply(sampleWithTags("metric", "a:3"))
+	assert.False(t, keep)
+	require.NotNil(t, event)
+	assert.Equal(t, metrics.EventAlertTypeWarning, event.AlertType)
+	assert.Equal(t, "host", event.Host)
+
+	// the event is sent once
+	keep, event = c.apply(sampleWithTags("metric", "a:4"))
+	assert.False(t, keep)
+	assert.Nil(t, event)
+
+	// the known contexts and the other metrics are not limited
+	keep, _ = c.apply(sampleWithTags("metric", "a:2"))
+	assert.True(t, keep)
+	keep, _ = c.apply(sampleWithTags("other", "a:3"))
+	assert.True(t, keep)
+}
+
+func TestCardinalityLimiterStripTags(t *testing.T) {
+	c := newCardinalityLimiter(1, true, time.Hour)
+
+	keep, _ := c.apply(sampleWithTags("metric", "a:1"))
+	assert.True(t, keep)
+
+	sample := sampleWithTags("metric", "a:2")
+	keep, event := c.apply(sample)
+	assert.True(t, keep)
+	assert.NotNil(t, event)
+	assert.Empty(t, sample.Tags)
+}
+
+func TestCardinalityLimiterReset(t *testing.T) {
+	c := newCardinalityLimiter(1, false, time.Hour)
+
+	keep, _ := c.apply(sampleWithTags("metric", "a:1"))
+	assert.True(t, keep)
+	keep, _ = c.apply(sampleWithTags("metric", "a:2"))
+	assert.False(t, keep)
+
+	for _, shard := range c.shards {
+		shard.resetAt = time.Now().Add(-time.Second)
+	}
+	keep, event := c.apply(sampleWithTags("metric", "a:2"))
+	assert.True(t, keep)
+	assert.Nil(t, event)
+}
+
+func TestContextHashDoesNotAllocate(t *testing.T) {
+	sample := sampleWithTags("metric", "a:1", "b:1")
+	allocs := testing.AllocsPerRun(100, func() {
+		contextHash(sample)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func BenchmarkCardinalityLimiter(b *testing.B) {
+	c := newCardinalityLimiter(1000, false, time.Hour)
+	samples := []*metrics.MetricSample{
+		sampleWithTags("metric.a", "env:prod", "service:web"),
+		sampleWithTags("metric.b", "env:prod", "service:db"),
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.apply(samples[i%len(samples)])
+			i++
+		}
+	})
+}
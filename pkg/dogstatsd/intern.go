@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+// stringInterner returns the same string for the same bytes, so that the metric
+// names and tags, usually a stable set, are only allocated once instead of once
+// per packet. It is cleared when full.
+// It is not thread safe, each worker holds its own. A nil stringInterner
+// allocates a new string on every call.
+type stringInterner struct {
+	strings map[string]string
+	maxSize int
+}
+
+func newStringInterner(maxSize int) *stringInterner {
+	return &stringInterner{
+		strings: make(map[string]string),
+		maxSize: maxSize,
+	}
+}
+
+// LoadOrStore returns the string holding the key bytes. The key is not
+// retained, it can be reused by the caller.
+func (i *stringInterner) LoadOrStore(key []byte) string {
+	if i == nil {
+		return string(key)
+	}
+	// the string(key) conversion of a map lookup does not allocate
+	if s, found := i.strings[string(key)]; found {
+		return s
+	}
+	if len(i.strings) >= i.maxSize {
+		i.strings = make(map[string]string)
+	}
+	s := string(key)
+	i.strings[s] = s
+	return s
+}
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringInterner(t *testing.T) {
+	i := newStringInterner(2)
+
+	key := []byte("foo")
+	foo := i.LoadOrStore(key)
+	assert.Equal(t, "foo", foo)
+
+	// the key can be reused by the caller
+	copy(key, "bar")
+	assert.Equal(t, "foo", foo)
+	assert.Equal(t, "bar", i.LoadOrStore(key))
+	assert.Len(t, i.strings, 2)
+
+	assert.Equal(t, "foo", i.LoadOrStore([]byte("foo")))
+	assert.Len(t, i.strings, 2)
+
+	// cleared when full
+	assert.Equal(t, "baz", i.LoadOrStore([]byte("baz")))
+	assert.Len(t, i.strings, 1)
+
+	var nilInterner *stringInterner
+	assert.Equal(t, "foo", nilInterner.LoadOrStore([]byte("foo")))
+}
+
+func BenchmarkStringInterner(b *testing.B) {
+	i := newStringInterner(1000)
+	key := []byte("my.metric.name")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		i.LoadOrStore(key)
+	}
+}
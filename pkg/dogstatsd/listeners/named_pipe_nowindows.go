@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package listeners
+
+import "errors"
+
+// ErrWindowsOnly is emitted on non-windows platforms
+var ErrWindowsOnly = errors.New("only implemented on Windows hosts")
+
+// NamedPipeListener is only implemented on Windows
+type NamedPipeListener struct{}
+
+// NewNamedPipeListener returns a "not implemented" error on non-windows hosts
+func NewNamedPipeListener(packetOut chan *Packet, packetPool *PacketPool) (*NamedPipeListener, error) {
+	return nil, ErrWindowsOnly
+}
+
+// Listen is not implemented on non-windows hosts
+func (l *NamedPipeListener) Listen() {}
+
+// Stop is not implemented on non-windows hosts
+func (l *NamedPipeListener) Stop() {}
@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/Microsoft/go-winio"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	pidToEntityCacheKeyPrefix = "pid_to_entity"
+	pidToEntityCacheDuration  = time.Minute
+)
+
+var (
+	namedPipeExpvars               = expvar.NewMap("dogstatsd-named-pipe")
+	namedPipeOriginDetectionErrors = expvar.Int{}
+	namedPipePacketReadingErrors   = expvar.Int{}
+	namedPipePackets               = expvar.Int{}
+	namedPipeBytes                 = expvar.Int{}
+	namedPipePacketQueueFull       = expvar.Int{}
+	namedPipeConnections           = expvar.Int{}
+
+	procGetNamedPipeClientProcessID = syscall.NewLazyDLL("kernel32.dll").NewProc("GetNamedPipeClientProcessId")
+)
+
+func init() {
+	namedPipeExpvars.Set("OriginDetectionErrors", &namedPipeOriginDetectionErrors)
+	namedPipeExpvars.Set("PacketReadingErrors", &namedPipePacketReadingErrors)
+	namedPipeExpvars.Set("Packets", &namedPipePackets)
+	namedPipeExpvars.Set("Bytes", &namedPipeBytes)
+	namedPipeExpvars.Set("PacketQueueFull", &namedPipePacketQueueFull)
+	namedPipeExpvars.Set("Connections", &namedPipeConnections)
+}
+
+// NamedPipeListener implements the StatsdListener interface for the Windows
+// named pipes in message mode, the local transport of the Windows hosts.
+// The origin of the packets is detected from the PID of the client.
+type NamedPipeListener struct {
+	listener        net.Listener
+	packetOut       chan *Packet
+	packetPool      *PacketPool
+	OriginDetection bool
+
+	m           sync.Mutex
+	connections map[net.Conn]struct{}
+}
+
+// NewNamedPipeListener returns an idle named pipe Statsd listener
+func NewNamedPipeListener(packetOut chan *Packet, packetPool *PacketPool) (*NamedPipeListener, error) {
+	pipeName := config.Datadog.GetString("dogstatsd_windows_pipe_name")
+	if len(pipeName) == 0 {
+		return nil, errors.New("dogstatsd_windows_pipe_name value should not be empty")
+	}
+
+	listener, err := winio.ListenPipe(pipeName, &winio.PipeConfig{
+		SecurityDescriptor: config.Datadog.GetString("dogstatsd_windows_pipe_security_descriptor"),
+		MessageMode:        true,
+		InputBufferSize:    int32(config.Datadog.GetInt("dogstatsd_buffer_size")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't listen: %s", err)
+	}
+
+	l := &NamedPipeListener{
+		listener:        listener,
+		packetOut:       packetOut,
+		packetPool:      packetPool,
+		OriginDetection: config.Datadog.GetBool("dogstatsd_origin_detection"),
+		connections:     make(map[net.Conn]struct{}),
+	}
+	log.Debugf("dogstatsd-named-pipe: %s successfully initialized", pipeName)
+	return l, nil
+}
+
+// Listen accepts the clients, each one is read in its own goroutine.
+// Should be called in its own goroutine.
+func (l *NamedPipeListener) Listen() {
+	log.Infof("dogstatsd-named-pipe: starting to listen on %s", l.listener.Addr())
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if err == winio.ErrPipeListenerClosed {
+				return
+			}
+			log.Errorf("dogstatsd-named-pipe: error accepting a client: %v", err)
+			continue
+		}
+
+		l.m.Lock()
+		l.connections[conn] = struct{}{}
+		l.m.Unlock()
+		namedPipeConnections.Add(1)
+		go l.handleConnection(conn)
+	}
+}
+
+func (l *NamedPipeListener) handleConnection(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		l.m.Lock()
+		delete(l.connections, conn)
+		l.m.Unlock()
+		namedPipeConnections.Add(-1)
+	}()
+
+	// the client process does not change for the connection
+	origin := NoOrigin
+	if l.OriginDetection {
+		var err error
+		origin, err = processNamedPipeOrigin(conn)
+		if err != nil {
+			log.Warnf("dogstatsd-named-pipe: error processing origin, data will not be tagged : %v", err)
+			namedPipeOriginDetectionErrors.Add(1)
+		}
+	}
+
+	for {
+		packet := l.packetPool.Get()
+		// each read returns a single message in message mode
+		n, err := conn.Read(packet.buffer)
+		if err != nil {
+			l.packetPool.Put(packet)
+			if err == io.EOF || err == winio.ErrFileClosed {
+				return
+			}
+			log.Errorf("dogstatsd-named-pipe: error reading packet: %v", err)
+			namedPipePacketReadingErrors.Add(1)
+			return
+		}
+
+		namedPipePackets.Add(1)
+		namedPipeBytes.Add(int64(n))
+		packet.Contents = packet.buffer[:n]
+		packet.Origin = origin
+		sendPacket(l.packetOut, packet, &namedPipePacketQueueFull)
+	}
+}
+
+// Stop closes the pipe and the client connections
+func (l *NamedPipeListener) Stop() {
+	l.listener.Close()
+	l.m.Lock()
+	defer l.m.Unlock()
+	for conn := range l.connections {
+		conn.Close()
+	}
+}
+
+// processNamedPipeOrigin returns the container entity of the client process
+func processNamedPipeOrigin(conn net.Conn) (string, error) {
+	handle, err := pipeHandle(conn)
+	if err != nil {
+		return NoOrigin, err
+	}
+	var pid uint32
+	r, _, err := procGetNamedPipeClientProcessID.Call(uintptr(handle), uintptr(unsafe.Pointer(&pid)))
+	if r == 0 {
+		return NoOrigin, fmt.Errorf("could not get the client PID: %s", err)
+	}
+	return getEntityForPID(int(pid))
+}
+
+// pipeHandle returns the handle of the pipe connection, exposed by the Fd
+// method of the go-winio pipes
+func pipeHandle(conn net.Conn) (syscall.Handle, error) {
+	f, ok := conn.(interface{ Fd() uintptr })
+	if !ok {
+		return 0, fmt.Errorf("could not get the handle of the pipe %T", conn)
+	}
+	return syscall.Handle(f.Fd()), nil
+}
+
+// getEntityForPID returns the container entity name and caches the value for
+// future lookups, the processes of the containers being listed by docker.
+func getEntityForPID(pid int) (string, error) {
+	key := cache.BuildAgentKey(pidToEntityCacheKeyPrefix, strconv.Itoa(pid))
+	if x, found := cache.Cache.Get(key); found {
+		return x.(string), nil
+	}
+
+	id, err := docker.ContainerIDForPIDFromTop(pid)
+	if err != nil {
+		return NoOrigin, err
+	}
+	value := NoOrigin
+	if id != "" {
+		value = fmt.Sprintf("docker://%s", id)
+	}
+	// a host process is cached as NoOrigin too
+	cache.Cache.Set(key, value, pidToEntityCacheDuration)
+	return value, nil
+}
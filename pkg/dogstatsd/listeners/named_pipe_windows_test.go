@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Microsoft/go-winio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const testPipeName = `\\.\pipe\dogstatsd-test`
+
+func TestNamedPipeListenerEmptyName(t *testing.T) {
+	config.Datadog.Set("dogstatsd_windows_pipe_name", "")
+	_, err := NewNamedPipeListener(nil, NewPacketPool(config.Datadog.GetInt("dogstatsd_buffer_size")))
+	assert.Error(t, err)
+}
+
+func TestNamedPipeListenerReceivesPackets(t *testing.T) {
+	config.Datadog.Set("dogstatsd_windows_pipe_name", testPipeName)
+	defer config.Datadog.Set("dogstatsd_windows_pipe_name", "")
+
+	packetOut := make(chan *Packet, 10)
+	s, err := NewNamedPipeListener(packetOut, NewPacketPool(config.Datadog.GetInt("dogstatsd_buffer_size")))
+	require.NoError(t, err)
+	go s.Listen()
+	defer s.Stop()
+
+	timeout := time.Second
+	conn, err := winio.DialPipe(testPipeName, &timeout)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// each write is received as a packet in message mode
+	_, err = conn.Write([]byte("foo:1|c"))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("bar:2|g"))
+	require.NoError(t, err)
+
+	for _, expected := range []string{"foo:1|c", "bar:2|g"} {
+		select {
+		case packet := <-packetOut:
+			assert.Equal(t, expected, string(packet.Contents))
+			assert.Equal(t, NoOrigin, packet.Origin)
+		case <-time.After(2 * time.Second):
+			assert.FailNow(t, "Timeout on receive channel")
+		}
+	}
+}
@@ -5,6 +5,8 @@
 
 package listeners
 
+import "expvar"
+
 // Packet represents a statsd packet ready to process,
 // with its origin metadata if applicable.
 //
@@ -25,3 +27,15 @@ type StatsdListener interface {
 
 // NoOrigin is returned if origin detection is off or failed.
 const NoOrigin = ""
+
+// sendPacket sends the packet to the workers, counting in queueFull the times
+// the queue was full. The listener is then blocked, and the new packets are
+// dropped by the system once the socket receive buffer is full.
+func sendPacket(packetOut chan *Packet, packet *Packet, queueFull *expvar.Int) {
+	select {
+	case packetOut <- packet:
+	default:
+		queueFull.Add(1)
+		packetOut <- packet
+	}
+}
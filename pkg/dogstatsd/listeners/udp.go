@@ -19,10 +19,16 @@ import (
 var (
 	udpExpvars             = expvar.NewMap("dogstatsd-udp")
 	udpPacketReadingErrors = expvar.Int{}
+	udpPackets             = expvar.Int{}
+	udpBytes               = expvar.Int{}
+	udpPacketQueueFull     = expvar.Int{}
 )
 
 func init() {
 	udpExpvars.Set("PacketReadingErrors", &udpPacketReadingErrors)
+	udpExpvars.Set("Packets", &udpPackets)
+	udpExpvars.Set("Bytes", &udpBytes)
+	udpExpvars.Set("PacketQueueFull", &udpPacketQueueFull)
 }
 
 // UDPListener implements the StatsdListener interface for UDP protocol.
@@ -33,6 +39,7 @@ type UDPListener struct {
 	conn       net.PacketConn
 	packetPool *PacketPool
 	packetOut  chan *Packet
+	batchSize  int
 }
 
 // NewUDPListener returns an idle UDP Statsd listener
@@ -49,21 +56,22 @@ func NewUDPListener(packetOut chan *Packet, packetPool *PacketPool) (*UDPListene
 	}
 
 	conn, err = net.ListenPacket("udp", url)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen: %s", err)
+	}
 
 	if rcvbuf := config.Datadog.GetInt("dogstatsd_so_rcvbuf"); rcvbuf != 0 {
 		if err := conn.(*net.UDPConn).SetReadBuffer(rcvbuf); err != nil {
+			conn.Close()
 			return nil, fmt.Errorf("could not set socket rcvbuf: %s", err)
 		}
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("can't listen: %s", err)
-	}
-
 	listener := &UDPListener{
 		packetOut:  packetOut,
 		packetPool: packetPool,
 		conn:       conn,
+		batchSize:  config.Datadog.GetInt("dogstatsd_udp_batch_size"),
 	}
 	log.Debugf("dogstatsd-udp: %s successfully initialized", conn.LocalAddr())
 	return listener, nil
@@ -72,6 +80,9 @@ func NewUDPListener(packetOut chan *Packet, packetPool *PacketPool) (*UDPListene
 // Listen runs the intake loop. Should be called in its own goroutine
 func (l *UDPListener) Listen() {
 	log.Infof("dogstatsd-udp: starting to listen on %s", l.conn.LocalAddr())
+	if l.batchSize > 1 && l.listenBatch() {
+		return
+	}
 	for {
 		packet := l.packetPool.Get()
 		n, _, err := l.conn.ReadFrom(packet.buffer)
@@ -86,8 +97,10 @@ func (l *UDPListener) Listen() {
 			continue
 		}
 
+		udpPackets.Add(1)
+		udpBytes.Add(int64(n))
 		packet.Contents = packet.buffer[:n]
-		l.packetOut <- packet
+		sendPacket(l.packetOut, packet, &udpPacketQueueFull)
 	}
 }
 
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"net"
+	"strings"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// batchReader reads several datagrams with a single recvmmsg syscall.
+// ipv4.Message and ipv6.Message are the same type.
+type batchReader interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// listenBatch runs the intake loop reading the packets by batches of
+// batchSize. It returns false if the connection does not support it.
+func (l *UDPListener) listenBatch() bool {
+	udpConn, ok := l.conn.(*net.UDPConn)
+	if !ok {
+		return false
+	}
+	var reader batchReader
+	if addr, ok := udpConn.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() != nil {
+		reader = ipv4.NewPacketConn(udpConn)
+	} else {
+		reader = ipv6.NewPacketConn(udpConn)
+	}
+	log.Debugf("dogstatsd-udp: reading the packets by batches of %d", l.batchSize)
+
+	packets := make([]*Packet, l.batchSize)
+	messages := make([]ipv4.Message, l.batchSize)
+	for i := range packets {
+		packets[i] = l.packetPool.Get()
+		messages[i].Buffers = [][]byte{packets[i].buffer}
+	}
+
+	for {
+		n, err := reader.ReadBatch(messages, 0)
+		if err != nil {
+			// connection has been closed
+			if strings.HasSuffix(err.Error(), " use of closed network connection") {
+				for _, packet := range packets {
+					l.packetPool.Put(packet)
+				}
+				return true
+			}
+
+			log.Errorf("dogstatsd-udp: error reading packets: %v", err)
+			udpPacketReadingErrors.Add(1)
+			continue
+		}
+
+		for i := 0; i < n; i++ {
+			packet := packets[i]
+			packet.Contents = packet.buffer[:messages[i].N]
+			udpPackets.Add(1)
+			udpBytes.Add(int64(messages[i].N))
+			sendPacket(l.packetOut, packet, &udpPacketQueueFull)
+
+			// the sent packet belongs to the workers now
+			packets[i] = l.packetPool.Get()
+			messages[i].Buffers[0] = packets[i].buffer
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestUDPReceiveBatch(t *testing.T) {
+	for _, nonLocal := range []bool{false, true} {
+		t.Run(fmt.Sprintf("non local traffic %v", nonLocal), func(t *testing.T) {
+			port, err := getAvailableUDPPort()
+			require.Nil(t, err)
+			config.Datadog.SetDefault("dogstatsd_port", port)
+			config.Datadog.SetDefault("dogstatsd_non_local_traffic", nonLocal)
+			defer config.Datadog.SetDefault("dogstatsd_non_local_traffic", false)
+			config.Datadog.SetDefault("dogstatsd_udp_batch_size", 4)
+			defer config.Datadog.SetDefault("dogstatsd_udp_batch_size", 32)
+
+			packetChannel := make(chan *Packet, 10)
+			s, err := NewUDPListener(packetChannel, packetPoolUDP)
+			require.NoError(t, err)
+			require.Equal(t, 4, s.batchSize)
+
+			go s.Listen()
+			defer s.Stop()
+			conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", port))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			// more packets than the batch size
+			for i := 0; i < 6; i++ {
+				conn.Write([]byte(fmt.Sprintf("daemon:%d|g", i)))
+			}
+
+			for i := 0; i < 6; i++ {
+				select {
+				case packet := <-packetChannel:
+					assert.Equal(t, fmt.Sprintf("daemon:%d|g", i), string(packet.Contents))
+				case <-time.After(2 * time.Second):
+					require.FailNow(t, "Timeout on receive channel")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !linux
+
+package listeners
+
+// listenBatch is only supported on Linux, the packets are read one by one
+func (l *UDPListener) listenBatch() bool {
+	return false
+}
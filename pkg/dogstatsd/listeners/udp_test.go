@@ -7,6 +7,7 @@
 package listeners
 
 import (
+	"expvar"
 	"fmt"
 	"net"
 	"strconv"
@@ -175,3 +176,19 @@ func getLocalIP() string {
 	}
 	return ""
 }
+
+func TestSendPacketQueueFull(t *testing.T) {
+	packetOut := make(chan *Packet, 1)
+	queueFull := expvar.Int{}
+
+	sendPacket(packetOut, &Packet{}, &queueFull)
+	assert.Equal(t, int64(0), queueFull.Value())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-packetOut
+	}()
+	sendPacket(packetOut, &Packet{}, &queueFull)
+	assert.Equal(t, int64(1), queueFull.Value())
+	assert.Len(t, packetOut, 1)
+}
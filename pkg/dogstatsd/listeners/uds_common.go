@@ -22,12 +22,17 @@ var (
 	udsExpvars               = expvar.NewMap("dogstatsd-uds")
 	udsOriginDetectionErrors = expvar.Int{}
 	udsPacketReadingErrors   = expvar.Int{}
+	udsPackets               = expvar.Int{}
+	udsBytes                 = expvar.Int{}
+	udsPacketQueueFull       = expvar.Int{}
 )
 
 func init() {
 	udsExpvars.Set("OriginDetectionErrors", &udsOriginDetectionErrors)
 	udsExpvars.Set("PacketReadingErrors", &udsPacketReadingErrors)
-
+	udsExpvars.Set("Packets", &udsPackets)
+	udsExpvars.Set("Bytes", &udsBytes)
+	udsExpvars.Set("PacketQueueFull", &udsPacketQueueFull)
 }
 
 // UDSListener implements the StatsdListener interface for Unix Domain
@@ -47,6 +52,10 @@ func NewUDSListener(packetOut chan *Packet, packetPool *PacketPool) (*UDSListene
 	socketPath := config.Datadog.GetString("dogstatsd_socket")
 	originDetection := config.Datadog.GetBool("dogstatsd_origin_detection")
 
+	if err := removeStaleSocket(socketPath); err != nil {
+		return nil, fmt.Errorf("dogstatsd-uds: %s", err)
+	}
+
 	address, addrErr := net.ResolveUnixAddr("unixgram", socketPath)
 	if addrErr != nil {
 		return nil, fmt.Errorf("dogstatsd-uds: can't ResolveUnixAddr: %v", addrErr)
@@ -110,13 +119,15 @@ func (l *UDSListener) Listen() {
 			oob := l.oobPool.Get().([]byte)
 			var oobn int
 			n, oobn, _, _, err = l.conn.ReadMsgUnix(packet.buffer, oob)
-			// Extract container id from credentials
-			container, taggingErr := processUDSOrigin(oob[:oobn])
-			if taggingErr != nil {
-				log.Warnf("dogstatsd-uds: error processing origin, data will not be tagged : %v", taggingErr)
-				udsOriginDetectionErrors.Add(1)
-			} else {
-				packet.Origin = container
+			if err == nil {
+				// Extract container id from credentials
+				container, taggingErr := processUDSOrigin(oob[:oobn])
+				if taggingErr != nil {
+					log.Warnf("dogstatsd-uds: error processing origin, data will not be tagged : %v", taggingErr)
+					udsOriginDetectionErrors.Add(1)
+				} else {
+					packet.Origin = container
+				}
 			}
 			// Return the buffer back to the pool for reuse
 			l.oobPool.Put(oob)
@@ -126,6 +137,7 @@ func (l *UDSListener) Listen() {
 		}
 
 		if err != nil {
+			l.packetPool.Put(packet)
 			// connection has been closed
 			if strings.HasSuffix(err.Error(), " use of closed network connection") {
 				return
@@ -136,9 +148,32 @@ func (l *UDSListener) Listen() {
 			continue
 		}
 
+		udsPackets.Add(1)
+		udsBytes.Add(int64(n))
 		packet.Contents = packet.buffer[:n]
-		l.packetOut <- packet
+		sendPacket(l.packetOut, packet, &udsPacketQueueFull)
+	}
+}
+
+// removeStaleSocket removes the socket left by a previous run that did not stop
+// cleanly, so that listening does not fail. It refuses to remove a file that is
+// not a socket, or a socket another process is listening on.
+func removeStaleSocket(socketPath string) error {
+	fi, err := os.Lstat(socketPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s already exists and is not a socket", socketPath)
+	}
+	if conn, err := net.Dial("unixgram", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is already in use", socketPath)
 	}
+	log.Debugf("dogstatsd-uds: removing the stale socket %s", socketPath)
+	return os.Remove(socketPath)
 }
 
 // Stop closes the UDS connection and stops listening
@@ -42,6 +42,34 @@ func TestNewUDSListener(t *testing.T) {
 	assert.Equal(t, "Srwx-w--w-", fi.Mode().String())
 }
 
+func TestNewUDSListenerExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dd-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir) // clean up
+	socketPath := filepath.Join(dir, "dsd.socket")
+	config.Datadog.Set("dogstatsd_socket", socketPath)
+	config.Datadog.Set("dogstatsd_origin_detection", false)
+
+	// A socket in use is not removed
+	inUse, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.Nil(t, err)
+	_, err = NewUDSListener(nil, packetPoolUDS)
+	assert.NotNil(t, err)
+
+	// The socket left by a previous run is replaced
+	inUse.Close()
+	s, err := NewUDSListener(nil, packetPoolUDS)
+	require.Nil(t, err)
+	s.Stop()
+
+	// Neither is a regular file
+	require.Nil(t, ioutil.WriteFile(socketPath, []byte("data"), 0644))
+	_, err = NewUDSListener(nil, packetPoolUDS)
+	assert.NotNil(t, err)
+	_, err = os.Stat(socketPath)
+	assert.Nil(t, err)
+}
+
 func TestStartStopUDSListener(t *testing.T) {
 	dir, err := ioutil.TempDir("", "dd-test-")
 	assert.Nil(t, err)
@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package mapper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	matchTypeWildcard = "wildcard"
+	matchTypeRegex    = "regex"
+)
+
+var (
+	// a wildcard matches a dot-delimited element of the name
+	allowedWildcardMatchPattern = regexp.MustCompile(`^[a-zA-Z0-9\-_*.]+$`)
+	doubleWildcardPattern       = regexp.MustCompile(`\*\*`)
+)
+
+// MetricMapper converts the dot-delimited metric names, e.g. graphite-style,
+// into a metric name and tags, following the rules of the mapping profiles.
+type MetricMapper struct {
+	profiles []mappingProfile
+	cache    *mapperCache
+}
+
+type mappingProfile struct {
+	name     string
+	prefix   string
+	mappings []metricMapping
+}
+
+type metricMapping struct {
+	name  string
+	tags  map[string]string
+	regex *regexp.Regexp
+}
+
+// MapResult is the name and tags a metric name is mapped to
+type MapResult struct {
+	Name    string
+	Tags    []string
+	matched bool
+}
+
+// NewMetricMapper returns a MetricMapper applying the profiles in order, the
+// first matching mapping wins. The results are cached, up to cacheSize names.
+func NewMetricMapper(configProfiles []config.MappingProfile, cacheSize int) (*MetricMapper, error) {
+	profiles := make([]mappingProfile, 0, len(configProfiles))
+	for i, configProfile := range configProfiles {
+		if configProfile.Name == "" {
+			return nil, fmt.Errorf("missing name of the profile %d", i)
+		}
+		if configProfile.Prefix == "" {
+			return nil, fmt.Errorf("missing prefix of the profile %s", configProfile.Name)
+		}
+		profile := mappingProfile{
+			name:     configProfile.Name,
+			prefix:   configProfile.Prefix,
+			mappings: make([]metricMapping, 0, len(configProfile.Mappings)),
+		}
+		for _, configMapping := range configProfile.Mappings {
+			mapping, err := newMetricMapping(configMapping)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mapping of the profile %s: %s", configProfile.Name, err)
+			}
+			profile.mappings = append(profile.mappings, mapping)
+		}
+		profiles = append(profiles, profile)
+	}
+	return &MetricMapper{
+		profiles: profiles,
+		cache:    newMapperCache(cacheSize),
+	}, nil
+}
+
+func newMetricMapping(configMapping config.MetricMapping) (metricMapping, error) {
+	if configMapping.Name == "" {
+		return metricMapping{}, fmt.Errorf("missing name of the mapping matching %q", configMapping.Match)
+	}
+	var pattern string
+	switch configMapping.MatchType {
+	case "", matchTypeWildcard:
+		if !allowedWildcardMatchPattern.MatchString(configMapping.Match) {
+			return metricMapping{}, fmt.Errorf("invalid wildcard match %q, only alphanumerics, '-', '_', '*' and '.' are allowed", configMapping.Match)
+		}
+		if doubleWildcardPattern.MatchString(configMapping.Match) {
+			return metricMapping{}, fmt.Errorf("invalid wildcard match %q, consecutive wildcards are not allowed", configMapping.Match)
+		}
+		pattern = strings.Replace(regexp.QuoteMeta(configMapping.Match), `\*`, `([^.]+)`, -1)
+	case matchTypeRegex:
+		pattern = configMapping.Match
+	default:
+		return metricMapping{}, fmt.Errorf("invalid match type %q, must be %q or %q", configMapping.MatchType, matchTypeWildcard, matchTypeRegex)
+	}
+	// the whole name must match, whatever the alternations of the pattern
+	regex, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return metricMapping{}, fmt.Errorf("invalid match %q: %s", configMapping.Match, err)
+	}
+	return metricMapping{
+		name:  configMapping.Name,
+		tags:  configMapping.Tags,
+		regex: regex,
+	}, nil
+}
+
+// Map returns the name and tags a metric name is mapped to, nil if no mapping matches.
+// The name and the tag values can reference the groups captured by the match: $1, ${1}...
+func (m *MetricMapper) Map(metricName string) *MapResult {
+	if result, found := m.cache.get(metricName); found {
+		if !result.matched {
+			return nil
+		}
+		return result
+	}
+
+	result := m.match(metricName)
+	m.cache.add(metricName, result)
+	if !result.matched {
+		return nil
+	}
+	return result
+}
+
+func (m *MetricMapper) match(metricName string) *MapResult {
+	for _, profile := range m.profiles {
+		if !strings.HasPrefix(metricName, profile.prefix) {
+			continue
+		}
+		for _, mapping := range profile.mappings {
+			submatches := mapping.regex.FindStringSubmatchIndex(metricName)
+			if submatches == nil {
+				continue
+			}
+			name := string(mapping.regex.ExpandString(nil, mapping.name, metricName, submatches))
+			tags := make([]string, 0, len(mapping.tags))
+			for key, tpl := range mapping.tags {
+				value := string(mapping.regex.ExpandString(nil, tpl, metricName, submatches))
+				tags = append(tags, key+":"+value)
+			}
+			sort.Strings(tags)
+			return &MapResult{Name: name, Tags: tags, matched: true}
+		}
+	}
+	return &MapResult{matched: false}
+}
+
+// mapperCache holds the results of the mapping, including the names that did not match.
+// It is cleared when full, as the names sent to dogstatsd are usually a stable set.
+type mapperCache struct {
+	m       sync.RWMutex
+	size    int
+	results map[string]*MapResult
+}
+
+func newMapperCache(size int) *mapperCache {
+	return &mapperCache{
+		size:    size,
+		results: make(map[string]*MapResult),
+	}
+}
+
+func (c *mapperCache) get(metricName string) (*MapResult, bool) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	result, found := c.results[metricName]
+	return result, found
+}
+
+func (c *mapperCache) add(metricName string, result *MapResult) {
+	if c.size <= 0 {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if len(c.results) >= c.size {
+		c.results = make(map[string]*MapResult)
+	}
+	c.results[metricName] = result
+}
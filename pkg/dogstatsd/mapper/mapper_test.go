@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var airflowProfile = config.MappingProfile{
+	Name:   "airflow",
+	Prefix: "airflow.",
+	Mappings: []config.MetricMapping{
+		{
+			Match: "airflow.job.duration.*.*",
+			Name:  "airflow.job.duration",
+			Tags:  map[string]string{"job_name": "$1", "zone": "$2"},
+		},
+		{
+			Match:     `airflow\.dag\.(.*)\.(\w+)_duration`,
+			MatchType: "regex",
+			Name:      "airflow.dag.${2}_duration",
+			Tags:      map[string]string{"dag": "$1"},
+		},
+		{
+			Match: "airflow.job.*",
+			Name:  "airflow.job.other",
+		},
+	},
+}
+
+func TestMetricMapper(t *testing.T) {
+	m, err := NewMetricMapper([]config.MappingProfile{airflowProfile}, 10)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		metricName   string
+		expectedName string
+		expectedTags []string
+	}{
+		{"airflow.job.duration.my_job.us-east-1", "airflow.job.duration", []string{"job_name:my_job", "zone:us-east-1"}},
+		{"airflow.dag.my.dag.task_duration", "airflow.dag.task_duration", []string{"dag:my.dag"}},
+		// The first matching mapping wins
+		{"airflow.job.started", "airflow.job.other", []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.metricName, func(t *testing.T) {
+			result := m.Map(tc.metricName)
+			require.NotNil(t, result)
+			assert.Equal(t, tc.expectedName, result.Name)
+			assert.Equal(t, tc.expectedTags, result.Tags)
+
+			// Served from the cache
+			assert.Equal(t, result, m.Map(tc.metricName))
+		})
+	}
+
+	for _, metricName := range []string{
+		"airflow.job.duration.my_job",  // missing element
+		"airflow.job.duration..zone",   // empty element
+		"other.job.duration.my_job.us", // other prefix
+		"airflow.scheduler",
+	} {
+		assert.Nil(t, m.Map(metricName), metricName)
+		assert.Nil(t, m.Map(metricName), metricName)
+	}
+}
+
+func TestMetricMapperRegexAnchored(t *testing.T) {
+	m, err := NewMetricMapper([]config.MappingProfile{{
+		Name:   "airflow",
+		Prefix: "airflow.",
+		Mappings: []config.MetricMapping{
+			{
+				Match:     `airflow\.pool\.(open)_slots|airflow\.pool\.(used)_slots`,
+				MatchType: "regex",
+				Name:      "airflow.pool.slots",
+			},
+		},
+	}}, 10)
+	require.NoError(t, err)
+
+	assert.NotNil(t, m.Map("airflow.pool.open_slots"))
+	assert.NotNil(t, m.Map("airflow.pool.used_slots"))
+	// each alternative must match the whole name
+	assert.Nil(t, m.Map("airflow.pool.open_slots.extra"))
+	assert.Nil(t, m.Map("airflow.other.airflow.pool.used_slots"))
+}
+
+func TestMetricMapperCacheSize(t *testing.T) {
+	m, err := NewMetricMapper([]config.MappingProfile{airflowProfile}, 2)
+	require.NoError(t, err)
+
+	m.Map("airflow.job.a")
+	m.Map("airflow.job.b")
+	assert.Len(t, m.cache.results, 2)
+	m.Map("airflow.job.c")
+	assert.Len(t, m.cache.results, 1)
+
+	m, err = NewMetricMapper([]config.MappingProfile{airflowProfile}, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, m.Map("airflow.job.a"))
+	assert.Len(t, m.cache.results, 0)
+}
+
+func TestMetricMapperInvalidProfiles(t *testing.T) {
+	testCases := []struct {
+		caseName string
+		profile  config.MappingProfile
+	}{
+		{"missing name", config.MappingProfile{Prefix: "test."}},
+		{"missing prefix", config.MappingProfile{Name: "test"}},
+		{"missing mapping name", config.MappingProfile{Name: "test", Prefix: "test.", Mappings: []config.MetricMapping{{Match: "test.*"}}}},
+		{"invalid wildcard", config.MappingProfile{Name: "test", Prefix: "test.", Mappings: []config.MetricMapping{{Match: "test.(*)", Name: "test"}}}},
+		{"double wildcard", config.MappingProfile{Name: "test", Prefix: "test.", Mappings: []config.MetricMapping{{Match: "test.**", Name: "test"}}}},
+		{"invalid regex", config.MappingProfile{Name: "test", Prefix: "test.", Mappings: []config.MetricMapping{{Match: "test.(", MatchType: "regex", Name: "test"}}}},
+		{"invalid match type", config.MappingProfile{Name: "test", Prefix: "test.", Mappings: []config.MetricMapping{{Match: "test.*", MatchType: "glob", Name: "test"}}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			_, err := NewMetricMapper([]config.MappingProfile{tc.profile}, 10)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"bytes"
+
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	// entityIDTagPrefix is the tag the client libraries set from the pod UID,
+	// e.g. with the downward API. It is never sent as a tag.
+	entityIDTagPrefix = []byte("dd.internal.entity_id:")
+	// containerIDFieldPrefix is the metric field set by the clients knowing their container ID
+	containerIDFieldPrefix = []byte("c:")
+)
+
+// messageOrigin returns the entity the client set in the metric message, from
+// the container ID field first, or from the entity ID tag. It is used on the
+// transports without origin detection, e.g. UDP, to tag the metrics anyway.
+func messageOrigin(message []byte) string {
+	var podUID []byte
+	_, remainder := nextField(message, fieldSeparator)
+	for remainder != nil {
+		var field []byte
+		field, remainder = nextField(remainder, fieldSeparator)
+
+		if bytes.HasPrefix(field, containerIDFieldPrefix) && len(field) > len(containerIDFieldPrefix) {
+			return docker.ContainerIDToEntityName(string(field[len(containerIDFieldPrefix):]))
+		} else if bytes.HasPrefix(field, []byte("#")) {
+			tags := field[1:]
+			for tags != nil {
+				var tag []byte
+				tag, tags = nextField(tags, tagSeparator)
+				if bytes.HasPrefix(tag, entityIDTagPrefix) && len(tag) > len(entityIDTagPrefix) {
+					podUID = tag[len(entityIDTagPrefix):]
+				}
+			}
+		}
+	}
+	if podUID != nil {
+		return kubelet.KubePodPrefix + string(podUID)
+	}
+	return listeners.NoOrigin
+}
+
+// clientOriginTags returns the tags of the entity set in the metric message
+func clientOriginTags(message []byte) []string {
+	origin := messageOrigin(message)
+	if origin == listeners.NoOrigin {
+		return nil
+	}
+	tags, err := tagger.Tag(origin, tagger.IsFullCardinality())
+	if err != nil {
+		log.Debugf("Dogstatsd: could not get the tags of %s: %s", origin, err)
+		return nil
+	}
+	return tags
+}
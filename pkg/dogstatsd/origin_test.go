@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageOrigin(t *testing.T) {
+	for message, origin := range map[string]string{
+		"daemon:666|g":                                               "",
+		"daemon:666|g|#sometag:value":                                "",
+		"daemon:666|g|c:abcdef":                                      "docker://abcdef",
+		"daemon:666|g|#sometag:value|c:abcdef":                       "docker://abcdef",
+		"daemon:666|g|#dd.internal.entity_id:pod-uid,sometag":        "kubernetes_pod://pod-uid",
+		"daemon:666|g|@0.5|#dd.internal.entity_id:pod-uid|T15350000": "kubernetes_pod://pod-uid",
+		"daemon:666|g|#dd.internal.entity_id:pod-uid|c:abcdef":       "docker://abcdef",
+		"daemon:666|g|#dd.internal.entity_id:|c:":                    "",
+	} {
+		assert.Equal(t, origin, messageOrigin([]byte(message)), message)
+	}
+}
+
+func TestParseMetricOriginFields(t *testing.T) {
+	sample, err := parseMetricMessage([]byte("daemon:666|g|@0.5|#sometag:value,dd.internal.entity_id:pod-uid|T1535000000|c:abcdef"), "", "default-hostname", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "daemon", sample.Name)
+	assert.Equal(t, []string{"sometag:value"}, sample.Tags)
+	assert.Equal(t, 0.5, sample.SampleRate)
+}
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -28,6 +29,15 @@ var metricTypes = map[string]metrics.MetricType{
 	"d":  metrics.DistributionType,
 }
 
+// Maximum sizes of the event and service check fields accepted by the intake,
+// the longer fields are truncated and end with truncationMarker.
+const (
+	eventTitleMaxSize          = 100
+	eventTextMaxSize           = 4000
+	serviceCheckMessageMaxSize = 4000
+	truncationMarker           = "..."
+)
+
 var tagSeparator = []byte(",")
 var fieldSeparator = []byte("|")
 var valueSeparator = []byte(":")
@@ -58,8 +68,9 @@ func nextField(slice, sep []byte) ([]byte, []byte) {
 }
 
 // parseTags parses `rawTags` and returns a slice of tags,
-// and, if extractHost is true, the extracted hostname
-func parseTags(rawTags []byte, extractHost bool, defaultHostname string) ([]string, string) {
+// and, if extractHost is true, the extracted hostname.
+// The entity ID tag is dropped.
+func parseTags(rawTags []byte, extractHost bool, defaultHostname string, interner *stringInterner) ([]string, string) {
 	if len(rawTags) == 0 {
 		return nil, defaultHostname
 	}
@@ -72,9 +83,11 @@ func parseTags(rawTags []byte, extractHost bool, defaultHostname string) ([]stri
 	for {
 		tag, remainder = nextField(remainder, tagSeparator)
 		if extractHost && bytes.HasPrefix(tag, []byte("host:")) {
-			host = string(tag[5:])
+			host = interner.LoadOrStore(tag[5:])
+		} else if bytes.HasPrefix(tag, entityIDTagPrefix) {
+			// read by messageOrigin
 		} else {
-			tagsList = append(tagsList, string(tag))
+			tagsList = append(tagsList, interner.LoadOrStore(tag))
 		}
 
 		if remainder == nil {
@@ -111,6 +124,7 @@ func parseServiceCheckMessage(message []byte) (*metrics.ServiceCheck, error) {
 	}
 
 	// Metadata
+	var hostTag string
 	for {
 		var rawMetadataField []byte
 		rawMetadataField, remainder = nextField(remainder, fieldSeparator)
@@ -128,14 +142,21 @@ func parseServiceCheckMessage(message []byte) (*metrics.ServiceCheck, error) {
 		} else if bytes.HasPrefix(rawMetadataField, []byte("h:")) {
 			service.Host = string(rawMetadataField[2:])
 		} else if bytes.HasPrefix(rawMetadataField, []byte("#")) {
-			service.Tags, _ = parseTags(rawMetadataField[1:], false, "")
+			service.Tags, hostTag = parseTags(rawMetadataField[1:], true, "", nil)
 		} else if bytes.HasPrefix(rawMetadataField, []byte("m:")) {
-			service.Message = string(rawMetadataField[2:])
+			message := bytes.Replace(rawMetadataField[2:], []byte("\\n"), []byte("\n"), -1)
+			message = bytes.Replace(message, []byte("m\\:"), []byte("m:"), -1)
+			service.Message = truncate(message, serviceCheckMessageMaxSize)
 		} else {
 			log.Warnf("unknown metadata type: '%s'", rawMetadataField)
 		}
 	}
 
+	// the h: field takes precedence over the host tag
+	if service.Host == "" {
+		service.Host = hostTag
+	}
+
 	return &service, nil
 }
 
@@ -146,7 +167,8 @@ func parseEventMessage(message []byte) (*metrics.Event, error) {
 	//   |p:priority
 	//   |h:hostname
 	//   |t:alert_type
-	//   |s:source_type_nam
+	//   |k:aggregation_key
+	//   |s:source_type_name
 	//   |#tag1,tag2
 	//  ]
 
@@ -169,7 +191,7 @@ func parseEventMessage(message []byte) (*metrics.Event, error) {
 
 	textLen, err := strconv.ParseInt(string(rawLen[1][:len(rawLen[1])-1]), 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("Invalid message format, could not parse text.length: '%s'", rawLen[0])
+		return nil, fmt.Errorf("Invalid message format, could not parse text.length: '%s'", rawLen[1])
 	}
 	if titleLen+textLen+1 > int64(len(message)) {
 		return nil, fmt.Errorf("Invalid message format, title.length and text.length exceed total message length")
@@ -186,11 +208,12 @@ func parseEventMessage(message []byte) (*metrics.Event, error) {
 	event := metrics.Event{
 		Priority:  metrics.EventPriorityNormal,
 		AlertType: metrics.EventAlertTypeInfo,
-		Title:     string(rawTitle),
-		Text:      string(bytes.Replace(rawText, []byte("\\n"), []byte("\n"), -1)),
+		Title:     truncate(bytes.Replace(rawTitle, []byte("\\n"), []byte("\n"), -1), eventTitleMaxSize),
+		Text:      truncate(bytes.Replace(rawText, []byte("\\n"), []byte("\n"), -1), eventTextMaxSize),
 	}
 
 	// Metadata
+	var hostTag string
 	if len(message) > 1 {
 		rawMetadataFields := bytes.Split(message[1:], []byte("|"))
 
@@ -223,22 +246,45 @@ func parseEventMessage(message []byte) (*metrics.Event, error) {
 			} else if bytes.HasPrefix(rawMetadataFields[i], []byte("s:")) {
 				event.SourceTypeName = string(rawMetadataFields[i][2:])
 			} else if bytes.HasPrefix(rawMetadataFields[i], []byte("#")) {
-				event.Tags, _ = parseTags(rawMetadataFields[i][1:], false, "")
+				event.Tags, hostTag = parseTags(rawMetadataFields[i][1:], true, "", nil)
 			} else {
 				log.Warnf("unknown metadata type: '%s'", rawMetadataFields[i])
 			}
 		}
 	}
 
+	// the h: field takes precedence over the host tag
+	if event.Host == "" {
+		event.Host = hostTag
+	}
+
 	return &event, nil
 }
 
-func parseMetricMessage(message []byte, namespace string, defaultHostname string) (*metrics.MetricSample, error) {
+// truncate returns the field as a string of at most maxSize bytes, the
+// truncated fields end with the truncationMarker. A multibyte character is
+// not split.
+func truncate(field []byte, maxSize int) string {
+	if len(field) <= maxSize {
+		return string(field)
+	}
+	end := maxSize - len(truncationMarker)
+	for end > 0 && !utf8.RuneStart(field[end]) {
+		end--
+	}
+	return string(field[:end]) + truncationMarker
+}
+
+// parseMetricMessage parses the message without copying it, the name and tags
+// are loaded from the interner and the sample is taken from the metrics pool.
+func parseMetricMessage(message []byte, namespace string, defaultHostname string, interner *stringInterner) (*metrics.MetricSample, error) {
 	// daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2
 	// daemon:666|g|@0.1|#sometag:somevalue"
+	// daemon:666|g|#sometag:somevalue|T1535000000
+	// daemon:666|g|#sometag:somevalue|c:container_id
 
 	separatorCount := bytes.Count(message, fieldSeparator)
-	if separatorCount < 1 || separatorCount > 3 {
+	if separatorCount < 1 || separatorCount > 5 {
 		return nil, fmt.Errorf("invalid field number for %q", message)
 	}
 
@@ -259,12 +305,13 @@ func parseMetricMessage(message []byte, namespace string, defaultHostname string
 	host := defaultHostname
 	var rawMetadataField []byte
 	sampleRate := 1.0
+	var timestamp float64
 
 	for {
 		rawMetadataField, remainder = nextField(remainder, fieldSeparator)
 
 		if bytes.HasPrefix(rawMetadataField, []byte("#")) {
-			metricTags, host = parseTags(rawMetadataField[1:], true, defaultHostname)
+			metricTags, host = parseTags(rawMetadataField[1:], true, defaultHostname, interner)
 		} else if bytes.HasPrefix(rawMetadataField, []byte("@")) {
 			rawSampleRate := rawMetadataField[1:]
 			var err error
@@ -272,6 +319,12 @@ func parseMetricMessage(message []byte, namespace string, defaultHostname string
 			if err != nil {
 				return nil, fmt.Errorf("invalid sample value for %q", message)
 			}
+		} else if bytes.HasPrefix(rawMetadataField, []byte("T")) {
+			ts, err := strconv.ParseInt(string(rawMetadataField[1:]), 10, 64)
+			if err != nil || ts <= 0 {
+				return nil, fmt.Errorf("invalid timestamp for %q", message)
+			}
+			timestamp = float64(ts)
 		}
 
 		if remainder == nil {
@@ -279,34 +332,39 @@ func parseMetricMessage(message []byte, namespace string, defaultHostname string
 		}
 	}
 
-	metricName := string(rawName)
-	if namespace != "" {
-		metricName = namespace + metricName
-	}
-
 	metricType, ok := metricTypes[string(rawType)]
 	if !ok {
 		return nil, fmt.Errorf("invalid metric type for %q", message)
 	}
 
-	sample := &metrics.MetricSample{
-		Name:       metricName,
-		Mtype:      metricType,
-		Tags:       metricTags,
-		Host:       host,
-		SampleRate: sampleRate,
-		Timestamp:  0,
-	}
-
-	if metricType == metrics.SetType {
-		sample.RawValue = string(rawValue)
-	} else {
-		metricValue, err := strconv.ParseFloat(string(rawValue), 64)
+	var metricValue float64
+	if metricType != metrics.SetType {
+		var err error
+		metricValue, err = strconv.ParseFloat(string(rawValue), 64)
 		if err != nil {
 			return nil, fmt.Errorf("invalid metric value for %q", message)
 		}
+	}
+
+	var metricName string
+	if namespace == "" {
+		metricName = interner.LoadOrStore(rawName)
+	} else {
+		// the prefixed name is built on the stack for most names
+		var nameBuf [128]byte
+		metricName = interner.LoadOrStore(append(append(nameBuf[:0], namespace...), rawName...))
+	}
+
+	sample := metrics.GetMetricSample()
+	sample.Name = metricName
+	sample.Value = metricValue
+	sample.Mtype = metricType
+	sample.Tags = metricTags
+	sample.Host = host
+	sample.SampleRate = sampleRate
+	sample.Timestamp = timestamp
+	if metricType == metrics.SetType {
 		sample.RawValue = string(rawValue)
-		sample.Value = metricValue
 	}
 
 	return sample, nil
@@ -7,7 +7,10 @@ package dogstatsd
 
 import (
 	// stdlib
+	"fmt"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	// 3p
 	"github.com/stretchr/testify/assert"
@@ -65,13 +68,13 @@ func TestGaugePacketCounter(t *testing.T) {
 }
 
 func TestParseGauge(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
 	assert.Equal(t, "daemon", parsed.Name)
 	assert.InEpsilon(t, 666.0, parsed.Value, epsilon)
-	assert.Equal(t, "666", parsed.RawValue)
+	assert.Equal(t, "", parsed.RawValue)
 	assert.Equal(t, metrics.GaugeType, parsed.Mtype)
 	assert.Equal(t, 0, len(parsed.Tags))
 	assert.Equal(t, "default-hostname", parsed.Host)
@@ -79,7 +82,7 @@ func TestParseGauge(t *testing.T) {
 }
 
 func TestParseCounter(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|c"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:21|c"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -92,7 +95,7 @@ func TestParseCounter(t *testing.T) {
 }
 
 func TestParseCounterWithTags(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("custom_counter:1|c|#protocol:http,bench"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("custom_counter:1|c|#protocol:http,bench"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -107,7 +110,7 @@ func TestParseCounterWithTags(t *testing.T) {
 }
 
 func TestParseHistogram(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|h"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:21|h"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -120,7 +123,7 @@ func TestParseHistogram(t *testing.T) {
 }
 
 func TestParseTimer(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|ms"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:21|ms"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -133,7 +136,7 @@ func TestParseTimer(t *testing.T) {
 }
 
 func TestParseSet(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:abc|s"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:abc|s"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -146,7 +149,7 @@ func TestParseSet(t *testing.T) {
 }
 
 func TestParseDistribution(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:3.5|d"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:3.5|d"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -158,7 +161,7 @@ func TestParseDistribution(t *testing.T) {
 }
 
 func TestParseSetUnicode(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:♬†øU†øU¥ºuT0♪|s"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:♬†øU†øU¥ºuT0♪|s"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -171,7 +174,7 @@ func TestParseSetUnicode(t *testing.T) {
 }
 
 func TestParseGaugeWithTags(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -186,7 +189,7 @@ func TestParseGaugeWithTags(t *testing.T) {
 }
 
 func TestParseGaugeWithHostTag(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:my-hostname,sometag2:somevalue2"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:my-hostname,sometag2:somevalue2"), "", "default-hostname", nil)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "daemon", parsed.Name)
@@ -200,7 +203,7 @@ func TestParseGaugeWithHostTag(t *testing.T) {
 }
 
 func TestParseGaugeWithEmptyHostTag(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:,sometag2:somevalue2"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:,sometag2:somevalue2"), "", "default-hostname", nil)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "daemon", parsed.Name)
@@ -214,7 +217,7 @@ func TestParseGaugeWithEmptyHostTag(t *testing.T) {
 }
 
 func TestParseGaugeWithNoTags(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname", nil)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "daemon", parsed.Name)
@@ -226,7 +229,7 @@ func TestParseGaugeWithNoTags(t *testing.T) {
 }
 
 func TestParseGaugeWithSampleRate(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|@0.21"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:666|g|@0.21"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -239,7 +242,7 @@ func TestParseGaugeWithSampleRate(t *testing.T) {
 }
 
 func TestParseGaugeWithPoundOnly(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:666|g|#"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -252,7 +255,7 @@ func TestParseGaugeWithPoundOnly(t *testing.T) {
 }
 
 func TestParseGaugeWithUnicode(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("♬†øU†øU¥ºuT0♪:666|g|#intitulé:T0µ"), "", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("♬†øU†øU¥ºuT0♪:666|g|#intitulé:T0µ"), "", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
@@ -267,42 +270,42 @@ func TestParseGaugeWithUnicode(t *testing.T) {
 
 func TestParseMetricError(t *testing.T) {
 	// not enough information
-	_, err := parseMetricMessage([]byte("daemon:666"), "", "default-hostname")
+	_, err := parseMetricMessage([]byte("daemon:666"), "", "default-hostname", nil)
 	assert.Error(t, err)
 
-	_, err = parseMetricMessage([]byte("daemon:666|"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte("daemon:666|"), "", "default-hostname", nil)
 	assert.Error(t, err)
 
-	_, err = parseMetricMessage([]byte("daemon:|g"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte("daemon:|g"), "", "default-hostname", nil)
 	assert.Error(t, err)
 
-	_, err = parseMetricMessage([]byte(":666|g"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte(":666|g"), "", "default-hostname", nil)
 	assert.Error(t, err)
 
 	// too many value
-	_, err = parseMetricMessage([]byte("daemon:666:777|g"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte("daemon:666:777|g"), "", "default-hostname", nil)
 	assert.Error(t, err)
 
 	// unknown metadata prefix
-	_, err = parseMetricMessage([]byte("daemon:666|g|m:test"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte("daemon:666|g|m:test"), "", "default-hostname", nil)
 	assert.NoError(t, err)
 
 	// invalid value
-	_, err = parseMetricMessage([]byte("daemon:abc|g"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte("daemon:abc|g"), "", "default-hostname", nil)
 	assert.Error(t, err)
 
 	// invalid metric type
-	_, err = parseMetricMessage([]byte("daemon:666|unknown"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte("daemon:666|unknown"), "", "default-hostname", nil)
 	assert.Error(t, err)
 
 	// invalid sample rate
-	_, err = parseMetricMessage([]byte("daemon:666|g|@abc"), "", "default-hostname")
+	_, err = parseMetricMessage([]byte("daemon:666|g|@abc"), "", "default-hostname", nil)
 	assert.Error(t, err)
 }
 
 func TestParseMonokeyBatching(t *testing.T) {
 	// TODO: not implemented
-	// parsed, err := parseMetricMessage([]byte("test_gauge:1.5|g|#tag1:one,tag2:two:2.3|g|#tag3:three:3|g"), "default-hostname")
+	// parsed, err := parseMetricMessage([]byte("test_gauge:1.5|g|#tag1:one,tag2:two:2.3|g|#tag3:three:3|g"), "default-hostname", nil)
 }
 
 func TestEnsureUTF8(t *testing.T) {
@@ -406,6 +409,37 @@ func TestServiceCheckMetadataMessage(t *testing.T) {
 	assert.Equal(t, []string(nil), sc.Tags)
 }
 
+func TestServiceCheckMetadataMessageEscaped(t *testing.T) {
+	sc, err := parseServiceCheckMessage([]byte("_sc|agent.up|0|m:line1\\nline2 m\\: fine"))
+
+	require.Nil(t, err)
+	assert.Equal(t, "line1\nline2 m: fine", sc.Message)
+}
+
+func TestServiceCheckMetadataMessageTruncated(t *testing.T) {
+	message := strings.Repeat("é", serviceCheckMessageMaxSize)
+	sc, err := parseServiceCheckMessage([]byte("_sc|agent.up|0|m:" + message))
+
+	require.Nil(t, err)
+	assert.True(t, len(sc.Message) <= serviceCheckMessageMaxSize)
+	assert.True(t, strings.HasSuffix(sc.Message, truncationMarker))
+	assert.True(t, utf8.ValidString(sc.Message))
+}
+
+func TestServiceCheckHostTag(t *testing.T) {
+	sc, err := parseServiceCheckMessage([]byte("_sc|agent.up|0|#tag1:test,host:my-host,tag2"))
+
+	require.Nil(t, err)
+	assert.Equal(t, "my-host", sc.Host)
+	assert.Equal(t, []string{"tag1:test", "tag2"}, sc.Tags)
+
+	// the h: field takes precedence
+	sc, err = parseServiceCheckMessage([]byte("_sc|agent.up|0|#host:my-host|h:localhost"))
+
+	require.Nil(t, err)
+	assert.Equal(t, "localhost", sc.Host)
+}
+
 func TestServiceCheckMetadataMultiple(t *testing.T) {
 	// all type
 	sc, err := parseServiceCheckMessage([]byte("_sc|agent.up|0|d:21|h:localhost|#tag1:test,tag2|m:this is fine"))
@@ -460,6 +494,38 @@ func TestEventMultilinesText(t *testing.T) {
 	assert.Equal(t, "", e.EventType)
 }
 
+func TestEventMultilinesTitle(t *testing.T) {
+	e, err := parseEventMessage([]byte("_e{11,9}:test\\ntitle|test text"))
+
+	require.Nil(t, err)
+	assert.Equal(t, "test\ntitle", e.Title)
+	assert.Equal(t, "test text", e.Text)
+}
+
+func TestEventTruncated(t *testing.T) {
+	title := strings.Repeat("a", eventTitleMaxSize+1)
+	text := strings.Repeat("b", eventTextMaxSize+1)
+	e, err := parseEventMessage([]byte(fmt.Sprintf("_e{%d,%d}:%s|%s", len(title), len(text), title, text)))
+
+	require.Nil(t, err)
+	assert.Equal(t, strings.Repeat("a", eventTitleMaxSize-len(truncationMarker))+truncationMarker, e.Title)
+	assert.Equal(t, strings.Repeat("b", eventTextMaxSize-len(truncationMarker))+truncationMarker, e.Text)
+}
+
+func TestEventHostTag(t *testing.T) {
+	e, err := parseEventMessage([]byte("_e{10,9}:test title|test text|#tag1,host:my-host"))
+
+	require.Nil(t, err)
+	assert.Equal(t, "my-host", e.Host)
+	assert.Equal(t, []string{"tag1"}, e.Tags)
+
+	// the h: field takes precedence
+	e, err = parseEventMessage([]byte("_e{10,9}:test title|test text|h:localhost|#host:my-host"))
+
+	require.Nil(t, err)
+	assert.Equal(t, "localhost", e.Host)
+}
+
 func TestEventPipeInTitle(t *testing.T) {
 	e, err := parseEventMessage([]byte("_e{10,24}:test|title|test\\line1\\nline2\\nline3"))
 
@@ -670,10 +736,73 @@ func TestEventMetadataMultiple(t *testing.T) {
 }
 
 func TestNamespace(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|ms"), "testNamespace.", "default-hostname")
+	parsed, err := parseMetricMessage([]byte("daemon:21|ms"), "testNamespace.", "default-hostname", nil)
 
 	assert.NoError(t, err)
 
 	assert.Equal(t, "testNamespace.daemon", parsed.Name)
 	assert.Equal(t, "default-hostname", parsed.Host)
 }
+
+func TestParseMetricMessageInterned(t *testing.T) {
+	interner := newStringInterner(10)
+	message := []byte("daemon:666|g|#sometag1:somevalue1,host:my-hostname")
+
+	first, err := parseMetricMessage(message, "testNamespace.", "default-hostname", interner)
+	require.NoError(t, err)
+	firstName := first.Name
+	metrics.PutMetricSample(first)
+
+	// the message buffer is reused by the packet pool
+	copy(message, "xxxxxx")
+	assert.Equal(t, "testNamespace.daemon", firstName)
+
+	parsed, err := parseMetricMessage([]byte("daemon:21|c|#sometag1:somevalue1,host:my-hostname"), "testNamespace.", "default-hostname", interner)
+	require.NoError(t, err)
+
+	assert.Equal(t, "testNamespace.daemon", parsed.Name)
+	assert.Equal(t, []string{"sometag1:somevalue1"}, parsed.Tags)
+	assert.Equal(t, "my-hostname", parsed.Host)
+	assert.InEpsilon(t, 21.0, parsed.Value, epsilon)
+	assert.Equal(t, metrics.CounterType, parsed.Mtype)
+	assert.Equal(t, "", parsed.RawValue)
+	assert.Len(t, interner.strings, 3)
+}
+
+func benchmarkParseMetricMessage(b *testing.B, interner *stringInterner) {
+	message := []byte("daemon.requests.duration:666|h|@0.5|#sometag1:somevalue1,sometag2:somevalue2,host:my-hostname")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		sample, err := parseMetricMessage(message, "namespace.", "default-hostname", interner)
+		if err != nil {
+			b.Fatal(err)
+		}
+		metrics.PutMetricSample(sample)
+	}
+}
+
+func BenchmarkParseMetricMessage(b *testing.B) {
+	benchmarkParseMetricMessage(b, nil)
+}
+
+func BenchmarkParseMetricMessageInterned(b *testing.B) {
+	benchmarkParseMetricMessage(b, newStringInterner(stringInternerMaxSize))
+}
+
+func TestParseMetricTimestamp(t *testing.T) {
+	parsed, err := parseMetricMessage([]byte("daemon:666|g|@0.5|#sometag:somevalue|T1535000000"), "", "default-hostname", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1535000000.0, parsed.Timestamp)
+	assert.Equal(t, []string{"sometag:somevalue"}, parsed.Tags)
+	assert.InEpsilon(t, 0.5, parsed.SampleRate, epsilon)
+
+	parsed, err = parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, parsed.Timestamp)
+
+	_, err = parseMetricMessage([]byte("daemon:666|g|Tabc"), "", "default-hostname", nil)
+	assert.Error(t, err)
+	_, err = parseMetricMessage([]byte("daemon:666|g|T-1"), "", "default-hostname", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// TrafficCapture records the packets received by dogstatsd for a duration
+type TrafficCapture struct {
+	ongoing int32 // accessed atomically, read for every packet
+
+	m      sync.Mutex
+	file   *os.File
+	writer *Writer
+	timer  *time.Timer
+	path   string
+}
+
+// IsOngoing returns whether a capture is ongoing, it is cheap enough to be
+// called for every packet.
+func (tc *TrafficCapture) IsOngoing() bool {
+	return atomic.LoadInt32(&tc.ongoing) == 1
+}
+
+// Start records the packets in a new file of the directory, for the duration.
+// It returns the path of the file.
+func (tc *TrafficCapture) Start(directory string, duration time.Duration) (string, error) {
+	if duration <= 0 {
+		return "", errors.New("the capture duration must be positive")
+	}
+
+	tc.m.Lock()
+	defer tc.m.Unlock()
+	if tc.writer != nil {
+		return "", fmt.Errorf("a capture is already ongoing to %s", tc.path)
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(directory, fmt.Sprintf("datadog-capture-%d", time.Now().Unix()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	writer, err := NewWriter(file)
+	if err != nil {
+		file.Close()
+		return "", err
+	}
+
+	tc.file = file
+	tc.writer = writer
+	tc.path = path
+	tc.timer = time.AfterFunc(duration, tc.Stop)
+	atomic.StoreInt32(&tc.ongoing, 1)
+	log.Infof("Dogstatsd: capturing the traffic to %s for %s", path, duration)
+	return path, nil
+}
+
+// Write records the packet, if a capture is ongoing
+func (tc *TrafficCapture) Write(p CapturedPacket) {
+	tc.m.Lock()
+	defer tc.m.Unlock()
+	if tc.writer == nil {
+		return
+	}
+	if err := tc.writer.Write(p); err != nil {
+		log.Errorf("Dogstatsd: stopping the capture to %s: %s", tc.path, err)
+		tc.stop()
+	}
+}
+
+// Stop ends the ongoing capture, if any
+func (tc *TrafficCapture) Stop() {
+	tc.m.Lock()
+	defer tc.m.Unlock()
+	tc.stop()
+}
+
+// stop closes the file, the lock must be held
+func (tc *TrafficCapture) stop() {
+	if tc.writer == nil {
+		return
+	}
+	atomic.StoreInt32(&tc.ongoing, 0)
+	tc.timer.Stop()
+	if err := tc.writer.Flush(); err != nil {
+		log.Errorf("Dogstatsd: could not write the capture %s: %s", tc.path, err)
+	}
+	tc.file.Close()
+	log.Infof("Dogstatsd: capture to %s done", tc.path)
+	tc.file = nil
+	tc.writer = nil
+	tc.timer = nil
+}
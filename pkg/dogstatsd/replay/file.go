@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// fileHeader starts every capture file, its last byte is the format version
+var fileHeader = []byte("DSDCAP\x00\x01")
+
+// maxRecordSize bounds the size of a record read from a file
+const maxRecordSize = 8 * 1024 * 1024
+
+// CapturedPacket is a dogstatsd packet as received by the server
+type CapturedPacket struct {
+	Timestamp time.Time
+	Origin    string
+	Contents  []byte
+}
+
+// Writer writes the packets to a capture file. Each record holds the
+// timestamp in nanoseconds, the origin and the contents, the sizes being
+// big-endian uint32. It is not thread safe.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter writes the file header and returns a Writer
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(fileHeader); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw}, nil
+}
+
+// Write appends the packet to the file
+func (w *Writer) Write(p CapturedPacket) error {
+	var header [16]byte
+	binary.BigEndian.PutUint64(header[0:8], uint64(p.Timestamp.UnixNano()))
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(p.Origin)))
+	binary.BigEndian.PutUint32(header[12:16], uint32(len(p.Contents)))
+	if _, err := w.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString(p.Origin); err != nil {
+		return err
+	}
+	_, err := w.w.Write(p.Contents)
+	return err
+}
+
+// Flush writes the buffered records
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Reader reads the packets from a capture file
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader checks the file header and returns a Reader
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(fileHeader))
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("could not read the capture header: %s", err)
+	}
+	if string(header) != string(fileHeader) {
+		return nil, errors.New("not a dogstatsd capture file, or unsupported version")
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next packet, io.EOF at the end of the file
+func (r *Reader) Next() (*CapturedPacket, error) {
+	var header [16]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated capture record")
+		}
+		return nil, err
+	}
+	originLen := binary.BigEndian.Uint32(header[8:12])
+	contentsLen := binary.BigEndian.Uint32(header[12:16])
+	if originLen+contentsLen > maxRecordSize {
+		return nil, fmt.Errorf("invalid capture record size %d", originLen+contentsLen)
+	}
+
+	buf := make([]byte, originLen+contentsLen)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, errors.New("truncated capture record")
+	}
+	return &CapturedPacket{
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8]))),
+		Origin:    string(buf[:originLen]),
+		Contents:  buf[originLen:],
+	}, nil
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package replay
+
+import (
+	"io"
+	"time"
+)
+
+// Replay sends the packets of the capture with send, e.g. to the socket of a
+// running dogstatsd. The origin of the packets cannot be replayed. If
+// keepTiming is true, the packets are spaced as they were received, otherwise
+// they are sent as fast as possible, e.g. to benchmark the server.
+// It returns the number of packets sent.
+func Replay(r *Reader, send func([]byte) error, keepTiming bool) (int, error) {
+	var sent int
+	var previous time.Time
+	for {
+		packet, err := r.Next()
+		if err == io.EOF {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+
+		if keepTiming && !previous.IsZero() {
+			if wait := packet.Timestamp.Sub(previous); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		previous = packet.Timestamp
+
+		if err = send(packet.Contents); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterReader(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+
+	now := time.Unix(1500000000, 123)
+	packets := []CapturedPacket{
+		{Timestamp: now, Contents: []byte("daemon:666|g")},
+		{Timestamp: now.Add(time.Millisecond), Origin: "docker://abc", Contents: []byte("daemon:1|c\ndaemon:2|c")},
+	}
+	for _, p := range packets {
+		require.NoError(t, w.Write(p))
+	}
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for _, expected := range packets {
+		p, err := r.Next()
+		require.NoError(t, err)
+		assert.True(t, expected.Timestamp.Equal(p.Timestamp))
+		assert.Equal(t, expected.Origin, p.Origin)
+		assert.Equal(t, expected.Contents, p.Contents)
+	}
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+
+	// truncated record
+	r, err = NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	require.NoError(t, err)
+	_, err = r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader([]byte("daemon:666|g")))
+	assert.Error(t, err)
+}
+
+func TestTrafficCapture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsd-capture")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tc := &TrafficCapture{}
+	assert.False(t, tc.IsOngoing())
+	tc.Write(CapturedPacket{Timestamp: time.Now(), Contents: []byte("ignored:1|c")})
+
+	path, err := tc.Start(dir, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, tc.IsOngoing())
+	_, err = tc.Start(dir, time.Hour)
+	assert.Error(t, err)
+
+	tc.Write(CapturedPacket{Timestamp: time.Now(), Contents: []byte("daemon:1|c")})
+	tc.Write(CapturedPacket{Timestamp: time.Now(), Contents: []byte("daemon:2|c")})
+	tc.Stop()
+	assert.False(t, tc.IsOngoing())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	r, err := NewReader(file)
+	require.NoError(t, err)
+
+	var sent []string
+	count, err := Replay(r, func(contents []byte) error {
+		sent = append(sent, string(contents))
+		return nil
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"daemon:1|c", "daemon:2|c"}, sent)
+}
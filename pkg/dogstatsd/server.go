@@ -11,18 +11,26 @@ import (
 	"fmt"
 	"net"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/replay"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/util"
 )
 
+// stringInternerMaxSize is the number of metric names and tags each worker
+// keeps allocated, it is bounded for the high cardinality tags.
+const stringInternerMaxSize = 4096
+
 var (
 	dogstatsdExpvars                 = expvar.NewMap("dogstatsd")
 	dogstatsdServiceCheckParseErrors = expvar.Int{}
@@ -31,6 +39,9 @@ var (
 	dogstatsdEventPackets            = expvar.Int{}
 	dogstatsdMetricParseErrors       = expvar.Int{}
 	dogstatsdMetricPackets           = expvar.Int{}
+	dogstatsdMetricBlocklisted       = expvar.Int{}
+	dogstatsdMetricCardinalityLimit  = expvar.Int{}
+	dogstatsdForwardErrors           = expvar.Int{}
 )
 
 func init() {
@@ -40,6 +51,9 @@ func init() {
 	dogstatsdExpvars.Set("EventPackets", &dogstatsdEventPackets)
 	dogstatsdExpvars.Set("MetricParseErrors", &dogstatsdMetricParseErrors)
 	dogstatsdExpvars.Set("MetricPackets", &dogstatsdMetricPackets)
+	dogstatsdExpvars.Set("MetricBlocklisted", &dogstatsdMetricBlocklisted)
+	dogstatsdExpvars.Set("MetricCardinalityLimited", &dogstatsdMetricCardinalityLimit)
+	dogstatsdExpvars.Set("ForwardErrors", &dogstatsdForwardErrors)
 }
 
 // Server represent a Dogstatsd server
@@ -55,6 +69,11 @@ type Server struct {
 	defaultHostname  string
 	histToDist       bool
 	histToDistPrefix string
+	mapper           *mapper.MetricMapper
+	blocklist        *metricBlocklist
+	cardinality      *cardinalityLimiter
+	clientOrigin     bool
+	capture          replay.TrafficCapture
 }
 
 // NewServer returns a running Dogstatsd server
@@ -71,7 +90,7 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 
 	packetChannel := make(chan *listeners.Packet, 100)
 	packetPool := listeners.NewPacketPool(config.Datadog.GetInt("dogstatsd_buffer_size"))
-	tmpListeners := make([]listeners.StatsdListener, 0, 2)
+	tmpListeners := make([]listeners.StatsdListener, 0, 3)
 
 	socketPath := config.Datadog.GetString("dogstatsd_socket")
 	if len(socketPath) > 0 {
@@ -91,8 +110,17 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 		}
 	}
 
+	if len(config.Datadog.GetString("dogstatsd_windows_pipe_name")) > 0 {
+		namedPipeListener, err := listeners.NewNamedPipeListener(packetChannel, packetPool)
+		if err != nil {
+			log.Errorf(err.Error())
+		} else {
+			tmpListeners = append(tmpListeners, namedPipeListener)
+		}
+	}
+
 	if len(tmpListeners) == 0 {
-		return nil, fmt.Errorf("listening on neither udp nor socket, please check your configuration")
+		return nil, fmt.Errorf("listening on neither udp, socket nor named pipe, please check your configuration")
 	}
 
 	// check configuration for custom namespace
@@ -106,6 +134,11 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 		log.Errorf("Dogstatsd: unable to determine default hostname: %s", err.Error())
 	}
 
+	metricMapper, err := getMetricMapper()
+	if err != nil {
+		log.Errorf("Dogstatsd: the metrics will not be mapped: %s", err)
+	}
+
 	histToDist := config.Datadog.GetBool("histogram_copy_to_distribution")
 	histToDistPrefix := config.Datadog.GetString("histogram_copy_to_distribution_prefix")
 	s := &Server{
@@ -120,27 +153,43 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 		defaultHostname:  defaultHostname,
 		histToDist:       histToDist,
 		histToDistPrefix: histToDistPrefix,
+		mapper:           metricMapper,
+		clientOrigin:     config.Datadog.GetBool("dogstatsd_origin_detection_client"),
 	}
 
-	forwardHost := config.Datadog.GetString("statsd_forward_host")
-	forwardPort := config.Datadog.GetInt("statsd_forward_port")
-
-	if forwardHost != "" && forwardPort != 0 {
+	if blocklist := newMetricBlocklist(config.Datadog.GetStringSlice("dogstatsd_metric_blocklist")); !blocklist.isEmpty() {
+		s.blocklist = blocklist
+	}
 
-		forwardAddress := fmt.Sprintf("%s:%d", forwardHost, forwardPort)
+	if limit := config.Datadog.GetInt("dogstatsd_context_limit_per_metric"); limit > 0 {
+		s.cardinality = newCardinalityLimiter(limit,
+			config.Datadog.GetBool("dogstatsd_context_limit_strip_tags"),
+			config.Datadog.GetDuration("dogstatsd_expiry_seconds")*time.Second)
+	}
 
+	var forwardConns []net.Conn
+	for _, forwardAddress := range getForwardAddresses() {
 		con, err := net.Dial("udp", forwardAddress)
-
 		if err != nil {
-			log.Warnf("Could not connect to statsd forward host : %s", err)
-		} else {
-			s.packetIn = make(chan *listeners.Packet, 100)
-			go s.forwarder(con, packetChannel)
+			log.Warnf("Could not connect to statsd forward host %s: %s", forwardAddress, err)
+			continue
 		}
+		forwardConns = append(forwardConns, con)
+	}
+	if len(forwardConns) > 0 {
+		s.packetIn = make(chan *listeners.Packet, 100)
+		go s.forwarder(forwardConns, packetChannel)
 	}
 
 	s.handleMessages(metricOut, eventOut, serviceCheckOut)
 
+	dogstatsdExpvars.Set("PacketQueueLength", expvar.Func(func() interface{} {
+		return len(packetChannel)
+	}))
+	if config.Datadog.GetBool("dogstatsd_telemetry_enabled") {
+		go s.telemetry(metricOut)
+	}
+
 	return s, nil
 }
 
@@ -153,11 +202,14 @@ func (s *Server) handleMessages(metricOut chan<- *metrics.MetricSample, eventOut
 		go l.Listen()
 	}
 
-	// Run min(2, GoMaxProcs-2) workers, we dedicate a core to the
+	// Run max(2, GoMaxProcs-2) workers by default, we dedicate a core to the
 	// listener goroutine and another to aggregator + forwarder
-	workers := runtime.GOMAXPROCS(-1) - 2
-	if workers < 2 {
-		workers = 2
+	workers := config.Datadog.GetInt("dogstatsd_workers_count")
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(-1) - 2
+		if workers < 2 {
+			workers = 2
+		}
 	}
 
 	for i := 0; i < workers; i++ {
@@ -165,16 +217,43 @@ func (s *Server) handleMessages(metricOut chan<- *metrics.MetricSample, eventOut
 	}
 }
 
-func (s *Server) forwarder(fcon net.Conn, packetChannel chan *listeners.Packet) {
+// getForwardAddresses returns the statsd servers the packets are forwarded
+// to, from statsd_forward_host and statsd_forward_port, and from
+// statsd_forward_addresses.
+func getForwardAddresses() []string {
+	var addresses []string
+	forwardHost := config.Datadog.GetString("statsd_forward_host")
+	forwardPort := config.Datadog.GetInt("statsd_forward_port")
+	if forwardHost != "" && forwardPort != 0 {
+		addresses = append(addresses, net.JoinHostPort(forwardHost, strconv.Itoa(forwardPort)))
+	}
+	for _, address := range config.Datadog.GetStringSlice("statsd_forward_addresses") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// forwarder duplicates the raw packets to the forward hosts before they are
+// processed. A host failing to receive them is only counted, the packets are
+// processed locally regardless.
+func (s *Server) forwarder(fconns []net.Conn, packetChannel chan *listeners.Packet) {
+	defer func() {
+		for _, fcon := range fconns {
+			fcon.Close()
+		}
+	}()
 	for {
 		select {
 		case <-s.stopChan:
 			return
 		case packet := <-packetChannel:
-			_, err := fcon.Write(packet.Contents)
-
-			if err != nil {
-				log.Warnf("Forwarding packet failed : %s", err)
+			for _, fcon := range fconns {
+				if _, err := fcon.Write(packet.Contents); err != nil {
+					log.Debugf("Forwarding packet to %s failed : %s", fcon.RemoteAddr(), err)
+					dogstatsdForwardErrors.Add(1)
+				}
 			}
 
 			s.packetIn <- packet
@@ -183,6 +262,7 @@ func (s *Server) forwarder(fcon net.Conn, packetChannel chan *listeners.Packet)
 }
 
 func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.Event, serviceCheckOut chan<- metrics.ServiceCheck) {
+	interner := newStringInterner(stringInternerMaxSize)
 	for {
 		select {
 		case <-s.stopChan:
@@ -203,6 +283,14 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 				log.Tracef("Dogstatsd receive: %s", packet.Contents)
 			}
 
+			if s.capture.IsOngoing() {
+				s.capture.Write(replay.CapturedPacket{
+					Timestamp: time.Now(),
+					Origin:    packet.Origin,
+					Contents:  packet.Contents,
+				})
+			}
+
 			for {
 				message := nextMessage(&packet.Contents)
 				if message == nil {
@@ -238,21 +326,44 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 					dogstatsdEventPackets.Add(1)
 					eventOut <- *event
 				} else {
-					sample, err := parseMetricMessage(message, s.metricPrefix, s.defaultHostname)
+					if s.blocklist != nil && s.blocklist.isBlocked(message) {
+						dogstatsdMetricBlocklisted.Add(1)
+						continue
+					}
+					sample, err := parseMetricMessage(message, s.metricPrefix, s.defaultHostname, interner)
 					if err != nil {
 						log.Errorf("Dogstatsd: error parsing metrics: %s", err)
 						dogstatsdMetricParseErrors.Add(1)
 						continue
 					}
+					if s.mapper != nil {
+						s.mapMetric(sample)
+					}
 					if len(originTags) > 0 {
 						sample.Tags = append(sample.Tags, originTags...)
+					} else if s.clientOrigin {
+						sample.Tags = append(sample.Tags, clientOriginTags(message)...)
+					}
+					if s.cardinality != nil {
+						keep, event := s.cardinality.apply(sample)
+						if event != nil {
+							eventOut <- *event
+						}
+						if !keep {
+							metrics.PutMetricSample(sample)
+							continue
+						}
 					}
 					dogstatsdMetricPackets.Add(1)
-					metricOut <- sample
+					// the sample goes back to the pool once aggregated, it is copied before being sent
+					var distSample *metrics.MetricSample
 					if s.histToDist && sample.Mtype == metrics.HistogramType {
-						distSample := sample.Copy()
+						distSample = sample.Copy()
 						distSample.Name = s.histToDistPrefix + distSample.Name
 						distSample.Mtype = metrics.DistributionType
+					}
+					metricOut <- sample
+					if distSample != nil {
 						metricOut <- distSample
 					}
 				}
@@ -263,6 +374,36 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 	}
 }
 
+// getMetricMapper returns the mapper of the dogstatsd_mapper_profiles, nil if none is configured.
+func getMetricMapper() (*mapper.MetricMapper, error) {
+	var profiles []config.MappingProfile
+	if err := config.Datadog.UnmarshalKey("dogstatsd_mapper_profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("could not parse dogstatsd_mapper_profiles: %s", err)
+	}
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+	return mapper.NewMetricMapper(profiles, config.Datadog.GetInt("dogstatsd_mapper_cache_size"))
+}
+
+// mapMetric applies the mapping profiles to the metric name as sent by the
+// client, the statsd_metric_namespace being prepended to the mapped name.
+func (s *Server) mapMetric(sample *metrics.MetricSample) {
+	result := s.mapper.Map(strings.TrimPrefix(sample.Name, s.metricPrefix))
+	if result == nil {
+		return
+	}
+	sample.Name = s.metricPrefix + result.Name
+	sample.Tags = append(sample.Tags, result.Tags...)
+}
+
+// StartCapture records the received packets to a new file of the
+// dogstatsd_capture_path directory for the duration, and returns its path.
+// The file can be fed back to dogstatsd with the dogstatsd-replay command.
+func (s *Server) StartCapture(duration time.Duration) (string, error) {
+	return s.capture.Start(config.Datadog.GetString("dogstatsd_capture_path"), duration)
+}
+
 // Stop stops a running Dogstatsd server
 func (s *Server) Stop() {
 	close(s.stopChan)
@@ -272,6 +413,7 @@ func (s *Server) Stop() {
 	if s.Statistics != nil {
 		s.Statistics.Stop()
 	}
+	s.capture.Stop()
 	s.health.Deregister()
 	s.Started = false
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -245,6 +246,67 @@ func TestUDPForward(t *testing.T) {
 	assert.Equal(t, message, buffer)
 }
 
+func TestUDPForwardAddresses(t *testing.T) {
+	var forwardAddresses []string
+	var forwardConns []net.PacketConn
+	for i := 0; i < 2; i++ {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer pc.Close()
+		forwardAddresses = append(forwardAddresses, pc.LocalAddr().String())
+		forwardConns = append(forwardConns, pc)
+	}
+	config.Datadog.SetDefault("statsd_forward_addresses", forwardAddresses)
+	defer config.Datadog.SetDefault("statsd_forward_addresses", []string{})
+
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+
+	metricOut := make(chan *metrics.MetricSample, 10)
+	eventOut := make(chan metrics.Event)
+	serviceOut := make(chan metrics.ServiceCheck)
+	s, err := NewServer(metricOut, eventOut, serviceOut)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	message := []byte("daemon:666|g|#sometag1:somevalue1")
+	conn.Write(message)
+
+	// every address gets the packet, and it is processed locally
+	for _, pc := range forwardConns {
+		pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buffer := make([]byte, len(message))
+		_, _, err = pc.ReadFrom(buffer)
+		require.NoError(t, err)
+		assert.Equal(t, message, buffer)
+	}
+	select {
+	case sample := <-metricOut:
+		assert.Equal(t, "daemon", sample.Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
+
+func TestGetForwardAddresses(t *testing.T) {
+	config.Datadog.SetDefault("statsd_forward_host", "statsd.local")
+	config.Datadog.SetDefault("statsd_forward_port", 8125)
+	config.Datadog.SetDefault("statsd_forward_addresses", []string{"other:8126", " "})
+	defer func() {
+		config.Datadog.SetDefault("statsd_forward_host", "")
+		config.Datadog.SetDefault("statsd_forward_port", 0)
+		config.Datadog.SetDefault("statsd_forward_addresses", []string{})
+	}()
+
+	assert.Equal(t, []string{"statsd.local:8125", "other:8126"}, getForwardAddresses())
+}
+
 func TestHistToDist(t *testing.T) {
 	port, err := getAvailableUDPPort()
 	require.NoError(t, err)
@@ -290,3 +352,33 @@ func TestHistToDist(t *testing.T) {
 		assert.FailNow(t, "Timeout on receive channel")
 	}
 }
+
+func TestMapMetric(t *testing.T) {
+	m, err := mapper.NewMetricMapper([]config.MappingProfile{
+		{
+			Name:   "airflow",
+			Prefix: "airflow.",
+			Mappings: []config.MetricMapping{
+				{
+					Match: "airflow.job.duration.*",
+					Name:  "airflow.job.duration",
+					Tags:  map[string]string{"job_name": "$1"},
+				},
+			},
+		},
+	}, 10)
+	require.NoError(t, err)
+	s := &Server{metricPrefix: "namespace.", mapper: m}
+
+	sample, err := parseMetricMessage([]byte("airflow.job.duration.my_job:1|g|#env:prod"), s.metricPrefix, "default-hostname", nil)
+	require.NoError(t, err)
+	s.mapMetric(sample)
+	assert.Equal(t, "namespace.airflow.job.duration", sample.Name)
+	assert.Equal(t, []string{"env:prod", "job_name:my_job"}, sample.Tags)
+
+	sample, err = parseMetricMessage([]byte("airflow.scheduler:1|g"), s.metricPrefix, "default-hostname", nil)
+	require.NoError(t, err)
+	s.mapMetric(sample)
+	assert.Equal(t, "namespace.airflow.scheduler", sample.Name)
+	assert.Empty(t, sample.Tags)
+}
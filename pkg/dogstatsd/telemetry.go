@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"expvar"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// telemetryInterval is the interval of the telemetry metrics, the aggregator bucket size
+const telemetryInterval = 10 * time.Second
+
+// telemetryMaps are the expvar maps sent as telemetry metrics, by metric prefix
+var telemetryMaps = map[string]string{
+	"datadog.dogstatsd.":     "dogstatsd",
+	"datadog.dogstatsd.udp.": "dogstatsd-udp",
+	"datadog.dogstatsd.uds.": "dogstatsd-uds",
+}
+
+// telemetry sends the increase of the dogstatsd expvar counters as counts,
+// and the usage of the packet queue as a gauge, so that the capacity issues
+// show up in the agent metrics.
+func (s *Server) telemetry(metricOut chan<- *metrics.MetricSample) {
+	ticker := time.NewTicker(telemetryInterval)
+	defer ticker.Stop()
+	previous := make(map[string]int64)
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			for prefix, name := range telemetryMaps {
+				m, ok := expvar.Get(name).(*expvar.Map)
+				if !ok {
+					continue
+				}
+				for _, sample := range telemetrySamples(prefix, m, s.defaultHostname, previous) {
+					metricOut <- sample
+				}
+			}
+			metricOut <- &metrics.MetricSample{
+				Name:       "datadog.dogstatsd.packet_queue.usage",
+				Value:      float64(len(s.packetIn)) / float64(cap(s.packetIn)),
+				Mtype:      metrics.GaugeType,
+				Host:       s.defaultHostname,
+				SampleRate: 1,
+			}
+		}
+	}
+}
+
+// telemetrySamples returns a count sample per expvar.Int of the map, its
+// increase since the previous values, which are updated. Monotonic counts
+// can't be used, the time sampler not keeping them from a bucket to the next.
+func telemetrySamples(prefix string, m *expvar.Map, host string, previous map[string]int64) []*metrics.MetricSample {
+	var samples []*metrics.MetricSample
+	m.Do(func(kv expvar.KeyValue) {
+		counter, ok := kv.Value.(*expvar.Int)
+		if !ok {
+			return
+		}
+		name := prefix + toSnakeCase(kv.Key)
+		value := counter.Value()
+		samples = append(samples, &metrics.MetricSample{
+			Name:       name,
+			Value:      float64(value - previous[name]),
+			Mtype:      metrics.CountType,
+			Host:       host,
+			SampleRate: 1,
+		})
+		previous[name] = value
+	})
+	return samples
+}
+
+// toSnakeCase converts the expvar names, e.g. MetricParseErrors to metric_parse_errors
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
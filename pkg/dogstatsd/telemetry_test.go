@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestToSnakeCase(t *testing.T) {
+	assert.Equal(t, "metric_parse_errors", toSnakeCase("MetricParseErrors"))
+	assert.Equal(t, "packets", toSnakeCase("Packets"))
+	assert.Equal(t, "bytes", toSnakeCase("bytes"))
+}
+
+func TestTelemetrySamples(t *testing.T) {
+	m := new(expvar.Map).Init()
+	packets := expvar.Int{}
+	packets.Add(42)
+	m.Set("Packets", &packets)
+	m.Set("PacketQueueLength", expvar.Func(func() interface{} { return 1 }))
+
+	previous := make(map[string]int64)
+	samples := telemetrySamples("datadog.dogstatsd.udp.", m, "my-hostname", previous)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "datadog.dogstatsd.udp.packets", samples[0].Name)
+	assert.Equal(t, 42.0, samples[0].Value)
+	assert.Equal(t, metrics.CountType, samples[0].Mtype)
+	assert.Equal(t, "my-hostname", samples[0].Host)
+
+	// the increase since the previous samples
+	packets.Add(8)
+	samples = telemetrySamples("datadog.dogstatsd.udp.", m, "my-hostname", previous)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 8.0, samples[0].Value)
+}
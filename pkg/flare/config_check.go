@@ -9,10 +9,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/fatih/color"
 )
@@ -56,6 +59,16 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		}
 	}
 
+	// the templates, by digest, and the ones some configs were resolved from
+	templates := make(map[string]integration.Config, len(cr.Unresolved))
+	resolved := make(map[string]bool)
+	for _, tpl := range cr.Unresolved {
+		templates[tpl.Digest()] = tpl
+	}
+	for _, trace := range cr.ResolutionTraces {
+		resolved[trace.TemplateDigest] = true
+	}
+
 	for _, c := range cr.Configs {
 		fmt.Fprintln(w, fmt.Sprintf("\n=== %s check ===", color.GreenString(c.Name)))
 		if len(c.Provider) > 0 {
@@ -86,6 +99,9 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 				fmt.Fprintln(w, fmt.Sprintf("* %s", color.CyanString(id)))
 			}
 		}
+		if trace, found := cr.ResolutionTraces[c.Digest()]; found && withDebug {
+			printResolutionTrace(w, trace, templates)
+		}
 		fmt.Fprintln(w, "===")
 	}
 
@@ -102,12 +118,45 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		if len(cr.Unresolved) > 0 {
 			fmt.Fprintln(w, fmt.Sprintf("\n=== %s Configs ===", color.YellowString("Unresolved")))
 			for ids, config := range cr.Unresolved {
+				if resolved[config.Digest()] {
+					continue
+				}
 				fmt.Fprintln(w, fmt.Sprintf("\n%s: %s", color.BlueString("Auto-discovery IDs"), color.YellowString(ids)))
 				fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Template")))
 				fmt.Fprintln(w, config.String())
+				if warnings := cr.ResolveWarnings[config.Name]; len(warnings) > 0 {
+					fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Resolution failures")))
+					for _, warning := range warnings {
+						fmt.Fprintln(w, fmt.Sprintf("* %s", color.YellowString(warning)))
+					}
+				}
 			}
 		}
 	}
 
 	return nil
 }
+
+// printResolutionTrace prints the template, the service and the template
+// variables a config was resolved from
+func printResolutionTrace(w io.Writer, trace integration.ResolutionTrace, templates map[string]integration.Config) {
+	fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Resolved from")))
+	if tpl, found := templates[trace.TemplateDigest]; found {
+		fmt.Fprintln(w, fmt.Sprintf("* %s: %s (%s)", color.BlueString("Template"), color.CyanString(tpl.Name), strings.Join(tpl.ADIdentifiers, ",")))
+	} else {
+		fmt.Fprintln(w, fmt.Sprintf("* %s: %s", color.BlueString("Template"), color.CyanString(trace.TemplateDigest)))
+	}
+	fmt.Fprintln(w, fmt.Sprintf("* %s: %s", color.BlueString("Service"), color.CyanString(trace.ServiceID)))
+	if len(trace.Variables) == 0 {
+		return
+	}
+	vars := make([]string, 0, len(trace.Variables))
+	for v := range trace.Variables {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	fmt.Fprintln(w, fmt.Sprintf("* %s:", color.BlueString("Template variables")))
+	for _, v := range vars {
+		fmt.Fprintln(w, fmt.Sprintf("  %s -> %s", v, color.CyanString(trace.Variables[v])))
+	}
+}
@@ -8,11 +8,15 @@ package forwarder
 import (
 	"expvar"
 	"fmt"
+	"net/http"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -23,12 +27,28 @@ var (
 	transactionsRetried  = expvar.Int{}
 	transactionsDropped  = expvar.Int{}
 	transactionsRequeued = expvar.Int{}
+	transactionsShed     = expvar.Int{}
+	// retryQueueSizePerDomain is the size of the retry queue of each domain,
+	// RetryQueueSize being their sum
+	retryQueueSizePerDomain = expvar.Map{}
+
+	// shedEndpoints are the endpoints of the payloads dropped for a congested
+	// domain, with the shed level from which they are: the sketches first,
+	// then the events and the other v1 intake payloads
+	shedEndpoints = map[string]int{
+		sketchSeriesEndpoint: 1,
+		eventsEndpoint:       2,
+		v1IntakeEndpoint:     2,
+	}
 )
 
 func initDomainForwarderExpvars() {
+	retryQueueSizePerDomain.Init()
 	transactionsExpvars.Set("Retried", &transactionsRetried)
 	transactionsExpvars.Set("Dropped", &transactionsDropped)
 	transactionsExpvars.Set("Requeued", &transactionsRequeued)
+	transactionsExpvars.Set("Shed", &transactionsShed)
+	forwarderExpvars.Set("RetryQueueSizePerDomain", &retryQueueSizePerDomain)
 }
 
 // domainForwarder is in charge of sending Transactions to Datadog backend over
@@ -48,6 +68,13 @@ type domainForwarder struct {
 	m                   sync.Mutex // To control Start/Stop races
 	isRetrying          int32
 	blockedList         *blockedEndpoints
+	storage             *transactionStorage     // nil if the transactions are not stored on disk
+	proxies             *config.Proxy           // overrides the global proxies if not nil
+	compressor          *compression.Compressor // overrides the compressor of the serializer if not nil
+	retryQueueSize      int64                   // last size reported to the expvars
+	retryQueueFull      int32                   // 1 if the retry queue is at its limit, atomic
+	congestedFlushes    int                     // the consecutive flushes with the domain congested, protected by m
+	maxDelayedFlushes   int                     // the low priority payloads are shed past this number of congested flushes
 }
 
 func newDomainForwarder(domain string, numberOfWorkers int, retryQueueLimit int) *domainForwarder {
@@ -60,6 +87,34 @@ func newDomainForwarder(domain string, numberOfWorkers int, retryQueueLimit int)
 	}
 }
 
+// recompress returns the payload compressed with the compressor of the domain,
+// and its headers, when the serializer compressed it with another one. The
+// payload is returned as-is when it could not be recompressed.
+func (f *domainForwarder) recompress(payload *[]byte, extra http.Header) (*[]byte, http.Header) {
+	contentEncoding := extra.Get("Content-Encoding")
+	if f.compressor == nil || contentEncoding == "" || contentEncoding != compression.ContentEncoding || contentEncoding == f.compressor.ContentEncoding() {
+		return payload, extra
+	}
+
+	decompressed, err := compression.Decompress(nil, *payload)
+	if err != nil {
+		log.Errorf("Could not decompress the payload to recompress it for %s: %s", f.domain, err)
+		return payload, extra
+	}
+	compressed, err := f.compressor.Compress(nil, decompressed)
+	if err != nil {
+		log.Errorf("Could not recompress the payload for %s: %s", f.domain, err)
+		return payload, extra
+	}
+
+	headers := make(http.Header, len(extra))
+	for key := range extra {
+		headers.Set(key, extra.Get(key))
+	}
+	headers.Set("Content-Encoding", f.compressor.ContentEncoding())
+	return &compressed, headers
+}
+
 type byCreatedTime []Transaction
 
 func (v byCreatedTime) Len() int           { return len(v) }
@@ -76,8 +131,10 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	defer atomic.StoreInt32(&f.isRetrying, 0)
 
 	newQueue := []Transaction{}
+	var toStore []*HTTPTransaction
 	droppedRetryQueueFull := 0
 	droppedWorkerBusy := 0
+	stats := domainStats(f.domain)
 
 	sort.Sort(byCreatedTime(f.retryQueue))
 
@@ -86,32 +143,143 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 			select {
 			case f.lowPrio <- t:
 				transactionsRetried.Add(1)
+				stats.Add("Retried", 1)
 			default:
 				droppedWorkerBusy++
 				transactionsDropped.Add(1)
+				stats.Add("Dropped", 1)
 			}
 		} else if len(newQueue) < f.retryQueueLimit {
 			newQueue = append(newQueue, t)
 			transactionsRequeued.Add(1)
+			stats.Add("Requeued", 1)
+		} else if httpTransaction, ok := t.(*HTTPTransaction); ok && f.storage != nil {
+			toStore = append(toStore, httpTransaction)
 		} else {
 			droppedRetryQueueFull++
 			transactionsDropped.Add(1)
+			stats.Add("Dropped", 1)
+		}
+	}
+
+	if len(toStore) > 0 {
+		if err := f.storage.store(toStore); err != nil {
+			log.Errorf("Could not store the transactions of the full retry queue on disk: %s", err)
 		}
+	} else if len(newQueue) == 0 && f.storage != nil {
+		// the domain is reachable again, the stored transactions are retried on the next tick
+		newQueue = f.loadStoredTransactions()
 	}
 
 	f.retryQueue = newQueue
-	transactionsRetryQueueSize.Set(int64(len(f.retryQueue)))
+	f.updateRetryQueueSize()
 
 	if droppedRetryQueueFull+droppedWorkerBusy > 0 {
-		log.Errorf("Dropped %d transactions in this retry attempt: %d for exceeding the retry queue size limit of %d, %d because the workers are too busy",
-			droppedRetryQueueFull+droppedWorkerBusy, droppedRetryQueueFull, f.retryQueueLimit, droppedWorkerBusy)
+		log.Errorf("Dropped %d transactions to %s in this retry attempt: %d for exceeding the retry queue size limit of %d, %d because the workers are too busy",
+			droppedRetryQueueFull+droppedWorkerBusy, f.domain, droppedRetryQueueFull, f.retryQueueLimit, droppedWorkerBusy)
+	}
+}
+
+// loadStoredTransactions returns the newest transactions stored on disk, up to
+// the retry queue limit
+func (f *domainForwarder) loadStoredTransactions() []Transaction {
+	var transactions []Transaction
+	for !f.storage.isEmpty() && len(transactions) < f.retryQueueLimit {
+		stored, err := f.storage.loadNewest()
+		if err != nil {
+			log.Errorf("Could not load the transactions stored on disk: %s", err)
+			continue
+		}
+		for _, t := range stored {
+			transactions = append(transactions, t)
+		}
+	}
+	return transactions
+}
+
+// storeRetryQueue writes the retry queue on disk so that it is retried after a restart
+func (f *domainForwarder) storeRetryQueue() {
+	var toStore []*HTTPTransaction
+	for _, t := range f.retryQueue {
+		if httpTransaction, ok := t.(*HTTPTransaction); ok {
+			toStore = append(toStore, httpTransaction)
+		}
+	}
+	if err := f.storage.store(toStore); err != nil {
+		log.Errorf("Could not store the retry queue on disk: %s", err)
 	}
 }
 
 func (f *domainForwarder) requeueTransaction(t Transaction) {
 	f.retryQueue = append(f.retryQueue, t)
 	transactionsRequeued.Add(1)
-	transactionsRetryQueueSize.Set(int64(len(f.retryQueue)))
+	domainStats(f.domain).Add("Requeued", 1)
+	f.updateRetryQueueSize()
+}
+
+// updateRetryQueueSize reports the size of the retry queue, the other domains
+// having their own
+func (f *domainForwarder) updateRetryQueueSize() {
+	size := int64(len(f.retryQueue))
+	transactionsRetryQueueSize.Add(size - f.retryQueueSize)
+	retryQueueSizePerDomain.Add(f.domain, size-f.retryQueueSize)
+	domainStats(f.domain).Add("RetryQueueSize", size-f.retryQueueSize)
+	f.retryQueueSize = size
+
+	var full int32
+	if len(f.retryQueue) >= f.retryQueueLimit {
+		full = 1
+	}
+	atomic.StoreInt32(&f.retryQueueFull, full)
+}
+
+// isCongested returns whether the transactions to the domain pile up: the
+// input queue or the retry queue is full
+func (f *domainForwarder) isCongested() bool {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.internalState == Stopped {
+		return false
+	}
+	return len(f.highPrio) == cap(f.highPrio) || atomic.LoadInt32(&f.retryQueueFull) == 1
+}
+
+// updateCongestion counts the consecutive flushes with the domain congested
+// and returns whether it is
+func (f *domainForwarder) updateCongestion() bool {
+	congested := f.isCongested()
+
+	f.m.Lock()
+	defer f.m.Unlock()
+	if congested {
+		f.congestedFlushes++
+	} else {
+		f.congestedFlushes = 0
+	}
+	return congested
+}
+
+// shed returns whether the payloads to endpoint are dropped instead of being
+// sent to the domain: past maxDelayedFlushes congested flushes, the sketches
+// then the events are, the other domains still receiving them
+func (f *domainForwarder) shed(endpoint string) bool {
+	level, ok := shedEndpoints[endpoint]
+	if !ok {
+		return false
+	}
+
+	f.m.Lock()
+	shedLevel := f.congestedFlushes - f.maxDelayedFlushes
+	f.m.Unlock()
+	if shedLevel < level {
+		return false
+	}
+
+	log.Warnf("The transactions to %s pile up, dropping the payload to %s", f.domain, endpoint)
+	transactionsShed.Add(1)
+	domainStats(f.domain).Add("Shed", 1)
+	return true
 }
 
 func (f *domainForwarder) handleFailedTransactions() {
@@ -150,9 +318,16 @@ func (f *domainForwarder) Start() error {
 
 	// reset internal state to purge transactions from past starts
 	f.init()
+	if f.storage != nil {
+		f.retryQueue = f.loadStoredTransactions()
+	}
+	f.updateRetryQueueSize()
 
 	for i := 0; i < f.numberOfWorkers; i++ {
 		w := NewWorker(f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList)
+		if f.proxies != nil {
+			w.Client.Transport = util.CreateHTTPTransportWithProxies(f.proxies)
+		}
 		w.Start()
 		f.workers = append(f.workers, w)
 	}
@@ -162,7 +337,8 @@ func (f *domainForwarder) Start() error {
 	return nil
 }
 
-// Stop stops a domainForwarder, all transactions not yet flushed will be lost.
+// Stop stops a domainForwarder, all transactions not yet flushed will be lost,
+// except the retry queue if the transactions are stored on disk.
 func (f *domainForwarder) Stop() {
 	// Lock so we can't start a Forwarder while is stopping
 	f.m.Lock()
@@ -178,7 +354,11 @@ func (f *domainForwarder) Stop() {
 		w.Stop()
 	}
 	f.workers = []*Worker{}
+	if f.storage != nil {
+		f.storeRetryQueue()
+	}
 	f.retryQueue = []Transaction{}
+	f.updateRetryQueueSize()
 	close(f.highPrio)
 	close(f.lowPrio)
 	close(f.requeuedTransaction)
@@ -200,6 +380,7 @@ func (f *domainForwarder) sendHTTPTransactions(transaction Transaction) error {
 	case f.highPrio <- transaction:
 	default:
 		transactionsDroppedOnInput.Add(1)
+		domainStats(f.domain).Add("DroppedOnInput", 1)
 		return fmt.Errorf("the forwarder input queue for %s is full: dropping transaction", f.domain)
 	}
 	return nil
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build zlib,zstd
+
+package forwarder
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+func TestDomainForwarderRecompress(t *testing.T) {
+	require.Nil(t, compression.Configure(compression.ZlibKind))
+
+	raw := []byte(`{"series":[]}`)
+	compressed, err := compression.Compress(nil, raw)
+	require.Nil(t, err)
+	extra := make(http.Header)
+	extra.Set("Content-Type", "application/json")
+	extra.Set("Content-Encoding", compression.ContentEncoding)
+
+	forwarder := newDomainForwarder("test", 1, 10)
+
+	// Sent as compressed by the serializer by default
+	payload, headers := forwarder.recompress(&compressed, extra)
+	assert.Equal(t, &compressed, payload)
+	assert.Equal(t, extra, headers)
+
+	forwarder.compressor, err = compression.Get(compression.ZstdKind)
+	require.Nil(t, err)
+	payload, headers = forwarder.recompress(&compressed, extra)
+	assert.Equal(t, "zstd", headers.Get("Content-Encoding"))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	decompressed, err := forwarder.compressor.Decompress(nil, *payload)
+	require.Nil(t, err)
+	assert.Equal(t, raw, decompressed)
+	// The headers shared by the other domains are not modified
+	assert.Equal(t, compression.ContentEncoding, extra.Get("Content-Encoding"))
+
+	// Uncompressed payloads are sent as-is
+	extra.Del("Content-Encoding")
+	payload, headers = forwarder.recompress(&raw, extra)
+	assert.Equal(t, &raw, payload)
+	assert.Equal(t, extra, headers)
+}
@@ -6,11 +6,14 @@
 package forwarder
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestNewDomainForwarder(t *testing.T) {
@@ -47,6 +50,21 @@ func TestDomainForwarderStart(t *testing.T) {
 	forwarder.Stop()
 }
 
+func TestDomainForwarderStartWithProxies(t *testing.T) {
+	forwarder := newDomainForwarder("test", 1, 10)
+	// no proxy for this domain whatever the global settings
+	forwarder.proxies = &config.Proxy{}
+	require.Nil(t, forwarder.Start())
+	defer forwarder.Stop()
+
+	require.Len(t, forwarder.workers, 1)
+	transport := forwarder.workers[0].Client.Transport.(*http.Transport)
+	r, _ := http.NewRequest("GET", "https://test.com", nil)
+	proxyURL, err := transport.Proxy(r)
+	assert.Nil(t, err)
+	assert.Nil(t, proxyURL)
+}
+
 func TestDomainForwarderInit(t *testing.T) {
 	forwarder := newDomainForwarder("test", 1, 10)
 	forwarder.init()
@@ -54,6 +72,56 @@ func TestDomainForwarderInit(t *testing.T) {
 	assert.Len(t, forwarder.retryQueue, 0)
 }
 
+func TestDomainForwarderIsCongested(t *testing.T) {
+	forwarder := newDomainForwarder("test", 1, 2)
+	assert.False(t, forwarder.isCongested())
+
+	// started without workers reading the input queue
+	forwarder.init()
+	forwarder.internalState = Started
+	assert.False(t, forwarder.isCongested())
+	for i := 0; i < chanBufferSize; i++ {
+		require.Nil(t, forwarder.sendHTTPTransactions(NewHTTPTransaction()))
+	}
+	assert.True(t, forwarder.isCongested())
+	<-forwarder.highPrio
+	assert.False(t, forwarder.isCongested())
+
+	forwarder.requeueTransaction(NewHTTPTransaction())
+	assert.False(t, forwarder.isCongested())
+	forwarder.requeueTransaction(NewHTTPTransaction())
+	assert.True(t, forwarder.isCongested())
+}
+
+func TestDomainForwarderShed(t *testing.T) {
+	forwarder := newDomainForwarder("test", 1, 1)
+	forwarder.maxDelayedFlushes = 1
+	forwarder.init()
+	forwarder.internalState = Started
+
+	assert.False(t, forwarder.updateCongestion())
+	assert.False(t, forwarder.shed(sketchSeriesEndpoint))
+
+	// the retry queue is full: the sketches then the events are shed, past
+	// one congested flush
+	forwarder.requeueTransaction(NewHTTPTransaction())
+	assert.True(t, forwarder.updateCongestion())
+	assert.False(t, forwarder.shed(sketchSeriesEndpoint))
+	assert.True(t, forwarder.updateCongestion())
+	assert.True(t, forwarder.shed(sketchSeriesEndpoint))
+	assert.False(t, forwarder.shed(eventsEndpoint))
+	assert.True(t, forwarder.updateCongestion())
+	assert.True(t, forwarder.shed(eventsEndpoint))
+	assert.True(t, forwarder.shed(v1IntakeEndpoint))
+	assert.False(t, forwarder.shed(seriesEndpoint))
+	assert.False(t, forwarder.shed(serviceChecksEndpoint))
+
+	forwarder.retryQueue = forwarder.retryQueue[:0]
+	forwarder.updateRetryQueueSize()
+	assert.False(t, forwarder.updateCongestion())
+	assert.False(t, forwarder.shed(sketchSeriesEndpoint))
+}
+
 func TestDomainForwarderStop(t *testing.T) {
 	forwarder := newDomainForwarder("test", 1, 10)
 	forwarder.Stop() // this should be a noop
@@ -98,6 +166,26 @@ func TestRequeueTransaction(t *testing.T) {
 	assert.Len(t, forwarder.retryQueue, 1)
 }
 
+func TestRetryQueueSizePerDomain(t *testing.T) {
+	retryQueueSizePerDomain.Init()
+	before := transactionsRetryQueueSize.Value()
+
+	forwarder1 := newDomainForwarder("domain1", 1, 10)
+	forwarder2 := newDomainForwarder("domain2", 1, 10)
+	forwarder1.requeueTransaction(NewHTTPTransaction())
+	forwarder1.requeueTransaction(NewHTTPTransaction())
+	forwarder2.requeueTransaction(NewHTTPTransaction())
+
+	assert.Equal(t, "2", retryQueueSizePerDomain.Get("domain1").String())
+	assert.Equal(t, "1", retryQueueSizePerDomain.Get("domain2").String())
+	assert.Equal(t, before+3, transactionsRetryQueueSize.Value())
+
+	forwarder1.retryQueue = forwarder1.retryQueue[:0]
+	forwarder1.updateRetryQueueSize()
+	assert.Equal(t, "0", retryQueueSizePerDomain.Get("domain1").String())
+	assert.Equal(t, before+1, transactionsRetryQueueSize.Value())
+}
+
 func TestRetryTransactions(t *testing.T) {
 	forwarder := newDomainForwarder("test", 1, 10)
 	forwarder.init()
@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
@@ -31,6 +32,7 @@ var (
 	transactionsTimeseriesV1  = expvar.Int{}
 	transactionsCheckRunsV1   = expvar.Int{}
 	transactionsIntakeV1      = expvar.Int{}
+	transactionsOrchestrator  = expvar.Int{}
 )
 
 func init() {
@@ -45,9 +47,11 @@ func init() {
 	transactionsExpvars.Set("TimeseriesV1", &transactionsTimeseriesV1)
 	transactionsExpvars.Set("CheckRunsV1", &transactionsCheckRunsV1)
 	transactionsExpvars.Set("IntakeV1", &transactionsIntakeV1)
+	transactionsExpvars.Set("Orchestrator", &transactionsOrchestrator)
 	initDomainForwarderExpvars()
 	initTransactionExpvars()
 	initForwarderHealthExpvars()
+	initTransactionStorageExpvars()
 }
 
 const (
@@ -70,6 +74,7 @@ const (
 	sketchSeriesEndpoint  = "/api/beta/sketches"
 	hostMetadataEndpoint  = "/api/v2/host_metadata"
 	metadataEndpoint      = "/api/v2/metadata"
+	orchestratorEndpoint  = "/api/v1/orchestrator"
 
 	apiHTTPHeaderKey     = "DD-Api-Key"
 	versionHTTPHeaderKey = "DD-Agent-Version"
@@ -92,6 +97,8 @@ type Forwarder interface {
 	SubmitSketchSeries(payload Payloads, extra http.Header) error
 	SubmitHostMetadata(payload Payloads, extra http.Header) error
 	SubmitMetadata(payload Payloads, extra http.Header) error
+	SubmitOrchestratorManifests(payload Payloads, extra http.Header) error
+	UpdateCongestion() bool
 }
 
 // DefaultForwarder is the default implementation of the Forwarder.
@@ -101,6 +108,8 @@ type DefaultForwarder struct {
 
 	domainForwarders map[string]*domainForwarder
 	keysPerDomains   map[string][]string
+	mainAPIKey       string       // the api_key, replaced by default by UpdateAPIKey
+	keysLock         sync.RWMutex // to protect the keysPerDomains and mainAPIKey fields, the keys can be rotated
 	healthChecker    *forwarderHealth
 	internalState    uint32
 	m                sync.Mutex // To control Start/Stop races
@@ -112,11 +121,23 @@ func NewDefaultForwarder(keysPerDomains map[string][]string) *DefaultForwarder {
 		NumberOfWorkers:  config.Datadog.GetInt("forwarder_num_workers"),
 		domainForwarders: map[string]*domainForwarder{},
 		keysPerDomains:   map[string][]string{},
+		mainAPIKey:       config.Datadog.GetString("api_key"),
 		internalState:    Stopped,
 		healthChecker:    &forwarderHealth{},
 	}
 	numWorkers := config.Datadog.GetInt("forwarder_num_workers")
 	retryQueueMaxSize := config.Datadog.GetInt("forwarder_retry_queue_max_size")
+	storagePath := config.Datadog.GetString("forwarder_storage_path")
+	storageMaxSize := config.Datadog.GetInt64("forwarder_storage_max_size_in_bytes")
+	maxDelayedFlushes := config.Datadog.GetInt("aggregator_backpressure_max_delayed_flushes")
+	endpointProxies, err := config.GetEndpointProxies()
+	if err != nil {
+		log.Errorf("Misconfiguration of the proxies per endpoint, using the global ones: %s", err)
+	}
+	endpointCompressorKinds, err := config.GetEndpointCompressorKinds()
+	if err != nil {
+		log.Errorf("Misconfiguration of the compressors per endpoint, using the default one: %s", err)
+	}
 
 	for domain, keys := range keysPerDomains {
 		if keys == nil || len(keys) == 0 {
@@ -124,6 +145,26 @@ func NewDefaultForwarder(keysPerDomains map[string][]string) *DefaultForwarder {
 		} else {
 			f.keysPerDomains[domain] = keys
 			f.domainForwarders[domain] = newDomainForwarder(domain, numWorkers, retryQueueMaxSize)
+			f.domainForwarders[domain].maxDelayedFlushes = maxDelayedFlushes
+			if proxies, ok := endpointProxies[domain]; ok {
+				f.domainForwarders[domain].proxies = proxies
+			}
+			if kind, ok := endpointCompressorKinds[domain]; ok {
+				compressor, err := compression.Get(kind)
+				if err != nil {
+					log.Errorf("The payloads to '%s' will be compressed with the default compressor: %s", domain, err)
+				} else {
+					f.domainForwarders[domain].compressor = compressor
+				}
+			}
+			if storageMaxSize > 0 {
+				storage, err := newTransactionStorage(storagePath, domain, keys, storageMaxSize)
+				if err != nil {
+					log.Errorf("The transactions of '%s' will not be stored on disk: %s", domain, err)
+				} else {
+					f.domainForwarders[domain].storage = storage
+				}
+			}
 		}
 	}
 
@@ -145,19 +186,34 @@ func (f *DefaultForwarder) Start() error {
 	}
 
 	// log endpoints configuration
-	endpointLogs := make([]string, 0, len(f.keysPerDomains))
-	for domain, apiKeys := range f.keysPerDomains {
+	keysPerDomains := f.getKeysPerDomains()
+	endpointLogs := make([]string, 0, len(keysPerDomains))
+	for domain, apiKeys := range keysPerDomains {
 		endpointLogs = append(endpointLogs, fmt.Sprintf("\"%s\" (%v api key(s))",
 			domain, len(apiKeys)))
 	}
 	log.Infof("Forwarder started, sending to %v endpoint(s) with %v worker(s) each: %s",
 		len(endpointLogs), f.NumberOfWorkers, strings.Join(endpointLogs, " ; "))
 
-	f.healthChecker.Start(f.keysPerDomains)
+	f.healthChecker.Start(f.getKeysPerDomains)
 	f.internalState = Started
 	return nil
 }
 
+// UpdateCongestion counts, for each domain, the consecutive flushes with its
+// transactions piling up, the low priority payloads to a domain being shed
+// once it is congested for too long. It returns whether every domain is
+// congested: delaying the flush only helps when no payload can be sent.
+func (f *DefaultForwarder) UpdateCongestion() bool {
+	congested := len(f.domainForwarders) > 0
+	for _, df := range f.domainForwarders {
+		if !df.updateCongestion() {
+			congested = false
+		}
+	}
+	return congested
+}
+
 // Stop all the component of a forwarder and free resources
 func (f *DefaultForwarder) Stop() {
 	// Lock so we can't start a Forwarder while is stopping
@@ -189,10 +245,108 @@ func (f *DefaultForwarder) State() uint32 {
 	return f.internalState
 }
 
+// getKeysPerDomains returns a copy of the API keys of each domain
+func (f *DefaultForwarder) getKeysPerDomains() map[string][]string {
+	f.keysLock.RLock()
+	defer f.keysLock.RUnlock()
+
+	keysPerDomains := make(map[string][]string, len(f.keysPerDomains))
+	for domain, apiKeys := range f.keysPerDomains {
+		keysPerDomains[domain] = append([]string(nil), apiKeys...)
+	}
+	return keysPerDomains
+}
+
+// UpdateAPIKey replaces the API key oldKey, the api_key if empty, by newKey
+// in every domain, e.g. to rotate it without restarting the agent. The
+// transactions already queued keep the previous key. newKey is validated
+// first against each domain using oldKey, on its unversioned URL, it's
+// rejected if invalid or if it could not be validated.
+func (f *DefaultForwarder) UpdateAPIKey(oldKey, newKey string) error {
+	if newKey == "" {
+		return fmt.Errorf("the new API key is empty")
+	}
+
+	f.keysLock.RLock()
+	if oldKey == "" {
+		oldKey = f.mainAPIKey
+	}
+	f.keysLock.RUnlock()
+
+	domains := []string{}
+	for domain, apiKeys := range f.getKeysPerDomains() {
+		for _, apiKey := range apiKeys {
+			if apiKey == oldKey {
+				domains = append(domains, domain)
+				break
+			}
+		}
+	}
+	if len(domains) == 0 {
+		return fmt.Errorf("the API key to replace is not configured")
+	}
+
+	for _, domain := range domains {
+		validationURL, err := config.RemoveAgentVersionFromDomain(domain, "app")
+		if err != nil {
+			return fmt.Errorf("could not parse the domain %s: %s", domain, err)
+		}
+		if valid, err := checkAPIKey(validationURL, newKey, validateAPIKeyTimeout); err != nil {
+			return fmt.Errorf("could not validate the new API key for %s: %s", domain, err)
+		} else if !valid {
+			return fmt.Errorf("the new API key is invalid for %s", domain)
+		}
+	}
+
+	f.keysLock.Lock()
+	for domain, apiKeys := range f.keysPerDomains {
+		updated := make([]string, len(apiKeys))
+		for i, apiKey := range apiKeys {
+			updated[i] = apiKey
+			if apiKey == oldKey {
+				updated[i] = newKey
+				if df, ok := f.domainForwarders[domain]; ok && df.storage != nil {
+					df.storage.setAPIKey(i, newKey)
+				}
+			}
+		}
+		f.keysPerDomains[domain] = updated
+	}
+	if f.mainAPIKey == oldKey {
+		f.mainAPIKey = newKey
+	}
+	f.keysLock.Unlock()
+
+	log.Infof("Rotated the API key ending with %s", lastChars(oldKey))
+
+	if f.healthChecker != nil {
+		f.healthChecker.Revalidate()
+	}
+	return nil
+}
+
+// lastChars returns the last 5 characters of an API key, to log it
+func lastChars(apiKey string) string {
+	if len(apiKey) > 5 {
+		return apiKey[len(apiKey)-5:]
+	}
+	return apiKey
+}
+
 func (f *DefaultForwarder) createHTTPTransactions(endpoint string, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
+	f.keysLock.RLock()
+	defer f.keysLock.RUnlock()
+
 	transactions := []*HTTPTransaction{}
 	for _, payload := range payloads {
 		for domain, apiKeys := range f.keysPerDomains {
+			domainPayload, domainExtra := payload, extra
+			if df, ok := f.domainForwarders[domain]; ok {
+				if df.shed(endpoint) {
+					continue
+				}
+				domainPayload, domainExtra = df.recompress(payload, extra)
+			}
 			for _, apiKey := range apiKeys {
 				transactionEndpoint := endpoint
 				if apiKeyInQueryString {
@@ -201,12 +355,12 @@ func (f *DefaultForwarder) createHTTPTransactions(endpoint string, payloads Payl
 				t := NewHTTPTransaction()
 				t.Domain = domain
 				t.Endpoint = transactionEndpoint
-				t.Payload = payload
+				t.Payload = domainPayload
 				t.Headers.Set(apiHTTPHeaderKey, apiKey)
 				t.Headers.Set(versionHTTPHeaderKey, version.AgentVersion)
 
-				for key := range extra {
-					t.Headers.Set(key, extra.Get(key))
+				for key := range domainExtra {
+					t.Headers.Set(key, domainExtra.Get(key))
 				}
 				transactions = append(transactions, t)
 			}
@@ -270,6 +424,13 @@ func (f *DefaultForwarder) SubmitMetadata(payload Payloads, extra http.Header) e
 	return f.sendHTTPTransactions(transactions)
 }
 
+// SubmitOrchestratorManifests will send a payload of Kubernetes resource manifests to Datadog backend.
+func (f *DefaultForwarder) SubmitOrchestratorManifests(payload Payloads, extra http.Header) error {
+	transactions := f.createHTTPTransactions(orchestratorEndpoint, payload, false, extra)
+	transactionsOrchestrator.Add(1)
+	return f.sendHTTPTransactions(transactions)
+}
+
 // SubmitV1Series will send timeserie to v1 endpoint (this will be remove once
 // the backend handles v2 endpoints).
 func (f *DefaultForwarder) SubmitV1Series(payload Payloads, extra http.Header) error {
@@ -42,17 +42,26 @@ func initForwarderHealthExpvars() {
 // unhealthy if the API keys are not longer valid or if to many transactions
 // were dropped
 type forwarderHealth struct {
-	health  *health.Handle
-	stop    chan bool
-	stopped chan struct{}
-	ddURL   string
-	timeout time.Duration
+	health     *health.Handle
+	stop       chan bool
+	stopped    chan struct{}
+	revalidate chan struct{}
+	ddURL      string
+	timeout    time.Duration
+	interval   time.Duration
+	// keysPerDomains returns the current API keys, they can be rotated
+	keysPerDomains func() map[string][]string
 }
 
 func (fh *forwarderHealth) init(keysPerDomains map[string][]string) {
 	fh.stop = make(chan bool, 1)
 	fh.stopped = make(chan struct{})
+	fh.revalidate = make(chan struct{}, 1)
 	fh.ddURL = config.Datadog.GetString("dd_url")
+	fh.interval = time.Duration(config.Datadog.GetInt("forwarder_apikey_validation_interval")) * time.Minute
+	if fh.interval <= 0 {
+		fh.interval = time.Hour
+	}
 
 	// Since timeout is the maximum duration we can wait, we need to divide it
 	// by the total number of api keys to obtain the max duration for each key
@@ -67,10 +76,11 @@ func (fh *forwarderHealth) init(keysPerDomains map[string][]string) {
 	}
 }
 
-func (fh *forwarderHealth) Start(keysPerDomains map[string][]string) {
+func (fh *forwarderHealth) Start(keysPerDomains func() map[string][]string) {
 	fh.health = health.Register("forwarder")
-	fh.init(keysPerDomains)
-	go fh.healthCheckLoop(keysPerDomains)
+	fh.keysPerDomains = keysPerDomains
+	fh.init(keysPerDomains())
+	go fh.healthCheckLoop()
 }
 
 func (fh *forwarderHealth) Stop() {
@@ -79,31 +89,42 @@ func (fh *forwarderHealth) Stop() {
 	<-fh.stopped
 }
 
-func (fh *forwarderHealth) healthCheckLoop(keysPerDomains map[string][]string) {
+// Revalidate validates the API keys again, e.g. after a rotation, the status of
+// the previous keys being removed
+func (fh *forwarderHealth) Revalidate() {
+	select {
+	case fh.revalidate <- struct{}{}:
+	default:
+		// a validation is already pending
+	}
+}
+
+func (fh *forwarderHealth) healthCheckLoop() {
 	log.Debug("Waiting for APIkey validity to be confirmed.")
 
-	validateTicker := time.NewTicker(time.Hour * 1)
+	validateTicker := time.NewTicker(fh.interval)
 	defer validateTicker.Stop()
 	defer close(fh.stopped)
 
-	valid := fh.hasValidAPIKey(keysPerDomains)
-	// If no key is valid, no need to keep checking, they won't magicaly become valid
-	if !valid {
-		log.Errorf("No valid api key found, reporting the forwarder as unhealthy.")
-		return
-	}
+	valid := fh.checkAPIKeys()
 
 	for {
+		// The forwarder is reported as unhealthy while no key is valid, the
+		// keys are validated again periodically and when they are rotated
+		healthC := fh.health.C
+		if !valid {
+			healthC = nil
+		}
+
 		select {
 		case <-fh.stop:
 			return
 		case <-validateTicker.C:
-			valid := fh.hasValidAPIKey(keysPerDomains)
-			if !valid {
-				log.Errorf("No valid api key found, reporting the forwarder as unhealthy.")
-				return
-			}
-		case <-fh.health.C:
+			valid = fh.checkAPIKeys()
+		case <-fh.revalidate:
+			apiKeyStatus.Init()
+			valid = fh.checkAPIKeys()
+		case <-healthC:
 			if transactionsDroppedOnInput.Value() != 0 {
 				log.Errorf("Detected dropped transaction, reporting the forwarder as unhealthy: %v.", transactionsDroppedOnInput)
 				return
@@ -112,6 +133,14 @@ func (fh *forwarderHealth) healthCheckLoop(keysPerDomains map[string][]string) {
 	}
 }
 
+func (fh *forwarderHealth) checkAPIKeys() bool {
+	valid := fh.hasValidAPIKey(fh.keysPerDomains())
+	if !valid {
+		log.Errorf("No valid api key found, reporting the forwarder as unhealthy.")
+	}
+	return valid
+}
+
 func (fh *forwarderHealth) setAPIKeyStatus(apiKey string, domain string, status expvar.Var) {
 	obfuscatedKey := fmt.Sprintf("%s,*************************", domain)
 	if len(apiKey) > 5 {
@@ -121,32 +150,41 @@ func (fh *forwarderHealth) setAPIKeyStatus(apiKey string, domain string, status
 }
 
 func (fh *forwarderHealth) validateAPIKey(apiKey, domain string) (bool, error) {
-	url := fmt.Sprintf("%s%s?api_key=%s", fh.ddURL, v1ValidateEndpoint, apiKey)
+	valid, err := checkAPIKey(fh.ddURL, apiKey, fh.timeout)
+	if err != nil {
+		fh.setAPIKeyStatus(apiKey, domain, &apiKeyStatusUnknown)
+	} else if valid {
+		fh.setAPIKeyStatus(apiKey, domain, &apiKeyValid)
+	} else {
+		fh.setAPIKeyStatus(apiKey, domain, &apiKeyInvalid)
+	}
+	return valid, err
+}
+
+// checkAPIKey queries the validation endpoint of ddURL for the API key
+func checkAPIKey(ddURL, apiKey string, timeout time.Duration) (bool, error) {
+	url := fmt.Sprintf("%s%s?api_key=%s", ddURL, v1ValidateEndpoint, apiKey)
 
 	transport := util.CreateHTTPTransport()
 
 	client := &http.Client{
 		Transport: transport,
-		Timeout:   fh.timeout,
+		Timeout:   timeout,
 	}
 
 	resp, err := client.Get(url)
 	if err != nil {
-		fh.setAPIKeyStatus(apiKey, domain, &apiKeyStatusUnknown)
 		return false, err
 	}
 	defer resp.Body.Close()
 
 	// Server will respond 200 if the key is valid or 403 if invalid
 	if resp.StatusCode == 200 {
-		fh.setAPIKeyStatus(apiKey, domain, &apiKeyValid)
 		return true, nil
 	} else if resp.StatusCode == 403 {
-		fh.setAPIKeyStatus(apiKey, domain, &apiKeyInvalid)
 		return false, nil
 	}
 
-	fh.setAPIKeyStatus(apiKey, domain, &apiKeyStatusUnknown)
 	return false, fmt.Errorf("Unexpected response code from the apikey validation endpoint: %v", resp.StatusCode)
 }
 
@@ -120,6 +120,31 @@ func TestCreateHTTPTransactions(t *testing.T) {
 	assert.Contains(t, transactions[3].Endpoint, "api_key=api-key-2")
 }
 
+func TestCreateHTTPTransactionsShed(t *testing.T) {
+	forwarder := NewDefaultForwarder(map[string][]string{
+		"datadog.foo": {"api-key-1"},
+		"datadog.bar": {"api-key-2"},
+	})
+	p := []byte("A payload")
+	payloads := Payloads{&p}
+
+	// only the transactions to datadog.foo pile up
+	congested := forwarder.domainForwarders["datadog.foo"]
+	congested.init()
+	congested.internalState = Started
+	for i := 0; i < congested.retryQueueLimit; i++ {
+		congested.requeueTransaction(NewHTTPTransaction())
+	}
+	congested.maxDelayedFlushes = 0
+	assert.False(t, forwarder.UpdateCongestion())
+
+	transactions := forwarder.createHTTPTransactions(sketchSeriesEndpoint, payloads, true, make(http.Header))
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "datadog.bar", transactions[0].Domain)
+	transactions = forwarder.createHTTPTransactions(seriesEndpoint, payloads, false, make(http.Header))
+	assert.Len(t, transactions, 2)
+}
+
 func TestSendHTTPTransactions(t *testing.T) {
 	forwarder := NewDefaultForwarder(keysPerDomains)
 	endpoint := "/api/foo"
@@ -215,3 +240,67 @@ func TestForwarderEndtoEnd(t *testing.T) {
 	ts.Close()
 	assert.Equal(t, int64(38), requests)
 }
+
+func TestUpdateAPIKey(t *testing.T) {
+	newValidationServer := func(invalidKey string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			if r.Form.Get("api_key") == invalidKey {
+				w.WriteHeader(http.StatusForbidden)
+			} else {
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+	}
+	foo := newValidationServer("invalid-key")
+	defer foo.Close()
+	bar := newValidationServer("invalid-bar-key")
+	defer bar.Close()
+	apiKey := config.Datadog.Get("api_key")
+	config.Datadog.Set("api_key", "api-key-1")
+	defer config.Datadog.Set("api_key", apiKey)
+
+	forwarder := NewDefaultForwarder(map[string][]string{
+		foo.URL: {"api-key-1", "api-key-2"},
+		bar.URL: {"api-key-1"},
+	})
+
+	assert.NotNil(t, forwarder.UpdateAPIKey("api-key-1", "invalid-key"))
+	// the key is validated against each domain using the replaced key
+	assert.NotNil(t, forwarder.UpdateAPIKey("api-key-1", "invalid-bar-key"))
+	assert.NotNil(t, forwarder.UpdateAPIKey("unknown-key", "api-key-3"))
+	assert.NotNil(t, forwarder.UpdateAPIKey("api-key-1", ""))
+	require.Nil(t, forwarder.UpdateAPIKey("api-key-2", "invalid-bar-key"))
+
+	// the api_key is replaced by default, the configuration is left untouched
+	require.Nil(t, forwarder.UpdateAPIKey("", "api-key-3"))
+	assert.Equal(t, map[string][]string{
+		foo.URL: {"api-key-3", "invalid-bar-key"},
+		bar.URL: {"api-key-3"},
+	}, forwarder.getKeysPerDomains())
+	assert.Equal(t, "api-key-1", config.Datadog.GetString("api_key"))
+	require.Nil(t, forwarder.UpdateAPIKey("", "api-key-4"))
+	assert.Equal(t, []string{"api-key-4"}, forwarder.getKeysPerDomains()[bar.URL])
+
+	transactions := forwarder.createHTTPTransactions("/api/foo", Payloads{&[]byte{}}, false, nil)
+	apiKeys := []string{}
+	for _, tr := range transactions {
+		apiKeys = append(apiKeys, tr.Headers.Get(apiHTTPHeaderKey))
+	}
+	assert.ElementsMatch(t, []string{"api-key-4", "invalid-bar-key", "api-key-4"}, apiKeys)
+}
+
+func TestUpdateAPIKeyValidationError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	forwarder := NewDefaultForwarder(map[string][]string{
+		ts.URL: {"api-key-1"},
+	})
+
+	// the rotation is rejected when the new key could not be validated
+	assert.NotNil(t, forwarder.UpdateAPIKey("api-key-1", "api-key-2"))
+	assert.Equal(t, map[string][]string{ts.URL: {"api-key-1"}}, forwarder.getKeysPerDomains())
+}
@@ -96,3 +96,13 @@ func (tf *MockedForwarder) SubmitHostMetadata(payload Payloads, extra http.Heade
 func (tf *MockedForwarder) SubmitMetadata(payload Payloads, extra http.Header) error {
 	return tf.Called(payload, extra).Error(0)
 }
+
+// SubmitOrchestratorManifests updates the internal mock struct
+func (tf *MockedForwarder) SubmitOrchestratorManifests(payload Payloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
+// UpdateCongestion returns false, the mock is never congested
+func (tf *MockedForwarder) UpdateCongestion() bool {
+	return false
+}
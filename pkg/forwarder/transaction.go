@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -24,6 +25,11 @@ var (
 	transactionsSuccessful     = expvar.Int{}
 	transactionsDroppedOnInput = expvar.Int{}
 	transactionsErrors         = expvar.Int{}
+
+	// transactionsPerDomain holds the counters of each domain, the
+	// Transactions ones being their sum, and the errors by class
+	transactionsPerDomain     = expvar.Map{}
+	transactionsPerDomainLock sync.Mutex
 )
 
 func initTransactionExpvars() {
@@ -31,6 +37,22 @@ func initTransactionExpvars() {
 	transactionsExpvars.Set("Success", &transactionsSuccessful)
 	transactionsExpvars.Set("DroppedOnInput", &transactionsDroppedOnInput)
 	transactionsExpvars.Set("Errors", &transactionsErrors)
+	forwarderExpvars.Set("TransactionsPerDomain", &transactionsPerDomain)
+}
+
+// domainStats returns the counters of the domain: Success, Errors,
+// ConnectionErrors, ClientErrors (4xx), ServerErrors (5xx), Dropped,
+// DroppedOnInput, Retried, Requeued and RetryQueueSize
+func domainStats(domain string) *expvar.Map {
+	transactionsPerDomainLock.Lock()
+	defer transactionsPerDomainLock.Unlock()
+
+	if stats, ok := transactionsPerDomain.Get(domain).(*expvar.Map); ok {
+		return stats
+	}
+	stats := new(expvar.Map).Init()
+	transactionsPerDomain.Set(domain, stats)
+	return stats
 }
 
 // HTTPTransaction represents one Payload for one Endpoint on one Domain.
@@ -82,10 +104,13 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 	url := t.Domain + t.Endpoint
 	logURL := util.SanitizeURL(url) // sanitized url that can be logged
 
+	stats := domainStats(t.Domain)
+
 	req, err := http.NewRequest("POST", url, reader)
 	if err != nil {
 		log.Errorf("Could not create request for transaction to invalid URL %q (dropping transaction): %s", logURL, err)
 		transactionsErrors.Add(1)
+		stats.Add("Errors", 1)
 		return nil
 	}
 	req = req.WithContext(ctx)
@@ -99,6 +124,8 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 		}
 		t.ErrorCount++
 		transactionsErrors.Add(1)
+		stats.Add("Errors", 1)
+		stats.Add("ConnectionErrors", 1)
 		return fmt.Errorf("error while sending transaction, rescheduling it: %s", util.SanitizeURL(err.Error()))
 	}
 	defer resp.Body.Close()
@@ -109,21 +136,31 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 		return err
 	}
 
+	if resp.StatusCode >= 500 {
+		stats.Add("ServerErrors", 1)
+	} else if resp.StatusCode >= 400 {
+		stats.Add("ClientErrors", 1)
+	}
+
 	if resp.StatusCode == 400 || resp.StatusCode == 404 || resp.StatusCode == 413 {
 		log.Errorf("Error code %q received while sending transaction to %q: %s, dropping it", resp.Status, logURL, string(body))
 		transactionsDropped.Add(1)
+		stats.Add("Dropped", 1)
 		return nil
 	} else if resp.StatusCode == 403 {
 		log.Errorf("API Key invalid, dropping transaction for %s", logURL)
 		transactionsDropped.Add(1)
+		stats.Add("Dropped", 1)
 		return nil
 	} else if resp.StatusCode > 400 {
 		t.ErrorCount++
 		transactionsErrors.Add(1)
+		stats.Add("Errors", 1)
 		return fmt.Errorf("error %q while sending transaction to %q, rescheduling it", resp.Status, logURL)
 	}
 
 	transactionsSuccessful.Add(1)
+	stats.Add("Success", 1)
 
 	loggingFrequency := config.Datadog.GetInt64("logging_frequency")
 
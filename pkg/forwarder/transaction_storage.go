@@ -0,0 +1,307 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// transactionStorageVersion is the version of the files format, the
+	// files of the other versions are removed
+	transactionStorageVersion = 2
+	transactionFileExtension  = ".retry"
+	apiKeyPlaceholder         = "__api_key__"
+)
+
+var (
+	transactionFileHeader = []byte("DDRETRY")
+
+	transactionsStoredOnDisk   = expvar.Int{}
+	transactionsLoadedFromDisk = expvar.Int{}
+	transactionsDroppedOnDisk  = expvar.Int{}
+)
+
+func initTransactionStorageExpvars() {
+	transactionsExpvars.Set("StoredOnDisk", &transactionsStoredOnDisk)
+	transactionsExpvars.Set("LoadedFromDisk", &transactionsLoadedFromDisk)
+	transactionsExpvars.Set("DroppedOnDisk", &transactionsDroppedOnDisk)
+}
+
+// storedTransaction is the serialized HTTPTransaction. The API key is
+// replaced by its hash, so that it is not written to the disk and the
+// transaction is only sent again with the key it was created with.
+type storedTransaction struct {
+	Endpoint   string      `json:"endpoint"`
+	Headers    http.Header `json:"headers"`
+	Payload    []byte      `json:"payload"`
+	ErrorCount int         `json:"error_count"`
+	CreatedAt  time.Time   `json:"created_at"`
+	APIKeyHash string      `json:"api_key_hash"`
+}
+
+// transactionStorage keeps the transactions of a domain the retry queue can't
+// hold on the disk, a file per batch, so that they survive a restart. The
+// oldest files are removed past maxSize bytes.
+type transactionStorage struct {
+	path    string
+	domain  string
+	apiKeys []string
+	keysMu  sync.Mutex // to protect the apiKeys field, the keys can be rotated
+	maxSize int64
+	// files are sorted from the oldest to the newest
+	files []string
+	sizes map[string]int64
+	size  int64
+}
+
+func newTransactionStorage(path string, domain string, apiKeys []string, maxSize int64) (*transactionStorage, error) {
+	path = filepath.Join(path, domainDirectory(domain))
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, fmt.Errorf("could not create the transaction storage directory: %s", err)
+	}
+
+	s := &transactionStorage{
+		path:    path,
+		domain:  domain,
+		apiKeys: append([]string(nil), apiKeys...),
+		maxSize: maxSize,
+		sizes:   make(map[string]int64),
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the stored transactions: %s", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), transactionFileExtension) {
+			continue
+		}
+		name := filepath.Join(path, entry.Name())
+		s.files = append(s.files, name)
+		s.sizes[name] = entry.Size()
+		s.size += entry.Size()
+	}
+	// the files are named after their creation time
+	sort.Strings(s.files)
+	s.makeRoom(0)
+
+	return s, nil
+}
+
+// domainDirectory returns the directory name of the domain, e.g.
+// app.datadoghq.com for https://app.datadoghq.com
+func domainDirectory(domain string) string {
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':':
+			return '_'
+		}
+		return r
+	}, domain)
+}
+
+// setAPIKey replaces the i-th API key, the stored transactions of the
+// previous key are dropped when loaded
+func (s *transactionStorage) setAPIKey(i int, apiKey string) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	if i < len(s.apiKeys) {
+		s.apiKeys[i] = apiKey
+	}
+}
+
+// isEmpty returns whether no transaction is stored
+func (s *transactionStorage) isEmpty() bool {
+	return len(s.files) == 0
+}
+
+// store writes the transactions to a new file
+func (s *transactionStorage) store(transactions []*HTTPTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	data, err := s.serialize(transactions)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > s.maxSize {
+		transactionsDroppedOnDisk.Add(int64(len(transactions)))
+		return fmt.Errorf("the %d transactions exceed the storage size of %d bytes", len(transactions), s.maxSize)
+	}
+	s.makeRoom(int64(len(data)))
+
+	name := filepath.Join(s.path, fmt.Sprintf("%020d%s", time.Now().UnixNano(), transactionFileExtension))
+	tmpName := name + ".tmp"
+	if err := ioutil.WriteFile(tmpName, data, 0600); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("could not write the transactions: %s", err)
+	}
+	if err := os.Rename(tmpName, name); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("could not write the transactions: %s", err)
+	}
+
+	s.files = append(s.files, name)
+	s.sizes[name] = int64(len(data))
+	s.size += int64(len(data))
+	transactionsStoredOnDisk.Add(int64(len(transactions)))
+	return nil
+}
+
+// loadNewest reads and removes the newest file, the newest transactions being
+// retried first like in the retry queue
+func (s *transactionStorage) loadNewest() ([]*HTTPTransaction, error) {
+	if s.isEmpty() {
+		return nil, nil
+	}
+
+	name := s.files[len(s.files)-1]
+	s.removeFile(len(s.files) - 1)
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the stored transactions: %s", err)
+	}
+	transactions, err := s.deserialize(data)
+	if err != nil {
+		return nil, fmt.Errorf("dropping the stored transactions of %s: %s", name, err)
+	}
+	transactionsLoadedFromDisk.Add(int64(len(transactions)))
+	return transactions, nil
+}
+
+// makeRoom removes the oldest files until size bytes can be stored
+func (s *transactionStorage) makeRoom(size int64) {
+	for len(s.files) > 0 && s.size+size > s.maxSize {
+		log.Warnf("The transaction storage of %s is full, dropping the oldest transactions", s.domain)
+		s.removeFile(0)
+	}
+}
+
+func (s *transactionStorage) removeFile(i int) {
+	name := s.files[i]
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		log.Errorf("Could not remove the stored transactions %s: %s", name, err)
+	}
+	s.size -= s.sizes[name]
+	delete(s.sizes, name)
+	s.files = append(s.files[:i], s.files[i+1:]...)
+}
+
+func (s *transactionStorage) serialize(transactions []*HTTPTransaction) ([]byte, error) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	stored := make([]storedTransaction, 0, len(transactions))
+	for _, t := range transactions {
+		st := storedTransaction{
+			Endpoint:   t.Endpoint,
+			Headers:    make(http.Header, len(t.Headers)),
+			ErrorCount: t.ErrorCount,
+			CreatedAt:  t.createdAt,
+		}
+		if t.Payload != nil {
+			st.Payload = *t.Payload
+		}
+		apiKey := t.Headers.Get(apiHTTPHeaderKey)
+		for _, key := range s.apiKeys {
+			if key == apiKey {
+				st.APIKeyHash = hashAPIKey(apiKey)
+				break
+			}
+		}
+		if st.APIKeyHash == "" {
+			// only the transactions of the configured keys are stored
+			transactionsDroppedOnDisk.Add(1)
+			continue
+		}
+		for k, v := range t.Headers {
+			st.Headers[k] = v
+		}
+		st.Headers.Set(apiHTTPHeaderKey, apiKeyPlaceholder)
+		st.Endpoint = strings.Replace(st.Endpoint, apiKey, apiKeyPlaceholder, -1)
+		stored = append(stored, st)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(transactionFileHeader)
+	buf.WriteByte(transactionStorageVersion)
+	if err := json.NewEncoder(&buf).Encode(stored); err != nil {
+		return nil, fmt.Errorf("could not serialize the transactions: %s", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *transactionStorage) deserialize(data []byte) ([]*HTTPTransaction, error) {
+	if !bytes.HasPrefix(data, transactionFileHeader) || len(data) <= len(transactionFileHeader) {
+		return nil, errors.New("not a transaction file")
+	}
+	if version := data[len(transactionFileHeader)]; version != transactionStorageVersion {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+
+	var stored []storedTransaction
+	if err := json.Unmarshal(data[len(transactionFileHeader)+1:], &stored); err != nil {
+		return nil, fmt.Errorf("could not deserialize the transactions: %s", err)
+	}
+
+	s.keysMu.Lock()
+	apiKeys := make(map[string]string, len(s.apiKeys))
+	for _, key := range s.apiKeys {
+		apiKeys[hashAPIKey(key)] = key
+	}
+	s.keysMu.Unlock()
+
+	transactions := make([]*HTTPTransaction, 0, len(stored))
+	for _, st := range stored {
+		apiKey, found := apiKeys[st.APIKeyHash]
+		if !found {
+			// the API key was removed from the configuration or rotated
+			transactionsDroppedOnDisk.Add(1)
+			continue
+		}
+		payload := st.Payload
+		t := &HTTPTransaction{
+			Domain:     s.domain,
+			Endpoint:   strings.Replace(st.Endpoint, apiKeyPlaceholder, apiKey, -1),
+			Headers:    st.Headers,
+			Payload:    &payload,
+			ErrorCount: st.ErrorCount,
+			createdAt:  st.CreatedAt,
+		}
+		if t.Headers == nil {
+			t.Headers = make(http.Header)
+		}
+		t.Headers.Set(apiHTTPHeaderKey, apiKey)
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
+
+// hashAPIKey returns the hash identifying an API key in the stored transactions
+func hashAPIKey(apiKey string) string {
+	h := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorageTestTransaction(apiKey string, payload string) *HTTPTransaction {
+	t := NewHTTPTransaction()
+	t.Domain = "https://app.datadoghq.com"
+	t.Endpoint = "/api/v1/series?api_key=" + apiKey
+	p := []byte(payload)
+	t.Payload = &p
+	t.Headers.Set(apiHTTPHeaderKey, apiKey)
+	t.Headers.Set("Content-Type", "application/json")
+	t.ErrorCount = 2
+	return t
+}
+
+func TestTransactionStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transactions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	keys := []string{"api_key1", "api_key2"}
+	s, err := newTransactionStorage(dir, "https://app.datadoghq.com", keys, 1024*1024)
+	require.NoError(t, err)
+	assert.True(t, s.isEmpty())
+
+	transactions := []*HTTPTransaction{
+		newStorageTestTransaction("api_key1", "first"),
+		newStorageTestTransaction("api_key2", "second"),
+		newStorageTestTransaction("unknown_key", "dropped"),
+	}
+	require.NoError(t, s.store(transactions))
+	assert.False(t, s.isEmpty())
+
+	// the API keys are not written on disk
+	files, err := filepath.Glob(filepath.Join(dir, "app.datadoghq.com", "*"+transactionFileExtension))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "api_key1")
+	assert.NotContains(t, string(content), "api_key2")
+
+	// the files are replayed after a restart
+	s, err = newTransactionStorage(dir, "https://app.datadoghq.com", keys, 1024*1024)
+	require.NoError(t, err)
+	loaded, err := s.loadNewest()
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	for i, l := range loaded {
+		assert.Equal(t, transactions[i].Domain, l.Domain)
+		assert.Equal(t, transactions[i].Endpoint, l.Endpoint)
+		assert.Equal(t, transactions[i].Headers, l.Headers)
+		assert.Equal(t, *transactions[i].Payload, *l.Payload)
+		assert.Equal(t, 2, l.ErrorCount)
+		assert.True(t, transactions[i].createdAt.Equal(l.createdAt))
+	}
+	assert.True(t, s.isEmpty())
+	files, err = filepath.Glob(filepath.Join(dir, "app.datadoghq.com", "*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 0)
+}
+
+func TestTransactionStorageAPIKeyChanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transactions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := newTransactionStorage(dir, "https://app.datadoghq.com", []string{"api_key1", "api_key2"}, 1024*1024)
+	require.NoError(t, err)
+	require.NoError(t, s.store([]*HTTPTransaction{
+		newStorageTestTransaction("api_key1", "first"),
+		newStorageTestTransaction("api_key2", "second"),
+	}))
+
+	// the keys were reordered and the first one replaced: each transaction is
+	// only sent again with its own key
+	s, err = newTransactionStorage(dir, "https://app.datadoghq.com", []string{"api_key2", "api_key3"}, 1024*1024)
+	require.NoError(t, err)
+	loaded, err := s.loadNewest()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "second", string(*loaded[0].Payload))
+	assert.Equal(t, "api_key2", loaded[0].Headers.Get(apiHTTPHeaderKey))
+	assert.Equal(t, "/api/v1/series?api_key=api_key2", loaded[0].Endpoint)
+}
+
+func TestTransactionStorageMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transactions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := newTransactionStorage(dir, "https://app.datadoghq.com", []string{"api_key"}, 1024)
+	require.NoError(t, err)
+
+	payload := string(make([]byte, 400))
+	require.NoError(t, s.store([]*HTTPTransaction{newStorageTestTransaction("api_key", payload+"old")}))
+	require.NoError(t, s.store([]*HTTPTransaction{newStorageTestTransaction("api_key", payload+"new")}))
+	assert.Len(t, s.files, 1)
+	assert.True(t, s.size <= 1024)
+
+	loaded, err := s.loadNewest()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, payload+"new", string(*loaded[0].Payload))
+
+	// a batch larger than the storage is dropped
+	assert.Error(t, s.store([]*HTTPTransaction{newStorageTestTransaction("api_key", string(make([]byte, 2048)))}))
+}
+
+func TestTransactionStorageVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transactions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := newTransactionStorage(dir, "https://app.datadoghq.com", []string{"api_key"}, 1024*1024)
+	require.NoError(t, err)
+	data, err := s.serialize([]*HTTPTransaction{newStorageTestTransaction("api_key", "payload")})
+	require.NoError(t, err)
+
+	data[len(transactionFileHeader)] = transactionStorageVersion + 1
+	_, err = s.deserialize(data)
+	assert.Error(t, err)
+
+	_, err = s.deserialize([]byte("not a transaction file"))
+	assert.Error(t, err)
+}
+
+func TestDomainDirectory(t *testing.T) {
+	assert.Equal(t, "app.datadoghq.com", domainDirectory("https://app.datadoghq.com"))
+	assert.Equal(t, "localhost_8080", domainDirectory("http://localhost:8080"))
+}
@@ -109,6 +109,14 @@ func TestProcessHTTPError(t *testing.T) {
 	err = transaction.Process(context.Background(), client)
 	assert.Nil(t, err)
 	assert.Equal(t, transaction.ErrorCount, 1)
+
+	// the test server has its own domain
+	stats := domainStats(ts.URL)
+	assert.Equal(t, "1", stats.Get("Errors").String())
+	assert.Equal(t, "1", stats.Get("ServerErrors").String())
+	assert.Equal(t, "3", stats.Get("ClientErrors").String())
+	assert.Equal(t, "3", stats.Get("Dropped").String())
+	assert.Nil(t, stats.Get("Success"))
 }
 
 func TestProcessCancel(t *testing.T) {
@@ -10,6 +10,7 @@ package jmxfetch
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -202,30 +203,51 @@ func (j *JMXFetch) Start() error {
 	if err != nil {
 		return err
 	}
-	go func() {
-		in := bufio.NewScanner(stdout)
-		for in.Scan() {
-			log.Info(in.Text())
-		}
-	}()
+	go forwardLogs(stdout, "INFO")
 
 	// forward the standard error to the Agent logger
 	stderr, err := j.cmd.StderrPipe()
 	if err != nil {
 		return err
 	}
-	go func() {
-		in := bufio.NewScanner(stderr)
-		for in.Scan() {
-			log.Error(in.Text())
-		}
-	}()
+	go forwardLogs(stderr, "ERROR")
 
 	log.Debugf("Args: %v", subprocessArgs)
 
 	return j.cmd.Start()
 }
 
+// forwardLogs forwards the output of JMXFetch to the Agent logger. Lines are
+// logged at the level of the JMXFetch log entry they belong to, in the
+// "date | level | class | message" format, and at defaultLevel otherwise.
+func forwardLogs(r io.Reader, defaultLevel string) {
+	in := bufio.NewScanner(r)
+	for in.Scan() {
+		line := in.Text()
+		level := defaultLevel
+		if fields := strings.SplitN(line, " | ", 3); len(fields) == 3 {
+			level = strings.TrimSpace(fields[1])
+		}
+		logLine(level, line)
+	}
+}
+
+// logLine logs a JMXFetch line at the Agent level matching a JMXFetch level
+func logLine(level string, line string) {
+	switch level {
+	case "TRACE":
+		log.Trace(line)
+	case "DEBUG":
+		log.Debug(line)
+	case "WARN":
+		log.Warn(line)
+	case "ERROR", "FATAL":
+		log.Error(line)
+	default:
+		log.Info(line)
+	}
+}
+
 // Kill kills the JMXFetch process
 func (j *JMXFetch) Kill() error {
 	if j.JmxExitFile == "" {
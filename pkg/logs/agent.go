@@ -11,6 +11,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/input/docker"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/file"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/journald"
+	"github.com/DataDog/datadog-agent/pkg/logs/input/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/listener"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/windowsevent"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -44,18 +45,26 @@ func NewAgent(sources *config.LogSources) *Agent {
 		config.LogsAgent.GetInt("logs_config.dd_port"),
 		config.LogsAgent.GetBool("logs_config.dev_mode_no_ssl"),
 		config.LogsAgent.GetString("logs_config.socks5_proxy_address"),
+		config.LogsAgent.GetString("logs_config.socks5_proxy_username"),
+		config.LogsAgent.GetString("logs_config.socks5_proxy_password"),
 	)
 	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, connectionManager, messageChan)
 
 	// setup the inputs
 	validSources := sources.GetValidSources()
+	fileScanner := file.New(validSources, config.LogsAgent.GetInt("logs_config.open_files_limit"), pipelineProvider, auditor, file.DefaultSleepDuration)
 	inputs := []restart.Restartable{
-		docker.NewScanner(validSources, pipelineProvider, auditor),
 		listener.New(validSources, pipelineProvider),
-		file.New(validSources, config.LogsAgent.GetInt("logs_config.open_files_limit"), pipelineProvider, auditor, file.DefaultSleepDuration),
+		fileScanner,
 		journald.New(validSources, pipelineProvider, auditor),
 		windowsevent.New(validSources, pipelineProvider, auditor),
 	}
+	if config.LogsAgent.GetBool("logs_config.k8s_container_use_file") {
+		// the logs of the containers are tailed from the files written by the kubelet
+		inputs = append(inputs, kubernetes.NewLauncher(fileScanner, config.LogsAgent.GetBool("logs_config.container_collect_all")))
+	} else {
+		inputs = append(inputs, docker.NewScanner(sources, pipelineProvider, auditor))
+	}
 
 	return &Agent{
 		auditor:          auditor,
@@ -25,6 +25,10 @@ const (
 	DockerType       = "docker"
 	JournaldType     = "journald"
 	WindowsEventType = "windows_event"
+
+	// KubernetesType is the type of the sources tailing the log files of the
+	// kubernetes containers, it can't be set in a configuration
+	KubernetesType = "kubernetes"
 )
 
 // Logs rule types
@@ -48,7 +52,10 @@ type LogsProcessingRule struct {
 	Type               string
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
-	Pattern            string
+	// ExpandCaptureGroups lets the placeholder of a masking rule reference the
+	// capture groups of the pattern, e.g. ${1}, the placeholder is literal otherwise
+	ExpandCaptureGroups bool `mapstructure:"expand_capture_groups" json:"expand_capture_groups"`
+	Pattern             string
 	// TODO: should be moved out
 	Reg                     *regexp.Regexp
 	ReplacePlaceholderBytes []byte
@@ -62,12 +69,17 @@ type LogsConfig struct {
 	Port int    // Network
 	Path string // File, Journald
 
+	TLSCert   string `mapstructure:"tls_cert" json:"tls_cert"`       // TCP
+	TLSKey    string `mapstructure:"tls_key" json:"tls_key"`         // TCP
+	TLSCACert string `mapstructure:"tls_ca_cert" json:"tls_ca_cert"` // TCP, verifies the client certificates when set
+
 	IncludeUnits []string `mapstructure:"include_units" json:"include_units"` // Journald
 	ExcludeUnits []string `mapstructure:"exclude_units" json:"exclude_units"` // Journald
 
-	Image string // Docker
-	Label string // Docker
-	Name  string // Docker
+	Image      string // Docker
+	Label      string // Docker
+	Name       string // Docker
+	Identifier string `json:"-"` // Docker, the ID of the container of the autodiscovery configurations
 
 	ChannelPath string `mapstructure:"channel_path" json:"channel_path"` // Windows Event
 	Query       string // Windows Event
@@ -207,6 +219,12 @@ func validateConfig(config LogsConfig) error {
 		return fmt.Errorf("A tcp source must have a port")
 	case config.Type == UDPType && config.Port == 0:
 		return fmt.Errorf("A udp source must have a port")
+	case config.Type != TCPType && (config.TLSCert != "" || config.TLSKey != "" || config.TLSCACert != ""):
+		return fmt.Errorf("TLS is only supported by the tcp sources")
+	case (config.TLSCert == "") != (config.TLSKey == ""):
+		return fmt.Errorf("A tcp source must have both a tls_cert and a tls_key to use TLS")
+	case config.TLSCACert != "" && config.TLSCert == "":
+		return fmt.Errorf("A tcp source must have a tls_cert and a tls_key to verify the client certificates")
 	default:
 		return validateProcessingRules(config.ProcessingRules)
 	}
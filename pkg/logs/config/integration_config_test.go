@@ -141,6 +141,10 @@ func TestBuildLogsAgentIntegrationConfigsWithMisconfiguredFile(t *testing.T) {
 	ddconfdPath = filepath.Join(testsPath, "misconfigured_5", "conf.d")
 	_, err = buildLogSources(ddconfdPath, false, -1)
 	assert.NotNil(t, err)
+
+	ddconfdPath = filepath.Join(testsPath, "misconfigured_6", "conf.d")
+	_, err = buildLogSources(ddconfdPath, false, -1)
+	assert.NotNil(t, err)
 }
 
 func TestIntegrationName(t *testing.T) {
@@ -5,20 +5,45 @@
 
 package config
 
+import "sync"
+
 // LogSources stores a list of log sources.
+// Sources can be added and removed at runtime, e.g. by autodiscovery.
 type LogSources struct {
+	mu      sync.RWMutex
 	sources []*LogSource
 }
 
-// newLogsSource creates a new log sources.
-func newLogSources(sources []*LogSource) *LogSources {
+// NewLogSources creates a new log sources.
+func NewLogSources(sources []*LogSource) *LogSources {
 	return &LogSources{
 		sources: sources,
 	}
 }
 
+// AddSource adds a source.
+func (s *LogSources) AddSource(source *LogSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources = append(s.sources, source)
+}
+
+// RemoveSource removes a source.
+func (s *LogSources) RemoveSource(source *LogSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, src := range s.sources {
+		if src == source {
+			s.sources = append(s.sources[:i:i], s.sources[i+1:]...)
+			return
+		}
+	}
+}
+
 // GetSources returns all the sources currently held.
 func (s *LogSources) GetSources() []*LogSource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.sources
 }
 
@@ -29,8 +54,17 @@ func (s *LogSources) GetValidSources() []*LogSource {
 	})
 }
 
+// GetSourcesByType returns all the sources of the type currently held.
+func (s *LogSources) GetSourcesByType(sourceType string) []*LogSource {
+	return s.getSources(func(source *LogSource) bool {
+		return source.Config != nil && source.Config.Type == sourceType
+	})
+}
+
 // getSources returns all the sources matching the provided filter.
 func (s *LogSources) getSources(filter func(*LogSource) bool) []*LogSource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	sources := make([]*LogSource, 0)
 	for _, source := range s.sources {
 		if filter(source) {
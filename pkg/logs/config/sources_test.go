@@ -18,16 +18,16 @@ type LogSourcesSuite struct {
 }
 
 func (s *LogSourcesSuite) TestGetSources() {
-	s.sources = newLogSources([]*LogSource{})
+	s.sources = NewLogSources([]*LogSource{})
 	s.Equal(0, len(s.sources.GetSources()))
-	s.sources = newLogSources([]*LogSource{NewLogSource("", nil)})
+	s.sources = NewLogSources([]*LogSource{NewLogSource("", nil)})
 	s.Equal(1, len(s.sources.GetSources()))
 }
 
 func (s *LogSourcesSuite) TestGetValidSources() {
 	source1 := NewLogSource("", nil)
 	source2 := NewLogSource("", nil)
-	s.sources = newLogSources([]*LogSource{source1, source2})
+	s.sources = NewLogSources([]*LogSource{source1, source2})
 	s.Equal(2, len(s.sources.GetValidSources()))
 	source1.Status.Error(errors.New("invalid"))
 	s.Equal(1, len(s.sources.GetValidSources()))
@@ -35,6 +35,18 @@ func (s *LogSourcesSuite) TestGetValidSources() {
 	s.Equal(2, len(s.sources.GetValidSources()))
 }
 
+func (s *LogSourcesSuite) TestAddRemoveSource() {
+	source1 := NewLogSource("", &LogsConfig{Type: DockerType})
+	source2 := NewLogSource("", &LogsConfig{Type: FileType})
+	s.sources = NewLogSources([]*LogSource{source1})
+	s.sources.AddSource(source2)
+	s.Equal(2, len(s.sources.GetSources()))
+	s.Equal([]*LogSource{source1}, s.sources.GetSourcesByType(DockerType))
+	s.sources.RemoveSource(source1)
+	s.Equal([]*LogSource{source2}, s.sources.GetSources())
+	s.Equal(0, len(s.sources.GetSourcesByType(DockerType)))
+}
+
 func TestLogSourcesSuite(t *testing.T) {
 	suite.Run(t, new(LogSourcesSuite))
 }
@@ -16,18 +16,29 @@ var contentLenLimit = 256 * 1000
 
 // Input represents a list of bytes consumed by the Decoder
 type Input struct {
-	content []byte
+	content    []byte
+	truncation bool
 }
 
 // NewInput returns a new input
 func NewInput(content []byte) *Input {
-	return &Input{content}
+	return &Input{content: content}
+}
+
+// NewTruncationMarker returns an input marking that the file was truncated,
+// it is passed through the Decoder in order with the content so that the
+// outputs read after the truncation can be told apart from the ones read before
+func NewTruncationMarker() *Input {
+	return &Input{truncation: true}
 }
 
 // Output represents a list of bytes produced by the Decoder
 type Output struct {
 	Content    []byte
 	RawDataLen int
+	// TruncationMarker is set on the output following the content read
+	// before the file was truncated, it has no content
+	TruncationMarker bool
 }
 
 // NewOutput returns a new decoder output
@@ -38,6 +49,11 @@ func NewOutput(content []byte, rawDataLen int) *Output {
 	}
 }
 
+// newTruncationMarker returns the output marking that the file was truncated
+func newTruncationMarker() *Output {
+	return &Output{TruncationMarker: true}
+}
+
 // Decoder splits raw data into lines and passes them to a lineHandler that emits outputs
 type Decoder struct {
 	InputChan  chan *Input
@@ -60,6 +76,8 @@ func InitializeDecoder(source *config.LogSource) *Decoder {
 			switch source.Config.Type {
 			case config.DockerType:
 				lineUnwrapper = NewDockerUnwrapper()
+			case config.KubernetesType:
+				lineUnwrapper = NewKubernetesUnwrapper()
 			default:
 				lineUnwrapper = NewUnwrapper()
 			}
@@ -99,6 +117,10 @@ func (d *Decoder) Stop() {
 // run lets the Decoder handle data coming from InputChan
 func (d *Decoder) run() {
 	for data := range d.InputChan {
+		if data.truncation {
+			d.decodeTruncation()
+			continue
+		}
 		d.decodeIncomingData(data.content)
 	}
 	// finish to stop decoder
@@ -128,6 +150,15 @@ func (d *Decoder) decodeIncomingData(inBuf []byte) {
 	d.lineBuffer.Write(inBuf[i:j])
 }
 
+// decodeTruncation sends the end of the content read before the truncation,
+// then the truncation marker
+func (d *Decoder) decodeTruncation() {
+	if d.lineBuffer.Len() > 0 {
+		d.sendLine()
+	}
+	d.lineHandler.HandleTruncation()
+}
+
 // sendLine copies content from lineBuffer which is passed to lineHandler
 func (d *Decoder) sendLine() {
 	content := make([]byte, d.lineBuffer.Len())
@@ -26,6 +26,10 @@ func (h *MockLineHandler) Handle(content []byte) {
 	h.lineChan <- content
 }
 
+func (h *MockLineHandler) HandleTruncation() {
+	h.lineChan <- nil
+}
+
 func (h *MockLineHandler) Start() {
 
 }
@@ -98,6 +102,19 @@ func TestDecodeIncomingData(t *testing.T) {
 	assert.Equal(t, "", d.lineBuffer.String())
 }
 
+func TestDecodeTruncation(t *testing.T) {
+	h := NewMockLineHandler()
+	d := New(nil, nil, h)
+
+	// the end of the content read before the truncation is sent before the marker
+	d.decodeIncomingData([]byte("helloworld\nhowayou"))
+	d.decodeTruncation()
+	assert.Equal(t, "helloworld", string(<-h.lineChan))
+	assert.Equal(t, "howayou", string(<-h.lineChan))
+	assert.Nil(t, <-h.lineChan)
+	assert.Equal(t, "", d.lineBuffer.String())
+}
+
 func TestDecoderLifeCycle(t *testing.T) {
 	h := NewMockLineHandler()
 	d := New(nil, nil, h)
@@ -17,6 +17,7 @@ var TRUNCATED = []byte("...TRUNCATED...")
 // LineHandler handles byte slices to form line output
 type LineHandler interface {
 	Handle(content []byte)
+	HandleTruncation()
 	Start()
 	Stop()
 }
@@ -42,6 +43,12 @@ func (h *SingleLineHandler) Handle(content []byte) {
 	h.lineChan <- content
 }
 
+// HandleTruncation sends a truncation marker after the lines handled so far,
+// a nil line on lineChan stands for the marker.
+func (h *SingleLineHandler) HandleTruncation() {
+	h.lineChan <- nil
+}
+
 // Stop stops the handler from processing new lines
 func (h *SingleLineHandler) Stop() {
 	close(h.lineChan)
@@ -55,6 +62,12 @@ func (h *SingleLineHandler) Start() {
 // run consumes lines from lineChan to process them
 func (h *SingleLineHandler) run() {
 	for line := range h.lineChan {
+		if line == nil {
+			// the end of a truncated line was lost with the file content
+			h.shouldTruncate = false
+			h.outputChan <- newTruncationMarker()
+			continue
+		}
 		h.process(line)
 	}
 	close(h.outputChan)
@@ -125,6 +138,12 @@ func (h *MultiLineHandler) Handle(content []byte) {
 	h.lineChan <- content
 }
 
+// HandleTruncation sends a truncation marker after the content handled so far,
+// a nil line on lineChan stands for the marker.
+func (h *MultiLineHandler) HandleTruncation() {
+	h.lineChan <- nil
+}
+
 // Stop stops the lineHandler from processing lines
 func (h *MultiLineHandler) Stop() {
 	close(h.lineChan)
@@ -149,8 +168,14 @@ func (h *MultiLineHandler) run() {
 				// lineChan has been closed, no more lines are expected
 				return
 			}
-			// process the new line and restart the timeout
 			flushTimer.Stop()
+			if line == nil {
+				// the content read before the truncation is sent before the marker
+				h.sendContent()
+				h.outputChan <- newTruncationMarker()
+				continue
+			}
+			// process the new line and restart the timeout
 			h.process(line)
 			flushTimer.Reset(h.flushTimeout)
 		case <-flushTimer.C:
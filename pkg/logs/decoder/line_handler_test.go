@@ -145,6 +145,28 @@ func TestMultiLineHandler(t *testing.T) {
 	h.Stop()
 }
 
+func TestMultiLineHandlerTruncation(t *testing.T) {
+	re := regexp.MustCompile("[0-9]+\\.")
+	outputChan := make(chan *Output, 10)
+	h := NewMultiLineHandler(outputChan, re, time.Hour, NewUnwrapper())
+	h.Start()
+
+	// the buffered content is sent before the marker
+	h.Handle([]byte("1. first line"))
+	h.Handle([]byte("second line"))
+	h.HandleTruncation()
+
+	output := <-outputChan
+	assert.Equal(t, "1. first line"+"\\n"+"second line", string(output.Content))
+	assert.False(t, output.TruncationMarker)
+
+	output = <-outputChan
+	assert.True(t, output.TruncationMarker)
+	assert.Equal(t, 0, output.RawDataLen)
+
+	h.Stop()
+}
+
 func TestTrimMultiLine(t *testing.T) {
 	re := regexp.MustCompile("[0-9]+\\.")
 	outputChan := make(chan *Output, 10)
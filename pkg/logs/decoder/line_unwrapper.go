@@ -7,6 +7,7 @@ package decoder
 
 import (
 	parser "github.com/DataDog/datadog-agent/pkg/logs/docker"
+	"github.com/DataDog/datadog-agent/pkg/logs/kubernetes"
 )
 
 // LineUnwrapper removes all the extra information that were added to the original log
@@ -40,3 +41,18 @@ func (u DockerUnwrapper) Unwrap(line []byte) []byte {
 	headerLen := parser.GetDockerMetadataLength(line)
 	return line[headerLen:]
 }
+
+// KubernetesUnwrapper removes the timestamp, stream and tag written by the
+// container runtimes in front of the logs of the kubernetes containers
+type KubernetesUnwrapper struct{}
+
+// NewKubernetesUnwrapper returns a new KubernetesUnwrapper
+func NewKubernetesUnwrapper() *KubernetesUnwrapper {
+	return &KubernetesUnwrapper{}
+}
+
+// Unwrap removes the metadata in front of the content of CRI logs
+func (u KubernetesUnwrapper) Unwrap(line []byte) []byte {
+	metadataLen := kubernetes.GetMetadataLength(line)
+	return line[metadataLen:]
+}
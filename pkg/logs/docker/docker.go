@@ -24,10 +24,6 @@ type Message struct {
 // [8]byte{STREAM_TYPE, 0, 0, 0, SIZE1, SIZE2, SIZE3, SIZE4}[]byte{OUTPUT}
 const dockerHeaderLength = 8
 
-// Docker splits logs that are larger than 16Kb
-// https://github.com/moby/moby/blob/master/daemon/logger/copier.go#L19-L22
-const dockerBufferSize = 16 * 1024
-
 // ParseMessage extracts the date and the status from the raw docker message
 // see https://godoc.org/github.com/moby/moby/client#Client.ContainerLogs
 func ParseMessage(msg []byte) (Message, error) {
@@ -51,11 +47,6 @@ func ParseMessage(msg []byte) (Message, error) {
 
 	} else {
 
-		// remove partial headers that are added by docker when the message gets too long
-		if len(msg) > dockerBufferSize {
-			msg = removePartialDockerMetadata(msg)
-		}
-
 		// remove the header as we don't need it anymore
 		msg = msg[dockerHeaderLength:]
 
@@ -89,31 +80,6 @@ func getStatus(msg []byte) string {
 	}
 }
 
-// removePartialDockerMetadata removes the 8 byte header, timestamp, and space that occurs between 16Kb section of a log.
-// If a docker log is greater than 16Kb, each 16Kb partial section will have a header, timestamp, and space in front of it.
-// For example, a message that is 35kb will be of the form: `H M1H M2H M3` where "H" is what pre-pends each 16 Kb section.
-// This function removes the "H " between two partial messages sections while leaving the very first "H ".
-// Input:
-//   H M1H M2H M3
-// Output:
-//   H M1M2M3
-func removePartialDockerMetadata(msgToClean []byte) []byte {
-	msg := []byte{}
-	metadataLen := GetDockerMetadataLength(msgToClean)
-	start := 0
-	end := min(len(msgToClean), dockerBufferSize+metadataLen)
-
-	for end > 0 && metadataLen > 0 {
-		msg = append(msg, msgToClean[start:end]...)
-		msgToClean = msgToClean[end:]
-		metadataLen = GetDockerMetadataLength(msgToClean)
-		start = metadataLen
-		end = min(len(msgToClean), dockerBufferSize+metadataLen)
-	}
-
-	return msg
-}
-
 // GetDockerMetadataLength returns the length of the 8 bytes header, timestamp, and space
 // that is in front of each message.
 func GetDockerMetadataLength(msg []byte) int {
@@ -126,11 +92,3 @@ func GetDockerMetadataLength(msg []byte) int {
 	}
 	return dockerHeaderLength + idx + 1
 }
-
-// min returns the minimum value between a and b.
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
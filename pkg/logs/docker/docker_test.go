@@ -7,7 +7,6 @@ package docker
 
 import (
 	// "bytes"
-	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,48 +65,3 @@ func TestParseMessageShouldFailWithInvalidInput(t *testing.T) {
 	assert.NotNil(t, err)
 
 }
-
-func TestParseMessageShouldRemovePartialHeaders(t *testing.T) {
-	var msgToClean []byte
-	var dockerMsg Message
-	var expectedMsg []byte
-	var err error
-
-	// 16kb log
-	msgToClean = []byte(buildPartialMessage('a', dockerBufferSize) + header)
-	expectedMsg = []byte(buildMessage('a', dockerBufferSize))
-	dockerMsg, err = ParseMessage(msgToClean)
-	assert.Nil(t, err)
-	assert.Equal(t, "2018-06-14T18:27:03.246999277Z", dockerMsg.Timestamp)
-	assert.Equal(t, message.StatusInfo, dockerMsg.Status)
-	assert.Equal(t, expectedMsg, dockerMsg.Content)
-	assert.Equal(t, dockerBufferSize, len(dockerMsg.Content))
-
-	// over 16kb
-	msgToClean = []byte(buildPartialMessage('a', dockerBufferSize) + buildPartialMessage('b', 50))
-	expectedMsg = []byte(buildMessage('a', dockerBufferSize) + buildMessage('b', 50))
-	dockerMsg, err = ParseMessage(msgToClean)
-	assert.Nil(t, err)
-	assert.Equal(t, "2018-06-14T18:27:03.246999277Z", dockerMsg.Timestamp)
-	assert.Equal(t, message.StatusInfo, dockerMsg.Status)
-	assert.Equal(t, expectedMsg, dockerMsg.Content)
-	assert.Equal(t, dockerBufferSize+50, len(dockerMsg.Content))
-
-	// three times over 16kb
-	msgToClean = []byte(buildPartialMessage('a', dockerBufferSize) + buildPartialMessage('a', dockerBufferSize) + buildPartialMessage('a', dockerBufferSize) + buildPartialMessage('b', 50))
-	expectedMsg = []byte(buildMessage('a', 3*dockerBufferSize) + buildMessage('b', 50))
-	dockerMsg, err = ParseMessage(msgToClean)
-	assert.Nil(t, err)
-	assert.Equal(t, "2018-06-14T18:27:03.246999277Z", dockerMsg.Timestamp)
-	assert.Equal(t, message.StatusInfo, dockerMsg.Status)
-	assert.Equal(t, expectedMsg, dockerMsg.Content)
-	assert.Equal(t, 3*dockerBufferSize+50, len(dockerMsg.Content))
-}
-
-func buildPartialMessage(r rune, count int) string {
-	return header + " " + strings.Repeat(string(r), count)
-}
-
-func buildMessage(r rune, count int) string {
-	return strings.Repeat(string(r), count)
-}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Stream types of the header of the frames, see
+// https://godoc.org/github.com/moby/moby/pkg/stdcopy
+const (
+	stdoutStream      byte = 1
+	stderrStream      byte = 2
+	systemErrorStream byte = 3
+)
+
+// maxPartialLength bounds the size of the messages reassembled from several
+// frames, a larger message is split, its remaining frames making a new one.
+const maxPartialLength = 256 * 1024
+
+// StreamReader demultiplexes the logs stream of a container started without
+// tty, as returned by the ContainerLogs API with timestamps. The stream is
+// made of frames: an 8 bytes header holding the stream type and the size of
+// the frame, followed by the timestamp and the content of the message.
+// Docker splits the messages larger than 16Kb into several frames, only the
+// last one ending with a newline.
+//
+// Each message is read as a line made of a header, with a zero size so that
+// it can't hold a newline, the timestamp of its first frame, and its content
+// reassembled from all its frames, to be parsed by ParseMessage. The messages
+// are split every maxPartialLength bytes.
+type StreamReader struct {
+	reader  io.Reader
+	header  [dockerHeaderLength]byte
+	partial []byte
+	pending bytes.Buffer
+}
+
+// NewStreamReader returns a new StreamReader reading the frames from reader
+func NewStreamReader(reader io.Reader) *StreamReader {
+	return &StreamReader{
+		reader: reader,
+	}
+}
+
+// Read reads the complete messages, it blocks until one is available
+func (r *StreamReader) Read(p []byte) (int, error) {
+	for r.pending.Len() == 0 {
+		if err := r.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+	return r.pending.Read(p)
+}
+
+// readFrame reads the next frame, and adds the message to the pending data
+// when it's complete
+func (r *StreamReader) readFrame() error {
+	if _, err := io.ReadFull(r.reader, r.header[:]); err != nil {
+		return err
+	}
+	frame := make([]byte, binary.BigEndian.Uint32(r.header[4:]))
+	if _, err := io.ReadFull(r.reader, frame); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	switch r.header[0] {
+	case stdoutStream, stderrStream:
+	case systemErrorStream:
+		return errors.New("docker error: " + string(frame))
+	default:
+		// stdin is never part of the logs
+		return nil
+	}
+
+	if len(r.partial) == 0 {
+		r.partial = append(r.partial, r.header[0], 0, 0, 0, 0, 0, 0, 0)
+		r.partial = append(r.partial, frame...)
+	} else {
+		// the following frames of a message repeat its timestamp
+		r.partial = append(r.partial, frame[bytes.IndexByte(frame, ' ')+1:]...)
+	}
+	if len(frame) > 0 && frame[len(frame)-1] != '\n' {
+		if len(r.partial) < maxPartialLength {
+			// wait for the end of the message
+			return nil
+		}
+		r.partial = append(r.partial, '\n')
+	}
+	r.pending.Write(r.partial)
+	r.partial = r.partial[:0]
+	return nil
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const timestamp = "2018-06-14T18:27:03.246999277Z"
+
+// buildFrame returns a frame of a multiplexed stream holding content
+func buildFrame(stream byte, content string) []byte {
+	frame := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(content)))
+	return append(frame, content...)
+}
+
+// readMessages returns the messages read from the frames
+func readMessages(t *testing.T, frames ...[]byte) []Message {
+	data, err := ioutil.ReadAll(NewStreamReader(bytes.NewReader(bytes.Join(frames, nil))))
+	require.NoError(t, err)
+	var messages []Message
+	for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte{'\n'}), []byte{'\n'}) {
+		msg, err := ParseMessage(line)
+		require.NoError(t, err)
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestStreamReaderDemultiplexesStreams(t *testing.T) {
+	// the size of the first frame, 266 bytes, holds a newline
+	content := strings.Repeat("x", 234)
+	messages := readMessages(t,
+		buildFrame(stdoutStream, timestamp+" "+content+"\n"),
+		buildFrame(stderrStream, timestamp+" something went wrong\n"),
+	)
+	require.Len(t, messages, 2)
+	assert.Equal(t, []byte(content), messages[0].Content)
+	assert.Equal(t, message.StatusInfo, messages[0].Status)
+	assert.Equal(t, timestamp, messages[0].Timestamp)
+	assert.Equal(t, []byte("something went wrong"), messages[1].Content)
+	assert.Equal(t, message.StatusError, messages[1].Status)
+}
+
+func TestStreamReaderReassemblesPartialMessages(t *testing.T) {
+	chunk := strings.Repeat("a", 16*1024)
+	messages := readMessages(t,
+		buildFrame(stdoutStream, timestamp+" "+chunk),
+		buildFrame(stdoutStream, "2018-06-14T18:27:03.247000000Z "+chunk),
+		buildFrame(stdoutStream, "2018-06-14T18:27:03.247000001Z bbb\n"),
+		buildFrame(stdoutStream, timestamp+" next\n"),
+	)
+	require.Len(t, messages, 2)
+	assert.Equal(t, timestamp, messages[0].Timestamp)
+	assert.Equal(t, []byte(chunk+chunk+"bbb"), messages[0].Content)
+	assert.Equal(t, []byte("next"), messages[1].Content)
+}
+
+func TestStreamReaderSplitsLargeMessages(t *testing.T) {
+	chunk := strings.Repeat("a", 16*1024)
+	var frames [][]byte
+	for i := 0; i < 20; i++ {
+		frames = append(frames, buildFrame(stdoutStream, timestamp+" "+chunk))
+	}
+	frames = append(frames, buildFrame(stdoutStream, "2018-06-14T18:27:03.247000001Z bbb\n"))
+	messages := readMessages(t, frames...)
+	require.Len(t, messages, 2)
+	assert.Equal(t, []byte(strings.Repeat(chunk, 16)), messages[0].Content)
+	assert.Equal(t, timestamp, messages[1].Timestamp)
+	assert.Equal(t, []byte(strings.Repeat(chunk, 4)+"bbb"), messages[1].Content)
+}
+
+func TestStreamReaderErrors(t *testing.T) {
+	frame := buildFrame(stdoutStream, timestamp+" hello\n")
+	_, err := ioutil.ReadAll(NewStreamReader(bytes.NewReader(frame[:len(frame)-2])))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = ioutil.ReadAll(NewStreamReader(bytes.NewReader(buildFrame(systemErrorStream, "no such container"))))
+	assert.EqualError(t, err, "docker error: no such container")
+}
@@ -30,6 +30,12 @@ func NewContainer(container types.Container) *Container {
 // findSource returns the source that most closely matches the container,
 // if no source is found return nil
 func (c *Container) findSource(sources []*config.LogSource) *config.LogSource {
+	for _, source := range sources {
+		// the sources of autodiscovery target the container itself
+		if source.Config.Identifier != "" && source.Config.Identifier == c.Container.ID {
+			return source
+		}
+	}
 	if label := c.getLabel(); label != "" {
 		cfg, err := config.Parse(label)
 		if err != nil {
@@ -41,6 +47,9 @@ func (c *Container) findSource(sources []*config.LogSource) *config.LogSource {
 	}
 	var candidate *config.LogSource
 	for _, source := range sources {
+		if source.Config.Identifier != "" {
+			continue
+		}
 		if source.Config.Image != "" && !c.isImageMatch(source.Config.Image) {
 			continue
 		}
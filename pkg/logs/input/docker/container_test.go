@@ -73,6 +73,22 @@ func TestFindSourceWithNoSourceFilterShouldSucceed(t *testing.T) {
 	}
 }
 
+func TestFindSourceWithIdentifierShouldSucceed(t *testing.T) {
+	sources := []*config.LogSource{
+		config.NewLogSource("", &config.LogsConfig{Type: config.DockerType, Image: "myapp"}),
+		config.NewLogSource("", &config.LogsConfig{Type: config.DockerType, Identifier: "a1b2c3"}),
+	}
+
+	container := NewContainer(types.Container{ID: "a1b2c3", Image: "myapp", Labels: map[string]string{"com.datadoghq.ad.logs": "[{\"source\":\"any_source\",\"service\":\"any_service\"}]"}})
+	assert.Equal(t, sources[1], container.findSource(sources))
+
+	// the sources of autodiscovery only match their own container
+	container = NewContainer(types.Container{ID: "d4e5f6", Image: "myapp"})
+	assert.Equal(t, sources[0], container.findSource(sources))
+	container = NewContainer(types.Container{ID: "d4e5f6", Image: "otherapp"})
+	assert.Nil(t, container.findSource(sources))
+}
+
 func TestFindSourceWithInvalidContainerLabelShouldReturnNil(t *testing.T) {
 	var source *config.LogSource
 	var container *Container
@@ -9,10 +9,13 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
@@ -26,43 +29,36 @@ import (
 
 const scanPeriod = 10 * time.Second
 
+// errNoContainerSource is returned by setup until a docker source is added
+var errNoContainerSource = errors.New("No container source defined")
+
 // A Scanner listens for stdout and stderr of containers
 type Scanner struct {
 	pp        pipeline.Provider
-	sources   []*config.LogSource
+	sources   *config.LogSources
 	tailers   map[string]*Tailer
 	cli       *client.Client
+	filter    *containers.Filter
 	auditor   *auditor.Auditor
 	isRunning bool
 	stop      chan struct{}
 }
 
-// NewScanner returns an initialized Scanner
-func NewScanner(sources []*config.LogSource, pp pipeline.Provider, a *auditor.Auditor) *Scanner {
-	containerSources := []*config.LogSource{}
-	for _, source := range sources {
-		switch source.Config.Type {
-		case config.DockerType:
-			containerSources = append(containerSources, source)
-		default:
-		}
-	}
+// NewScanner returns an initialized Scanner, tailing the containers matching
+// the docker sources, including the ones added later by autodiscovery
+func NewScanner(sources *config.LogSources, pp pipeline.Provider, a *auditor.Auditor) *Scanner {
 	return &Scanner{
 		pp:      pp,
-		sources: containerSources,
+		sources: sources,
 		tailers: make(map[string]*Tailer),
 		auditor: a,
 		stop:    make(chan struct{}),
 	}
 }
 
-// Start starts the Scanner
+// Start starts the Scanner, it is set up once there is a docker source as
+// they can be added later by autodiscovery
 func (s *Scanner) Start() {
-	err := s.setup()
-	if err != nil {
-		s.reportErrorToAllSources(err)
-		return
-	}
 	go s.run()
 	s.isRunning = true
 }
@@ -87,11 +83,19 @@ func (s *Scanner) Stop() {
 func (s *Scanner) run() {
 	scanTicker := time.NewTicker(scanPeriod)
 	defer scanTicker.Stop()
+	isSetup, setupFailed := s.trySetup()
 	for {
 		select {
 		case <-scanTicker.C:
-			// check all the containers running on the host and start new tailers if needed
-			s.scan(true)
+			switch {
+			case setupFailed:
+				// the containers can't be tailed
+			case !isSetup:
+				isSetup, setupFailed = s.trySetup()
+			default:
+				// check all the containers running on the host and start new tailers if needed
+				s.scan(true)
+			}
 		case <-s.stop:
 			// no docker container should be tailed anymore
 			return
@@ -105,16 +109,23 @@ func (s *Scanner) run() {
 func (s *Scanner) scan(tailFromBeginning bool) {
 	runningContainers := s.listContainers()
 	containersToMonitor := make(map[string]bool)
+	sources := s.sources.GetSourcesByType(config.DockerType)
 
 	// monitor new containers, and restart tailers if needed
 	for _, container := range runningContainers {
-		source := NewContainer(container).findSource(s.sources)
+		if s.isExcluded(container) {
+			continue
+		}
+		source := NewContainer(container).findSource(sources)
 		if source == nil {
 			continue
 		}
 		tailer, isTailed := s.tailers[container.ID]
 		if isTailed && tailer.shouldStop {
-			continue
+			// the logs stream ended while the container is still running,
+			// e.g. it restarted or the daemon closed the connection,
+			// the tailer is kept to try again in the next scan on failure
+			s.restartTailer(tailer)
 		}
 		if !isTailed {
 			// setup a new tailer
@@ -136,10 +147,25 @@ func (s *Scanner) scan(tailFromBeginning bool) {
 	}
 }
 
-// Start starts the Scanner
+// trySetup sets up the Scanner, returns whether it is set up, and whether
+// it failed for good
+func (s *Scanner) trySetup() (bool, bool) {
+	err := s.setup()
+	switch err {
+	case nil:
+		return true, false
+	case errNoContainerSource:
+		return false, false
+	default:
+		s.reportErrorToAllSources(err)
+		return false, true
+	}
+}
+
+// setup initializes the client and starts tailing the containers
 func (s *Scanner) setup() error {
-	if len(s.sources) == 0 {
-		return fmt.Errorf("No container source defined")
+	if len(s.sources.GetSourcesByType(config.DockerType)) == 0 {
+		return errNoContainerSource
 	}
 
 	cli, err := NewClient()
@@ -149,6 +175,13 @@ func (s *Scanner) setup() error {
 	}
 	s.cli = cli
 
+	filter, err := containers.NewFilterFromConfig(containers.LogsFilter)
+	if err != nil {
+		log.Error("Can't tail containers, ", err)
+		return fmt.Errorf("Invalid container filters: %v", err)
+	}
+	s.filter = filter
+
 	// Initialize docker utils
 	err = tagger.Init()
 	if err != nil {
@@ -179,6 +212,21 @@ func (s *Scanner) setupTailer(cli *client.Client, container types.Container, sou
 	return true
 }
 
+// restartTailer sets a new tailer for the container of a tailer whose logs
+// stream ended, resuming after the last message it forwarded
+func (s *Scanner) restartTailer(tailer *Tailer) {
+	log.Info("Resume tailing container ", s.humanReadableContainerID(tailer.ContainerID))
+	t := NewTailer(s.cli, tailer.ContainerID, tailer.source, tailer.outputChan)
+	err := t.tailFrom(tailer.nextLogSince())
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+	// the previous tailer is done, only its reader is left to release
+	tailer.reader.Close()
+	s.tailers[tailer.ContainerID] = t
+}
+
 // dismissTailer stops the tailer and removes it from the list of active tailers
 func (s *Scanner) dismissTailer(tailer *Tailer) {
 	// stop the tailer in another routine as we don't want to block here
@@ -186,6 +234,16 @@ func (s *Scanner) dismissTailer(tailer *Tailer) {
 	delete(s.tailers, tailer.ContainerID)
 }
 
+// isExcluded returns true if the container is excluded from the log collection
+// by the container_include_logs and container_exclude_logs options
+func (s *Scanner) isExcluded(container types.Container) bool {
+	var name string
+	if len(container.Names) > 0 {
+		name = strings.TrimPrefix(container.Names[0], "/")
+	}
+	return s.filter.IsExcluded(name, container.Image)
+}
+
 func (s *Scanner) listContainers() []types.Container {
 	containers, err := s.cli.ContainerList(context.Background(), types.ContainerListOptions{})
 	if err != nil {
@@ -199,7 +257,7 @@ func (s *Scanner) listContainers() []types.Container {
 
 // reportErrorToAllSources changes the status of all sources to Error with err
 func (s *Scanner) reportErrorToAllSources(err error) {
-	for _, source := range s.sources {
+	for _, source := range s.sources.GetSourcesByType(config.DockerType) {
 		source.Status.Error(err)
 	}
 }
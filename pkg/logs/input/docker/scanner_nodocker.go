@@ -17,7 +17,7 @@ import (
 type Scanner struct{}
 
 // NewScanner returns a new Scanner
-func NewScanner(sources []*config.LogSource, pp pipeline.Provider, auditor *auditor.Auditor) *Scanner {
+func NewScanner(sources *config.LogSources, pp pipeline.Provider, auditor *auditor.Auditor) *Scanner {
 	return &Scanner{}
 }
 
@@ -11,10 +11,9 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"reflect"
+	"sync"
 	"time"
 
-	"github.com/DataDog/datadog-agent/pkg/tagger"
 	dockerutil "github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -23,25 +22,32 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/decoder"
 	parser "github.com/DataDog/datadog-agent/pkg/logs/docker"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 )
 
 const defaultSleepDuration = 1 * time.Second
-const tagsUpdatePeriod = 10 * time.Second
 
 // Tailer tails logs coming from stdout and stderr of a docker container
-// With docker api, there is no way to know if a log comes from strout or stderr
-// so if we want to capture the severity, we need to tail both in two goroutines
+// through the docker API, demultiplexing the two streams to capture the
+// severity of the messages
 type Tailer struct {
-	ContainerID   string
-	outputChan    chan message.Message
-	decoder       *decoder.Decoder
-	reader        io.ReadCloser
-	cli           *client.Client
-	source        *config.LogSource
-	containerTags []string
+	ContainerID string
+	outputChan  chan message.Message
+	decoder     *decoder.Decoder
+	reader      io.ReadCloser
+	stream      io.Reader
+	cli         *client.Client
+	source      *config.LogSource
+	tagProvider *tag.Provider
+
+	// since is the date the tailing started from, lastTimestamp the date
+	// of the last message forwarded, set by forwardMessages
+	since             string
+	lastTimestamp     string
+	lastTimestampLock sync.Mutex
 
 	sleepDuration time.Duration
 	shouldStop    bool
@@ -57,6 +63,7 @@ func NewTailer(cli *client.Client, containerID string, source *config.LogSource,
 		decoder:     decoder.InitializeDecoder(source),
 		source:      source,
 		cli:         cli,
+		tagProvider: tag.NewProvider(dockerutil.ContainerIDToEntityName(containerID)),
 
 		sleepDuration: defaultSleepDuration,
 		stop:          make(chan struct{}, 1),
@@ -114,9 +121,37 @@ func (t *Tailer) nextLogSinceDate(lastTs string) string {
 	return ts.Format(config.DateFormat)
 }
 
+// nextLogSince returns the date to resume the tailing from when the logs
+// stream ended, right after the last message forwarded
+func (t *Tailer) nextLogSince() string {
+	lastTimestamp := t.getLastTimestamp()
+	if lastTimestamp == "" {
+		return t.since
+	}
+	return t.nextLogSinceDate(lastTimestamp)
+}
+
+func (t *Tailer) getLastTimestamp() string {
+	t.lastTimestampLock.Lock()
+	defer t.lastTimestampLock.Unlock()
+	return t.lastTimestamp
+}
+
+func (t *Tailer) setLastTimestamp(timestamp string) {
+	t.lastTimestampLock.Lock()
+	defer t.lastTimestampLock.Unlock()
+	t.lastTimestamp = timestamp
+}
+
 // setupReader sets up the reader that reads the container's logs
-// with the proper configuration
-func (t *Tailer) setupReader(from string) (io.ReadCloser, error) {
+// with the proper configuration. The stdout and stderr streams of
+// the containers started without tty are multiplexed, the stream
+// reader demultiplexes them.
+func (t *Tailer) setupReader(from string) error {
+	container, err := t.cli.ContainerInspect(context.Background(), t.ContainerID)
+	if err != nil {
+		return err
+	}
 	options := types.ContainerLogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
@@ -125,12 +160,22 @@ func (t *Tailer) setupReader(from string) (io.ReadCloser, error) {
 		Details:    false,
 		Since:      from,
 	}
-	return t.cli.ContainerLogs(context.Background(), t.ContainerID, options)
+	reader, err := t.cli.ContainerLogs(context.Background(), t.ContainerID, options)
+	if err != nil {
+		return err
+	}
+	t.reader = reader
+	if container.Config != nil && container.Config.Tty {
+		t.stream = reader
+	} else {
+		t.stream = parser.NewStreamReader(reader)
+	}
+	return nil
 }
 
 // tailFrom sets up and starts the tailer
 func (t *Tailer) tailFrom(from string) error {
-	reader, err := t.setupReader(from)
+	err := t.setupReader(from)
 	if err != nil {
 		// could not start the tailer
 		t.source.Status.Error(err)
@@ -138,10 +183,8 @@ func (t *Tailer) tailFrom(from string) error {
 	}
 	t.source.Status.Success()
 	t.source.AddInput(t.ContainerID)
+	t.since = from
 
-	t.reader = reader
-
-	go t.keepDockerTagsUpdated()
 	go t.forwardMessages()
 	t.decoder.Start()
 	go t.readForever()
@@ -160,7 +203,7 @@ func (t *Tailer) readForever() {
 			return
 		default:
 			inBuf := make([]byte, 4096)
-			n, err := t.reader.Read(inBuf)
+			n, err := t.stream.Read(inBuf)
 			if err != nil {
 				// an error occurred, stop from reading new logs
 				if err != io.EOF {
@@ -197,38 +240,17 @@ func (t *Tailer) forwardMessages() {
 			log.Warn(err)
 			continue
 		}
+		t.setLastTimestamp(dockerMsg.Timestamp)
 		if len(dockerMsg.Content) > 0 {
 			origin := message.NewOrigin(t.source)
 			origin.Offset = dockerMsg.Timestamp
 			origin.Identifier = t.Identifier()
-			origin.SetTags(t.containerTags)
+			origin.SetTags(t.tagProvider.GetTags())
 			t.outputChan <- message.New(dockerMsg.Content, origin, dockerMsg.Status)
 		}
 	}
 }
 
-func (t *Tailer) keepDockerTagsUpdated() {
-	t.checkForNewDockerTags()
-	ticker := time.NewTicker(tagsUpdatePeriod)
-	for range ticker.C {
-		if t.shouldStop {
-			return
-		}
-		t.checkForNewDockerTags()
-	}
-}
-
-func (t *Tailer) checkForNewDockerTags() {
-	tags, err := tagger.Tag(dockerutil.ContainerIDToEntityName(t.ContainerID), true)
-	if err != nil {
-		log.Warn(err)
-	} else {
-		if !reflect.DeepEqual(tags, t.containerTags) {
-			t.containerTags = tags
-		}
-	}
-}
-
 // wait lets the reader sleep for a bit
 func (t *Tailer) wait() {
 	time.Sleep(t.sleepDuration)
@@ -24,3 +24,10 @@ func TestTailerIdentifier(t *testing.T) {
 	tailer := &Tailer{ContainerID: "test"}
 	assert.Equal(t, "docker:test", tailer.Identifier())
 }
+
+func TestTailerNextLogSince(t *testing.T) {
+	tailer := &Tailer{since: "2008-01-12T01:01:01.000000000Z"}
+	assert.Equal(t, "2008-01-12T01:01:01.000000000Z", tailer.nextLogSince())
+	tailer.setLastTimestamp("2008-01-12T01:02:01.000000000Z")
+	assert.Equal(t, "2008-01-12T01:02:01.000000001Z", tailer.nextLogSince())
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -33,6 +34,7 @@ func NewFile(path string, source *config.LogSource) *File {
 // Provider implements the logic to retrieve at most filesLimit Files defined in sources
 type Provider struct {
 	sources         []*config.LogSource
+	sourcesMutex    sync.Mutex
 	filesLimit      int
 	shouldLogErrors bool
 }
@@ -46,6 +48,32 @@ func NewProvider(sources []*config.LogSource, filesLimit int) *Provider {
 	}
 }
 
+// addSource adds a source whose files are returned from the next call to FilesToTail
+func (p *Provider) addSource(source *config.LogSource) {
+	p.sourcesMutex.Lock()
+	defer p.sourcesMutex.Unlock()
+	p.sources = append(p.sources, source)
+}
+
+// removeSource removes a source, its files are not returned anymore
+func (p *Provider) removeSource(source *config.LogSource) {
+	p.sourcesMutex.Lock()
+	defer p.sourcesMutex.Unlock()
+	for i, src := range p.sources {
+		if src == source {
+			p.sources = append(p.sources[:i:i], p.sources[i+1:]...)
+			return
+		}
+	}
+}
+
+// getSources returns the sources currently held
+func (p *Provider) getSources() []*config.LogSource {
+	p.sourcesMutex.Lock()
+	defer p.sourcesMutex.Unlock()
+	return p.sources
+}
+
 // FilesToTail returns all the Files matching paths in sources,
 // it cannot return more than filesLimit Files.
 // For now, there is no way to prioritize specific Files over others,
@@ -55,8 +83,9 @@ func (p *Provider) FilesToTail() []*File {
 	shouldLogErrors := p.shouldLogErrors
 	p.shouldLogErrors = false // Let's log errors on first run only
 
-	for i := 0; i < len(p.sources) && len(filesToTail) < p.filesLimit; i++ {
-		source := p.sources[i]
+	sources := p.getSources()
+	for i := 0; i < len(sources) && len(filesToTail) < p.filesLimit; i++ {
+		source := sources[i]
 		sourcePath := source.Config.Path
 		if p.exists(sourcePath) {
 			// no need to traverse the file system here as we found a file
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A tailer commits its position in its file as <offset>:<inode>, so that the
+// offset is not applied to another file when the file was rotated while the
+// agent was stopped. The inode is omitted when it's unknown, as on windows
+// and in the positions committed by the previous versions of the agent.
+//
+// The positions are committed under the inode of the file when it's known, so
+// that the position of a file renamed by a rotation is found under its new
+// path, and under the path of the file otherwise.
+
+// pathIdentifier returns the identifier of the file at path
+func pathIdentifier(path string) string {
+	return fmt.Sprintf("file:%s", path)
+}
+
+// inodeIdentifier returns the identifier of the position of the file identified by inode
+func inodeIdentifier(inode uint64) string {
+	return fmt.Sprintf("file-inode:%d", inode)
+}
+
+// formatPosition returns the position of offset in the file identified by inode
+func formatPosition(offset int64, inode uint64) string {
+	if inode == 0 {
+		return strconv.FormatInt(offset, 10)
+	}
+	return fmt.Sprintf("%d:%d", offset, inode)
+}
+
+// parsePosition returns the offset and the inode of a position
+func parsePosition(position string) (int64, uint64, error) {
+	var inode uint64
+	var err error
+	parts := strings.SplitN(position, ":", 2)
+	if len(parts) == 2 {
+		inode, err = strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	offset, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, inode, nil
+}
+
+// resumeOffset returns the offset to resume tailing the file at path from, and
+// whether a position was committed for it, getPosition returning the position
+// committed under an identifier. The position is looked up under the inode of
+// the file first, then under its path. The offset is 0 when the file was
+// rotated or truncated since the position was committed.
+func resumeOffset(path string, getPosition func(identifier string) string) (int64, bool) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	fileInode := inode(stat)
+	position := ""
+	if fileInode != 0 {
+		position = getPosition(inodeIdentifier(fileInode))
+	}
+	if position == "" {
+		// the positions committed by the previous versions of the agent, and
+		// on windows, are committed under the path of the file
+		position = getPosition(pathIdentifier(path))
+	}
+	if position == "" {
+		return 0, false
+	}
+	offset, committedInode, err := parsePosition(position)
+	if err != nil || offset <= 0 {
+		return 0, false
+	}
+	if committedInode != 0 && committedInode != fileInode {
+		// the file at path was replaced, its whole content is new
+		return 0, true
+	}
+	if stat.Size() < offset {
+		// the file was truncated
+		return 0, true
+	}
+	return offset, true
+}
@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package file
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePosition(t *testing.T) {
+	offset, inode, err := parsePosition(formatPosition(42, 1234))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, uint64(1234), inode)
+
+	// the positions committed by the previous versions only hold the offset
+	offset, inode, err = parsePosition("42")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, uint64(0), inode)
+	assert.Equal(t, "42", formatPosition(42, 0))
+
+	_, _, err = parsePosition("42:foo")
+	assert.Error(t, err)
+	_, _, err = parsePosition("2018-06-14T18:27:03Z")
+	assert.Error(t, err)
+}
+
+func TestResumeOffset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-position-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "file.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("hello world\n"), 0644))
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	fileInode := inode(stat)
+
+	positions := make(map[string]string)
+	getPosition := func(identifier string) string {
+		return positions[identifier]
+	}
+
+	offset, hasPosition := resumeOffset(path, getPosition)
+	assert.Equal(t, int64(0), offset)
+	assert.False(t, hasPosition)
+
+	positions[inodeIdentifier(fileInode)] = formatPosition(6, fileInode)
+	offset, hasPosition = resumeOffset(path, getPosition)
+	assert.Equal(t, int64(6), offset)
+	assert.True(t, hasPosition)
+
+	// the file was truncated
+	positions[inodeIdentifier(fileInode)] = formatPosition(42, fileInode)
+	offset, hasPosition = resumeOffset(path, getPosition)
+	assert.Equal(t, int64(0), offset)
+	assert.True(t, hasPosition)
+
+	// the positions of the previous versions are committed under the path
+	delete(positions, inodeIdentifier(fileInode))
+	positions[pathIdentifier(path)] = "6"
+	offset, hasPosition = resumeOffset(path, getPosition)
+	assert.Equal(t, int64(6), offset)
+	assert.True(t, hasPosition)
+
+	// the file was replaced
+	positions[pathIdentifier(path)] = fmt.Sprintf("6:%d", fileInode+1)
+	offset, hasPosition = resumeOffset(path, getPosition)
+	assert.Equal(t, int64(0), offset)
+	assert.True(t, hasPosition)
+}
+
+func TestResumeOffsetOfRenamedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-position-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "file.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("hello world\n"), 0644))
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	fileInode := inode(stat)
+
+	positions := map[string]string{
+		pathIdentifier(path):       formatPosition(6, fileInode),
+		inodeIdentifier(fileInode): formatPosition(6, fileInode),
+	}
+	getPosition := func(identifier string) string {
+		return positions[identifier]
+	}
+
+	// the file is rotated by renaming it, a new file is created at its path
+	renamedPath := filepath.Join(dir, "file.log.1")
+	require.NoError(t, os.Rename(path, renamedPath))
+	require.NoError(t, ioutil.WriteFile(path, []byte("hello again\n"), 0644))
+
+	// the renamed file resumes from its position
+	offset, hasPosition := resumeOffset(renamedPath, getPosition)
+	assert.Equal(t, int64(6), offset)
+	assert.True(t, hasPosition)
+
+	// the new file is read from the beginning
+	offset, hasPosition = resumeOffset(path, getPosition)
+	assert.Equal(t, int64(0), offset)
+	assert.True(t, hasPosition)
+}
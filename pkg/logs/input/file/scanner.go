@@ -6,7 +6,6 @@
 package file
 
 import (
-	"strconv"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -30,6 +29,7 @@ type Scanner struct {
 	tailers             map[string]*Tailer
 	auditor             *auditor.Auditor
 	tailerSleepDuration time.Duration
+	rotationChan        chan struct{}
 	stop                chan struct{}
 }
 
@@ -50,6 +50,7 @@ func New(sources []*config.LogSource, tailingLimit int, pp pipeline.Provider, au
 		tailers:             make(map[string]*Tailer),
 		auditor:             auditor,
 		tailerSleepDuration: tailerSleepDuration,
+		rotationChan:        make(chan struct{}, 1),
 		stop:                make(chan struct{}),
 	}
 }
@@ -84,21 +85,21 @@ func (s *Scanner) cleanup() {
 
 // createTailer returns a new initialized tailer
 func (s *Scanner) createTailer(file *File, outputChan chan message.Message) *Tailer {
-	return NewTailer(outputChan, file.Source, file.Path, s.tailerSleepDuration)
+	tailer := NewTailer(outputChan, file.Source, file.Path, s.tailerSleepDuration)
+	tailer.rotationChan = s.rotationChan
+	return tailer
 }
 
 // startNewTailer creates a new tailer, making it tail from the last committed offset, the beginning or the end of the file,
+// the file is tailed from the beginning when it was rotated or truncated since the offset was committed,
 // returns true if the operation succeeded, false otherwise
 func (s *Scanner) startNewTailer(file *File, tailFromBeginning bool) bool {
 	tailer := s.createTailer(file, s.pp.NextPipelineChan())
-	offset := s.auditor.GetLastCommittedOffset(tailer.Identifier())
-	value, err := strconv.ParseInt(offset, 10, 64)
-	if err != nil {
-		value = 0
-	}
-	if value > 0 {
-		err = tailer.recoverTailing(value)
-	} else if tailFromBeginning {
+	offset, hasPosition := resumeOffset(file.Path, s.auditor.GetLastCommittedOffset)
+	var err error
+	if offset > 0 {
+		err = tailer.recoverTailing(offset)
+	} else if hasPosition || tailFromBeginning {
 		err = tailer.tailFromBeginning()
 	} else {
 		err = tailer.tailFromEnd()
@@ -111,6 +112,18 @@ func (s *Scanner) startNewTailer(file *File, tailFromBeginning bool) bool {
 	return true
 }
 
+// AddSource adds a source whose files are tailed from the next scan, as the
+// sources of the kubernetes containers discovered at runtime
+func (s *Scanner) AddSource(source *config.LogSource) {
+	s.fileProvider.addSource(source)
+}
+
+// RemoveSource removes a source, the tailers of its files are stopped at the
+// next scan
+func (s *Scanner) RemoveSource(source *config.LogSource) {
+	s.fileProvider.removeSource(source)
+}
+
 // Start starts the Scanner
 func (s *Scanner) Start() {
 	s.setup()
@@ -133,6 +146,10 @@ func (s *Scanner) run() {
 		case <-scanTicker.C:
 			// check if there are new files to tail, tailers to stop and tailer to restart because of file rotation
 			s.scan()
+		case <-s.rotationChan:
+			// a tailer read the end of its file after it was rotated, start tailing the new file
+			// right away to not miss the files rotated several times within a scan period
+			s.scan()
 		case <-s.stop:
 			// no more file should be tailed
 			return
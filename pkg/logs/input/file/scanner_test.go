@@ -132,6 +132,24 @@ func (suite *ScannerTestSuite) TestScannerScanWithLogRotation() {
 	suite.Equal("hello again", string(msg.Content()))
 }
 
+func (suite *ScannerTestSuite) TestScannerNotifiedOfLogRotation() {
+	s := suite.s
+	tailer := s.tailers[suite.sources[0].Config.Path]
+
+	os.Rename(suite.testPath, suite.testRotatedPath)
+	_, err := os.Create(suite.testPath)
+	suite.Nil(err)
+
+	// the tailer notifies the rotation when it reads the end of the rotated file
+	select {
+	case <-s.rotationChan:
+	case <-time.After(time.Second):
+		suite.Fail("the rotation was not notified")
+	}
+	s.scan()
+	suite.True(tailer != s.tailers[suite.sources[0].Config.Path])
+}
+
 func (suite *ScannerTestSuite) TestScannerScanWithLogRotationCopyTruncate() {
 	s := suite.s
 	sources := suite.sources
@@ -153,9 +171,10 @@ func (suite *ScannerTestSuite) TestScannerScanWithLogRotationCopyTruncate() {
 	_, err = suite.testFile.WriteString("third\n")
 	suite.Nil(err)
 
+	// the tailer reads the truncated file again from the beginning
 	s.scan()
 	newTailer = s.tailers[sources[0].Config.Path]
-	suite.True(tailer != newTailer)
+	suite.True(tailer == newTailer)
 
 	msg = <-suite.outputChan
 	suite.Equal("third", string(msg.Content()))
@@ -236,6 +255,34 @@ func TestScannerScanStartNewTailer(t *testing.T) {
 	assert.Equal(t, "world", string(msg.Content()))
 }
 
+func TestScannerAddRemoveSource(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := fmt.Sprintf("%s/0.log", testDir)
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	_, err = file.WriteString("hello\n")
+	assert.Nil(t, err)
+
+	scanner := New(nil, 2, mock.NewMockProvider(), auditor.New(nil, ""), 20*time.Millisecond)
+	scanner.setup()
+	assert.Equal(t, 0, len(scanner.tailers))
+
+	// the files of the sources added at runtime are tailed from the next scan
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/*.log", testDir)})
+	scanner.AddSource(source)
+	scanner.scan()
+	assert.Equal(t, 1, len(scanner.tailers))
+	msg := <-scanner.tailers[path].outputChan
+	assert.Equal(t, "hello", string(msg.Content()))
+
+	scanner.RemoveSource(source)
+	scanner.scan()
+	assert.Equal(t, 0, len(scanner.tailers))
+}
+
 func TestScannerScanWithTooManyFiles(t *testing.T) {
 	var err error
 	var path string
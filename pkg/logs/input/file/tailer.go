@@ -6,9 +6,7 @@
 package file
 
 import (
-	"fmt"
 	"os"
-	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -18,7 +16,9 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
 )
 
 // DefaultSleepDuration represents the amount of time the tailer waits before reading new data when no data is received
@@ -26,16 +26,26 @@ const DefaultSleepDuration = 1 * time.Second
 
 const defaultCloseTimeout = 60 * time.Second
 
+// maxPartialContentLen represents the length above which the chunks of a
+// kubernetes log line are sent without waiting for the end of the line
+const maxPartialContentLen = 256 * 1000
+
 // Tailer tails one file and sends messages to an output channel
 type Tailer struct {
 	path     string
 	fullpath string
 	file     *os.File
+	inode    uint64
 	tags     []string
 
 	readOffset    int64
 	decodedOffset int64
 
+	// content of the partial kubernetes log line being reassembled
+	partialContent []byte
+	// tagProvider returns the tags of the kubernetes container writing the file
+	tagProvider *tag.Provider
+
 	outputChan chan message.Message
 	decoder    *decoder.Decoder
 	source     *config.LogSource
@@ -45,17 +55,25 @@ type Tailer struct {
 	closeTimeout  time.Duration
 	shouldStop    bool
 	didFileRotate bool
-	stop          chan struct{}
-	done          chan struct{}
+	// rotationChan is notified when the tailer reads the end of its file after
+	// it was rotated, so that a new tailer starts without waiting for the next scan
+	rotationChan chan struct{}
+	stop         chan struct{}
+	done         chan struct{}
 }
 
 // NewTailer returns an initialized Tailer
 func NewTailer(outputChan chan message.Message, source *config.LogSource, path string, sleepDuration time.Duration) *Tailer {
+	var tagProvider *tag.Provider
+	if source.Config.Type == config.KubernetesType && source.Config.Identifier != "" {
+		tagProvider = tag.NewProvider(source.Config.Identifier)
+	}
 	return &Tailer{
 		path:          path,
 		outputChan:    outputChan,
 		decoder:       decoder.InitializeDecoder(source),
 		source:        source,
+		tagProvider:   tagProvider,
 		readOffset:    0,
 		sleepDuration: sleepDuration,
 		closeTimeout:  defaultCloseTimeout,
@@ -66,7 +84,17 @@ func NewTailer(outputChan chan message.Message, source *config.LogSource, path s
 
 // Identifier returns a string that uniquely identifies a source
 func (t *Tailer) Identifier() string {
-	return fmt.Sprintf("file:%s", t.path)
+	return pathIdentifier(t.path)
+}
+
+// positionIdentifier returns the identifier the position of the tailer is
+// committed under, the inode of its file when it's known so that the position
+// follows the file when it's renamed, its path otherwise
+func (t *Tailer) positionIdentifier() string {
+	if t.inode == 0 {
+		return t.Identifier()
+	}
+	return inodeIdentifier(t.inode)
 }
 
 // tailFromBeginning lets the tailer start tailing its file
@@ -142,19 +170,69 @@ func (t *Tailer) forwardMessages() {
 		t.done <- struct{}{}
 	}()
 	for output := range t.decoder.OutputChan {
-		offset := t.decodedOffset + int64(output.RawDataLen)
-		identifier := t.Identifier()
+		if output.TruncationMarker {
+			// the next outputs are read from the beginning of the file
+			t.SetDecodedOffset(0)
+			t.partialContent = nil
+			continue
+		}
+		offset := t.GetDecodedOffset() + int64(output.RawDataLen)
+		identifier := t.positionIdentifier()
 		if !t.shouldTrackOffset() {
 			offset = 0
 			identifier = ""
 		}
-		t.decodedOffset = offset
+		t.SetDecodedOffset(offset)
+		content, status := output.Content, ""
+		if t.source.Config.Type == config.KubernetesType {
+			var isComplete bool
+			content, status, isComplete = t.parseKubernetesLine(output.Content)
+			if !isComplete {
+				// the offset is only committed with the end of the line
+				continue
+			}
+		}
+		if len(content) == 0 {
+			continue
+		}
 		origin := message.NewOrigin(t.source)
 		origin.Identifier = identifier
-		origin.Offset = strconv.FormatInt(offset, 10)
-		origin.SetTags(t.tags)
-		t.outputChan <- message.New(output.Content, origin, "")
+		origin.Offset = formatPosition(offset, t.inode)
+		origin.SetTags(t.getTags())
+		t.outputChan <- message.New(content, origin, status)
+	}
+}
+
+// parseKubernetesLine extracts the content and the status of a line written
+// by a container runtime in the CRI log format, reassembling the lines it split.
+// It returns false while the end of the line has not been read.
+func (t *Tailer) parseKubernetesLine(line []byte) ([]byte, string, bool) {
+	msg, err := kubernetes.ParseMessage(line)
+	if err != nil {
+		log.Debug(err)
+		return line, "", true
+	}
+	if msg.IsPartial && len(t.partialContent)+len(msg.Content) < maxPartialContentLen {
+		t.partialContent = append(t.partialContent, msg.Content...)
+		return nil, "", false
+	}
+	content := msg.Content
+	if len(t.partialContent) > 0 {
+		content = append(t.partialContent, msg.Content...)
+		t.partialContent = nil
+	}
+	return content, msg.Status, true
+}
+
+// getTags returns the tags of the file, and the ones of its container for kubernetes
+func (t *Tailer) getTags() []string {
+	if t.tagProvider == nil {
+		return t.tags
 	}
+	containerTags := t.tagProvider.GetTags()
+	tags := make([]string, 0, len(t.tags)+len(containerTags))
+	tags = append(tags, t.tags...)
+	return append(tags, containerTags...)
 }
 
 func (t *Tailer) incrementReadOffset(n int) {
@@ -184,9 +262,11 @@ func (t *Tailer) SetDecodedOffset(off int64) {
 	atomic.StoreInt64(&t.decodedOffset, off)
 }
 
-// shouldTrackOffset returns whether the tailer should track the file offset or not
+// shouldTrackOffset returns whether the tailer should track the file offset or not,
+// a rotated file keeps its position when it's committed under its inode as the
+// position of the new file is committed under another one
 func (t *Tailer) shouldTrackOffset() bool {
-	if t.didFileRotate {
+	if t.didFileRotate && t.inode == 0 {
 		return false
 	}
 	return true
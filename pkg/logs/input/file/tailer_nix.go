@@ -34,9 +34,12 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	}
 
 	t.file = f
+	if stat, err := f.Stat(); err == nil {
+		t.inode = inode(stat)
+	}
 	ret, _ := f.Seek(offset, whence)
-	t.readOffset = ret
-	t.decodedOffset = ret
+	t.SetReadOffset(ret)
+	t.SetDecodedOffset(ret)
 
 	return nil
 }
@@ -61,6 +64,8 @@ func (t *Tailer) readForever() {
 				return
 			}
 			if n == 0 {
+				t.checkForTruncation()
+				t.notifyRotation()
 				// wait for new data to come
 				t.wait()
 				continue
@@ -71,16 +76,20 @@ func (t *Tailer) readForever() {
 	}
 }
 
+// checkForRotation returns true when the path of the tailer leads to another
+// file than the one it's reading, the truncations are handled while reading
 func (t *Tailer) checkForRotation() (bool, error) {
-	f, err := os.Open(t.path)
+	didRotate, err := t.didRotate()
 	if err != nil {
 		t.source.Status.Error(err)
-		return false, err
 	}
+	return didRotate, err
+}
 
-	stat1, err := f.Stat()
+// didRotate returns true when the path of the tailer leads to another file
+func (t *Tailer) didRotate() (bool, error) {
+	stat1, err := os.Stat(t.path)
 	if err != nil {
-		t.source.Status.Error(err)
 		return false, err
 	}
 
@@ -89,7 +98,41 @@ func (t *Tailer) checkForRotation() (bool, error) {
 		return true, nil
 	}
 
-	return inode(stat1) != inode(stat2) || stat1.Size() < t.GetReadOffset(), nil
+	return inode(stat1) != inode(stat2), nil
+}
+
+// checkForTruncation reads the file again from the beginning when it's
+// smaller than the offset read, as after a copytruncate rotation. The decoded
+// offset is reset by forwardMessages when it receives the truncation marker,
+// after the lines read before the truncation.
+func (t *Tailer) checkForTruncation() {
+	stat, err := t.file.Stat()
+	if err != nil || stat.Size() >= t.GetReadOffset() {
+		return
+	}
+	log.Info("File truncated, reading it from the beginning: ", t.path)
+	if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+		log.Warn(err)
+		return
+	}
+	t.SetReadOffset(0)
+	t.decoder.InputChan <- decoder.NewTruncationMarker()
+}
+
+// notifyRotation notifies rotationChan when the file was rotated, the tailer
+// keeps reading it until it's stopped
+func (t *Tailer) notifyRotation() {
+	if t.rotationChan == nil || t.didFileRotate {
+		return
+	}
+	if didRotate, err := t.didRotate(); err != nil || !didRotate {
+		return
+	}
+	select {
+	case t.rotationChan <- struct{}{}:
+	default:
+		// a scan is already pending
+	}
 }
 
 // inode uniquely identifies a file on a filesystem
@@ -9,9 +9,9 @@ package file
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"strconv"
 	"testing"
 	"time"
 
@@ -153,9 +153,55 @@ func (suite *TailerTestSuite) TestRecoverTailing() {
 	suite.Equal(len(lines[0])+len(lines[1])+len(lines[2]), int(suite.tl.GetDecodedOffset()))
 }
 
+func (suite *TailerTestSuite) TestTruncationWhileLinesInFlight() {
+	var content string
+	for i := 0; i < 2*chanSize; i++ {
+		content += fmt.Sprintf("line %02d\n", i)
+	}
+	_, err := suite.testFile.WriteString(content)
+	suite.Nil(err)
+
+	suite.tl.tailFromBeginning()
+
+	// the lines are read but can't all be forwarded, the output channel is full
+	for suite.tl.GetReadOffset() < int64(len(content)) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the file is truncated, as by a copytruncate rotation
+	suite.Nil(suite.testFile.Truncate(0))
+	_, err = suite.testFile.Seek(0, io.SeekStart)
+	suite.Nil(err)
+	newLine := "after truncation\n"
+	_, err = suite.testFile.WriteString(newLine)
+	suite.Nil(err)
+
+	// wait for the truncation to be detected while the lines are still in flight
+	for suite.tl.GetReadOffset() == int64(len(content)) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the lines read before the truncation keep their offsets
+	for i := 0; i < 2*chanSize; i++ {
+		msg := <-suite.outputChan
+		suite.Equal(fmt.Sprintf("line %02d", i), string(msg.Content()))
+		suite.Equal((i+1)*len("line 00\n"), toInt(msg.GetOrigin().Offset))
+	}
+
+	msg := <-suite.outputChan
+	suite.Equal("after truncation", string(msg.Content()))
+	suite.Equal(len(newLine), toInt(msg.GetOrigin().Offset))
+	suite.Equal(len(newLine), int(suite.tl.GetDecodedOffset()))
+}
+
 func (suite *TailerTestSuite) TestTailerIdentifier() {
 	suite.tl.tailFromBeginning()
 	suite.Equal(fmt.Sprintf("file:%s/tailer.log", suite.testDir), suite.tl.Identifier())
+
+	// the position is committed under the inode of the file
+	stat, err := os.Stat(suite.testPath)
+	suite.Nil(err)
+	suite.Equal(fmt.Sprintf("file-inode:%d", inode(stat)), suite.tl.positionIdentifier())
 }
 
 func (suite *TailerTestSuite) TestOriginTagsWhenTailingFiles() {
@@ -172,13 +218,44 @@ func (suite *TailerTestSuite) TestOriginTagsWhenTailingFiles() {
 
 }
 
+func (suite *TailerTestSuite) TestTailKubernetesLogs() {
+	suite.source.Config.Type = config.KubernetesType
+	suite.tl = NewTailer(suite.outputChan, suite.source, suite.testPath, 10*time.Millisecond)
+	lines := []string{
+		"2018-09-20T11:54:11.753589172Z stdout F hello world\n",
+		"2018-09-20T11:54:12.753589172Z stderr P hello\n",
+		"2018-09-20T11:54:12.753589172Z stderr F  again\n",
+		"not a cri line\n",
+	}
+
+	suite.tl.tailFromBeginning()
+	for _, line := range lines {
+		_, err := suite.testFile.WriteString(line)
+		suite.Nil(err)
+	}
+
+	msg := <-suite.outputChan
+	suite.Equal("hello world", string(msg.Content()))
+	suite.Equal(message.StatusInfo, msg.GetStatus())
+	suite.Equal(len(lines[0]), toInt(msg.GetOrigin().Offset))
+
+	// the partial lines are joined, the offset is the one of the end of the line
+	msg = <-suite.outputChan
+	suite.Equal("hello again", string(msg.Content()))
+	suite.Equal(message.StatusError, msg.GetStatus())
+	suite.Equal(len(lines[0])+len(lines[1])+len(lines[2]), toInt(msg.GetOrigin().Offset))
+
+	msg = <-suite.outputChan
+	suite.Equal("not a cri line", string(msg.Content()))
+}
+
 func TestTailerTestSuite(t *testing.T) {
 	suite.Run(t, new(TailerTestSuite))
 }
 
-func toInt(str string) int {
-	if value, err := strconv.ParseInt(str, 10, 64); err == nil {
-		return int(value)
+func toInt(position string) int {
+	if offset, _, err := parsePosition(position); err == nil {
+		return int(offset)
 	}
 	return 0
 }
@@ -26,8 +26,8 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	}
 	t.tags = []string{fmt.Sprintf("filename:%s", filepath.Base(t.path))}
 	t.fullpath = path
-	t.readOffset = offset
-	t.decodedOffset = offset
+	t.SetReadOffset(offset)
+	t.SetDecodedOffset(offset)
 	log.Info("Opening ", t.fullpath)
 	return nil
 }
@@ -44,14 +44,14 @@ func (t *Tailer) readAvailable() (err error) {
 	if err == nil {
 		sz := st.Size()
 		log.Debugf("Size is %d, offset is %d", sz, t.GetReadOffset())
-		if sz == 0 {
+		if sz == 0 && t.GetReadOffset() > 0 {
 			log.Debug("File size now zero, resetting offset")
 			t.SetReadOffset(0)
-			t.SetDecodedOffset(0)
+			t.decoder.InputChan <- decoder.NewTruncationMarker()
 		} else if sz < t.GetReadOffset() {
 			log.Debug("Offset off end of file, resetting")
 			t.SetReadOffset(0)
-			t.SetDecodedOffset(0)
+			t.decoder.InputChan <- decoder.NewTruncationMarker()
 		}
 	} else {
 		log.Debugf("Error stat()ing file %v", err)
@@ -101,3 +101,8 @@ func (t *Tailer) readForever() {
 func (t *Tailer) checkForRotation() (bool, error) {
 	return false, nil
 }
+
+// inode is not available on windows, the files are identified by their path
+func inode(f os.FileInfo) uint64 {
+	return 0
+}
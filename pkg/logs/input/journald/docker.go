@@ -13,6 +13,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/tagger"
 	dockerutil "github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
 )
 
 // containerIDKey represents the key of the container identifier in a journal entry.
@@ -32,7 +34,7 @@ func (t *Tailer) getContainerID(entry *sdjournal.JournalEntry) string {
 
 // getContainerTags returns all the tags of a given container.
 func (t *Tailer) getContainerTags(containerID string) []string {
-	tags, err := tagger.Tag(dockerutil.ContainerIDToEntityName(containerID), true)
+	tags, err := tagger.Tag(dockerutil.ContainerIDToEntityName(containerID), t.highCardTags)
 	if err != nil {
 		log.Warn(err)
 	}
@@ -45,4 +47,5 @@ func (t *Tailer) initializeTagger() {
 	if err != nil {
 		log.Warn(err)
 	}
+	t.highCardTags = tag.IsHighCardinality()
 }
@@ -29,8 +29,10 @@ type Tailer struct {
 	outputChan chan message.Message
 	journal    *sdjournal.Journal
 	blacklist  map[string]bool
-	stop       chan struct{}
-	done       chan struct{}
+	// highCardTags is set when the high cardinality tags of the containers are collected
+	highCardTags bool
+	stop         chan struct{}
+	done         chan struct{}
 }
 
 // setup configures the tailer
@@ -71,18 +73,29 @@ func (t *Tailer) setup() error {
 
 // seek seeks to the cursor if it is not empty or the end of the journal,
 // returns an error if the operation failed.
+// A cursor that can't be restored, e.g. because the journal was rotated
+// or vacuumed since it was committed, is dropped to tail the end of the
+// journal instead of failing.
 func (t *Tailer) seek(cursor string) error {
 	if cursor != "" {
-		err := t.journal.SeekCursor(cursor)
-		if err != nil {
-			return err
+		err := t.seekCursor(cursor)
+		if err == nil {
+			return nil
 		}
-		// must skip one entry since the cursor points to the last committed one.
-		_, err = t.journal.NextSkip(1)
+		log.Warnf("Could not restore the cursor of journal %s, tailing from its end: %s", t.journalPath(), err)
+	}
+	return t.journal.SeekTail()
+}
+
+// seekCursor seeks to the entry following the one the cursor points to.
+func (t *Tailer) seekCursor(cursor string) error {
+	err := t.journal.SeekCursor(cursor)
+	if err != nil {
 		return err
-	} else {
-		return t.journal.SeekTail()
 	}
+	// must skip one entry since the cursor points to the last committed one.
+	_, err = t.journal.NextSkip(1)
+	return err
 }
 
 // tail tails the journal until a message stop is received.
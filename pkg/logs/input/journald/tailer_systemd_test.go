@@ -45,6 +45,16 @@ func TestShouldDropEntry(t *testing.T) {
 		}))
 }
 
+func TestSeekShouldFallBackToTailWithInvalidCursor(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	tailer := NewTailer(source, nil)
+	err := tailer.setup()
+	assert.Nil(t, err)
+	defer tailer.journal.Close()
+
+	assert.Nil(t, tailer.seek("not a cursor"))
+}
+
 func TestApplicationName(t *testing.T) {
 	source := config.NewLogSource("", &config.LogsConfig{})
 	tailer := NewTailer(source, nil)
@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+const (
+	scanPeriod = 10 * time.Second
+	// podsLogsPath is the directory where the kubelet writes the logs of the containers
+	podsLogsPath = "/var/log/pods"
+	// logsAnnotationFormat is the annotation holding the logs configuration of a container
+	logsAnnotationFormat = "ad.datadoghq.com/%s.logs"
+)
+
+// Launcher looks for the containers of the pods running on the node, and adds
+// a source tailing the log files the kubelet writes for each of them
+type Launcher struct {
+	registry     SourceRegistry
+	collectAll   bool
+	podsLogsPath string
+	kubeUtil     *kubelet.KubeUtil
+	filter       *containers.Filter
+	sources      map[string]*config.LogSource // by container ID
+	isRunning    bool
+	stop         chan struct{}
+}
+
+// NewLauncher returns a new Launcher adding the sources of the containers to
+// registry. All the containers are collected when collectAll is set, only the
+// ones having a logs configuration in the annotations of their pod otherwise.
+func NewLauncher(registry SourceRegistry, collectAll bool) *Launcher {
+	return &Launcher{
+		registry:     registry,
+		collectAll:   collectAll,
+		podsLogsPath: podsLogsPath,
+		sources:      make(map[string]*config.LogSource),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start starts the Launcher
+func (l *Launcher) Start() {
+	kubeUtil, err := kubelet.GetKubeUtil()
+	if err != nil {
+		log.Error("Can't collect the logs of the kubernetes containers, ", err)
+		return
+	}
+	l.kubeUtil = kubeUtil
+
+	filter, err := containers.NewFilterFromConfig(containers.LogsFilter)
+	if err != nil {
+		log.Error("Can't collect the logs of the kubernetes containers, invalid container filters: ", err)
+		return
+	}
+	l.filter = filter
+
+	err = tagger.Init()
+	if err != nil {
+		log.Warn(err)
+	}
+
+	go l.run()
+	l.isRunning = true
+}
+
+// Stop stops the Launcher, the tailers of the files are stopped with the
+// registry
+func (l *Launcher) Stop() {
+	if !l.isRunning {
+		return
+	}
+	l.stop <- struct{}{}
+}
+
+// run checks periodically which containers are running until stop
+func (l *Launcher) run() {
+	l.scan()
+	scanTicker := time.NewTicker(scanPeriod)
+	defer scanTicker.Stop()
+	for {
+		select {
+		case <-scanTicker.C:
+			l.scan()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// scan lists the pods of the node, whether they are ready or not as the
+// containers of the pods failing their readiness probes log too
+func (l *Launcher) scan() {
+	pods, err := l.kubeUtil.GetLocalPodList()
+	if err != nil {
+		log.Error("Can't list the pods of the node, ", err)
+		return
+	}
+	l.update(pods)
+}
+
+// update adds the sources of the new containers and removes the ones of the
+// containers that are gone. A restarted container gets a new ID, its source
+// is replaced by one tailing the log file of the new instance.
+func (l *Launcher) update(pods []*kubelet.Pod) {
+	running := make(map[string]bool)
+	for _, pod := range pods {
+		for _, container := range pod.Status.Containers {
+			if container.ID == "" {
+				// the container has not been created yet
+				continue
+			}
+			running[container.ID] = true
+			l.addSource(pod, container)
+		}
+	}
+
+	for id, source := range l.sources {
+		if !running[id] {
+			log.Infof("Stop collecting the logs of %s", source.Name)
+			delete(l.sources, id)
+			l.registry.RemoveSource(source)
+		}
+	}
+}
+
+// addSource adds the source of a container if its logs are collected and it
+// doesn't have one yet
+func (l *Launcher) addSource(pod *kubelet.Pod, container kubelet.ContainerStatus) {
+	if _, found := l.sources[container.ID]; found {
+		return
+	}
+	if l.filter.IsExcluded(container.Name, container.Image) {
+		return
+	}
+	source, err := l.newSource(pod, container)
+	if err != nil {
+		log.Warnf("Invalid logs configuration for the container %s of the pod %s: %s", container.Name, pod.Metadata.Name, err)
+		return
+	}
+	if source == nil {
+		return
+	}
+	log.Infof("Collecting the logs of %s from %s", source.Name, source.Config.Path)
+	l.sources[container.ID] = source
+	l.registry.AddSource(source)
+}
+
+// newSource returns the source of a container configured by the annotations of
+// its pod, nil when its logs are not collected
+func (l *Launcher) newSource(pod *kubelet.Pod, container kubelet.ContainerStatus) (*config.LogSource, error) {
+	var cfg *config.LogsConfig
+	if annotation, found := pod.Metadata.Annotations[fmt.Sprintf(logsAnnotationFormat, container.Name)]; found {
+		var err error
+		cfg, err = config.Parse(annotation)
+		if err != nil {
+			return nil, err
+		}
+	} else if l.collectAll {
+		cfg = &config.LogsConfig{}
+	} else {
+		return nil, nil
+	}
+
+	sourceName := container.Name
+	if _, shortImage, _, err := docker.SplitImageName(container.Image); err == nil {
+		sourceName = shortImage
+	}
+	if cfg.Source == "" {
+		cfg.Source = sourceName
+	}
+	if cfg.Service == "" {
+		cfg.Service = sourceName
+	}
+	cfg.Type = config.KubernetesType
+	cfg.Identifier = container.ID
+	cfg.Path = l.getPath(pod, container)
+
+	name := fmt.Sprintf("%s/%s/%s", pod.Metadata.Namespace, pod.Metadata.Name, container.Name)
+	return config.NewLogSource(name, cfg), nil
+}
+
+// getPath returns the path of the log file of a container, there is one file
+// per restart of the container so only the one of the current instance is
+// tailed. Depending on their version, the kubelets write them in:
+//   <pods logs path>/<namespace>_<pod name>_<pod uid>/<container name>/<restart>.log
+//   <pods logs path>/<pod uid>/<container name>/<restart>.log
+//   <pods logs path>/<pod uid>/<container name>_<restart>.log
+func (l *Launcher) getPath(pod *kubelet.Pod, container kubelet.ContainerStatus) string {
+	podDirectory := fmt.Sprintf("%s_%s_%s", pod.Metadata.Namespace, pod.Metadata.Name, pod.Metadata.UID)
+	fileName := fmt.Sprintf("%d.log", container.RestartCount)
+	for _, directory := range []string{
+		filepath.Join(l.podsLogsPath, podDirectory, container.Name),
+		filepath.Join(l.podsLogsPath, pod.Metadata.UID, container.Name),
+	} {
+		if info, err := os.Stat(directory); err == nil && info.IsDir() {
+			return filepath.Join(directory, fileName)
+		}
+	}
+	return filepath.Join(l.podsLogsPath, pod.Metadata.UID, fmt.Sprintf("%s_%s", container.Name, fileName))
+}
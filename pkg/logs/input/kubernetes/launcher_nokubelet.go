@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubelet
+
+package kubernetes
+
+// Launcher is not supported without the kubelet support
+type Launcher struct{}
+
+// NewLauncher returns a new Launcher
+func NewLauncher(registry SourceRegistry, collectAll bool) *Launcher {
+	return &Launcher{}
+}
+
+// Start does nothing
+func (l *Launcher) Start() {}
+
+// Stop does nothing
+func (l *Launcher) Stop() {}
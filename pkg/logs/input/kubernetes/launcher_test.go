@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package kubernetes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+type fakeRegistry struct {
+	sources []*config.LogSource
+	removed []*config.LogSource
+}
+
+func (r *fakeRegistry) AddSource(source *config.LogSource) {
+	r.sources = append(r.sources, source)
+}
+
+func (r *fakeRegistry) RemoveSource(source *config.LogSource) {
+	r.removed = append(r.removed, source)
+}
+
+func newTestPod() *kubelet.Pod {
+	pod := &kubelet.Pod{}
+	pod.Metadata.Name = "web-1"
+	pod.Metadata.Namespace = "default"
+	pod.Metadata.UID = "uid-1"
+	pod.Metadata.Annotations = map[string]string{
+		"ad.datadoghq.com/nginx.logs":  `[{"source":"nginx","service":"frontend","tags":["env:test"]}]`,
+		"ad.datadoghq.com/broken.logs": `{"source":"nginx"}`,
+	}
+	pod.Status.Containers = []kubelet.ContainerStatus{
+		{Name: "nginx", Image: "nginx:latest", ID: "containerd://abcdef"},
+		{Name: "sidecar", Image: "gcr.io/project/proxy:1.0", ID: "containerd://012345", RestartCount: 2},
+		{Name: "broken", Image: "busybox", ID: "containerd://6789ab"},
+		{Name: "pending", Image: "busybox"},
+	}
+	return pod
+}
+
+func TestNewSource(t *testing.T) {
+	pod := newTestPod()
+	launcher := NewLauncher(&fakeRegistry{}, false)
+	launcher.podsLogsPath = "/var/log/pods"
+
+	source, err := launcher.newSource(pod, pod.Status.Containers[0])
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	assert.Equal(t, "default/web-1/nginx", source.Name)
+	assert.Equal(t, config.KubernetesType, source.Config.Type)
+	assert.Equal(t, "containerd://abcdef", source.Config.Identifier)
+	assert.Equal(t, "/var/log/pods/uid-1/nginx_0.log", source.Config.Path)
+	assert.Equal(t, "nginx", source.Config.Source)
+	assert.Equal(t, "frontend", source.Config.Service)
+	// the tags of the container are added by the tailer when the messages are sent
+	assert.Equal(t, []string{"env:test"}, source.Config.Tags)
+
+	// the containers without annotation are only collected with collectAll
+	source, err = launcher.newSource(pod, pod.Status.Containers[1])
+	assert.NoError(t, err)
+	assert.Nil(t, source)
+
+	_, err = launcher.newSource(pod, pod.Status.Containers[2])
+	assert.Error(t, err)
+
+	launcher.collectAll = true
+	source, err = launcher.newSource(pod, pod.Status.Containers[1])
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	assert.Equal(t, "proxy", source.Config.Source)
+	assert.Equal(t, "proxy", source.Config.Service)
+	assert.Equal(t, "/var/log/pods/uid-1/sidecar_2.log", source.Config.Path)
+}
+
+func TestUpdate(t *testing.T) {
+	pod := newTestPod()
+	registry := &fakeRegistry{}
+	launcher := NewLauncher(registry, true)
+
+	launcher.update([]*kubelet.Pod{pod})
+	// sources are added once per container
+	launcher.update([]*kubelet.Pod{pod})
+	require.Len(t, registry.sources, 2)
+	assert.Equal(t, "default/web-1/nginx", registry.sources[0].Name)
+	assert.Equal(t, "default/web-1/sidecar", registry.sources[1].Name)
+	assert.Len(t, registry.removed, 0)
+
+	// the restarted container gets a new source tailing its new log file
+	pod.Status.Containers[1].ID = "containerd://3456cd"
+	pod.Status.Containers[1].RestartCount = 3
+	launcher.update([]*kubelet.Pod{pod})
+	require.Len(t, registry.sources, 3)
+	assert.Equal(t, "containerd://3456cd", registry.sources[2].Config.Identifier)
+	assert.Equal(t, "/var/log/pods/uid-1/sidecar_3.log", registry.sources[2].Config.Path)
+	require.Len(t, registry.removed, 1)
+	assert.Equal(t, "containerd://012345", registry.removed[0].Config.Identifier)
+
+	// the sources of the deleted pods are removed
+	launcher.update(nil)
+	assert.Len(t, registry.removed, 3)
+	assert.Len(t, launcher.sources, 0)
+}
+
+func TestGetPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pods-logs-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pod := newTestPod()
+	container := pod.Status.Containers[0]
+	launcher := NewLauncher(&fakeRegistry{}, false)
+	launcher.podsLogsPath = dir
+
+	assert.Equal(t, filepath.Join(dir, "uid-1", "nginx_0.log"), launcher.getPath(pod, container))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "uid-1", "nginx"), 0755))
+	assert.Equal(t, filepath.Join(dir, "uid-1", "nginx", "0.log"), launcher.getPath(pod, container))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "default_web-1_uid-1", "nginx"), 0755))
+	assert.Equal(t, filepath.Join(dir, "default_web-1_uid-1", "nginx", "0.log"), launcher.getPath(pod, container))
+}
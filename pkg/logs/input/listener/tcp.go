@@ -6,6 +6,7 @@
 package listener
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
@@ -27,6 +28,7 @@ type TCPListener struct {
 	source    *config.LogSource
 	frameSize int
 	listener  net.Listener
+	tlsConfig *tls.Config
 	tailers   []*Tailer
 	mu        sync.Mutex
 	stop      chan struct{}
@@ -46,7 +48,14 @@ func NewTCPListener(pp pipeline.Provider, source *config.LogSource, frameSize in
 // Start starts the listener to accepts new incoming connections.
 func (l *TCPListener) Start() {
 	log.Infof("Starting TCP forwarder on port %d", l.source.Config.Port)
-	err := l.startListener()
+	tlsConfig, err := buildTLSConfig(l.source.Config)
+	if err != nil {
+		log.Errorf("Can't start TCP forwarder on port %d: %v", l.source.Config.Port, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.tlsConfig = tlsConfig
+	err = l.startListener()
 	if err != nil {
 		log.Errorf("Can't start TCP forwarder on port %d: %v", l.source.Config.Port, err)
 		l.source.Status.Error(err)
@@ -60,7 +69,9 @@ func (l *TCPListener) Start() {
 func (l *TCPListener) Stop() {
 	log.Infof("Stopping TCP forwarder on port %d", l.source.Config.Port)
 	l.stop <- struct{}{}
-	l.listener.Close()
+	if l.listener != nil {
+		l.listener.Close()
+	}
 	stopper := restart.NewParallelStopper()
 	for _, tailer := range l.tailers {
 		stopper.Add(tailer)
@@ -107,6 +118,10 @@ func (l *TCPListener) startListener() error {
 	if err != nil {
 		return err
 	}
+	if l.tlsConfig != nil {
+		// the handshake happens on the first read of the tailer
+		listener = tls.NewListener(listener, l.tlsConfig)
+	}
 	l.listener = listener
 	return nil
 }
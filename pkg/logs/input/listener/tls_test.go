@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listener
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+)
+
+// writeCertificate writes a self-signed certificate valid for localhost and its key in dir
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certPath, keyPath
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "listener-tls-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	certPath, keyPath := writeCertificate(t, dir)
+
+	tlsConfig, err := buildTLSConfig(&config.LogsConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	tlsConfig, err = buildTLSConfig(&config.LogsConfig{TLSCert: certPath, TLSKey: keyPath})
+	require.NoError(t, err)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+
+	tlsConfig, err = buildTLSConfig(&config.LogsConfig{TLSCert: certPath, TLSKey: keyPath, TLSCACert: certPath})
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.ClientCAs)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+
+	_, err = buildTLSConfig(&config.LogsConfig{TLSCert: certPath, TLSKey: filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+
+	_, err = buildTLSConfig(&config.LogsConfig{TLSCert: certPath, TLSKey: keyPath, TLSCACert: keyPath})
+	assert.Error(t, err)
+}
+
+func TestTCPWithTLSShouldReceivesMessages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "listener-tls-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	certPath, keyPath := writeCertificate(t, dir)
+
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Port: tcpTestPort, TLSCert: certPath, TLSKey: keyPath, TLSCACert: certPath})
+	listener := NewTCPListener(pp, source, defaultFrameSize)
+	listener.Start()
+	defer listener.Stop()
+
+	clientCert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	require.NoError(t, err)
+	tlsConfig, err := buildTLSConfig(source.Config)
+	require.NoError(t, err)
+	conn, err := tls.Dial("tcp", fmt.Sprintf("localhost:%d", tcpTestPort), &tls.Config{
+		RootCAs:      tlsConfig.ClientCAs,
+		Certificates: []tls.Certificate{clientCert},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "hello world\n")
+	msg := <-msgChan
+	assert.Equal(t, "hello world", string(msg.Content()))
+}
@@ -73,9 +73,44 @@ func (t *Tailer) toMessage(event string) (message.Message, error) {
 		return nil, err
 	}
 	log.Debug("Sending JSON: ", string(jsonEvent))
+	origin := message.NewOrigin(t.source)
+	// set the source attribute of the message to the provider of the event,
+	// this value is still overridden by the integration config when defined
+	origin.SetSource(getValue(mv, "Event.System.Provider.Name"))
 	return message.New(
 		jsonEvent,
-		message.NewOrigin(t.source),
-		message.StatusInfo,
+		origin,
+		getStatus(mv),
 	), nil
 }
+
+// levelStatusMapping represents the mapping between the event levels and the statuses,
+// see https://docs.microsoft.com/en-us/windows/desktop/wes/eventschema-level-systempropertiestype-element
+var levelStatusMapping = map[string]string{
+	"1": message.StatusCritical,
+	"2": message.StatusError,
+	"3": message.StatusWarning,
+	"4": message.StatusInfo,
+	"5": message.StatusDebug,
+}
+
+// getStatus returns the status of the event,
+// returns "info" by default if no valid level is found.
+func getStatus(mv mxj.Map) string {
+	status, exists := levelStatusMapping[getValue(mv, "Event.System.Level")]
+	if !exists {
+		return message.StatusInfo
+	}
+	return status
+}
+
+// getValue returns the value of the event at path,
+// returns an empty string if the path doesn't lead to a string.
+func getValue(mv mxj.Map, path string) string {
+	value, err := mv.ValueForPath(path)
+	if err != nil {
+		return ""
+	}
+	str, _ := value.(string)
+	return str
+}
@@ -9,12 +9,39 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
 func TestToMessage(t *testing.T) {
-	tailer := NewTailer(nil, nil, nil)
+	tailer := NewTailer(config.NewLogSource("", &config.LogsConfig{}), nil, nil)
 	evt1 := `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Service Control Manager' Guid='{555908d1-a6d7-4695-8e1e-26931d2012f4}' EventSourceName='Service Control Manager'/><EventID Qualifiers='16384'>7036</EventID><Version>0</Version><Level>4</Level><Task>0</Task><Opcode>0</Opcode><Keywords>0x8080000000000000</Keywords><TimeCreated SystemTime='2013-08-22T14:51:44.205667300Z'/><EventRecordID>2</EventRecordID><Correlation/><Execution ProcessID='516' ThreadID='1792'/><Channel>System</Channel><Computer>windows-n7iefg2</Computer><Security/></System><EventData><Data Name='param1'>Windows Event Log</Data><Data Name='param2'>stopped</Data><Binary>4500760065006E0074004C006F0067002F0031000000</Binary></EventData></Event>`
 	expected1 := `{"Event":{"EventData":{"Binary":"4500760065006E0074004C006F0067002F0031000000","Data":[{"#text":"Windows Event Log","Name":"param1"},{"#text":"stopped","Name":"param2"}]},"System":{"Channel":"System","Computer":"windows-n7iefg2","Correlation":"","EventID":{"#text":"7036","Qualifiers":"16384"},"EventRecordID":"2","Execution":{"ProcessID":"516","ThreadID":"1792"},"Keywords":"0x8080000000000000","Level":"4","Opcode":"0","Provider":{"EventSourceName":"Service Control Manager","Guid":"{555908d1-a6d7-4695-8e1e-26931d2012f4}","Name":"Service Control Manager"},"Security":"","Task":"0","TimeCreated":{"SystemTime":"2013-08-22T14:51:44.205667300Z"},"Version":"0"},"xmlns":"http://schemas.microsoft.com/win/2004/08/events/event"}}`
 	actual, _ := tailer.toMessage(evt1)
 	assert.Equal(t, expected1, string(actual.Content()))
+	assert.Equal(t, message.StatusInfo, actual.GetStatus())
+	assert.Equal(t, "Service Control Manager", actual.GetOrigin().Source())
+}
+
+func TestToMessageStatus(t *testing.T) {
+	tailer := NewTailer(config.NewLogSource("", &config.LogsConfig{}), nil, nil)
+	for level, status := range map[string]string{
+		"0": message.StatusInfo,
+		"1": message.StatusCritical,
+		"2": message.StatusError,
+		"3": message.StatusWarning,
+		"5": message.StatusDebug,
+	} {
+		evt := `<Event><System><Provider Name='MsiInstaller'/><Level>` + level + `</Level></System></Event>`
+		actual, err := tailer.toMessage(evt)
+		assert.Nil(t, err)
+		assert.Equal(t, status, actual.GetStatus(), level)
+	}
+
+	// no level
+	actual, err := tailer.toMessage(`<Event><System><Channel>Application</Channel></System></Event>`)
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusInfo, actual.GetStatus())
+	assert.Equal(t, "", actual.GetOrigin().Source())
 }
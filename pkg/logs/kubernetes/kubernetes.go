@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Message represents a log line written by a container runtime in the CRI
+// log format
+type Message struct {
+	Content   []byte
+	Status    string
+	Timestamp string
+	IsPartial bool
+}
+
+// Tags of the CRI log format telling whether a line is complete or is a chunk
+// of a longer line the runtime split
+const (
+	partialTag = "P"
+	fullTag    = "F"
+)
+
+// ParseMessage extracts the date, the status and the content of a log line.
+// The format of the line is:
+//   <timestamp> <stdout|stderr> <P|F> <content>
+// The P|F tag is missing from the files written by the older runtimes, the
+// lines are complete in that case.
+func ParseMessage(msg []byte) (Message, error) {
+	fields := bytes.SplitN(msg, []byte{' '}, 4)
+	if len(fields) < 2 {
+		return Message{}, fmt.Errorf("can't parse kubernetes message: %q", msg)
+	}
+
+	status, err := getStatus(fields[1])
+	if err != nil {
+		return Message{}, err
+	}
+	parsed := Message{
+		Status:    status,
+		Timestamp: string(fields[0]),
+	}
+
+	switch {
+	case len(fields) < 3:
+		// nothing after the stream: empty message
+	case len(fields) == 3 && isTag(fields[2]):
+		parsed.IsPartial = string(fields[2]) == partialTag
+	case isTag(fields[2]):
+		parsed.IsPartial = string(fields[2]) == partialTag
+		parsed.Content = fields[3]
+	default:
+		// no tag, the content starts after the stream
+		parsed.Content = msg[len(fields[0])+len(fields[1])+2:]
+	}
+	return parsed, nil
+}
+
+// GetMetadataLength returns the length of the timestamp, stream and tag, and
+// of the spaces following them, that are in front of the content of a line.
+func GetMetadataLength(msg []byte) int {
+	parsed, err := ParseMessage(msg)
+	if err != nil {
+		return 0
+	}
+	return len(msg) - len(parsed.Content)
+}
+
+// getStatus returns the status of the message based on the stream it was
+// written to.
+func getStatus(stream []byte) (string, error) {
+	switch string(stream) {
+	case "stdout":
+		return message.StatusInfo, nil
+	case "stderr":
+		return message.StatusError, nil
+	default:
+		return "", fmt.Errorf("can't parse kubernetes message: unknown stream %q", stream)
+	}
+}
+
+// isTag returns whether field is the partial or the full tag
+func isTag(field []byte) bool {
+	tag := string(field)
+	return tag == partialTag || tag == fullTag
+}
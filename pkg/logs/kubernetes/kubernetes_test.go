@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const timestamp = "2018-09-20T11:54:11.753589172Z"
+
+func TestParseMessageShouldSucceedWithValidInput(t *testing.T) {
+	msg, err := ParseMessage([]byte(timestamp + " stdout F anything goes"))
+	assert.Nil(t, err)
+	assert.Equal(t, timestamp, msg.Timestamp)
+	assert.Equal(t, message.StatusInfo, msg.Status)
+	assert.Equal(t, []byte("anything goes"), msg.Content)
+	assert.False(t, msg.IsPartial)
+
+	msg, err = ParseMessage([]byte(timestamp + " stderr P part of a line"))
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusError, msg.Status)
+	assert.Equal(t, []byte("part of a line"), msg.Content)
+	assert.True(t, msg.IsPartial)
+}
+
+func TestParseMessageShouldSucceedWithoutTag(t *testing.T) {
+	msg, err := ParseMessage([]byte(timestamp + " stdout Fine, thanks"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Fine, thanks"), msg.Content)
+	assert.False(t, msg.IsPartial)
+}
+
+func TestParseMessageShouldHandleEmptyMessage(t *testing.T) {
+	for _, line := range []string{timestamp + " stdout", timestamp + " stdout F", timestamp + " stdout F "} {
+		msg, err := ParseMessage([]byte(line))
+		assert.Nil(t, err, line)
+		assert.Equal(t, 0, len(msg.Content), line)
+	}
+}
+
+func TestParseMessageShouldFailWithInvalidInput(t *testing.T) {
+	for _, line := range []string{"", "anything", timestamp + " stdin F hello"} {
+		_, err := ParseMessage([]byte(line))
+		assert.NotNil(t, err, line)
+	}
+}
+
+func TestGetMetadataLength(t *testing.T) {
+	assert.Equal(t, len(timestamp)+10, GetMetadataLength([]byte(timestamp+" stdout F hello")))
+	assert.Equal(t, len(timestamp)+8, GetMetadataLength([]byte(timestamp+" stdout hello")))
+	assert.Equal(t, 0, GetMetadataLength([]byte("hello")))
+}
@@ -17,6 +17,8 @@ var (
 	isRunning bool
 	// logs-agent
 	agent *Agent
+	// scheduler of the logs configurations of autodiscovery
+	adScheduler *Scheduler
 )
 
 // Start starts logs-agent
@@ -32,8 +34,11 @@ func Start() error {
 	agent = NewAgent(sources)
 	agent.Start()
 
+	// setup the autodiscovery scheduler
+	adScheduler = NewScheduler(sources)
+
 	// setup the status
-	status.Initialize(sources.GetSources())
+	status.Initialize(sources)
 
 	isRunning = true
 
@@ -49,6 +54,15 @@ func Stop() {
 	}
 }
 
+// GetScheduler returns the scheduler of the logs configurations of
+// autodiscovery, nil when logs-agent is not running
+func GetScheduler() *Scheduler {
+	if !isRunning {
+		return nil
+	}
+	return adScheduler
+}
+
 // GetStatus returns logs-agent status
 func GetStatus() status.Status {
 	if !isRunning {
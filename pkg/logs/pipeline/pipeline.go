@@ -6,9 +6,12 @@
 package pipeline
 
 import (
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/processor"
+	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
 )
 
@@ -16,33 +19,54 @@ import (
 type Pipeline struct {
 	InputChan chan message.Message
 	processor *processor.Processor
-	sender    *sender.Sender
+	sender    restart.Restartable
 }
 
-// NewPipeline returns a new Pipeline
+// NewPipeline returns a new Pipeline, sending the messages to the http intake
+// when logs_config.use_http is set, over connManager otherwise
 func NewPipeline(connManager *sender.ConnectionManager, outputChan chan message.Message) *Pipeline {
 
 	useProto := config.LogsAgent.GetBool("logs_config.dev_mode_use_proto")
+	useHTTP := config.LogsAgent.GetBool("logs_config.use_http")
+	apikey := config.LogsAgent.GetString("api_key")
 
 	// initialize the sender
 	senderChan := make(chan message.Message, config.ChanSize)
-	delimiter := sender.NewDelimiter(useProto)
-	sender := sender.New(senderChan, outputChan, connManager, delimiter)
+	var s restart.Restartable
+	if useHTTP {
+		destination := sender.NewHTTPDestination(
+			config.LogsAgent.GetString("logs_config.http_url"),
+			apikey,
+			config.LogsAgent.GetBool("logs_config.use_compression"),
+		)
+		batchWait := time.Duration(config.LogsAgent.GetInt("logs_config.batch_wait")) * time.Second
+		s = sender.NewHTTPSender(senderChan, outputChan, destination, batchWait)
+	} else {
+		delimiter := sender.NewDelimiter(useProto)
+		s = sender.New(senderChan, outputChan, connManager, delimiter)
+	}
 
 	// initialize the input chan
 	inputChan := make(chan message.Message, config.ChanSize)
 
 	// initialize the processor
-	encoder := processor.NewEncoder(useProto)
-	apikey := config.LogsAgent.GetString("api_key")
-	logset := config.LogsAgent.GetString("logset") // TODO Logset is deprecated and should be removed eventually.
-	prefixer := processor.NewAPIKeyPrefixer(apikey, logset)
+	var encoder processor.Encoder
+	var prefixer processor.Prefixer
+	if useHTTP {
+		// the API key is sent in a header of the requests
+		encoder = processor.NewJSONEncoder()
+		prefixer = processor.NewNoopPrefixer()
+	} else {
+		encoder = processor.NewEncoder(useProto)
+		logset := config.LogsAgent.GetString("logset") // TODO Logset is deprecated and should be removed eventually.
+		prefixer = processor.NewAPIKeyPrefixer(apikey, logset)
+	}
 	processor := processor.New(inputChan, senderChan, encoder, prefixer)
 
 	return &Pipeline{
 		InputChan: inputChan,
 		processor: processor,
-		sender:    sender,
+		sender:    s,
 	}
 }
 
@@ -6,6 +6,8 @@
 package processor
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"regexp"
@@ -31,6 +33,9 @@ var rawEncoder raw
 // Proto is an encoder implementation that writes messages as protocol buffers.
 var protoEncoder proto
 
+// JSON is an encoder implementation that writes messages as the json objects of the http intake.
+var jsonEncoder jsonFormat
+
 // NewEncoder returns an encoder.
 func NewEncoder(useProto bool) Encoder {
 	if useProto {
@@ -39,6 +44,11 @@ func NewEncoder(useProto bool) Encoder {
 	return &rawEncoder
 }
 
+// NewJSONEncoder returns the encoder of the messages sent to the http intake.
+func NewJSONEncoder() Encoder {
+	return &jsonEncoder
+}
+
 var rfc5424Pattern, _ = regexp.Compile("<[0-9]{1,3}>[0-9] ")
 
 type raw struct{}
@@ -134,6 +144,32 @@ func (p *proto) toValidUtf8(msg []byte) string {
 	return string(str)
 }
 
+type jsonFormat struct{}
+
+// jsonPayload is the json object of a message, the invalid UTF-8 sequences of
+// its content are replaced by the marshaller.
+type jsonPayload struct {
+	Message   string `json:"message"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+	Hostname  string `json:"hostname"`
+	Service   string `json:"service"`
+	Source    string `json:"ddsource"`
+	Tags      string `json:"ddtags"`
+}
+
+func (j *jsonFormat) encode(msg message.Message, redactedMsg []byte) ([]byte, error) {
+	return json.Marshal(jsonPayload{
+		Message:   string(redactedMsg),
+		Status:    msg.GetStatus(),
+		Timestamp: time.Now().UTC().UnixNano() / int64(time.Millisecond),
+		Hostname:  getHostname(),
+		Service:   msg.GetOrigin().Service(),
+		Source:    msg.GetOrigin().Source(),
+		Tags:      strings.Join(msg.GetOrigin().Tags(), ","),
+	})
+}
+
 // getHostname returns the hostname for the agent.
 func getHostname() string {
 	// Compute the hostname
@@ -6,6 +6,7 @@
 package processor
 
 import (
+	"encoding/json"
 	"testing"
 
 	"strings"
@@ -21,6 +22,7 @@ import (
 func TestNewEncoder(t *testing.T) {
 	assert.Equal(t, &protoEncoder, NewEncoder(true))
 	assert.Equal(t, &rawEncoder, NewEncoder(false))
+	assert.Equal(t, &jsonEncoder, NewJSONEncoder())
 }
 
 func TestRawEncoder(t *testing.T) {
@@ -196,3 +198,32 @@ func TestProtoEncoderToValidUTF8(t *testing.T) {
 	assert.Equal(t, "a���z", protoEncoder.toValidUtf8([]byte("a\xed\xa0\x80z")))
 	assert.Equal(t, "a����z", protoEncoder.toValidUtf8([]byte("a\xf0\x8f\xbf\xbfz")))
 }
+
+func TestJSONEncoder(t *testing.T) {
+
+	logsConfig := &config.LogsConfig{
+		Service:        "Service",
+		Source:         "Source",
+		SourceCategory: "SourceCategory",
+		Tags:           []string{"foo:bar", "baz"},
+	}
+
+	source := config.NewLogSource("", logsConfig)
+
+	msg := newMessage([]byte("message"), source, message.StatusError)
+	msg.GetOrigin().SetTags([]string{"a", "b:c"})
+
+	content, err := jsonEncoder.encode(msg, []byte("redacted\xff"))
+	assert.Nil(t, err)
+
+	var payload jsonPayload
+	assert.Nil(t, json.Unmarshal(content, &payload))
+	assert.Equal(t, "redacted\uFFFD", payload.Message)
+	assert.Equal(t, message.StatusError, payload.Status)
+	assert.NotZero(t, payload.Timestamp)
+	assert.NotEmpty(t, payload.Hostname)
+	assert.Equal(t, "Service", payload.Service)
+	assert.Equal(t, "Source", payload.Source)
+	assert.Equal(t, "a,b:c,sourcecategory:SourceCategory,foo:bar,baz", payload.Tags)
+
+}
@@ -28,3 +28,17 @@ func NewAPIKeyPrefixer(apikey, logset string) Prefixer {
 func (p *apiKeyPrefixer) prefix(content []byte) []byte {
 	return append(p.key, content...)
 }
+
+// noopPrefixer leaves the messages unchanged, the API key of the http intake is sent in a header.
+type noopPrefixer struct {
+	Prefixer
+}
+
+// NewNoopPrefixer returns a prefixer that doesn't change the messages.
+func NewNoopPrefixer() Prefixer {
+	return &noopPrefixer{}
+}
+
+func (p *noopPrefixer) prefix(content []byte) []byte {
+	return content
+}
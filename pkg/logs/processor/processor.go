@@ -81,7 +81,11 @@ func applyRedactingRules(msg message.Message) (bool, []byte) {
 				return false, nil
 			}
 		case config.MaskSequences:
-			content = rule.Reg.ReplaceAllLiteral(content, rule.ReplacePlaceholderBytes)
+			if rule.ExpandCaptureGroups {
+				content = rule.Reg.ReplaceAll(content, rule.ReplacePlaceholderBytes)
+			} else {
+				content = rule.Reg.ReplaceAllLiteral(content, rule.ReplacePlaceholderBytes)
+			}
 		}
 	}
 	return true, content
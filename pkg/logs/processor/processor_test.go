@@ -138,6 +138,18 @@ func TestMask(t *testing.T) {
 	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("The credit card 4323124312341234 was used to buy some time"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("The credit card [masked_credit_card] was used to buy some time"), redactedMessage)
+
+	// the placeholder is literal by default
+	source = buildTestConfigLogSource("mask_sequences", "$1[masked]", "([0-9]{4})[0-9]{12}")
+	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("The credit card 4323124312341234 was used to buy some time"), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte("The credit card $1[masked] was used to buy some time"), redactedMessage)
+
+	source = buildTestConfigLogSource("mask_sequences", "${1}********${2}", "([0-9]{4})[0-9]{8}([0-9]{4})")
+	source.Config.ProcessingRules[0].ExpandCaptureGroups = true
+	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("The credit card 4323124312341234 was used to buy some time"), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte("The credit card 4323********1234 was used to buy some time"), redactedMessage)
 }
 
 func TestTruncate(t *testing.T) {
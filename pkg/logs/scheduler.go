@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const dockerEntityPrefix = "docker://"
+
+// Scheduler turns the logs configurations resolved by autodiscovery into
+// sources tailing the logs of the containers they were resolved for
+type Scheduler struct {
+	sources *config.LogSources
+	// sources added per config digest
+	scheduled map[string]*config.LogSource
+	m         sync.Mutex
+}
+
+// NewScheduler returns a new Scheduler adding its sources to sources
+func NewScheduler(sources *config.LogSources) *Scheduler {
+	return &Scheduler{
+		sources:   sources,
+		scheduled: make(map[string]*config.LogSource),
+	}
+}
+
+// Schedule adds a source for the resolved configs having a logs configuration,
+// the other ones are skipped, the static ones being handled by the logs-agent
+func (s *Scheduler) Schedule(configs []integration.Config) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, cfg := range configs {
+		if len(cfg.LogsConfig) == 0 || cfg.Entity == "" {
+			continue
+		}
+		digest := cfg.Digest()
+		if _, found := s.scheduled[digest]; found {
+			continue
+		}
+		source, err := newSource(cfg)
+		if err != nil {
+			log.Warnf("Invalid logs configuration for %s: %s", cfg.Entity, err)
+			continue
+		}
+		log.Infof("Collecting the logs of %s", cfg.Entity)
+		s.scheduled[digest] = source
+		s.sources.AddSource(source)
+	}
+}
+
+// Unschedule removes the sources of the configs
+func (s *Scheduler) Unschedule(configs []integration.Config) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, cfg := range configs {
+		digest := cfg.Digest()
+		source, found := s.scheduled[digest]
+		if !found {
+			continue
+		}
+		delete(s.scheduled, digest)
+		s.sources.RemoveSource(source)
+	}
+}
+
+// Stop does nothing, the sources are released with the logs-agent
+func (s *Scheduler) Stop() {}
+
+// newSource returns a docker source for the container the config was resolved for
+func newSource(cfg integration.Config) (*config.LogSource, error) {
+	if !strings.HasPrefix(cfg.Entity, dockerEntityPrefix) {
+		return nil, fmt.Errorf("only the docker containers are supported")
+	}
+	rawConfig, err := decryptLogsConfig(cfg.LogsConfig)
+	if err != nil {
+		return nil, err
+	}
+	logsConfig, err := config.Parse(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	logsConfig.Type = config.DockerType
+	logsConfig.Identifier = strings.TrimPrefix(cfg.Entity, dockerEntityPrefix)
+
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Entity
+	}
+	return config.NewLogSource(name, logsConfig), nil
+}
+
+// decryptLogsConfig returns the JSON logs configuration with its secret
+// handles replaced by the secrets, the scheduled configs keeping the handles
+func decryptLogsConfig(data integration.Data) (string, error) {
+	decrypted, err := secrets.Decrypt(data)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt the secrets: %s", err)
+	}
+	if bytes.Equal(decrypted, data) {
+		return string(data), nil
+	}
+	// the secrets backend returns the configuration as YAML
+	var raw interface{}
+	if err := yaml.Unmarshal(decrypted, &raw); err != nil {
+		return "", err
+	}
+	jsonConfig, err := json.Marshal(util.GetJSONSerializableMap(raw))
+	if err != nil {
+		return "", err
+	}
+	return string(jsonConfig), nil
+}
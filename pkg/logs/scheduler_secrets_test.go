@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package logs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+)
+
+func TestScheduleDecryptsSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	backend := filepath.Join(dir, "backend.sh")
+	script := "#!/bin/sh\necho '{\"redis_service\": {\"value\": \"cache\"}}'\n"
+	require.NoError(t, ioutil.WriteFile(backend, []byte(script), 0700))
+	secrets.Init(backend, nil, 5, 1024)
+	defer secrets.Init("", nil, 5, 1024)
+
+	sources := config.NewLogSources([]*config.LogSource{})
+	scheduler := NewScheduler(sources)
+	cfg := integration.Config{
+		LogsConfig: integration.Data(`[{"source":"redis","service":"ENC[redis_service]"}]`),
+		Entity:     "docker://a1b2c3",
+	}
+	scheduler.Schedule([]integration.Config{cfg})
+
+	require.Len(t, sources.GetSources(), 1)
+	assert.Equal(t, "cache", sources.GetSources()[0].Config.Service)
+	// the scheduled config keeps its secret handle
+	assert.Equal(t, `[{"source":"redis","service":"ENC[redis_service]"}]`, string(cfg.LogsConfig))
+
+	scheduler.Unschedule([]integration.Config{cfg})
+	assert.Len(t, sources.GetSources(), 0)
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestScheduleUnschedule(t *testing.T) {
+	sources := config.NewLogSources([]*config.LogSource{})
+	scheduler := NewScheduler(sources)
+
+	configs := []integration.Config{
+		{
+			LogsConfig: integration.Data(`[{"source":"redis","service":"cache"}]`),
+			Entity:     "docker://a1b2c3",
+		},
+		// static configurations are handled by the logs-agent
+		{LogsConfig: integration.Data("- type: file\n  path: /var/log/app.log\n")},
+		// no logs configuration
+		{Name: "redisdb", Entity: "docker://a1b2c3"},
+		// unsupported entity
+		{
+			LogsConfig: integration.Data(`[{"source":"redis","service":"cache"}]`),
+			Entity:     "kube_service://default/redis",
+		},
+		// invalid logs configuration
+		{LogsConfig: integration.Data(`{"source":"redis"}`), Entity: "docker://d4e5f6"},
+	}
+
+	scheduler.Schedule(configs)
+	require.Len(t, sources.GetSources(), 1)
+	source := sources.GetSources()[0]
+	assert.Equal(t, "docker://a1b2c3", source.Name)
+	assert.Equal(t, config.DockerType, source.Config.Type)
+	assert.Equal(t, "a1b2c3", source.Config.Identifier)
+	assert.Equal(t, "redis", source.Config.Source)
+	assert.Equal(t, "cache", source.Config.Service)
+
+	// scheduling the same configuration again is a no-op
+	scheduler.Schedule(configs[:1])
+	assert.Len(t, sources.GetSources(), 1)
+
+	scheduler.Unschedule(configs)
+	assert.Len(t, sources.GetSources(), 0)
+}
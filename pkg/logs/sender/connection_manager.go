@@ -30,16 +30,27 @@ type ConnectionManager struct {
 	serverAddress string
 	devModeNoSSL  bool
 	proxyAddress  string
+	proxyAuth     *proxy.Auth
 	mutex         sync.Mutex
 	firstConn     sync.Once
 }
 
-// NewConnectionManager returns an initialized ConnectionManager
-func NewConnectionManager(serverName string, serverPort int, devModeNoSSL bool, proxyAddress string) *ConnectionManager {
+// NewConnectionManager returns an initialized ConnectionManager, connecting through
+// the SOCKS5 proxy at proxyAddress when it's set, authenticated with proxyUsername
+// and proxyPassword when a username is set
+func NewConnectionManager(serverName string, serverPort int, devModeNoSSL bool, proxyAddress, proxyUsername, proxyPassword string) *ConnectionManager {
+	var proxyAuth *proxy.Auth
+	if proxyUsername != "" {
+		proxyAuth = &proxy.Auth{
+			User:     proxyUsername,
+			Password: proxyPassword,
+		}
+	}
 	return &ConnectionManager{
 		serverName:    serverName,
 		serverAddress: fmt.Sprintf("%s:%d", serverName, serverPort),
 		proxyAddress:  proxyAddress,
+		proxyAuth:     proxyAuth,
 		devModeNoSSL:  devModeNoSSL,
 	}
 }
@@ -61,7 +72,7 @@ func (cm *ConnectionManager) NewConnection() net.Conn {
 	var retries int
 	for {
 		if retries > 0 {
-			cm.backoff(retries)
+			backoff(retries)
 		}
 		retries++
 
@@ -70,7 +81,7 @@ func (cm *ConnectionManager) NewConnection() net.Conn {
 
 		if cm.proxyAddress != "" {
 			var dialer proxy.Dialer
-			dialer, err = proxy.SOCKS5("tcp", cm.proxyAddress, nil, proxy.Direct)
+			dialer, err = proxy.SOCKS5("tcp", cm.proxyAddress, cm.proxyAuth, &net.Dialer{Timeout: timeout})
 			if err != nil {
 				log.Warn(err)
 				continue
@@ -122,11 +133,16 @@ func (cm *ConnectionManager) handleServerClose(conn net.Conn) {
 	}
 }
 
-// backoff lets the connection manager sleep a bit
-func (cm *ConnectionManager) backoff(retries int) {
-	backoffDuration := backoffUnit * time.Duration(retries)
-	if backoffDuration > backoffMax {
-		backoffDuration = backoffMax
+// backoff sleeps a bit longer after each retry
+func backoff(retries int) {
+	time.Sleep(backoffDuration(retries))
+}
+
+// backoffDuration returns how long to wait before the next retry
+func backoffDuration(retries int) time.Duration {
+	duration := backoffUnit * time.Duration(retries)
+	if duration > backoffMax {
+		duration = backoffMax
 	}
-	time.Sleep(backoffDuration)
+	return duration
 }
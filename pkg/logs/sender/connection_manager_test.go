@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package sender
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveSOCKS5 accepts a connection on listener, authenticates it with the
+// username "user" and the password "secret", and relays it to target
+func serveSOCKS5(listener net.Listener, target string) error {
+	conn, err := listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// version and authentication methods, the username/password one is selected
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(reader, make([]byte, header[1])); err != nil {
+		return err
+	}
+	conn.Write([]byte{5, 2})
+
+	// version, username length, username, password length, password
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	username := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, username); err != nil {
+		return err
+	}
+	length, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	password := make([]byte, length)
+	if _, err := io.ReadFull(reader, password); err != nil {
+		return err
+	}
+	if string(username) != "user" || string(password) != "secret" {
+		conn.Write([]byte{1, 1})
+		return fmt.Errorf("invalid credentials %s:%s", username, password)
+	}
+	conn.Write([]byte{1, 0})
+
+	// version, command, reserved, address type, address and port
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(reader, request); err != nil {
+		return err
+	}
+	addressLength := net.IPv4len
+	switch request[3] {
+	case 3:
+		length, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		addressLength = int(length)
+	case 4:
+		addressLength = net.IPv6len
+	}
+	if _, err := io.ReadFull(reader, make([]byte, addressLength+2)); err != nil {
+		return err
+	}
+
+	targetConn, err := net.Dial("tcp", target)
+	if err != nil {
+		return err
+	}
+	defer targetConn.Close()
+	conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0})
+	_, err = io.Copy(targetConn, reader)
+	return err
+}
+
+func TestConnectionManagerThroughSOCKS5Proxy(t *testing.T) {
+	intake, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer intake.Close()
+	proxy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer proxy.Close()
+	proxyErr := make(chan error, 1)
+	go func() {
+		proxyErr <- serveSOCKS5(proxy, intake.Addr().String())
+	}()
+
+	port := intake.Addr().(*net.TCPAddr).Port
+	connManager := NewConnectionManager("127.0.0.1", port, true, proxy.Addr().String(), "user", "secret")
+	conn := connManager.NewConnection()
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	intakeConn, err := intake.Accept()
+	require.NoError(t, err)
+	defer intakeConn.Close()
+	line, err := bufio.NewReader(intakeConn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", line)
+
+	conn.Close()
+	assert.NoError(t, <-proxyErr)
+}
@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package sender
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	// maxBatchSize is the maximum number of messages in a payload
+	maxBatchSize = 200
+	// maxContentSize is the maximum size of a payload before compression
+	maxContentSize = 1000000
+	// defaultBatchWait is used when the configured batch wait is not positive
+	defaultBatchWait = 5 * time.Second
+)
+
+// clientError is returned when the intake rejected a payload, sending it again would fail too
+type clientError struct {
+	status string
+}
+
+func (e *clientError) Error() string {
+	return fmt.Sprintf("payload rejected: %s", e.status)
+}
+
+// HTTPDestination posts the payloads to the http intake
+type HTTPDestination struct {
+	url            string
+	apiKey         string
+	useCompression bool
+	client         *http.Client
+}
+
+// NewHTTPDestination returns a new HTTPDestination posting to url, through the proxy of the agent when one is configured
+func NewHTTPDestination(url, apiKey string, useCompression bool) *HTTPDestination {
+	return &HTTPDestination{
+		url:            url,
+		apiKey:         apiKey,
+		useCompression: useCompression,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: util.CreateHTTPTransport(),
+		},
+	}
+}
+
+// Send posts a payload, it returns a clientError when the intake rejected it
+func (d *HTTPDestination) Send(payload []byte) error {
+	if d.useCompression {
+		var err error
+		payload, err = compress(payload)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", d.apiKey)
+	if d.useCompression {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// read the body so that the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("intake error: %s", resp.Status)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return &clientError{status: resp.Status}
+	}
+	return nil
+}
+
+// compress returns the gzip compressed payload
+func compress(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// An HTTPSender sends the messages from an inputChan to the http intake in batches,
+// a batch is sent when it's full or every batchWait.
+type HTTPSender struct {
+	inputChan   chan message.Message
+	outputChan  chan message.Message
+	destination *HTTPDestination
+	batchWait   time.Duration
+	messages    []message.Message
+	contentSize int
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewHTTPSender returns an initialized HTTPSender
+func NewHTTPSender(inputChan, outputChan chan message.Message, destination *HTTPDestination, batchWait time.Duration) *HTTPSender {
+	if batchWait <= 0 {
+		log.Warnf("Invalid batch wait %v, using %v instead", batchWait, defaultBatchWait)
+		batchWait = defaultBatchWait
+	}
+	return &HTTPSender{
+		inputChan:   inputChan,
+		outputChan:  outputChan,
+		destination: destination,
+		batchWait:   batchWait,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start starts the HTTPSender
+func (s *HTTPSender) Start() {
+	go s.run()
+}
+
+// Stop stops the HTTPSender,
+// this call blocks until inputChan is flushed and the last batch is sent,
+// the pending batches are not retried anymore once stopping
+func (s *HTTPSender) Stop() {
+	close(s.stop)
+	close(s.inputChan)
+	<-s.done
+}
+
+// run adds the messages to the batch until inputChan is closed
+func (s *HTTPSender) run() {
+	defer func() {
+		s.done <- struct{}{}
+	}()
+	flushTicker := time.NewTicker(s.batchWait)
+	defer flushTicker.Stop()
+	for {
+		select {
+		case payload, isOpen := <-s.inputChan:
+			if !isOpen {
+				s.flush()
+				return
+			}
+			if s.contentSize+len(payload.Content()) > maxContentSize {
+				s.flush()
+			}
+			s.messages = append(s.messages, payload)
+			s.contentSize += len(payload.Content())
+			if len(s.messages) == maxBatchSize {
+				s.flush()
+			}
+		case <-flushTicker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush sends the batch as a json array, retrying until the intake accepts or
+// rejects it or the sender is stopped. The messages of a batch accepted or
+// rejected, and dropped, are passed to outputChan for the auditor to commit
+// their offsets; a batch abandoned on shutdown is not, to be sent again on
+// the next start.
+func (s *HTTPSender) flush() {
+	if len(s.messages) == 0 {
+		return
+	}
+	if s.send(s.buildPayload()) {
+		for _, msg := range s.messages {
+			s.outputChan <- msg
+		}
+	}
+	s.messages = s.messages[:0]
+	s.contentSize = 0
+}
+
+// send sends the payload, retrying on server errors, it returns false when
+// the sender is stopped before the payload is accepted or rejected
+func (s *HTTPSender) send(payload []byte) bool {
+	for retries := 0; ; retries++ {
+		err := s.destination.Send(payload)
+		if err == nil {
+			return true
+		}
+		if _, isClientError := err.(*clientError); isClientError {
+			log.Errorf("Can't send %d logs, dropping them: %v", len(s.messages), err)
+			return true
+		}
+		if !s.waitBeforeRetry(retries + 1) {
+			log.Errorf("Can't send %d logs, the sender is stopping: %v", len(s.messages), err)
+			return false
+		}
+		log.Warnf("Can't send %d logs, retrying: %v", len(s.messages), err)
+	}
+}
+
+// waitBeforeRetry backs off before the next retry, it returns false when
+// the sender is stopped meanwhile
+func (s *HTTPSender) waitBeforeRetry(retries int) bool {
+	timer := time.NewTimer(backoffDuration(retries))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.stop:
+		return false
+	}
+}
+
+// buildPayload returns the json array of the messages of the batch
+func (s *HTTPSender) buildPayload() []byte {
+	payload := make([]byte, 0, s.contentSize+len(s.messages)+1)
+	payload = append(payload, '[')
+	for i, msg := range s.messages {
+		if i > 0 {
+			payload = append(payload, ',')
+		}
+		payload = append(payload, msg.Content()...)
+	}
+	return append(payload, ']')
+}
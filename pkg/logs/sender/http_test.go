@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package sender
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// newTestIntake returns a server answering the statuses in order, and
+// passing the bodies of the requests to payloads
+func newTestIntake(t *testing.T, payloads chan []byte, statuses ...int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "api_key", r.Header.Get("DD-API-KEY"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		reader, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		w.WriteHeader(status)
+		payloads <- body
+	}))
+}
+
+func newTestMessage(content string) message.Message {
+	return message.New([]byte(content), message.NewOrigin(config.NewLogSource("", &config.LogsConfig{})), "")
+}
+
+func TestHTTPSenderSendsBatches(t *testing.T) {
+	payloads := make(chan []byte, 10)
+	intake := newTestIntake(t, payloads)
+	defer intake.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, NewHTTPDestination(intake.URL, "api_key", true), time.Hour)
+	sender.Start()
+
+	inputChan <- newTestMessage(`{"message":"hello"}`)
+	inputChan <- newTestMessage(`{"message":"world"}`)
+	sender.Stop()
+
+	assert.Equal(t, `[{"message":"hello"},{"message":"world"}]`, string(<-payloads))
+	assert.Len(t, outputChan, 2)
+}
+
+func TestHTTPSenderFlushesPeriodically(t *testing.T) {
+	payloads := make(chan []byte, 10)
+	intake := newTestIntake(t, payloads)
+	defer intake.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, NewHTTPDestination(intake.URL, "api_key", true), 10*time.Millisecond)
+	sender.Start()
+	defer sender.Stop()
+
+	inputChan <- newTestMessage(`{"message":"hello"}`)
+	assert.Equal(t, `[{"message":"hello"}]`, string(<-payloads))
+	<-outputChan
+}
+
+func TestHTTPSenderRetries(t *testing.T) {
+	payloads := make(chan []byte, 10)
+	intake := newTestIntake(t, payloads, http.StatusServiceUnavailable, http.StatusBadRequest)
+	defer intake.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, NewHTTPDestination(intake.URL, "api_key", true), 10*time.Millisecond)
+	sender.Start()
+
+	inputChan <- newTestMessage(`{"message":"hello"}`)
+	<-outputChan
+	sender.Stop()
+
+	// the payload is sent again after a server error, and dropped after a client error
+	assert.Equal(t, `[{"message":"hello"}]`, string(<-payloads))
+	assert.Equal(t, `[{"message":"hello"}]`, string(<-payloads))
+	assert.Len(t, payloads, 0)
+}
+
+func TestHTTPSenderStopsRetrying(t *testing.T) {
+	payloads := make(chan []byte, 10)
+	intake := newTestIntake(t, payloads, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer intake.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, NewHTTPDestination(intake.URL, "api_key", true), time.Hour)
+	sender.Start()
+
+	inputChan <- newTestMessage(`{"message":"hello"}`)
+	sender.Stop()
+
+	// the last batch is sent once and not retried after a server error, nor
+	// committed to be sent again on the next start
+	assert.Equal(t, `[{"message":"hello"}]`, string(<-payloads))
+	assert.Len(t, payloads, 0)
+	assert.Len(t, outputChan, 0)
+}
+
+func TestHTTPSenderDefaultBatchWait(t *testing.T) {
+	sender := NewHTTPSender(nil, nil, NewHTTPDestination("", "api_key", true), 0)
+	assert.Equal(t, defaultBatchWait, sender.batchWait)
+}
@@ -14,12 +14,13 @@ import (
 )
 
 func TestSourceAreGroupedByIntegrations(t *testing.T) {
-	sources := []*config.LogSource{
+	sources := config.NewLogSources([]*config.LogSource{
 		config.NewLogSource("foo", &config.LogsConfig{}),
 		config.NewLogSource("bar", &config.LogsConfig{}),
-		config.NewLogSource("foo", &config.LogsConfig{}),
-	}
+	})
 	Initialize(sources)
+	// added at runtime
+	sources.AddSource(config.NewLogSource("foo", &config.LogsConfig{}))
 	status := Get()
 	assert.Equal(t, true, status.IsRunning)
 	assert.Equal(t, 2, len(status.Integrations))
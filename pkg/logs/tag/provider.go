@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package tag
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+// refreshPeriod is the period after which the tags are requested again from
+// the tagger, to pick up the changes of the labels and the annotations
+const refreshPeriod = 10 * time.Second
+
+// Provider returns the tags of a container from the tagger, as its image,
+// its pod or its labels, to add to each of its messages when they're sent.
+// A Provider is used by a single tailer, it's not safe for concurrent use.
+type Provider struct {
+	entityName string
+	highCard   bool
+	tags       []string
+	expiry     time.Time
+	tag        func(entity string, highCard bool) ([]string, error)
+}
+
+// NewProvider returns a new Provider of the tags of the tagger entity, as
+// docker://<container id>, with the cardinality set by logs_config.tag_cardinality
+func NewProvider(entityName string) *Provider {
+	return &Provider{
+		entityName: entityName,
+		highCard:   IsHighCardinality(),
+		tag:        tagger.Tag,
+	}
+}
+
+// GetTags returns the tags of the entity, they're cached for refreshPeriod
+func (p *Provider) GetTags() []string {
+	now := time.Now()
+	if now.Before(p.expiry) {
+		return p.tags
+	}
+	tags, err := p.tag(p.entityName, p.highCard)
+	if err != nil {
+		log.Warnf("Can't get the tags of %s: %v", p.entityName, err)
+	} else {
+		p.tags = tags
+	}
+	p.expiry = now.Add(refreshPeriod)
+	return p.tags
+}
+
+// IsHighCardinality returns true unless logs_config.tag_cardinality is set to low,
+// the high cardinality tags, as the container ids, are then added to the messages
+func IsHighCardinality() bool {
+	switch cardinality := config.LogsAgent.GetString("logs_config.tag_cardinality"); cardinality {
+	case "low":
+		return false
+	case "high":
+		return true
+	default:
+		log.Warnf("Invalid logs_config.tag_cardinality %q, using high", cardinality)
+		return true
+	}
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package tag
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestProviderGetTags(t *testing.T) {
+	var calls int
+	var err error
+	tags := []string{"image_name:nginx"}
+	provider := NewProvider("docker://abcdef")
+	provider.tag = func(entity string, highCard bool) ([]string, error) {
+		assert.Equal(t, "docker://abcdef", entity)
+		assert.True(t, highCard)
+		calls++
+		return tags, err
+	}
+
+	assert.Equal(t, []string{"image_name:nginx"}, provider.GetTags())
+	// the tags are cached
+	tags = []string{"image_name:nginx", "env:prod"}
+	assert.Equal(t, []string{"image_name:nginx"}, provider.GetTags())
+	assert.Equal(t, 1, calls)
+
+	provider.expiry = time.Now()
+	assert.Equal(t, []string{"image_name:nginx", "env:prod"}, provider.GetTags())
+	assert.Equal(t, 2, calls)
+
+	// the last tags are kept when the tagger fails
+	provider.expiry = time.Now()
+	err = errors.New("tagger error")
+	assert.Equal(t, []string{"image_name:nginx", "env:prod"}, provider.GetTags())
+	assert.Equal(t, 3, calls)
+}
+
+func TestIsHighCardinality(t *testing.T) {
+	defer config.LogsAgent.Set("logs_config.tag_cardinality", "high")
+
+	config.LogsAgent.Set("logs_config.tag_cardinality", "high")
+	assert.True(t, IsHighCardinality())
+
+	config.LogsAgent.Set("logs_config.tag_cardinality", "low")
+	assert.False(t, IsHighCardinality())
+
+	config.LogsAgent.Set("logs_config.tag_cardinality", "medium")
+	assert.True(t, IsHighCardinality())
+}
@@ -70,6 +70,13 @@ func GetMeta() *Meta {
 	return getMeta()
 }
 
+// GetHostTags returns the tags of the host: the configured ones and the EC2,
+// Kubernetes, Docker and GCE ones
+func GetHostTags() []string {
+	t := getHostTags()
+	return append(t.System, t.GoogleCloudPlatform...)
+}
+
 func getHostTags() *tags {
 	hostTags := config.Datadog.GetStringSlice("tags")
 
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -46,8 +48,15 @@ const (
 )
 
 var (
-	defaultAggregates  = []string(nil)
-	defaultPercentiles = []int(nil)
+	// histogramConfigOnce loads the configuration of the histograms on the
+	// first histogram creation, the histograms can be created concurrently by
+	// the time samplers of the dogstatsd pipelines
+	histogramConfigOnce sync.Once
+	defaultAggregates   = []string(nil)
+	defaultPercentiles  = []int(nil)
+	// histogramOverrides are sorted by decreasing prefix length, the longest
+	// matching prefix configures the histogram
+	histogramOverrides = []histogramOverride(nil)
 )
 
 type histogramPercentilesConfig struct {
@@ -55,15 +64,21 @@ type histogramPercentilesConfig struct {
 }
 
 func (h *histogramPercentilesConfig) percentiles() []int {
+	return parsePercentiles("histogram_percentiles", h.Percentiles)
+}
+
+// parsePercentiles converts the percentiles of the option, between 0 and 1,
+// to ints in the 1-100 range
+func parsePercentiles(option string, percentiles []string) []int {
 	res := []int{}
-	for _, p := range h.Percentiles {
+	for _, p := range percentiles {
 		i, err := strconv.ParseFloat(p, 64)
 		if err != nil {
-			log.Errorf("Could not parse '%s' from 'histogram_percentiles' (skipping): %s", p, err)
+			log.Errorf("Could not parse '%s' from '%s' (skipping): %s", p, option, err)
 			continue
 		}
 		if i < 0 || i > 1 {
-			log.Errorf("histogram_percentiles must be between 0 and 1: skipping %f", i)
+			log.Errorf("%s must be between 0 and 1: skipping %f", option, i)
 			continue
 		}
 		// in some cases the '*100' will lower the number resulting in
@@ -74,22 +89,65 @@ func (h *histogramPercentilesConfig) percentiles() []int {
 	return res
 }
 
-// NewHistogram returns a newly initialized histogram
-func NewHistogram(interval int64) *Histogram {
-	// we initialize default value on the first histogram creation
-	if defaultAggregates == nil {
-		defaultAggregates = config.Datadog.GetStringSlice("histogram_aggregates")
+// histogramOverride is the configuration of the histograms of a metric prefix
+type histogramOverride struct {
+	prefix      string
+	aggregates  []string
+	percentiles []int
+}
+
+// loadHistogramOverrides returns the histogram_overrides, the aggregates or
+// percentiles not set falling back to the default ones
+func loadHistogramOverrides() []histogramOverride {
+	var entries []config.HistogramOverride
+	if err := config.Datadog.UnmarshalKey("histogram_overrides", &entries); err != nil {
+		log.Errorf("Could not parse histogram_overrides: %s", err)
+		return nil
 	}
-	if defaultPercentiles == nil {
-		c := histogramPercentilesConfig{}
-		err := config.Datadog.Unmarshal(&c)
-		if err != nil {
-			log.Errorf("Could not Unmarshal histogram configuration: %s", err)
-		} else {
-			defaultPercentiles = c.percentiles()
-			sort.Ints(defaultPercentiles)
+
+	overrides := make([]histogramOverride, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Prefix == "" {
+			log.Errorf("histogram_overrides: skipping an entry without prefix")
+			continue
+		}
+		o := histogramOverride{
+			prefix:      entry.Prefix,
+			aggregates:  defaultAggregates,
+			percentiles: defaultPercentiles,
+		}
+		if entry.Aggregates != nil {
+			o.aggregates = entry.Aggregates
+		}
+		if entry.Percentiles != nil {
+			o.percentiles = parsePercentiles("histogram_overrides", entry.Percentiles)
+			sort.Ints(o.percentiles)
 		}
+		overrides = append(overrides, o)
 	}
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return len(overrides[i].prefix) > len(overrides[j].prefix)
+	})
+	return overrides
+}
+
+// loadHistogramConfig loads the default aggregates and percentiles, and the
+// histogram_overrides
+func loadHistogramConfig() {
+	defaultAggregates = config.Datadog.GetStringSlice("histogram_aggregates")
+	c := histogramPercentilesConfig{}
+	if err := config.Datadog.Unmarshal(&c); err != nil {
+		log.Errorf("Could not Unmarshal histogram configuration: %s", err)
+	} else {
+		defaultPercentiles = c.percentiles()
+		sort.Ints(defaultPercentiles)
+	}
+	histogramOverrides = loadHistogramOverrides()
+}
+
+// NewHistogram returns a newly initialized histogram
+func NewHistogram(interval int64) *Histogram {
+	histogramConfigOnce.Do(loadHistogramConfig)
 
 	return &Histogram{
 		interval:    interval,
@@ -98,6 +156,21 @@ func NewHistogram(interval int64) *Histogram {
 	}
 }
 
+// newHistogramFor returns a histogram with the aggregates and percentiles
+// configured for the metric name
+func newHistogramFor(name string, interval int64) *Histogram {
+	h := NewHistogram(interval)
+	for _, o := range histogramOverrides {
+		if strings.HasPrefix(name, o.prefix) {
+			// the percentiles are sorted already
+			h.aggregates = o.aggregates
+			h.percentiles = o.percentiles
+			break
+		}
+	}
+	return h
+}
+
 func (h *Histogram) configure(aggregates []string, percentiles []int) {
 	h.aggregates = aggregates
 	sort.Ints(percentiles)
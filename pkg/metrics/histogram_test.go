@@ -8,6 +8,7 @@ package metrics
 import (
 	// stdlib
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,6 +19,14 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
+// resetHistogramConfig makes the next histogram creation reload the configuration
+func resetHistogramConfig() {
+	histogramConfigOnce = sync.Once{}
+	defaultAggregates = nil
+	defaultPercentiles = nil
+	histogramOverrides = nil
+}
+
 func TestHistogramConf(t *testing.T) {
 	h := histogramPercentilesConfig{Percentiles: []string{"0.95", "0.96", "0.28", "0.57", "0.58"}}
 	assert.Equal(t, []int{95, 96, 28, 57, 58}, h.percentiles())
@@ -45,12 +54,10 @@ func TestConfigure(t *testing.T) {
 	defer func() {
 		config.Datadog.Set("histogram_aggregates", aggregatesBk)
 		config.Datadog.Set("histogram_percentiles", percentilesBk)
-		defaultAggregates = nil
-		defaultPercentiles = nil
+		resetHistogramConfig()
 	}()
 
-	defaultAggregates = nil
-	defaultPercentiles = nil
+	resetHistogramConfig()
 	aggregates := []string{"max", "min", "test"}
 	config.Datadog.Set("histogram_aggregates", aggregates)
 	config.Datadog.Set("histogram_percentiles", []string{"0.50", "0.30", "0.98"})
@@ -362,3 +369,63 @@ func BenchmarkHistogram10000SampleRate02(b *testing.B) {
 func BenchmarkHistogram100000SampleRate02(b *testing.B) {
 	benchHistogram(b, 100000, 0.2)
 }
+
+func TestHistogramOverrides(t *testing.T) {
+	defer func() {
+		config.Datadog.Set("histogram_overrides", nil)
+		resetHistogramConfig()
+	}()
+
+	resetHistogramConfig()
+	config.Datadog.Set("histogram_overrides", []map[string]interface{}{
+		{"prefix": "my.app.", "aggregates": []string{"max"}},
+		{"prefix": "my.app.latency", "percentiles": []string{"0.99", "0.5"}},
+		{"aggregates": []string{"min"}},
+	})
+
+	hist := newHistogramFor("my.app.latency.db", 10)
+	assert.Equal(t, []string{"max", "median", "avg", "count"}, hist.aggregates)
+	assert.Equal(t, []int{50, 99}, hist.percentiles)
+
+	hist = newHistogramFor("my.app.requests", 10)
+	assert.Equal(t, []string{"max"}, hist.aggregates)
+	assert.Equal(t, []int{95}, hist.percentiles)
+
+	hist = newHistogramFor("other.app.requests", 10)
+	assert.Equal(t, []string{"max", "median", "avg", "count"}, hist.aggregates)
+	assert.Equal(t, []int{95}, hist.percentiles)
+
+	historate := newHistorateFor("my.app.requests", 10)
+	assert.Equal(t, []string{"max"}, historate.histogram.aggregates)
+}
+
+func TestHistogramOverridesLoadedOnce(t *testing.T) {
+	percentilesBk := config.Datadog.Get("histogram_percentiles")
+	defer func() {
+		config.Datadog.Set("histogram_percentiles", percentilesBk)
+		config.Datadog.Set("histogram_overrides", nil)
+		resetHistogramConfig()
+	}()
+
+	resetHistogramConfig()
+	config.Datadog.Set("histogram_percentiles", []string{})
+	config.Datadog.Set("histogram_overrides", []map[string]interface{}{
+		{"prefix": "my.app.", "aggregates": []string{"max"}},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			newHistogramFor("my.app.requests", 10)
+		}()
+	}
+	wg.Wait()
+
+	// the empty default percentiles don't trigger a reload of the overrides
+	config.Datadog.Set("histogram_overrides", nil)
+	hist := newHistogramFor("my.app.requests", 10)
+	assert.Equal(t, []string{"max"}, hist.aggregates)
+	assert.Empty(t, hist.percentiles)
+}
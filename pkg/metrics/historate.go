@@ -22,6 +22,14 @@ func NewHistorate(interval int64) *Historate {
 	}
 }
 
+// newHistorateFor returns a historate with the aggregates and percentiles
+// configured for the metric name
+func newHistorateFor(name string, interval int64) *Historate {
+	return &Historate{
+		histogram: *newHistogramFor(name, interval),
+	}
+}
+
 func (h *Historate) addSample(sample *MetricSample, timestamp float64) {
 	if h.previousTimestamp != 0 {
 		v := (sample.Value - h.previousSample) / (timestamp - h.previousTimestamp)
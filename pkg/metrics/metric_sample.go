@@ -5,6 +5,8 @@
 
 package metrics
 
+import "sync"
+
 // MetricType is the representation of an aggregator metric type
 type MetricType int
 
@@ -18,7 +20,6 @@ const (
 	HistogramType
 	HistorateType
 	SetType
-	// NOTE: DistributionType is in development and is NOT supported
 	DistributionType
 )
 
@@ -54,6 +55,7 @@ func (m MetricType) String() string {
 }
 
 // MetricSample represents a raw metric sample
+// RawValue is only set for the SetType samples.
 type MetricSample struct {
 	Name       string
 	Value      float64
@@ -73,3 +75,22 @@ func (src *MetricSample) Copy() *MetricSample {
 	copy(dst.Tags, src.Tags)
 	return dst
 }
+
+var metricSamplePool = sync.Pool{
+	New: func() interface{} {
+		return &MetricSample{}
+	},
+}
+
+// GetMetricSample returns an empty MetricSample from a pool, to avoid
+// allocating one per dogstatsd packet.
+func GetMetricSample() *MetricSample {
+	return metricSamplePool.Get().(*MetricSample)
+}
+
+// PutMetricSample resets the sample and puts it back in the pool. The sample
+// must not be used afterwards, but its Name, Tags and RawValue can be retained.
+func PutMetricSample(sample *MetricSample) {
+	*sample = MetricSample{}
+	metricSamplePool.Put(sample)
+}
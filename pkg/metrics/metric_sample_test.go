@@ -27,3 +27,17 @@ func TestMetricSampleCopy(t *testing.T) {
 	assert.False(t, src == dst)
 	assert.True(t, reflect.DeepEqual(&src, &dst))
 }
+
+func TestMetricSamplePool(t *testing.T) {
+	sample := GetMetricSample()
+	sample.Name = "metric.name"
+	sample.Tags = []string{"a", "b"}
+	tags := sample.Tags
+
+	PutMetricSample(sample)
+	assert.Equal(t, MetricSample{}, *sample)
+	// the tags can be retained by the aggregator
+	assert.Equal(t, []string{"a", "b"}, tags)
+
+	assert.Equal(t, MetricSample{}, *GetMetricSample())
+}
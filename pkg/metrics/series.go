@@ -51,13 +51,14 @@ type Serie struct {
 type Series []*Serie
 
 func marshalPoints(points []Point) []*agentpayload.MetricsPayload_Sample_Point {
-	pointsPayload := []*agentpayload.MetricsPayload_Sample_Point{}
-
-	for _, p := range points {
-		pointsPayload = append(pointsPayload, &agentpayload.MetricsPayload_Sample_Point{
-			Ts:    int64(p.Ts),
-			Value: p.Value,
-		})
+	// the points are allocated at once, there is usually one per serie
+	pointsPayload := make([]*agentpayload.MetricsPayload_Sample_Point, len(points))
+	pointsData := make([]agentpayload.MetricsPayload_Sample_Point, len(points))
+
+	for i, p := range points {
+		pointsData[i].Ts = int64(p.Ts)
+		pointsData[i].Value = p.Value
+		pointsPayload[i] = &pointsData[i]
 	}
 	return pointsPayload
 }
@@ -65,20 +66,21 @@ func marshalPoints(points []Point) []*agentpayload.MetricsPayload_Sample_Point {
 // Marshal serialize timeseries using agent-payload definition
 func (series Series) Marshal() ([]byte, error) {
 	payload := &agentpayload.MetricsPayload{
-		Samples:  []*agentpayload.MetricsPayload_Sample{},
+		Samples:  make([]*agentpayload.MetricsPayload_Sample, len(series)),
 		Metadata: &agentpayload.CommonMetadata{},
 	}
 
-	for _, serie := range series {
-		payload.Samples = append(payload.Samples,
-			&agentpayload.MetricsPayload_Sample{
-				Metric:         serie.Name,
-				Type:           serie.MType.String(),
-				Host:           serie.Host,
-				Points:         marshalPoints(serie.Points),
-				Tags:           serie.Tags,
-				SourceTypeName: serie.SourceTypeName,
-			})
+	samples := make([]agentpayload.MetricsPayload_Sample, len(series))
+	for i, serie := range series {
+		samples[i] = agentpayload.MetricsPayload_Sample{
+			Metric:         serie.Name,
+			Type:           serie.MType.String(),
+			Host:           serie.Host,
+			Points:         marshalPoints(serie.Points),
+			Tags:           serie.Tags,
+			SourceTypeName: serie.SourceTypeName,
+		}
+		payload.Samples[i] = &samples[i]
 	}
 
 	return proto.Marshal(payload)
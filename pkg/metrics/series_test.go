@@ -7,6 +7,7 @@ package metrics
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/gogo/protobuf/proto"
@@ -273,3 +274,63 @@ func TestUnmarshalSeriesJSON(t *testing.T) {
 	err = json.Unmarshal(badPointJSON, &badPoint)
 	require.NotNil(t, err)
 }
+
+func makeSeries(count int) Series {
+	series := make(Series, 0, count)
+	for i := 0; i < count; i++ {
+		series = append(series, &Serie{
+			Name:   fmt.Sprintf("test.metrics.%d", i%100),
+			Points: []Point{{Ts: 1535000000, Value: float64(i)}},
+			Tags: []string{
+				"env:prod",
+				"service:web",
+				fmt.Sprintf("container_id:%032d", i),
+				fmt.Sprintf("pod_name:web-%d", i),
+				"kube_namespace:default",
+			},
+			Host:           "my-host",
+			MType:          APIGaugeType,
+			Interval:       10,
+			SourceTypeName: "System",
+		})
+	}
+	return series
+}
+
+func TestSeriesPayloadSize(t *testing.T) {
+	protobufPayload, err := makeSeries(100).Marshal()
+	require.NoError(t, err)
+	jsonPayload, err := makeSeries(100).MarshalJSON()
+	require.NoError(t, err)
+
+	assert.True(t, len(protobufPayload) < len(jsonPayload), "protobuf: %d bytes, json: %d bytes", len(protobufPayload), len(jsonPayload))
+}
+
+func benchmarkSeriesMarshal(b *testing.B, count int, marshal func(Series) ([]byte, error)) {
+	series := makeSeries(count)
+	payload, err := marshal(series)
+	require.NoError(b, err)
+	b.Logf("%d series: %d bytes", count, len(payload))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		marshal(series)
+	}
+}
+
+func BenchmarkSeriesMarshal1000(b *testing.B) {
+	benchmarkSeriesMarshal(b, 1000, Series.Marshal)
+}
+
+func BenchmarkSeriesMarshalJSON1000(b *testing.B) {
+	benchmarkSeriesMarshal(b, 1000, Series.MarshalJSON)
+}
+
+func BenchmarkSeriesMarshal10000(b *testing.B) {
+	benchmarkSeriesMarshal(b, 10000, Series.Marshal)
+}
+
+func BenchmarkSeriesMarshalJSON10000(b *testing.B) {
+	benchmarkSeriesMarshal(b, 10000, Series.MarshalJSON)
+}
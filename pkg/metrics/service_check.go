@@ -78,20 +78,21 @@ type ServiceChecks []*ServiceCheck
 // Marshal serialize service checks using agent-payload definition
 func (sc ServiceChecks) Marshal() ([]byte, error) {
 	payload := &agentpayload.ServiceChecksPayload{
-		ServiceChecks: []*agentpayload.ServiceChecksPayload_ServiceCheck{},
+		ServiceChecks: make([]*agentpayload.ServiceChecksPayload_ServiceCheck, len(sc)),
 		Metadata:      &agentpayload.CommonMetadata{},
 	}
 
-	for _, c := range sc {
-		payload.ServiceChecks = append(payload.ServiceChecks,
-			&agentpayload.ServiceChecksPayload_ServiceCheck{
-				Name:    c.CheckName,
-				Host:    c.Host,
-				Ts:      c.Ts,
-				Status:  int32(c.Status),
-				Message: c.Message,
-				Tags:    c.Tags,
-			})
+	serviceChecks := make([]agentpayload.ServiceChecksPayload_ServiceCheck, len(sc))
+	for i, c := range sc {
+		serviceChecks[i] = agentpayload.ServiceChecksPayload_ServiceCheck{
+			Name:    c.CheckName,
+			Host:    c.Host,
+			Ts:      c.Ts,
+			Status:  int32(c.Status),
+			Message: c.Message,
+			Tags:    c.Tags,
+		}
+		payload.ServiceChecks[i] = &serviceChecks[i]
 	}
 
 	return proto.Marshal(payload)
@@ -6,6 +6,7 @@
 package metrics
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/gogo/protobuf/proto"
@@ -83,3 +84,39 @@ func TestSplitServiceChecks(t *testing.T) {
 	require.Nil(t, err)
 	require.Len(t, newSC, 2)
 }
+
+func makeServiceChecks(count int) ServiceChecks {
+	serviceChecks := make(ServiceChecks, 0, count)
+	for i := 0; i < count; i++ {
+		serviceChecks = append(serviceChecks, &ServiceCheck{
+			CheckName: "test.check",
+			Host:      "my-host",
+			Ts:        1535000000,
+			Status:    ServiceCheckOK,
+			Message:   "this is fine",
+			Tags:      []string{"env:prod", fmt.Sprintf("container_id:%032d", i)},
+		})
+	}
+	return serviceChecks
+}
+
+func benchmarkServiceChecksMarshal(b *testing.B, count int, marshal func(ServiceChecks) ([]byte, error)) {
+	serviceChecks := makeServiceChecks(count)
+	payload, err := marshal(serviceChecks)
+	require.NoError(b, err)
+	b.Logf("%d service checks: %d bytes", count, len(payload))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		marshal(serviceChecks)
+	}
+}
+
+func BenchmarkServiceChecksMarshal1000(b *testing.B) {
+	benchmarkServiceChecksMarshal(b, 1000, ServiceChecks.Marshal)
+}
+
+func BenchmarkServiceChecksMarshalJSON1000(b *testing.B) {
+	benchmarkServiceChecksMarshal(b, 1000, ServiceChecks.MarshalJSON)
+}
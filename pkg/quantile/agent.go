@@ -56,3 +56,14 @@ func (a *Agent) Insert(v float64) {
 
 	a.flush()
 }
+
+// InsertN is equivalent to calling Insert(v) n times, it is used to weight a
+// value by the sample rate it was sent with. The n values are added to the
+// sketch as a single bin count instead of being buffered one by one.
+func (a *Agent) InsertN(v float64, n uint) {
+	if n == 0 {
+		return
+	}
+	a.Sketch.Basic.InsertN(v, n)
+	a.Sketch.insertN(agentConfig, agentConfig.key(v), int(n))
+}
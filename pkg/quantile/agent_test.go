@@ -110,3 +110,32 @@ func TestAgentFinish(t *testing.T) {
 		require.Nil(t, a.Finish())
 	})
 }
+
+func TestAgentInsertN(t *testing.T) {
+	a := &Agent{}
+	a.InsertN(1, 0)
+	require.True(t, a.IsEmpty())
+
+	a.InsertN(1, agentBufCap+2)
+	require.Empty(t, a.Buf)
+	require.EqualValues(t, agentBufCap+2, a.Sketch.Basic.Cnt)
+	require.EqualValues(t, agentBufCap+2, a.Sketch.Basic.Sum)
+
+	exp := &Sketch{}
+	for i := 0; i < agentBufCap+2; i++ {
+		exp.Insert(Default(), 1)
+	}
+	require.True(t, exp.Equals(a.Finish()))
+}
+
+func TestAgentInsertNOverflow(t *testing.T) {
+	a := &Agent{}
+	a.Insert(1)
+	a.InsertN(1, 3*maxBinWidth)
+	a.InsertN(2, 5)
+
+	s := a.Finish()
+	require.EqualValues(t, 3*maxBinWidth+6, s.Basic.Cnt)
+	require.Equal(t, 3*maxBinWidth+6, s.count)
+	require.Equal(t, 3*maxBinWidth+6, s.bins.nSum())
+}
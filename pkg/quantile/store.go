@@ -184,6 +184,15 @@ func (s *sparseStore) insert(c *Config, keys []Key) {
 	putBinList(tmp)
 }
 
+// insertN adds n values of key k to the store.
+func (s *sparseStore) insertN(c *Config, k Key, n int) {
+	o := sparseStore{
+		bins:  appendSafe(nil, k, n),
+		count: n,
+	}
+	s.merge(c, &o)
+}
+
 // bufCountLeadingEqual returns the number of consecutive keys in a[i:] that equal a[i].
 // given:
 //   i = 0 1 2 3 4 5 6
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	auditJSONExtension   = ".json"
+	auditBinaryExtension = ".bin"
+)
+
+// payloadAuditor writes a copy of the outgoing payloads to a directory, as
+// they are split and before their compression, so that what the agent sends
+// can be reviewed. The JSON payloads are indented and written in .json files,
+// the protobuf ones as is in .bin files. Only the maxFiles newest files are kept.
+type payloadAuditor struct {
+	path     string
+	maxFiles int
+	mu       sync.Mutex
+	// files are sorted from the oldest to the newest
+	files []string
+}
+
+func newPayloadAuditor(path string, maxFiles int) (*payloadAuditor, error) {
+	if maxFiles <= 0 {
+		return nil, fmt.Errorf("invalid number of audit files %d", maxFiles)
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, fmt.Errorf("could not create the audit directory: %s", err)
+	}
+
+	a := &payloadAuditor{
+		path:     path,
+		maxFiles: maxFiles,
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the audit files: %s", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), auditJSONExtension) || strings.HasSuffix(entry.Name(), auditBinaryExtension)) {
+			continue
+		}
+		a.files = append(a.files, filepath.Join(path, entry.Name()))
+	}
+	// the files are named after their creation time
+	sort.Strings(a.files)
+	a.rotate(0)
+
+	return a, nil
+}
+
+// audit writes the payload, indented if it is JSON. A nil auditor does nothing.
+func (a *payloadAuditor) audit(kind string, payload []byte) {
+	if a == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	extension := auditJSONExtension
+	if err := json.Indent(&buf, payload, "", "  "); err != nil {
+		buf.Reset()
+		buf.Write(payload)
+		extension = auditBinaryExtension
+	}
+	if err := a.write(kind+extension, buf.Bytes()); err != nil {
+		log.Warnf("Could not audit the %s payload: %s", kind, err)
+	}
+}
+
+// auditCompressed decompresses the payload and writes it. A nil auditor does nothing.
+func (a *payloadAuditor) auditCompressed(kind string, payload []byte) {
+	if a == nil {
+		return
+	}
+
+	data, err := compression.Decompress(nil, payload)
+	if err != nil {
+		log.Warnf("Could not audit the %s payload: %s", kind, err)
+		return
+	}
+	a.audit(kind, data)
+}
+
+func (a *payloadAuditor) write(suffix string, data []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rotate(1)
+	name := filepath.Join(a.path, fmt.Sprintf("%020d_%s", time.Now().UnixNano(), suffix))
+	if err := ioutil.WriteFile(name, data, 0600); err != nil {
+		return err
+	}
+	a.files = append(a.files, name)
+	return nil
+}
+
+// rotate removes the oldest files until count files can be added
+func (a *payloadAuditor) rotate(count int) {
+	for len(a.files) > 0 && len(a.files)+count > a.maxFiles {
+		if err := os.Remove(a.files[0]); err != nil && !os.IsNotExist(err) {
+			log.Errorf("Could not remove the audit file %s: %s", a.files[0], err)
+		}
+		a.files = a.files[1:]
+	}
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadAuditor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	auditor, err := newPayloadAuditor(dir, 2)
+	require.NoError(t, err)
+
+	auditor.audit("series", []byte(`{"series":[{"metric":"foo"}]}`))
+	auditor.audit("orchestrator", []byte{0x0a, 0x03})
+	require.Len(t, auditor.files, 2)
+
+	data, err := ioutil.ReadFile(auditor.files[0])
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(auditor.files[0], "_series.json"))
+	assert.Equal(t, "{\n  \"series\": [\n    {\n      \"metric\": \"foo\"\n    }\n  ]\n}", string(data))
+
+	data, err = ioutil.ReadFile(auditor.files[1])
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(auditor.files[1], "_orchestrator.bin"))
+	assert.Equal(t, []byte{0x0a, 0x03}, data)
+
+	// the oldest file is rotated out
+	oldest := auditor.files[0]
+	auditor.audit("events", []byte(`{"events":`))
+	require.Len(t, auditor.files, 2)
+	_, err = os.Stat(oldest)
+	assert.True(t, os.IsNotExist(err))
+	// the payload is not valid JSON, it is written as is
+	assert.True(t, strings.HasSuffix(auditor.files[1], "_events.bin"))
+
+	// the existing files are rotated on start
+	auditor, err = newPayloadAuditor(dir, 1)
+	require.NoError(t, err)
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Equal(t, auditor.files, files)
+	assert.Len(t, files, 1)
+}
+
+func TestNilPayloadAuditor(t *testing.T) {
+	var auditor *payloadAuditor
+	// does nothing
+	auditor.audit("series", []byte("{}"))
+	auditor.auditCompressed("series", []byte("{}"))
+}
@@ -85,6 +85,10 @@ type Serializer struct {
 	enableServiceChecks  bool
 	enableSketches       bool
 	enableJSONToV1Intake bool
+
+	// auditor writes a copy of the payloads to the disk when
+	// serializer_audit_enabled is set, nil otherwise
+	auditor *payloadAuditor
 }
 
 // NewSerializer returns a new Serializer initialized
@@ -98,6 +102,23 @@ func NewSerializer(forwarder forwarder.Forwarder) *Serializer {
 		enableJSONToV1Intake: config.Datadog.GetBool("enable_payloads.json_to_v1_intake"),
 	}
 
+	if kind := config.Datadog.GetString("serializer_compressor_kind"); kind != "" {
+		if err := compression.Configure(kind); err != nil {
+			log.Errorf("Could not configure the compression of the payloads, keeping the default one: %s", err)
+		}
+		initExtraHeaders()
+	}
+
+	if config.Datadog.GetBool("serializer_audit_enabled") {
+		auditor, err := newPayloadAuditor(config.Datadog.GetString("serializer_audit_path"), config.Datadog.GetInt("serializer_audit_max_files"))
+		if err != nil {
+			log.Errorf("Could not enable the payloads audit: %s", err)
+		} else {
+			log.Warnf("The payloads audit is enabled: a copy of every payload is written to %s", auditor.path)
+			s.auditor = auditor
+		}
+	}
+
 	if !s.enableEvents {
 		log.Warn("event payloads are disabled: all events will be dropped")
 	}
@@ -117,7 +138,9 @@ func NewSerializer(forwarder forwarder.Forwarder) *Serializer {
 	return s
 }
 
-func (s Serializer) serializePayload(payload marshaler.Marshaler, compress bool, useV1API bool) (forwarder.Payloads, http.Header, error) {
+// serializePayload splits and serializes the payload, the resulting payloads
+// are audited before their compression, under the kind name
+func (s Serializer) serializePayload(kind string, payload marshaler.Marshaler, compress bool, useV1API bool) (forwarder.Payloads, http.Header, error) {
 	var marshalType split.MarshalType
 	var extraHeaders http.Header
 
@@ -142,10 +165,23 @@ func (s Serializer) serializePayload(payload marshaler.Marshaler, compress bool,
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not split payload into small enough chunks: %s", err)
 	}
+	for _, p := range payloads {
+		if compress {
+			s.auditor.auditCompressed(kind, *p)
+		} else {
+			s.auditor.audit(kind, *p)
+		}
+	}
 
 	return payloads, extraHeaders, nil
 }
 
+// UpdateForwarderCongestion updates the congestion of each domain of the
+// forwarder and returns whether the transactions to all of them pile up
+func (s *Serializer) UpdateForwarderCongestion() bool {
+	return s.Forwarder.UpdateCongestion()
+}
+
 // SendEvents serializes a list of event and sends the payload to the forwarder
 func (s *Serializer) SendEvents(e marshaler.Marshaler) error {
 	if !s.enableEvents {
@@ -156,7 +192,7 @@ func (s *Serializer) SendEvents(e marshaler.Marshaler) error {
 	useV1API := !config.Datadog.GetBool("use_v2_api.events")
 
 	compress := true
-	eventPayloads, extraHeaders, err := s.serializePayload(e, compress, useV1API)
+	eventPayloads, extraHeaders, err := s.serializePayload("events", e, compress, useV1API)
 	if err != nil {
 		return fmt.Errorf("dropping event payload: %s", err)
 	}
@@ -177,7 +213,7 @@ func (s *Serializer) SendServiceChecks(sc marshaler.Marshaler) error {
 	useV1API := !config.Datadog.GetBool("use_v2_api.service_checks")
 
 	compress := true
-	serviceCheckPayloads, extraHeaders, err := s.serializePayload(sc, compress, useV1API)
+	serviceCheckPayloads, extraHeaders, err := s.serializePayload("service_checks", sc, compress, useV1API)
 	if err != nil {
 		return fmt.Errorf("dropping service check payload: %s", err)
 	}
@@ -198,7 +234,7 @@ func (s *Serializer) SendSeries(series marshaler.Marshaler) error {
 	useV1API := !config.Datadog.GetBool("use_v2_api.series")
 
 	compress := true
-	seriesPayloads, extraHeaders, err := s.serializePayload(series, compress, useV1API)
+	seriesPayloads, extraHeaders, err := s.serializePayload("series", series, compress, useV1API)
 	if err != nil {
 		return fmt.Errorf("dropping series payload: %s", err)
 	}
@@ -218,7 +254,7 @@ func (s *Serializer) SendSketch(sketches marshaler.Marshaler) error {
 
 	compress := false // TODO: enable compression once the backend supports it on this endpoint
 	useV1API := false // Sketches only have a v2 endpoint
-	splitSketches, extraHeaders, err := s.serializePayload(sketches, compress, useV1API)
+	splitSketches, extraHeaders, err := s.serializePayload("sketches", sketches, compress, useV1API)
 	if err != nil {
 		return fmt.Errorf("dropping sketch payload: %s", err)
 	}
@@ -234,6 +270,7 @@ func (s *Serializer) SendMetadata(m marshaler.Marshaler) error {
 	} else if !smallEnough {
 		return fmt.Errorf("metadata payload was too big to send, metadata payloads cannot be split")
 	}
+	s.auditor.audit("metadata", payload)
 
 	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&payload}, jsonExtraHeaders); err != nil {
 		return err
@@ -244,6 +281,21 @@ func (s *Serializer) SendMetadata(m marshaler.Marshaler) error {
 	return nil
 }
 
+// SendOrchestratorManifests compresses the protobuf payloads of Kubernetes resource
+// manifests and sends them to the forwarder. The payloads are already chunked.
+func (s *Serializer) SendOrchestratorManifests(payloads [][]byte) error {
+	compressed := make(forwarder.Payloads, 0, len(payloads))
+	for _, payload := range payloads {
+		s.auditor.audit("orchestrator", payload)
+		c, err := compression.Compress(nil, payload)
+		if err != nil {
+			return fmt.Errorf("could not compress orchestrator payload: %s", err)
+		}
+		compressed = append(compressed, &c)
+	}
+	return s.Forwarder.SubmitOrchestratorManifests(compressed, protobufExtraHeadersWithCompression)
+}
+
 // SendJSONToV1Intake serializes a payload and sends it to the forwarder. Some code sends
 // arbitrary payload the v1 API.
 func (s *Serializer) SendJSONToV1Intake(data interface{}) error {
@@ -256,6 +308,7 @@ func (s *Serializer) SendJSONToV1Intake(data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("could not serialize v1 payload: %s", err)
 	}
+	s.auditor.audit("v1_intake", payload)
 	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&payload}, jsonExtraHeaders); err != nil {
 		return err
 	}
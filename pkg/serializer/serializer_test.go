@@ -266,6 +266,19 @@ func TestSendMetadata(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestSendOrchestratorManifests(t *testing.T) {
+	f := &forwarder.MockedForwarder{}
+	payload := []byte("manifests")
+	payloads, _ := mkPayloads(payload, true)
+	f.On("SubmitOrchestratorManifests", payloads, protobufExtraHeadersWithCompression).Return(nil).Times(1)
+
+	s := NewSerializer(f)
+
+	err := s.SendOrchestratorManifests([][]byte{payload})
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+}
+
 func TestSendJSONToV1Intake(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	payload := []byte("\"test\"")
@@ -18,6 +18,10 @@ import (
 // the backend accepts payloads up to 3MB, but being conservative is okay
 var maxPayloadSize = 2 * 1024 * 1024
 
+// maxSplitLoops is the number of times the chunks still too big are split
+// again before being dropped
+var maxSplitLoops = 3
+
 // MarshalType is the type of marshaler to use
 type MarshalType int
 
@@ -32,13 +36,14 @@ var (
 	splitterNotTooBig  = expvar.Int{}
 	splitterTooBig     = expvar.Int{}
 	splitterTotalLoops = expvar.Int{}
+	splitterDrops      = expvar.Int{}
 )
 
 func init() {
 	splitterExpvars.Set("NotTooBig", &splitterNotTooBig)
 	splitterExpvars.Set("TooBig", &splitterTooBig)
 	splitterExpvars.Set("TotalLoops", &splitterTotalLoops)
-
+	splitterExpvars.Set("PayloadDrops", &splitterDrops)
 }
 
 // CheckSizeAndSerialize Check the size of a payload and marshall it (optionally compress it)
@@ -51,7 +56,10 @@ func CheckSizeAndSerialize(m marshaler.Marshaler, compress bool, mType MarshalTy
 	return checkSize(payload), payload, nil
 }
 
-// Payloads serializes a metadata payload and sends it to the forwarder
+// Payloads serializes a payload, splitting it in chunks smaller than the
+// maximum payload size if needed. The chunks that can't be split further,
+// e.g. a single item bigger than the maximum size, are dropped, the others
+// are returned.
 func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarder.Payloads, error) {
 	marshallers := []marshaler.Marshaler{m}
 	smallEnoughPayloads := forwarder.Payloads{}
@@ -71,16 +79,15 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 	loops := 0
 	// Do not attempt to split payloads forever, if a payload cannot be split then abandon the task
 	// the function will return all the payloads that were able to be split
-	for toobig && loops < 3 {
+	for toobig && loops < maxSplitLoops {
 		splitterTotalLoops.Add(1)
 		// create a temporary slice, the other array will be reused to keep track of the payloads that have yet to be split
 		tempSlice := make([]marshaler.Marshaler, len(marshallers))
 		copy(tempSlice, marshallers)
 		marshallers = []marshaler.Marshaler{}
 		for _, toSplit := range tempSlice {
-			var e error
 			// we have to do this every time to get the proper payload
-			payload, compressedPayload, e := serializeMarshaller(toSplit, compress, mType)
+			compressedPayload, payload, e := serializeMarshaller(toSplit, compress, mType)
 			if e != nil {
 				return smallEnoughPayloads, e
 			}
@@ -90,18 +97,27 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 			// This is the same function used in dd-agent
 			compressionRatio := float64(payloadSize) / float64(compressedSize)
 			numChunks := compressedSize/maxPayloadSize + 1 + int(compressionRatio/2)
-			log.Debugf("split the payload into into %f chunks", numChunks)
+			log.Debugf("split the payload into %d chunks", numChunks)
 			chunks, err := toSplit.SplitPayload(numChunks)
-			log.Debugf("payload was split into %f chunks", len(chunks))
 			if err != nil {
-				return smallEnoughPayloads, err
+				log.Warnf("Dropping a payload of %d bytes that can't be split: %s", compressedSize, err)
+				splitterDrops.Add(1)
+				continue
+			}
+			log.Debugf("payload was split into %d chunks", len(chunks))
+			if len(chunks) == 1 {
+				// a single item, it can't be split further
+				log.Warnf("Dropping a payload of %d bytes that can't be split, it's bigger than the maximum payload size of %d bytes", compressedSize, maxPayloadSize)
+				splitterDrops.Add(1)
+				continue
 			}
 			// after the payload has been split, loop through the chunks
 			for _, chunk := range chunks {
 				// serialize the payload
 				smallEnough, payload, err := CheckSizeAndSerialize(chunk, compress, mType)
 				if err != nil {
-					log.Debugf("Error serializing a chunk: %s", err)
+					log.Warnf("Dropping a chunk that can't be serialized: %s", err)
+					splitterDrops.Add(1)
 					continue
 				}
 				if smallEnough {
@@ -124,6 +140,11 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 		}
 	}
 
+	if len(marshallers) > 0 {
+		log.Warnf("Dropping %d chunks still bigger than the maximum payload size after %d splits", len(marshallers), maxSplitLoops)
+		splitterDrops.Add(int64(len(marshallers)))
+	}
+
 	return smallEnoughPayloads, nil
 }
 
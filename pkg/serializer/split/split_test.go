@@ -8,9 +8,11 @@ package split
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -126,3 +128,65 @@ func TestSplitPayloadsServiceChecks(t *testing.T) {
 	newLength := len(testServiceChecks)
 	require.Equal(t, originalLength, newLength)
 }
+
+func TestSplitPayloadsDropsIrreducibleItems(t *testing.T) {
+	defer func(size int) { maxPayloadSize = size }(maxPayloadSize)
+	maxPayloadSize = 1000
+
+	testEvent := metrics.Events{}
+	for i := 0; i < 20; i++ {
+		testEvent = append(testEvent, &metrics.Event{
+			Title:          "test title",
+			Text:           "test text",
+			Host:           "test.localhost",
+			SourceTypeName: "test source",
+		})
+	}
+	// an event bigger than the maximum payload size on its own
+	testEvent = append(testEvent, &metrics.Event{
+		Title:          "big event",
+		Text:           strings.Repeat("a", 2*maxPayloadSize),
+		Host:           "test.localhost",
+		SourceTypeName: "test source",
+	})
+
+	drops := splitterDrops.Value()
+	payloads, err := Payloads(testEvent, false, MarshalJSON)
+	require.Nil(t, err)
+	assert.Equal(t, drops+1, splitterDrops.Value())
+
+	nbEvents := 0
+	for _, payload := range payloads {
+		assert.True(t, len(*payload) < maxPayloadSize)
+		var s map[string]interface{}
+		err = json.Unmarshal(*payload, &s)
+		require.Nil(t, err)
+		for _, events := range s["events"].(map[string]interface{}) {
+			nbEvents += len(events.([]interface{}))
+		}
+	}
+	assert.Equal(t, 20, nbEvents)
+}
+
+func TestSplitPayloadsSingleMetric(t *testing.T) {
+	defer func(size int) { maxPayloadSize = size }(maxPayloadSize)
+	maxPayloadSize = 1000
+
+	testSeries := metrics.Series{}
+	for i := 0; i < 50; i++ {
+		testSeries = append(testSeries, &metrics.Serie{
+			Points: []metrics.Point{{Ts: 12345.0, Value: float64(i)}},
+			MType:  metrics.APIGaugeType,
+			Name:   "test.metric",
+			Host:   "localHost",
+			Tags:   []string{fmt.Sprintf("tag:%d", i)},
+		})
+	}
+
+	// the series of a metric are not split across payloads
+	drops := splitterDrops.Value()
+	payloads, err := Payloads(testSeries, false, MarshalJSON)
+	require.Nil(t, err)
+	assert.Len(t, payloads, 0)
+	assert.Equal(t, drops+1, splitterDrops.Value())
+}
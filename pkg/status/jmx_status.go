@@ -14,19 +14,34 @@ type jmxCheckStatus struct {
 	FailedChecks      map[string]interface{} `json:"failed_checks"`
 }
 
+// JMXProcessStatus holds the state of the JMXFetch process, as seen by the
+// agent supervising it
+type JMXProcessStatus struct {
+	Running   bool   `json:"running"`
+	Restarts  int    `json:"restarts"`
+	LastStart int64  `json:"last_start"`
+	LastError string `json:"last_error"`
+}
+
 // JMXStatus holds status for JMX checks
 type JMXStatus struct {
-	ChecksStatus jmxCheckStatus `json:"checks"`
-	Timestamp    int64          `json:"timestamp"`
+	ChecksStatus   jmxCheckStatus   `json:"checks"`
+	Timestamp      int64            `json:"timestamp"`
+	InstanceCount  int              `json:"instance_count"`   // computed from the initialized checks
+	BeanMatchCount int              `json:"bean_match_count"` // computed from the initialized checks
+	Process        JMXProcessStatus `json:"process"`
 }
 
 var (
-	lastJMXStatus JMXStatus
-	m             sync.RWMutex
+	lastJMXStatus        JMXStatus
+	lastJMXProcessStatus JMXProcessStatus
+	m                    sync.RWMutex
 )
 
 // SetJMXStatus sets the last JMX Status
 func SetJMXStatus(s JMXStatus) {
+	s.InstanceCount, s.BeanMatchCount = countJMXInstances(s.ChecksStatus.InitializedChecks)
+
 	m.Lock()
 	defer m.Unlock()
 
@@ -38,5 +53,38 @@ func GetJMXStatus() JMXStatus {
 	m.RLock()
 	defer m.RUnlock()
 
-	return lastJMXStatus
+	s := lastJMXStatus
+	s.Process = lastJMXProcessStatus
+	return s
+}
+
+// SetJMXProcessStatus sets the state of the JMXFetch process
+func SetJMXProcessStatus(s JMXProcessStatus) {
+	m.Lock()
+	defer m.Unlock()
+
+	lastJMXProcessStatus = s
+}
+
+// countJMXInstances returns the number of instances initialized by JMXFetch
+// and the number of bean attributes they matched, that JMXFetch reports as the
+// metric_count of each instance
+func countJMXInstances(checks map[string]interface{}) (instances int, beans int) {
+	for _, checkInstances := range checks {
+		list, ok := checkInstances.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, instance := range list {
+			instances++
+			fields, ok := instance.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if count, ok := fields["metric_count"].(float64); ok {
+				beans += int(count)
+			}
+		}
+	}
+	return instances, beans
 }
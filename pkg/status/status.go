@@ -129,6 +129,9 @@ func GetDCAStatus() (map[string]interface{}, error) {
 	stats["time"] = now.Format(timeFormat)
 	stats["leaderelection"] = getLeaderElectionDetails()
 	stats["hpaExternal"] = GetHorizontalPodAutoscalingStatus()
+	stats["metadataMapper"] = getMetadataMapperStats()
+	stats["apiserver"] = getAPIServerStatus()
+	stats["clusterChecks"] = getClusterChecksStats()
 
 	return stats, nil
 }
@@ -9,6 +9,7 @@ package status
 
 import (
 	"fmt"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/custommetrics"
 	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
@@ -33,6 +34,42 @@ func getLeaderElectionDetails() map[string]string {
 	return leaderElectionStats
 }
 
+func getMetadataMapperStats() map[string]interface{} {
+	return apiserver.GetMetadataMapperStats()
+}
+
+// getAPIServerStatus checks the connectivity to the apiserver and reports its version
+func getAPIServerStatus() map[string]string {
+	apiServerStatus := make(map[string]string)
+
+	apiCl, err := apiserver.GetAPIClient()
+	if err != nil {
+		apiServerStatus["status"] = "Failing"
+		apiServerStatus["error"] = err.Error()
+		return apiServerStatus
+	}
+	start := time.Now()
+	serverVersion, err := apiCl.Client().Discovery().ServerVersion()
+	if err != nil {
+		apiServerStatus["status"] = "Failing"
+		apiServerStatus["error"] = err.Error()
+		return apiServerStatus
+	}
+	apiServerStatus["status"] = "Connected"
+	apiServerStatus["version"] = serverVersion.String()
+	apiServerStatus["latency"] = time.Since(start).String()
+	return apiServerStatus
+}
+
+// getClusterChecksStats returns the distribution of the cluster checks, nil if they are not enabled
+func getClusterChecksStats() interface{} {
+	dispatcher := clusterchecks.GetDispatcher()
+	if dispatcher == nil {
+		return nil
+	}
+	return dispatcher.GetStats()
+}
+
 func getDCAStatus() map[string]string {
 	clusterAgentDetails := make(map[string]string)
 
@@ -65,7 +102,11 @@ func GetHorizontalPodAutoscalingStatus() map[string]interface{} {
 	datadogHPAConfigMap := custommetrics.GetHPAConfigmapName()
 	horizontalPodAutoscalingStatus["Cmname"] = datadogHPAConfigMap
 
-	store, err := custommetrics.NewConfigMapStore(apiCl.Cl, apiserver.GetResourcesNamespace(), datadogHPAConfigMap)
+	store, err := custommetrics.NewConfigMapStore(apiCl.Client, apiserver.GetResourcesNamespace(), datadogHPAConfigMap)
+	if err != nil {
+		horizontalPodAutoscalingStatus["ErrorStore"] = err.Error()
+		return horizontalPodAutoscalingStatus
+	}
 	externalMetrics, err := store.ListAllExternalMetricValues()
 	if err != nil {
 		horizontalPodAutoscalingStatus["ErrorStore"] = err.Error()
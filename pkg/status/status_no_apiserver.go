@@ -16,6 +16,21 @@ func getLeaderElectionDetails() map[string]string {
 	return nil
 }
 
+func getMetadataMapperStats() map[string]interface{} {
+	log.Info("Not implemented")
+	return nil
+}
+
+func getAPIServerStatus() map[string]string {
+	log.Info("Not implemented")
+	return nil
+}
+
+func getClusterChecksStats() interface{} {
+	log.Info("Not implemented")
+	return nil
+}
+
 func getDCAStatus() map[string]string {
 	log.Info("Not implemented")
 	return nil
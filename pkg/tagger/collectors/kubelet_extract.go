@@ -53,6 +53,9 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 		// OpenShift pod annotations
 		if dc_name, found := pod.Metadata.Annotations["openshift.io/deployment-config.name"]; found {
 			tags.AddLow("oshift_deployment_config", dc_name)
+		} else if dc_name, found := pod.Metadata.Labels["deploymentconfig"]; found {
+			// Older OpenShift versions only set the deploymentconfig label
+			tags.AddLow("oshift_deployment_config", dc_name)
 		}
 		if deploy_name, found := pod.Metadata.Annotations["openshift.io/deployment.name"]; found {
 			tags.AddHigh("oshift_deployment", deploy_name)
@@ -283,6 +283,24 @@ func TestParsePods(t *testing.T) {
 				HighCardTags: []string{"oshift_deployment:gitlab-ce-1"},
 			},
 		},
+		{
+			desc: "openshift deploymentconfig label",
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Labels: map[string]string{
+						"deploymentconfig": "gitlab-ce",
+					},
+				},
+				Status: dockerContainerStatus,
+			},
+			labelsAsTags: map[string]string{},
+			expectedInfo: &TagInfo{
+				Source:       "kubelet",
+				Entity:       dockerEntityID,
+				LowCardTags:  []string{"kube_container_name:dd-agent", "oshift_deployment_config:gitlab-ce"},
+				HighCardTags: []string{},
+			},
+		},
 		{
 			desc: "CRI pod",
 			pod: &kubelet.Pod{
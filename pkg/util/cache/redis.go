@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package cache
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const redisTimeout = 2 * time.Second
+
+// RedisBackend is a Backend storing the entries in Redis. It holds a single
+// connection, opened on the first command and reopened after a network error.
+// It only implements the GET, SET, DEL, AUTH and SELECT commands, against a
+// single server: Redis Cluster and Sentinel are not supported.
+type RedisBackend struct {
+	address   string
+	password  string
+	db        int
+	keyPrefix string
+	tlsConfig *tls.Config
+
+	m      sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisError is an error reply of the server, the connection is still usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// NewRedisBackend returns a RedisBackend, the keys are prefixed by keyPrefix
+// for several clusters to share the same server. The connection is encrypted
+// when tlsConfig is not nil.
+func NewRedisBackend(address, password string, db int, keyPrefix string, tlsConfig *tls.Config) *RedisBackend {
+	return &RedisBackend{
+		address:   address,
+		password:  password,
+		db:        db,
+		keyPrefix: keyPrefix,
+		tlsConfig: tlsConfig,
+	}
+}
+
+// Get implements Backend
+func (r *RedisBackend) Get(key string) ([]byte, bool, error) {
+	reply, err := r.do("GET", r.keyPrefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected reply to GET: %v", reply)
+	}
+	return value, true, nil
+}
+
+// Set implements Backend, a ttl lower than a millisecond means no expiration
+func (r *RedisBackend) Set(key string, value []byte, ttl time.Duration) error {
+	args := []interface{}{"SET", r.keyPrefix + key, value}
+	if ms := int64(ttl / time.Millisecond); ms > 0 {
+		args = append(args, "PX", strconv.FormatInt(ms, 10))
+	}
+	_, err := r.do(args...)
+	return err
+}
+
+// Delete implements Backend
+func (r *RedisBackend) Delete(key string) error {
+	_, err := r.do("DEL", r.keyPrefix+key)
+	return err
+}
+
+// do sends a command and returns its reply, reconnecting if needed.
+func (r *RedisBackend) do(args ...interface{}) (interface{}, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.conn == nil {
+		if err := r.connect(); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := r.roundTrip(args...)
+	if err != nil {
+		if _, ok := err.(redisError); !ok {
+			r.close()
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// connect opens the connection, authenticates and selects the database.
+// The lock must be held.
+func (r *RedisBackend) connect() error {
+	var conn net.Conn
+	var err error
+	if r.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: redisTimeout}, "tcp", r.address, r.tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", r.address, redisTimeout)
+	}
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+	r.reader = bufio.NewReader(conn)
+
+	if r.password != "" {
+		if _, err = r.roundTrip("AUTH", r.password); err != nil {
+			r.close()
+			return fmt.Errorf("could not authenticate to redis: %s", err)
+		}
+	}
+	if r.db != 0 {
+		if _, err = r.roundTrip("SELECT", strconv.Itoa(r.db)); err != nil {
+			r.close()
+			return fmt.Errorf("could not select the redis database %d: %s", r.db, err)
+		}
+	}
+	return nil
+}
+
+// close closes the connection, the lock must be held.
+func (r *RedisBackend) close() {
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	r.conn = nil
+	r.reader = nil
+}
+
+func (r *RedisBackend) roundTrip(args ...interface{}) (interface{}, error) {
+	r.conn.SetDeadline(time.Now().Add(redisTimeout))
+	if _, err := r.conn.Write(encodeRedisCommand(args...)); err != nil {
+		return nil, err
+	}
+	return readRedisReply(r.reader)
+}
+
+// encodeRedisCommand encodes the arguments as an array of bulk strings.
+func encodeRedisCommand(args ...interface{}) []byte {
+	buf := []byte(fmt.Sprintf("*%d\r\n", len(args)))
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case []byte:
+			b = v
+		case string:
+			b = []byte(v)
+		default:
+			b = []byte(fmt.Sprint(v))
+		}
+		buf = append(buf, fmt.Sprintf("$%d\r\n", len(b))...)
+		buf = append(buf, b...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+// readRedisReply reads a reply: a string, an int64, a []byte, nil for a
+// missing value, a []interface{} or a redisError.
+func readRedisReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("malformed redis reply")
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, b); err != nil {
+			return nil, err
+		}
+		return b[:size], nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, size)
+		for i := range values {
+			if values[i], err = readRedisReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown redis reply type %q", line[0])
+	}
+}
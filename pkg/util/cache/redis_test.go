@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package cache
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis serves GET, SET, DEL and AUTH from a map, ignoring the expiration
+type fakeRedis struct {
+	listener net.Listener
+	password string
+	m        sync.Mutex
+	data     map[string][]byte
+	commands []string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeRedis{listener: listener, password: password, data: make(map[string][]byte)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	for {
+		reply, err := readRedisReply(reader)
+		if err != nil {
+			return
+		}
+		args := reply.([]interface{})
+		cmd := string(args[0].([]byte))
+		s.m.Lock()
+		s.commands = append(s.commands, cmd)
+		switch {
+		case cmd == "AUTH":
+			authenticated = string(args[1].([]byte)) == s.password
+			if authenticated {
+				conn.Write([]byte("+OK\r\n"))
+			} else {
+				conn.Write([]byte("-ERR invalid password\r\n"))
+			}
+		case !authenticated:
+			conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+		case cmd == "GET":
+			if v, found := s.data[string(args[1].([]byte))]; found {
+				conn.Write(encodeRedisCommand(v)[4:])
+			} else {
+				conn.Write([]byte("$-1\r\n"))
+			}
+		case cmd == "SET":
+			s.data[string(args[1].([]byte))] = args[2].([]byte)
+			conn.Write([]byte("+OK\r\n"))
+		case cmd == "DEL":
+			delete(s.data, string(args[1].([]byte)))
+			conn.Write([]byte(":1\r\n"))
+		default:
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+		s.m.Unlock()
+	}
+}
+
+func TestRedisBackend(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	defer server.listener.Close()
+
+	backend := NewRedisBackend(server.listener.Addr().String(), "secret", 0, "dca/", nil)
+	_, found, err := backend.Get("foo")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, backend.Set("foo", []byte("bar\r\nbaz"), time.Minute))
+	value, found, err := backend.Get("foo")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("bar\r\nbaz"), value)
+	server.m.Lock()
+	assert.Contains(t, server.data, "dca/foo")
+	server.m.Unlock()
+
+	require.NoError(t, backend.Delete("foo"))
+	_, found, err = backend.Get("foo")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Authenticated once on the single connection
+	server.m.Lock()
+	defer server.m.Unlock()
+	assert.Equal(t, []string{"AUTH", "GET", "SET", "GET", "DEL", "GET"}, server.commands)
+}
+
+func TestRedisBackendErrors(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	defer server.listener.Close()
+
+	backend := NewRedisBackend(server.listener.Addr().String(), "wrong", 0, "", nil)
+	_, _, err := backend.Get("foo")
+	assert.Error(t, err)
+
+	// Reconnects after a network error
+	backend = NewRedisBackend(server.listener.Addr().String(), "secret", 0, "", nil)
+	require.NoError(t, backend.Set("foo", []byte("bar"), 0))
+	backend.conn.Close()
+	_, _, err = backend.Get("foo")
+	assert.Error(t, err)
+	value, found, err := backend.Get("foo")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("bar"), value)
+}
+
+func TestEncodeRedisCommand(t *testing.T) {
+	assert.Equal(t, "*3\r\n$3\r\nSET\r\n$3\r\nfoo\r\n$0\r\n\r\n", string(encodeRedisCommand("SET", "foo", []byte{})))
+}
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package cache
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Backend is an external key-value store, shared by the cluster agent replicas
+// and surviving their restarts. The values are stored JSON-encoded.
+type Backend interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+var (
+	sharedBackend      Backend
+	sharedBackendMutex sync.RWMutex
+
+	// sharedRefreshes are the keys being refreshed from the shared backend
+	sharedRefreshes      = make(map[string]bool)
+	sharedRefreshesMutex sync.Mutex
+)
+
+// SetSharedBackend sets the backend of the shared entries, nil to only use the in-process Cache.
+func SetSharedBackend(b Backend) {
+	sharedBackendMutex.Lock()
+	defer sharedBackendMutex.Unlock()
+	sharedBackend = b
+}
+
+func getSharedBackend() Backend {
+	sharedBackendMutex.RLock()
+	defer sharedBackendMutex.RUnlock()
+	return sharedBackend
+}
+
+// SetShared stores the value in the in-process Cache and in the shared backend, if any.
+func SetShared(key string, value interface{}, ttl time.Duration) {
+	Cache.Set(key, value, ttl)
+
+	backend := getSharedBackend()
+	if backend == nil {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		log.Debugf("Could not encode the shared cache entry %s: %s", key, err)
+		return
+	}
+	if ttl == 0 {
+		ttl = defaultExpire
+	}
+	if err = backend.Set(key, raw, ttl); err != nil {
+		log.Debugf("Could not store the shared cache entry %s: %s", key, err)
+	}
+}
+
+// GetShared returns the value from the in-process Cache, refreshed in the
+// background from the shared backend, if any, as it holds the freshest state
+// of the replicas. When the value is not in the in-process Cache, it is read
+// from the backend and decoded by decode. The values read from the backend
+// are cached for ttl, the one they were stored with by SetShared.
+func GetShared(key string, ttl time.Duration, decode func([]byte) (interface{}, error)) (interface{}, bool) {
+	backend := getSharedBackend()
+	if value, found := Cache.Get(key); found {
+		if backend != nil {
+			refreshShared(backend, key, ttl, decode)
+		}
+		return value, true
+	}
+	if backend == nil {
+		return nil, false
+	}
+	value, found, err := getFromBackend(backend, key, decode)
+	if err != nil || !found {
+		return nil, false
+	}
+	Cache.Set(key, value, ttl)
+	return value, true
+}
+
+// refreshShared updates the in-process Cache entry from the shared backend in
+// a goroutine, not to wait for the backend when the value is cached already.
+// An entry deleted from the backend is deleted from the in-process Cache, it
+// is kept on errors.
+func refreshShared(backend Backend, key string, ttl time.Duration, decode func([]byte) (interface{}, error)) {
+	sharedRefreshesMutex.Lock()
+	defer sharedRefreshesMutex.Unlock()
+	if sharedRefreshes[key] {
+		return
+	}
+	sharedRefreshes[key] = true
+
+	go func() {
+		defer func() {
+			sharedRefreshesMutex.Lock()
+			delete(sharedRefreshes, key)
+			sharedRefreshesMutex.Unlock()
+		}()
+		value, found, err := getFromBackend(backend, key, decode)
+		switch {
+		case err != nil:
+			return
+		case found:
+			Cache.Set(key, value, ttl)
+		default:
+			Cache.Delete(key)
+		}
+	}()
+}
+
+// getFromBackend returns the decoded value of the shared backend
+func getFromBackend(backend Backend, key string, decode func([]byte) (interface{}, error)) (interface{}, bool, error) {
+	raw, found, err := backend.Get(key)
+	if err != nil {
+		log.Debugf("Could not read the shared cache entry %s: %s", key, err)
+		return nil, false, err
+	}
+	if !found {
+		return nil, false, nil
+	}
+	value, err := decode(raw)
+	if err != nil {
+		log.Debugf("Could not decode the shared cache entry %s: %s", key, err)
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// DeleteShared removes the entry from the in-process Cache and from the shared backend, if any.
+func DeleteShared(key string) {
+	Cache.Delete(key)
+	if backend := getSharedBackend(); backend != nil {
+		if err := backend.Delete(key); err != nil {
+			log.Debugf("Could not delete the shared cache entry %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapBackend struct {
+	sync.Mutex
+	data map[string][]byte
+	err  error
+}
+
+func (b *mapBackend) Get(key string) ([]byte, bool, error) {
+	b.Lock()
+	defer b.Unlock()
+	v, found := b.data[key]
+	return v, found, b.err
+}
+
+func (b *mapBackend) Set(key string, value []byte, ttl time.Duration) error {
+	b.Lock()
+	defer b.Unlock()
+	b.data[key] = value
+	return b.err
+}
+
+func (b *mapBackend) Delete(key string) error {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.data, key)
+	return b.err
+}
+
+func (b *mapBackend) set(key string, value []byte, err error) {
+	b.Lock()
+	defer b.Unlock()
+	b.data[key] = value
+	b.err = err
+}
+
+type sharedValue struct {
+	Name string
+}
+
+func decodeSharedValue(raw []byte) (interface{}, error) {
+	v := &sharedValue{}
+	err := json.Unmarshal(raw, v)
+	return v, err
+}
+
+// waitForSharedRefreshes waits for the background refreshes to be done
+func waitForSharedRefreshes(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		sharedRefreshesMutex.Lock()
+		pending := len(sharedRefreshes)
+		sharedRefreshesMutex.Unlock()
+		if pending == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the shared cache entries were not refreshed")
+}
+
+func TestSharedCache(t *testing.T) {
+	key := BuildAgentKey("shared", "test")
+	defer Cache.Delete(key)
+
+	// Without backend, the in-process cache is used
+	SetShared(key, &sharedValue{Name: "local"}, time.Minute)
+	v, found := GetShared(key, time.Minute, decodeSharedValue)
+	assert.True(t, found)
+	assert.Equal(t, &sharedValue{Name: "local"}, v)
+
+	backend := &mapBackend{data: make(map[string][]byte)}
+	SetSharedBackend(backend)
+	defer SetSharedBackend(nil)
+
+	SetShared(key, &sharedValue{Name: "shared"}, time.Minute)
+	assert.JSONEq(t, `{"Name":"shared"}`, string(backend.data[key]))
+
+	// Written by another replica, the cached value is returned until it is refreshed
+	backend.set(key, []byte(`{"Name":"replica"}`), nil)
+	v, found = GetShared(key, time.Minute, decodeSharedValue)
+	assert.True(t, found)
+	assert.Equal(t, &sharedValue{Name: "shared"}, v)
+	waitForSharedRefreshes(t)
+	v, found = GetShared(key, time.Minute, decodeSharedValue)
+	assert.True(t, found)
+	assert.Equal(t, &sharedValue{Name: "replica"}, v)
+	waitForSharedRefreshes(t)
+
+	// The cached value is kept on errors
+	backend.set(key, []byte(`not json`), nil)
+	GetShared(key, time.Minute, decodeSharedValue)
+	waitForSharedRefreshes(t)
+	backend.set(key, []byte(`{"Name":"replica"}`), errors.New("connection refused"))
+	GetShared(key, time.Minute, decodeSharedValue)
+	waitForSharedRefreshes(t)
+	v, found = GetShared(key, time.Minute, decodeSharedValue)
+	assert.True(t, found)
+	assert.Equal(t, &sharedValue{Name: "replica"}, v)
+	waitForSharedRefreshes(t)
+
+	// Read from the backend when not cached, by a replica restarting for instance
+	backend.set(key, []byte(`{"Name":"restarted"}`), nil)
+	Cache.Delete(key)
+	v, found = GetShared(key, time.Minute, decodeSharedValue)
+	assert.True(t, found)
+	assert.Equal(t, &sharedValue{Name: "restarted"}, v)
+	_, expiration, _ := Cache.GetWithExpiration(key)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiration, 5*time.Second)
+
+	DeleteShared(key)
+	_, found = GetShared(key, time.Minute, decodeSharedValue)
+	assert.False(t, found)
+	assert.NotContains(t, backend.data, key)
+}
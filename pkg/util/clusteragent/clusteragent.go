@@ -6,9 +6,11 @@
 package clusteragent
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -20,6 +22,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/api/security"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -82,13 +85,60 @@ func (c *DCAClient) init() error {
 	c.clusterAgentAPIRequestHeaders = http.Header{}
 	c.clusterAgentAPIRequestHeaders.Set(authorizationHeaderKey, fmt.Sprintf("Bearer %s", authToken))
 
-	// TODO remove insecure
-	c.clusterAgentAPIClient = util.GetClient(false)
+	c.clusterAgentAPIClient, err = getClusterAgentAPIClient()
+	if err != nil {
+		return err
+	}
 	c.clusterAgentAPIClient.Timeout = 2 * time.Second
 
 	return nil
 }
 
+// getClusterAgentAPIClient returns the http client to query the DCA.
+// The server certificate is verified if cluster_agent.tls.ca_file is set, and the client
+// certificate of cluster_agent.tls.client_cert_file is presented for mutual TLS. The files
+// are reloaded when rotated.
+func getClusterAgentAPIClient() (*http.Client, error) {
+	caFile := config.Datadog.GetString("cluster_agent.tls.ca_file")
+	certFile := config.Datadog.GetString("cluster_agent.tls.client_cert_file")
+	keyFile := config.Datadog.GetString("cluster_agent.tls.client_key_file")
+	if caFile == "" && certFile == "" {
+		// TODO remove insecure
+		return util.GetClient(false), nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if certFile != "" {
+		keyPair, err := security.NewKeyPairReloader(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = keyPair.GetClientCertificate
+	}
+	if caFile == "" {
+		tlsConfig.InsecureSkipVerify = true
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}, nil
+	}
+
+	rootCAs, err := security.NewCertPoolReloader(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the cluster agent CA file: %s", err)
+	}
+	// The connections are dialed with the current pool of CA certificates,
+	// TLSClientConfig being read once by the transport
+	transport := &http.Transport{
+		DialTLS: func(network, addr string) (net.Conn, error) {
+			connConfig := tlsConfig.Clone()
+			connConfig.RootCAs = rootCAs.Pool()
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				connConfig.ServerName = host
+			}
+			return tls.DialWithDialer(&net.Dialer{Timeout: 2 * time.Second}, network, addr, connConfig)
+		},
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
 // getClusterAgentEndpoint provides a validated https endpoint from configuration keys in datadog.yaml:
 // 1st. configuration key "cluster_agent.url", add the https prefix if the scheme isn't specified
 // 2nd. environment variables associated with "cluster_agent.kubernetes_service_name"
@@ -234,3 +284,115 @@ func (c *DCAClient) GetKubernetesMetadataNames(nodeName, ns, podName string) ([]
 
 	return metadataNames, nil
 }
+
+// GetNodeLabels queries the datadog cluster agent to get the labels of the node nodeName.
+func (c *DCAClient) GetNodeLabels(nodeName string) (map[string]string, error) {
+	const dcaNodeLabelsPath = "api/v1/tags/node"
+	var nodeLabels map[string]string
+	var err error
+
+	if c == nil {
+		return nil, fmt.Errorf("cluster agent's client is not properly initialized")
+	}
+
+	// https://host:port/api/v1/tags/node/{nodeName}
+	rawURL := fmt.Sprintf("%s/%s/%s", c.ClusterAgentAPIEndpoint, dcaNodeLabelsPath, nodeName)
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = c.clusterAgentAPIRequestHeaders
+
+	resp, err := c.clusterAgentAPIClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &nodeLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeLabels, nil
+}
+
+// GetClusterCheckConfigs queries the datadog cluster agent to get the cluster checks dispatched to the node nodeName.
+func (c *DCAClient) GetClusterCheckConfigs(nodeName string) (clusterchecks.ConfigResponse, error) {
+	const dcaClusterChecksPath = "api/v1/clusterchecks/configs"
+	var configs clusterchecks.ConfigResponse
+	var err error
+
+	if c == nil {
+		return configs, fmt.Errorf("cluster agent's client is not properly initialized")
+	}
+
+	// https://host:port/api/v1/clusterchecks/configs/{nodeName}
+	rawURL := fmt.Sprintf("%s/%s/%s", c.ClusterAgentAPIEndpoint, dcaClusterChecksPath, nodeName)
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return configs, err
+	}
+	req.Header = c.clusterAgentAPIRequestHeaders
+
+	resp, err := c.clusterAgentAPIClient.Do(req)
+	if err != nil {
+		return configs, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return configs, fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return configs, err
+	}
+	err = json.Unmarshal(b, &configs)
+	return configs, err
+}
+
+// GetClusterTemplates queries the datadog cluster agent to get the check templates it serves to the node agents.
+func (c *DCAClient) GetClusterTemplates() (clusterchecks.ConfigResponse, error) {
+	const dcaClusterTemplatesPath = "api/v1/clusterchecks/templates"
+	var configs clusterchecks.ConfigResponse
+	var err error
+
+	if c == nil {
+		return configs, fmt.Errorf("cluster agent's client is not properly initialized")
+	}
+
+	// https://host:port/api/v1/clusterchecks/templates
+	rawURL := fmt.Sprintf("%s/%s", c.ClusterAgentAPIEndpoint, dcaClusterTemplatesPath)
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return configs, err
+	}
+	req.Header = c.clusterAgentAPIRequestHeaders
+
+	resp, err := c.clusterAgentAPIClient.Do(req)
+	if err != nil {
+		return configs, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return configs, fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return configs, err
+	}
+	err = json.Unmarshal(b, &configs)
+	return configs, err
+}
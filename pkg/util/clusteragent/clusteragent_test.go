@@ -6,7 +6,10 @@
 package clusteragent
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -29,7 +32,8 @@ import (
 )
 
 type dummyClusterAgent struct {
-	responses map[string][]string
+	responses  map[string][]string
+	nodeLabels map[string]map[string]string
 	sync.RWMutex
 	token string
 }
@@ -44,6 +48,9 @@ func newDummyClusterAgent() (*dummyClusterAgent, error) {
 			"node2/bar/pod-00005": {"kube_service:svc3"},
 			"node2/bar/pod-00006": {},
 		},
+		nodeLabels: map[string]map[string]string{
+			"node1": {"topology.kubernetes.io/zone": "us-east-1a"},
+		},
 		token: config.Datadog.GetString("cluster_agent.auth_token"),
 	}
 	return dca, nil
@@ -58,6 +65,11 @@ func (d *dummyClusterAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v1/tags/node/") {
+		d.serveNodeLabels(w, r)
+		return
+	}
+
 	// path should be like: /api/v1/metadata/{nodeName}/{ns}/{pod-[0-9a-z]+}
 	s := strings.Split(r.URL.Path, "/")
 	if len(s) != 7 {
@@ -83,6 +95,24 @@ func (d *dummyClusterAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotFound)
 }
 
+func (d *dummyClusterAgent) serveNodeLabels(w http.ResponseWriter, r *http.Request) {
+	nodeName := strings.TrimPrefix(r.URL.Path, "/api/v1/tags/node/")
+
+	d.RLock()
+	defer d.RUnlock()
+	labels, found := d.nodeLabels[nodeName]
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	b, err := json.Marshal(labels)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Write(b)
+}
+
 func (d *dummyClusterAgent) parsePort(ts *httptest.Server) (*httptest.Server, int, error) {
 	u, err := url.Parse(ts.URL)
 	if err != nil {
@@ -313,6 +343,65 @@ func (suite *clusterAgentSuite) TestGetKubernetesMetadataNames() {
 	}
 }
 
+func (suite *clusterAgentSuite) TestGetNodeLabels() {
+	dca, err := newDummyClusterAgent()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	ts, p, err := dca.StartTLS()
+	defer ts.Close()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	config.Datadog.Set("cluster_agent.url", fmt.Sprintf("https://127.0.0.1:%d", p))
+	globalClusterAgentClient = nil
+
+	ca, err := GetClusterAgentClient()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	labels, err := ca.GetNodeLabels("node1")
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), map[string]string{"topology.kubernetes.io/zone": "us-east-1a"}, labels)
+
+	_, err = ca.GetNodeLabels("unknown")
+	require.NotNil(suite.T(), err)
+}
+
+func TestClusterAgentAPIClientCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dca-ca-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	newCert := func(caFile string) tls.Certificate {
+		_, certPEM, key, err := security.GenerateRootCert([]string{"127.0.0.1"}, 1024)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(caFile, certPEM, 0600))
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+		return cert
+	}
+	serverCA, otherCA := filepath.Join(dir, "server.pem"), filepath.Join(dir, "other.pem")
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{newCert(serverCA)}}
+	newCert(otherCA)
+	ts.StartTLS()
+	defer ts.Close()
+	defer config.Datadog.Set("cluster_agent.tls.ca_file", "")
+
+	// the server certificate is verified with the CA file
+	config.Datadog.Set("cluster_agent.tls.ca_file", serverCA)
+	client, err := getClusterAgentAPIClient()
+	require.NoError(t, err)
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	config.Datadog.Set("cluster_agent.tls.ca_file", otherCA)
+	client, err = getClusterAgentAPIClient()
+	require.NoError(t, err)
+	_, err = client.Get(ts.URL)
+	assert.Error(t, err)
+}
+
 func TestClusterAgentSuite(t *testing.T) {
 	clusterAgentAuthTokenFilename := "cluster_agent.auth_token"
 
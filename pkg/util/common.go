@@ -190,6 +190,13 @@ func GetProxyTransportFunc(p *config.Proxy) func(*http.Request) (*url.URL, error
 
 // CreateHTTPTransport creates an *http.Transport for use in the agent
 func CreateHTTPTransport() *http.Transport {
+	return CreateHTTPTransportWithProxies(config.GetProxies())
+}
+
+// CreateHTTPTransportWithProxies creates an *http.Transport for use in the
+// agent using the given proxies instead of the global ones, nil meaning no
+// proxy
+func CreateHTTPTransportWithProxies(proxies *config.Proxy) *http.Transport {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: config.Datadog.GetBool("skip_ssl_validation"),
 	}
@@ -202,7 +209,7 @@ func CreateHTTPTransport() *http.Transport {
 		TLSClientConfig: tlsConfig,
 	}
 
-	if proxies := config.GetProxies(); proxies != nil {
+	if proxies != nil {
 		transport.Proxy = GetProxyTransportFunc(proxies)
 	}
 	return transport
@@ -157,3 +157,15 @@ func TestCreateHTTPTransport(t *testing.T) {
 	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
 	assert.Equal(t, transport.TLSClientConfig.MinVersion, uint16(tls.VersionTLS12))
 }
+
+func TestCreateHTTPTransportWithProxies(t *testing.T) {
+	transport := CreateHTTPTransportWithProxies(nil)
+	assert.Nil(t, transport.Proxy)
+
+	transport = CreateHTTPTransportWithProxies(&config.Proxy{HTTPS: "https://proxy_https:3128"})
+	require.NotNil(t, transport.Proxy)
+	r, _ := http.NewRequest("GET", "https://test.com", nil)
+	proxyURL, err := transport.Proxy(r)
+	require.Nil(t, err)
+	assert.Equal(t, "https://proxy_https:3128", proxyURL.String())
+}
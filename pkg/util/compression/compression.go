@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build zlib zstd
+
+package compression
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ZlibKind is the name of the zlib compressor
+	ZlibKind = "zlib"
+	// ZstdKind is the name of the zstd compressor
+	ZstdKind = "zstd"
+)
+
+// ContentEncoding describes the HTTP header value associated with the compression method
+// var instead of const to ease testing
+var ContentEncoding = ""
+
+type compressor struct {
+	contentEncoding string
+	compress        func(dst []byte, src []byte) ([]byte, error)
+	decompress      func(dst []byte, src []byte) ([]byte, error)
+}
+
+var (
+	// compressors are the compressors built in the binary, by kind
+	compressors = make(map[string]compressor)
+	current     compressor
+)
+
+// register adds a compressor built in the binary, zlib being the default one
+func register(kind string, c compressor) {
+	compressors[kind] = c
+	if current.compress == nil || kind == ZlibKind {
+		current = c
+		ContentEncoding = c.contentEncoding
+	}
+}
+
+// Compressor is a compressor selected by kind, to compress the payloads of
+// an endpoint independently of the configured one.
+type Compressor struct {
+	compressor
+}
+
+// Configure selects the compressor of the payloads, by kind
+func Configure(kind string) error {
+	c, found := compressors[kind]
+	if !found {
+		return unknownCompressorError(kind)
+	}
+	current = c
+	ContentEncoding = c.contentEncoding
+	return nil
+}
+
+// Get returns the compressor of the given kind, the configured one if kind is empty
+func Get(kind string) (*Compressor, error) {
+	if kind == "" {
+		return &Compressor{current}, nil
+	}
+	c, found := compressors[kind]
+	if !found {
+		return nil, unknownCompressorError(kind)
+	}
+	return &Compressor{c}, nil
+}
+
+func unknownCompressorError(kind string) error {
+	kinds := make([]string, 0, len(compressors))
+	for k := range compressors {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return fmt.Errorf("unknown compressor %q, the available ones are: %s", kind, strings.Join(kinds, ", "))
+}
+
+// ContentEncoding returns the HTTP header value associated with the compressor
+func (c *Compressor) ContentEncoding() string {
+	return c.contentEncoding
+}
+
+// Compress will compress the data with the compressor
+func (c *Compressor) Compress(dst []byte, src []byte) ([]byte, error) {
+	return c.compress(dst, src)
+}
+
+// Decompress will decompress the data with the compressor
+func (c *Compressor) Decompress(dst []byte, src []byte) ([]byte, error) {
+	return c.decompress(dst, src)
+}
+
+// Compress will compress the data with the configured compressor
+func Compress(dst []byte, src []byte) ([]byte, error) {
+	return current.compress(dst, src)
+}
+
+// Decompress will decompress the data with the configured compressor
+func Decompress(dst []byte, src []byte) ([]byte, error) {
+	return current.decompress(dst, src)
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build zlib zstd
+
+package compression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	defer func(c compressor, contentEncoding string) {
+		current = c
+		ContentEncoding = contentEncoding
+	}(current, ContentEncoding)
+
+	payload := []byte(`{"series":[{"metric":"my.metric","points":[[1,2]]}]}`)
+	for kind, c := range compressors {
+		require.Nil(t, Configure(kind))
+		assert.Equal(t, c.contentEncoding, ContentEncoding)
+
+		compressed, err := Compress(nil, payload)
+		require.Nil(t, err)
+		decompressed, err := Decompress(nil, compressed)
+		require.Nil(t, err)
+		assert.Equal(t, payload, decompressed)
+	}
+
+	contentEncoding := ContentEncoding
+	assert.NotNil(t, Configure("lz4"))
+	assert.Equal(t, contentEncoding, ContentEncoding)
+}
+
+func TestGet(t *testing.T) {
+	payload := []byte(`{"series":[{"metric":"my.metric","points":[[1,2]]}]}`)
+	for kind, c := range compressors {
+		compressor, err := Get(kind)
+		require.Nil(t, err)
+		assert.Equal(t, c.contentEncoding, compressor.ContentEncoding())
+
+		compressed, err := compressor.Compress(nil, payload)
+		require.Nil(t, err)
+		decompressed, err := compressor.Decompress(nil, compressed)
+		require.Nil(t, err)
+		assert.Equal(t, payload, decompressed)
+	}
+
+	compressor, err := Get("")
+	require.Nil(t, err)
+	assert.Equal(t, ContentEncoding, compressor.ContentEncoding())
+
+	_, err = Get("lz4")
+	assert.NotNil(t, err)
+}
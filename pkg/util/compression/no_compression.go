@@ -7,11 +7,47 @@
 
 package compression
 
+import "fmt"
+
 // ContentEncoding describes the HTTP header value associated with the compression method
 // empty here since there's no compression
 // var instead of const to ease testing
 var ContentEncoding = ""
 
+// Compressor does not compress anything, no compressor being built in the binary
+type Compressor struct{}
+
+// Configure only accepts no compression, no compressor being built in the binary
+func Configure(kind string) error {
+	if kind != "" {
+		return fmt.Errorf("unknown compressor %q, the agent is built without compression", kind)
+	}
+	return nil
+}
+
+// Get only accepts no compression, no compressor being built in the binary
+func Get(kind string) (*Compressor, error) {
+	if err := Configure(kind); err != nil {
+		return nil, err
+	}
+	return &Compressor{}, nil
+}
+
+// ContentEncoding is empty since there's no compression
+func (c *Compressor) ContentEncoding() string {
+	return ""
+}
+
+// Compress will not compress anything
+func (c *Compressor) Compress(dst []byte, src []byte) ([]byte, error) {
+	return Compress(dst, src)
+}
+
+// Decompress will not decompress anything
+func (c *Compressor) Decompress(dst []byte, src []byte) ([]byte, error) {
+	return Decompress(dst, src)
+}
+
 // Compress will not compress anything
 func Compress(dst []byte, src []byte) ([]byte, error) {
 	dst = src
@@ -13,12 +13,16 @@ import (
 	"io/ioutil"
 )
 
-// ContentEncoding describes the HTTP header value associated with the compression method
-// var instead of const to ease testing
-var ContentEncoding = "deflate"
+func init() {
+	register(ZlibKind, compressor{
+		contentEncoding: "deflate",
+		compress:        zlibCompress,
+		decompress:      zlibDecompress,
+	})
+}
 
-// Compress will compress the data with zlib
-func Compress(dst []byte, src []byte) ([]byte, error) {
+// zlibCompress will compress the data with zlib
+func zlibCompress(dst []byte, src []byte) ([]byte, error) {
 	var b bytes.Buffer
 	w := zlib.NewWriter(&b)
 	_, err := w.Write(src)
@@ -33,8 +37,8 @@ func Compress(dst []byte, src []byte) ([]byte, error) {
 	return dst, nil
 }
 
-// Decompress will decompress the data with zlib
-func Decompress(dst []byte, src []byte) ([]byte, error) {
+// zlibDecompress will decompress the data with zlib
+func zlibDecompress(dst []byte, src []byte) ([]byte, error) {
 	r, err := zlib.NewReader(bytes.NewReader(src))
 	if err != nil {
 		return nil, err
@@ -12,16 +12,20 @@ import "github.com/DataDog/zstd"
 // TODO: the intake still uses a pre-v1 (unstable) version of the zstd compression format.
 // The agent shouldn't use zstd compression until the intake supports a stable v1 format.
 
-// ContentEncoding describes the HTTP header value associated with the compression method
-// var instead of const to ease testing
-var ContentEncoding = "zstd"
+func init() {
+	register(ZstdKind, compressor{
+		contentEncoding: "zstd",
+		compress:        zstdCompress,
+		decompress:      zstdDecompress,
+	})
+}
 
-// Compress will compress the data with zstd
-func Compress(dst []byte, src []byte) ([]byte, error) {
+// zstdCompress will compress the data with zstd
+func zstdCompress(dst []byte, src []byte) ([]byte, error) {
 	return zstd.Compress(dst, src)
 }
 
-// Decompress will decompress the data with zstd
-func Decompress(dst []byte, src []byte) ([]byte, error) {
+// zstdDecompress will decompress the data with zstd
+func zstdDecompress(dst []byte, src []byte) ([]byte, error) {
 	return zstd.Decompress(dst, src)
 }
@@ -3,7 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2018 Datadog, Inc.
 
-package docker
+package containers
 
 import (
 	"fmt"
@@ -13,9 +13,26 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
-// TODO: move to pkg/util/container once it does not
-// import pkg/util/docker anymore (circular import)
-// It's already decoupled from that package
+// FilterType is the scope of a container filter: the containers can be
+// excluded from the metrics, the logs or the autodiscovery independently
+type FilterType int
+
+// Filter scopes, the GlobalFilter patterns apply to all the other scopes
+const (
+	GlobalFilter FilterType = iota
+	MetricsFilter
+	LogsFilter
+	ADFilter
+)
+
+// filterSuffixes are the suffixes of the container_include and container_exclude
+// options of each scope
+var filterSuffixes = map[FilterType]string{
+	GlobalFilter:  "",
+	MetricsFilter: "_metrics",
+	LogsFilter:    "_logs",
+	ADFilter:      "_ad",
+}
 
 const (
 	// pauseContainerGCR regex matches:
@@ -86,11 +103,24 @@ func NewFilter(whitelist, blacklist []string) (*Filter, error) {
 	}, nil
 }
 
-// NewFilterFromConfig creates a new container filter, sourcing patterns
-// from the pkg/config options
-func NewFilterFromConfig() (*Filter, error) {
+// NewFilterFromConfig creates a new container filter of the given scope,
+// sourcing patterns from the pkg/config options: the container_include and
+// container_exclude options, their legacy ac_include and ac_exclude names,
+// and the options of the scope, e.g. container_exclude_logs
+func NewFilterFromConfig(filterType FilterType) (*Filter, error) {
+	suffix, found := filterSuffixes[filterType]
+	if !found {
+		return nil, fmt.Errorf("unknown container filter type %d", filterType)
+	}
+
 	whitelist := config.Datadog.GetStringSlice("ac_include")
 	blacklist := config.Datadog.GetStringSlice("ac_exclude")
+	whitelist = append(whitelist, config.Datadog.GetStringSlice("container_include")...)
+	blacklist = append(blacklist, config.Datadog.GetStringSlice("container_exclude")...)
+	if suffix != "" {
+		whitelist = append(whitelist, config.Datadog.GetStringSlice("container_include"+suffix)...)
+		blacklist = append(blacklist, config.Datadog.GetStringSlice("container_exclude"+suffix)...)
+	}
 
 	if config.Datadog.GetBool("exclude_pause_container") {
 		blacklist = append(blacklist,
@@ -105,9 +135,10 @@ func NewFilterFromConfig() (*Filter, error) {
 }
 
 // IsExcluded returns a bool indicating if the container should be excluded
-// based on the filters in the containerFilter instance.
-func (cf Filter) IsExcluded(containerName, containerImage string) bool {
-	if !cf.Enabled {
+// based on the filters in the containerFilter instance. A nil filter
+// excludes nothing.
+func (cf *Filter) IsExcluded(containerName, containerImage string) bool {
+	if cf == nil || !cf.Enabled {
 		return false
 	}
 
@@ -3,7 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2018 Datadog, Inc.
 
-package docker
+package containers
 
 import (
 	"testing"
@@ -14,8 +14,14 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
+type testContainer struct {
+	ID    string
+	Name  string
+	Image string
+}
+
 func TestFilter(t *testing.T) {
-	containers := []*Container{
+	containers := []testContainer{
 		{
 			ID:    "1",
 			Name:  "secret-container-dd",
@@ -130,14 +136,12 @@ func TestFilter(t *testing.T) {
 	}
 }
 
-// NewFilterFromConfig creates a new container filter, sourcing patterns
-// from the pkg/config options
 func TestNewFilterFromConfig(t *testing.T) {
 	config.Datadog.SetDefault("exclude_pause_container", true)
 	config.Datadog.SetDefault("ac_include", []string{"image:apache.*"})
 	config.Datadog.SetDefault("ac_exclude", []string{"name:dd-.*"})
 
-	f, err := NewFilterFromConfig()
+	f, err := NewFilterFromConfig(GlobalFilter)
 	require.NoError(t, err)
 
 	assert.True(t, f.IsExcluded("dd-152462", "dummy:latest"))
@@ -146,7 +150,7 @@ func TestNewFilterFromConfig(t *testing.T) {
 	assert.True(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1"))
 
 	config.Datadog.SetDefault("exclude_pause_container", false)
-	f, err = NewFilterFromConfig()
+	f, err = NewFilterFromConfig(GlobalFilter)
 	require.NoError(t, err)
 	assert.False(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1"))
 
@@ -154,3 +158,44 @@ func TestNewFilterFromConfig(t *testing.T) {
 	config.Datadog.SetDefault("ac_include", []string{})
 	config.Datadog.SetDefault("ac_exclude", []string{})
 }
+
+func TestNewFilterFromConfigScopes(t *testing.T) {
+	config.Datadog.SetDefault("container_exclude", []string{"name:dd-.*"})
+	config.Datadog.SetDefault("container_exclude_logs", []string{"image:nginx.*"})
+	config.Datadog.SetDefault("container_include_metrics", []string{"name:dd-agent"})
+	defer func() {
+		config.Datadog.SetDefault("container_exclude", []string{})
+		config.Datadog.SetDefault("container_exclude_logs", []string{})
+		config.Datadog.SetDefault("container_include_metrics", []string{})
+	}()
+
+	// the global patterns apply to every scope
+	for _, filterType := range []FilterType{GlobalFilter, MetricsFilter, LogsFilter, ADFilter} {
+		f, err := NewFilterFromConfig(filterType)
+		require.NoError(t, err)
+		assert.True(t, f.IsExcluded("dd-152462", "dummy:latest"), "filter %d", filterType)
+	}
+
+	// the image is only excluded from the logs
+	logs, err := NewFilterFromConfig(LogsFilter)
+	require.NoError(t, err)
+	assert.True(t, logs.IsExcluded("web", "nginx:latest"))
+	metrics, err := NewFilterFromConfig(MetricsFilter)
+	require.NoError(t, err)
+	assert.False(t, metrics.IsExcluded("web", "nginx:latest"))
+	ad, err := NewFilterFromConfig(ADFilter)
+	require.NoError(t, err)
+	assert.False(t, ad.IsExcluded("web", "nginx:latest"))
+
+	// the include patterns of a scope take precedence over the global exclusions
+	assert.False(t, metrics.IsExcluded("dd-agent", "datadog/agent:latest"))
+	assert.True(t, ad.IsExcluded("dd-agent", "datadog/agent:latest"))
+
+	_, err = NewFilterFromConfig(FilterType(42))
+	assert.Error(t, err)
+}
+
+func TestNilFilter(t *testing.T) {
+	var f *Filter
+	assert.False(t, f.IsExcluded("dummy", "dummy"))
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build cri
+
+package cri
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	pb "k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/retry"
+)
+
+// defaultSocketPaths are the CRI sockets tried when cri_socket_path is unset
+var defaultSocketPaths = []string{
+	"/var/run/containerd/containerd.sock",
+	"/run/containerd/containerd.sock",
+	"/var/run/crio/crio.sock",
+}
+
+var (
+	globalCRIUtil     *CRIUtil
+	globalCRIUtilLock sync.Mutex
+)
+
+// CRIUtil wraps the runtime service of a CRI socket
+type CRIUtil struct {
+	// used to setup the CRIUtil
+	initRetry retry.Retrier
+
+	client       pb.RuntimeServiceClient
+	queryTimeout time.Duration
+	socketPath   string
+	// Runtime is the name of the runtime, e.g. containerd or cri-o
+	Runtime string
+	// RuntimeVersion is the version of the runtime
+	RuntimeVersion string
+}
+
+// GetUtil returns a ready to use CRIUtil. It is backed by a shared singleton.
+func GetUtil() (*CRIUtil, error) {
+	globalCRIUtilLock.Lock()
+	defer globalCRIUtilLock.Unlock()
+	if globalCRIUtil == nil {
+		globalCRIUtil = &CRIUtil{}
+		globalCRIUtil.initRetry.SetupRetrier(&retry.Config{
+			Name:          "criutil",
+			AttemptMethod: globalCRIUtil.init,
+			Strategy:      retry.RetryCount,
+			RetryCount:    10,
+			RetryDelay:    30 * time.Second,
+		})
+	}
+	if err := globalCRIUtil.initRetry.TriggerRetry(); err != nil {
+		log.Debugf("CRI init error: %s", err)
+		return nil, err
+	}
+	return globalCRIUtil, nil
+}
+
+// init connects to the CRI socket and gets the runtime name and version.
+// This is not exposed as public API but is called by the retrier embed.
+func (c *CRIUtil) init() error {
+	socketPath := config.Datadog.GetString("cri_socket_path")
+	if socketPath == "" {
+		socketPath = detectSocketPath()
+	}
+	if socketPath == "" {
+		return fmt.Errorf("no CRI socket found in %v, set cri_socket_path", defaultSocketPaths)
+	}
+
+	connectionTimeout := config.Datadog.GetDuration("cri_connection_timeout") * time.Second
+	conn, err := grpc.Dial(socketPath,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(connectionTimeout),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("could not connect to the CRI socket %s: %s", socketPath, err)
+	}
+
+	c.client = pb.NewRuntimeServiceClient(conn)
+	c.queryTimeout = config.Datadog.GetDuration("cri_query_timeout") * time.Second
+	c.socketPath = socketPath
+	return c.getVersion()
+}
+
+// detectSocketPath returns the first of the default socket paths existing
+func detectSocketPath() string {
+	for _, path := range defaultSocketPaths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func (c *CRIUtil) getVersion() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+	r, err := c.client.Version(ctx, &pb.VersionRequest{})
+	if err != nil {
+		return fmt.Errorf("could not get the version of the runtime: %s", err)
+	}
+	c.Runtime = r.RuntimeName
+	c.RuntimeVersion = r.RuntimeVersion
+	log.Debugf("Connected to the CRI socket %s of %s %s", c.socketPath, c.Runtime, c.RuntimeVersion)
+	return nil
+}
+
+// ListRunningContainers returns the running containers
+func (c *CRIUtil) ListRunningContainers() ([]*pb.Container, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+	r, err := c.client.ListContainers(ctx, &pb.ListContainersRequest{
+		Filter: &pb.ContainerFilter{
+			State: &pb.ContainerStateValue{State: pb.ContainerState_CONTAINER_RUNNING},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Containers, nil
+}
+
+// ListContainerStats returns the stats of the containers by container ID
+func (c *CRIUtil) ListContainerStats() (map[string]*pb.ContainerStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+	r, err := c.client.ListContainerStats(ctx, &pb.ListContainerStatsRequest{Filter: &pb.ContainerStatsFilter{}})
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]*pb.ContainerStats, len(r.Stats))
+	for _, s := range r.Stats {
+		if s.Attributes != nil {
+			stats[s.Attributes.Id] = s
+		}
+	}
+	return stats, nil
+}
+
+// GetEntityID returns the tagger entity of a container, the runtime being
+// the scheme of the container IDs reported by the kubelet
+func (c *CRIUtil) GetEntityID(containerID string) string {
+	return fmt.Sprintf("%s://%s", c.Runtime, containerID)
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build cri
+
+package cri
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	pb "k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2"
+)
+
+// fakeRuntimeClient implements the calls of the runtime service used by CRIUtil
+type fakeRuntimeClient struct {
+	pb.RuntimeServiceClient
+	containers []*pb.Container
+	stats      []*pb.ContainerStats
+	filter     *pb.ContainerFilter
+}
+
+func (f *fakeRuntimeClient) Version(ctx context.Context, in *pb.VersionRequest, opts ...grpc.CallOption) (*pb.VersionResponse, error) {
+	return &pb.VersionResponse{RuntimeName: "containerd", RuntimeVersion: "v1.1.2"}, nil
+}
+
+func (f *fakeRuntimeClient) ListContainers(ctx context.Context, in *pb.ListContainersRequest, opts ...grpc.CallOption) (*pb.ListContainersResponse, error) {
+	f.filter = in.Filter
+	return &pb.ListContainersResponse{Containers: f.containers}, nil
+}
+
+func (f *fakeRuntimeClient) ListContainerStats(ctx context.Context, in *pb.ListContainerStatsRequest, opts ...grpc.CallOption) (*pb.ListContainerStatsResponse, error) {
+	return &pb.ListContainerStatsResponse{Stats: f.stats}, nil
+}
+
+func TestCRIUtil(t *testing.T) {
+	client := &fakeRuntimeClient{
+		containers: []*pb.Container{{Id: "abc"}},
+		stats: []*pb.ContainerStats{
+			{Attributes: &pb.ContainerAttributes{Id: "abc"}},
+			// stats without attributes are skipped
+			{},
+		},
+	}
+	util := &CRIUtil{client: client, queryTimeout: time.Second}
+	require.NoError(t, util.getVersion())
+	assert.Equal(t, "containerd", util.Runtime)
+	assert.Equal(t, "v1.1.2", util.RuntimeVersion)
+	assert.Equal(t, "containerd://abc", util.GetEntityID("abc"))
+
+	containers, err := util.ListRunningContainers()
+	require.NoError(t, err)
+	assert.Len(t, containers, 1)
+	assert.Equal(t, pb.ContainerState_CONTAINER_RUNNING, client.filter.State.State)
+
+	stats, err := util.ListContainerStats()
+	require.NoError(t, err)
+	assert.Len(t, stats, 1)
+	assert.NotNil(t, stats["abc"])
+}
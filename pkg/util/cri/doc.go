@@ -0,0 +1,10 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+/*
+Package cri provides a client of the Container Runtime Interface socket of
+the container runtimes used by the kubelet, e.g. containerd or CRI-O
+*/
+package cri
@@ -89,10 +89,23 @@ type CgroupTimesStat struct {
 }
 
 // CgroupIOStat store I/O statistics about a cgroup.
+// Devices holds the stats per block device, keyed by the device name found in
+// /proc/partitions, or by its "major:minor" id if it is not listed there.
 type CgroupIOStat struct {
 	ContainerID string
 	ReadBytes   uint64
 	WriteBytes  uint64
+	ReadOps     uint64
+	WriteOps    uint64
+	Devices     map[string]*DeviceIOStat
+}
+
+// DeviceIOStat stores I/O statistics about a block device used by a cgroup.
+type DeviceIOStat struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadOps    uint64
+	WriteOps   uint64
 }
 
 // ContainerCgroup is a structure that stores paths and mounts for a cgroup.
@@ -108,6 +121,9 @@ type ContainerCgroup struct {
 // Mem returns the memory statistics for a Cgroup. If the cgroup file is not
 // available then we return an empty stats file.
 func (c ContainerCgroup) Mem() (*CgroupMemStat, error) {
+	if c.useUnified("memory") {
+		return c.unifiedMem()
+	}
 	ret := &CgroupMemStat{ContainerID: c.ContainerID}
 	statfile := c.cgroupFilePath("memory", "memory.stat")
 
@@ -197,6 +213,14 @@ func (c ContainerCgroup) Mem() (*CgroupMemStat, error) {
 // MemLimit returns the memory limit of the cgroup, if it exists. If the file does not
 // exist or there is no limit then this will default to 0.
 func (c ContainerCgroup) MemLimit() (uint64, error) {
+	if c.useUnified("memory") {
+		v, err := c.parseUnifiedLimit("memory.max")
+		if os.IsNotExist(err) {
+			log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(unifiedTarget, "memory.max"))
+			return 0, nil
+		}
+		return v, err
+	}
 	v, err := c.ParseSingleStat("memory", "memory.limit_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -216,6 +240,15 @@ func (c ContainerCgroup) MemLimit() (uint64, error) {
 // SoftMemLimit returns the soft memory limit of the cgroup, if it exists. If the file does not
 // exist or there is no limit then this will default to 0.
 func (c ContainerCgroup) SoftMemLimit() (uint64, error) {
+	// memory.low is where the container runtimes put the memory reservation on v2
+	if c.useUnified("memory") {
+		v, err := c.parseUnifiedLimit("memory.low")
+		if os.IsNotExist(err) {
+			log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(unifiedTarget, "memory.low"))
+			return 0, nil
+		}
+		return v, err
+	}
 	v, err := c.ParseSingleStat("memory", "memory.soft_limit_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -235,6 +268,9 @@ func (c ContainerCgroup) SoftMemLimit() (uint64, error) {
 // CPU returns the CPU status for this cgroup instance
 // If the cgroup file does not exist then we just log debug return nothing.
 func (c ContainerCgroup) CPU() (*CgroupTimesStat, error) {
+	if c.useUnified("cpuacct") {
+		return c.unifiedCPU()
+	}
 	ret := &CgroupTimesStat{ContainerID: c.ContainerID}
 	statfile := c.cgroupFilePath("cpuacct", "cpuacct.stat")
 	f, err := os.Open(statfile)
@@ -286,7 +322,12 @@ func (c ContainerCgroup) CPU() (*CgroupTimesStat, error) {
 // throttle/limited because of CPU quota / limit
 // If the cgroup file does not exist then we just log debug and return 0.
 func (c ContainerCgroup) CPUNrThrottled() (uint64, error) {
-	statfile := c.cgroupFilePath("cpu", "cpu.stat")
+	// cpu.stat has the same nr_throttled line in both versions
+	target := "cpu"
+	if c.useUnified("cpu") {
+		target = unifiedTarget
+	}
+	statfile := c.cgroupFilePath(target, "cpu.stat")
 	f, err := os.Open(statfile)
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s", statfile)
@@ -320,6 +361,9 @@ func (c ContainerCgroup) CPUNrThrottled() (uint64, error) {
 // If the limits files aren't available (on older version) then
 // we'll return the default value of 100.
 func (c ContainerCgroup) CPULimit() (float64, error) {
+	if c.useUnified("cpu") {
+		return c.unifiedCPULimit()
+	}
 	periodFile := c.cgroupFilePath("cpu", "cpu.cfs_period_us")
 	quotaFile := c.cgroupFilePath("cpu", "cpu.cfs_quota_us")
 	plines, err := readLines(periodFile)
@@ -352,8 +396,9 @@ func (c ContainerCgroup) CPULimit() (float64, error) {
 	return limit, nil
 }
 
-// IO returns the disk read and write bytes stats for this cgroup.
-// Format:
+// IO returns the disk read and write bytes and operations stats for this
+// cgroup, per device and summed over all of them. Format of the
+// blkio.throttle.io_service_bytes and blkio.throttle.io_serviced files:
 //
 // 8:0 Read 49225728
 // 8:0 Write 9850880
@@ -365,38 +410,162 @@ func (c ContainerCgroup) CPULimit() (float64, error) {
 // 252:0 Sync 0
 // 252:0 Async 58945536
 // 252:0 Total 58945536
+// Total 118022144
 //
 func (c ContainerCgroup) IO() (*CgroupIOStat, error) {
+	if c.useUnified("blkio") {
+		return c.unifiedIO()
+	}
 	ret := &CgroupIOStat{ContainerID: c.ContainerID}
+	devices := make(map[string]*DeviceIOStat)
 	statfile := c.cgroupFilePath("blkio", "blkio.throttle.io_service_bytes")
-	f, err := os.Open(statfile)
+	err := readBlkioFile(statfile, devices, func(dev *DeviceIOStat, op string, value uint64) {
+		if op == "Read" {
+			dev.ReadBytes = value
+		} else if op == "Write" {
+			dev.WriteBytes = value
+		}
+	})
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s", statfile)
 		return ret, nil
 	} else if err != nil {
 		return nil, err
 	}
+
+	opsfile := c.cgroupFilePath("blkio", "blkio.throttle.io_serviced")
+	err = readBlkioFile(opsfile, devices, func(dev *DeviceIOStat, op string, value uint64) {
+		if op == "Read" {
+			dev.ReadOps = value
+		} else if op == "Write" {
+			dev.WriteOps = value
+		}
+	})
+	if err != nil {
+		log.Debugf("Cannot read the I/O operations of %s: %s", c.ContainerID, err)
+	}
+
+	ret.setDevices(devices)
+	return ret, nil
+}
+
+// readBlkioFile parses a blkio.throttle file, calling fn for every
+// "major:minor operation value" line. The last "Total value" line is skipped.
+func readBlkioFile(statfile string, devices map[string]*DeviceIOStat, fn func(dev *DeviceIOStat, op string, value uint64)) error {
+	f, err := os.Open(statfile)
+	if err != nil {
+		return err
+	}
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		fields := strings.Split(scanner.Text(), " ")
-		if fields[1] == "Read" {
-			read, err := strconv.ParseUint(fields[2], 10, 64)
-			if err == nil {
-				ret.ReadBytes = read
-			}
-		} else if fields[1] == "Write" {
-			write, err := strconv.ParseUint(fields[2], 10, 64)
-			if err == nil {
-				ret.WriteBytes = write
-			}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
 		}
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		dev, found := devices[fields[0]]
+		if !found {
+			dev = &DeviceIOStat{}
+			devices[fields[0]] = dev
+		}
+		fn(dev, fields[1], value)
 	}
 	if err := scanner.Err(); err != nil {
-		return ret, fmt.Errorf("error reading %s: %s", statfile, err)
+		return fmt.Errorf("error reading %s: %s", statfile, err)
 	}
-	return ret, nil
+	return nil
+}
+
+// setDevices sums the stats of the devices, keyed by "major:minor" id, into
+// the cgroup ones, and keys them by device name when it is known.
+func (s *CgroupIOStat) setDevices(devices map[string]*DeviceIOStat) {
+	names := blockDeviceNames()
+	s.Devices = make(map[string]*DeviceIOStat, len(devices))
+	for id, dev := range devices {
+		s.ReadBytes += dev.ReadBytes
+		s.WriteBytes += dev.WriteBytes
+		s.ReadOps += dev.ReadOps
+		s.WriteOps += dev.WriteOps
+		if name, found := names[id]; found {
+			id = name
+		}
+		s.Devices[id] = dev
+	}
+}
+
+// blockDeviceNames returns the names of the block devices of the host, by
+// "major:minor" id, from /{host/}proc/partitions
+func blockDeviceNames() map[string]string {
+	partitionsFile := hostProc("partitions")
+	f, err := os.Open(partitionsFile)
+	if err != nil {
+		log.Debugf("Cannot read the block device names from %s: %s", partitionsFile, err)
+		return nil
+	}
+	defer f.Close()
+	return parsePartitions(f)
+}
+
+// parsePartitions parses a /proc/partitions file. Format:
+//
+// major minor  #blocks  name
+//
+//    8        0  500107608 sda
+//    8        1     524288 sda1
+//
+func parsePartitions(r io.Reader) map[string]string {
+	names := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		if _, err := strconv.ParseUint(fields[0], 10, 32); err != nil {
+			// header line
+			continue
+		}
+		names[fields[0]+":"+fields[1]] = fields[3]
+	}
+	return names
+}
+
+// ThreadCount returns the number of tasks (threads) currently in the cgroup,
+// from the pids controller. If the cgroup file does not exist then we just
+// log debug and return 0.
+func (c ContainerCgroup) ThreadCount() (uint64, error) {
+	target := "pids"
+	if c.useUnified("pids") {
+		target = unifiedTarget
+	}
+	v, err := c.ParseSingleStat(target, "pids.current")
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(target, "pids.current"))
+		return 0, nil
+	}
+	return v, err
+}
+
+// ThreadLimit returns the maximum number of tasks (threads) allowed in the
+// cgroup by the pids controller. If the file does not exist or there is no
+// limit then this will default to 0.
+func (c ContainerCgroup) ThreadLimit() (uint64, error) {
+	target := "pids"
+	if c.useUnified("pids") {
+		target = unifiedTarget
+	}
+	statfile := c.cgroupFilePath(target, "pids.max")
+	v, err := parseLimit(statfile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statfile)
+		return 0, nil
+	}
+	return v, err
 }
 
 // ParseSingleStat reads and converts a single-value cgroup stat file content to uint64.
@@ -419,7 +588,11 @@ func (c ContainerCgroup) ParseSingleStat(target, file string) (uint64, error) {
 // ContainerStartTime gets the stat for cgroup directory and use the mtime for that dir to determine the start time for the container
 // this should work because the cgroup dir for the container would be created only when it's started
 func (c ContainerCgroup) ContainerStartTime() (int64, error) {
-	cgroupDir := c.cgroupFilePath("cpuacct", "")
+	target := "cpuacct"
+	if c.useUnified(target) {
+		target = unifiedTarget
+	}
+	cgroupDir := c.cgroupFilePath(target, "")
 	if !pathExists(cgroupDir) {
 		return 0, fmt.Errorf("could not get cgroup dir, directory doesn't exist")
 	}
@@ -469,7 +642,12 @@ func (c ContainerCgroup) cgroupFilePath(target, file string) string {
 //	 cgroup /sys/fs/cgroup/perf_event cgroup rw,relatime,perf_event 0 0
 //	 cgroup /sys/fs/cgroup/hugetlb cgroup rw,relatime,hugetlb 0 0
 //
-// Returns a map for every target (cpuset, cpu, cpuacct) => path
+// The cgroup v2 unified hierarchy is mounted with the cgroup2 type, either
+// on the cgroup root or on its unified directory on hosts mixing both versions:
+//	 cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime 0 0
+//	 cgroup2 /sys/fs/cgroup/unified cgroup2 rw,nosuid,nodev,noexec,relatime 0 0
+//
+// Returns a map for every target (cpuset, cpu, cpuacct, unified) => path
 func cgroupMountPoints() (map[string]string, error) {
 	mountsFile := "/proc/mounts"
 	if !pathExists(mountsFile) {
@@ -490,6 +668,14 @@ func parseCgroupMountPoints(r io.Reader) map[string]string {
 	for scanner.Scan() {
 		mount := scanner.Text()
 		tokens := strings.Split(mount, " ")
+		// The unified hierarchy can be mounted on the cgroup root itself
+		if len(tokens) >= 3 && tokens[2] == "cgroup2" {
+			cgroupPath := tokens[1]
+			if strings.HasPrefix(cgroupPath+"/", cgroupRoot) {
+				mountPoints[unifiedTarget] = cgroupPath
+			}
+			continue
+		}
 		// Check if the filesystem type is 'cgroup'
 		if len(tokens) >= 3 && tokens[2] == "cgroup" {
 			cgroupPath := tokens[1]
@@ -597,7 +783,10 @@ func readCgroupPaths(pidCgroupPath string) (string, map[string]string, error) {
 // 9:cpu,cpuacct:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
 // 8:memory:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
 // 7:blkio:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
+// 0::/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
 //
+// The "0::" line is the path in the cgroup v2 unified hierarchy, stored under
+// the unified target. It is the only line on cgroup v2 hosts.
 // Returns the common containerID and a mapping of target => path
 // If the first line doesn't have a valid container ID we will return an empty string
 func parseCgroupPaths(r io.Reader) (string, map[string]string, error) {
@@ -619,6 +808,10 @@ func parseCgroupPaths(r io.Reader) (string, map[string]string, error) {
 		if len(sp) < 3 {
 			continue
 		}
+		if sp[1] == "" {
+			paths[unifiedTarget] = sp[2]
+			continue
+		}
 		// Target can be comma-separate values like cpu,cpuacct
 		tsp := strings.Split(sp[1], ",")
 		for _, target := range tsp {
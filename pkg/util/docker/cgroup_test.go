@@ -13,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestCPU(t *testing.T) {
@@ -133,6 +135,49 @@ func TestSoftMemLimit(t *testing.T) {
 	assert.Equal(t, value, uint64(1234))
 }
 
+func TestIO(t *testing.T) {
+	tempFolder, err := newTempFolder("io-stats")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+	tempFolder.add("proc/partitions", strings.Join([]string{
+		"major minor  #blocks  name",
+		"",
+		"   8        0  500107608 sda",
+		"   8        1     524288 sda1",
+	}, "\n"))
+	config.Datadog.SetDefault("container_proc_root", tempFolder.RootPath+"/proc")
+
+	tempFolder.add("blkio/blkio.throttle.io_service_bytes", strings.Join([]string{
+		"8:0 Read 49225728",
+		"8:0 Write 9850880",
+		"8:0 Sync 0",
+		"8:0 Total 59076608",
+		"252:0 Read 49094656",
+		"252:0 Write 9850880",
+		"252:0 Total 58945536",
+		"Total 118022144",
+	}, "\n"))
+	tempFolder.add("blkio/blkio.throttle.io_serviced", strings.Join([]string{
+		"8:0 Read 1012",
+		"8:0 Write 224",
+		"252:0 Read 1007",
+		"252:0 Write 224",
+		"Total 2467",
+	}, "\n"))
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, "blkio")
+
+	ioStat, err := cgroup.IO()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(98320384), ioStat.ReadBytes)
+	assert.Equal(t, uint64(19701760), ioStat.WriteBytes)
+	assert.Equal(t, uint64(2019), ioStat.ReadOps)
+	assert.Equal(t, uint64(448), ioStat.WriteOps)
+	assert.Len(t, ioStat.Devices, 2)
+	assert.Equal(t, &DeviceIOStat{ReadBytes: 49225728, WriteBytes: 9850880, ReadOps: 1012, WriteOps: 224}, ioStat.Devices["sda"])
+	assert.Equal(t, &DeviceIOStat{ReadBytes: 49094656, WriteBytes: 9850880, ReadOps: 1007, WriteOps: 224}, ioStat.Devices["252:0"])
+}
+
 func TestParseSingleStat(t *testing.T) {
 	tempFolder, err := newTempFolder("test-parse-single-stat")
 	assert.Nil(t, err)
@@ -0,0 +1,272 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// This code is not tied to docker itself, hence no docker build flag.
+// It could be moved to its own package.
+
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// unifiedTarget is the target under which the mount point and the container
+// path of the cgroup v2 unified hierarchy are stored. This hierarchy has no
+// controller list in /proc/$pid/cgroup: its line looks like "0::/path".
+const unifiedTarget = "unified"
+
+// MicroToUserHZDivisor holds the divisor to convert the cgroup v2
+// usage_usec, user_usec and system_usec to USER_HZ (1/100)
+const MicroToUserHZDivisor float64 = 1e6 / 100
+
+// useUnified returns whether the stats of a controller are read from the
+// unified hierarchy. This is the case on cgroup v2 hosts, and on hosts mixing
+// both versions for the controllers that are not bound to a v1 hierarchy.
+func (c ContainerCgroup) useUnified(controller string) bool {
+	if _, found := c.Mounts[controller]; found {
+		return false
+	}
+	_, found := c.Mounts[unifiedTarget]
+	return found
+}
+
+// unifiedMem returns the memory statistics of a cgroup from the memory.stat,
+// memory.current, memory.max, memory.swap.* and memory.events files of the
+// unified hierarchy. The v2 stats already account for the descendant cgroups.
+func (c ContainerCgroup) unifiedMem() (*CgroupMemStat, error) {
+	ret := &CgroupMemStat{ContainerID: c.ContainerID}
+	statfile := c.cgroupFilePath(unifiedTarget, "memory.stat")
+	stats, err := readFlatKeyed(statfile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statfile)
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+	ret.Cache = stats["file"]
+	ret.RSS = stats["anon"]
+	ret.RSSHuge = stats["anon_thp"]
+	ret.MappedFile = stats["file_mapped"]
+	ret.Pgfault = stats["pgfault"]
+	ret.Pgmajfault = stats["pgmajfault"]
+	ret.InactiveAnon = stats["inactive_anon"]
+	ret.ActiveAnon = stats["active_anon"]
+	ret.InactiveFile = stats["inactive_file"]
+	ret.ActiveFile = stats["active_file"]
+	ret.Unevictable = stats["unevictable"]
+
+	if usage, err := c.ParseSingleStat(unifiedTarget, "memory.current"); err == nil {
+		ret.MemUsageInBytes = usage
+	} else {
+		log.Debugf("Missing memory usage stat for %s: %s", c.ContainerID, err)
+	}
+	if events, err := readFlatKeyed(c.cgroupFilePath(unifiedTarget, "memory.events")); err == nil {
+		ret.MemFailCnt = events["max"]
+	} else {
+		log.Debugf("Missing memory events for %s: %s", c.ContainerID, err)
+	}
+
+	memLimit, err := c.parseUnifiedLimit("memory.max")
+	if err != nil {
+		log.Debugf("Missing memory limit for %s: %s", c.ContainerID, err)
+	}
+	ret.HierarchicalMemoryLimit = memLimit
+
+	// The swap files are absent when the kernel has no swap accounting
+	if swap, err := c.ParseSingleStat(unifiedTarget, "memory.swap.current"); err == nil {
+		ret.Swap = swap
+		ret.SwapPresent = true
+	}
+	// memory.swap.max only limits the swap, v1 memsw limits memory + swap
+	swapLimit, err := c.parseUnifiedLimit("memory.swap.max")
+	if err == nil && memLimit > 0 && swapLimit > 0 {
+		ret.HierarchicalMemSWLimit = memLimit + swapLimit
+	}
+
+	return ret, nil
+}
+
+// unifiedCPU returns the CPU times of a cgroup from the cpu.stat and
+// cpu.weight files of the unified hierarchy, converted to the v1 units.
+func (c ContainerCgroup) unifiedCPU() (*CgroupTimesStat, error) {
+	ret := &CgroupTimesStat{ContainerID: c.ContainerID}
+	statfile := c.cgroupFilePath(unifiedTarget, "cpu.stat")
+	stats, err := readFlatKeyed(statfile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statfile)
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+	ret.User = uint64(float64(stats["user_usec"]) / MicroToUserHZDivisor)
+	ret.System = uint64(float64(stats["system_usec"]) / MicroToUserHZDivisor)
+	ret.UsageTotal = float64(stats["usage_usec"]) / MicroToUserHZDivisor
+
+	weight, err := c.ParseSingleStat(unifiedTarget, "cpu.weight")
+	if err == nil {
+		ret.Shares = cpuWeightToShares(weight)
+	} else {
+		log.Debugf("Missing cpu weight stat for %s: %s", c.ContainerID, err.Error())
+	}
+
+	return ret, nil
+}
+
+// unifiedCPULimit reads the cpu.max file of the unified hierarchy.
+// Format is "$quota $period", the quota being "max" when there is no limit.
+func (c ContainerCgroup) unifiedCPULimit() (float64, error) {
+	maxFile := c.cgroupFilePath(unifiedTarget, "cpu.max")
+	lines, err := readLines(maxFile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", maxFile)
+		return 100, nil
+	} else if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(lines[0])
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("wrong file format: %s", maxFile)
+	}
+	// default cpu limit is 100%
+	if fields[0] == "max" {
+		return 100, nil
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	limit := 100.0
+	if (period > 0) && (quota > 0) {
+		limit = (quota / period) * 100.0
+	}
+	return limit, nil
+}
+
+// unifiedIO returns the disk read and write bytes and operations of a cgroup,
+// per device and summed over all the devices of the io.stat file of the
+// unified hierarchy.
+// Format:
+//
+// 8:0 rbytes=49225728 wbytes=9850880 rios=1012 wios=224 dbytes=0 dios=0
+// 252:0 rbytes=49094656 wbytes=9850880 rios=1007 wios=224 dbytes=0 dios=0
+//
+func (c ContainerCgroup) unifiedIO() (*CgroupIOStat, error) {
+	ret := &CgroupIOStat{ContainerID: c.ContainerID}
+	statfile := c.cgroupFilePath(unifiedTarget, "io.stat")
+	f, err := os.Open(statfile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statfile)
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	devices := make(map[string]*DeviceIOStat)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		dev := &DeviceIOStat{}
+		devices[fields[0]] = dev
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			v, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch kv[0] {
+			case "rbytes":
+				dev.ReadBytes = v
+			case "wbytes":
+				dev.WriteBytes = v
+			case "rios":
+				dev.ReadOps = v
+			case "wios":
+				dev.WriteOps = v
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ret, fmt.Errorf("error reading %s: %s", statfile, err)
+	}
+	ret.setDevices(devices)
+	return ret, nil
+}
+
+// parseUnifiedLimit reads a single-value limit file of the unified hierarchy,
+// where "max" stands for no limit. It returns 0 in that case.
+func (c ContainerCgroup) parseUnifiedLimit(file string) (uint64, error) {
+	return parseLimit(c.cgroupFilePath(unifiedTarget, file))
+}
+
+// parseLimit reads a single-value limit file holding either a number or "max",
+// as the pids.max file of both versions or the v2 memory limits.
+func parseLimit(statFile string) (uint64, error) {
+	lines, err := readLines(statFile)
+	if err != nil {
+		return 0, err
+	}
+	if len(lines) != 1 {
+		return 0, fmt.Errorf("wrong file format: %s", statFile)
+	}
+	if lines[0] == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(lines[0], 10, 64)
+}
+
+// readFlatKeyed parses a flat keyed cgroup file made of "key value" lines,
+// such as cpu.stat, memory.stat or memory.events. Non-integer values are skipped.
+func readFlatKeyed(statfile string) (map[string]uint64, error) {
+	f, err := os.Open(statfile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stats := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		stats[fields[0]] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", statfile, err)
+	}
+	return stats, nil
+}
+
+// cpuWeightToShares converts a cgroup v2 cpu.weight, in [1, 10000], to the
+// v1 cpu.shares, in [2, 262144]. This is the reverse of the conversion done
+// by runc when it applies the shares of a container on a v2 host.
+func cpuWeightToShares(weight uint64) uint64 {
+	if weight == 0 {
+		return 0
+	}
+	return 2 + ((weight-1)*262142)/9999
+}
@@ -0,0 +1,298 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestUnifiedMem(t *testing.T) {
+	tempFolder, err := newTempFolder("unified-mem")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, unifiedTarget)
+
+	// No file
+	memStat, err := cgroup.Mem()
+	assert.Nil(t, err)
+	assert.Equal(t, &CgroupMemStat{ContainerID: "dummy"}, memStat)
+
+	memoryStats := dummyCgroupStat{
+		"anon":        6733824,
+		"file":        25710592,
+		"anon_thp":    2097152,
+		"file_mapped": 1234,
+		"pgfault":     4567,
+		"pgmajfault":  89,
+	}
+	tempFolder.add("unified/memory.stat", memoryStats.String()+"\nfoo bar")
+	tempFolder.add("unified/memory.current", "32444416")
+	tempFolder.add("unified/memory.max", "max")
+	tempFolder.add("unified/memory.events", "low 0\nhigh 0\nmax 12\noom 1\noom_kill 1")
+
+	memStat, err = cgroup.Mem()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6733824), memStat.RSS)
+	assert.Equal(t, uint64(25710592), memStat.Cache)
+	assert.Equal(t, uint64(2097152), memStat.RSSHuge)
+	assert.Equal(t, uint64(1234), memStat.MappedFile)
+	assert.Equal(t, uint64(4567), memStat.Pgfault)
+	assert.Equal(t, uint64(89), memStat.Pgmajfault)
+	assert.Equal(t, uint64(32444416), memStat.MemUsageInBytes)
+	assert.Equal(t, uint64(12), memStat.MemFailCnt)
+	assert.Equal(t, uint64(0), memStat.HierarchicalMemoryLimit)
+	assert.False(t, memStat.SwapPresent)
+	assert.Equal(t, uint64(0), memStat.HierarchicalMemSWLimit)
+
+	// Memory and swap limits
+	tempFolder.add("unified/memory.max", "536870912")
+	tempFolder.add("unified/memory.swap.current", "4096")
+	tempFolder.add("unified/memory.swap.max", "536870912")
+	memStat, err = cgroup.Mem()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(536870912), memStat.HierarchicalMemoryLimit)
+	assert.True(t, memStat.SwapPresent)
+	assert.Equal(t, uint64(4096), memStat.Swap)
+	assert.Equal(t, uint64(1073741824), memStat.HierarchicalMemSWLimit)
+}
+
+func TestUnifiedMemLimits(t *testing.T) {
+	tempFolder, err := newTempFolder("unified-mem-limit")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, unifiedTarget)
+
+	// No file
+	value, err := cgroup.MemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), value)
+	value, err = cgroup.SoftMemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), value)
+
+	// No limit
+	tempFolder.add("unified/memory.max", "max")
+	tempFolder.add("unified/memory.low", "0")
+	value, err = cgroup.MemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), value)
+	value, err = cgroup.SoftMemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), value)
+
+	// Invalid file
+	tempFolder.add("unified/memory.max", "ab")
+	_, err = cgroup.MemLimit()
+	assert.NotNil(t, err)
+
+	// Valid values
+	tempFolder.add("unified/memory.max", "1234")
+	tempFolder.add("unified/memory.low", "567")
+	value, err = cgroup.MemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1234), value)
+	value, err = cgroup.SoftMemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(567), value)
+}
+
+func TestUnifiedCPU(t *testing.T) {
+	tempFolder, err := newTempFolder("unified-cpu")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cpuStats := dummyCgroupStat{
+		"usage_usec":     915266418,
+		"user_usec":      641400000,
+		"system_usec":    183270000,
+		"nr_periods":     40,
+		"nr_throttled":   10,
+		"throttled_usec": 18327,
+	}
+	tempFolder.add("unified/cpu.stat", cpuStats.String())
+	tempFolder.add("unified/cpu.weight", "39")
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, unifiedTarget)
+
+	timeStat, err := cgroup.CPU()
+	assert.Nil(t, err)
+	assert.Equal(t, timeStat.ContainerID, "dummy")
+	assert.Equal(t, timeStat.User, uint64(64140))
+	assert.Equal(t, timeStat.System, uint64(18327))
+	assert.Equal(t, timeStat.Shares, uint64(998))
+	assert.InDelta(t, timeStat.UsageTotal, 91526.6418, 0.0000001)
+
+	value, err := cgroup.CPUNrThrottled()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(10))
+}
+
+func TestUnifiedCPULimit(t *testing.T) {
+	tempFolder, err := newTempFolder("unified-cpu-limit")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, unifiedTarget)
+
+	// No file
+	limit, err := cgroup.CPULimit()
+	assert.Nil(t, err)
+	assert.Equal(t, 100.0, limit)
+
+	// No limit
+	tempFolder.add("unified/cpu.max", "max 100000")
+	limit, err = cgroup.CPULimit()
+	assert.Nil(t, err)
+	assert.Equal(t, 100.0, limit)
+
+	// Invalid file
+	tempFolder.add("unified/cpu.max", "50000")
+	_, err = cgroup.CPULimit()
+	assert.NotNil(t, err)
+
+	// Half a CPU
+	tempFolder.add("unified/cpu.max", "50000 100000")
+	limit, err = cgroup.CPULimit()
+	assert.Nil(t, err)
+	assert.Equal(t, 50.0, limit)
+}
+
+func TestUnifiedIO(t *testing.T) {
+	tempFolder, err := newTempFolder("unified-io")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	tempFolder.add("unified/io.stat", strings.Join([]string{
+		"8:0 rbytes=49225728 wbytes=9850880 rios=1012 wios=224 dbytes=0 dios=0",
+		"252:0 rbytes=49094656 wbytes=9850880 rios=1007 wios=224 dbytes=0 dios=0",
+	}, "\n"))
+
+	// No partitions file, the devices are keyed by id
+	config.Datadog.SetDefault("container_proc_root", tempFolder.RootPath)
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, unifiedTarget)
+
+	ioStat, err := cgroup.IO()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(98320384), ioStat.ReadBytes)
+	assert.Equal(t, uint64(19701760), ioStat.WriteBytes)
+	assert.Equal(t, uint64(2019), ioStat.ReadOps)
+	assert.Equal(t, uint64(448), ioStat.WriteOps)
+	assert.Len(t, ioStat.Devices, 2)
+	assert.Equal(t, &DeviceIOStat{ReadBytes: 49225728, WriteBytes: 9850880, ReadOps: 1012, WriteOps: 224}, ioStat.Devices["8:0"])
+}
+
+func TestThreads(t *testing.T) {
+	tempFolder, err := newTempFolder("threads")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	for _, target := range []string{"pids", unifiedTarget} {
+		cgroup := newDummyContainerCgroup(tempFolder.RootPath, target)
+
+		// No file
+		value, err := cgroup.ThreadCount()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), value)
+		value, err = cgroup.ThreadLimit()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), value)
+
+		tempFolder.add(target+"/pids.current", "42")
+		tempFolder.add(target+"/pids.max", "max")
+		value, err = cgroup.ThreadCount()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(42), value)
+		value, err = cgroup.ThreadLimit()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), value)
+
+		tempFolder.add(target+"/pids.max", "1024")
+		value, err = cgroup.ThreadLimit()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1024), value)
+	}
+}
+
+func TestMixedHierarchy(t *testing.T) {
+	tempFolder, err := newTempFolder("mixed-hierarchy")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	// memory is bound to v1, pids is only available in the unified hierarchy
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, "memory", unifiedTarget)
+	tempFolder.add("memory/memory.limit_in_bytes", "1234")
+	tempFolder.add("unified/memory.max", "5678")
+	tempFolder.add("unified/pids.current", "7")
+
+	assert.False(t, cgroup.useUnified("memory"))
+	assert.True(t, cgroup.useUnified("pids"))
+
+	value, err := cgroup.MemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1234), value)
+	value, err = cgroup.ThreadCount()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), value)
+}
+
+func TestParseUnifiedCgroupMountPoints(t *testing.T) {
+	for _, tc := range []struct {
+		contents []string
+		expected map[string]string
+	}{
+		{
+			contents: []string{
+				"sysfs /sys sysfs ro,nosuid,nodev,noexec,relatime 0 0",
+				"cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime,nsdelegate 0 0",
+			},
+			expected: map[string]string{
+				"unified": "/sys/fs/cgroup",
+			},
+		},
+		{
+			contents: []string{
+				"tmpfs /sys/fs/cgroup tmpfs ro,nosuid,nodev,noexec,mode=755 0 0",
+				"cgroup2 /sys/fs/cgroup/unified cgroup2 rw,nosuid,nodev,noexec,relatime 0 0",
+				"cgroup /sys/fs/cgroup/systemd cgroup rw,nosuid,nodev,noexec,relatime,xattr,name=systemd 0 0",
+				"cgroup /sys/fs/cgroup/memory cgroup rw,nosuid,nodev,noexec,relatime,memory 0 0",
+				"cgroup /sys/fs/cgroup/cpu,cpuacct cgroup rw,nosuid,nodev,noexec,relatime,cpu,cpuacct 0 0",
+			},
+			expected: map[string]string{
+				"unified": "/sys/fs/cgroup/unified",
+				"systemd": "/sys/fs/cgroup/systemd",
+				"memory":  "/sys/fs/cgroup/memory",
+				"cpu":     "/sys/fs/cgroup/cpu,cpuacct",
+				"cpuacct": "/sys/fs/cgroup/cpu,cpuacct",
+			},
+		},
+		{
+			contents: []string{
+				"cgroup2 /mnt/cgroup2 cgroup2 rw,nosuid,nodev,noexec,relatime 0 0",
+			},
+			expected: map[string]string{},
+		},
+	} {
+		contents := strings.NewReader(strings.Join(tc.contents, "\n"))
+		assert.Equal(t, tc.expected, parseCgroupMountPoints(contents))
+	}
+}
+
+func TestParseUnifiedCgroupPaths(t *testing.T) {
+	containerID, paths, err := parseCgroupPaths(strings.NewReader(
+		"0::/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope"))
+	assert.Nil(t, err)
+	assert.Equal(t, "47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e", containerID)
+	assert.Equal(t, map[string]string{
+		"unified": "/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope",
+	}, paths)
+}
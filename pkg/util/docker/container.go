@@ -34,6 +34,8 @@ type Container struct {
 	SoftMemLimit   uint64
 	MemLimit       uint64
 	CPUNrThrottled uint64
+	ThreadCount    uint64
+	ThreadLimit    uint64
 	CPU            *CgroupTimesStat
 	Memory         *CgroupMemStat
 	IO             *CgroupIOStat
@@ -22,6 +22,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
 )
@@ -64,7 +65,7 @@ func (d *DockerUtil) init() error {
 		CacheDuration:  10 * time.Second,
 	}
 
-	cfg.filter, err = NewFilterFromConfig()
+	cfg.filter, err = containers.NewFilterFromConfig(containers.MetricsFilter)
 	if err != nil {
 		return err
 	}
@@ -260,6 +261,10 @@ func (d *DockerUtil) Containers(cfg *ContainerListConfig) ([]*Container, error)
 			if err != nil {
 				log.Debugf("Cgroup soft mem limit: %s", err)
 			}
+			container.ThreadLimit, err = cgroup.ThreadLimit()
+			if err != nil {
+				log.Debugf("Cgroup thread limit: %s", err)
+			}
 		}
 		cache.Cache.Set(cacheKey, containers, d.cfg.CacheDuration)
 	}
@@ -304,6 +309,11 @@ func (d *DockerUtil) Containers(cfg *ContainerListConfig) ([]*Container, error)
 			log.Debugf("Cgroup i/o: %s", err)
 			continue
 		}
+		container.ThreadCount, err = cgroup.ThreadCount()
+		if err != nil {
+			log.Debugf("Cgroup thread count: %s", err)
+			continue
+		}
 
 		if d.cfg.CollectNetwork {
 			d.Lock()
@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/docker/docker/api/types"
@@ -40,9 +41,10 @@ func (d *DockerUtil) openEventChannel(ctx context.Context, since, until time.Tim
 	return msgChan, errorChan
 }
 
-// processContainerEvent formats the events from a channel.
+// processContainerEvent formats the events from a channel, skipping the ones
+// of the containers excluded by filter, nil to keep them all.
 // It can return nil, nil if the event is filtered out, one should check for nil pointers before using the event.
-func (d *DockerUtil) processContainerEvent(msg events.Message) (*ContainerEvent, error) {
+func (d *DockerUtil) processContainerEvent(msg events.Message, filter *containers.Filter) (*ContainerEvent, error) {
 	// Type filtering
 	if msg.Type != "container" {
 		return nil, nil
@@ -68,7 +70,7 @@ func (d *DockerUtil) processContainerEvent(msg events.Message) (*ContainerEvent,
 			log.Warnf("can't resolve image name %s: %s", imageName, err)
 		}
 	}
-	if d.cfg.filter.IsExcluded(containerName, imageName) {
+	if filter.IsExcluded(containerName, imageName) {
 		log.Tracef("events from %s are skipped as the image is excluded for the event collection", containerName)
 		return nil, nil
 	}
@@ -116,7 +118,7 @@ func (d *DockerUtil) LatestContainerEvents(since time.Time) ([]*ContainerEvent,
 	for {
 		select {
 		case msg := <-msgChan:
-			event, err := d.processContainerEvent(msg)
+			event, err := d.processContainerEvent(msg, d.cfg.filter)
 			if err != nil {
 				log.Warnf("error parsing docker message: %s", err)
 				continue
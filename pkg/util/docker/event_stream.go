@@ -116,7 +116,8 @@ CONNECT: // Outer loop handles re-connecting in case the docker daemon closes th
 				continue CONNECT // Re-connect to docker
 			case msg := <-messages:
 				latestTimestamp = msg.Time
-				event, err := d.processContainerEvent(msg)
+				// the subscribers filter the containers of their own scope
+				event, err := d.processContainerEvent(msg, nil)
 				if err != nil {
 					log.Debugf("Skipping event: %s", err)
 					continue
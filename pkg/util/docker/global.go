@@ -11,6 +11,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
@@ -90,7 +91,7 @@ type Config struct {
 	Blacklist []string
 
 	// internal use only
-	filter *Filter
+	filter *containers.Filter
 }
 
 // Expose module-level functions that will interact with a the globalDockerUtil singleton.
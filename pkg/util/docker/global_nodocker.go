@@ -35,3 +35,8 @@ func IsContainerized() bool {
 func GetTags() ([]string, error) {
 	return []string{}, nil
 }
+
+// ContainerIDForPIDFromTop returns ErrDockerNotCompiled
+func ContainerIDForPIDFromTop(pid int) (string, error) {
+	return "", ErrDockerNotCompiled
+}
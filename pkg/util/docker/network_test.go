@@ -111,6 +111,7 @@ func TestFindDockerNetworks(t *testing.T) {
 			networks: []dockerNetwork{{iface: "eth0", dockerName: "bridge"}},
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "bridge",
 					BytesRcvd:   1345,
 					PacketsRcvd: 10,
@@ -151,6 +152,7 @@ func TestFindDockerNetworks(t *testing.T) {
 			networks: []dockerNetwork{{iface: "eth0", dockerName: "bridge"}},
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "bridge",
 					BytesRcvd:   1345,
 					PacketsRcvd: 10,
@@ -191,6 +193,7 @@ func TestFindDockerNetworks(t *testing.T) {
 			networks: []dockerNetwork{{iface: "eth0", dockerName: "bridge"}},
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "bridge",
 					BytesRcvd:   1345,
 					PacketsRcvd: 10,
@@ -236,6 +239,7 @@ func TestFindDockerNetworks(t *testing.T) {
 			networks: []dockerNetwork{{iface: "eth0", dockerName: "eth0"}},
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "eth0",
 					BytesRcvd:   1296,
 					PacketsRcvd: 16,
@@ -282,6 +286,7 @@ func TestFindDockerNetworks(t *testing.T) {
 			networks: []dockerNetwork{{iface: "eth0", dockerName: "eth0"}},
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "eth0",
 					BytesRcvd:   1296,
 					PacketsRcvd: 16,
@@ -323,13 +328,14 @@ func TestFindDockerNetworks(t *testing.T) {
 				 face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
 				    lo:       0       0    0    0    0     0          0         0        0       0    0    0    0     0       0          0
 				  eth0:     648       8    0    0    0     0          0         0        0       0    0    0    0     0       0          0
-				  eth1:    1478      19    0    0    0     0          0         0      182       3    0    0    0     0       0          0`),
+				  eth1:    1478      19    2    1    0     0          0         0      182       3    1    4    0     0       0          0`),
 			networks: []dockerNetwork{
 				{iface: "eth0", dockerName: "bridge"},
 				{iface: "eth1", dockerName: "test"},
 			},
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "bridge",
 					BytesRcvd:   648,
 					PacketsRcvd: 8,
@@ -337,11 +343,16 @@ func TestFindDockerNetworks(t *testing.T) {
 					PacketsSent: 0,
 				},
 				&InterfaceNetStats{
+					Interface:   "eth1",
 					NetworkName: "test",
 					BytesRcvd:   1478,
 					PacketsRcvd: 19,
 					BytesSent:   182,
 					PacketsSent: 3,
+					ErrorsRcvd:  2,
+					DroppedRcvd: 1,
+					ErrorsSent:  1,
+					DroppedSent: 4,
 				},
 			},
 			summedStat: &InterfaceNetStats{
@@ -349,6 +360,10 @@ func TestFindDockerNetworks(t *testing.T) {
 				PacketsRcvd: 27,
 				BytesSent:   182,
 				PacketsSent: 3,
+				ErrorsRcvd:  2,
+				DroppedRcvd: 1,
+				ErrorsSent:  1,
+				DroppedSent: 4,
 			},
 		},
 		// Fallback to interface name if bridge is not in inspect (docker swarm bug)
@@ -381,6 +396,7 @@ func TestFindDockerNetworks(t *testing.T) {
 			},
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "eth0",
 					BytesRcvd:   648,
 					PacketsRcvd: 8,
@@ -388,6 +404,7 @@ func TestFindDockerNetworks(t *testing.T) {
 					PacketsSent: 0,
 				},
 				&InterfaceNetStats{
+					Interface:   "eth1",
 					NetworkName: "test",
 					BytesRcvd:   1478,
 					PacketsRcvd: 19,
@@ -433,6 +450,7 @@ func TestFindDockerNetworks(t *testing.T) {
             `),
 			stat: ContainerNetStats{
 				&InterfaceNetStats{
+					Interface:   "eth0",
 					NetworkName: "eth0",
 					BytesRcvd:   1111,
 					PacketsRcvd: 2,
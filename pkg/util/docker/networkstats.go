@@ -23,6 +23,10 @@ func (ns ContainerNetStats) SumInterfaces() *InterfaceNetStats {
 		sum.BytesRcvd += stat.BytesRcvd
 		sum.PacketsSent += stat.PacketsSent
 		sum.PacketsRcvd += stat.PacketsRcvd
+		sum.ErrorsSent += stat.ErrorsSent
+		sum.ErrorsRcvd += stat.ErrorsRcvd
+		sum.DroppedSent += stat.DroppedSent
+		sum.DroppedRcvd += stat.DroppedRcvd
 	}
 	return sum
 }
@@ -58,7 +62,7 @@ func collectNetworkStats(containerID string, pid int, networks []dockerNetwork)
 	//
 	for _, line := range lines[2:] {
 		fields := strings.Fields(line)
-		if len(fields) < 11 {
+		if len(fields) < 13 {
 			continue
 		}
 		iface := fields[0][:len(fields[0])-1]
@@ -66,22 +70,30 @@ func collectNetworkStats(containerID string, pid int, networks []dockerNetwork)
 		var stat *InterfaceNetStats
 
 		if nw, ok := nwByIface[iface]; ok {
-			stat = &InterfaceNetStats{NetworkName: nw.dockerName}
+			stat = &InterfaceNetStats{NetworkName: nw.dockerName, Interface: iface}
 		} else if iface == "lo" {
 			continue // Ignore loopback
 		} else {
 			log.Debugf("Container %s: interface %s does not match a network, tagging with interface name", containerID, iface)
-			stat = &InterfaceNetStats{NetworkName: iface}
+			stat = &InterfaceNetStats{NetworkName: iface, Interface: iface}
 		}
 
 		rcvd, _ := strconv.Atoi(fields[1])
 		stat.BytesRcvd = uint64(rcvd)
 		pktRcvd, _ := strconv.Atoi(fields[2])
 		stat.PacketsRcvd = uint64(pktRcvd)
+		errRcvd, _ := strconv.Atoi(fields[3])
+		stat.ErrorsRcvd = uint64(errRcvd)
+		dropRcvd, _ := strconv.Atoi(fields[4])
+		stat.DroppedRcvd = uint64(dropRcvd)
 		sent, _ := strconv.Atoi(fields[9])
 		stat.BytesSent = uint64(sent)
 		pktSent, _ := strconv.Atoi(fields[10])
 		stat.PacketsSent = uint64(pktSent)
+		errSent, _ := strconv.Atoi(fields[11])
+		stat.ErrorsSent = uint64(errSent)
+		dropSent, _ := strconv.Atoi(fields[12])
+		stat.DroppedSent = uint64(dropSent)
 
 		netStats = append(netStats, stat)
 	}
@@ -8,10 +8,15 @@ package docker
 // InterfaceNetStats stores network statistics about a Docker network interface
 type InterfaceNetStats struct {
 	NetworkName string
+	Interface   string
 	BytesSent   uint64
 	BytesRcvd   uint64
 	PacketsSent uint64
 	PacketsRcvd uint64
+	ErrorsSent  uint64
+	ErrorsRcvd  uint64
+	DroppedSent uint64
+	DroppedRcvd uint64
 }
 
 // ContainerNetStats stores network statistics about a Docker container per interface
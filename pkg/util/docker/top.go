@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package docker
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+)
+
+// ContainerIDForPIDFromTop returns the ID of the running container of the PID,
+// empty if the PID is not in a container. It lists the processes of every
+// container, for the hosts without cgroups, e.g. the Windows containers, and
+// its result should be cached.
+func ContainerIDForPIDFromTop(pid int) (string, error) {
+	du, err := GetDockerUtil()
+	if err != nil {
+		return "", err
+	}
+	containers, err := du.RawContainerList(types.ContainerListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	pidStr := strconv.Itoa(pid)
+	for _, container := range containers {
+		ctx, cancel := context.WithTimeout(context.Background(), du.queryTimeout)
+		top, err := du.cli.ContainerTop(ctx, container.ID, nil)
+		cancel()
+		if err != nil {
+			// the container may have exited in the meantime
+			continue
+		}
+
+		pidColumn := -1
+		for i, title := range top.Titles {
+			if title == "PID" {
+				pidColumn = i
+				break
+			}
+		}
+		if pidColumn == -1 {
+			continue
+		}
+		for _, process := range top.Processes {
+			if len(process) > pidColumn && process[pidColumn] == pidStr {
+				return container.ID, nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -34,7 +34,14 @@ type Container struct {
 	DockerID      string            `json:"DockerID"`
 	CreatedAt     string            `json:"CreatedAt"`
 	Networks      []Network         `json:"Networks"`
-	Ports         string            `json:"Ports"`
+	Ports         []Port            `json:"Ports"`
+}
+
+// Port represents a port mapping of a container
+type Port struct {
+	ContainerPort int    `json:"ContainerPort"`
+	Protocol      string `json:"Protocol"`
+	HostPort      int    `json:"HostPort,omitempty"`
 }
 
 // Network represents the network of a container
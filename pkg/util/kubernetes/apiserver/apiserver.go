@@ -18,6 +18,7 @@ import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -40,8 +41,9 @@ const (
 	configMapDCAToken         = "datadogtoken"
 	tokenTime                 = "tokenTimestamp"
 	tokenKey                  = "tokenKey"
-	metadataMapExpire         = 2 * time.Minute
 	metadataMapperCachePrefix = "KubernetesMetadataMapping"
+	nodeLabelsCachePrefix     = "KubernetesNodeLabels"
+	contentTypeProtobuf       = "application/vnd.kubernetes.protobuf"
 )
 
 // APIClient provides authenticated access to the
@@ -49,9 +51,25 @@ const (
 type APIClient struct {
 	// used to setup the APIClient
 	initRetry        retry.Retrier
-	Cl               kubernetes.Interface
 	timeoutSeconds   int64
 	metadataPollIntl time.Duration
+	// client set of the apiserver, re-created when the certificate authority
+	// is rotated or on failover: clientLock guards it and the fields used to
+	// re-create it
+	cl         kubernetes.Interface
+	clientLock sync.RWMutex
+	caFile     string
+	caModTime  time.Time
+	// index of the apiserver endpoint in use when several are configured,
+	// whose health is checked in the background
+	endpointIndex   int
+	healthCheckOnce sync.Once
+	// cached result of the detection of OpenShift APIs, guarded by openShiftAPILevelLock
+	openShiftAPILevel     OpenShiftAPILevel
+	openShiftAPILevelLock sync.Mutex
+	// informers shared by the consumers, re-created with the client set
+	informerFactory informers.SharedInformerFactory
+	informersStop   chan struct{}
 }
 
 // GetAPIClient returns the shared ApiClient instance.
@@ -74,15 +92,36 @@ func GetAPIClient() (*APIClient, error) {
 		log.Debugf("API Server init error: %s", err)
 		return nil, err
 	}
+	globalAPIClient.reloadIfCAChanged()
+	globalAPIClient.startHealthChecks()
 	return globalAPIClient, nil
 }
 
+// Client returns the client set of the apiserver. Callers should get it again
+// rather than keep it, as it is replaced when the certificate authority is
+// rotated or when failing over to another endpoint.
+func (c *APIClient) Client() kubernetes.Interface {
+	c.clientLock.RLock()
+	defer c.clientLock.RUnlock()
+	return c.cl
+}
+
+// setClient replaces the client set with the one of the endpoint at index,
+// recording the certificate authority it was built with.
+func (c *APIClient) setClient(cl kubernetes.Interface, k8sConfig *rest.Config, index int) {
+	c.clientLock.Lock()
+	defer c.clientLock.Unlock()
+	if c.cl != cl {
+		c.stopInformers()
+	}
+	c.cl = cl
+	c.endpointIndex = index
+	c.caFile = k8sConfig.TLSClientConfig.CAFile
+	c.caModTime = fileModTime(c.caFile)
+}
+
 // getClientSet returns the generic kubernetes client set
-func getClientSet() (*kubernetes.Clientset, error) {
-	k8sConfig, err := getK8sConfig()
-	if err != nil {
-		return nil, err
-	}
+func getClientSet(k8sConfig *rest.Config) (*kubernetes.Clientset, error) {
 	clientSet, err := kubernetes.NewForConfig(k8sConfig)
 	if err != nil {
 		log.Debugf("Could not create the ClientSet: %s", err)
@@ -103,6 +142,9 @@ func getK8sConfig() (*rest.Config, error) {
 			log.Debugf("Can't create a config for the official client from the service account's token: %s", err)
 			return nil, err
 		}
+		// The token is read from disk by the transport to support token rotation
+		k8sConfig.BearerToken = ""
+		k8sConfig.WrapTransport = wrapWithServiceAccountToken
 	} else {
 		// use the current context in kubeconfig
 		k8sConfig, err = clientcmd.BuildConfigFromFlags("", cfgPath)
@@ -111,20 +153,52 @@ func getK8sConfig() (*rest.Config, error) {
 			return nil, err
 		}
 	}
-	k8sConfig.Timeout = 2 * time.Second
+	applyClientOptions(k8sConfig)
 	return k8sConfig, nil
 }
 
+// applyClientOptions sets the timeout, rate limiting and serialization options of the client
+func applyClientOptions(k8sConfig *rest.Config) {
+	k8sConfig.Timeout = time.Duration(config.Datadog.GetInt64("kubernetes_apiserver_request_timeout")) * time.Second
+	// Zero values keep the client-go defaults (5 QPS, burst of 10)
+	if qps := config.Datadog.GetFloat64("kubernetes_apiserver_client_qps"); qps > 0 {
+		k8sConfig.QPS = float32(qps)
+	}
+	if burst := config.Datadog.GetInt("kubernetes_apiserver_client_burst"); burst > 0 {
+		k8sConfig.Burst = burst
+	}
+	if config.Datadog.GetBool("kubernetes_apiserver_use_protobuf") {
+		// Decoding protobuf is much cheaper than JSON on large clusters.
+		// Non built-in resources (e.g. OpenShift) are still served in JSON,
+		// the raw requests decoded with encoding/json must accept JSON only.
+		k8sConfig.ContentType = contentTypeProtobuf
+		k8sConfig.AcceptContentTypes = fmt.Sprintf("%s,%s", contentTypeProtobuf, runtime.ContentTypeJSON)
+	}
+}
+
 func (c *APIClient) connect() error {
-	var err error
-	c.Cl, err = getClientSet()
+	k8sConfigs, err := getK8sConfigs()
+	if err != nil {
+		log.Errorf("Not able to set up a client for the API Server: %s", err)
+		return err
+	}
+	if len(k8sConfigs) == 1 {
+		var cl *kubernetes.Clientset
+		cl, err = getClientSet(k8sConfigs[0])
+		if err == nil {
+			c.setClient(cl, k8sConfigs[0], 0)
+		}
+	} else {
+		_, err = c.selectHealthyEndpoint(k8sConfigs, c.currentEndpointIndex())
+	}
 	if err != nil {
 		// We do not return an error as the HPA is an option that should not prevent the DCA to work.
 		log.Errorf("Not able to set up a client for the API Server: %s", err)
 		return err
 	}
+
 	// Try to get apiserver version to confim connectivity
-	APIversion := c.Cl.Discovery().RESTClient().APIVersion()
+	APIversion := c.Client().Discovery().RESTClient().APIVersion()
 	if APIversion.Empty() {
 		return fmt.Errorf("cannot retrieve the version of the API server at the moment")
 	}
@@ -142,15 +216,22 @@ func (c *APIClient) connect() error {
 //
 // It is updated by mapServices in services.go.
 type MetadataMapperBundle struct {
-	Services ServicesMapper `json:"services,omitempty"`
-	mapOnIP  bool           // temporary opt-out of the new mapping logic
-	m        sync.RWMutex
+	Services   ServicesMapper   `json:"services,omitempty"`
+	Namespaces NamespacesMapper `json:"namespaces,omitempty"`
+	Routes     RoutesMapper     `json:"routes,omitempty"`
+	// Version is incremented every time the bundle of a node is rebuilt
+	Version uint64 `json:"version"`
+	// fingerprint of the endpoints targeting the node, used to detect changes
+	endpointsFingerprint string
+	mapOnIP              bool // temporary opt-out of the new mapping logic
+	m                    sync.RWMutex
 }
 
 func newMetadataMapperBundle() *MetadataMapperBundle {
 	return &MetadataMapperBundle{
-		Services: make(ServicesMapper),
-		mapOnIP:  config.Datadog.GetBool("kubernetes_map_services_on_ip"),
+		Services:   make(ServicesMapper),
+		Namespaces: make(NamespacesMapper),
+		mapOnIP:    config.Datadog.GetBool("kubernetes_map_services_on_ip"),
 	}
 }
 
@@ -158,7 +239,7 @@ func newMetadataMapperBundle() *MetadataMapperBundle {
 // node to the cache
 // Only called when the node agent computes the metadata mapper locally and does not rely on the DCA.
 func (c *APIClient) NodeMetadataMapping(nodeName string, podList *v1.PodList) error {
-	endpointList, err := c.Cl.CoreV1().Endpoints("").List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+	endpointList, err := c.listEndpoints()
 	if err != nil {
 		log.Errorf("Could not collect endpoints from the API Server: %q", err.Error())
 		return err
@@ -169,13 +250,23 @@ func (c *APIClient) NodeMetadataMapping(nodeName string, podList *v1.PodList) er
 	}
 	log.Debugf("Successfully collected endpoints")
 
+	namespaceList, err := c.namespacesForLabelsAsTags()
+	if err != nil {
+		log.Errorf("Could not collect namespaces from the API Server: %q", err.Error())
+	}
+
+	routes, err := c.listOpenShiftRoutes()
+	if err != nil {
+		log.Errorf("Could not collect OpenShift routes from the API Server: %q", err.Error())
+	}
+
 	var node v1.Node
 	var nodeList v1.NodeList
 	node.Name = nodeName
 
 	nodeList.Items = append(nodeList.Items, node)
 
-	processKubeServices(&nodeList, podList, endpointList)
+	processKubeServices(&nodeList, podList, endpointList, namespaceList, buildRoutesMapper(routes))
 	return nil
 }
 
@@ -184,78 +275,124 @@ func (c *APIClient) NodeMetadataMapping(nodeName string, podList *v1.PodList) er
 // - all endpoints of all namespaces
 // - all pods of all namespaces
 // Then it stores in cache the MetadataMapperBundle of each node.
+// If kubernetes_metadata_namespaces is set, only the endpoints and pods of these namespaces are queried,
+// and the nodes are inferred from the pods as they cannot be listed without cluster-wide permissions.
 func (c *APIClient) ClusterMetadataMapping() error {
-	// A poll run should take less than the poll frequency.
-	// We fetch nodes to reliably use nodename as key in the cache.
-	// Avoiding to retrieve them from the endpoints/podList.
-	nodeList, err := c.Cl.CoreV1().Nodes().List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
-	if err != nil {
-		log.Errorf("Could not collect nodes from the kube-apiserver: %q", err.Error())
-		return err
-	}
-	if nodeList.Items == nil {
-		log.Debug("No node collected from the kube-apiserver")
-		return nil
+	start := time.Now()
+	err := c.clusterMetadataMapping()
+	recordMappingRun(start, err)
+	return err
+}
+
+func (c *APIClient) clusterMetadataMapping() error {
+	var nodeList *v1.NodeList
+	var err error
+
+	namespaceScoped := len(getMetadataNamespaces()) > 0
+	if !namespaceScoped {
+		// A poll run should take less than the poll frequency.
+		// We fetch nodes to reliably use nodename as key in the cache.
+		// Avoiding to retrieve them from the endpoints/podList.
+		nodeList, err = c.Client().CoreV1().Nodes().List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+		if err != nil {
+			log.Errorf("Could not collect nodes from the kube-apiserver: %q", err.Error())
+			recordListResult("nodes", 0, err)
+			return err
+		}
+		recordListResult("nodes", len(nodeList.Items), nil)
+		if nodeList.Items == nil {
+			log.Debug("No node collected from the kube-apiserver")
+			return nil
+		}
+		storeNodeLabels(nodeList)
 	}
 
-	endpointList, err := c.Cl.CoreV1().Endpoints("").List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+	endpointList, err := c.listEndpoints()
 	if err != nil {
 		log.Errorf("Could not collect endpoints from the kube-apiserver: %q", err.Error())
+		recordListResult("endpoints", 0, err)
 		return err
 	}
+	recordListResult("endpoints", len(endpointList.Items), nil)
 	if endpointList.Items == nil {
 		log.Debug("No endpoint collected from the kube-apiserver")
 		return nil
 	}
 
-	podList, err := c.Cl.CoreV1().Pods("").List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+	podList, err := c.listPods()
 	if err != nil {
 		log.Errorf("Could not collect pods from the kube-apiserver: %q", err.Error())
+		recordListResult("pods", 0, err)
 		return err
 	}
+	recordListResult("pods", len(podList.Items), nil)
 	if podList.Items == nil {
 		log.Debug("No pod collected from the kube-apiserver")
 		return nil
 	}
+	if namespaceScoped {
+		nodeList = nodesFromPods(podList)
+	}
+
+	namespaceList, err := c.namespacesForLabelsAsTags()
+	if err != nil {
+		log.Errorf("Could not collect namespaces from the kube-apiserver: %q", err.Error())
+		recordListResult("namespaces", 0, err)
+	} else if namespaceList != nil {
+		recordListResult("namespaces", len(namespaceList.Items), nil)
+	}
+
+	routes, err := c.listOpenShiftRoutes()
+	if err != nil {
+		log.Errorf("Could not collect OpenShift routes from the kube-apiserver: %q", err.Error())
+		recordListResult("routes", 0, err)
+	} else if routes != nil {
+		recordListResult("routes", len(routes), nil)
+	}
 
-	processKubeServices(nodeList, podList, endpointList)
+	processKubeServices(nodeList, podList, endpointList, namespaceList, buildRoutesMapper(routes))
 	return nil
 }
 
 // processKubeServices adds services to the metadataMapper cache, pointer parameters must be non nil
-func processKubeServices(nodeList *v1.NodeList, podList *v1.PodList, endpointList *v1.EndpointsList) {
+// except namespaceList, which is nil when namespace labels are not collected.
+// A new bundle is built for every node and swapped in the cache once complete, so that
+// consumers never read a partially updated mapping.
+func processKubeServices(nodeList *v1.NodeList, podList *v1.PodList, endpointList *v1.EndpointsList, namespaceList *v1.NamespaceList, routes RoutesMapper) {
 	if nodeList.Items == nil || podList.Items == nil || endpointList.Items == nil {
 		return
 	}
 	log.Debugf("Identified: %d node, %d pod, %d endpoints", len(nodeList.Items), len(podList.Items), len(endpointList.Items))
+	ttl := getMetadataMapExpire()
 	for _, node := range nodeList.Items {
 		nodeName := node.Name
 		nodeNameCacheKey := cache.BuildAgentKey(metadataMapperCachePrefix, nodeName)
-		freshness := cache.BuildAgentKey(metadataMapperCachePrefix, nodeName, "freshness")
 
-		metaBundle, found := cache.Cache.Get(nodeNameCacheKey)       // We get the old one with the dead pods. if diff reset metabundle and deledte key. Then compute again.
-		freshnessCache, freshnessFound := cache.Cache.Get(freshness) // if expired, freshness not found deal with that
-
-		if !found {
-			metaBundle = newMetadataMapperBundle()
-			cache.Cache.Set(freshness, len(podList.Items), metadataMapExpire)
+		metaBundle := newMetadataMapperBundle()
+		err := metaBundle.mapServices(nodeName, *podList, *endpointList)
+		if err != nil {
+			log.Errorf("Could not map the services on node %s: %s", node.Name, err.Error())
+			continue
+		}
+		if namespaceList != nil {
+			metaBundle.mapNamespaces(*namespaceList, getNamespaceLabelsAsTags())
 		}
+		metaBundle.mapRoutes(routes)
+		metaBundle.endpointsFingerprint = endpointsFingerprint(nodeName, *endpointList)
 
-		// We want to churn the cache every `metadataMapExpire` and if the number of entries varies between 2 runs..
-		// If a pod is killed and rescheduled during a run, we will only keep the old entry for another run, which is acceptable.
-		if found && freshnessCache != len(podList.Items) || !freshnessFound {
-			cache.Cache.Delete(nodeNameCacheKey)
-			metaBundle = newMetadataMapperBundle()
-			cache.Cache.Set(freshness, len(podList.Items), metadataMapExpire)
-			log.Debugf("Refreshing cache for %s", nodeNameCacheKey)
+		var previous *MetadataMapperBundle
+		if cached, found := cache.GetShared(nodeNameCacheKey, ttl, decodeMetadataMapperBundle); found {
+			previous, _ = cached.(*MetadataMapperBundle)
 		}
+		if previous != nil {
+			metaBundle.Version = previous.Version + 1
+		}
+		cache.SetShared(nodeNameCacheKey, metaBundle, ttl)
 
-		err := metaBundle.(*MetadataMapperBundle).mapServices(nodeName, *podList, *endpointList)
-		if err != nil {
-			log.Errorf("Could not map the services on node %s: %s", node.Name, err.Error())
-			continue
+		if previous != nil && previous.endpointsFingerprint != metaBundle.endpointsFingerprint {
+			log.Debugf("Endpoints changed on node %s, metadata bundle now at version %d", nodeName, metaBundle.Version)
+			notifyMetadataMapperInvalidation(nodeName, metaBundle.Version)
 		}
-		cache.Cache.Set(nodeNameCacheKey, metaBundle, metadataMapExpire)
 	}
 }
 
@@ -288,8 +425,16 @@ func aggregateCheckResourcesErrors(errorMessages []string) error {
 func (c *APIClient) checkResourcesAuth() error {
 	var errorMessages []string
 
+	// Cluster-wide event collection is not expected to be allowed in namespace-scoped mode
+	if namespaces := getMetadataNamespaces(); len(namespaces) > 0 {
+		if config.Datadog.GetBool("kubernetes_collect_metadata_tags") == false {
+			return nil
+		}
+		return aggregateCheckResourcesErrors(c.checkNamespacedResourcesAuth(namespaces))
+	}
+
 	// We always want to collect events
-	_, err := c.Cl.CoreV1().Events("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
+	_, err := c.Client().CoreV1().Events("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
 	if err != nil {
 		errorMessages = append(errorMessages, fmt.Sprintf("event collection: %q", err.Error()))
 		if !isConnectVerbose {
@@ -300,21 +445,21 @@ func (c *APIClient) checkResourcesAuth() error {
 	if config.Datadog.GetBool("kubernetes_collect_metadata_tags") == false {
 		return aggregateCheckResourcesErrors(errorMessages)
 	}
-	_, err = c.Cl.CoreV1().Services("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
+	_, err = c.Client().CoreV1().Services("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
 	if err != nil {
 		errorMessages = append(errorMessages, fmt.Sprintf("service collection: %q", err.Error()))
 		if !isConnectVerbose {
 			return aggregateCheckResourcesErrors(errorMessages)
 		}
 	}
-	_, err = c.Cl.CoreV1().Pods("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
+	_, err = c.Client().CoreV1().Pods("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
 	if err != nil {
 		errorMessages = append(errorMessages, fmt.Sprintf("pod collection: %q", err.Error()))
 		if !isConnectVerbose {
 			return aggregateCheckResourcesErrors(errorMessages)
 		}
 	}
-	_, err = c.Cl.CoreV1().Nodes().List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
+	_, err = c.Client().CoreV1().Nodes().List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
 
 	if err != nil {
 		errorMessages = append(errorMessages, fmt.Sprintf("node collection: %q", err.Error()))
@@ -324,13 +469,13 @@ func (c *APIClient) checkResourcesAuth() error {
 
 // ComponentStatuses returns the component status list from the APIServer
 func (c *APIClient) ComponentStatuses() (*v1.ComponentStatusList, error) {
-	return c.Cl.CoreV1().ComponentStatuses().List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+	return c.Client().CoreV1().ComponentStatuses().List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
 }
 
 // GetTokenFromConfigmap returns the value of the `tokenValue` from the `tokenKey` in the ConfigMap `configMapDCAToken` if its timestamp is less than tokenTimeout old.
 func (c *APIClient) GetTokenFromConfigmap(token string, tokenTimeout int64) (string, bool, error) {
 	namespace := GetResourcesNamespace()
-	tokenConfigMap, err := c.Cl.CoreV1().ConfigMaps(namespace).Get(configMapDCAToken, metav1.GetOptions{})
+	tokenConfigMap, err := c.Client().CoreV1().ConfigMaps(namespace).Get(configMapDCAToken, metav1.GetOptions{})
 	if err != nil {
 		log.Debugf("Could not find the ConfigMap %s: %s", configMapDCAToken, err.Error())
 		return "", false, ErrNotFound
@@ -372,7 +517,7 @@ func (c *APIClient) GetTokenFromConfigmap(token string, tokenTimeout int64) (str
 // sets its collected timestamp in the ConfigMap `configmaptokendca`
 func (c *APIClient) UpdateTokenInConfigmap(token, tokenValue string) error {
 	namespace := GetResourcesNamespace()
-	tokenConfigMap, err := c.Cl.CoreV1().ConfigMaps(namespace).Get(configMapDCAToken, metav1.GetOptions{})
+	tokenConfigMap, err := c.Client().CoreV1().ConfigMaps(namespace).Get(configMapDCAToken, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -384,7 +529,7 @@ func (c *APIClient) UpdateTokenInConfigmap(token, tokenValue string) error {
 	eventTokenTS := fmt.Sprintf("%s.%s", token, tokenTime)
 	tokenConfigMap.Data[eventTokenTS] = now.Format(time.RFC822) // Timestamps in the ConfigMap should all use the type int.
 
-	_, err = c.Cl.CoreV1().ConfigMaps(namespace).Update(tokenConfigMap)
+	_, err = c.Client().CoreV1().ConfigMaps(namespace).Update(tokenConfigMap)
 	if err != nil {
 		return err
 	}
@@ -394,7 +539,7 @@ func (c *APIClient) UpdateTokenInConfigmap(token, tokenValue string) error {
 
 // NodeLabels is used to fetch the labels attached to a given node.
 func (c *APIClient) NodeLabels(nodeName string) (map[string]string, error) {
-	node, err := c.Cl.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	node, err := c.Client().CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -448,7 +593,7 @@ func GetMetadataMapBundleOnNode(nodeName string) (map[string]interface{}, error)
 
 func getMetadataMapBundle(nodeName string) (*MetadataMapperBundle, error) {
 	nodeNameCacheKey := cache.BuildAgentKey(metadataMapperCachePrefix, nodeName)
-	metaBundle, found := cache.Cache.Get(nodeNameCacheKey)
+	metaBundle, found := cache.GetShared(nodeNameCacheKey, getMetadataMapExpire(), decodeMetadataMapperBundle)
 	if !found {
 		return nil, fmt.Errorf("the key %s was not found in the cache", nodeNameCacheKey)
 	}
@@ -461,7 +606,7 @@ func getNodeList() ([]v1.Node, error) {
 		log.Errorf("Can't create client to query the API Server: %s", err.Error())
 		return nil, err
 	}
-	nodes, err := cl.Cl.CoreV1().Nodes().List(metav1.ListOptions{TimeoutSeconds: &cl.timeoutSeconds})
+	nodes, err := cl.Client().CoreV1().Nodes().List(metav1.ListOptions{TimeoutSeconds: &cl.timeoutSeconds})
 
 	if err != nil {
 		log.Errorf("Can't list nodes from the API server: %s", err.Error())
@@ -488,7 +633,7 @@ func GetResourcesNamespace() string {
 
 // GetRESTObject allows to retrive a custom resource from the APIserver
 func (c *APIClient) GetRESTObject(path string, output runtime.Object) error {
-	result := c.Cl.CoreV1().RESTClient().Get().AbsPath(path).Do()
+	result := c.Client().CoreV1().RESTClient().Get().AbsPath(path).Do()
 	if result.Error() != nil {
 		return result.Error()
 	}
@@ -31,6 +31,12 @@ func GetPodMetadataNames(nodeName, ns, podName string) ([]string, error) {
 	return nil, nil
 }
 
+// GetNodeLabels is used when the API endpoint of the DCA to get the labels of a node is hit.
+func GetNodeLabels(nodeName string) (map[string]string, error) {
+	log.Errorf("GetNodeLabels not implemented %s", ErrNotCompiled.Error())
+	return nil, nil
+}
+
 // GetMetadataMapBundleOnNode is used for the CLI svcmap command to output given a nodeName
 func GetMetadataMapBundleOnNode(nodeName string) (map[string]interface{}, error) {
 	log.Errorf("GetMetadataMapBundleOnNode not implemented %s", ErrNotCompiled.Error())
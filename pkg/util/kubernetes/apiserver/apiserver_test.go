@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const testKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abcdef
+`
+
+func writeTestKubeConfig(t *testing.T) string {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	_, err = f.WriteString(testKubeConfig)
+	require.NoError(t, err)
+	f.Close()
+	return f.Name()
+}
+
+func TestGetK8sConfigRateLimiting(t *testing.T) {
+	cfgPath := writeTestKubeConfig(t)
+	defer os.Remove(cfgPath)
+
+	config.Datadog.Set("kubernetes_kubeconfig_path", cfgPath)
+	defer config.Datadog.Set("kubernetes_kubeconfig_path", "")
+
+	// Defaults keep the client-go rate limiting
+	k8sConfig, err := getK8sConfig()
+	require.NoError(t, err)
+	assert.Equal(t, float32(0), k8sConfig.QPS)
+	assert.Equal(t, 0, k8sConfig.Burst)
+	assert.Equal(t, 2*time.Second, k8sConfig.Timeout)
+
+	config.Datadog.Set("kubernetes_apiserver_client_qps", 50)
+	config.Datadog.Set("kubernetes_apiserver_client_burst", 100)
+	config.Datadog.Set("kubernetes_apiserver_request_timeout", 15)
+	defer func() {
+		config.Datadog.Set("kubernetes_apiserver_client_qps", 0)
+		config.Datadog.Set("kubernetes_apiserver_client_burst", 0)
+		config.Datadog.Set("kubernetes_apiserver_request_timeout", 2)
+	}()
+
+	k8sConfig, err = getK8sConfig()
+	require.NoError(t, err)
+	assert.Equal(t, float32(50), k8sConfig.QPS)
+	assert.Equal(t, 100, k8sConfig.Burst)
+	assert.Equal(t, 15*time.Second, k8sConfig.Timeout)
+}
+
+func TestGetK8sConfigProtobuf(t *testing.T) {
+	cfgPath := writeTestKubeConfig(t)
+	defer os.Remove(cfgPath)
+
+	config.Datadog.Set("kubernetes_kubeconfig_path", cfgPath)
+	defer config.Datadog.Set("kubernetes_kubeconfig_path", "")
+
+	k8sConfig, err := getK8sConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "", k8sConfig.ContentType)
+
+	config.Datadog.Set("kubernetes_apiserver_use_protobuf", true)
+	defer config.Datadog.Set("kubernetes_apiserver_use_protobuf", false)
+
+	k8sConfig, err = getK8sConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "application/vnd.kubernetes.protobuf", k8sConfig.ContentType)
+	assert.Equal(t, "application/vnd.kubernetes.protobuf,application/json", k8sConfig.AcceptContentTypes)
+}
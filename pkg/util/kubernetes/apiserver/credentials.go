@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// tokenFileRoundTripper sets the Authorization header of every request from the
+// content of a token file. The file is read again when it is modified, or when the
+// apiserver rejects the current token, so that rotated service account tokens
+// are picked up without restarting the agent.
+type tokenFileRoundTripper struct {
+	path    string
+	rt      http.RoundTripper
+	m       sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+func newTokenFileRoundTripper(path string, rt http.RoundTripper) *tokenFileRoundTripper {
+	return &tokenFileRoundTripper{
+		path: path,
+		rt:   rt,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *tokenFileRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(req.Header.Get("Authorization")) != 0 {
+		return t.rt.RoundTrip(req)
+	}
+	token, err := t.getToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the original request
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := t.rt.RoundTrip(r)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		log.Debugf("Got a 401 from the apiserver, the token from %s will be read again on the next request", t.path)
+		t.invalidate()
+	}
+	return resp, err
+}
+
+// getToken returns the cached token, reading the file again if it was modified
+func (t *tokenFileRoundTripper) getToken() (string, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		if t.token != "" {
+			log.Debugf("Could not stat %s, using the cached token: %s", t.path, err)
+			return t.token, nil
+		}
+		return "", err
+	}
+	if t.token != "" && info.ModTime().Equal(t.modTime) {
+		return t.token, nil
+	}
+
+	token, err := kubernetes.GetBearerToken(t.path)
+	if err != nil {
+		return "", err
+	}
+	t.token = strings.TrimSpace(token)
+	t.modTime = info.ModTime()
+	log.Debugf("Loaded the apiserver token from %s", t.path)
+	return t.token, nil
+}
+
+func (t *tokenFileRoundTripper) invalidate() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.token = ""
+}
+
+// fileModTime returns the modification time of a file, or the zero time if it
+// cannot be accessed.
+func fileModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// wrapWithServiceAccountToken is used as the WrapTransport of the in-cluster
+// configuration to read the service account token from disk.
+func wrapWithServiceAccountToken(rt http.RoundTripper) http.RoundTripper {
+	return newTokenFileRoundTripper(kubernetes.ServiceAccountTokenPath, rt)
+}
+
+// reloadIfCAChanged re-creates the client set when the certificate authority
+// file was modified since the client was built. The TLS configuration of the
+// client cannot be updated in place.
+func (c *APIClient) reloadIfCAChanged() {
+	c.clientLock.Lock()
+	defer c.clientLock.Unlock()
+
+	if c.caFile == "" {
+		return
+	}
+	modTime := fileModTime(c.caFile)
+	if modTime.IsZero() || modTime.Equal(c.caModTime) {
+		return
+	}
+	log.Infof("The certificate authority %s was modified, re-creating the apiserver client", c.caFile)
+	k8sConfig, err := getK8sConfigAt(c.endpointIndex)
+	if err != nil {
+		log.Errorf("Could not reload the apiserver client configuration: %s", err)
+		return
+	}
+	cl, err := getClientSet(k8sConfig)
+	if err != nil {
+		log.Errorf("Could not re-create the apiserver client: %s", err)
+		return
+	}
+	c.cl = cl
+	c.caModTime = modTime
+}
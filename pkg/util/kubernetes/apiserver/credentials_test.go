@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenFileRoundTripper(t *testing.T) {
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		received = append(received, auth)
+		if auth == "Bearer expired" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	f, err := ioutil.TempFile("", "token")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte("first\n"), 0600))
+
+	client := &http.Client{Transport: newTokenFileRoundTripper(f.Name(), http.DefaultTransport)}
+
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// Rotated token is read again thanks to the modification time
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte("second"), 0600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(f.Name(), future, future))
+	resp, err = client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// A 401 invalidates the cached token even if the file was not modified
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte("expired"), 0600))
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(f.Name(), future, future))
+	resp, err = client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte("third"), 0600))
+	require.NoError(t, os.Chtimes(f.Name(), future, future))
+	resp, err = client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"Bearer first", "Bearer second", "Bearer expired", "Bearer third"}, received)
+}
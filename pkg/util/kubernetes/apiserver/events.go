@@ -26,8 +26,9 @@ import (
 // First slice is the new events, second slice the modified events.
 // If the `since` parameter is empty, we query the apiserver's cache to avoid
 // overloading it.
+// The fieldSelector, if not empty, is passed to the apiserver to only watch the matching events.
 // https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.9/#watch-list-289
-func (c *APIClient) LatestEvents(since string, eventReadTimeout time.Duration) ([]*v1.Event, []*v1.Event, string, error) {
+func (c *APIClient) LatestEvents(since string, eventReadTimeout time.Duration, fieldSelector string) ([]*v1.Event, []*v1.Event, string, error) {
 	var added, modified []*v1.Event
 
 	// If `since` is "" strconv.Atoi(*latestResVersion) below will panic as we evaluate the error.
@@ -41,7 +42,7 @@ func (c *APIClient) LatestEvents(since string, eventReadTimeout time.Duration) (
 
 	log.Tracef("Starting watch of events with resourceVersion %s", since)
 
-	eventWatcher, err := c.Cl.CoreV1().Events(metav1.NamespaceAll).Watch(metav1.ListOptions{Watch: true, ResourceVersion: since})
+	eventWatcher, err := c.Client().CoreV1().Events(metav1.NamespaceAll).Watch(metav1.ListOptions{Watch: true, ResourceVersion: since, FieldSelector: fieldSelector})
 	if err != nil {
 		return nil, nil, "0", fmt.Errorf("Failed to watch events: %v", err)
 	}
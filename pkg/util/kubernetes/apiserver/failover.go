@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	healthzPath                = "/healthz"
+	defaultHealthCheckInterval = 15 * time.Second
+)
+
+// getK8sConfigs returns the client configurations of the apiserver endpoints, in order of preference.
+// Entries of kubernetes_apiserver_endpoints are either URLs, overriding the host of the
+// default configuration, or names of contexts of the kubeconfig file.
+func getK8sConfigs() ([]*rest.Config, error) {
+	endpoints := config.Datadog.GetStringSlice("kubernetes_apiserver_endpoints")
+	if len(endpoints) == 0 {
+		k8sConfig, err := getK8sConfig()
+		if err != nil {
+			return nil, err
+		}
+		return []*rest.Config{k8sConfig}, nil
+	}
+
+	var k8sConfigs []*rest.Config
+	for _, endpoint := range endpoints {
+		k8sConfig, err := getK8sConfigForEndpoint(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		k8sConfigs = append(k8sConfigs, k8sConfig)
+	}
+	return k8sConfigs, nil
+}
+
+func getK8sConfigForEndpoint(endpoint string) (*rest.Config, error) {
+	if strings.Contains(endpoint, "://") {
+		k8sConfig, err := getK8sConfig()
+		if err != nil {
+			return nil, err
+		}
+		k8sConfig.Host = endpoint
+		return k8sConfig, nil
+	}
+
+	cfgPath := config.Datadog.GetString("kubernetes_kubeconfig_path")
+	if cfgPath == "" {
+		return nil, fmt.Errorf("the apiserver endpoint %q is not a URL and no kubeconfig is configured to look it up as a context", endpoint)
+	}
+	k8sConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: cfgPath},
+		&clientcmd.ConfigOverrides{CurrentContext: endpoint},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load the context %q from the kubeconfig %s: %s", endpoint, cfgPath, err)
+	}
+	applyClientOptions(k8sConfig)
+	return k8sConfig, nil
+}
+
+// getK8sConfigAt returns the configuration of the apiserver endpoint at index.
+func getK8sConfigAt(index int) (*rest.Config, error) {
+	k8sConfigs, err := getK8sConfigs()
+	if err != nil {
+		return nil, err
+	}
+	if index >= len(k8sConfigs) {
+		return k8sConfigs[0], nil
+	}
+	return k8sConfigs[index], nil
+}
+
+// currentEndpointIndex returns the index of the apiserver endpoint in use.
+func (c *APIClient) currentEndpointIndex() int {
+	c.clientLock.RLock()
+	defer c.clientLock.RUnlock()
+	return c.endpointIndex
+}
+
+// checkAPIServerHealth queries the health endpoint of the apiserver.
+func checkAPIServerHealth(cl kubernetes.Interface) error {
+	body, err := cl.Discovery().RESTClient().Get().AbsPath(healthzPath).DoRaw()
+	if err != nil {
+		return err
+	}
+	if string(body) != "ok" {
+		return fmt.Errorf("unexpected health status: %q", string(body))
+	}
+	return nil
+}
+
+// selectHealthyEndpoint sets the client to the first healthy endpoint, starting at the given index.
+func (c *APIClient) selectHealthyEndpoint(k8sConfigs []*rest.Config, start int) (*rest.Config, error) {
+	var errMessages []string
+	for i := 0; i < len(k8sConfigs); i++ {
+		index := (start + i) % len(k8sConfigs)
+		k8sConfig := k8sConfigs[index]
+		cl, err := getClientSet(k8sConfig)
+		if err == nil {
+			err = checkAPIServerHealth(cl)
+		}
+		if err != nil {
+			log.Warnf("The apiserver endpoint %s is not healthy: %s", k8sConfig.Host, err)
+			errMessages = append(errMessages, fmt.Sprintf("%s: %s", k8sConfig.Host, err))
+			continue
+		}
+		if index != c.currentEndpointIndex() {
+			log.Infof("Using the apiserver endpoint %s", k8sConfig.Host)
+		}
+		c.setClient(cl, k8sConfig, index)
+		return k8sConfig, nil
+	}
+	return nil, fmt.Errorf("no healthy apiserver endpoint: %s", strings.Join(errMessages, ", "))
+}
+
+// startHealthChecks starts checking the health of the endpoint in use every
+// kubernetes_apiserver_health_check_interval seconds in the background, to switch
+// to the next healthy endpoint if it is down. It is a no-op when a single endpoint
+// is configured, or when the checks are already running.
+func (c *APIClient) startHealthChecks() {
+	if len(config.Datadog.GetStringSlice("kubernetes_apiserver_endpoints")) < 2 {
+		return
+	}
+	c.healthCheckOnce.Do(func() {
+		interval := time.Duration(config.Datadog.GetInt64("kubernetes_apiserver_health_check_interval")) * time.Second
+		if interval <= 0 {
+			log.Warnf("Invalid kubernetes_apiserver_health_check_interval, using %s", defaultHealthCheckInterval)
+			interval = defaultHealthCheckInterval
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				c.failoverIfUnhealthy()
+			}
+		}()
+	})
+}
+
+// failoverIfUnhealthy switches to the next healthy endpoint if the one in use is down.
+func (c *APIClient) failoverIfUnhealthy() {
+	err := checkAPIServerHealth(c.Client())
+	if err == nil {
+		return
+	}
+	log.Warnf("The apiserver endpoint in use is not healthy, failing over: %s", err)
+
+	k8sConfigs, err := getK8sConfigs()
+	if err != nil {
+		log.Errorf("Could not load the apiserver endpoints configuration: %s", err)
+		return
+	}
+	if _, err = c.selectHealthyEndpoint(k8sConfigs, c.currentEndpointIndex()+1); err != nil {
+		log.Errorf("Could not fail over: %s", err)
+	}
+}
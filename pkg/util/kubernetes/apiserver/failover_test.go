@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const testMultiContextKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://10.0.0.1:6443
+  name: lb1
+- cluster:
+    server: https://10.0.0.2:6443
+  name: lb2
+contexts:
+- context:
+    cluster: lb1
+    user: test
+  name: lb1
+- context:
+    cluster: lb2
+    user: test
+  name: lb2
+current-context: lb1
+users:
+- name: test
+  user:
+    token: abcdef
+`
+
+func TestGetK8sConfigs(t *testing.T) {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(testMultiContextKubeConfig)
+	require.NoError(t, err)
+	f.Close()
+
+	config.Datadog.Set("kubernetes_kubeconfig_path", f.Name())
+	defer config.Datadog.Set("kubernetes_kubeconfig_path", "")
+
+	k8sConfigs, err := getK8sConfigs()
+	require.NoError(t, err)
+	require.Len(t, k8sConfigs, 1)
+	assert.Equal(t, "https://10.0.0.1:6443", k8sConfigs[0].Host)
+
+	config.Datadog.Set("kubernetes_apiserver_endpoints", []string{"lb2", "https://10.0.0.3:6443"})
+	defer config.Datadog.Set("kubernetes_apiserver_endpoints", []string{})
+
+	k8sConfigs, err = getK8sConfigs()
+	require.NoError(t, err)
+	require.Len(t, k8sConfigs, 2)
+	assert.Equal(t, "https://10.0.0.2:6443", k8sConfigs[0].Host)
+	assert.Equal(t, "https://10.0.0.3:6443", k8sConfigs[1].Host)
+	assert.Equal(t, "abcdef", k8sConfigs[1].BearerToken)
+
+	config.Datadog.Set("kubernetes_apiserver_endpoints", []string{"unknown"})
+	_, err = getK8sConfigs()
+	assert.Error(t, err)
+}
+
+func TestSelectHealthyEndpoint(t *testing.T) {
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer unhealthy.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != healthzPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer healthy.Close()
+
+	k8sConfigs := []*rest.Config{
+		{Host: unhealthy.URL},
+		{Host: healthy.URL},
+	}
+
+	c := &APIClient{}
+	k8sConfig, err := c.selectHealthyEndpoint(k8sConfigs, 0)
+	require.NoError(t, err)
+	assert.Equal(t, healthy.URL, k8sConfig.Host)
+	assert.Equal(t, 1, c.endpointIndex)
+	assert.NotNil(t, c.Client())
+
+	_, err = c.selectHealthyEndpoint(k8sConfigs[:1], 0)
+	assert.Error(t, err)
+}
+
+func TestFailoverIfUnhealthy(t *testing.T) {
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer unhealthy.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer healthy.Close()
+
+	f, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(testMultiContextKubeConfig)
+	require.NoError(t, err)
+	f.Close()
+
+	config.Datadog.Set("kubernetes_kubeconfig_path", f.Name())
+	defer config.Datadog.Set("kubernetes_kubeconfig_path", "")
+	config.Datadog.Set("kubernetes_apiserver_endpoints", []string{unhealthy.URL, healthy.URL})
+	defer config.Datadog.Set("kubernetes_apiserver_endpoints", []string{})
+
+	k8sConfig, err := getK8sConfigAt(0)
+	require.NoError(t, err)
+	cl, err := getClientSet(k8sConfig)
+	require.NoError(t, err)
+	c := &APIClient{}
+	c.setClient(cl, k8sConfig, 0)
+
+	c.failoverIfUnhealthy()
+	assert.Equal(t, 1, c.currentEndpointIndex())
+
+	// the healthy endpoint is kept
+	c.failoverIfUnhealthy()
+	assert.Equal(t, 1, c.currentEndpointIndex())
+}
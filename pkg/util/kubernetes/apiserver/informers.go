@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"time"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// InformerFactory returns the informer factory shared by the consumers of the
+// apiserver, built on the client set in use, and the channel its informers run
+// until. Callers should get it again rather than keep it: when the client set
+// is replaced, the informers are stopped and a new factory is built on the new
+// client set. The objects of the listers are shared and must not be modified.
+func (c *APIClient) InformerFactory() (informers.SharedInformerFactory, <-chan struct{}) {
+	c.clientLock.Lock()
+	defer c.clientLock.Unlock()
+	if c.informerFactory == nil {
+		// the consumers list the caches, they don't need to be resynced
+		c.informerFactory = informers.NewSharedInformerFactory(c.cl, 0)
+		c.informersStop = make(chan struct{})
+	}
+	return c.informerFactory, c.informersStop
+}
+
+// stopInformers stops the informers of the shared factory, the next call to
+// InformerFactory builds a new one. clientLock must be held.
+func (c *APIClient) stopInformers() {
+	if c.informerFactory == nil {
+		return
+	}
+	close(c.informersStop)
+	c.informerFactory = nil
+	c.informersStop = nil
+}
+
+// SyncInformers starts the informers requested from factory that are not
+// started yet, and waits at most timeout for the given ones to sync. It
+// returns whether they synced.
+func SyncInformers(factory informers.SharedInformerFactory, stop <-chan struct{}, timeout time.Duration, synced ...cache.InformerSynced) bool {
+	factory.Start(stop)
+	if hasSynced(synced) {
+		return true
+	}
+
+	wait := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	go func() {
+		select {
+		case <-timer.C:
+		case <-stop:
+		case <-done:
+			return
+		}
+		close(wait)
+	}()
+	return cache.WaitForCacheSync(wait, synced...)
+}
+
+func hasSynced(synced []cache.InformerSynced) bool {
+	for _, s := range synced {
+		if !s() {
+			return false
+		}
+	}
+	return true
+}
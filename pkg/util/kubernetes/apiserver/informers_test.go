@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestInformerFactory(t *testing.T) {
+	c := &APIClient{}
+	c.setClient(fake.NewSimpleClientset(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}}), &rest.Config{}, 0)
+
+	factory, stop := c.InformerFactory()
+	pods := factory.Core().V1().Pods()
+	require.True(t, SyncInformers(factory, stop, 5*time.Second, pods.Informer().HasSynced))
+	list, err := pods.Lister().List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "pod1", list[0].Name)
+
+	// the factory is shared until the client set is replaced
+	sameFactory, _ := c.InformerFactory()
+	assert.Equal(t, factory, sameFactory)
+
+	c.setClient(fake.NewSimpleClientset(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default"}}), &rest.Config{}, 1)
+	select {
+	case <-stop:
+	default:
+		assert.Fail(t, "the informers of the previous client set should be stopped")
+	}
+
+	factory, stop = c.InformerFactory()
+	pods = factory.Core().V1().Pods()
+	require.True(t, SyncInformers(factory, stop, 5*time.Second, pods.Informer().HasSynced))
+	list, err = pods.Lister().List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "pod2", list[0].Name)
+}
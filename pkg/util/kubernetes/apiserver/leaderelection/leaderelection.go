@@ -124,7 +124,7 @@ func (le *LeaderEngine) init() error {
 		return err
 	}
 
-	le.coreClient = apiClient.Cl.CoreV1().(*corev1.CoreV1Client)
+	le.coreClient = apiClient.Client().CoreV1().(*corev1.CoreV1Client)
 
 	// check if we can get ConfigMap.
 	_, err = le.coreClient.ConfigMaps(le.LeaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
@@ -209,7 +209,7 @@ func GetLeaderElectionRecord() (leaderDetails rl.LeaderElectionRecord, err error
 		return led, err
 	}
 
-	c := client.Cl.CoreV1()
+	c := client.Client().CoreV1()
 
 	leaderNamespace := apiserver.GetResourcesNamespace()
 	leaderElectionCM, err := c.ConfigMaps(leaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
@@ -228,6 +228,30 @@ func GetLeaderElectionRecord() (leaderDetails rl.LeaderElectionRecord, err error
 	return led, nil
 }
 
+// LeaderDetails describes the current holder of the leadership.
+type LeaderDetails struct {
+	Leader            string    `json:"leader"`
+	AcquiredTime      time.Time `json:"acquired_time"`
+	RenewedTime       time.Time `json:"renewed_time"`
+	LeaderTransitions int       `json:"leader_transitions"`
+	HeldForSeconds    float64   `json:"held_for_seconds"`
+}
+
+// GetLeaderDetails returns the identity of the leader and for how long it has held the leadership.
+func GetLeaderDetails() (LeaderDetails, error) {
+	record, err := GetLeaderElectionRecord()
+	if err != nil {
+		return LeaderDetails{}, err
+	}
+	return LeaderDetails{
+		Leader:            record.HolderIdentity,
+		AcquiredTime:      record.AcquireTime.Time,
+		RenewedTime:       record.RenewTime.Time,
+		LeaderTransitions: record.LeaderTransitions,
+		HeldForSeconds:    time.Since(record.AcquireTime.Time).Seconds(),
+	}, nil
+}
+
 func init() {
 	// Avoid logging glog from the k8s.io package
 	flag.Lookup("stderrthreshold").Value.Set("FATAL")
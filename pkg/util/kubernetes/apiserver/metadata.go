@@ -10,6 +10,8 @@ package apiserver
 import (
 	"fmt"
 
+	"k8s.io/api/core/v1"
+
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -19,7 +21,7 @@ func GetPodMetadataNames(nodeName, ns, podName string) ([]string, error) {
 	var metaList []string
 	cacheKey := cache.BuildAgentKey(metadataMapperCachePrefix, nodeName)
 
-	metaBundleInterface, found := cache.Cache.Get(cacheKey)
+	metaBundleInterface, found := cache.GetShared(cacheKey, getMetadataMapExpire(), decodeMetadataMapperBundle)
 	if !found {
 		log.Tracef("no metadata was found for the pod %s on node %s", podName, nodeName)
 		return nil, nil
@@ -34,12 +36,38 @@ func GetPodMetadataNames(nodeName, ns, podName string) ([]string, error) {
 	serviceList, foundServices := metaBundle.ServicesForPod(ns, podName)
 	if !foundServices {
 		log.Tracef("no cached services list found for the pod %s on the node %s", podName, nodeName)
-		return nil, nil
 	}
 	log.Debugf("CacheKey: %s, with %d services", cacheKey, len(serviceList))
 	for _, s := range serviceList {
 		metaList = append(metaList, fmt.Sprintf("kube_service:%s", s))
+		for _, route := range metaBundle.RoutesForService(ns, s) {
+			metaList = append(metaList, fmt.Sprintf("oshift_route:%s", route))
+		}
 	}
+	metaList = append(metaList, metaBundle.TagsForNamespace(ns)...)
 
 	return metaList, nil
 }
+
+// storeNodeLabels caches the labels of every node, to be served to the node agents
+// that cannot query the apiserver themselves.
+func storeNodeLabels(nodeList *v1.NodeList) {
+	for _, node := range nodeList.Items {
+		cacheKey := cache.BuildAgentKey(nodeLabelsCachePrefix, node.Name)
+		cache.SetShared(cacheKey, node.Labels, getMetadataMapExpire())
+	}
+}
+
+// GetNodeLabels is used when the API endpoint of the DCA to get the labels of a node is hit.
+func GetNodeLabels(nodeName string) (map[string]string, error) {
+	cacheKey := cache.BuildAgentKey(nodeLabelsCachePrefix, nodeName)
+	labels, found := cache.GetShared(cacheKey, getMetadataMapExpire(), decodeNodeLabels)
+	if !found {
+		return nil, fmt.Errorf("no labels were found for the node %s", nodeName)
+	}
+	nodeLabels, ok := labels.(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("invalid cache format for the cacheKey: %s", cacheKey)
+	}
+	return nodeLabels, nil
+}
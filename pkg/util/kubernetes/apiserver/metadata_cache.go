@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const defaultMetadataMapExpire = 2 * time.Minute
+
+// MetadataMapperInvalidationHook is called with the name of the node and the version
+// of its new bundle when the endpoints targeting the node have changed.
+type MetadataMapperInvalidationHook func(nodeName string, version uint64)
+
+var (
+	invalidationHooks      []MetadataMapperInvalidationHook
+	invalidationHooksMutex sync.RWMutex
+)
+
+// RegisterMetadataMapperInvalidationHook registers a hook fired when the metadata
+// bundle of a node is replaced following a change of its endpoints.
+// Hooks are called synchronously from the polling loop and must not block.
+func RegisterMetadataMapperInvalidationHook(hook MetadataMapperInvalidationHook) {
+	invalidationHooksMutex.Lock()
+	defer invalidationHooksMutex.Unlock()
+	invalidationHooks = append(invalidationHooks, hook)
+}
+
+func notifyMetadataMapperInvalidation(nodeName string, version uint64) {
+	invalidationHooksMutex.RLock()
+	defer invalidationHooksMutex.RUnlock()
+	for _, hook := range invalidationHooks {
+		hook(nodeName, version)
+	}
+}
+
+// InvalidateMetadataMapperBundle drops the cached bundle of a node, it is
+// rebuilt on the next poll.
+func InvalidateMetadataMapperBundle(nodeName string) {
+	cache.DeleteShared(cache.BuildAgentKey(metadataMapperCachePrefix, nodeName))
+}
+
+// decodeMetadataMapperBundle decodes a bundle stored in the shared cache backend.
+// The endpoints fingerprint is not shared: the next rebuild of the bundle notifies
+// an invalidation, the node agents refreshing their tags once more than needed.
+func decodeMetadataMapperBundle(raw []byte) (interface{}, error) {
+	metaBundle := newMetadataMapperBundle()
+	err := json.Unmarshal(raw, metaBundle)
+	return metaBundle, err
+}
+
+func decodeNodeLabels(raw []byte) (interface{}, error) {
+	labels := make(map[string]string)
+	err := json.Unmarshal(raw, &labels)
+	return labels, err
+}
+
+// getMetadataMapExpire returns the time to live of the cached bundles.
+func getMetadataMapExpire() time.Duration {
+	ttl := config.Datadog.GetInt64("kubernetes_metadata_mapper_ttl")
+	if ttl <= 0 {
+		log.Warnf("Invalid kubernetes_metadata_mapper_ttl %d, using %s", ttl, defaultMetadataMapExpire)
+		return defaultMetadataMapExpire
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// endpointsFingerprint identifies the state of the endpoints having an address on the node.
+// Addresses without a node name are considered, as we cannot tell where they run.
+func endpointsFingerprint(nodeName string, endpointList v1.EndpointsList) string {
+	var versions []string
+	for _, endpoints := range endpointList.Items {
+		if !endpointsOnNode(nodeName, endpoints) {
+			continue
+		}
+		versions = append(versions, fmt.Sprintf("%s/%s:%s", endpoints.Namespace, endpoints.Name, endpoints.ResourceVersion))
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ",")
+}
+
+func endpointsOnNode(nodeName string, endpoints v1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.NodeName == nil || *address.NodeName == nodeName {
+				return true
+			}
+		}
+	}
+	return false
+}
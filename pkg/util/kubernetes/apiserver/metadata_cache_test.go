@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProcessKubeServicesVersioning(t *testing.T) {
+	nodeName := "versioned-node"
+	defer InvalidateMetadataMapperBundle(nodeName)
+
+	nodeList := &v1.NodeList{Items: []v1.Node{{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}}}
+	podList := &v1.PodList{
+		Items: []v1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: types.UID("nginx-uid")}},
+		},
+	}
+	newEndpoints := func(resourceVersion string) *v1.EndpointsList {
+		return &v1.EndpointsList{
+			Items: []v1.Endpoints{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "nginx-svc", Namespace: "default", ResourceVersion: resourceVersion},
+					Subsets: []v1.EndpointSubset{
+						{
+							Addresses: []v1.EndpointAddress{
+								{
+									IP:       "10.0.0.1",
+									NodeName: &nodeName,
+									TargetRef: &v1.ObjectReference{
+										Kind:      "Pod",
+										Name:      "nginx",
+										Namespace: "default",
+										UID:       types.UID("nginx-uid"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	var invalidated []uint64
+	RegisterMetadataMapperInvalidationHook(func(node string, version uint64) {
+		if node == nodeName {
+			invalidated = append(invalidated, version)
+		}
+	})
+
+	processKubeServices(nodeList, podList, newEndpoints("1"), nil, nil)
+	bundle, err := getMetadataMapBundle(nodeName)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), bundle.Version)
+	services, found := bundle.ServicesForPod("default", "nginx")
+	assert.True(t, found)
+	assert.Equal(t, []string{"nginx-svc"}, services)
+
+	// Same endpoints, the bundle is rebuilt without invalidation
+	processKubeServices(nodeList, podList, newEndpoints("1"), nil, nil)
+	updated, err := getMetadataMapBundle(nodeName)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), updated.Version)
+	assert.Empty(t, invalidated)
+	// The previous bundle is not modified in place
+	assert.Equal(t, uint64(0), bundle.Version)
+
+	processKubeServices(nodeList, podList, newEndpoints("2"), nil, nil)
+	assert.Equal(t, []uint64{2}, invalidated)
+
+	InvalidateMetadataMapperBundle(nodeName)
+	_, err = getMetadataMapBundle(nodeName)
+	assert.Error(t, err)
+}
+
+func TestEndpointsFingerprint(t *testing.T) {
+	node1, node2 := "node1", "node2"
+	newEndpoints := func(name string, nodeName *string) v1.Endpoints {
+		return v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: "1"},
+			Subsets: []v1.EndpointSubset{
+				{Addresses: []v1.EndpointAddress{{IP: "10.0.0.1", NodeName: nodeName}}},
+			},
+		}
+	}
+	endpointList := v1.EndpointsList{
+		Items: []v1.Endpoints{
+			newEndpoints("b", &node1),
+			newEndpoints("a", &node1),
+			newEndpoints("c", &node2),
+			newEndpoints("d", nil),
+		},
+	}
+
+	assert.Equal(t, "default/a:1,default/b:1,default/d:1", endpointsFingerprint(node1, endpointList))
+	assert.Equal(t, "default/c:1,default/d:1", endpointsFingerprint(node2, endpointList))
+}
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"expvar"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+// The metadata mapping polls the apiserver, these stats expose the state of
+// each List call so that a stuck or failing resource is visible in the status.
+var (
+	metadataMapperExpvars = expvar.NewMap("metadataMapper")
+	resourcesExpvars      = new(expvar.Map).Init()
+	resourcesExpvarsMutex sync.Mutex
+)
+
+func init() {
+	metadataMapperExpvars.Set("Resources", resourcesExpvars)
+}
+
+func setExpvarString(m *expvar.Map, key, value string) {
+	v := new(expvar.String)
+	v.Set(value)
+	m.Set(key, v)
+}
+
+func setExpvarInt(m *expvar.Map, key string, value int64) {
+	v := new(expvar.Int)
+	v.Set(value)
+	m.Set(key, v)
+}
+
+func resourceExpvars(resource string) *expvar.Map {
+	resourcesExpvarsMutex.Lock()
+	defer resourcesExpvarsMutex.Unlock()
+
+	if m, ok := resourcesExpvars.Get(resource).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map).Init()
+	resourcesExpvars.Set(resource, m)
+	return m
+}
+
+// recordListResult stores the outcome of listing a resource from the apiserver.
+func recordListResult(resource string, count int, err error) {
+	m := resourceExpvars(resource)
+	if err != nil {
+		m.Add("Errors", 1)
+		setExpvarString(m, "LastError", err.Error())
+		setExpvarString(m, "LastErrorTime", time.Now().Format(time.RFC3339))
+		return
+	}
+	setExpvarInt(m, "Objects", int64(count))
+	setExpvarString(m, "LastSuccess", time.Now().Format(time.RFC3339))
+}
+
+// recordMappingRun stores the outcome of a full ClusterMetadataMapping run.
+func recordMappingRun(start time.Time, err error) {
+	metadataMapperExpvars.Add("Runs", 1)
+	setExpvarString(metadataMapperExpvars, "LastRunDuration", time.Since(start).String())
+	if err != nil {
+		metadataMapperExpvars.Add("RunErrors", 1)
+		setExpvarString(metadataMapperExpvars, "LastRunError", err.Error())
+		return
+	}
+	setExpvarString(metadataMapperExpvars, "LastSuccessfulRun", start.Format(time.RFC3339))
+}
+
+// NodeBundleStats describes the metadata bundle of a node
+type NodeBundleStats struct {
+	Version  uint64
+	Pods     int
+	Services int
+}
+
+// GetMetadataMapperStats returns the stats of the metadata mapping for the status page.
+// A resource is flagged as stale when it was not listed successfully for two poll periods.
+// The endpoints sync lag is the time elapsed since the endpoints were last listed.
+func GetMetadataMapperStats() map[string]interface{} {
+	stats := make(map[string]interface{})
+	json.Unmarshal([]byte(metadataMapperExpvars.String()), &stats)
+
+	pollInterval := time.Duration(config.Datadog.GetInt64("kubernetes_apiserver_poll_freq")) * time.Second
+	stats["PollInterval"] = pollInterval.String()
+	stats["Nodes"] = getNodeBundleStats()
+
+	resources, ok := stats["Resources"].(map[string]interface{})
+	if !ok {
+		return stats
+	}
+	for name, r := range resources {
+		resource, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		resource["Stale"] = isStale(resource["LastSuccess"], pollInterval)
+		if name == "endpoints" {
+			if lag, ok := sinceLastSuccess(resource["LastSuccess"]); ok {
+				stats["EndpointsSyncLag"] = lag.String()
+			}
+		}
+	}
+	return stats
+}
+
+// getNodeBundleStats returns the stats of the metadata bundles in the cache, by node name.
+func getNodeBundleStats() map[string]NodeBundleStats {
+	prefix := cache.BuildAgentKey(metadataMapperCachePrefix) + "/"
+	nodes := make(map[string]NodeBundleStats)
+	for key, item := range cache.Cache.Items() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		bundle, ok := item.Object.(*MetadataMapperBundle)
+		if !ok {
+			continue
+		}
+		nodes[strings.TrimPrefix(key, prefix)] = bundle.stats()
+	}
+	return nodes
+}
+
+func (metaBundle *MetadataMapperBundle) stats() NodeBundleStats {
+	metaBundle.m.RLock()
+	defer metaBundle.m.RUnlock()
+
+	stats := NodeBundleStats{Version: metaBundle.Version}
+	services := make(map[string]struct{})
+	for ns, pods := range metaBundle.Services {
+		stats.Pods += len(pods)
+		for _, svcs := range pods {
+			for _, svc := range svcs {
+				services[ns+"/"+svc] = struct{}{}
+			}
+		}
+	}
+	stats.Services = len(services)
+	return stats
+}
+
+func sinceLastSuccess(lastSuccess interface{}) (time.Duration, bool) {
+	s, ok := lastSuccess.(string)
+	if !ok {
+		return 0, false
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, false
+	}
+	return time.Since(t).Truncate(time.Second), true
+}
+
+func isStale(lastSuccess interface{}, pollInterval time.Duration) bool {
+	since, ok := sinceLastSuccess(lastSuccess)
+	return !ok || since > 2*pollInterval
+}
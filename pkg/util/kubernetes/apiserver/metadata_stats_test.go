@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+func TestMetadataMapperStats(t *testing.T) {
+	recordListResult("pods", 12, nil)
+	recordListResult("endpoints", 0, errors.New("connection refused"))
+	recordMappingRun(time.Now(), errors.New("connection refused"))
+
+	stats := GetMetadataMapperStats()
+	assert.Equal(t, "connection refused", stats["LastRunError"])
+	assert.NotEmpty(t, stats["PollInterval"])
+
+	resources, ok := stats["Resources"].(map[string]interface{})
+	require.True(t, ok)
+
+	pods := resources["pods"].(map[string]interface{})
+	assert.EqualValues(t, 12, pods["Objects"])
+	assert.Equal(t, false, pods["Stale"])
+
+	endpoints := resources["endpoints"].(map[string]interface{})
+	assert.EqualValues(t, 1, endpoints["Errors"])
+	assert.Equal(t, "connection refused", endpoints["LastError"])
+	assert.Equal(t, true, endpoints["Stale"])
+
+	recordListResult("endpoints", 3, nil)
+	stats = GetMetadataMapperStats()
+	assert.Contains(t, stats, "EndpointsSyncLag")
+}
+
+func TestNodeBundleStats(t *testing.T) {
+	bundle := newMetadataMapperBundle()
+	bundle.Version = 3
+	bundle.Services.Set("default", "pod1", []string{"svc1", "svc2"})
+	bundle.Services.Set("default", "pod2", []string{"svc1"})
+	bundle.Services.Set("kube-system", "pod3", []string{"svc1"})
+	cacheKey := cache.BuildAgentKey(metadataMapperCachePrefix, "node1")
+	cache.Cache.Set(cacheKey, bundle, time.Minute)
+	defer cache.Cache.Delete(cacheKey)
+
+	nodes := GetMetadataMapperStats()["Nodes"].(map[string]NodeBundleStats)
+	assert.Equal(t, NodeBundleStats{Version: 3, Pods: 3, Services: 3}, nodes["node1"])
+}
+
+func TestIsStale(t *testing.T) {
+	assert.True(t, isStale(nil, time.Minute))
+	assert.True(t, isStale("not a date", time.Minute))
+	assert.True(t, isStale(time.Now().Add(-3*time.Minute).Format(time.RFC3339), time.Minute))
+	assert.False(t, isStale(time.Now().Format(time.RFC3339), time.Minute))
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// getMetadataNamespaces returns the namespaces the metadata collection is restricted to.
+// An empty list means that all the namespaces are collected.
+func getMetadataNamespaces() []string {
+	return config.Datadog.GetStringSlice("kubernetes_metadata_namespaces")
+}
+
+// listEndpoints lists the endpoints of all the namespaces, or of the configured
+// namespaces only, to run with namespace-scoped permissions.
+func (c *APIClient) listEndpoints() (*v1.EndpointsList, error) {
+	namespaces := getMetadataNamespaces()
+	if len(namespaces) == 0 {
+		return c.Client().CoreV1().Endpoints(metav1.NamespaceAll).List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+	}
+
+	endpointList := &v1.EndpointsList{}
+	for _, ns := range namespaces {
+		list, err := c.Client().CoreV1().Endpoints(ns).List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %s", ns, err)
+		}
+		endpointList.Items = append(endpointList.Items, list.Items...)
+	}
+	return endpointList, nil
+}
+
+// listPods lists the pods of all the namespaces, or of the configured
+// namespaces only, to run with namespace-scoped permissions.
+func (c *APIClient) listPods() (*v1.PodList, error) {
+	namespaces := getMetadataNamespaces()
+	if len(namespaces) == 0 {
+		return c.Client().CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+	}
+
+	podList := &v1.PodList{}
+	for _, ns := range namespaces {
+		list, err := c.Client().CoreV1().Pods(ns).List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %s", ns, err)
+		}
+		podList.Items = append(podList.Items, list.Items...)
+	}
+	return podList, nil
+}
+
+// nodesFromPods builds the list of the nodes running the given pods, used when
+// the nodes cannot be listed with namespace-scoped permissions.
+func nodesFromPods(podList *v1.PodList) *v1.NodeList {
+	nodeList := &v1.NodeList{}
+	seen := make(map[string]struct{})
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if _, found := seen[pod.Spec.NodeName]; found {
+			continue
+		}
+		seen[pod.Spec.NodeName] = struct{}{}
+
+		var node v1.Node
+		node.Name = pod.Spec.NodeName
+		nodeList.Items = append(nodeList.Items, node)
+	}
+	return nodeList
+}
+
+// checkNamespacedResourcesAuth checks that the services and pods of every configured namespace can be queried.
+func (c *APIClient) checkNamespacedResourcesAuth(namespaces []string) []string {
+	var errorMessages []string
+	for _, ns := range namespaces {
+		_, err := c.Client().CoreV1().Services(ns).List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
+		if err != nil {
+			errorMessages = append(errorMessages, fmt.Sprintf("service collection in namespace %s: %q", ns, err.Error()))
+		}
+		_, err = c.Client().CoreV1().Pods(ns).List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &c.timeoutSeconds})
+		if err != nil {
+			errorMessages = append(errorMessages, fmt.Sprintf("pod collection in namespace %s: %q", ns, err.Error()))
+		}
+	}
+	return errorMessages
+}
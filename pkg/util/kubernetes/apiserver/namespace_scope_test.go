@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+)
+
+func TestNodesFromPods(t *testing.T) {
+	newPod := func(nodeName string) v1.Pod {
+		var pod v1.Pod
+		pod.Spec.NodeName = nodeName
+		return pod
+	}
+	podList := &v1.PodList{
+		Items: []v1.Pod{
+			newPod("node1"),
+			newPod("node2"),
+			newPod("node1"),
+			newPod(""), // pending pod
+		},
+	}
+
+	nodeList := nodesFromPods(podList)
+	var names []string
+	for _, node := range nodeList.Items {
+		names = append(names, node.Name)
+	}
+	assert.ElementsMatch(t, []string{"node1", "node2"}, names)
+}
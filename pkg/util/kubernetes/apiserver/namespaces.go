@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// NamespacesMapper maps namespace names to the tags extracted from their labels,
+// these tags are applied to all the pods of the namespace.
+//
+// The data is stored in the following schema:
+// {
+// 	"namespace": [ "team:foo", "env:prod" ]
+// }
+type NamespacesMapper map[string][]string
+
+// getNamespaceLabelsAsTags returns the configured mapping of namespace labels
+// to tag names, with lower-cased label names.
+func getNamespaceLabelsAsTags() map[string]string {
+	labelsToTags := config.Datadog.GetStringMapString("kubernetes_namespace_labels_as_tags")
+	// viper lower-cases map keys from yaml, but not from envvars
+	for label, value := range labelsToTags {
+		delete(labelsToTags, label)
+		labelsToTags[strings.ToLower(label)] = value
+	}
+	return labelsToTags
+}
+
+// namespacesForLabelsAsTags lists the namespaces if namespace labels need to be
+// collected, and returns a nil list otherwise.
+func (c *APIClient) namespacesForLabelsAsTags() (*v1.NamespaceList, error) {
+	if len(getNamespaceLabelsAsTags()) == 0 {
+		return nil, nil
+	}
+	metadataNamespaces := getMetadataNamespaces()
+	if len(metadataNamespaces) == 0 {
+		return c.Client().CoreV1().Namespaces().List(metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
+	}
+
+	// Listing namespaces requires cluster-wide permissions
+	namespaceList := &v1.NamespaceList{}
+	for _, name := range metadataNamespaces {
+		ns, err := c.Client().CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		namespaceList.Items = append(namespaceList.Items, *ns)
+	}
+	return namespaceList, nil
+}
+
+// mapNamespaces extracts the tags of every namespace from their labels
+func (metaBundle *MetadataMapperBundle) mapNamespaces(namespaces v1.NamespaceList, labelsToTags map[string]string) {
+	metaBundle.m.Lock()
+	defer metaBundle.m.Unlock()
+
+	metaBundle.Namespaces = make(NamespacesMapper)
+	for _, ns := range namespaces.Items {
+		var tags []string
+		for labelName, labelValue := range ns.Labels {
+			if tagName, found := labelsToTags[strings.ToLower(labelName)]; found {
+				tags = append(tags, fmt.Sprintf("%s:%s", tagName, labelValue))
+			}
+		}
+		if len(tags) > 0 {
+			metaBundle.Namespaces[ns.Name] = tags
+		}
+	}
+}
+
+// TagsForNamespace returns the tags extracted from the labels of a given namespace.
+// This call is thread-safe.
+func (metaBundle *MetadataMapperBundle) TagsForNamespace(ns string) []string {
+	metaBundle.m.RLock()
+	defer metaBundle.m.RUnlock()
+
+	return metaBundle.Namespaces[ns]
+}
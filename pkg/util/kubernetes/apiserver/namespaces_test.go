@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMapNamespaces(t *testing.T) {
+	namespaces := v1.NamespaceList{
+		Items: []v1.Namespace{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "frontend",
+					Labels: map[string]string{"team": "web", "Env": "prod", "other": "ignored"},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "unlabeled",
+					Labels: map[string]string{"other": "ignored"},
+				},
+			},
+		},
+	}
+	labelsToTags := map[string]string{
+		"team": "team",
+		"env":  "environment",
+	}
+
+	metaBundle := newMetadataMapperBundle()
+	metaBundle.mapNamespaces(namespaces, labelsToTags)
+
+	assert.ElementsMatch(t, []string{"team:web", "environment:prod"}, metaBundle.TagsForNamespace("frontend"))
+	assert.Empty(t, metaBundle.TagsForNamespace("unlabeled"))
+	assert.Empty(t, metaBundle.TagsForNamespace("unknown"))
+}
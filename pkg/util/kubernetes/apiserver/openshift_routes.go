@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	oapiRoutesEndpoint     = "/oapi/v1"
+	apiGroupRoutesEndpoint = "/apis/route.openshift.io/v1"
+)
+
+// RoutesMapper maps service names to the names of the OpenShift routes
+// targeting them, keyed by namespace.
+//
+// The data is stored in the following schema:
+// {
+// 	"namespace": {
+// 		"svc": [ "route1", "route2" ]
+// 	}
+// }
+type RoutesMapper map[string]map[string][]string
+
+// Get returns the list of routes for a given namespace and service name.
+func (m RoutesMapper) Get(ns, svcName string) []string {
+	return m[ns][svcName]
+}
+
+func (m RoutesMapper) add(ns, svcName, routeName string) {
+	if _, ok := m[ns]; !ok {
+		m[ns] = make(map[string][]string)
+	}
+	m[ns][svcName] = append(m[ns][svcName], routeName)
+}
+
+// buildRoutesMapper maps the services targeted by the routes, including the alternate backends.
+func buildRoutesMapper(routes []Route) RoutesMapper {
+	m := make(RoutesMapper)
+	for _, route := range routes {
+		targets := append([]RouteTargetReference{route.Spec.To}, route.Spec.AlternateBackends...)
+		for _, target := range targets {
+			if target.Kind != "Service" || target.Name == "" {
+				continue
+			}
+			m.add(route.Metadata.Namespace, target.Name, route.Metadata.Name)
+		}
+	}
+	return m
+}
+
+// getOpenShiftAPILevel detects the OpenShift APIs on the first call and caches the result.
+// The concurrent callers wait for the detection.
+func (c *APIClient) getOpenShiftAPILevel() OpenShiftAPILevel {
+	c.openShiftAPILevelLock.Lock()
+	defer c.openShiftAPILevelLock.Unlock()
+	if c.openShiftAPILevel == "" {
+		c.openShiftAPILevel = c.DetectOpenShiftAPILevel()
+	}
+	return c.openShiftAPILevel
+}
+
+// listOpenShiftRoutes lists the routes of all the namespaces, or of the namespaces the metadata
+// collection is restricted to. It returns nil when OpenShift APIs are not available.
+func (c *APIClient) listOpenShiftRoutes() ([]Route, error) {
+	var prefix string
+	switch c.getOpenShiftAPILevel() {
+	case OpenShiftAPIGroup:
+		prefix = apiGroupRoutesEndpoint
+	case OpenShiftOAPI:
+		prefix = oapiRoutesEndpoint
+	default:
+		return nil, nil
+	}
+
+	paths := []string{fmt.Sprintf("%s/routes", prefix)}
+	if namespaces := getMetadataNamespaces(); len(namespaces) > 0 {
+		paths = nil
+		for _, ns := range namespaces {
+			paths = append(paths, fmt.Sprintf("%s/namespaces/%s/routes", prefix, ns))
+		}
+	}
+
+	var routes []Route
+	for _, path := range paths {
+		// the client may prefer protobuf, the routes are decoded from JSON
+		raw, err := c.Client().CoreV1().RESTClient().Get().AbsPath(path).
+			SetHeader("Accept", "application/json").
+			DoRaw()
+		if err != nil {
+			return nil, err
+		}
+		list := RouteList{}
+		if err = json.Unmarshal(raw, &list); err != nil {
+			return nil, err
+		}
+		routes = append(routes, list.Items...)
+	}
+	log.Tracef("Collected %d OpenShift routes", len(routes))
+	return routes, nil
+}
+
+// mapRoutes stores the routes targeting the services
+func (metaBundle *MetadataMapperBundle) mapRoutes(routes RoutesMapper) {
+	metaBundle.m.Lock()
+	defer metaBundle.m.Unlock()
+
+	metaBundle.Routes = routes
+}
+
+// RoutesForService returns the OpenShift routes targeting a given service.
+// This call is thread-safe.
+func (metaBundle *MetadataMapperBundle) RoutesForService(ns, svcName string) []string {
+	metaBundle.m.RLock()
+	defer metaBundle.m.RUnlock()
+
+	return metaBundle.Routes.Get(ns, svcName)
+}
@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const testRouteList = `{
+  "kind": "RouteList",
+  "apiVersion": "route.openshift.io/v1",
+  "items": [
+    {
+      "metadata": {"name": "frontend", "namespace": "shop"},
+      "spec": {
+        "host": "www.example.com",
+        "to": {"kind": "Service", "name": "web", "weight": 100},
+        "alternateBackends": [{"kind": "Service", "name": "web-canary", "weight": 10}]
+      }
+    },
+    {
+      "metadata": {"name": "api", "namespace": "shop"},
+      "spec": {
+        "to": {"kind": "Service", "name": "web"}
+      }
+    },
+    {
+      "metadata": {"name": "other", "namespace": "default"},
+      "spec": {
+        "to": {"kind": "Unknown", "name": "web"}
+      }
+    }
+  ]
+}`
+
+func TestBuildRoutesMapper(t *testing.T) {
+	list := RouteList{}
+	require.NoError(t, json.Unmarshal([]byte(testRouteList), &list))
+	require.Len(t, list.Items, 3)
+
+	routes := buildRoutesMapper(list.Items)
+	assert.ElementsMatch(t, []string{"frontend", "api"}, routes.Get("shop", "web"))
+	assert.ElementsMatch(t, []string{"frontend"}, routes.Get("shop", "web-canary"))
+	assert.Empty(t, routes.Get("default", "web"))
+	assert.Empty(t, routes.Get("unknown", "web"))
+}
+
+func TestListOpenShiftRoutesAcceptsJSON(t *testing.T) {
+	apiserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/route.openshift.io/v1/routes", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(testRouteList))
+	}))
+	defer apiserver.Close()
+
+	config.Datadog.Set("kubernetes_apiserver_use_protobuf", true)
+	defer config.Datadog.Set("kubernetes_apiserver_use_protobuf", false)
+
+	k8sConfig := &rest.Config{Host: apiserver.URL}
+	applyClientOptions(k8sConfig)
+	cl, err := getClientSet(k8sConfig)
+	require.NoError(t, err)
+	c := &APIClient{openShiftAPILevel: OpenShiftAPIGroup}
+	c.setClient(cl, k8sConfig, 0)
+
+	routes, err := c.listOpenShiftRoutes()
+	require.NoError(t, err)
+	assert.Len(t, routes, 3)
+}
@@ -16,3 +16,27 @@ const (
 	OpenShiftOAPI                       = "legacy OAPI"
 	NotOpenShift                        = "no API"
 )
+
+// RouteList is a minimal representation of an OpenShift route list, with
+// the fields used to map routes to services.
+type RouteList struct {
+	Items []Route `json:"items"`
+}
+
+// Route is a minimal representation of an OpenShift route
+type Route struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		To                RouteTargetReference   `json:"to"`
+		AlternateBackends []RouteTargetReference `json:"alternateBackends,omitempty"`
+	} `json:"spec"`
+}
+
+// RouteTargetReference specifies the target that resolve into endpoints
+type RouteTargetReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
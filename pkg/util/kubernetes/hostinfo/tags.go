@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
@@ -34,15 +35,28 @@ func GetTags() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	client, err := apiserver.GetAPIClient()
+	nodeLabels, err := getNodeLabels(nodeName)
 	if err != nil {
 		return nil, err
 	}
-	nodeLabels, err := client.NodeLabels(nodeName)
+	return extractTags(nodeLabels, labelsToTags), nil
+}
+
+// getNodeLabels fetches the node labels from the cluster agent if it is enabled,
+// as it already collects them, and from the apiserver otherwise.
+func getNodeLabels(nodeName string) (map[string]string, error) {
+	if config.Datadog.GetBool("cluster_agent.enabled") {
+		dcaClient, err := clusteragent.GetClusterAgentClient()
+		if err != nil {
+			return nil, err
+		}
+		return dcaClient.GetNodeLabels(nodeName)
+	}
+	client, err := apiserver.GetAPIClient()
 	if err != nil {
 		return nil, err
 	}
-	return extractTags(nodeLabels, labelsToTags), nil
+	return client.NodeLabels(nodeName)
 }
 
 func extractTags(nodeLabels, labelsToTags map[string]string) []string {
@@ -10,6 +10,7 @@ package hpa
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,38 +20,62 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-// queryDatadogExternal converts the metric name and labels from the HPA format into a Datadog metric.
-// It returns the last value for a bucket of 5 minutes,
-func (hpa *HPAWatcherClient) queryDatadogExternal(metricName string, tags map[string]string) (int64, error) {
-	if metricName == "" || len(tags) == 0 {
-		return 0, errors.New("invalid metric to query")
-	}
-	bucketSize := config.Datadog.GetInt64("external_metrics_provider.bucket_size")
-	datadogTags := []string{}
+// Point represents the last value of a query, and whether it could be retrieved from Datadog.
+type Point struct {
+	Value int64
+	Valid bool
+}
 
-	for key, val := range tags {
+// getKey converts the metric name and labels from the HPA format into a Datadog query.
+// The labels are sorted, for the query to match the scope of the series returned by Datadog,
+// which is `*` for a query without labels.
+func getKey(metricName string, labels map[string]string) string {
+	datadogTags := []string{}
+	for key, val := range labels {
 		datadogTags = append(datadogTags, fmt.Sprintf("%s:%s", key, val))
 	}
-	tagString := strings.Join(datadogTags, ",")
+	sort.Strings(datadogTags)
+	scope := "*"
+	if len(datadogTags) > 0 {
+		scope = strings.Join(datadogTags, ",")
+	}
 
 	// TODO: offer other aggregations than avg.
-	query := fmt.Sprintf("avg:%s{%s}", metricName, tagString)
+	return fmt.Sprintf("avg:%s{%s}", metricName, scope)
+}
 
-	seriesSlice, err := hpa.datadogClient.QueryMetrics(time.Now().Unix()-bucketSize, time.Now().Unix(), query)
+// queryDatadogExternal runs the queries in a single call to Datadog.
+// It returns the last value of each query for a bucket of 5 minutes, indexed by query.
+// Queries without any series in the response are absent from the result.
+func (hpa *HPAWatcherClient) queryDatadogExternal(queries []string) (map[string]Point, error) {
+	if len(queries) == 0 {
+		return nil, errors.New("no query to run")
+	}
+	bucketSize := config.Datadog.GetInt64("external_metrics_provider.bucket_size")
+	query := strings.Join(queries, ",")
 
+	seriesSlice, err := hpa.datadogClient.QueryMetrics(time.Now().Unix()-bucketSize, time.Now().Unix(), query)
 	if err != nil {
-		return 0, log.Errorf("Error while executing metric query %s: %s", query, err)
-	}
-	if len(seriesSlice) == 0 {
-		return 0, log.Errorf("Returned series slice empty")
+		return nil, log.Errorf("Error while executing metric query %s: %s", query, err)
 	}
-	points := seriesSlice[0].Points
 
-	if len(points) == 0 {
-		return 0, log.Errorf("No points in series")
+	processed := make(map[string]Point)
+	for _, serie := range seriesSlice {
+		if serie.Metric == nil || serie.Scope == nil {
+			log.Debugf("Skipping a series without metric name or scope in the response to %s", query)
+			continue
+		}
+		points := serie.Points
+		if len(points) == 0 {
+			continue
+		}
+		key := fmt.Sprintf("avg:%s{%s}", *serie.Metric, *serie.Scope)
+		processed[key] = Point{
+			Value: int64(points[len(points)-1][1]),
+			Valid: true,
+		}
 	}
-	lastValue := int64(points[len(points)-1][1])
-	return lastValue, nil
+	return processed, nil
 }
 
 // NewDatadogClient generates a new client to query metrics from Datadog
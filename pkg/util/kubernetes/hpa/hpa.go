@@ -16,7 +16,6 @@ import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/kubernetes"
 
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/custommetrics"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -34,17 +33,18 @@ type DatadogClient interface {
 
 // HPAWatcherClient embeds the API Server client and the configuration to refresh metrics from Datadog and watch the HPA Objects' activities
 type HPAWatcherClient struct {
-	clientSet      kubernetes.Interface
+	clientSet      custommetrics.ClientGetter
 	readTimeout    time.Duration
 	refreshItl     *time.Ticker
 	pollItl        *time.Ticker
 	externalMaxAge time.Duration
 	datadogClient  DatadogClient
 	store          custommetrics.Store
+	wpaEnabled     bool
 }
 
 // NewHPAWatcherClient returns a new HPAWatcherClient
-func NewHPAWatcherClient(clientSet kubernetes.Interface, datadogCl DatadogClient, store custommetrics.Store) (*HPAWatcherClient, error) {
+func NewHPAWatcherClient(clientSet custommetrics.ClientGetter, datadogCl DatadogClient, store custommetrics.Store) (*HPAWatcherClient, error) {
 	pollInterval := config.Datadog.GetInt("hpa_watcher_polling_freq")
 	refreshInterval := config.Datadog.GetInt("external_metrics_provider.polling_freq")
 	externalMaxAge := config.Datadog.GetInt("external_metrics_provider.max_age")
@@ -56,12 +56,13 @@ func NewHPAWatcherClient(clientSet kubernetes.Interface, datadogCl DatadogClient
 		externalMaxAge: time.Duration(externalMaxAge) * time.Second,
 		datadogClient:  datadogCl,
 		store:          store,
+		wpaEnabled:     config.Datadog.GetBool("external_metrics_provider.wpa_controller"),
 	}, nil
 }
 
 func (c *HPAWatcherClient) run(res string) (added, modified, deleted []*autoscalingv2.HorizontalPodAutoscaler, resVer string, err error) {
 	metaOptions := metav1.ListOptions{Watch: true, ResourceVersion: res}
-	watcher, err := c.clientSet.AutoscalingV2beta1().HorizontalPodAutoscalers(metav1.NamespaceAll).Watch(metaOptions)
+	watcher, err := c.clientSet().AutoscalingV2beta1().HorizontalPodAutoscalers(metav1.NamespaceAll).Watch(metaOptions)
 	if err != nil {
 		log.Infof("Failed to watch %v: %v", expectedHPAType, err)
 	}
@@ -159,6 +160,9 @@ func (c *HPAWatcherClient) Start() {
 				// Updating the metrics against Datadog should not affect the HPA pipeline.
 				// If metrics are temporarily unavailable for too long, they will become `Valid=false` and won't be evaluated.
 				c.updateExternalMetrics()
+				if c.wpaEnabled {
+					c.processWPAs()
+				}
 			}
 		}
 	}()
@@ -178,16 +182,25 @@ func (c *HPAWatcherClient) updateExternalMetrics() {
 		return
 	}
 
-	var updated []custommetrics.ExternalMetricValue
+	var stale []custommetrics.ExternalMetricValue
 	for _, em := range emList {
 		if metav1.Now().Unix()-em.Timestamp <= maxAge && em.Valid {
 			continue
 		}
-		em.Valid = false
+		stale = append(stale, em)
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	points := c.queryExternalMetrics(stale)
+	var updated []custommetrics.ExternalMetricValue
+	for _, em := range stale {
+		point := points[getKey(em.MetricName, em.Labels)]
+		em.Value, em.Valid = point.Value, point.Valid
 		em.Timestamp = metav1.Now().Unix()
-		em.Value, em.Valid, err = c.validateExternalMetric(em.MetricName, em.Labels)
-		if err != nil {
-			log.Debugf("Could not fetch the external metric %s from Datadog, metric is no longer valid: %s", em.MetricName, err)
+		if !em.Valid {
+			log.Debugf("Could not fetch the external metric %s from Datadog, metric is no longer valid", em.MetricName)
 		}
 		log.Debugf("Updated the external metric %#v", em)
 		updated = append(updated, em)
@@ -204,7 +217,6 @@ func (c *HPAWatcherClient) processHPAs(added, modified []*autoscalingv2.Horizont
 		return nil
 	}
 	var externalMetrics []custommetrics.ExternalMetricValue
-	var err error
 	for _, hpa := range added {
 		for _, metricSpec := range hpa.Spec.Metrics {
 			switch metricSpec.Type {
@@ -218,16 +230,25 @@ func (c *HPAWatcherClient) processHPAs(added, modified []*autoscalingv2.Horizont
 					},
 					Labels: metricSpec.External.MetricSelector.MatchLabels,
 				}
-				m.Value, m.Valid, err = c.validateExternalMetric(m.MetricName, m.Labels)
-				if err != nil {
-					log.Debugf("Could not fetch the external metric %s from Datadog, metric is no longer valid: %s", m.MetricName, err)
-				}
 				externalMetrics = append(externalMetrics, m)
 			default:
 				log.Debugf("Unsupported metric type %s", metricSpec.Type)
 			}
 		}
 	}
+	if len(externalMetrics) == 0 {
+		return nil
+	}
+
+	// Validate that the metrics are available in Datadog, batching the queries of all the HPAs
+	points := c.queryExternalMetrics(externalMetrics)
+	for i, m := range externalMetrics {
+		point := points[getKey(m.MetricName, m.Labels)]
+		externalMetrics[i].Value, externalMetrics[i].Valid = point.Value, point.Valid
+		if !point.Valid {
+			log.Debugf("Could not fetch the external metric %s from Datadog, metric is not valid", m.MetricName)
+		}
+	}
 	return c.store.SetExternalMetricValues(externalMetrics)
 }
 
@@ -246,15 +267,6 @@ func (c *HPAWatcherClient) removeEntryFromStore(deleted []*autoscalingv2.Horizon
 	return c.store.Delete(objectRefs)
 }
 
-// validateExternalMetric queries Datadog to validate the availability of an external metric
-func (c *HPAWatcherClient) validateExternalMetric(metricName string, labels map[string]string) (value int64, valid bool, err error) {
-	val, err := c.queryDatadogExternal(metricName, labels)
-	if err != nil {
-		return val, false, err
-	}
-	return val, true, nil
-}
-
 // Stop sends a signal to the HPAWatcher to stop it.
 // Used for the tests to avoid leaking go-routines.
 func (c *HPAWatcherClient) Stop() {
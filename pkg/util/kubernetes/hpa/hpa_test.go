@@ -17,6 +17,7 @@ import (
 	"gopkg.in/zorkian/go-datadog-api.v2"
 	"k8s.io/api/autoscaling/v2beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -32,7 +33,8 @@ func (d *fakeDatadogClient) QueryMetrics(from, to int64, query string) ([]datado
 }
 
 func newFakeConfigMapStore(t *testing.T, ns, name string, metrics []custommetrics.ExternalMetricValue) custommetrics.Store {
-	store, err := custommetrics.NewConfigMapStore(fake.NewSimpleClientset(), ns, name)
+	client := fake.NewSimpleClientset()
+	store, err := custommetrics.NewConfigMapStore(func() kubernetes.Interface { return client }, ns, name)
 	require.NoError(t, err)
 	err = store.SetExternalMetricValues(metrics)
 	require.NoError(t, err)
@@ -128,6 +130,7 @@ func TestHPAWatcherRemoveEntryFromStore(t *testing.T) {
 
 func TestHPAWatcherUpdateExternalMetrics(t *testing.T) {
 	metricName := "requests_per_s"
+	scope := "foo:bar"
 	tests := []struct {
 		desc     string
 		metrics  []custommetrics.ExternalMetricValue
@@ -146,6 +149,7 @@ func TestHPAWatcherUpdateExternalMetrics(t *testing.T) {
 			[]datadog.Series{
 				{
 					Metric: &metricName,
+					Scope:  &scope,
 					Points: []datadog.DataPoint{
 						{1531492452, 12},
 						{1531492486, 14},
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package hpa
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/custommetrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	externalMetricsCachePrefix = "externalMetrics"
+	defaultQueriesPerBatch     = 35
+)
+
+// queryExternalMetrics returns the points of the external metrics, indexed by query.
+// Several metrics sharing the same query are only queried once, the points fetched
+// less than external_metrics_provider.cache_ttl seconds ago are served from the cache,
+// and the other queries are sent to Datadog in batches.
+// The metrics that could not be retrieved have an invalid point.
+func (c *HPAWatcherClient) queryExternalMetrics(metrics []custommetrics.ExternalMetricValue) map[string]Point {
+	points := make(map[string]Point)
+	var queries []string
+	cacheTTL := time.Duration(config.Datadog.GetInt64("external_metrics_provider.cache_ttl")) * time.Second
+	for _, m := range metrics {
+		query := getKey(m.MetricName, m.Labels)
+		if _, found := points[query]; found {
+			continue
+		}
+		if cacheTTL > 0 {
+			if cached, found := cache.GetShared(cache.BuildAgentKey(externalMetricsCachePrefix, query), cacheTTL, decodePoint); found {
+				if point, ok := cached.(Point); ok {
+					points[query] = point
+					continue
+				}
+			}
+		}
+		points[query] = Point{Valid: false}
+		queries = append(queries, query)
+	}
+
+	for _, batch := range batchQueries(queries) {
+		processed, err := c.queryDatadogExternal(batch)
+		if err != nil {
+			log.Debugf("Could not fetch %d external metrics from Datadog: %s", len(batch), err)
+			continue
+		}
+		for _, query := range batch {
+			point, found := processed[query]
+			if !found {
+				log.Debugf("No series returned by Datadog for the query %s", query)
+				continue
+			}
+			points[query] = point
+			if cacheTTL > 0 {
+				cache.SetShared(cache.BuildAgentKey(externalMetricsCachePrefix, query), point, cacheTTL)
+			}
+		}
+	}
+	return points
+}
+
+func decodePoint(raw []byte) (interface{}, error) {
+	var point Point
+	err := json.Unmarshal(raw, &point)
+	return point, err
+}
+
+// batchQueries splits the queries in batches of external_metrics_provider.queries_per_batch.
+func batchQueries(queries []string) [][]string {
+	size := config.Datadog.GetInt("external_metrics_provider.queries_per_batch")
+	if size <= 0 {
+		log.Warnf("Invalid external_metrics_provider.queries_per_batch %d, using %d", size, defaultQueriesPerBatch)
+		size = defaultQueriesPerBatch
+	}
+	var batches [][]string
+	for len(queries) > size {
+		batches = append(batches, queries[:size])
+		queries = queries[size:]
+	}
+	if len(queries) > 0 {
+		batches = append(batches, queries)
+	}
+	return batches
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package hpa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/zorkian/go-datadog-api.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/custommetrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+func TestGetKey(t *testing.T) {
+	assert.Equal(t, "avg:requests{*}", getKey("requests", nil))
+	assert.Equal(t, "avg:requests{app:foo,env:prod}", getKey("requests", map[string]string{"env": "prod", "app": "foo"}))
+}
+
+func TestBatchQueries(t *testing.T) {
+	config.Datadog.Set("external_metrics_provider.queries_per_batch", 2)
+	defer config.Datadog.Set("external_metrics_provider.queries_per_batch", 35)
+
+	assert.Len(t, batchQueries(nil), 0)
+	assert.Equal(t, [][]string{{"a", "b"}}, batchQueries([]string{"a", "b"}))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, batchQueries([]string{"a", "b", "c"}))
+}
+
+func TestQueryExternalMetrics(t *testing.T) {
+	cache.Cache.Flush()
+	defer cache.Cache.Flush()
+	config.Datadog.Set("external_metrics_provider.queries_per_batch", 2)
+	defer config.Datadog.Set("external_metrics_provider.queries_per_batch", 35)
+
+	newSerie := func(metric, scope string, value float64) datadog.Series {
+		return datadog.Series{
+			Metric: &metric,
+			Scope:  &scope,
+			Points: []datadog.DataPoint{{1531492452, value}},
+		}
+	}
+	var received []string
+	datadogClient := &fakeDatadogClient{
+		queryMetricsFunc: func(from, to int64, query string) ([]datadog.Series, error) {
+			received = append(received, query)
+			var series []datadog.Series
+			for _, q := range strings.Split(query, ",") {
+				switch q {
+				case "avg:requests{app:foo}":
+					series = append(series, newSerie("requests", "app:foo", 12))
+				case "avg:requests{app:bar}":
+					series = append(series, newSerie("requests", "app:bar", 4))
+				}
+			}
+			return series, nil
+		},
+	}
+	hpaCl := &HPAWatcherClient{datadogClient: datadogClient}
+
+	metrics := []custommetrics.ExternalMetricValue{
+		{MetricName: "requests", Labels: map[string]string{"app": "foo"}},
+		{MetricName: "requests", Labels: map[string]string{"app": "foo"}},
+		{MetricName: "requests", Labels: map[string]string{"app": "bar"}},
+		{MetricName: "requests", Labels: map[string]string{"app": "unknown"}},
+	}
+	points := hpaCl.queryExternalMetrics(metrics)
+
+	// Duplicate queries are sent once, in batches of 2
+	assert.Equal(t, []string{"avg:requests{app:foo},avg:requests{app:bar}", "avg:requests{app:unknown}"}, received)
+	assert.Equal(t, map[string]Point{
+		"avg:requests{app:foo}":     {Value: 12, Valid: true},
+		"avg:requests{app:bar}":     {Value: 4, Valid: true},
+		"avg:requests{app:unknown}": {Valid: false},
+	}, points)
+
+	// Valid points are served from the cache, invalid ones are queried again
+	received = nil
+	points = hpaCl.queryExternalMetrics(metrics)
+	assert.Equal(t, []string{"avg:requests{app:unknown}"}, received)
+	assert.Equal(t, Point{Value: 12, Valid: true}, points["avg:requests{app:foo}"])
+}
@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package hpa
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/custommetrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// processWPAs evaluates the watermarks of the WatermarkPodAutoscalers against the
+// values of their Datadog metrics, and writes the recommended number of replicas
+// in their status. The queries of all the WPAs are batched with the HPA ones.
+func (c *HPAWatcherClient) processWPAs() {
+	wpas, err := c.listWPAs()
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Debugf("The WatermarkPodAutoscaler resource is not registered, skipping the evaluation")
+			return
+		}
+		log.Errorf("Could not list the WatermarkPodAutoscalers: %s", err)
+		return
+	}
+	if len(wpas) == 0 {
+		return
+	}
+
+	var metrics []custommetrics.ExternalMetricValue
+	for _, wpa := range wpas {
+		for _, metricSpec := range wpa.Spec.Metrics {
+			if metricSpec.External == nil {
+				continue
+			}
+			metrics = append(metrics, custommetrics.ExternalMetricValue{
+				MetricName: metricSpec.External.MetricName,
+				Labels:     wpaMetricLabels(metricSpec.External),
+			})
+		}
+	}
+	points := c.queryExternalMetrics(metrics)
+
+	for i := range wpas {
+		wpa := &wpas[i]
+		currentReplicas, err := c.getCurrentReplicas(wpa.Namespace, wpa.Spec.ScaleTargetRef)
+		if err != nil {
+			log.Errorf("Could not get the replicas of the target of the WatermarkPodAutoscaler %s/%s: %s", wpa.Namespace, wpa.Name, err)
+			continue
+		}
+		recommended, statuses := evaluateWPA(wpa, currentReplicas, points)
+		if len(statuses) == 0 {
+			log.Debugf("No valid metric to evaluate the WatermarkPodAutoscaler %s/%s", wpa.Namespace, wpa.Name)
+			continue
+		}
+		now := metav1.Now()
+		wpa.Status = WatermarkPodAutoscalerStatus{
+			LastEvaluationTime:  &now,
+			CurrentReplicas:     currentReplicas,
+			RecommendedReplicas: recommended,
+			CurrentMetrics:      statuses,
+		}
+		if err := c.updateWPAStatus(wpa); err != nil {
+			log.Errorf("Could not update the status of the WatermarkPodAutoscaler %s/%s: %s", wpa.Namespace, wpa.Name, err)
+			continue
+		}
+		log.Debugf("Recommended %d replicas instead of %d for the WatermarkPodAutoscaler %s/%s", recommended, currentReplicas, wpa.Namespace, wpa.Name)
+	}
+}
+
+// evaluateWPA returns the number of replicas recommended by the valid metrics of
+// the WPA, the highest recommendation winning, within the min and max replicas.
+func evaluateWPA(wpa *WatermarkPodAutoscaler, currentReplicas int32, points map[string]Point) (int32, []autoscalingv2.MetricStatus) {
+	var recommended int32
+	var statuses []autoscalingv2.MetricStatus
+	for _, metricSpec := range wpa.Spec.Metrics {
+		external := metricSpec.External
+		if external == nil || external.HighWatermark == nil || external.LowWatermark == nil {
+			log.Debugf("Skipping a metric of the WatermarkPodAutoscaler %s/%s without watermarks", wpa.Namespace, wpa.Name)
+			continue
+		}
+		point := points[getKey(external.MetricName, wpaMetricLabels(external))]
+		if !point.Valid {
+			continue
+		}
+		if r := recommendReplicas(currentReplicas, point.Value, external.LowWatermark, external.HighWatermark); r > recommended {
+			recommended = r
+		}
+		statuses = append(statuses, autoscalingv2.MetricStatus{
+			Type: autoscalingv2.ExternalMetricSourceType,
+			External: &autoscalingv2.ExternalMetricStatus{
+				MetricName:     external.MetricName,
+				MetricSelector: external.MetricSelector,
+				CurrentValue:   *resource.NewQuantity(point.Value, resource.DecimalSI),
+			},
+		})
+	}
+	if len(statuses) == 0 {
+		return currentReplicas, nil
+	}
+
+	minReplicas := int32(1)
+	if wpa.Spec.MinReplicas != nil {
+		minReplicas = *wpa.Spec.MinReplicas
+	}
+	if recommended < minReplicas {
+		recommended = minReplicas
+	}
+	if wpa.Spec.MaxReplicas > 0 && recommended > wpa.Spec.MaxReplicas {
+		recommended = wpa.Spec.MaxReplicas
+	}
+	return recommended, statuses
+}
+
+// recommendReplicas scales the current replicas proportionally to the ratio between
+// the value and the crossed watermark. The replicas are left unchanged while the
+// value is between the watermarks, and a target scaled to zero is not scaled up.
+func recommendReplicas(currentReplicas int32, value int64, low, high *resource.Quantity) int32 {
+	if currentReplicas == 0 {
+		return 0
+	}
+	v := float64(value)
+	highValue := float64(high.MilliValue()) / 1000
+	lowValue := float64(low.MilliValue()) / 1000
+	switch {
+	case v > highValue && highValue > 0:
+		return int32(math.Ceil(float64(currentReplicas) * v / highValue))
+	case v < lowValue && lowValue > 0:
+		return int32(math.Floor(float64(currentReplicas) * v / lowValue))
+	default:
+		return currentReplicas
+	}
+}
+
+func wpaMetricLabels(external *WPAExternalMetricSource) map[string]string {
+	if external.MetricSelector == nil {
+		return nil
+	}
+	return external.MetricSelector.MatchLabels
+}
+
+// getCurrentReplicas returns the desired replicas of the scale target of a WPA.
+func (c *HPAWatcherClient) getCurrentReplicas(namespace string, ref autoscalingv2.CrossVersionObjectReference) (int32, error) {
+	var replicas *int32
+	switch ref.Kind {
+	case "Deployment":
+		deploy, err := c.clientSet().AppsV1().Deployments(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		replicas = deploy.Spec.Replicas
+	case "ReplicaSet":
+		rs, err := c.clientSet().AppsV1().ReplicaSets(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		replicas = rs.Spec.Replicas
+	case "StatefulSet":
+		sts, err := c.clientSet().AppsV1().StatefulSets(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		replicas = sts.Spec.Replicas
+	default:
+		return 0, fmt.Errorf("unsupported scale target kind %q", ref.Kind)
+	}
+	if replicas == nil {
+		// Defaulted by the API server
+		return 1, nil
+	}
+	return *replicas, nil
+}
+
+func (c *HPAWatcherClient) listWPAs() ([]WatermarkPodAutoscaler, error) {
+	// the client may prefer protobuf, the WPAs are decoded from JSON
+	raw, err := c.clientSet().CoreV1().RESTClient().Get().AbsPath(wpaAPIPath, wpaResource).
+		SetHeader("Accept", "application/json").
+		DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	list := WatermarkPodAutoscalerList{}
+	if err = json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// updateWPAStatus writes the status of the WPA through its status subresource.
+func (c *HPAWatcherClient) updateWPAStatus(wpa *WatermarkPodAutoscaler) error {
+	body, err := json.Marshal(wpa)
+	if err != nil {
+		return err
+	}
+	_, err = c.clientSet().CoreV1().RESTClient().Put().
+		AbsPath(wpaAPIPath, "namespaces", wpa.Namespace, wpaResource, wpa.Name, "status").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		DoRaw()
+	return err
+}
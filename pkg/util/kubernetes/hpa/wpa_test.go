@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package hpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newMockWPA(minReplicas, maxReplicas int32, low, high string) *WatermarkPodAutoscaler {
+	lowWatermark := resource.MustParse(low)
+	highWatermark := resource.MustParse(high)
+	return &WatermarkPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: WatermarkPodAutoscalerSpec{
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+			Metrics: []WPAMetricSpec{
+				{
+					Type: autoscalingv2.ExternalMetricSourceType,
+					External: &WPAExternalMetricSource{
+						MetricName:     "requests_per_s",
+						MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+						LowWatermark:   &lowWatermark,
+						HighWatermark:  &highWatermark,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRecommendReplicas(t *testing.T) {
+	low := resource.MustParse("50")
+	high := resource.MustParse("100")
+	testCases := []struct {
+		caseName string
+		current  int32
+		value    int64
+		expected int32
+	}{
+		{"between the watermarks", 4, 70, 4},
+		{"on the high watermark", 4, 100, 4},
+		{"above the high watermark", 4, 150, 6},
+		{"above the high watermark rounded up", 3, 110, 4},
+		{"below the low watermark", 4, 25, 2},
+		{"below the low watermark rounded down", 3, 40, 2},
+		{"scaled to zero", 0, 500, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, recommendReplicas(tc.current, tc.value, &low, &high))
+		})
+	}
+}
+
+func TestEvaluateWPA(t *testing.T) {
+	query := "avg:requests_per_s{app:nginx}"
+	testCases := []struct {
+		caseName          string
+		wpa               *WatermarkPodAutoscaler
+		points            map[string]Point
+		expectedReplicas  int32
+		expectedStatusLen int
+	}{
+		{
+			caseName:          "scale up",
+			wpa:               newMockWPA(1, 10, "50", "100"),
+			points:            map[string]Point{query: {Value: 200, Valid: true}},
+			expectedReplicas:  4,
+			expectedStatusLen: 1,
+		},
+		{
+			caseName:          "capped by the max replicas",
+			wpa:               newMockWPA(1, 3, "50", "100"),
+			points:            map[string]Point{query: {Value: 200, Valid: true}},
+			expectedReplicas:  3,
+			expectedStatusLen: 1,
+		},
+		{
+			caseName:          "floored by the min replicas",
+			wpa:               newMockWPA(2, 10, "50", "100"),
+			points:            map[string]Point{query: {Value: 1, Valid: true}},
+			expectedReplicas:  2,
+			expectedStatusLen: 1,
+		},
+		{
+			caseName:          "invalid metric",
+			wpa:               newMockWPA(1, 10, "50", "100"),
+			points:            map[string]Point{query: {Valid: false}},
+			expectedReplicas:  2,
+			expectedStatusLen: 0,
+		},
+		{
+			caseName:          "missing metric",
+			wpa:               newMockWPA(1, 10, "50", "100"),
+			points:            map[string]Point{},
+			expectedReplicas:  2,
+			expectedStatusLen: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			replicas, statuses := evaluateWPA(tc.wpa, 2, tc.points)
+			assert.Equal(t, tc.expectedReplicas, replicas)
+			require.Len(t, statuses, tc.expectedStatusLen)
+			for _, status := range statuses {
+				assert.Equal(t, "requests_per_s", status.External.MetricName)
+				assert.Equal(t, tc.points[query].Value, status.External.CurrentValue.Value())
+			}
+		})
+	}
+}
+
+func TestGetCurrentReplicas(t *testing.T) {
+	replicas := int32(5)
+	client := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	})
+	hpaCl := &HPAWatcherClient{clientSet: func() kubernetes.Interface { return client }}
+
+	current, err := hpaCl.getCurrentReplicas("default", autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), current)
+
+	_, err = hpaCl.getCurrentReplicas("default", autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "missing"})
+	assert.Error(t, err)
+
+	_, err = hpaCl.getCurrentReplicas("default", autoscalingv2.CrossVersionObjectReference{Kind: "DaemonSet", Name: "nginx"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package hpa
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	wpaAPIPath  = "/apis/datadoghq.com/v1alpha1"
+	wpaResource = "watermarkpodautoscalers"
+)
+
+// WatermarkPodAutoscalerList is a minimal representation of a list of the
+// datadoghq.com/v1alpha1 WatermarkPodAutoscaler custom resources.
+type WatermarkPodAutoscalerList struct {
+	Items []WatermarkPodAutoscaler `json:"items"`
+}
+
+// WatermarkPodAutoscaler is a minimal representation of the WatermarkPodAutoscaler
+// custom resource, with the fields used to evaluate its watermarks.
+type WatermarkPodAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              WatermarkPodAutoscalerSpec   `json:"spec"`
+	Status            WatermarkPodAutoscalerStatus `json:"status,omitempty"`
+}
+
+// WatermarkPodAutoscalerSpec is the specification of a WatermarkPodAutoscaler
+type WatermarkPodAutoscalerSpec struct {
+	ScaleTargetRef autoscalingv2.CrossVersionObjectReference `json:"scaleTargetRef"`
+	MinReplicas    *int32                                    `json:"minReplicas,omitempty"`
+	MaxReplicas    int32                                     `json:"maxReplicas"`
+	Metrics        []WPAMetricSpec                           `json:"metrics,omitempty"`
+}
+
+// WPAMetricSpec is a metric of a WatermarkPodAutoscaler, only the External type is supported.
+type WPAMetricSpec struct {
+	Type     autoscalingv2.MetricSourceType `json:"type"`
+	External *WPAExternalMetricSource       `json:"external,omitempty"`
+}
+
+// WPAExternalMetricSource is a Datadog metric and the watermarks between which
+// the number of replicas is left unchanged.
+type WPAExternalMetricSource struct {
+	MetricName     string                `json:"metricName"`
+	MetricSelector *metav1.LabelSelector `json:"metricSelector,omitempty"`
+	HighWatermark  *resource.Quantity    `json:"highWatermark,omitempty"`
+	LowWatermark   *resource.Quantity    `json:"lowWatermark,omitempty"`
+}
+
+// WatermarkPodAutoscalerStatus is the recommendation written by the cluster agent
+type WatermarkPodAutoscalerStatus struct {
+	LastEvaluationTime  *metav1.Time                 `json:"lastEvaluationTime,omitempty"`
+	CurrentReplicas     int32                        `json:"currentReplicas"`
+	RecommendedReplicas int32                        `json:"recommendedReplicas"`
+	CurrentMetrics      []autoscalingv2.MetricStatus `json:"currentMetrics,omitempty"`
+}
@@ -30,6 +30,8 @@ import (
 const (
 	kubeletPodPath         = "/pods"
 	kubeletMetricsPath     = "/metrics"
+	kubeletSummaryPath     = "/stats/summary"
+	kubeletCadvisorPath    = "/metrics/cadvisor"
 	authorizationHeaderKey = "Authorization"
 	podListCacheKey        = "KubeletPodListCacheKey"
 )
@@ -402,6 +404,48 @@ func (ku *KubeUtil) GetRawMetrics() ([]byte, error) {
 	return data, nil
 }
 
+// GetStatsSummary returns the node, pod and container stats of the kubelet
+// /stats/summary endpoint. A NotFound error is returned by kubelets not
+// exposing it.
+func (ku *KubeUtil) GetStatsSummary() (*Summary, error) {
+	data, err := ku.queryKubeletEndpoint(kubeletSummaryPath)
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{}
+	if err := json.Unmarshal(data, summary); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s%s: %s", ku.kubeletApiEndpoint, kubeletSummaryPath, err)
+	}
+	return summary, nil
+}
+
+// GetRawCadvisorMetrics returns the raw cAdvisor metrics payload exposed by
+// the kubelet. A NotFound error is returned by kubelets not exposing it.
+func (ku *KubeUtil) GetRawCadvisorMetrics() ([]byte, error) {
+	return ku.queryKubeletEndpoint(kubeletCadvisorPath)
+}
+
+// GetKubeletHost returns the resolved hostname or IP address of the kubelet
+func (ku *KubeUtil) GetKubeletHost() string {
+	return ku.kubeletHost
+}
+
+// queryKubeletEndpoint queries an optional endpoint of the kubelet,
+// returning a NotFound error if it is not exposed
+func (ku *KubeUtil) queryKubeletEndpoint(path string) ([]byte, error) {
+	data, code, err := ku.QueryKubelet(path)
+	if err != nil {
+		return nil, fmt.Errorf("error performing kubelet query %s%s: %s", ku.kubeletApiEndpoint, path, err)
+	}
+	if code == http.StatusNotFound {
+		return nil, errors.NewNotFound(ku.kubeletApiEndpoint + path)
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d on %s%s: %s", code, ku.kubeletApiEndpoint, path, string(data))
+	}
+	return data, nil
+}
+
 func (ku *KubeUtil) setupKubeletApiEndpoint() error {
 	// HTTPS
 	ku.kubeletApiEndpoint = fmt.Sprintf("https://%s:%d", ku.kubeletHost, config.Datadog.GetInt("kubernetes_https_kubelet_port"))
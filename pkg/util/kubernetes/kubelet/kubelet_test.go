@@ -36,8 +36,9 @@ const (
 
 // dummyKubelet allows tests to mock a kubelet's responses
 type dummyKubelet struct {
-	Requests chan *http.Request
-	PodsBody []byte
+	Requests    chan *http.Request
+	PodsBody    []byte
+	SummaryBody []byte
 
 	testingCertificate string
 	testingPrivateKey  string
@@ -75,6 +76,14 @@ func (d *dummyKubelet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s, err := w.Write(d.PodsBody)
 		log.Debugf("dummyKubelet wrote %d bytes, err: %v", s, err)
 
+	case "/stats/summary":
+		if d.SummaryBody == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s, err := w.Write(d.SummaryBody)
+		log.Debugf("dummyKubelet wrote %d bytes, err: %v", s, err)
+
 	default:
 		w.WriteHeader(http.StatusNotFound)
 	}
@@ -222,6 +231,60 @@ func (suite *KubeletTestSuite) TestGetLocalPodList() {
 	}
 }
 
+func (suite *KubeletTestSuite) TestGetStatsSummary() {
+	kubelet, err := newDummyKubelet("./testdata/podlist_1.8-2.json")
+	require.Nil(suite.T(), err)
+	ts, kubeletPort, err := kubelet.Start()
+	defer ts.Close()
+	require.Nil(suite.T(), err)
+
+	config.Datadog.Set("kubernetes_kubelet_host", "localhost")
+	config.Datadog.Set("kubernetes_http_kubelet_port", kubeletPort)
+	config.Datadog.Set("kubelet_tls_verify", false)
+	config.Datadog.Set("kubelet_auth_token_path", "")
+
+	kubeutil, err := GetKubeUtil()
+	require.Nil(suite.T(), err)
+	require.NotNil(suite.T(), kubeutil)
+	drainRequests(kubelet)
+
+	// Kubelets without the summary API
+	_, err = kubeutil.GetStatsSummary()
+	require.NotNil(suite.T(), err)
+	assert.True(suite.T(), errors.IsNotFound(err))
+	drainRequests(kubelet)
+
+	kubelet.SummaryBody, err = ioutil.ReadFile("./testdata/summary.json")
+	require.Nil(suite.T(), err)
+	summary, err := kubeutil.GetStatsSummary()
+	require.Nil(suite.T(), err)
+	require.NotNil(suite.T(), summary)
+
+	assert.Equal(suite.T(), "minikube", summary.Node.NodeName)
+	require.NotNil(suite.T(), summary.Node.Fs)
+	assert.Equal(suite.T(), uint64(17293533184), *summary.Node.Fs.CapacityBytes)
+	require.NotNil(suite.T(), summary.Node.Network)
+	assert.Equal(suite.T(), "eth0", summary.Node.Network.Name)
+	assert.Equal(suite.T(), uint64(2135486201), *summary.Node.Network.RxBytes)
+	require.Len(suite.T(), summary.Node.Network.Interfaces, 2)
+	require.Len(suite.T(), summary.Pods, 1)
+	assert.Equal(suite.T(), "kube-dns-86f4d74b45-fmzgx", summary.Pods[0].PodRef.Name)
+	require.Len(suite.T(), summary.Pods[0].Containers, 1)
+	assert.Equal(suite.T(), uint64(5419008), *summary.Pods[0].Containers[0].Memory.RSSBytes)
+	assert.Nil(suite.T(), summary.Pods[0].Containers[0].Logs)
+}
+
+// drainRequests empties the request channel of a dummyKubelet
+func drainRequests(kubelet *dummyKubelet) {
+	for {
+		select {
+		case <-kubelet.Requests:
+		default:
+			return
+		}
+	}
+}
+
 func (suite *KubeletTestSuite) TestGetNodeInfo() {
 	kubelet, err := newDummyKubelet("./testdata/podlist_1.8-2.json")
 	require.Nil(suite.T(), err)
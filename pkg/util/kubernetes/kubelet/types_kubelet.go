@@ -76,7 +76,8 @@ type Conditions struct {
 
 // ContainerStatus contains fields for unmarshalling a Pod.Status.Containers
 type ContainerStatus struct {
-	Name  string `json:"name,omitempty"`
-	Image string `json:"image,omitempty"`
-	ID    string `json:"containerID,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Image        string `json:"image,omitempty"`
+	ID           string `json:"containerID,omitempty"`
+	RestartCount int    `json:"restartCount,omitempty"`
 }
@@ -10,6 +10,7 @@ ALL_TAGS = set([
     "apm",
     "consul",
     "cpython",
+    "cri",
     "docker",
     "ec2",
     "etcd",
@@ -25,12 +26,19 @@ ALL_TAGS = set([
     "kubeapiserver",
 ])
 
+# OPT_IN_TAGS lists the build tags that are only included when explicitly
+# passed through --build-include, "all" doesn't include them
+OPT_IN_TAGS = set([
+    "zstd",
+])
+
 # PUPPY_TAGS lists the tags needed when building the Puppy Agent
 PUPPY_TAGS = set([
     "zlib",
 ])
 
 LINUX_ONLY_TAGS = [
+    "cri",
     "docker",
     "kubelet",
     "kubeapiserver",
@@ -68,11 +76,11 @@ def get_build_tags(include, exclude):
     """
     # special case, include == all
     if "all" in include:
-        return list(ALL_TAGS - set(exclude))
+        return list(ALL_TAGS.union(OPT_IN_TAGS.intersection(set(include))) - set(exclude))
 
     # filter out unrecognised tags
-    include = ALL_TAGS.intersection(set(include))
-    exclude = ALL_TAGS.intersection(set(exclude))
+    include = ALL_TAGS.union(OPT_IN_TAGS).intersection(set(include))
+    exclude = ALL_TAGS.union(OPT_IN_TAGS).intersection(set(exclude))
     return list(include - exclude)
 
 
@@ -104,6 +104,12 @@ func (f *forwarderBenchStub) SubmitHostMetadata(payload forwarder.Payloads, extr
 func (f *forwarderBenchStub) SubmitMetadata(payload forwarder.Payloads, extraHeaders http.Header) error {
 	return nil
 }
+func (f *forwarderBenchStub) SubmitOrchestratorManifests(payload forwarder.Payloads, extraHeaders http.Header) error {
+	return nil
+}
+func (f *forwarderBenchStub) UpdateCongestion() bool {
+	return false
+}
 
 type aggregatorStats struct {
 	Flush map[string]aggregator.Stats
@@ -112,6 +112,13 @@ func (f *forwarderBenchStub) SubmitMetadata(payloads forwarder.Payloads, extraHe
 	f.computeStats(payloads)
 	return nil
 }
+func (f *forwarderBenchStub) SubmitOrchestratorManifests(payloads forwarder.Payloads, extraHeaders http.Header) error {
+	f.computeStats(payloads)
+	return nil
+}
+func (f *forwarderBenchStub) UpdateCongestion() bool {
+	return false
+}
 
 // NewStatsdGenerator returns a generator server
 // We could use datadog-go, but I want as little overhead as possible.
@@ -76,7 +76,7 @@ func (suite *testSuite) SetupTest() {
 			}
 			// Confirm that we can query the kube-apiserver's resources
 			log.Debugf("trying to get LatestEvents")
-			_, _, resV, err := suite.apiClient.LatestEvents("0", eventReadTimeout)
+			_, _, resV, err := suite.apiClient.LatestEvents("0", eventReadTimeout, "")
 			if err == nil {
 				log.Debugf("successfully get LatestEvents: %s", resV)
 				return
@@ -93,11 +93,11 @@ func (suite *testSuite) TestKubeEvents() {
 
 	require.NoError(suite.T(), err)
 
-	core := c.Cl.CoreV1()
+	core := c.Client().CoreV1()
 	require.NotNil(suite.T(), core)
 
 	// Ignore potential startup events
-	_, _, initresversion, err := suite.apiClient.LatestEvents("0", eventReadTimeout)
+	_, _, initresversion, err := suite.apiClient.LatestEvents("0", eventReadTimeout, "")
 	require.NoError(suite.T(), err)
 
 	// Create started event
@@ -107,7 +107,7 @@ func (suite *testSuite) TestKubeEvents() {
 	require.NoError(suite.T(), err)
 
 	// Test we get the new started event
-	added, modified, resversion, err := suite.apiClient.LatestEvents(initresversion, eventReadTimeout)
+	added, modified, resversion, err := suite.apiClient.LatestEvents(initresversion, eventReadTimeout, "")
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), added, 1)
 	assert.Len(suite.T(), modified, 0)
@@ -119,7 +119,7 @@ func (suite *testSuite) TestKubeEvents() {
 	require.NoError(suite.T(), err)
 
 	// Test we get the new tick event
-	added, modified, resversion, err = suite.apiClient.LatestEvents(resversion, eventReadTimeout)
+	added, modified, resversion, err = suite.apiClient.LatestEvents(resversion, eventReadTimeout, "")
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), added, 1)
 	assert.Len(suite.T(), modified, 0)
@@ -139,7 +139,7 @@ func (suite *testSuite) TestKubeEvents() {
 	require.NoError(suite.T(), err)
 
 	// Test we get the two modified test events
-	added, modified, resversion, err = suite.apiClient.LatestEvents(resversion, eventReadTimeout)
+	added, modified, resversion, err = suite.apiClient.LatestEvents(resversion, eventReadTimeout, "")
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), added, 0)
 	assert.Len(suite.T(), modified, 2)
@@ -150,14 +150,14 @@ func (suite *testSuite) TestKubeEvents() {
 	assert.EqualValues(suite.T(), modified[0].InvolvedObject.UID, modified[1].InvolvedObject.UID)
 
 	// We should get nothing new now
-	added, modified, resversion, err = suite.apiClient.LatestEvents(resversion, eventReadTimeout)
+	added, modified, resversion, err = suite.apiClient.LatestEvents(resversion, eventReadTimeout, "")
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), added, 0)
 	assert.Len(suite.T(), modified, 0)
 
 	// We should get 2+0 events from initresversion
 	// apiserver does not send updates to objects if the add is in the same bucket
-	added, modified, _, err = suite.apiClient.LatestEvents(initresversion, eventReadTimeout)
+	added, modified, _, err = suite.apiClient.LatestEvents(initresversion, eventReadTimeout, "")
 	require.NoError(suite.T(), err)
 	assert.Len(suite.T(), added, 2)
 	assert.Len(suite.T(), modified, 0)
@@ -167,7 +167,7 @@ func (suite *testSuite) TestServiceMapper() {
 	client, err := apiserver.GetAPIClient()
 	require.NoError(suite.T(), err)
 
-	c := client.Cl.CoreV1()
+	c := client.Client().CoreV1()
 	require.NotNil(suite.T(), c)
 
 	// Create a Ready Schedulable node
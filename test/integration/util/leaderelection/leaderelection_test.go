@@ -166,7 +166,7 @@ func (suite *apiserverSuite) TestLeaderElectionMulti() {
 	}
 
 	c, err := apiserver.GetAPIClient()
-	client := c.Cl.CoreV1()
+	client := c.Client().CoreV1()
 
 	require.Nil(suite.T(), err)
 	cmList, err := client.ConfigMaps(metav1.NamespaceDefault).List(metav1.ListOptions{})
@@ -186,4 +186,9 @@ func (suite *apiserverSuite) TestLeaderElectionMulti() {
 	require.Nil(suite.T(), err)
 	expectedMessage := fmt.Sprintf(`"holderIdentity":"%s"`, testCases[0].leaderEngine.HolderIdentity)
 	assert.Contains(suite.T(), leaderAnnotation, expectedMessage)
+
+	details, err := leaderelection.GetLeaderDetails()
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), actualLeader.HolderIdentity, details.Leader)
+	assert.True(suite.T(), details.HeldForSeconds >= 0)
 }